@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
@@ -24,6 +25,21 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Running input and output units used to apply configuration changes
+	reloadLock sync.Mutex
+	inputs     *inputUnit
+	outputs    *outputUnit
+
+	// Global tags including the ones resolved from metadata sources, nil if
+	// no sources are configured
+	globalTags map[string]string
+
+	// Series tracker kept up to date with the global tags, nil if disabled
+	series *seriesTracker
+
+	// Result of draining the outputs on shutdown
+	drained drainSummary
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -48,6 +64,21 @@ func NewAgent(cfg *config.Config) *Agent {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// State of the gather loops, only used when running continuously
+	sync.Mutex
+	ctx       context.Context
+	startTime time.Time
+	running   map[*models.RunningInput]*gatherRun
+	wg        sync.WaitGroup
+	stopped   bool
+}
+
+// gatherRun is the state of a single running gather loop.
+type gatherRun struct {
+	cancel context.CancelFunc
+	ticker Ticker
+	done   chan struct{}
 }
 
 //  ______     ┌───────────┐     ______
@@ -99,6 +130,20 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// State of the flush loops, the lock protects the outputs as well
+	sync.RWMutex
+	ctx     context.Context
+	running map[*models.RunningOutput]*flushRun
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// flushRun is the state of a single running flush loop.
+type flushRun struct {
+	cancel   context.CancelFunc
+	handover chan struct{}
+	done     chan struct{}
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -117,17 +162,40 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.Config.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
 	}
 
-	log.Printf("D! [agent] Initializing plugins")
-	if err := a.InitPlugins(); err != nil {
+	if err := checkShutdownPolicy(a.Config.Agent); err != nil {
 		return err
 	}
 
+	var health *healthService
+	if a.Config.Agent.HealthServiceAddress != "" {
+		var err error
+		health, err = newHealthService(a.Config.Agent)
+		if err != nil {
+			return err
+		}
+		if err := health.start(a.Config.Agent.HealthServiceAddress); err != nil {
+			return fmt.Errorf("starting health service failed: %w", err)
+		}
+		defer health.stop()
+	}
+
+	tracerProvider, err := newTracerProvider(a.Config.Agent)
+	if err != nil {
+		return err
+	}
+	if tracerProvider != nil {
+		log.Printf("D! [agent] Exporting pipeline traces to %q", a.Config.Agent.TracingEndpoint)
+		stopTracing := startTracing(tracerProvider)
+		defer stopTracing()
+	}
+
+	// Read the states before initializing the plugins to provide the state
+	// stores of the plugins with the previous state
 	if a.Config.Persister != nil {
-		log.Printf("D! [agent] Initializing plugin states")
-		if err := a.initPersister(); err != nil {
+		if err := a.Config.Persister.Init(); err != nil {
 			return err
 		}
-		if err := a.Config.Persister.Load(); err != nil {
+		if err := a.Config.Persister.Read(); err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
 				return err
 			}
@@ -135,8 +203,52 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	log.Printf("D! [agent] Initializing plugins")
+	if err := a.InitPlugins(); err != nil {
+		return err
+	}
+
+	if a.Config.Persister != nil {
+		log.Printf("D! [agent] Initializing plugin states")
+		if err := a.initPersister(); err != nil {
+			return err
+		}
+		if err := a.Config.Persister.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if interval := time.Duration(a.Config.Agent.StatefileInterval); interval > 0 {
+			stopCheckpoints := a.startCheckpoints(interval)
+			defer stopCheckpoints()
+		}
+	}
+
+	globalTags := a.Config.Tags
+	enricher, err := newTagEnricher(a.Config.Agent, a.Config.Tags)
+	if err != nil {
+		return err
+	}
+	if enricher != nil {
+		log.Printf("D! [agent] Resolving global tags")
+		globalTags = enricher.refresh(ctx)
+		a.setGlobalTags(globalTags)
+		if enricher.interval > 0 {
+			stopEnrichment := a.startEnrichment(enricher)
+			defer stopEnrichment()
+		}
+	}
+
 	startTime := time.Now()
 
+	deadLetterTargets, err := resolveDeadLetterOutputs(a.Config.Outputs)
+	if err != nil {
+		return err
+	}
+	linkDeadLetterOutputs(a.Config.Outputs, deadLetterTargets)
+
+	if err := a.restoreSpilled(); err != nil {
+		return err
+	}
+
 	log.Printf("D! [agent] Connecting outputs")
 	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
 	if err != nil {
@@ -165,11 +277,23 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	next, su, err := a.setupSeries(next, globalTags)
+	if err != nil {
+		return err
+	}
+
 	iu, err := a.startInputs(next, a.Config.Inputs)
 	if err != nil {
 		return err
 	}
 
+	a.reloadLock.Lock()
+	a.inputs, a.outputs = iu, ou
+	a.reloadLock.Unlock()
+	if health != nil {
+		health.setUnits(iu, ou)
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -199,6 +323,22 @@ func (a *Agent) Run(ctx context.Context) error {
 		}()
 	}
 
+	if su != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runSeries(su)
+		}()
+	}
+
+	if backpressureEnabled(ou.outputs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runBackpressure(ctx, iu, ou)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -207,6 +347,13 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	wg.Wait()
 
+	a.reloadLock.Lock()
+	a.inputs, a.outputs = nil, nil
+	a.reloadLock.Unlock()
+	if health != nil {
+		health.setUnits(nil, nil)
+	}
+
 	if a.Config.Persister != nil {
 		log.Printf("D! [agent] Persisting plugin states")
 		if err := a.Config.Persister.Store(); err != nil {
@@ -225,18 +372,27 @@ func (a *Agent) InitPlugins() error {
 		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
 			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
 		}
+		if err := a.attachStateStore(input.ID(), input.Input); err != nil {
+			return fmt.Errorf("could not set state store of input %s: %w", input.LogName(), err)
+		}
 		err := input.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
 		}
 	}
 	for _, processor := range a.Config.Processors {
+		if err := a.attachStateStore(processor.ID(), processor.Processor); err != nil {
+			return fmt.Errorf("could not set state store of processor %s: %w", processor.LogName(), err)
+		}
 		err := processor.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
 		}
 	}
 	for _, aggregator := range a.Config.Aggregators {
+		if err := a.attachStateStore(aggregator.ID(), aggregator.Aggregator); err != nil {
+			return fmt.Errorf("could not set state store of aggregator %s: %w", aggregator.LogName(), err)
+		}
 		err := aggregator.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err)
@@ -244,6 +400,9 @@ func (a *Agent) InitPlugins() error {
 	}
 	if !*a.Config.Agent.SkipProcessorsAfterAggregators {
 		for _, processor := range a.Config.AggProcessors {
+			if err := a.attachStateStore(processor.ID(), processor.Processor); err != nil {
+				return fmt.Errorf("could not set state store of processor %s: %w", processor.LogName(), err)
+			}
 			err := processor.Init()
 			if err != nil {
 				return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
@@ -251,6 +410,9 @@ func (a *Agent) InitPlugins() error {
 		}
 	}
 	for _, output := range a.Config.Outputs {
+		if err := a.attachStateStore(output.ID(), output.Output); err != nil {
+			return fmt.Errorf("could not set state store of output %s: %w", output.LogName(), err)
+		}
 		err := output.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
@@ -259,12 +421,51 @@ func (a *Agent) InitPlugins() error {
 	return nil
 }
 
-// initPersister initializes the persister and registers the plugins.
-func (a *Agent) initPersister() error {
-	if err := a.Config.Persister.Init(); err != nil {
-		return err
+// attachStateStore sets the state store of the plugin with the given ID if the
+// plugin uses one. Without a state file the states are kept in memory only.
+func (a *Agent) attachStateStore(id string, plugin interface{}) error {
+	if p, ok := plugin.(processors.HasUnwrap); ok {
+		plugin = p.Unwrap()
 	}
+	_, err := models.SetStateStoreOnPlugin(plugin, func() (telegraf.StateStore, error) {
+		if a.Config.Persister == nil {
+			return persister.NewBucket(), nil
+		}
+		return a.Config.Persister.Bucket(id)
+	})
+	return err
+}
 
+// startCheckpoints periodically writes the modified plugin states to disk
+// until the returned function is called.
+func (a *Agent) startCheckpoints(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.Config.Persister.Checkpoint(); err != nil {
+					log.Printf("E! [agent] Persisting plugin states failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// initPersister registers the stateful plugins with the persister.
+func (a *Agent) initPersister() error {
 	for _, input := range a.Config.Inputs {
 		plugin, ok := input.Input.(telegraf.StatefulPlugin)
 		if !ok {
@@ -349,45 +550,55 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 	}
 
 	for _, input := range inputs {
-		// Service input plugins are not normally subject to timestamp
-		// rounding except for when precision is set on the input plugin.
-		//
-		// This only applies to the accumulator passed to Start(), the
-		// Gather() accumulator does apply rounding according to the
-		// precision and interval agent/plugin settings.
-		var interval time.Duration
-		var precision time.Duration
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
-
-		acc := NewAccumulator(input, dst)
-		acc.SetPrecision(getPrecision(precision, interval))
-
-		if err := input.Start(acc); err != nil {
-			// If the model tells us to remove the plugin we do so without error
-			var fatalErr *internal.FatalError
-			if errors.As(err, &fatalErr) {
-				log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
-				continue
-			}
-
+		started, err := startInput(dst, input)
+		if err != nil {
 			stopRunningInputs(unit.inputs)
-
-			return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+			return nil, err
 		}
-		if err := input.Probe(); err != nil {
-			// Probe failures are non-fatal to the agent but should only remove the plugin
-			log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
-			input.Stop()
-			continue
+		if started {
+			unit.inputs = append(unit.inputs, input)
 		}
-		unit.inputs = append(unit.inputs, input)
 	}
 
 	return unit, nil
 }
 
+// startInput starts the given service input. Inputs failing with a fatal error
+// or failing the probe are removed without error and reported as not started.
+func startInput(dst chan<- telegraf.Metric, input *models.RunningInput) (bool, error) {
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	if err := input.Start(acc); err != nil {
+		// If the model tells us to remove the plugin we do so without error
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
+			return false, nil
+		}
+		return false, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+	}
+	if err := input.Probe(); err != nil {
+		// Probe failures are non-fatal to the agent but should only remove the plugin
+		log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
+		input.Stop()
+		return false, nil
+	}
+	return true, nil
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -397,52 +608,26 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) {
-	var wg sync.WaitGroup
-	tickers := make([]Ticker, 0, len(unit.inputs))
+	unit.Lock()
+	unit.ctx = ctx
+	unit.startTime = startTime
+	unit.running = make(map[*models.RunningInput]*gatherRun, len(unit.inputs))
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := time.Duration(a.Config.Agent.Interval)
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
-
-		// Overwrite agent precision if this plugin has its own.
-		precision := time.Duration(a.Config.Agent.Precision)
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
-
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := time.Duration(a.Config.Agent.CollectionJitter)
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
-
-		// Overwrite agent collection_offset if this plugin has its own.
-		offset := time.Duration(a.Config.Agent.CollectionOffset)
-		if input.Config.CollectionOffset != 0 {
-			offset = input.Config.CollectionOffset
-		}
-
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter, offset)
-		}
-		tickers = append(tickers, ticker)
+		a.startGather(unit, input)
+	}
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+	// Keep the unit open for configuration changes until the context is done,
+	// even without inputs as those might be added by a later change
+	unit.Unlock()
+	<-ctx.Done()
+	unit.Lock()
+	unit.stopped = true
+	unit.Unlock()
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+	unit.wg.Wait()
+	for _, run := range unit.running {
+		run.ticker.Stop()
 	}
-	defer stopTickers(tickers)
-	wg.Wait()
 
 	log.Printf("D! [agent] Stopping service inputs")
 	stopRunningInputs(unit.inputs)
@@ -451,6 +636,76 @@ func (a *Agent) runInputs(
 	log.Printf("D! [agent] Input channel closed")
 }
 
+// startGather starts the periodic gather loop for the given input. The caller
+// must hold the lock of the unit.
+func (a *Agent) startGather(unit *inputUnit, input *models.RunningInput) {
+	// Overwrite agent interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.Interval)
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
+
+	// Overwrite agent precision if this plugin has its own.
+	precision := time.Duration(a.Config.Agent.Precision)
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.CollectionJitter)
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
+	}
+
+	// Overwrite agent collection_offset if this plugin has its own.
+	offset := time.Duration(a.Config.Agent.CollectionOffset)
+	if input.Config.CollectionOffset != 0 {
+		offset = input.Config.CollectionOffset
+	}
+
+	var ticker Ticker
+	if schedule := input.Schedule(); schedule != nil {
+		catchUp := input.Config.ScheduleCatchUp == "once"
+		ticker = NewCronTicker(unit.startTime, schedule, jitter, offset, catchUp)
+	} else if a.Config.Agent.RoundInterval {
+		ticker = NewAlignedTicker(unit.startTime, interval, jitter, offset)
+	} else {
+		ticker = NewUnalignedTicker(interval, jitter, offset)
+	}
+
+	acc := NewAccumulator(input, unit.dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	run := &gatherRun{
+		cancel: cancel,
+		ticker: ticker,
+		done:   make(chan struct{}),
+	}
+	unit.running[input] = run
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(run.done)
+		a.gatherLoop(ctx, acc, input, ticker, interval)
+	}()
+}
+
+// detachGather cancels the gather loop of the given input and removes it from
+// the unit. The caller must hold the lock of the unit and wait for the returned
+// run to complete an ongoing Gather call after releasing the lock.
+func detachGather(unit *inputUnit, input *models.RunningInput) *gatherRun {
+	run, found := unit.running[input]
+	if !found {
+		return nil
+	}
+	delete(unit.running, input)
+
+	run.cancel()
+	return run
+}
+
 // testStartInputs is a variation of startInputs for use in --test and --once mode.
 // It differs by logging Start errors and returning only plugins successfully started.
 func (*Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) *inputUnit {
@@ -572,18 +827,29 @@ func (a *Agent) gatherLoop(
 	for {
 		select {
 		case <-ticker.Elapsed():
-			err := a.gatherOnce(acc, input, ticker, interval)
+			missed, err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
 			}
+			// Catch up on a scheduled collection missed due to the previous
+			// collection still running
+			for missed && input.Config.ScheduleCatchUp == "once" && ctx.Err() == nil {
+				log.Printf("D! [%s] Catching up on missed scheduled collection", input.LogName())
+				missed, err = a.gatherOnce(acc, input, ticker, interval)
+				if err != nil {
+					acc.AddError(err)
+				}
+			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// gatherOnce runs the input's Gather function once, logging a warning each interval it fails to complete before.
-func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration) error {
+// gatherOnce runs the input's Gather function once, logging a warning each
+// interval it fails to complete before. The returned flag is true if a
+// scheduled collection was skipped while gathering.
+func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration) (bool, error) {
 	done := make(chan error)
 	go func() {
 		defer panicRecover(input)
@@ -596,10 +862,11 @@ func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, t
 	slowWarning := time.NewTicker(interval)
 	defer slowWarning.Stop()
 
+	var missed bool
 	for {
 		select {
 		case err := <-done:
-			return err
+			return missed, err
 		case <-slowWarning.C:
 			log.Printf("W! [%s] Collection took longer than expected; not complete after interval of %s",
 				input.LogName(), interval)
@@ -607,6 +874,7 @@ func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, t
 		case <-ticker.Elapsed():
 			log.Printf("D! [%s] Previous collection has not completed; scheduled collection skipped",
 				input.LogName())
+			missed = true
 		}
 	}
 }
@@ -829,39 +1097,19 @@ func (*Agent) connectOutput(ctx context.Context, output *models.RunningOutput) e
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
-	// Start flush loop
-	interval := time.Duration(a.Config.Agent.FlushInterval)
-	jitter := time.Duration(a.Config.Agent.FlushJitter)
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Start flush loop
+	unit.Lock()
+	unit.ctx = ctx
+	unit.running = make(map[*models.RunningOutput]*flushRun, len(unit.outputs))
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
-
-		wg.Add(1)
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
-
-			a.flushLoop(ctx, output, ticker)
-		}(output)
+		a.startFlush(unit, output)
 	}
+	unit.Unlock()
 
 	for metric := range unit.src {
+		unit.RLock()
 		for i, output := range unit.outputs {
 			if i == len(unit.outputs)-1 {
 				output.AddMetricNoCopy(metric)
@@ -869,22 +1117,89 @@ func (a *Agent) runOutputs(
 				output.AddMetric(metric)
 			}
 		}
+		unit.RUnlock()
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
+	unit.Lock()
+	unit.stopped = true
+	unit.Unlock()
 	cancel()
-	wg.Wait()
+	unit.wg.Wait()
+
+	if a.Config.Agent.ShutdownPolicy == "drain" {
+		a.drained.Lock()
+		log.Printf("I! [agent] Drained outputs: %d flushed, %d spilled, %d dropped",
+			a.drained.flushed, a.drained.spilled, a.drained.dropped)
+		a.drained.Unlock()
+	}
 
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
+// startFlush starts the periodic flush loop for the given output. The caller
+// must hold the lock of the unit.
+func (a *Agent) startFlush(unit *outputUnit, output *models.RunningOutput) {
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.FlushInterval)
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
+
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.FlushJitter)
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	run := &flushRun{
+		cancel:   cancel,
+		handover: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	unit.running[output] = run
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(run.done)
+
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, output, ticker, run.handover)
+	}()
+}
+
+// stopFlush stops the flush loop of the given output and waits for it to
+// finish. If handover is set, the loop is left without writing the buffered
+// metrics so they can be taken over by another output. The caller must hold
+// the lock of the unit.
+func stopFlush(unit *outputUnit, output *models.RunningOutput, handover bool) {
+	run, found := unit.running[output]
+	if !found {
+		return
+	}
+	delete(unit.running, output)
+
+	if handover {
+		close(run.handover)
+	} else {
+		run.cancel()
+	}
+	<-run.done
+	run.cancel()
+}
+
 // flushLoop runs an output's flush function periodically until the context is
-// done.
+// done or the output is handed over.
 func (a *Agent) flushLoop(
 	ctx context.Context,
 	output *models.RunningOutput,
 	ticker Ticker,
+	handover <-chan struct{},
 ) {
 	logError := func(err error) {
 		if err != nil {
@@ -898,17 +1213,25 @@ func (a *Agent) flushLoop(
 	defer stopListeningForFlushSignal(flushRequested)
 
 	for {
-		// Favor shutdown over other methods.
+		// Favor handover and shutdown over other methods.
+		select {
+		case <-handover:
+			return
+		default:
+		}
+
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.shutdownFlush(output, ticker))
 			return
 		default:
 		}
 
 		select {
+		case <-handover:
+			return
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.shutdownFlush(output, ticker))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
@@ -920,6 +1243,15 @@ func (a *Agent) flushLoop(
 	}
 }
 
+// shutdownFlush writes the buffered metrics of the output on shutdown
+// according to the shutdown policy.
+func (a *Agent) shutdownFlush(output *models.RunningOutput, ticker Ticker) error {
+	if a.Config.Agent.ShutdownPolicy == "drain" {
+		return a.drainOutput(output)
+	}
+	return a.flushOnce(output, ticker, output.Write)
+}
+
 // flushOnce runs the output's Write function once, logging a warning each interval it fails to complete before the flush interval elapses.
 func (*Agent) flushOnce(output *models.RunningOutput, ticker Ticker, writeFunc func() error) error {
 	done := make(chan error)
@@ -1025,6 +1357,11 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 		}
 	}
 
+	next, su, err := a.setupSeries(next, a.Config.Tags)
+	if err != nil {
+		return err
+	}
+
 	iu := a.testStartInputs(next, a.Config.Inputs)
 
 	var wg sync.WaitGroup
@@ -1050,6 +1387,14 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 		}()
 	}
 
+	if su != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runSeries(su)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -1102,8 +1447,29 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
+	globalTags := a.Config.Tags
+	enricher, err := newTagEnricher(a.Config.Agent, a.Config.Tags)
+	if err != nil {
+		return err
+	}
+	if enricher != nil {
+		log.Printf("D! [agent] Resolving global tags")
+		globalTags = enricher.refresh(ctx)
+		a.setGlobalTags(globalTags)
+		if enricher.interval > 0 {
+			stopEnrichment := a.startEnrichment(enricher)
+			defer stopEnrichment()
+		}
+	}
+
 	startTime := time.Now()
 
+	deadLetterTargets, err := resolveDeadLetterOutputs(a.Config.Outputs)
+	if err != nil {
+		return err
+	}
+	linkDeadLetterOutputs(a.Config.Outputs, deadLetterTargets)
+
 	log.Printf("D! [agent] Connecting outputs")
 	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
 	if err != nil {
@@ -1132,6 +1498,11 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		}
 	}
 
+	next, su, err := a.setupSeries(next, globalTags)
+	if err != nil {
+		return err
+	}
+
 	iu := a.testStartInputs(next, a.Config.Inputs)
 
 	var wg sync.WaitGroup
@@ -1163,6 +1534,14 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		}()
 	}
 
+	if su != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runSeries(su)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// Interval for checking the outputs for backpressure
+const backpressureCheckInterval = time.Second
+
+// backpressureEnabled returns true if any of the outputs is configured to
+// signal backpressure
+func backpressureEnabled(outputs []*models.RunningOutput) bool {
+	for _, output := range outputs {
+		if output.Config.BackpressureThreshold > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runBackpressure periodically checks the outputs for backpressure and pauses
+// the service inputs supporting it while any output signals backpressure.
+// Inputs are resumed once all outputs recovered. The function returns when
+// the context is done.
+func (*Agent) runBackpressure(ctx context.Context, iu *inputUnit, ou *outputUnit) {
+	ticker := time.NewTicker(backpressureCheckInterval)
+	defer ticker.Stop()
+
+	paused := make(map[*models.RunningInput]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ou.RLock()
+			var pressure bool
+			for _, output := range ou.outputs {
+				// Evaluate all outputs to keep their state up-to-date
+				if output.Backpressure(now) {
+					pressure = true
+				}
+			}
+			ou.RUnlock()
+
+			iu.Lock()
+			if iu.stopped {
+				iu.Unlock()
+				return
+			}
+			if pressure {
+				// Also pause inputs added by configuration changes
+				for _, input := range iu.inputs {
+					if paused[input] {
+						continue
+					}
+					if input.Pause() {
+						log.Printf("I! [agent] Pausing %s due to output backpressure", input.LogName())
+						paused[input] = true
+					}
+				}
+			} else if len(paused) > 0 {
+				for _, input := range iu.inputs {
+					if paused[input] {
+						log.Printf("I! [agent] Resuming %s", input.LogName())
+						input.Resume()
+					}
+				}
+				clear(paused)
+			}
+			iu.Unlock()
+		}
+	}
+}
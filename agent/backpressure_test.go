@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestBackpressurePausesInputs(t *testing.T) {
+	input := &pausableInput{}
+	ri := models.NewRunningInput(input, &models.InputConfig{Name: "mock"})
+	iu := &inputUnit{inputs: []*models.RunningInput{ri}}
+
+	ro := models.NewRunningOutput(
+		&reloadOutput{},
+		&models.OutputConfig{Name: "mock", BackpressureThreshold: 50},
+		1, 4,
+	)
+	require.NoError(t, ro.Init())
+	ou := &outputUnit{outputs: []*models.RunningOutput{ro}}
+	require.True(t, backpressureEnabled(ou.outputs))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	a := &Agent{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.runBackpressure(ctx, iu, ou)
+	}()
+
+	// Fill the buffer above the threshold
+	for i := range 3 {
+		ro.AddMetric(testutil.TestMetric(i))
+	}
+	require.Eventually(t, input.paused.Load, 5*time.Second, 100*time.Millisecond)
+
+	// Drain the buffer to resume the input
+	for range 3 {
+		require.NoError(t, ro.WriteBatch())
+	}
+	require.Eventually(t, func() bool { return !input.paused.Load() }, 5*time.Second, 100*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+type pausableInput struct {
+	paused atomic.Bool
+}
+
+func (*pausableInput) SampleConfig() string {
+	return ""
+}
+
+func (*pausableInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*pausableInput) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*pausableInput) Stop() {}
+
+func (i *pausableInput) Pause() {
+	i.paused.Store(true)
+}
+
+func (i *pausableInput) Resume() {
+	i.paused.Store(false)
+}
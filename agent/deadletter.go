@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// resolveDeadLetterOutputs returns the dead-letter output referenced by each
+// of the given outputs via its alias. Outputs without a dead-letter output are
+// omitted. To avoid loops, dead-letter outputs cannot forward rejected metrics
+// to another output themselves.
+func resolveDeadLetterOutputs(outputs []*models.RunningOutput) (map[*models.RunningOutput]*models.RunningOutput, error) {
+	byAlias := make(map[string]*models.RunningOutput, len(outputs))
+	for _, output := range outputs {
+		if output.Config.Alias != "" {
+			byAlias[output.Config.Alias] = output
+		}
+	}
+
+	targets := make(map[*models.RunningOutput]*models.RunningOutput)
+	for _, output := range outputs {
+		alias := output.Config.DeadLetterOutput
+		if alias == "" {
+			continue
+		}
+
+		target, found := byAlias[alias]
+		switch {
+		case !found:
+			return nil, fmt.Errorf("dead-letter output %q of %s not found", alias, output.LogName())
+		case target == output:
+			return nil, fmt.Errorf("output %s cannot be its own dead-letter output", output.LogName())
+		case target.Config.DeadLetterOutput != "":
+			return nil, fmt.Errorf("dead-letter output %s of %s cannot have a dead-letter output itself",
+				target.LogName(), output.LogName())
+		}
+		targets[output] = target
+	}
+	return targets, nil
+}
+
+// linkDeadLetterOutputs sets the resolved dead-letter output on each of the
+// given outputs, unsetting it for outputs without one.
+func linkDeadLetterOutputs(outputs []*models.RunningOutput, targets map[*models.RunningOutput]*models.RunningOutput) {
+	for _, output := range outputs {
+		output.SetDeadLetterOutput(targets[output])
+	}
+}
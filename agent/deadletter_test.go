@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestResolveDeadLetterOutputs(t *testing.T) {
+	primary := models.NewRunningOutput(&reloadOutput{}, &models.OutputConfig{Name: "mock", DeadLetterOutput: "rejected"}, 1, 1)
+	other := models.NewRunningOutput(&reloadOutput{}, &models.OutputConfig{Name: "mock", Alias: "other"}, 1, 1)
+	rejected := models.NewRunningOutput(&reloadOutput{}, &models.OutputConfig{Name: "mock", Alias: "rejected"}, 1, 1)
+
+	targets, err := resolveDeadLetterOutputs([]*models.RunningOutput{primary, other, rejected})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Same(t, rejected, targets[primary])
+}
+
+func TestResolveDeadLetterOutputsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		outputs  []*models.OutputConfig
+		expected string
+	}{
+		{
+			name: "not found",
+			outputs: []*models.OutputConfig{
+				{Name: "mock", DeadLetterOutput: "rejected"},
+			},
+			expected: `dead-letter output "rejected" of outputs.mock not found`,
+		},
+		{
+			name: "self",
+			outputs: []*models.OutputConfig{
+				{Name: "mock", Alias: "rejected", DeadLetterOutput: "rejected"},
+			},
+			expected: "cannot be its own dead-letter output",
+		},
+		{
+			name: "chained",
+			outputs: []*models.OutputConfig{
+				{Name: "mock", Alias: "primary", DeadLetterOutput: "secondary"},
+				{Name: "mock", Alias: "secondary", DeadLetterOutput: "tertiary"},
+				{Name: "mock", Alias: "tertiary"},
+			},
+			expected: "cannot have a dead-letter output itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := make([]*models.RunningOutput, 0, len(tt.outputs))
+			for _, cfg := range tt.outputs {
+				outputs = append(outputs, models.NewRunningOutput(&reloadOutput{}, cfg, 1, 1))
+			}
+			_, err := resolveDeadLetterOutputs(outputs)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+	drainRetryInterval  = time.Second
+
+	// Age after which spill files not belonging to any output are removed
+	spillFileExpiry = 7 * 24 * time.Hour
+)
+
+// drainSummary accumulates the result of draining the outputs on shutdown
+type drainSummary struct {
+	sync.Mutex
+	flushed int
+	spilled int
+	dropped int
+}
+
+func checkShutdownPolicy(cfg *config.AgentConfig) error {
+	switch cfg.ShutdownPolicy {
+	case "", "flush":
+		if cfg.ShutdownSpillDirectory != "" {
+			return errors.New("'shutdown_spill_directory' requires the \"drain\" shutdown policy")
+		}
+	case "drain":
+		if cfg.ShutdownDrainTimeout < 0 {
+			return fmt.Errorf("invalid shutdown drain timeout %s", time.Duration(cfg.ShutdownDrainTimeout))
+		}
+	default:
+		return fmt.Errorf("invalid shutdown policy %q", cfg.ShutdownPolicy)
+	}
+	return nil
+}
+
+// drainOutput writes the buffered metrics of the output until the buffer is
+// empty or the drain timeout elapsed. Metrics still buffered afterwards are
+// spilled to disk if configured and dropped otherwise.
+func (a *Agent) drainOutput(output *models.RunningOutput) error {
+	timeout := time.Duration(a.Config.Agent.ShutdownDrainTimeout)
+	if output.Config.DrainTimeout > 0 {
+		timeout = output.Config.DrainTimeout
+	}
+	if timeout == 0 {
+		timeout = defaultDrainTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	initial := output.BufferLength()
+	var err error
+	for remaining := initial; remaining > 0; {
+		err = output.Write()
+		output.LogBufferStatus()
+
+		previous := remaining
+		remaining = output.BufferLength()
+		if remaining == 0 || time.Now().Add(drainRetryInterval).After(deadline) {
+			break
+		}
+		if remaining >= previous || err != nil {
+			log.Printf("D! [agent] Retrying to drain %d metrics of %s", remaining, output.LogName())
+			time.Sleep(drainRetryInterval)
+		}
+	}
+
+	remaining := output.BufferLength()
+	var spilled, dropped int
+	if remaining > 0 {
+		switch {
+		case output.Config.BufferStrategy == "disk":
+			log.Printf("I! [agent] Keeping %d metrics of %s in the disk buffer", remaining, output.LogName())
+		case a.Config.Agent.ShutdownSpillDirectory != "":
+			n, serr := output.SpillBuffer(spillFilename(a.Config.Agent.ShutdownSpillDirectory, output))
+			if serr != nil {
+				log.Printf("E! [agent] Spilling metrics of %s failed: %v", output.LogName(), serr)
+			}
+			spilled, dropped = n, remaining-n
+		default:
+			dropped = remaining
+		}
+	}
+	flushed := max(initial-remaining, 0)
+
+	log.Printf("I! [agent] Drained %s: %d flushed, %d spilled, %d dropped", output.LogName(), flushed, spilled, dropped)
+	a.drained.Lock()
+	a.drained.flushed += flushed
+	a.drained.spilled += spilled
+	a.drained.dropped += dropped
+	a.drained.Unlock()
+
+	if remaining > 0 && err == nil {
+		err = fmt.Errorf("draining timed out after %s", timeout)
+	}
+	return err
+}
+
+// restoreSpilled adds the metrics spilled on the last shutdown to the buffers
+// of the outputs and removes the spill files. Spill files not belonging to any
+// output, e.g. because the output's ID changed, are reported and removed once
+// expired.
+func (a *Agent) restoreSpilled() error {
+	dir := a.Config.Agent.ShutdownSpillDirectory
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating spill directory failed: %w", err)
+	}
+
+	known := make(map[string]bool, len(a.Config.Outputs))
+	for _, output := range a.Config.Outputs {
+		filename := spillFilename(dir, output)
+		known[filename] = true
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("reading spilled metrics of %s failed: %w", output.LogName(), err)
+		}
+
+		parser := &influx.Parser{}
+		if err := parser.Init(); err != nil {
+			return err
+		}
+		metrics, err := parser.Parse(buf)
+		if err != nil {
+			return fmt.Errorf("parsing spilled metrics of %s failed: %w", output.LogName(), err)
+		}
+		dropped := output.AddBuffered(metrics)
+		log.Printf("I! [agent] Restored %d spilled metrics of %s, %d dropped", len(metrics)-dropped, output.LogName(), dropped)
+
+		if err := os.Remove(filename); err != nil {
+			return fmt.Errorf("removing spilled metrics of %s failed: %w", output.LogName(), err)
+		}
+	}
+
+	return expireSpilled(dir, known, time.Now())
+}
+
+// expireSpilled logs the spill files in the directory not contained in the
+// given known files and removes the ones older than the expiry period
+func expireSpilled(dir string, known map[string]bool, now time.Time) error {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.spill"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		if known[filename] {
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("checking spill file failed: %w", err)
+		}
+		age := now.Sub(info.ModTime())
+		if age < spillFileExpiry {
+			log.Printf("W! [agent] Spill file %q does not belong to any output, removing it after %s",
+				filename, spillFileExpiry-age.Truncate(time.Second))
+			continue
+		}
+		log.Printf("W! [agent] Removing expired spill file %q not belonging to any output", filename)
+		if err := os.Remove(filename); err != nil {
+			return fmt.Errorf("removing expired spill file failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// spillFilename returns the file to spill the metrics of the output to. The
+// plugin ID is used as name to be stable across restarts.
+func spillFilename(dir string, output *models.RunningOutput) string {
+	id := output.ID()
+	if id == "" {
+		id = strings.ReplaceAll(output.LogName(), ":", "_")
+	}
+	return filepath.Join(dir, id+".spill")
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestCheckShutdownPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.AgentConfig
+		expected string
+	}{
+		{
+			name: "default",
+			cfg:  &config.AgentConfig{},
+		},
+		{
+			name: "drain with spilling",
+			cfg:  &config.AgentConfig{ShutdownPolicy: "drain", ShutdownSpillDirectory: "/var/lib/telegraf/spill"},
+		},
+		{
+			name:     "invalid policy",
+			cfg:      &config.AgentConfig{ShutdownPolicy: "wait"},
+			expected: `invalid shutdown policy "wait"`,
+		},
+		{
+			name:     "spilling without draining",
+			cfg:      &config.AgentConfig{ShutdownSpillDirectory: "/var/lib/telegraf/spill"},
+			expected: "requires the \"drain\" shutdown policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkShutdownPolicy(tt.cfg)
+			if tt.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDrainOutputRetries(t *testing.T) {
+	plugin := &flakyOutput{failures: 1}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "mock"}, 2, 100)
+	for i := range 5 {
+		output.AddMetric(testutil.TestMetric(i))
+	}
+
+	cfg := config.NewConfig()
+	cfg.Agent.ShutdownPolicy = "drain"
+	a := NewAgent(cfg)
+
+	require.NoError(t, a.drainOutput(output))
+	require.Zero(t, output.BufferLength())
+	require.Len(t, plugin.Metrics(), 5)
+	require.Equal(t, 5, a.drained.flushed)
+	require.Zero(t, a.drained.dropped)
+}
+
+func TestDrainOutputSpillAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	expected := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		expected = append(expected, metric.New(
+			"test",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"value": int64(i), "count": uint64(i)},
+			time.Unix(1700000000, int64(i)),
+		))
+	}
+
+	// Draining an output failing permanently spills the metrics
+	cfg := config.NewConfig()
+	cfg.Agent.ShutdownPolicy = "drain"
+	cfg.Agent.ShutdownSpillDirectory = dir
+	outputCfg := &models.OutputConfig{Name: "mock", ID: "abc123", DrainTimeout: time.Millisecond}
+	output := models.NewRunningOutput(&flakyOutput{failures: -1}, outputCfg, 10, 100)
+	for _, m := range expected {
+		output.AddMetric(m)
+	}
+
+	a := NewAgent(cfg)
+	require.ErrorContains(t, a.drainOutput(output), "failed write")
+	require.Equal(t, 3, a.drained.spilled)
+	require.Zero(t, a.drained.dropped)
+	require.FileExists(t, filepath.Join(dir, "abc123.spill"))
+
+	// The spilled metrics are restored to the output with the same ID
+	plugin := &flakyOutput{}
+	cfg.Outputs = append(cfg.Outputs, models.NewRunningOutput(plugin, outputCfg, 10, 100))
+	a = NewAgent(cfg)
+	require.NoError(t, a.restoreSpilled())
+	require.NoFileExists(t, filepath.Join(dir, "abc123.spill"))
+
+	require.NoError(t, cfg.Outputs[0].Write())
+	testutil.RequireMetricsEqual(t, expected, plugin.Metrics())
+}
+
+func TestRestoreSpilledUnknownOutput(t *testing.T) {
+	dir := t.TempDir()
+	recent := filepath.Join(dir, "recent.spill")
+	expired := filepath.Join(dir, "expired.spill")
+	for _, fn := range []string{recent, expired} {
+		require.NoError(t, os.WriteFile(fn, []byte("test value=42i 1700000000000000000\n"), 0600))
+	}
+	past := time.Now().Add(-spillFileExpiry - time.Hour)
+	require.NoError(t, os.Chtimes(expired, past, past))
+
+	// Spill files of outputs no longer configured are kept until expired
+	cfg := config.NewConfig()
+	cfg.Agent.ShutdownPolicy = "drain"
+	cfg.Agent.ShutdownSpillDirectory = dir
+	outputCfg := &models.OutputConfig{Name: "mock", ID: "abc123"}
+	cfg.Outputs = append(cfg.Outputs, models.NewRunningOutput(&flakyOutput{}, outputCfg, 10, 100))
+
+	a := NewAgent(cfg)
+	require.NoError(t, a.restoreSpilled())
+	require.FileExists(t, recent)
+	require.NoFileExists(t, expired)
+	require.Zero(t, cfg.Outputs[0].BufferLength())
+}
+
+func TestDrainOutputDrop(t *testing.T) {
+	output := models.NewRunningOutput(
+		&flakyOutput{failures: -1},
+		&models.OutputConfig{Name: "mock", DrainTimeout: time.Millisecond},
+		10,
+		100,
+	)
+	output.AddMetric(testutil.TestMetric(1))
+	output.AddMetric(testutil.TestMetric(2))
+
+	cfg := config.NewConfig()
+	cfg.Agent.ShutdownPolicy = "drain"
+	a := NewAgent(cfg)
+	require.Error(t, a.drainOutput(output))
+	require.Zero(t, a.drained.flushed)
+	require.Equal(t, 2, a.drained.dropped)
+	require.Zero(t, a.drained.spilled)
+}
+
+// flakyOutput fails the given number of writes, or all writes if negative,
+// before accepting the metrics
+type flakyOutput struct {
+	reloadOutput
+	failures int
+}
+
+func (o *flakyOutput) Write(metrics []telegraf.Metric) error {
+	if o.failures != 0 {
+		if o.failures > 0 {
+			o.failures--
+		}
+		return errors.New("failed write")
+	}
+	return o.reloadOutput.Write(metrics)
+}
@@ -0,0 +1,345 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+const metadataTimeout = 5 * time.Second
+
+// Default endpoints of the instance metadata services
+const (
+	defaultEC2MetadataURL   = "http://169.254.169.254"
+	defaultGCEMetadataURL   = "http://metadata.google.internal"
+	defaultAzureMetadataURL = "http://169.254.169.254"
+)
+
+// Environment variables commonly populated via the Kubernetes downward API and
+// the tags they are reported as
+var kubernetesEnvTags = map[string]string{
+	"POD_NAME":      "pod_name",
+	"POD_NAMESPACE": "namespace",
+	"NODE_NAME":     "node_name",
+}
+
+// tagEnricher resolves global tags from instance metadata sources. The tags
+// configured statically take precedence over the resolved ones.
+type tagEnricher struct {
+	sources  []string
+	file     string
+	interval time.Duration
+	static   map[string]string
+	client   *http.Client
+
+	// Endpoints of the metadata services, overridden in tests
+	ec2URL   string
+	gceURL   string
+	azureURL string
+
+	// Last successfully resolved tags per source
+	mu       sync.Mutex
+	resolved map[string]map[string]string
+}
+
+func newTagEnricher(cfg *config.AgentConfig, static map[string]string) (*tagEnricher, error) {
+	if len(cfg.GlobalTagsSources) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(cfg.GlobalTagsSources))
+	for _, source := range cfg.GlobalTagsSources {
+		switch source {
+		case "ec2", "gce", "azure", "kubernetes":
+		case "file":
+			if cfg.GlobalTagsFile == "" {
+				return nil, errors.New("global tags source \"file\" requires 'global_tags_file'")
+			}
+		default:
+			return nil, fmt.Errorf("invalid global tags source %q", source)
+		}
+		if seen[source] {
+			return nil, fmt.Errorf("duplicate global tags source %q", source)
+		}
+		seen[source] = true
+	}
+
+	return &tagEnricher{
+		sources:  cfg.GlobalTagsSources,
+		file:     cfg.GlobalTagsFile,
+		interval: time.Duration(cfg.GlobalTagsRefreshInterval),
+		static:   static,
+		client:   &http.Client{Timeout: metadataTimeout},
+		ec2URL:   defaultEC2MetadataURL,
+		gceURL:   defaultGCEMetadataURL,
+		azureURL: defaultAzureMetadataURL,
+		resolved: make(map[string]map[string]string),
+	}, nil
+}
+
+// refresh resolves the tags of all sources and returns the resulting global
+// tags. Sources failing to resolve keep the tags of the last successful
+// resolution. Later sources take precedence over earlier ones.
+func (e *tagEnricher) refresh(ctx context.Context) map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, source := range e.sources {
+		tags, err := e.resolve(ctx, source)
+		if err != nil {
+			log.Printf("W! [agent] Resolving global tags from %q failed: %v", source, err)
+			continue
+		}
+		e.resolved[source] = tags
+	}
+
+	merged := make(map[string]string, len(e.static))
+	for _, source := range e.sources {
+		maps.Copy(merged, e.resolved[source])
+	}
+	maps.Copy(merged, e.static)
+
+	return merged
+}
+
+func (e *tagEnricher) resolve(ctx context.Context, source string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	switch source {
+	case "ec2":
+		return e.resolveEC2(ctx)
+	case "gce":
+		return e.resolveGCE(ctx)
+	case "azure":
+		return e.resolveAzure(ctx)
+	case "kubernetes":
+		return resolveKubernetes()
+	case "file":
+		return resolveFile(e.file)
+	}
+	return nil, fmt.Errorf("unknown source %q", source)
+}
+
+// resolveEC2 queries the instance identity document using IMDSv2
+func (e *tagEnricher) resolveEC2(ctx context.Context) (map[string]string, error) {
+	token, err := e.request(ctx, http.MethodPut, e.ec2URL+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting token failed: %w", err)
+	}
+
+	body, err := e.request(ctx, http.MethodGet, e.ec2URL+"/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": string(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		Region           string `json:"region"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("decoding identity document failed: %w", err)
+	}
+
+	return nonEmpty(map[string]string{
+		"account_id":        doc.AccountID,
+		"availability_zone": doc.AvailabilityZone,
+		"instance_id":       doc.InstanceID,
+		"instance_type":     doc.InstanceType,
+		"region":            doc.Region,
+	}), nil
+}
+
+func (e *tagEnricher) resolveGCE(ctx context.Context) (map[string]string, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+	body, err := e.request(ctx, http.MethodGet, e.gceURL+"/computeMetadata/v1/instance/?recursive=true", header)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance struct {
+		ID          json.Number `json:"id"`
+		MachineType string      `json:"machineType"`
+		Zone        string      `json:"zone"`
+	}
+	if err := json.Unmarshal(body, &instance); err != nil {
+		return nil, fmt.Errorf("decoding instance metadata failed: %w", err)
+	}
+
+	project, err := e.request(ctx, http.MethodGet, e.gceURL+"/computeMetadata/v1/project/project-id", header)
+	if err != nil {
+		return nil, err
+	}
+
+	// The zone and machine type are given as resource paths e.g.
+	// "projects/123/zones/us-central1-a"
+	zone := path.Base(instance.Zone)
+	var region string
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+
+	return nonEmpty(map[string]string{
+		"instance_id":  instance.ID.String(),
+		"machine_type": path.Base(instance.MachineType),
+		"project_id":   string(project),
+		"region":       region,
+		"zone":         zone,
+	}), nil
+}
+
+func (e *tagEnricher) resolveAzure(ctx context.Context) (map[string]string, error) {
+	body, err := e.request(ctx, http.MethodGet, e.azureURL+"/metadata/instance/compute?api-version=2021-02-01", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var compute struct {
+		Location          string `json:"location"`
+		ResourceGroupName string `json:"resourceGroupName"`
+		SubscriptionID    string `json:"subscriptionId"`
+		VMID              string `json:"vmId"`
+		VMSize            string `json:"vmSize"`
+	}
+	if err := json.Unmarshal(body, &compute); err != nil {
+		return nil, fmt.Errorf("decoding instance metadata failed: %w", err)
+	}
+
+	return nonEmpty(map[string]string{
+		"location":        compute.Location,
+		"resource_group":  compute.ResourceGroupName,
+		"subscription_id": compute.SubscriptionID,
+		"vm_id":           compute.VMID,
+		"vm_size":         compute.VMSize,
+	}), nil
+}
+
+func resolveKubernetes() (map[string]string, error) {
+	tags := make(map[string]string, len(kubernetesEnvTags))
+	for env, key := range kubernetesEnvTags {
+		if v := os.Getenv(env); v != "" {
+			tags[key] = v
+		}
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("none of POD_NAME, POD_NAMESPACE or NODE_NAME is set")
+	}
+	return tags, nil
+}
+
+// resolveFile reads the tags from a JSON file containing a flat object,
+// non-string values are converted to strings and nested values are ignored
+func resolveFile(filename string) (map[string]string, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var facts map[string]interface{}
+	if err := json.Unmarshal(buf, &facts); err != nil {
+		return nil, fmt.Errorf("decoding %q failed: %w", filename, err)
+	}
+
+	tags := make(map[string]string, len(facts))
+	for k, v := range facts {
+		switch v := v.(type) {
+		case string:
+			tags[k] = v
+		case float64, bool:
+			tags[k] = fmt.Sprint(v)
+		}
+	}
+	return nonEmpty(tags), nil
+}
+
+func (e *tagEnricher) request(ctx context.Context, method, address string, header map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting %q returned status %q", address, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func nonEmpty(tags map[string]string) map[string]string {
+	maps.DeleteFunc(tags, func(_, v string) bool { return v == "" })
+	return tags
+}
+
+// setGlobalTags applies the given global tags to all running inputs, the
+// inputs added later on and the series tracker
+func (a *Agent) setGlobalTags(tags map[string]string) {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	a.globalTags = tags
+	for _, input := range a.Config.Inputs {
+		input.SetDefaultTags(tags)
+	}
+	if a.series != nil {
+		a.series.setGlobalTags(tags)
+	}
+}
+
+// startEnrichment periodically refreshes the global tags until the returned
+// function is called.
+func (a *Agent) startEnrichment(enricher *tagEnricher) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(enricher.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.setGlobalTags(enricher.refresh(ctx))
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
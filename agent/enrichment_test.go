@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestTagEnricherDisabled(t *testing.T) {
+	enricher, err := newTagEnricher(&config.AgentConfig{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, enricher)
+}
+
+func TestTagEnricherInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.AgentConfig
+		expected string
+	}{
+		{
+			name:     "unknown source",
+			cfg:      &config.AgentConfig{GlobalTagsSources: []string{"ec2", "openstack"}},
+			expected: `invalid global tags source "openstack"`,
+		},
+		{
+			name:     "duplicate source",
+			cfg:      &config.AgentConfig{GlobalTagsSources: []string{"gce", "gce"}},
+			expected: `duplicate global tags source "gce"`,
+		},
+		{
+			name:     "file without filename",
+			cfg:      &config.AgentConfig{GlobalTagsSources: []string{"file"}},
+			expected: "requires 'global_tags_file'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTagEnricher(tt.cfg, nil)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestTagEnricherCloud(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch r.URL.Path {
+		case "/latest/api/token":
+			if r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			body = "secret"
+		case "/latest/dynamic/instance-identity/document":
+			if r.Header.Get("X-aws-ec2-metadata-token") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body = `{
+				"accountId": "123456789012",
+				"availabilityZone": "eu-central-1a",
+				"instanceId": "i-0123456789abcdef0",
+				"instanceType": "t3.micro",
+				"region": "eu-central-1",
+				"imageId": "ami-12345678"
+			}`
+		case "/computeMetadata/v1/instance/":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			body = `{
+				"id": 4520031799277581759,
+				"machineType": "projects/123/machineTypes/e2-medium",
+				"zone": "projects/123/zones/us-central1-a"
+			}`
+		case "/computeMetadata/v1/project/project-id":
+			body = "my-project"
+		case "/metadata/instance/compute":
+			if r.Header.Get("Metadata") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = `{
+				"location": "westeurope",
+				"resourceGroupName": "telegraf",
+				"subscriptionId": "8d10da13-8125-4ba9-a717-bf7490507b3d",
+				"vmId": "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+				"vmSize": "Standard_A3"
+			}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		source   string
+		expected map[string]string
+	}{
+		{
+			source: "ec2",
+			expected: map[string]string{
+				"account_id":        "123456789012",
+				"availability_zone": "eu-central-1a",
+				"instance_id":       "i-0123456789abcdef0",
+				"instance_type":     "t3.micro",
+				"region":            "eu-central-1",
+			},
+		},
+		{
+			source: "gce",
+			expected: map[string]string{
+				"instance_id":  "4520031799277581759",
+				"machine_type": "e2-medium",
+				"project_id":   "my-project",
+				"region":       "us-central1",
+				"zone":         "us-central1-a",
+			},
+		},
+		{
+			source: "azure",
+			expected: map[string]string{
+				"location":        "westeurope",
+				"resource_group":  "telegraf",
+				"subscription_id": "8d10da13-8125-4ba9-a717-bf7490507b3d",
+				"vm_id":           "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+				"vm_size":         "Standard_A3",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			cfg := &config.AgentConfig{GlobalTagsSources: []string{tt.source}}
+			enricher, err := newTagEnricher(cfg, nil)
+			require.NoError(t, err)
+			enricher.ec2URL = server.URL
+			enricher.gceURL = server.URL
+			enricher.azureURL = server.URL
+
+			require.Equal(t, tt.expected, enricher.refresh(t.Context()))
+		})
+	}
+}
+
+func TestTagEnricherPrecedence(t *testing.T) {
+	t.Setenv("POD_NAME", "telegraf-abcde")
+	t.Setenv("POD_NAMESPACE", "monitoring")
+	t.Setenv("NODE_NAME", "")
+
+	filename := filepath.Join(t.TempDir(), "facts.json")
+	facts := `{"namespace": "overridden", "datacenter": "fra1", "rack": 12, "nested": {"a": "b"}, "dc": "ignored"}`
+	require.NoError(t, os.WriteFile(filename, []byte(facts), 0600))
+
+	cfg := &config.AgentConfig{
+		GlobalTagsSources: []string{"kubernetes", "file"},
+		GlobalTagsFile:    filename,
+	}
+	enricher, err := newTagEnricher(cfg, map[string]string{"dc": "static", "host": "localhost"})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"pod_name":   "telegraf-abcde",
+		"namespace":  "overridden",
+		"datacenter": "fra1",
+		"rack":       "12",
+		"dc":         "static",
+		"host":       "localhost",
+	}
+	require.Equal(t, expected, enricher.refresh(t.Context()))
+
+	// Failing sources keep the last resolved tags
+	require.NoError(t, os.Remove(filename))
+	require.Equal(t, expected, enricher.refresh(t.Context()))
+}
+
+func TestSetGlobalTags(t *testing.T) {
+	input := models.NewRunningInput(&failingInput{healthy: true}, &models.InputConfig{Name: "mock"})
+	input.SetDefaultTags(map[string]string{"host": "localhost"})
+
+	cfg := config.NewConfig()
+	cfg.Inputs = append(cfg.Inputs, input)
+	a := NewAgent(cfg)
+
+	tags := map[string]string{"host": "localhost", "region": "eu-central-1"}
+	a.setGlobalTags(tags)
+	require.Equal(t, tags, input.DefaultTags())
+	require.Equal(t, tags, a.globalTags)
+}
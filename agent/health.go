@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+// healthService serves the health and readiness status of the agent and its
+// plugins via HTTP.
+//
+// The liveness endpoint "/healthz" reports failure if any plugin exceeds the
+// configured health thresholds. The readiness endpoint "/readyz" reports
+// failure unless all plugins are started and the agent is not shutting down.
+type healthService struct {
+	maxGatherFailures int
+	maxWriteFailures  int
+	maxBufferFullness int
+
+	server   *http.Server
+	listener net.Listener
+
+	// Running units of the agent, nil while not running
+	sync.Mutex
+	inputs  *inputUnit
+	outputs *outputUnit
+}
+
+type healthReport struct {
+	Status  string               `json:"status"`
+	Inputs  []inputHealthReport  `json:"inputs"`
+	Outputs []outputHealthReport `json:"outputs"`
+}
+
+type inputHealthReport struct {
+	Name                      string     `json:"name"`
+	Alias                     string     `json:"alias,omitempty"`
+	ID                        string     `json:"id"`
+	Healthy                   bool       `json:"healthy"`
+	LastGather                *time.Time `json:"last_gather,omitempty"`
+	LastGatherSuccess         bool       `json:"last_gather_success"`
+	ConsecutiveGatherFailures int        `json:"consecutive_gather_failures"`
+}
+
+type outputHealthReport struct {
+	Name                     string     `json:"name"`
+	Alias                    string     `json:"alias,omitempty"`
+	ID                       string     `json:"id"`
+	Healthy                  bool       `json:"healthy"`
+	BufferSize               int        `json:"buffer_size"`
+	BufferLimit              int        `json:"buffer_limit,omitempty"`
+	BufferFullness           float64    `json:"buffer_fullness,omitempty"`
+	LastWrite                *time.Time `json:"last_write,omitempty"`
+	ConsecutiveWriteFailures int        `json:"consecutive_write_failures"`
+}
+
+func newHealthService(cfg *config.AgentConfig) (*healthService, error) {
+	if cfg.HealthMaxGatherFailures < 0 {
+		return nil, errors.New("invalid 'health_max_gather_failures' setting")
+	}
+	if cfg.HealthMaxWriteFailures < 0 {
+		return nil, errors.New("invalid 'health_max_write_failures' setting")
+	}
+	if cfg.HealthMaxBufferFullness < 0 || cfg.HealthMaxBufferFullness > 100 {
+		return nil, errors.New("invalid 'health_max_buffer_fullness' setting")
+	}
+
+	h := &healthService{
+		maxGatherFailures: cfg.HealthMaxGatherFailures,
+		maxWriteFailures:  cfg.HealthMaxWriteFailures,
+		maxBufferFullness: cfg.HealthMaxBufferFullness,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", h.serveHealth)
+	mux.HandleFunc("GET /readyz", h.serveReady)
+	h.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return h, nil
+}
+
+// start listens on the given address and serves the endpoints in the
+// background until stop is called.
+func (h *healthService) start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	h.listener = listener
+
+	log.Printf("I! [agent] Serving health endpoints on %s", listener.Addr())
+	go func() {
+		if err := h.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving health endpoints failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (h *healthService) stop() {
+	if err := h.server.Close(); err != nil {
+		log.Printf("E! [agent] Stopping health endpoints failed: %v", err)
+	}
+}
+
+// setUnits sets the running units of the agent to report on, nil values
+// indicate the agent is not running.
+func (h *healthService) setUnits(iu *inputUnit, ou *outputUnit) {
+	h.Lock()
+	defer h.Unlock()
+	h.inputs, h.outputs = iu, ou
+}
+
+func (h *healthService) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	report, healthy, _ := h.report()
+	report.Status = "ok"
+	if !healthy {
+		report.Status = "failing"
+	}
+	h.respond(w, healthy, report)
+}
+
+func (h *healthService) serveReady(w http.ResponseWriter, _ *http.Request) {
+	report, _, ready := h.report()
+	report.Status = "ready"
+	if !ready {
+		report.Status = "not_ready"
+	}
+	h.respond(w, ready, report)
+}
+
+func (*healthService) respond(w http.ResponseWriter, ok bool, report *healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("E! [agent] Writing health report failed: %v", err)
+	}
+}
+
+// report collects the status of all plugins and returns whether all of them
+// are healthy and the agent is ready.
+func (h *healthService) report() (report *healthReport, healthy, ready bool) {
+	h.Lock()
+	iu, ou := h.inputs, h.outputs
+	h.Unlock()
+
+	report = &healthReport{
+		Inputs:  make([]inputHealthReport, 0),
+		Outputs: make([]outputHealthReport, 0),
+	}
+	healthy = true
+	if iu == nil || ou == nil {
+		return report, healthy, false
+	}
+
+	iu.Lock()
+	ready = !iu.stopped
+	for _, input := range iu.inputs {
+		ir := h.inputReport(input)
+		healthy = healthy && ir.Healthy
+		report.Inputs = append(report.Inputs, ir)
+	}
+	iu.Unlock()
+
+	ou.RLock()
+	ready = ready && !ou.stopped
+	for _, output := range ou.outputs {
+		or := h.outputReport(output)
+		healthy = healthy && or.Healthy
+		report.Outputs = append(report.Outputs, or)
+	}
+	ou.RUnlock()
+
+	return report, healthy, ready
+}
+
+func (h *healthService) inputReport(input *models.RunningInput) inputHealthReport {
+	status := input.Status()
+	r := inputHealthReport{
+		Name:                      input.Config.Name,
+		Alias:                     input.Config.Alias,
+		ID:                        input.ID(),
+		LastGatherSuccess:         status.LastGatherSuccess,
+		ConsecutiveGatherFailures: status.ConsecutiveGatherFailures,
+	}
+	if !status.LastGather.IsZero() {
+		r.LastGather = &status.LastGather
+	}
+	r.Healthy = h.maxGatherFailures <= 0 || status.ConsecutiveGatherFailures < h.maxGatherFailures
+	return r
+}
+
+func (h *healthService) outputReport(output *models.RunningOutput) outputHealthReport {
+	status := output.Status()
+	r := outputHealthReport{
+		Name:                     output.Config.Name,
+		Alias:                    output.Config.Alias,
+		ID:                       output.ID(),
+		BufferSize:               status.BufferSize,
+		BufferLimit:              status.BufferLimit,
+		ConsecutiveWriteFailures: status.ConsecutiveWriteFailures,
+		Healthy:                  true,
+	}
+	if !status.LastWrite.IsZero() {
+		r.LastWrite = &status.LastWrite
+	}
+	if status.BufferLimit > 0 {
+		r.BufferFullness = 100 * float64(status.BufferSize) / float64(status.BufferLimit)
+		if h.maxBufferFullness > 0 && r.BufferFullness > float64(h.maxBufferFullness) {
+			r.Healthy = false
+		}
+	}
+	if h.maxWriteFailures > 0 && status.ConsecutiveWriteFailures >= h.maxWriteFailures {
+		r.Healthy = false
+	}
+	return r
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestHealthServiceInvalidSettings(t *testing.T) {
+	_, err := newHealthService(&config.AgentConfig{HealthMaxBufferFullness: 101})
+	require.ErrorContains(t, err, "health_max_buffer_fullness")
+
+	_, err = newHealthService(&config.AgentConfig{HealthMaxWriteFailures: -1})
+	require.ErrorContains(t, err, "health_max_write_failures")
+}
+
+func TestHealthServiceNotRunning(t *testing.T) {
+	h, err := newHealthService(&config.AgentConfig{})
+	require.NoError(t, err)
+	require.NoError(t, h.start("127.0.0.1:0"))
+	defer h.stop()
+
+	addr := "http://" + h.listener.Addr().String()
+	code, report := queryHealth(t, addr+"/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", report.Status)
+
+	code, report = queryHealth(t, addr+"/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not_ready", report.Status)
+}
+
+func TestHealthServiceThresholds(t *testing.T) {
+	h, err := newHealthService(&config.AgentConfig{
+		HealthMaxGatherFailures: 2,
+		HealthMaxWriteFailures:  3,
+		HealthMaxBufferFullness: 50,
+	})
+	require.NoError(t, err)
+	require.NoError(t, h.start("127.0.0.1:0"))
+	defer h.stop()
+
+	input := &failingInput{}
+	ri := models.NewRunningInput(input, &models.InputConfig{Name: "failing"})
+	require.NoError(t, ri.Init())
+	iu := &inputUnit{inputs: []*models.RunningInput{ri}}
+
+	ro := models.NewRunningOutput(&reloadOutput{}, &models.OutputConfig{Name: "mock"}, 1, 4)
+	require.NoError(t, ro.Init())
+	ou := &outputUnit{outputs: []*models.RunningOutput{ro}}
+
+	h.setUnits(iu, ou)
+	addr := "http://" + h.listener.Addr().String()
+
+	code, report := queryHealth(t, addr+"/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, report.Inputs, 1)
+	require.Len(t, report.Outputs, 1)
+	require.Equal(t, 4, report.Outputs[0].BufferLimit)
+
+	code, _ = queryHealth(t, addr+"/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	// Fail the input
+	var acc testutil.Accumulator
+	require.Error(t, ri.Gather(&acc))
+	require.Error(t, ri.Gather(&acc))
+	code, report = queryHealth(t, addr+"/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "failing", report.Status)
+	require.False(t, report.Inputs[0].Healthy)
+	require.Equal(t, 2, report.Inputs[0].ConsecutiveGatherFailures)
+	require.True(t, report.Outputs[0].Healthy)
+
+	// Recover the input and fill the output buffer above the threshold
+	input.healthy = true
+	require.NoError(t, ri.Gather(&acc))
+	for i := range 3 {
+		ro.AddMetric(testutil.TestMetric(i))
+	}
+	code, report = queryHealth(t, addr+"/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.True(t, report.Inputs[0].Healthy)
+	require.True(t, report.Inputs[0].LastGatherSuccess)
+	require.False(t, report.Outputs[0].Healthy)
+	require.InDelta(t, 75.0, report.Outputs[0].BufferFullness, 0.01)
+
+	// Readiness is lost on shutdown
+	iu.stopped = true
+	code, _ = queryHealth(t, addr+"/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func queryHealth(t *testing.T, url string) (int, *healthReport) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var report healthReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	return resp.StatusCode, &report
+}
+
+type failingInput struct {
+	healthy bool
+}
+
+func (*failingInput) SampleConfig() string {
+	return ""
+}
+
+func (i *failingInput) Gather(telegraf.Accumulator) error {
+	if i.healthy {
+		return nil
+	}
+	return errors.New("failed")
+}
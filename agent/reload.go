@@ -0,0 +1,338 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/models"
+)
+
+// ErrRestartRequired is returned by Reload if the configuration changes
+// cannot be applied to the running agent.
+var ErrRestartRequired = errors.New("restart required")
+
+// Reload applies the given configuration to the running agent. Inputs and
+// outputs removed from the configuration are stopped and added ones are
+// started while plugins with an unchanged configuration keep running. An output
+// replaced by an output with the same name and alias takes over the buffered
+// metrics of the previous instance. Changes to any other part of the
+// configuration cannot be applied at runtime and ErrRestartRequired is
+// returned. In case of an error the agent continues to run with the previous
+// configuration.
+func (a *Agent) Reload(ctx context.Context, cfg *config.Config) error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	iu, ou := a.inputs, a.outputs
+	if iu == nil || ou == nil {
+		return fmt.Errorf("%w: agent is not running", ErrRestartRequired)
+	}
+
+	if reason := restartReason(a.Config, cfg); reason != "" {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, reason)
+	}
+
+	iu.Lock()
+	runningInputs := slices.Clone(iu.inputs)
+	iu.Unlock()
+	ou.RLock()
+	runningOutputs := slices.Clone(ou.outputs)
+	ou.RUnlock()
+
+	inputs, addedInputs, removedInputs := diffPlugins(runningInputs, cfg.Inputs)
+	outputs, addedOutputs, removedOutputs := diffPlugins(runningOutputs, cfg.Outputs)
+	if len(addedInputs)+len(removedInputs)+len(addedOutputs)+len(removedOutputs) == 0 {
+		log.Printf("I! [agent] No changes to inputs or outputs found")
+		return nil
+	}
+	log.Printf("I! [agent] Applying configuration changes: %d inputs added, %d inputs removed, %d outputs added, %d outputs removed",
+		len(addedInputs), len(removedInputs), len(addedOutputs), len(removedOutputs))
+
+	deadLetterTargets, err := resolveDeadLetterOutputs(outputs)
+	if err != nil {
+		return err
+	}
+
+	// Initialize and start the new plugins before touching the running ones so
+	// we can bail out without affecting the agent.
+	for _, input := range addedInputs {
+		if a.globalTags != nil {
+			input.SetDefaultTags(a.globalTags)
+		}
+		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
+		}
+		if err := a.attachStateStore(input.ID(), input.Input); err != nil {
+			return fmt.Errorf("could not set state store of input %s: %w", input.LogName(), err)
+		}
+		if err := input.Init(); err != nil {
+			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
+		}
+	}
+	for _, output := range addedOutputs {
+		if err := a.attachStateStore(output.ID(), output.Output); err != nil {
+			return fmt.Errorf("could not set state store of output %s: %w", output.LogName(), err)
+		}
+		if err := output.Init(); err != nil {
+			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
+		}
+	}
+
+	connected := make([]*models.RunningOutput, 0, len(addedOutputs))
+	for _, output := range addedOutputs {
+		if err := a.connectOutput(ctx, output); err != nil {
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				log.Printf("I! [agent] Failed to connect to [%s], error was %q;  shutting down plugin...", output.LogName(), err)
+				output.Close()
+				continue
+			}
+			stopRunningOutputs(connected)
+			return fmt.Errorf("connecting output %s: %w", output.LogName(), err)
+		}
+		connected = append(connected, output)
+	}
+
+	started := make([]*models.RunningInput, 0, len(addedInputs))
+	for _, input := range addedInputs {
+		ok, err := startInput(iu.dst, input)
+		if err != nil {
+			stopRunningInputs(started)
+			stopRunningOutputs(connected)
+			return err
+		}
+		if ok {
+			started = append(started, input)
+		}
+	}
+
+	a.registerStates(started, connected)
+
+	if err := a.swapOutputs(ou, connected, removedOutputs); err != nil {
+		stopRunningInputs(started)
+		stopRunningOutputs(connected)
+		return err
+	}
+	a.Config.Outputs = outputs
+	linkDeadLetterOutputs(outputs, deadLetterTargets)
+
+	if err := a.swapInputs(iu, started, removedInputs); err != nil {
+		stopRunningInputs(started)
+		return err
+	}
+	a.Config.Inputs = inputs
+
+	return nil
+}
+
+// swapOutputs adds the given outputs to the unit and removes the ones no longer
+// configured. Removed outputs write their buffered metrics one last time unless
+// they are replaced by an added output of the same name and alias, in which
+// case the buffer is handed over to the new output.
+func (a *Agent) swapOutputs(unit *outputUnit, added, removed []*models.RunningOutput) error {
+	unit.Lock()
+	if unit.stopped {
+		unit.Unlock()
+		return errors.New("agent is shutting down")
+	}
+
+	replacements := make(map[string]*models.RunningOutput, len(added))
+	for _, output := range added {
+		if _, found := replacements[output.LogName()]; !found {
+			replacements[output.LogName()] = output
+		}
+	}
+
+	// Metrics are not distributed to the outputs while holding the lock, so
+	// no metric is lost during the handover.
+	var final, unflushed []*models.RunningOutput
+	for _, output := range removed {
+		unit.outputs = slices.DeleteFunc(unit.outputs, func(o *models.RunningOutput) bool { return o == output })
+
+		replacement, found := replacements[output.LogName()]
+		if !found {
+			final = append(final, output)
+			continue
+		}
+		delete(replacements, output.LogName())
+
+		stopFlush(unit, output, true)
+		if replacement.AdoptBuffer(output) {
+			log.Printf("I! [agent] Replacing output %s, handing over %d buffered metrics",
+				output.LogName(), replacement.BufferLength())
+		} else {
+			log.Printf("I! [agent] Replacing output %s, buffer settings differ", output.LogName())
+			unflushed = append(unflushed, output)
+		}
+	}
+	for _, output := range added {
+		unit.outputs = append(unit.outputs, output)
+		a.startFlush(unit, output)
+	}
+
+	// Flush loops ending with a final write must not block the other outputs
+	runs := make([]*flushRun, 0, len(final))
+	for _, output := range final {
+		log.Printf("I! [agent] Removing output %s", output.LogName())
+		if run, found := unit.running[output]; found {
+			delete(unit.running, output)
+			runs = append(runs, run)
+		}
+	}
+	unit.Unlock()
+
+	for _, run := range runs {
+		run.cancel()
+		<-run.done
+	}
+	for _, output := range unflushed {
+		if err := output.Write(); err != nil {
+			log.Printf("E! [agent] Error writing to %s: %v", output.LogName(), err)
+		}
+	}
+
+	for _, output := range removed {
+		output.Close()
+	}
+
+	return nil
+}
+
+// swapInputs starts gathering for the given inputs and stops the ones no
+// longer configured.
+func (a *Agent) swapInputs(unit *inputUnit, added, removed []*models.RunningInput) error {
+	unit.Lock()
+	if unit.stopped {
+		unit.Unlock()
+		return errors.New("agent is shutting down")
+	}
+
+	// Ongoing Gather calls of the removed inputs must not block the health and
+	// backpressure checks, so only wait for them after releasing the lock.
+	runs := make([]*gatherRun, 0, len(removed))
+	for _, input := range removed {
+		log.Printf("I! [agent] Removing input %s", input.LogName())
+		if run := detachGather(unit, input); run != nil {
+			runs = append(runs, run)
+		}
+		unit.inputs = slices.DeleteFunc(unit.inputs, func(i *models.RunningInput) bool { return i == input })
+	}
+	for _, input := range added {
+		unit.inputs = append(unit.inputs, input)
+		a.startGather(unit, input)
+	}
+	unit.Unlock()
+
+	for _, run := range runs {
+		<-run.done
+		run.ticker.Stop()
+	}
+	for _, input := range removed {
+		input.Stop()
+	}
+
+	return nil
+}
+
+// registerStates registers the stateful plugins among the given inputs and
+// outputs with the persister. The plugins start without restoring a state.
+func (a *Agent) registerStates(inputs []*models.RunningInput, outputs []*models.RunningOutput) {
+	if a.Config.Persister == nil {
+		return
+	}
+
+	for _, input := range inputs {
+		if plugin, ok := input.Input.(telegraf.StatefulPlugin); ok {
+			if err := a.Config.Persister.Register(input.ID(), plugin); err != nil {
+				log.Printf("W! [agent] Could not register input %s: %v", input.LogName(), err)
+			}
+		}
+	}
+	for _, output := range outputs {
+		if plugin, ok := output.Output.(telegraf.StatefulPlugin); ok {
+			if err := a.Config.Persister.Register(output.ID(), plugin); err != nil {
+				log.Printf("W! [agent] Could not register output %s: %v", output.LogName(), err)
+			}
+		}
+	}
+}
+
+// restartReason returns why the configuration change from current to next
+// cannot be applied without restarting the agent, or an empty string if it can.
+func restartReason(current, next *config.Config) string {
+	if !reflect.DeepEqual(normalizeAgentConfig(current.Agent), normalizeAgentConfig(next.Agent)) {
+		return "agent settings changed"
+	}
+	if !maps.Equal(current.Tags, next.Tags) {
+		return "global tags changed"
+	}
+	if len(current.SecretStores) > 0 || len(next.SecretStores) > 0 {
+		return "secret-stores are configured"
+	}
+	if !samePlugins(current.Processors, next.Processors) {
+		return "processors changed"
+	}
+	if !samePlugins(current.AggProcessors, next.AggProcessors) {
+		return "aggregator processors changed"
+	}
+	if !samePlugins(current.Aggregators, next.Aggregators) {
+		return "aggregators changed"
+	}
+	return ""
+}
+
+// normalizeAgentConfig returns a copy of the agent settings with the defaults
+// applied by the running agent.
+func normalizeAgentConfig(cfg *config.AgentConfig) config.AgentConfig {
+	normalized := *cfg
+	skip := cfg.SkipProcessorsAfterAggregators != nil && *cfg.SkipProcessorsAfterAggregators
+	normalized.SkipProcessorsAfterAggregators = &skip
+	return normalized
+}
+
+// samePlugins checks if both lists contain plugins with the same IDs in the
+// same order.
+func samePlugins[T interface{ ID() string }](current, next []T) bool {
+	return slices.EqualFunc(current, next, func(c, n T) bool { return c.ID() == n.ID() })
+}
+
+// diffPlugins compares the running plugins with the ones in the new
+// configuration using their IDs. It returns the list of plugins to run, where
+// unchanged plugins refer to the already running instance, and the added and
+// removed plugins.
+func diffPlugins[T interface{ ID() string }](current, next []T) (resolved, added, removed []T) {
+	unmatched := make(map[string][]T, len(current))
+	for _, plugin := range current {
+		unmatched[plugin.ID()] = append(unmatched[plugin.ID()], plugin)
+	}
+
+	resolved = make([]T, 0, len(next))
+	for _, plugin := range next {
+		candidates := unmatched[plugin.ID()]
+		if len(candidates) == 0 {
+			resolved = append(resolved, plugin)
+			added = append(added, plugin)
+			continue
+		}
+		resolved = append(resolved, candidates[0])
+		unmatched[plugin.ID()] = candidates[1:]
+	}
+
+	for _, plugin := range current {
+		candidates := unmatched[plugin.ID()]
+		if len(candidates) > 0 && slices.ContainsFunc(candidates, func(c T) bool { return any(c) == any(plugin) }) {
+			removed = append(removed, plugin)
+		}
+	}
+
+	return resolved, added, removed
+}
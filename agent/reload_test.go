@@ -0,0 +1,367 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+func TestDiffPlugins(t *testing.T) {
+	a1, a2 := &reloadPlugin{id: "a"}, &reloadPlugin{id: "a"}
+	b := &reloadPlugin{id: "b"}
+	c := &reloadPlugin{id: "c"}
+
+	na1, na2 := &reloadPlugin{id: "a"}, &reloadPlugin{id: "a"}
+	nc := &reloadPlugin{id: "c"}
+	d := &reloadPlugin{id: "d"}
+
+	resolved, added, removed := diffPlugins(
+		[]*reloadPlugin{a1, b, a2, c},
+		[]*reloadPlugin{d, na1, nc, na2},
+	)
+	require.Equal(t, []*reloadPlugin{d, a1, c, a2}, resolved)
+	require.Equal(t, []*reloadPlugin{d}, added)
+	require.Equal(t, []*reloadPlugin{b}, removed)
+
+	// Removing one of two identical plugins
+	resolved, added, removed = diffPlugins([]*reloadPlugin{a1, a2}, []*reloadPlugin{na1})
+	require.Equal(t, []*reloadPlugin{a1}, resolved)
+	require.Empty(t, added)
+	require.Equal(t, []*reloadPlugin{a2}, removed)
+}
+
+func TestRestartReason(t *testing.T) {
+	skip := false
+	current := config.NewConfig()
+	current.Agent.SkipProcessorsAfterAggregators = &skip
+	current.Processors = models.RunningProcessors{
+		models.NewRunningProcessor(processors.NewStreamingProcessorFromProcessor(&reloadProcessor{}), &models.ProcessorConfig{Name: "mock", ID: "p1"}),
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(*config.Config)
+		expected string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(*config.Config) {},
+		},
+		{
+			name:     "agent settings",
+			modify:   func(c *config.Config) { c.Agent.Interval = config.Duration(time.Minute) },
+			expected: "agent settings changed",
+		},
+		{
+			name:     "global tags",
+			modify:   func(c *config.Config) { c.Tags["dc"] = "eu" },
+			expected: "global tags changed",
+		},
+		{
+			name: "processors",
+			modify: func(c *config.Config) {
+				c.Processors = models.RunningProcessors{
+					models.NewRunningProcessor(processors.NewStreamingProcessorFromProcessor(&reloadProcessor{}), &models.ProcessorConfig{Name: "mock", ID: "p2"}),
+				}
+			},
+			expected: "processors changed",
+		},
+		{
+			name: "aggregators",
+			modify: func(c *config.Config) {
+				c.Aggregators = []*models.RunningAggregator{
+					models.NewRunningAggregator(&reloadAggregator{}, &models.AggregatorConfig{Name: "mock", ID: "a1"}),
+				}
+			},
+			expected: "aggregators changed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := config.NewConfig()
+			next.Processors = models.RunningProcessors{
+				models.NewRunningProcessor(processors.NewStreamingProcessorFromProcessor(&reloadProcessor{}), &models.ProcessorConfig{Name: "mock", ID: "p1"}),
+			}
+			tt.modify(next)
+			require.Equal(t, tt.expected, restartReason(current, next))
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	keptInput := &reloadInput{name: "kept"}
+	removedInput := &reloadInput{name: "removed"}
+	oldOutput := &reloadOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(10 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(time.Hour)
+	cfg.Inputs = []*models.RunningInput{
+		models.NewRunningInput(keptInput, &models.InputConfig{Name: "mock", ID: "kept"}),
+		models.NewRunningInput(removedInput, &models.InputConfig{Name: "mock", ID: "removed"}),
+	}
+	cfg.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput(oldOutput, &models.OutputConfig{Name: "mock", ID: "old"}, 0, 0),
+	}
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	// Reloading is impossible until the agent runs
+	require.Eventually(t, func() bool {
+		a.reloadLock.Lock()
+		defer a.reloadLock.Unlock()
+		return a.outputs != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return cfg.Outputs[0].BufferLength() > 0 && removedInput.gathered.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Replace the output and one of the inputs
+	duplicateInput := &reloadInput{name: "kept"}
+	addedInput := &reloadInput{name: "added"}
+	newOutput := &reloadOutput{}
+
+	next := config.NewConfig()
+	next.Inputs = []*models.RunningInput{
+		models.NewRunningInput(duplicateInput, &models.InputConfig{Name: "mock", ID: "kept"}),
+		models.NewRunningInput(addedInput, &models.InputConfig{Name: "mock", ID: "added"}),
+	}
+	next.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput(newOutput, &models.OutputConfig{Name: "mock", ID: "new"}, 0, 0),
+	}
+	next.Agent.Interval = cfg.Agent.Interval
+	next.Agent.FlushInterval = cfg.Agent.FlushInterval
+	require.NoError(t, a.Reload(ctx, next))
+
+	require.Len(t, a.Config.Inputs, 2)
+	require.Same(t, keptInput, a.Config.Inputs[0].Input)
+	require.Same(t, addedInput, a.Config.Inputs[1].Input)
+	require.Same(t, newOutput, a.Config.Outputs[0].Output)
+	require.True(t, oldOutput.closed.Load())
+	require.Empty(t, oldOutput.Metrics())
+
+	// Stopped inputs are not gathered anymore
+	gathered := removedInput.gathered.Load()
+	require.Eventually(t, func() bool {
+		return addedInput.gathered.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, gathered, removedInput.gathered.Load())
+	require.Zero(t, duplicateInput.gathered.Load())
+
+	// Changes to the processing pipeline require a restart
+	next = config.NewConfig()
+	next.Tags["dc"] = "eu"
+	require.ErrorIs(t, a.Reload(ctx, next), ErrRestartRequired)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// The new output received the metrics buffered by the old one
+	names := make(map[string]bool)
+	for _, m := range newOutput.Metrics() {
+		name, _ := m.GetTag("name")
+		names[name] = true
+	}
+	require.Equal(t, map[string]bool{"kept": true, "removed": true, "added": true}, names)
+
+	// Reloading a stopped agent is not possible
+	require.ErrorIs(t, a.Reload(ctx, next), ErrRestartRequired)
+}
+
+func TestReloadWithoutInputs(t *testing.T) {
+	output := &reloadOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(10 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(10 * time.Millisecond)
+	cfg.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput(output, &models.OutputConfig{Name: "mock", ID: "output"}, 0, 0),
+	}
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		a.reloadLock.Lock()
+		defer a.reloadLock.Unlock()
+		return a.outputs != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The agent keeps running and picks up added inputs
+	addedInput := &reloadInput{name: "added"}
+	next := config.NewConfig()
+	next.Inputs = []*models.RunningInput{
+		models.NewRunningInput(addedInput, &models.InputConfig{Name: "mock", ID: "added"}),
+	}
+	next.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput(output, &models.OutputConfig{Name: "mock", ID: "output"}, 0, 0),
+	}
+	next.Agent.Interval = cfg.Agent.Interval
+	next.Agent.FlushInterval = cfg.Agent.FlushInterval
+	require.NoError(t, a.Reload(ctx, next))
+	require.Eventually(t, func() bool {
+		return len(output.Metrics()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestSwapInputsOngoingGather(t *testing.T) {
+	release := make(chan struct{})
+	blocking := &blockingInput{started: make(chan struct{}), release: release}
+	input := models.NewRunningInput(blocking, &models.InputConfig{Name: "blocking"})
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(10 * time.Millisecond)
+	a := NewAgent(cfg)
+
+	unit := &inputUnit{
+		dst:    make(chan telegraf.Metric, 10),
+		inputs: []*models.RunningInput{input},
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.runInputs(ctx, time.Now(), unit)
+	}()
+	<-blocking.started
+
+	swapped := make(chan error, 1)
+	go func() {
+		swapped <- a.swapInputs(unit, nil, []*models.RunningInput{input})
+	}()
+
+	// The unit is not locked while waiting for the ongoing Gather call
+	require.Eventually(t, func() bool {
+		unit.Lock()
+		defer unit.Unlock()
+		return len(unit.inputs) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, swapped)
+
+	close(release)
+	require.NoError(t, <-swapped)
+
+	cancel()
+	<-done
+}
+
+type reloadPlugin struct {
+	id string
+}
+
+func (p *reloadPlugin) ID() string {
+	return p.id
+}
+
+type reloadInput struct {
+	name     string
+	gathered atomic.Int64
+}
+
+func (*reloadInput) SampleConfig() string {
+	return ""
+}
+
+func (i *reloadInput) Gather(acc telegraf.Accumulator) error {
+	i.gathered.Add(1)
+	acc.AddFields("test", map[string]interface{}{"value": 42}, map[string]string{"name": i.name})
+	return nil
+}
+
+// blockingInput blocks the first Gather call until released
+type blockingInput struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (*blockingInput) SampleConfig() string {
+	return ""
+}
+
+func (i *blockingInput) Gather(telegraf.Accumulator) error {
+	i.once.Do(func() {
+		close(i.started)
+		<-i.release
+	})
+	return nil
+}
+
+type reloadOutput struct {
+	metrics []telegraf.Metric
+	closed  atomic.Bool
+	sync.Mutex
+}
+
+func (*reloadOutput) SampleConfig() string {
+	return ""
+}
+
+func (*reloadOutput) Connect() error {
+	return nil
+}
+
+func (o *reloadOutput) Close() error {
+	o.closed.Store(true)
+	return nil
+}
+
+func (o *reloadOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *reloadOutput) Metrics() []telegraf.Metric {
+	o.Lock()
+	defer o.Unlock()
+	return append([]telegraf.Metric(nil), o.metrics...)
+}
+
+type reloadProcessor struct{}
+
+func (*reloadProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*reloadProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	return in
+}
+
+type reloadAggregator struct{}
+
+func (*reloadAggregator) SampleConfig() string {
+	return ""
+}
+
+func (*reloadAggregator) Add(telegraf.Metric) {}
+
+func (*reloadAggregator) Push(telegraf.Accumulator) {}
+
+func (*reloadAggregator) Reset() {}
@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	defaultSeriesExpiry      = time.Hour
+	defaultSeriesHashBuckets = 16
+
+	// Maximum number of measurements with a dedicated overflow statistic to
+	// limit the number of registered statistics
+	maxSeriesOverflowStats = 100
+)
+
+// seriesUnit tracks the series produced by all inputs and enforces the
+// optional series budget before passing the metrics on.
+//
+//  ______     ┌────────┐     ______
+// ()_____)──▶ │ Series │──▶ ()_____)
+//             └────────┘
+
+type seriesUnit struct {
+	src     <-chan telegraf.Metric
+	dst     chan<- telegraf.Metric
+	tracker *seriesTracker
+}
+
+// seriesTracker counts the active series, i.e. the unique combinations of
+// metric name and tags, seen within the expiry period.
+type seriesTracker struct {
+	budget      int
+	policy      string
+	hashBuckets uint64
+	expiry      time.Duration
+
+	mu         sync.Mutex
+	series     map[uint64]time.Time
+	exceeded   bool
+	globalTags map[string]string
+
+	active   selfstat.Stat
+	dropped  selfstat.Stat
+	stripped selfstat.Stat
+	hashed   selfstat.Stat
+	overflow map[string]selfstat.Stat
+	// Overflow statistic of the measurements beyond the limit
+	overflowOther selfstat.Stat
+}
+
+func newSeriesTracker(cfg *config.AgentConfig, globalTags map[string]string) (*seriesTracker, error) {
+	if !cfg.SeriesTracking && cfg.SeriesBudget <= 0 {
+		return nil, nil
+	}
+
+	policy := cfg.SeriesBudgetPolicy
+	switch policy {
+	case "":
+		policy = "drop"
+	case "drop", "strip_tags", "hash":
+	default:
+		return nil, fmt.Errorf("invalid series budget policy %q", policy)
+	}
+
+	buckets := cfg.SeriesBudgetHashBuckets
+	if buckets < 0 {
+		return nil, fmt.Errorf("invalid number of series hash buckets %d", buckets)
+	}
+	if buckets == 0 {
+		buckets = defaultSeriesHashBuckets
+	}
+
+	expiry := time.Duration(cfg.SeriesExpiry)
+	if expiry <= 0 {
+		expiry = defaultSeriesExpiry
+	}
+
+	return &seriesTracker{
+		budget:      cfg.SeriesBudget,
+		policy:      policy,
+		hashBuckets: uint64(buckets),
+		expiry:      expiry,
+		globalTags:  globalTags,
+		series:      make(map[uint64]time.Time),
+		active:      selfstat.Register("agent", "series_active", make(map[string]string)),
+		dropped:     selfstat.Register("agent", "series_dropped", make(map[string]string)),
+		stripped:    selfstat.Register("agent", "series_stripped", make(map[string]string)),
+		hashed:      selfstat.Register("agent", "series_hashed", make(map[string]string)),
+		overflow:    make(map[string]selfstat.Stat),
+	}, nil
+}
+
+// setGlobalTags updates the global tags kept by the strip_tags and hash
+// policies, e.g. after refreshing the tags from metadata sources.
+func (t *seriesTracker) setGlobalTags(tags map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.globalTags = tags
+}
+
+// apply records the series of the given metric and returns the metric to pass
+// on or nil if the metric was dropped due to the series budget.
+func (t *seriesTracker) apply(m telegraf.Metric, now time.Time) telegraf.Metric {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := m.HashID()
+	if _, found := t.series[id]; found || t.budget <= 0 || len(t.series) < t.budget {
+		t.series[id] = now
+		t.active.Set(int64(len(t.series)))
+		return m
+	}
+
+	if !t.exceeded {
+		log.Printf("W! [agent] Series budget of %d exceeded, applying policy %q to new series", t.budget, t.policy)
+		t.exceeded = true
+	}
+	t.overflowStat(m.Name()).Incr(1)
+
+	switch t.policy {
+	case "strip_tags", "hash":
+		// Keep the global tags only to fold the metric into an existing series
+		// or a new series per measurement, the latter is accepted beyond the
+		// budget to avoid losing the measurement entirely. When hashing, the
+		// removed tags are replaced by a bucket derived from the original
+		// series to retain some distribution while limiting the number of
+		// series per measurement to the number of buckets.
+		keys := make([]string, 0, len(m.TagList()))
+		for _, tag := range m.TagList() {
+			if _, found := t.globalTags[tag.Key]; !found {
+				keys = append(keys, tag.Key)
+			}
+		}
+		for _, key := range keys {
+			m.RemoveTag(key)
+		}
+		if t.policy == "hash" {
+			m.AddTag("series_hash", strconv.FormatUint(id%t.hashBuckets, 10))
+			t.hashed.Incr(1)
+		} else {
+			t.stripped.Incr(1)
+		}
+		t.series[m.HashID()] = now
+		t.active.Set(int64(len(t.series)))
+		return m
+	}
+
+	t.dropped.Incr(1)
+	m.Drop()
+	return nil
+}
+
+// overflowStat returns the statistic counting the metrics of the given
+// measurement exceeding the budget. Once the limit of statistics is reached,
+// the metrics of further measurements are counted in a shared statistic
+// without measurement tag. The lock must be held by the caller.
+func (t *seriesTracker) overflowStat(measurement string) selfstat.Stat {
+	if stat, found := t.overflow[measurement]; found {
+		return stat
+	}
+	if len(t.overflow) >= maxSeriesOverflowStats {
+		if t.overflowOther == nil {
+			t.overflowOther = selfstat.Register("series_overflow", "metrics", map[string]string{"policy": t.policy})
+		}
+		return t.overflowOther
+	}
+	tags := map[string]string{
+		"measurement": measurement,
+		"policy":      t.policy,
+	}
+	stat := selfstat.Register("series_overflow", "metrics", tags)
+	t.overflow[measurement] = stat
+	return stat
+}
+
+// expire removes all series not seen since the expiry period.
+func (t *seriesTracker) expire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, seen := range t.series {
+		if now.Sub(seen) > t.expiry {
+			delete(t.series, id)
+		}
+	}
+	if t.budget > 0 && len(t.series) < t.budget {
+		t.exceeded = false
+	}
+	t.active.Set(int64(len(t.series)))
+}
+
+// setupSeries creates the series tracker if enabled and sets up the series
+// unit in front of the given channel. The returned unit is nil if neither
+// series tracking nor a series budget is configured.
+func (a *Agent) setupSeries(dst chan<- telegraf.Metric, globalTags map[string]string) (chan<- telegraf.Metric, *seriesUnit, error) {
+	tracker, err := newSeriesTracker(a.Config.Agent, globalTags)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tracker == nil {
+		return dst, nil, nil
+	}
+
+	// Pick up the global tags refreshed in the meantime
+	a.reloadLock.Lock()
+	a.series = tracker
+	if a.globalTags != nil {
+		tracker.setGlobalTags(a.globalTags)
+	}
+	a.reloadLock.Unlock()
+
+	src, unit := a.startSeries(dst, tracker)
+	return src, unit, nil
+}
+
+// startSeries sets up the series unit and returns its source channel.
+func (*Agent) startSeries(dst chan<- telegraf.Metric, tracker *seriesTracker) (chan<- telegraf.Metric, *seriesUnit) {
+	src := make(chan telegraf.Metric, 100)
+	unit := &seriesUnit{
+		src:     src,
+		dst:     dst,
+		tracker: tracker,
+	}
+	return src, unit
+}
+
+// runSeries tracks the series of the metrics and runs until the source
+// channel is closed and all metrics have been passed on.
+func (*Agent) runSeries(unit *seriesUnit) {
+	ticker := time.NewTicker(unit.tracker.expiry / 10)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case m, ok := <-unit.src:
+			if !ok {
+				close(unit.dst)
+				log.Printf("D! [agent] Series channel closed")
+				return
+			}
+			if m := unit.tracker.apply(m, time.Now()); m != nil {
+				unit.dst <- m
+			}
+		case now := <-ticker.C:
+			unit.tracker.expire(now)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSeriesTrackerDisabled(t *testing.T) {
+	tracker, err := newSeriesTracker(&config.AgentConfig{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, tracker)
+}
+
+func TestSeriesTrackerInvalidPolicy(t *testing.T) {
+	cfg := &config.AgentConfig{
+		SeriesBudget:       10,
+		SeriesBudgetPolicy: "foo",
+	}
+	_, err := newSeriesTracker(cfg, nil)
+	require.ErrorContains(t, err, `invalid series budget policy "foo"`)
+}
+
+func TestSeriesTrackerInvalidHashBuckets(t *testing.T) {
+	cfg := &config.AgentConfig{
+		SeriesBudget:            10,
+		SeriesBudgetPolicy:      "hash",
+		SeriesBudgetHashBuckets: -1,
+	}
+	_, err := newSeriesTracker(cfg, nil)
+	require.ErrorContains(t, err, "invalid number of series hash buckets -1")
+}
+
+func TestSeriesTrackerBudget(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "2"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "drop",
+			policy: "drop",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "strip tags",
+			policy: "strip_tags",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "hash",
+			policy: "hash",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "series_hash": strconv.FormatUint(input[3].HashID()%4, 10)},
+					map[string]interface{}{"value": 4},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AgentConfig{
+				SeriesBudget:            2,
+				SeriesBudgetPolicy:      tt.policy,
+				SeriesBudgetHashBuckets: 4,
+			}
+			tracker, err := newSeriesTracker(cfg, map[string]string{"host": "a"})
+			require.NoError(t, err)
+			overflow := selfstat.Register("series_overflow", "metrics", map[string]string{"measurement": "cpu", "policy": tt.policy})
+			before := overflow.Get()
+
+			var actual []telegraf.Metric
+			for _, m := range input {
+				if m := tracker.apply(m.Copy(), time.Now()); m != nil {
+					actual = append(actual, m)
+				}
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+
+			// The metric exceeding the budget is reported per measurement
+			require.Equal(t, before+1, overflow.Get())
+		})
+	}
+}
+
+func TestSeriesTrackerRefreshedGlobalTags(t *testing.T) {
+	cfg := &config.AgentConfig{
+		SeriesBudget:       1,
+		SeriesBudgetPolicy: "strip_tags",
+	}
+	tracker, err := newSeriesTracker(cfg, map[string]string{"host": "a"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NotNil(t, tracker.apply(metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 1}, now), now))
+
+	// Tags added by a refresh of the global tags are kept
+	a := NewAgent(config.NewConfig())
+	a.series = tracker
+	a.setGlobalTags(map[string]string{"host": "a", "region": "eu"})
+
+	m := tracker.apply(metric.New("cpu", map[string]string{"cpu": "1", "host": "a", "region": "eu"}, map[string]interface{}{"value": 1}, now), now)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]string{"host": "a", "region": "eu"}, m.Tags())
+}
+
+func TestSeriesTrackerOverflowStatsLimit(t *testing.T) {
+	cfg := &config.AgentConfig{SeriesBudget: 1}
+	tracker, err := newSeriesTracker(cfg, nil)
+	require.NoError(t, err)
+	other := selfstat.Register("series_overflow", "metrics", map[string]string{"policy": "drop"})
+	before := other.Get()
+
+	now := time.Now()
+	require.NotNil(t, tracker.apply(metric.New("first", map[string]string{}, map[string]interface{}{"value": 1}, now), now))
+	for i := range maxSeriesOverflowStats + 10 {
+		name := "overflow" + strconv.Itoa(i)
+		require.Nil(t, tracker.apply(metric.New(name, map[string]string{}, map[string]interface{}{"value": 1}, now), now))
+	}
+
+	// Measurements beyond the limit share a single statistic
+	require.Len(t, tracker.overflow, maxSeriesOverflowStats)
+	require.Equal(t, before+10, other.Get())
+}
+
+func TestSeriesTrackerExpiry(t *testing.T) {
+	cfg := &config.AgentConfig{
+		SeriesBudget: 1,
+		SeriesExpiry: config.Duration(time.Minute),
+	}
+	tracker, err := newSeriesTracker(cfg, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	first := metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 1}, now)
+	second := metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"value": 1}, now)
+
+	require.NotNil(t, tracker.apply(first, now))
+	require.Nil(t, tracker.apply(second, now))
+	require.Equal(t, int64(1), tracker.active.Get())
+
+	// The first series expired so the budget allows the second one
+	tracker.expire(now.Add(2 * time.Minute))
+	require.Equal(t, int64(0), tracker.active.Get())
+	require.NotNil(t, tracker.apply(second, now.Add(2*time.Minute)))
+	require.Equal(t, int64(1), tracker.active.Get())
+}
+
+func TestSeriesUnit(t *testing.T) {
+	cfg := &config.AgentConfig{SeriesTracking: true}
+	tracker, err := newSeriesTracker(cfg, nil)
+	require.NoError(t, err)
+
+	a := NewAgent(config.NewConfig())
+	dst := make(chan telegraf.Metric, 10)
+	src, unit := a.startSeries(dst, tracker)
+
+	done := make(chan struct{})
+	go func() {
+		a.runSeries(unit)
+		close(done)
+	}()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": 1}, time.Unix(0, 0)),
+	}
+	for _, m := range input {
+		src <- m
+	}
+	close(src)
+	<-done
+
+	actual := make([]telegraf.Metric, 0, len(input))
+	for m := range dst {
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, input, actual)
+	require.Equal(t, int64(3), tracker.active.Get())
+}
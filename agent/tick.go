@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/robfig/cron/v3"
 
 	"github.com/influxdata/telegraf/internal"
 )
@@ -279,3 +280,98 @@ func (t *RollingTicker) Stop() {
 	t.cancel()
 	t.wg.Wait()
 }
+
+// CronTicker delivers ticks at the times of a cron schedule plus an optional
+// offset and jitter.
+//
+// The first tick is emitted at the next scheduled time.
+//
+// If the ticker fires after the window of the following scheduled time
+// passed, e.g. because the host was suspended, the missed runs are either
+// dropped or caught up by a single immediate tick depending on the catch-up
+// setting.
+//
+// Ticks are dropped for slow consumers.
+type CronTicker struct {
+	schedule cron.Schedule
+	jitter   time.Duration
+	offset   time.Duration
+	catchUp  bool
+	ch       chan time.Time
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewCronTicker(now time.Time, schedule cron.Schedule, jitter, offset time.Duration, catchUp bool) *CronTicker {
+	t := &CronTicker{
+		schedule: schedule,
+		jitter:   jitter,
+		offset:   offset,
+		catchUp:  catchUp,
+	}
+	t.start(now, clock.New())
+	return t
+}
+
+func (t *CronTicker) start(now time.Time, clk clock.Clock) {
+	t.ch = make(chan time.Time, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	scheduled := t.schedule.Next(now)
+	timer := clk.Timer(t.delay(now, scheduled))
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(ctx, timer, clk, scheduled)
+	}()
+}
+
+func (t *CronTicker) delay(now, scheduled time.Time) time.Duration {
+	return scheduled.Sub(now) + t.offset + internal.RandomDuration(t.jitter)
+}
+
+func (t *CronTicker) run(ctx context.Context, timer *clock.Timer, clk clock.Clock, scheduled time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			// Use the current wall-clock time as the timer might fire late
+			// e.g. after the host resumed from suspend
+			now := clk.Now()
+			tick, next := t.advance(now, scheduled)
+			if tick {
+				select {
+				case t.ch <- now:
+				default:
+				}
+			}
+			scheduled = next
+			timer.Reset(t.delay(now, scheduled))
+		}
+	}
+}
+
+// advance determines if a tick should be emitted for the scheduled time at
+// the given time and returns the next scheduled time.
+func (t *CronTicker) advance(now, scheduled time.Time) (bool, time.Time) {
+	// Runs were missed if the window of the following run passed
+	next := t.schedule.Next(scheduled)
+	if now.Before(next.Add(t.offset + t.jitter)) {
+		return true, next
+	}
+	return t.catchUp, t.schedule.Next(now.Add(-t.offset))
+}
+
+func (t *CronTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (t *CronTicker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
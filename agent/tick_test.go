@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -228,6 +229,105 @@ func TestRollingTicker(t *testing.T) {
 
 // Simulates running the Ticker for an hour and displays stats about the
 // operation.
+func TestCronTicker(t *testing.T) {
+	schedule, err := cron.ParseStandard("CRON_TZ=UTC */15 * * * *")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	since := clk.Now()
+
+	ticker := &CronTicker{schedule: schedule}
+	ticker.start(since, clk)
+	defer ticker.Stop()
+
+	expected := []time.Time{
+		time.Unix(15*60, 0).UTC(),
+		time.Unix(30*60, 0).UTC(),
+		time.Unix(45*60, 0).UTC(),
+		time.Unix(60*60, 0).UTC(),
+	}
+
+	actual := make([]time.Time, 0, len(expected))
+	for range expected {
+		clk.Add(15 * time.Minute)
+		tm := <-ticker.Elapsed()
+		actual = append(actual, tm.UTC())
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+func TestCronTickerOffset(t *testing.T) {
+	schedule, err := cron.ParseStandard("CRON_TZ=UTC 0 * * * *")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	since := clk.Now()
+
+	ticker := &CronTicker{schedule: schedule, offset: 30 * time.Second}
+	ticker.start(since, clk)
+	defer ticker.Stop()
+
+	clk.Add(time.Hour)
+	select {
+	case tm := <-ticker.Elapsed():
+		require.Failf(t, "unexpected tick", "tick at %v before offset", tm)
+	default:
+	}
+
+	clk.Add(30 * time.Second)
+	tm := <-ticker.Elapsed()
+	require.Equal(t, time.Unix(3630, 0).UTC(), tm.UTC())
+}
+
+func TestCronTickerMissedRuns(t *testing.T) {
+	schedule, err := cron.ParseStandard("CRON_TZ=UTC 0 * * * *")
+	require.NoError(t, err)
+
+	scheduled := time.Unix(3600, 0).UTC()
+	tests := []struct {
+		name     string
+		catchUp  bool
+		now      time.Time
+		tick     bool
+		expected time.Time
+	}{
+		{
+			name:     "on time",
+			now:      scheduled,
+			tick:     true,
+			expected: time.Unix(2*3600, 0).UTC(),
+		},
+		{
+			name:     "late within window",
+			now:      scheduled.Add(59 * time.Minute),
+			tick:     true,
+			expected: time.Unix(2*3600, 0).UTC(),
+		},
+		{
+			name:     "missed with skip",
+			now:      scheduled.Add(2*time.Hour + 30*time.Minute),
+			expected: time.Unix(4*3600, 0).UTC(),
+		},
+		{
+			name:     "missed with catch-up",
+			catchUp:  true,
+			now:      scheduled.Add(2*time.Hour + 30*time.Minute),
+			tick:     true,
+			expected: time.Unix(4*3600, 0).UTC(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticker := &CronTicker{schedule: schedule, catchUp: tt.catchUp}
+			tick, next := ticker.advance(tt.now, scheduled)
+			require.Equal(t, tt.tick, tick)
+			require.Equal(t, tt.expected, next.UTC())
+		})
+	}
+}
+
 func TestAlignedTickerDistribution(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	defaultTracingSampleRatio = 0.01
+	tracingShutdownTimeout    = 5 * time.Second
+)
+
+// newTracerProvider creates a provider exporting the sampled spans of the
+// metric pipeline via OTLP. A nil provider is returned if tracing is disabled.
+func newTracerProvider(cfg *config.AgentConfig) (*sdktrace.TracerProvider, error) {
+	if cfg.TracingEndpoint == "" {
+		return nil, nil
+	}
+
+	ratio := cfg.TracingSampleRatio
+	if ratio == 0 {
+		ratio = defaultTracingSampleRatio
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid tracing sample ratio %v, must be between 0 and 1", ratio)
+	}
+
+	exporter, err := newTraceExporter(cfg.TracingEndpoint, cfg.TracingInsecure)
+	if err != nil {
+		return nil, fmt.Errorf("creating trace exporter failed: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName("telegraf"), semconv.ServiceVersion(internal.Version)}
+	if cfg.Hostname != "" {
+		attrs = append(attrs, semconv.HostName(cfg.Hostname))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+	), nil
+}
+
+// startTracing installs the given provider as global tracer provider used by
+// the pipeline stages. The returned function flushes the pending spans and
+// disables tracing again.
+func startTracing(provider *sdktrace.TracerProvider) func() {
+	otel.SetTracerProvider(provider)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("W! [agent] Shutting down tracing failed: %v", err)
+		}
+	}
+}
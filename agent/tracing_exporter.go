@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// traceExporter sends the finished spans to an OTLP endpoint via gRPC using
+// the same collector client as the OpenTelemetry output.
+type traceExporter struct {
+	conn   *grpc.ClientConn
+	client ptraceotlp.GRPCClient
+}
+
+func newTraceExporter(endpoint string, insecureConn bool) (*traceExporter, error) {
+	creds := credentials.NewTLS(nil)
+	if insecureConn {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds), grpc.WithUserAgent(internal.ProductToken()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &traceExporter{
+		conn:   conn,
+		client: ptraceotlp.NewGRPCClient(conn),
+	}, nil
+}
+
+func (e *traceExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	request := ptraceotlp.NewExportRequestFromTraces(convertSpans(spans))
+	if _, err := e.client.Export(ctx, request); err != nil {
+		return fmt.Errorf("exporting %d spans failed: %w", len(spans), err)
+	}
+	return nil
+}
+
+func (e *traceExporter) Shutdown(context.Context) error {
+	return e.conn.Close()
+}
+
+// convertSpans converts the spans to the OTLP representation. All spans are
+// created by the same provider so they share the resource and scope.
+func convertSpans(spans []sdktrace.ReadOnlySpan) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	if res := spans[0].Resource(); res != nil {
+		rs.SetSchemaUrl(res.SchemaURL())
+		setAttributes(rs.Resource().Attributes(), res.Attributes())
+	}
+
+	ss := rs.ScopeSpans().AppendEmpty()
+	scope := spans[0].InstrumentationScope()
+	ss.Scope().SetName(scope.Name)
+	ss.Scope().SetVersion(scope.Version)
+
+	for _, s := range spans {
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(pcommon.TraceID(s.SpanContext().TraceID()))
+		span.SetSpanID(pcommon.SpanID(s.SpanContext().SpanID()))
+		if s.Parent().IsValid() {
+			span.SetParentSpanID(pcommon.SpanID(s.Parent().SpanID()))
+		}
+		span.SetName(s.Name())
+		span.SetKind(ptrace.SpanKindInternal)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(s.StartTime()))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(s.EndTime()))
+		setAttributes(span.Attributes(), s.Attributes())
+
+		for _, l := range s.Links() {
+			link := span.Links().AppendEmpty()
+			link.SetTraceID(pcommon.TraceID(l.SpanContext.TraceID()))
+			link.SetSpanID(pcommon.SpanID(l.SpanContext.SpanID()))
+			setAttributes(link.Attributes(), l.Attributes)
+		}
+
+		for _, e := range s.Events() {
+			event := span.Events().AppendEmpty()
+			event.SetName(e.Name)
+			event.SetTimestamp(pcommon.NewTimestampFromTime(e.Time))
+			setAttributes(event.Attributes(), e.Attributes)
+		}
+
+		switch s.Status().Code {
+		case codes.Ok:
+			span.Status().SetCode(ptrace.StatusCodeOk)
+		case codes.Error:
+			span.Status().SetCode(ptrace.StatusCodeError)
+			span.Status().SetMessage(s.Status().Description)
+		}
+	}
+
+	return traces
+}
+
+func setAttributes(dst pcommon.Map, attrs []attribute.KeyValue) {
+	dst.EnsureCapacity(len(attrs))
+	for _, kv := range attrs {
+		key := string(kv.Key)
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			dst.PutBool(key, kv.Value.AsBool())
+		case attribute.INT64:
+			dst.PutInt(key, kv.Value.AsInt64())
+		case attribute.FLOAT64:
+			dst.PutDouble(key, kv.Value.AsFloat64())
+		default:
+			dst.PutStr(key, kv.Value.Emit())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestTracerProviderDisabled(t *testing.T) {
+	provider, err := newTracerProvider(&config.AgentConfig{})
+	require.NoError(t, err)
+	require.Nil(t, provider)
+}
+
+func TestTracerProviderInvalidRatio(t *testing.T) {
+	cfg := &config.AgentConfig{
+		TracingEndpoint:    "localhost:4317",
+		TracingSampleRatio: 1.5,
+	}
+	_, err := newTracerProvider(cfg)
+	require.ErrorContains(t, err, "invalid tracing sample ratio 1.5")
+}
+
+func TestTracerProvider(t *testing.T) {
+	cfg := &config.AgentConfig{
+		TracingEndpoint: "localhost:4317",
+		TracingInsecure: true,
+	}
+	provider, err := newTracerProvider(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, provider)
+
+	stop := startTracing(provider)
+	stop()
+}
+
+func TestTraceExporter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	service := &mockTraceService{}
+	server := grpc.NewServer()
+	ptraceotlp.RegisterGRPCServer(server, service)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer server.Stop()
+
+	exporter, err := newTraceExporter(listener.Addr().String(), true)
+	require.NoError(t, err)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	tracer := provider.Tracer("telegraf")
+	ctx, parent := tracer.Start(t.Context(), "gather")
+	parent.SetAttributes(attribute.Int("metrics", 2))
+	_, child := tracer.Start(ctx, "write")
+	child.SetStatus(codes.Error, "failed write")
+	child.End()
+	parent.End()
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	require.Len(t, service.spans, 2)
+	write, gather := service.spans[0], service.spans[1]
+	require.Equal(t, "write", write.Name())
+	require.Equal(t, ptrace.StatusCodeError, write.Status().Code())
+	require.Equal(t, "failed write", write.Status().Message())
+	require.Equal(t, gather.SpanID(), write.ParentSpanID())
+	require.Equal(t, gather.TraceID(), write.TraceID())
+
+	require.Equal(t, "gather", gather.Name())
+	require.True(t, gather.ParentSpanID().IsEmpty())
+	v, found := gather.Attributes().Get("metrics")
+	require.True(t, found)
+	require.Equal(t, int64(2), v.Int())
+}
+
+type mockTraceService struct {
+	ptraceotlp.UnimplementedGRPCServer
+	spans []ptrace.Span
+}
+
+func (s *mockTraceService) Export(_ context.Context, request ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	rs := request.Traces().ResourceSpans()
+	for i := range rs.Len() {
+		ss := rs.At(i).ScopeSpans()
+		for j := range ss.Len() {
+			spans := ss.At(j).Spans()
+			for k := range spans.Len() {
+				s.spans = append(s.spans, spans.At(k))
+			}
+		}
+	}
+	return ptraceotlp.NewExportResponse(), nil
+}
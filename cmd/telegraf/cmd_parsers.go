@@ -0,0 +1,138 @@
+// Command handling for the "parsers" command
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/plugins/parsers/graphite"
+)
+
+func getParserCommands(outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "parsers",
+			Usage: "commands for testing parser settings",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "graphite",
+					Usage: "commands for the graphite parser",
+					Subcommands: []*cli.Command{
+						{
+							Name:  "test",
+							Usage: "show how metric names are mapped by the given templates",
+							Description: `
+		The 'test' command reads the templates from the files specified via
+		'--templates', with one template per line like for the 'templates_files'
+		setting of the parser, and applies them to the metrics read from the
+		'--input' file or stdin. For each metric the applied template and the
+		resulting measurement, tags and field are printed.
+		The metrics can be given as plaintext protocol lines or as metric names
+		only.
+
+		To test the templates in 'templates.conf' use
+
+		> telegraf parsers graphite test --templates templates.conf --input metrics.txt
+		`,
+							Flags: []cli.Flag{
+								&cli.StringSliceFlag{
+									Name:     "templates",
+									Usage:    "file containing the templates, supports globs",
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:  "input",
+									Usage: "file containing the metrics, one per line, defaults to stdin",
+								},
+								&cli.StringFlag{
+									Name:  "separator",
+									Usage: "separator used to join the measurement and field parts",
+									Value: graphite.DefaultSeparator,
+								},
+							},
+							Action: func(cCtx *cli.Context) error {
+								parser := &graphite.Parser{
+									Separator:      cCtx.String("separator"),
+									TemplatesFiles: cCtx.StringSlice("templates"),
+								}
+								if err := parser.Init(); err != nil {
+									return err
+								}
+
+								input := io.Reader(os.Stdin)
+								if fn := cCtx.String("input"); fn != "" && fn != "-" {
+									f, err := os.Open(fn)
+									if err != nil {
+										return fmt.Errorf("opening input failed: %w", err)
+									}
+									defer f.Close()
+									input = f
+								}
+
+								return testGraphiteTemplates(outputBuffer, parser, input)
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// testGraphiteTemplates prints the result of parsing each of the input lines
+func testGraphiteTemplates(w io.Writer, parser *graphite.Parser, input io.Reader) error {
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		name := fields[0]
+		// Allow to test metric names without value
+		if len(fields) == 1 {
+			line += " 0"
+		}
+
+		fmt.Fprintln(w, name)
+		template := parser.MatchedTemplate(line)
+		if template == "" {
+			template = "measurement* (default)"
+		}
+		fmt.Fprintf(w, "  template:    %s\n", template)
+
+		m, err := parser.ParseLine(line)
+		if err != nil {
+			fmt.Fprintf(w, "  error:       %v\n", err)
+			continue
+		}
+		if m == nil {
+			fmt.Fprintln(w, "  dropped")
+			continue
+		}
+
+		tags := make([]string, 0, len(m.TagList()))
+		for _, tag := range m.TagList() {
+			tags = append(tags, tag.Key+"="+tag.Value)
+		}
+		sort.Strings(tags)
+		if len(tags) == 0 {
+			tags = append(tags, "(none)")
+		}
+		fmt.Fprintf(w, "  measurement: %s\n", m.Name())
+		fmt.Fprintf(w, "  tags:        %s\n", strings.Join(tags, ","))
+		for _, field := range m.FieldList() {
+			fmt.Fprintf(w, "  field:       %s\n", field.Key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading input failed: %w", err)
+	}
+	return nil
+}
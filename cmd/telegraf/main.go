@@ -231,6 +231,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			configURLWatchInterval:  cCtx.Duration("config-url-watch-interval"),
 			watchConfig:             cCtx.String("watch-config"),
 			watchInterval:           cCtx.Duration("watch-interval"),
+			partialReload:           cCtx.Bool("partial-reload"),
 			pidFile:                 cCtx.String("pidfile"),
 			plugindDir:              cCtx.String("plugin-directory"),
 			password:                cCtx.String("password"),
@@ -261,6 +262,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		getSecretStoreCommands(m)...,
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
+	commands = append(commands, getParserCommands(outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
 
 	app := &cli.App{
@@ -309,6 +311,12 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Name:  "old-env-behavior",
 					Usage: "switch back to pre v1.27 environment replacement behavior",
 				},
+				&cli.BoolFlag{
+					Name: "partial-reload",
+					Usage: "on config changes only restart added, removed or modified inputs and outputs " +
+						"and keep the remaining plugins and buffered metrics; requires a full restart " +
+						"for other changes",
+				},
 				&cli.BoolFlag{
 					Name:  "print-plugin-config-source",
 					Usage: "print the source for a given plugin",
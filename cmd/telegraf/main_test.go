@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -472,6 +473,49 @@ func TestCommandVersion(t *testing.T) {
 	}
 }
 
+func TestCommandParsersGraphiteTest(t *testing.T) {
+	dir := t.TempDir()
+	templates := filepath.Join(dir, "templates.conf")
+	require.NoError(t, os.WriteFile(templates, []byte(`
+servers.* .host.measurement.field
+servers.db.* .role.host.measurement.field @priority=10
+`), 0640))
+	input := filepath.Join(dir, "metrics.txt")
+	require.NoError(t, os.WriteFile(input, []byte(`
+servers.web01.cpu.load
+servers.db.db01.cpu.load;dc=eu 42 1700000000
+cpu.load
+servers.web01.cpu.load foo
+`), 0640))
+
+	expected := `servers.web01.cpu.load
+  template:    servers.* .host.measurement.field
+  measurement: cpu
+  tags:        host=web01
+  field:       load
+servers.db.db01.cpu.load;dc=eu
+  template:    servers.db.* .role.host.measurement.field @priority=10
+  measurement: cpu
+  tags:        dc=eu,host=db01,role=db
+  field:       load
+cpu.load
+  template:    measurement* (default)
+  measurement: cpu.load
+  tags:        (none)
+  field:       value
+servers.web01.cpu.load
+  template:    servers.* .host.measurement.field
+  error:       field "servers.web01.cpu.load" value: strconv.ParseFloat: parsing "foo": invalid syntax
+`
+
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	args = append(args, "parsers", "graphite", "test", "--templates", templates, "--input", input)
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+	require.NoError(t, err)
+	require.Equal(t, expected, buf.String())
+}
+
 // Users should use the version subcommand
 func TestFlagVersion(t *testing.T) {
 	tests := []struct {
@@ -552,6 +596,7 @@ func TestFlagsAreSet(t *testing.T) {
 		"--once",
 		"--test-wait", strconv.Itoa(expectedInt),
 		"--watch-config", expectedString,
+		"--partial-reload",
 		"--pidfile", expectedString,
 	}
 
@@ -570,5 +615,6 @@ func TestFlagsAreSet(t *testing.T) {
 	require.True(t, m.quiet)
 	require.Equal(t, expectedInt, m.testWait)
 	require.Equal(t, expectedString, m.watchConfig)
+	require.True(t, m.partialReload)
 	require.Equal(t, expectedString, m.pidFile)
 }
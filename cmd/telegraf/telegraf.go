@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -41,6 +42,7 @@ type GlobalFlags struct {
 	configURLWatchInterval  time.Duration
 	watchConfig             string
 	watchInterval           time.Duration
+	partialReload           bool
 	pidFile                 string
 	plugindDir              string
 	password                string
@@ -81,6 +83,10 @@ type Telegraf struct {
 
 	cfg *config.Config
 
+	// Running agent used to apply configuration changes
+	agent     *agent.Agent
+	agentLock sync.Mutex
+
 	GlobalFlags
 	WindowFlags
 }
@@ -149,57 +155,40 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
-		if t.watchConfig != "" {
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					continue
-				}
-
-				if _, err := os.Stat(fConfig); err != nil {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfig)
-				}
-			}
-			for _, fConfigDirectory := range t.configDir {
-				if _, err := os.Stat(fConfigDirectory); err != nil {
-					log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfigDirectory)
-				}
-			}
-		}
-		if t.configURLWatchInterval > 0 {
-			remoteConfigs := make([]string, 0)
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					remoteConfigs = append(remoteConfigs, fConfig)
-				}
-			}
-			if len(remoteConfigs) > 0 {
-				go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
-			}
-		}
+		watchCtx, stopWatchers := context.WithCancel(ctx)
+		t.startConfigWatchers(watchCtx, signals)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					// May need to update the list of known config files
-					// if a delete or create occured. That way on the reload
-					// we ensure we watch the correct files.
-					if err := t.getConfigFiles(); err != nil {
-						log.Println("E! Error loading config files: ", err)
+			defer func() { stopWatchers() }()
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						// May need to update the list of known config files
+						// if a delete or create occured. That way on the reload
+						// we ensure we watch the correct files.
+						if err := t.getConfigFiles(); err != nil {
+							log.Println("E! Error loading config files: ", err)
+						}
+						if t.partialReload && t.applyConfigChanges(ctx) {
+							// The watchers stop after reporting a change so
+							// restart them for the updated list of files.
+							stopWatchers()
+							watchCtx, stopWatchers = context.WithCancel(ctx)
+							t.startConfigWatchers(watchCtx, signals)
+							continue
+						}
+						<-reload
+						reload <- true
 					}
-					<-reload
-					reload <- true
+					cancel()
+				case err := <-t.pprofErr:
+					log.Printf("E! pprof server failed: %v", err)
+					cancel()
+				case <-stop:
+					cancel()
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
@@ -213,6 +202,77 @@ func (t *Telegraf) reloadLoop() error {
 	return nil
 }
 
+// startConfigWatchers starts watching the local and remote configuration
+// files for changes. Changes are reported by sending SIGHUP to the given
+// signal channel.
+func (t *Telegraf) startConfigWatchers(ctx context.Context, signals chan os.Signal) {
+	if t.watchConfig != "" {
+		for _, fConfig := range t.configFiles {
+			if isURL(fConfig) {
+				continue
+			}
+
+			if _, err := os.Stat(fConfig); err != nil {
+				log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfig)
+			}
+		}
+		for _, fConfigDirectory := range t.configDir {
+			if _, err := os.Stat(fConfigDirectory); err != nil {
+				log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfigDirectory)
+			}
+		}
+	}
+	if t.configURLWatchInterval > 0 {
+		remoteConfigs := make([]string, 0)
+		for _, fConfig := range t.configFiles {
+			if isURL(fConfig) {
+				remoteConfigs = append(remoteConfigs, fConfig)
+			}
+		}
+		if len(remoteConfigs) > 0 {
+			go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
+		}
+	}
+}
+
+// applyConfigChanges loads the configuration and applies the changes to the
+// running agent without restarting it. It returns false if the agent has to
+// be restarted instead.
+func (t *Telegraf) applyConfigChanges(ctx context.Context) bool {
+	t.agentLock.Lock()
+	ag := t.agent
+	t.agentLock.Unlock()
+	if ag == nil {
+		return false
+	}
+
+	c, err := t.loadConfiguration()
+	if err != nil {
+		log.Printf("E! Loading config failed, restarting agent: %v", err)
+		return false
+	}
+
+	// Leave reporting invalid configurations to the restart
+	if len(c.Outputs) == 0 || (t.plugindDir == "" && len(c.Inputs) == 0) {
+		return false
+	}
+
+	if err := ag.Reload(ctx, c); err != nil {
+		if errors.Is(err, agent.ErrRestartRequired) {
+			log.Printf("I! Config changes cannot be applied partially, restarting agent: %v", err)
+		} else {
+			log.Printf("E! Applying config changes failed, restarting agent: %v", err)
+		}
+		return false
+	}
+	log.Println("I! Config changes applied")
+	return true
+}
+
 func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal, fConfig string) {
 	var mytomb tomb.Tomb
 	var watcher watch.FileWatcher
@@ -483,6 +543,15 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 
+	t.agentLock.Lock()
+	t.agent = ag
+	t.agentLock.Unlock()
+	defer func() {
+		t.agentLock.Lock()
+		t.agent = nil
+		t.agentLock.Unlock()
+	}()
+
 	return ag.Run(ctx)
 }
 
@@ -35,12 +35,13 @@ func (t *Telegraf) Run() error {
 		switch t.service {
 		case "install":
 			cfg := &serviceConfig{
-				displayName:  t.serviceDisplayName,
-				restartDelay: t.serviceRestartDelay,
-				autoRestart:  t.serviceAutoRestart,
-				configs:      t.config,
-				configDirs:   t.configDir,
-				watchConfig:  t.watchConfig,
+				displayName:   t.serviceDisplayName,
+				restartDelay:  t.serviceRestartDelay,
+				autoRestart:   t.serviceAutoRestart,
+				configs:       t.config,
+				configDirs:    t.configDir,
+				watchConfig:   t.watchConfig,
+				partialReload: t.partialReload,
 			}
 			if err := installService(t.serviceName, cfg); err != nil {
 				return err
@@ -169,9 +170,10 @@ type serviceConfig struct {
 	autoRestart  bool
 
 	// Telegraf parameters
-	configs     []string
-	configDirs  []string
-	watchConfig string
+	configs       []string
+	configDirs    []string
+	watchConfig   string
+	partialReload bool
 }
 
 func installService(name string, cfg *serviceConfig) error {
@@ -201,6 +203,9 @@ func installService(name string, cfg *serviceConfig) error {
 	if cfg.watchConfig != "" {
 		args = append(args, "--watch-config", cfg.watchConfig)
 	}
+	if cfg.partialReload {
+		args = append(args, "--partial-reload")
+	}
 	// Pass the service name to the command line, to have a custom name when relaunching as a service
 	args = append(args, "--service-name", name)
 
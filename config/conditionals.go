@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/compose-spec/compose-go/template"
+)
+
+// processConditionals evaluates the conditional blocks in the configuration
+// and removes the content of blocks whose condition does not hold. Blocks
+// are delimited by comment directives
+//
+//	# @if <condition>
+//	# @else
+//	# @endif
+//
+// and can be nested. A condition is either a single operand, which holds
+// unless it is empty, "false" or "0", or a comparison of two operands using
+// "==" or "!=". Environment variables in conditions are expanded. The
+// directives and removed lines are replaced by empty lines to keep the line
+// numbers of the remaining content intact.
+func processConditionals(contents []byte) ([]byte, error) {
+	type block struct {
+		active   bool
+		parent   bool
+		seenElse bool
+		line     int
+	}
+
+	var output bytes.Buffer
+	var stack []block
+	active := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(contents)+1)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		directive, arg := parseDirective(line)
+		switch directive {
+		case "if":
+			if arg == "" {
+				return nil, fmt.Errorf("line %d: missing condition", lineno)
+			}
+			// Conditions inside inactive blocks are not evaluated as they
+			// might rely on variables only defined where the block applies
+			var result bool
+			if active {
+				var err error
+				if result, err = evaluateCondition(arg); err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineno, err)
+				}
+			}
+			stack = append(stack, block{active: result, parent: active, line: lineno})
+			active = active && result
+		case "else":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: @else without @if", lineno)
+			}
+			b := &stack[len(stack)-1]
+			if b.seenElse {
+				return nil, fmt.Errorf("line %d: multiple @else for @if in line %d", lineno, b.line)
+			}
+			b.seenElse = true
+			active = b.parent && !b.active
+		case "endif":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: @endif without @if", lineno)
+			}
+			active = stack[len(stack)-1].parent
+			stack = stack[:len(stack)-1]
+		default:
+			if active {
+				output.WriteString(line)
+			}
+		}
+		output.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("line %d: @if without @endif", stack[len(stack)-1].line)
+	}
+
+	// Keep the content unchanged if there was no trailing newline
+	if !bytes.HasSuffix(contents, []byte("\n")) {
+		output.Truncate(max(output.Len()-1, 0))
+	}
+
+	return output.Bytes(), nil
+}
+
+// parseDirective returns the directive and its argument if the line is a
+// conditional directive and an empty string otherwise.
+func parseDirective(line string) (directive, arg string) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	if !strings.HasPrefix(line, "@") {
+		return "", ""
+	}
+	directive, arg, _ = strings.Cut(line[1:], " ")
+	switch directive {
+	case "if", "else", "endif":
+		return directive, strings.TrimSpace(arg)
+	}
+	return "", ""
+}
+
+// evaluateCondition expands the environment variables in the condition and
+// evaluates it.
+func evaluateCondition(condition string) (bool, error) {
+	expanded, err := template.SubstituteWithOptions(condition, os.LookupEnv, template.WithoutLogging)
+	if err != nil {
+		return false, fmt.Errorf("expanding condition %q failed: %w", condition, err)
+	}
+
+	if strings.Count(expanded, "==")+strings.Count(expanded, "!=") > 1 {
+		return false, errors.New("only a single comparison is supported per condition")
+	}
+	for _, op := range []string{"==", "!="} {
+		left, right, found := strings.Cut(expanded, op)
+		if !found {
+			continue
+		}
+		equal := unquoteOperand(left) == unquoteOperand(right)
+		return equal == (op == "=="), nil
+	}
+
+	switch unquoteOperand(expanded) {
+	case "", "false", "0":
+		return false, nil
+	}
+	return true, nil
+}
+
+func unquoteOperand(operand string) string {
+	operand = strings.TrimSpace(operand)
+	if len(operand) >= 2 && operand[0] == operand[len(operand)-1] && (operand[0] == '"' || operand[0] == '\'') {
+		return operand[1 : len(operand)-1]
+	}
+	return operand
+}
@@ -103,6 +103,10 @@ type Config struct {
 
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
+
+	// Absolute paths of the loaded local files referencing or referenced
+	// by includes
+	loadedFiles map[string]bool
 }
 
 // Ordered plugins used to keep the order in which they appear in a file
@@ -208,6 +212,15 @@ type AgentConfig struct {
 	// output plugin in one call.
 	MetricBatchSize int
 
+	// MetricBatchOrder is the order of the metrics in the batches written to
+	// the output plugins, either "arrival", or grouped by "measurement" or
+	// "series", the latter additionally sorting the metrics by time.
+	MetricBatchOrder string `toml:"metric_batch_order"`
+
+	// RoutingTag is the name of the tag used to route metrics to the outputs
+	// declaring routes. The tag is removed from metrics written to outputs.
+	RoutingTag string `toml:"routing_tag"`
+
 	// MetricBufferLimit is the max number of metrics that each output plugin
 	// will cache. The buffer is cleared when a successful write occurs. When
 	// full, the oldest metrics will be overwritten. This number should be a
@@ -275,6 +288,10 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Interval for writing modified states of plugins using a state store to
+	// the statefile in addition to the termination of Telegraf.
+	StatefileInterval Duration `toml:"statefile_interval"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -299,6 +316,90 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// BufferSegmentSize is the target size of the individual buffer files
+	// when using the "disk" buffer strategy.
+	BufferSegmentSize Size `toml:"buffer_segment_size"`
+
+	// BufferMaxSize limits the total size of the buffer files when using the
+	// "disk" buffer strategy. New metrics are dropped if the limit is reached.
+	BufferMaxSize Size `toml:"buffer_max_size"`
+
+	// BufferMaxAge is the maximum age of metrics kept in the buffer when using
+	// the "disk" buffer strategy. Older metrics are dropped.
+	BufferMaxAge Duration `toml:"buffer_max_age"`
+
+	// SeriesTracking enables counting the active series produced by all
+	// inputs, reported via the internal input.
+	SeriesTracking bool `toml:"series_tracking"`
+
+	// SeriesExpiry is the duration after which a series not seen anymore is
+	// no longer considered active.
+	SeriesExpiry Duration `toml:"series_expiry"`
+
+	// SeriesBudget is the maximum number of active series. Zero disables the
+	// budget, a non-zero value implies series tracking.
+	SeriesBudget int `toml:"series_budget"`
+
+	// SeriesBudgetPolicy determines how metrics of new series exceeding the
+	// budget are handled and can be "drop", "strip_tags" or "hash".
+	SeriesBudgetPolicy string `toml:"series_budget_policy"`
+
+	// SeriesBudgetHashBuckets is the number of series per measurement the
+	// metrics exceeding the budget are distributed to with the "hash" policy.
+	SeriesBudgetHashBuckets int `toml:"series_budget_hash_buckets"`
+
+	// GlobalTagsSources are the sources to resolve additional global tags
+	// from, e.g. instance metadata services of cloud providers.
+	GlobalTagsSources []string `toml:"global_tags_sources"`
+
+	// GlobalTagsFile is the JSON file containing global tags used by the
+	// "file" source.
+	GlobalTagsFile string `toml:"global_tags_file"`
+
+	// GlobalTagsRefreshInterval is the interval to resolve the global tags
+	// from the sources again. Zero resolves the tags at startup only.
+	GlobalTagsRefreshInterval Duration `toml:"global_tags_refresh_interval"`
+
+	// HealthServiceAddress is the address to serve the health and readiness
+	// endpoints on. The endpoints are disabled if empty.
+	HealthServiceAddress string `toml:"health_service_address"`
+
+	// HealthMaxGatherFailures is the number of consecutive failed gather
+	// cycles after which an input is reported unhealthy. Zero disables the
+	// check.
+	HealthMaxGatherFailures int `toml:"health_max_gather_failures"`
+
+	// HealthMaxWriteFailures is the number of consecutive failed writes after
+	// which an output is reported unhealthy. Zero disables the check.
+	HealthMaxWriteFailures int `toml:"health_max_write_failures"`
+
+	// HealthMaxBufferFullness is the buffer fill level, in percent of the
+	// buffer limit, above which an output is reported unhealthy. Zero disables
+	// the check.
+	HealthMaxBufferFullness int `toml:"health_max_buffer_fullness"`
+
+	// TracingEndpoint is the OTLP gRPC endpoint to export traces of the metric
+	// pipeline to. Tracing is disabled if empty.
+	TracingEndpoint string `toml:"tracing_endpoint"`
+
+	// TracingSampleRatio is the fraction of pipeline operations to trace.
+	TracingSampleRatio float64 `toml:"tracing_sample_ratio"`
+
+	// TracingInsecure disables TLS for the connection to the tracing endpoint.
+	TracingInsecure bool `toml:"tracing_insecure"`
+
+	// ShutdownPolicy determines how the output buffers are flushed on
+	// shutdown and can be "flush" or "drain".
+	ShutdownPolicy string `toml:"shutdown_policy"`
+
+	// ShutdownDrainTimeout is the maximum time to retry writing the buffered
+	// metrics of each output with the "drain" policy.
+	ShutdownDrainTimeout Duration `toml:"shutdown_drain_timeout"`
+
+	// ShutdownSpillDirectory is the directory to save the metrics still
+	// buffered after draining to. The metrics are restored on the next start.
+	ShutdownSpillDirectory string `toml:"shutdown_spill_directory"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -600,6 +701,13 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		return fmt.Errorf("error parsing data: %w", err)
 	}
 
+	// Extract the included files to load them after this file
+	includes := c.getFieldStringSlice(tbl, "include")
+	if c.hasErrs() {
+		return c.firstErr()
+	}
+	delete(tbl.Fields, "include")
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -814,6 +922,67 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		c.AggProcessors = append(c.AggProcessors, op.plugin.(*models.RunningProcessor))
 	}
 
+	return c.loadIncludes(path, includes)
+}
+
+// loadIncludes loads the configuration files matching the given glob
+// patterns. Relative patterns are resolved against the directory of the
+// including file. The files matching a pattern are loaded in lexical order
+// and each file can only be loaded once to prevent duplicate plugins and
+// include loops.
+func (c *Config) loadIncludes(path string, patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	if fetchURLRe.MatchString(path) {
+		return errors.New("includes are not supported in remote configurations")
+	}
+
+	if c.loadedFiles == nil {
+		c.loadedFiles = make(map[string]bool)
+	}
+	if path != "" {
+		if fn, err := filepath.Abs(path); err == nil {
+			c.loadedFiles[fn] = true
+		}
+	}
+
+	dir := filepath.Dir(path)
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			log.Printf("W! No configuration files found for include %q", pattern)
+			continue
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil {
+				return err
+			} else if info.IsDir() {
+				continue
+			}
+
+			fn, err := filepath.Abs(match)
+			if err != nil {
+				return err
+			}
+			if c.loadedFiles[fn] {
+				return fmt.Errorf("configuration file %q included multiple times", match)
+			}
+			c.loadedFiles[fn] = true
+
+			if err := c.LoadConfig(match); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -926,7 +1095,10 @@ func requestURLConfig(req *http.Request) ([]byte, error) {
 // will find environment variables and replace them.
 func parseConfig(contents []byte) (*ast.Table, error) {
 	contents = trimBOM(contents)
-	var err error
+	contents, err := processConditionals(contents)
+	if err != nil {
+		return nil, err
+	}
 	contents, err = removeComments(contents)
 	if err != nil {
 		return nil, err
@@ -1075,6 +1247,7 @@ func (c *Config) addParser(parentcategory, parentname string, table *ast.Table)
 		}
 	}
 	conf.LogLevel = c.getFieldString(table, "log_level")
+	conf.DataCompression = c.getFieldString(table, "data_compression")
 
 	creator, ok := parsers.Parsers[conf.DataFormat]
 	if !ok {
@@ -1612,6 +1785,8 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.CollectionOffset, _ = c.getFieldDuration(tbl, "collection_offset")
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
+	cp.Schedule = c.getFieldString(tbl, "schedule")
+	cp.ScheduleCatchUp = c.getFieldString(tbl, "schedule_catchup")
 
 	cp.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	cp.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1653,11 +1828,15 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 		return nil, err
 	}
 	oc := &models.OutputConfig{
-		Name:            name,
-		Source:          source,
-		Filter:          filter,
-		BufferStrategy:  c.Agent.BufferStrategy,
-		BufferDirectory: c.Agent.BufferDirectory,
+		Name:              name,
+		Source:            source,
+		Filter:            filter,
+		BufferStrategy:    c.Agent.BufferStrategy,
+		BufferDirectory:   c.Agent.BufferDirectory,
+		BufferSegmentSize: int64(c.Agent.BufferSegmentSize),
+		BufferMaxSize:     int64(c.Agent.BufferMaxSize),
+		BufferMaxAge:      time.Duration(c.Agent.BufferMaxAge),
+		MetricBatchOrder:  c.Agent.MetricBatchOrder,
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1666,6 +1845,9 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.FlushJitter, _ = c.getFieldDuration(tbl, "flush_jitter")
 	oc.MetricBufferLimit = c.getFieldInt(tbl, "metric_buffer_limit")
 	oc.MetricBatchSize = c.getFieldInt(tbl, "metric_batch_size")
+	if order := c.getFieldString(tbl, "metric_batch_order"); order != "" {
+		oc.MetricBatchOrder = order
+	}
 	oc.Alias = c.getFieldString(tbl, "alias")
 	oc.NameOverride = c.getFieldString(tbl, "name_override")
 	oc.NameSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1673,6 +1855,39 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
 
+	oc.Route = models.Route{
+		Tag:         c.Agent.RoutingTag,
+		Expressions: c.getFieldStringSlice(tbl, "routes"),
+	}
+	if err := oc.Route.Compile(); err != nil {
+		return nil, fmt.Errorf("compiling routes of outputs.%s failed: %w", name, err)
+	}
+
+	// Buffer settings can be overridden per output
+	if strategy := c.getFieldString(tbl, "buffer_strategy"); strategy != "" {
+		oc.BufferStrategy = strategy
+	}
+	if directory := c.getFieldString(tbl, "buffer_directory"); directory != "" {
+		oc.BufferDirectory = directory
+	}
+	if size, found := c.getFieldSize(tbl, "buffer_segment_size"); found {
+		oc.BufferSegmentSize = size
+	}
+	if size, found := c.getFieldSize(tbl, "buffer_max_size"); found {
+		oc.BufferMaxSize = size
+	}
+	if age, found := c.getFieldDuration(tbl, "buffer_max_age"); found {
+		oc.BufferMaxAge = age
+	}
+
+	oc.MetricRateLimit = c.getFieldInt64(tbl, "metric_rate_limit")
+	oc.ByteRateLimit, _ = c.getFieldSize(tbl, "byte_rate_limit")
+	oc.BackpressureThreshold = c.getFieldInt(tbl, "backpressure_threshold")
+	oc.BackpressureDelay, _ = c.getFieldDuration(tbl, "backpressure_delay")
+	oc.DeadLetterFile = c.getFieldString(tbl, "dead_letter_file")
+	oc.DeadLetterOutput = c.getFieldString(tbl, "dead_letter_output")
+	oc.DrainTimeout, _ = c.getFieldDuration(tbl, "drain_timeout")
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
@@ -1690,24 +1905,28 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
-		"buffer_strategy", "buffer_directory",
+		"backpressure_delay", "backpressure_threshold",
+		"buffer_directory", "buffer_max_age", "buffer_max_size", "buffer_segment_size", "buffer_strategy",
+		"byte_rate_limit",
 		"collection_jitter", "collection_offset",
-		"data_format", "delay", "drop", "drop_original",
+		"data_format", "dead_letter_file", "dead_letter_output", "delay", "drain_timeout", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"metric_batch_order", "metric_batch_size", "metric_buffer_limit", "metric_rate_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
+		"routes",
+		"schedule", "schedule_catchup",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
 	case "id":
 
 	// Parser and serializer options to ignore
-	case "data_type", "influx_parser_type":
+	case "data_compression", "data_type", "influx_parser_type":
 
 	default:
 		c.unusedFieldsMutex.Lock()
@@ -1780,6 +1999,32 @@ func (c *Config) getFieldDuration(tbl *ast.Table, fieldName string) (time.Durati
 	return 0, false
 }
 
+func (c *Config) getFieldSize(tbl *ast.Table, fieldName string) (int64, bool) {
+	if node, ok := tbl.Fields[fieldName]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			var raw string
+			switch v := kv.Value.(type) {
+			case *ast.String:
+				raw = v.Value
+			case *ast.Integer:
+				raw = v.Value
+			default:
+				c.addError(tbl, fmt.Errorf("found unexpected format while parsing %q, expecting size", fieldName))
+				return 0, false
+			}
+
+			var size Size
+			if err := size.UnmarshalText([]byte(raw)); err != nil {
+				c.addError(tbl, fmt.Errorf("error parsing size: %w", err))
+				return 0, false
+			}
+			return int64(size), true
+		}
+	}
+
+	return 0, false
+}
+
 func (c *Config) getFieldBool(tbl *ast.Table, fieldName string) bool {
 	if node, ok := tbl.Fields[fieldName]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
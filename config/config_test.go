@@ -491,6 +491,209 @@ func TestConfig_SliceComment(t *testing.T) {
 	require.Equal(t, []string{"test"}, output.Scopes)
 }
 
+func TestConfig_OutputBufferSettings(t *testing.T) {
+	dir := t.TempDir()
+	cfg := fmt.Sprintf(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = %q
+  buffer_max_size = "10MiB"
+  buffer_max_age = "1h"
+
+[[outputs.http]]
+  url = "http://localhost:8080/default"
+
+[[outputs.http]]
+  url = "http://localhost:8080/memory"
+  buffer_strategy = "memory"
+
+[[outputs.http]]
+  url = "http://localhost:8080/limits"
+  buffer_segment_size = 1048576
+  buffer_max_size = "1MiB"
+  buffer_max_age = "24h"
+`, dir)
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 3)
+	defer func() {
+		for _, o := range c.Outputs {
+			o.Close()
+		}
+	}()
+
+	require.Equal(t, "disk", c.Outputs[0].Config.BufferStrategy)
+	require.Equal(t, dir, c.Outputs[0].Config.BufferDirectory)
+	require.Zero(t, c.Outputs[0].Config.BufferSegmentSize)
+	require.Equal(t, int64(10*1024*1024), c.Outputs[0].Config.BufferMaxSize)
+	require.Equal(t, time.Hour, c.Outputs[0].Config.BufferMaxAge)
+
+	require.Equal(t, "memory", c.Outputs[1].Config.BufferStrategy)
+
+	require.Equal(t, "disk", c.Outputs[2].Config.BufferStrategy)
+	require.Equal(t, int64(1024*1024), c.Outputs[2].Config.BufferSegmentSize)
+	require.Equal(t, int64(1024*1024), c.Outputs[2].Config.BufferMaxSize)
+	require.Equal(t, 24*time.Hour, c.Outputs[2].Config.BufferMaxAge)
+}
+
+func TestConfig_OutputRateLimitSettings(t *testing.T) {
+	cfg := `
+[[outputs.http]]
+  url = "http://localhost:8080/unlimited"
+
+[[outputs.http]]
+  url = "http://localhost:8080/limited"
+  metric_rate_limit = 1000
+  byte_rate_limit = "1MiB"
+  backpressure_threshold = 80
+  backpressure_delay = "30s"
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 2)
+
+	require.Zero(t, c.Outputs[0].Config.MetricRateLimit)
+	require.Zero(t, c.Outputs[0].Config.ByteRateLimit)
+	require.Zero(t, c.Outputs[0].Config.BackpressureThreshold)
+
+	require.Equal(t, int64(1000), c.Outputs[1].Config.MetricRateLimit)
+	require.Equal(t, int64(1024*1024), c.Outputs[1].Config.ByteRateLimit)
+	require.Equal(t, 80, c.Outputs[1].Config.BackpressureThreshold)
+	require.Equal(t, 30*time.Second, c.Outputs[1].Config.BackpressureDelay)
+}
+
+func TestConfig_OutputDeadLetterSettings(t *testing.T) {
+	cfg := `
+[[outputs.http]]
+  url = "http://localhost:8080/primary"
+  dead_letter_file = "/var/lib/telegraf/rejected.influx"
+  dead_letter_output = "rejected"
+  drain_timeout = "1m"
+
+[[outputs.http]]
+  alias = "rejected"
+  url = "http://localhost:8080/rejected"
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 2)
+
+	require.Equal(t, "/var/lib/telegraf/rejected.influx", c.Outputs[0].Config.DeadLetterFile)
+	require.Equal(t, "rejected", c.Outputs[0].Config.DeadLetterOutput)
+	require.Equal(t, time.Minute, c.Outputs[0].Config.DrainTimeout)
+
+	require.Empty(t, c.Outputs[1].Config.DeadLetterFile)
+	require.Empty(t, c.Outputs[1].Config.DeadLetterOutput)
+}
+
+func TestConfig_InputSchedule(t *testing.T) {
+	cfg := `
+[[inputs.memcached]]
+  schedule = "0 2 * * *"
+  schedule_catchup = "once"
+
+[[inputs.memcached]]
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Inputs, 2)
+
+	require.Equal(t, "0 2 * * *", c.Inputs[0].Config.Schedule)
+	require.Equal(t, "once", c.Inputs[0].Config.ScheduleCatchUp)
+
+	require.Empty(t, c.Inputs[1].Config.Schedule)
+	require.Empty(t, c.Inputs[1].Config.ScheduleCatchUp)
+}
+
+func TestConfig_Includes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(filepath.Join("testdata", "includes", "telegraf.toml")))
+
+	// Included files are loaded after the including file in lexical order
+	require.Len(t, c.Inputs, 3)
+	require.Equal(t, "memcached", c.Inputs[0].Config.Name)
+	require.Equal(t, "procstat", c.Inputs[1].Config.Name)
+	require.Equal(t, filepath.Join("testdata", "includes", "conf.d", "10-procstat.toml"), c.Inputs[1].Config.Source)
+	require.Equal(t, "exec", c.Inputs[2].Config.Name)
+	require.Equal(t, filepath.Join("testdata", "includes", "conf.d", "20-exec.toml"), c.Inputs[2].Config.Source)
+}
+
+func TestConfig_IncludeLoop(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfig(filepath.Join("testdata", "includes", "loop", "a.toml"))
+	require.ErrorContains(t, err, "included multiple times")
+}
+
+func TestConfig_ConditionalBlocks(t *testing.T) {
+	t.Setenv("TELEGRAF_TEST_ENVIRONMENT", "production")
+
+	cfg := `
+# @if ${TELEGRAF_TEST_ENVIRONMENT:-development} == "production"
+[[outputs.http]]
+  url = "http://localhost:8080/production"
+# @else
+[[outputs.http]]
+  url = "http://localhost:8080/development"
+# @endif
+
+# @if ${TELEGRAF_TEST_DEBUG}
+[[outputs.http]]
+  url = "http://localhost:8080/debug"
+# @endif
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, "http", c.Outputs[0].Config.Name)
+	require.Equal(t, "http://localhost:8080/production", c.Outputs[0].Output.(*MockupOutputPlugin).URL)
+}
+
+func TestConfig_OutputRoutes(t *testing.T) {
+	cfg := `
+[agent]
+  routing_tag = "route"
+
+[[outputs.http]]
+  url = "http://localhost:8080/all"
+
+[[outputs.http]]
+  url = "http://localhost:8080/errors"
+  routes = ["errors", "name:syslog tag:severity=err"]
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 2)
+
+	require.False(t, c.Outputs[0].Config.Route.IsActive())
+	require.Equal(t, "route", c.Outputs[0].Config.Route.Tag)
+
+	require.True(t, c.Outputs[1].Config.Route.IsActive())
+	require.Equal(t, []string{"errors", "name:syslog tag:severity=err"}, c.Outputs[1].Config.Route.Expressions)
+
+	m := metric.New("cpu", map[string]string{"route": "errors"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.True(t, c.Outputs[1].Config.Route.Match(m))
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.False(t, c.Outputs[1].Config.Route.Match(m))
+}
+
+func TestConfig_OutputRoutesWithoutRoutingTag(t *testing.T) {
+	cfg := `
+[[outputs.http]]
+  url = "http://localhost:8080/errors"
+  routes = ["errors"]
+`
+
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(cfg), config.EmptySourcePath)
+	require.ErrorContains(t, err, "requires the agent's 'routing_tag' setting")
+}
+
 func TestConfig_BadOrdering(t *testing.T) {
 	// #3444: when not using inline tables, care has to be taken so subsequent configuration
 	// doesn't become part of the table. This is not a bug, but TOML syntax.
@@ -374,6 +374,121 @@ func TestRemoveComments(t *testing.T) {
 	require.Equal(t, string(expected), string(actual))
 }
 
+func TestConditionals(t *testing.T) {
+	t.Setenv("TELEGRAF_TEST_ENV", "production")
+
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name:     "no conditionals",
+			contents: "a = 1\n# comment\nb = 2",
+			expected: "a = 1\n# comment\nb = 2",
+		},
+		{
+			name:     "equal",
+			contents: "# @if ${TELEGRAF_TEST_ENV} == production\na = 1\n# @endif\n",
+			expected: "\na = 1\n\n",
+		},
+		{
+			name:     "not equal with else",
+			contents: "# @if ${TELEGRAF_TEST_ENV} != \"production\"\na = 1\n# @else\na = 2\n# @endif\n",
+			expected: "\n\n\na = 2\n\n",
+		},
+		{
+			name:     "default value",
+			contents: "#@if ${TELEGRAF_TEST_UNSET:-staging} == staging\na = 1\n#@endif\n",
+			expected: "\na = 1\n\n",
+		},
+		{
+			name:     "unset variable",
+			contents: "# @if ${TELEGRAF_TEST_UNSET}\na = 1\n# @endif\n",
+			expected: "\n\n\n",
+		},
+		{
+			name:     "false value",
+			contents: "# @if false\na = 1\n# @else\na = 2\n# @endif\n",
+			expected: "\n\n\na = 2\n\n",
+		},
+		{
+			name:     "nested",
+			contents: "# @if 1\n# @if 0\na = 1\n# @else\na = 2\n# @endif\n# @else\na = 3\n# @endif\n",
+			expected: "\n\n\n\na = 2\n\n\n\n\n",
+		},
+		{
+			name:     "nested in inactive block",
+			contents: "# @if 0\n# @if 0\na = 1\n# @else\na = 2\n# @endif\n# @endif\n",
+			expected: "\n\n\n\n\n\n\n",
+		},
+		{
+			name:     "not evaluated in inactive block",
+			contents: "# @if 0\n# @if ${TELEGRAF_TEST_UNSET:?required}\na = 1\n# @endif\n# @else\na = 2\n# @endif\n",
+			expected: "\n\n\n\n\na = 2\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := processConditionals([]byte(tt.contents))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestConditionalsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name:     "missing endif",
+			contents: "a = 1\n# @if 1\n",
+			expected: "line 2: @if without @endif",
+		},
+		{
+			name:     "endif without if",
+			contents: "# @endif\n",
+			expected: "line 1: @endif without @if",
+		},
+		{
+			name:     "else without if",
+			contents: "# @else\n",
+			expected: "line 1: @else without @if",
+		},
+		{
+			name:     "multiple else",
+			contents: "# @if 1\n# @else\n# @else\n# @endif\n",
+			expected: "line 3: multiple @else for @if in line 1",
+		},
+		{
+			name:     "missing condition",
+			contents: "# @if\n# @endif\n",
+			expected: "line 1: missing condition",
+		},
+		{
+			name:     "multiple comparisons",
+			contents: "# @if a == b == c\n# @endif\n",
+			expected: "only a single comparison is supported",
+		},
+		{
+			name:     "failing expansion",
+			contents: "# @if ${TELEGRAF_TEST_UNSET:?required}\n# @endif\n",
+			expected: "line 1: expanding condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processConditionals([]byte(tt.contents))
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
 func TestURLRetries3Fails(t *testing.T) {
 	httpLoadConfigRetryInterval = 0 * time.Second
 	responseCounter := 0
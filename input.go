@@ -21,3 +21,16 @@ type ServiceInput interface {
 	// to the accumulator before returning.
 	Stop()
 }
+
+// PausableInput is a ServiceInput that can temporarily stop consuming data,
+// e.g. on sustained backpressure of the outputs.
+type PausableInput interface {
+	ServiceInput
+
+	// Pause stops consuming new data until Resume is called. Data already
+	// received should still be passed to the accumulator.
+	Pause()
+
+	// Resume continues consuming data after a previous call to Pause.
+	Resume()
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/golang/snappy"
 	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zlib"
 	"github.com/klauspost/compress/zstd"
@@ -113,6 +114,8 @@ func NewContentEncoder(encoding string, options ...EncodingOption) (ContentEncod
 		return NewZlibEncoder(options...)
 	case "zstd":
 		return NewZstdEncoder(options...)
+	case "snappy":
+		return NewSnappyEncoder(options...)
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -156,6 +159,8 @@ func NewContentDecoder(encoding string, options ...DecodingOption) (ContentDecod
 		return NewZlibDecoder(options...), nil
 	case "zstd":
 		return NewZstdDecoder(options...)
+	case "snappy":
+		return NewSnappyDecoder(options...), nil
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -318,6 +323,21 @@ func (e *ZstdEncoder) Encode(data []byte) ([]byte, error) {
 	return e.encoder.EncodeAll(data, make([]byte, 0, len(data))), nil
 }
 
+// SnappyEncoder compresses buffers using the snappy block format.
+type SnappyEncoder struct{}
+
+func NewSnappyEncoder(options ...EncodingOption) (*SnappyEncoder, error) {
+	if len(options) > 0 {
+		return nil, errors.New("snappy encoder does not support options")
+	}
+
+	return &SnappyEncoder{}, nil
+}
+
+func (*SnappyEncoder) Encode(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
 // IdentityEncoder is a null encoder that applies no transformation.
 type IdentityEncoder struct{}
 
@@ -480,6 +500,121 @@ func (d *ZstdDecoder) Decode(data []byte) ([]byte, error) {
 	return d.decoder.DecodeAll(data, nil)
 }
 
+// SnappyDecoder decompresses buffers with snappy compression. Both, the block
+// and the framed stream format, are supported.
+type SnappyDecoder struct {
+	buf                  *bytes.Buffer
+	maxDecompressionSize int64
+}
+
+// Magic bytes of the snappy stream identifier chunk
+var snappyStreamMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+func NewSnappyDecoder(options ...DecodingOption) *SnappyDecoder {
+	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return &SnappyDecoder{
+		buf:                  new(bytes.Buffer),
+		maxDecompressionSize: cfg.maxDecompressionSize,
+	}
+}
+
+func (*SnappyDecoder) SetEncoding(string) {}
+
+func (d *SnappyDecoder) Decode(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, snappyStreamMagic) {
+		d.buf.Reset()
+		n, err := io.CopyN(d.buf, snappy.NewReader(bytes.NewReader(data)), d.maxDecompressionSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		} else if n == d.maxDecompressionSize {
+			return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+		}
+		return d.buf.Bytes(), nil
+	}
+
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if int64(size) >= d.maxDecompressionSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+	}
+	return snappy.Decode(nil, data)
+}
+
+// DetectingDecoder decompresses buffers according to the compression
+// detected from the magic bytes at the start of the data. Data without any
+// known magic bytes is returned unchanged. As the snappy block format does
+// not contain magic bytes, only the framed snappy format can be detected.
+type DetectingDecoder struct {
+	gzip   *GzipDecoder
+	zlib   *ZlibDecoder
+	zstd   *ZstdDecoder
+	snappy *SnappyDecoder
+}
+
+func NewDetectingDecoder(options ...DecodingOption) (*DetectingDecoder, error) {
+	zstdDecoder, err := NewZstdDecoder(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DetectingDecoder{
+		gzip:   NewGzipDecoder(options...),
+		zlib:   NewZlibDecoder(options...),
+		zstd:   zstdDecoder,
+		snappy: NewSnappyDecoder(options...),
+	}, nil
+}
+
+func (*DetectingDecoder) SetEncoding(string) {}
+
+func (d *DetectingDecoder) Decode(data []byte) ([]byte, error) {
+	switch DetectContentEncoding(data) {
+	case "gzip":
+		return d.gzip.Decode(data)
+	case "zlib":
+		return d.zlib.Decode(data)
+	case "zstd":
+		return d.zstd.Decode(data)
+	case "snappy":
+		return d.snappy.Decode(data)
+	}
+	return data, nil
+}
+
+// DetectContentEncoding returns the compression of the data detected from its
+// magic bytes or "identity" if no known magic bytes are found.
+func DetectContentEncoding(data []byte) string {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return "gzip"
+	case len(data) >= 4 && data[0] == 0x28 && data[1] == 0xb5 && data[2] == 0x2f && data[3] == 0xfd:
+		return "zstd"
+	case bytes.HasPrefix(data, snappyStreamMagic):
+		return "snappy"
+	case isZlibHeader(data):
+		return "zlib"
+	}
+	return "identity"
+}
+
+// isZlibHeader checks for a zlib header as defined in RFC 1950, i.e. deflate
+// compression with a window size of at most 32k, no preset dictionary and a
+// valid header checksum. Data starting with a preset dictionary cannot be
+// decoded anyway and excluding it avoids detecting text like "x value=1".
+func isZlibHeader(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	cmf, flg := data[0], data[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && flg&0x20 == 0 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
 // IdentityDecoder is a null decoder that returns the input.
 type IdentityDecoder struct {
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/require"
 )
 
@@ -112,6 +113,96 @@ func TestZstdReuse(t *testing.T) {
 	require.Equal(t, "doody", string(actual))
 }
 
+func TestSnappyDecode(t *testing.T) {
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	// Block format
+	actual, err := dec.Decode(snappy.Encode(nil, []byte("howdy")))
+	require.NoError(t, err)
+	require.Equal(t, "howdy", string(actual))
+
+	// Framed stream format
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	_, err = w.Write([]byte("doody"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	actual, err = dec.Decode(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "doody", string(actual))
+}
+
+func TestSnappyEncodeDecode(t *testing.T) {
+	enc, err := NewSnappyEncoder()
+	require.NoError(t, err)
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+}
+
+func TestSnappyDecodeWithTooLargeMessage(t *testing.T) {
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(3))
+
+	_, err := dec.Decode(snappy.Encode(nil, []byte("howdy")))
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 3")
+}
+
+func TestDetectingDecode(t *testing.T) {
+	var framed bytes.Buffer
+	w := snappy.NewBufferedWriter(&framed)
+	_, err := w.Write([]byte("howdy"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	encoded := map[string][]byte{
+		"identity": []byte("howdy"),
+		"snappy":   framed.Bytes(),
+	}
+	for _, encoding := range []string{"gzip", "zlib", "zstd"} {
+		enc, err := NewContentEncoder(encoding)
+		require.NoError(t, err)
+		payload, err := enc.Encode([]byte("howdy"))
+		require.NoError(t, err)
+		encoded[encoding] = bytes.Clone(payload)
+	}
+
+	dec, err := NewDetectingDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+	require.NoError(t, err)
+	for encoding, payload := range encoded {
+		t.Run(encoding, func(t *testing.T) {
+			require.Equal(t, encoding, DetectContentEncoding(payload))
+
+			actual, err := dec.Decode(payload)
+			require.NoError(t, err)
+			require.Equal(t, "howdy", string(actual))
+		})
+	}
+}
+
+func TestDetectContentEncodingPlainText(t *testing.T) {
+	// Line protocol starting with 'x' has a valid zlib header checksum but
+	// announces a preset dictionary
+	payload := []byte("x value=1 1700000000000000000\n")
+	require.Equal(t, "identity", DetectContentEncoding(payload))
+
+	dec, err := NewDetectingDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+	require.NoError(t, err)
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+	require.Equal(t, payload, actual)
+
+	for _, payload := range []string{"x", "xy value=1", "H value=1", "{\"x\": 1}"} {
+		require.Equal(t, "identity", DetectContentEncoding([]byte(payload)), payload)
+	}
+}
+
 func TestIdentityEncodeDecode(t *testing.T) {
 	dec := NewIdentityDecoder(WithMaxDecompressionSize(maxDecompressionSize))
 	enc, err := NewIdentityEncoder()
@@ -61,3 +61,35 @@ func (e *PartialWriteError) Error() string {
 func (e *PartialWriteError) Unwrap() error {
 	return e.Err
 }
+
+// RejectedError indicates that the output permanently rejected the whole batch,
+// e.g. due to a client error response of the service, so retrying the write
+// will not succeed. The metrics are passed to the dead-letter destination of
+// the output if configured and are kept for retrying otherwise.
+type RejectedError struct {
+	Err error
+}
+
+func (e *RejectedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// MetricError denotes an error occurring while processing metrics of the
+// given measurement. The measurement is added to structured log messages
+// containing the error.
+type MetricError struct {
+	Measurement string
+	Err         error
+}
+
+func (e *MetricError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MetricError) Unwrap() error {
+	return e.Err
+}
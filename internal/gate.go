@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate blocks callers of Wait while it is closed. Service inputs use it to
+// stop consuming messages while paused due to backpressure of the outputs.
+// The zero value is an open gate.
+type Gate struct {
+	mu sync.Mutex
+	// open is non-nil while the gate is closed and will be closed on Open
+	open chan struct{}
+}
+
+// Close closes the gate so subsequent calls to Wait block until Open is
+// called.
+func (g *Gate) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.open == nil {
+		g.open = make(chan struct{})
+	}
+}
+
+// Open opens the gate and releases all callers waiting for it.
+func (g *Gate) Open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.open != nil {
+		close(g.open)
+		g.open = nil
+	}
+}
+
+// IsClosed returns true if the gate is currently closed.
+func (g *Gate) IsClosed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.open != nil
+}
+
+// Wait blocks until the gate is open or the context is done.
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	open := g.open
+	g.mu.Unlock()
+
+	if open == nil {
+		return nil
+	}
+
+	select {
+	case <-open:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate(t *testing.T) {
+	var g Gate
+	require.False(t, g.IsClosed())
+	require.NoError(t, g.Wait(t.Context()))
+
+	g.Close()
+	require.True(t, g.IsClosed())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Wait(t.Context())
+	}()
+
+	select {
+	case <-done:
+		require.Fail(t, "wait returned on closed gate")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.Open()
+	require.False(t, g.IsClosed())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "wait did not return on opened gate")
+	}
+}
+
+func TestGateContextCancel(t *testing.T) {
+	var g Gate
+	g.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	require.ErrorIs(t, g.Wait(ctx), context.Canceled)
+}
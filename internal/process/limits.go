@@ -0,0 +1,164 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// ErrOutputLimit is returned if a process exceeded the allowed output size
+var ErrOutputLimit = errors.New("output size limit exceeded")
+
+// Limits of the resources available to a process
+type Limits struct {
+	// Resource limits applied to the process itself
+	CPUTime   config.Duration `toml:"limit_cpu_time"`
+	Memory    config.Size     `toml:"limit_memory"`
+	OpenFiles uint64          `toml:"limit_open_files"`
+
+	// Control group to place the process in, either as absolute path or
+	// relative to the cgroup mount-point
+	Cgroup string `toml:"cgroup"`
+
+	// Maximum number of bytes read from the output streams of the process
+	// during one run
+	StdoutSize config.Size `toml:"limit_stdout_size"`
+	StderrSize config.Size `toml:"limit_stderr_size"`
+}
+
+// Validate checks the limits for consistency
+func (l *Limits) Validate() error {
+	if l.CPUTime < 0 {
+		return errors.New("'limit_cpu_time' must not be negative")
+	}
+	if l.Memory < 0 {
+		return errors.New("'limit_memory' must not be negative")
+	}
+	if l.StdoutSize < 0 {
+		return errors.New("'limit_stdout_size' must not be negative")
+	}
+	if l.StderrSize < 0 {
+		return errors.New("'limit_stderr_size' must not be negative")
+	}
+	return l.supported()
+}
+
+func (l *Limits) hasResourceLimits() bool {
+	return l.CPUTime > 0 || l.Memory > 0 || l.OpenFiles > 0
+}
+
+// Run executes the command once within the limits and waits for it to
+// finish. The process is killed if it exceeds the timeout or the output size
+// limits.
+func Run(cmd *exec.Cmd, timeout time.Duration, limits Limits) error {
+	var exceeded bool
+	var mu sync.Mutex
+	kill := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if exceeded {
+			return
+		}
+		exceeded = true
+		if cmd.Process != nil {
+			//nolint:errcheck // The process might have exited already
+			cmd.Process.Kill()
+		}
+	}
+	if limits.StdoutSize > 0 && cmd.Stdout != nil {
+		cmd.Stdout = &limitWriter{w: cmd.Stdout, remaining: int64(limits.StdoutSize), exceeded: kill}
+	}
+	if limits.StderrSize > 0 && cmd.Stderr != nil {
+		cmd.Stderr = &limitWriter{w: cmd.Stderr, remaining: int64(limits.StderrSize), exceeded: kill}
+	}
+
+	if err := startWithLimits(cmd, limits); err != nil {
+		return err
+	}
+	err := internal.WaitTimeout(cmd, timeout)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if exceeded {
+		return ErrOutputLimit
+	}
+	return err
+}
+
+// startWithLimits starts the command and applies the limits to the started
+// process. The process is killed if the limits cannot be applied.
+func startWithLimits(cmd *exec.Cmd, limits Limits) error {
+	cleanup, err := limits.prepare(cmd)
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	cleanup()
+	if err != nil {
+		return err
+	}
+
+	if err := limits.apply(cmd.Process.Pid); err != nil {
+		//nolint:errcheck // Best effort as the process is not usable anyway
+		cmd.Process.Kill()
+		//nolint:errcheck // Only reaping the process here
+		cmd.Wait()
+		return fmt.Errorf("applying limits failed: %w", err)
+	}
+	return nil
+}
+
+// limitReader returns EOF after the given number of bytes were read and
+// notifies about exceeding the limit.
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  func()
+}
+
+func (l *limitReader) Read(b []byte) (int, error) {
+	if l.remaining <= 0 {
+		l.exceeded()
+		return 0, io.EOF
+	}
+	if int64(len(b)) > l.remaining {
+		b = b[:l.remaining]
+	}
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// limitWriter discards data after the given number of bytes were written and
+// notifies about exceeding the limit.
+type limitWriter struct {
+	w         io.Writer
+	remaining int64
+	exceeded  func()
+}
+
+func (l *limitWriter) Write(b []byte) (int, error) {
+	if int64(len(b)) <= l.remaining {
+		n, err := l.w.Write(b)
+		l.remaining -= int64(n)
+		return n, err
+	}
+
+	if l.remaining > 0 {
+		if _, err := l.w.Write(b[:l.remaining]); err != nil {
+			return 0, err
+		}
+		l.remaining = 0
+	}
+	l.exceeded()
+
+	// Pretend to have written everything to not fail the copying before
+	// the process is killed
+	return len(b), nil
+}
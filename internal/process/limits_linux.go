@@ -0,0 +1,75 @@
+//go:build linux
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+func (*Limits) supported() error {
+	return nil
+}
+
+// prepare sets up the command to be started in the configured control group.
+// The returned function must be called after starting the command.
+func (l *Limits) prepare(cmd *exec.Cmd) (func(), error) {
+	if l.Cgroup == "" {
+		return func() {}, nil
+	}
+
+	path := l.Cgroup
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cgroupRoot, path)
+	}
+	fd, err := unix.Open(path, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening cgroup %q failed: %w", path, err)
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+
+	//nolint:errcheck // Nothing to do if closing the directory fails
+	return func() { unix.Close(fd) }, nil
+}
+
+// apply sets the resource limits of the started process
+func (l *Limits) apply(pid int) error {
+	if !l.hasResourceLimits() {
+		return nil
+	}
+
+	if l.CPUTime > 0 {
+		seconds := uint64(time.Duration(l.CPUTime).Round(time.Second) / time.Second)
+		if err := setLimit(pid, unix.RLIMIT_CPU, max(seconds, 1)); err != nil {
+			return fmt.Errorf("setting CPU time limit failed: %w", err)
+		}
+	}
+	if l.Memory > 0 {
+		if err := setLimit(pid, unix.RLIMIT_AS, uint64(l.Memory)); err != nil {
+			return fmt.Errorf("setting memory limit failed: %w", err)
+		}
+	}
+	if l.OpenFiles > 0 {
+		if err := setLimit(pid, unix.RLIMIT_NOFILE, l.OpenFiles); err != nil {
+			return fmt.Errorf("setting open files limit failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func setLimit(pid, resource int, value uint64) error {
+	limit := &unix.Rlimit{Cur: value, Max: value}
+	return unix.Prlimit(pid, resource, limit, nil)
+}
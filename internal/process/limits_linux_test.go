@@ -0,0 +1,38 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestStartWithLimits(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	cmd := exec.Command(exe, "-external")
+	cmd.Env = append(os.Environ(), "INTERNAL_PROCESS_MODE=application")
+	limits := Limits{
+		Memory:    1024 * 1024 * 1024,
+		OpenFiles: 64,
+	}
+	require.NoError(t, startWithLimits(cmd, limits))
+	defer func() {
+		require.NoError(t, cmd.Process.Kill())
+		require.Error(t, cmd.Wait())
+	}()
+
+	var actual unix.Rlimit
+	require.NoError(t, unix.Prlimit(cmd.Process.Pid, unix.RLIMIT_AS, nil, &actual))
+	require.Equal(t, uint64(1024*1024*1024), actual.Cur)
+	require.NoError(t, unix.Prlimit(cmd.Process.Pid, unix.RLIMIT_NOFILE, nil, &actual))
+	require.Equal(t, uint64(64), actual.Cur)
+}
+
+func TestStartWithLimitsInvalidCgroup(t *testing.T) {
+	cmd := exec.Command("true")
+	require.ErrorContains(t, startWithLimits(cmd, Limits{Cgroup: "telegraf-nonexisting"}), "opening cgroup")
+}
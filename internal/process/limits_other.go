@@ -0,0 +1,26 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func (l *Limits) supported() error {
+	if l.hasResourceLimits() {
+		return errors.New("resource limits are not supported on this platform")
+	}
+	if l.Cgroup != "" {
+		return errors.New("control groups are not supported on this platform")
+	}
+	return nil
+}
+
+func (l *Limits) prepare(*exec.Cmd) (func(), error) {
+	return func() {}, l.supported()
+}
+
+func (l *Limits) apply(int) error {
+	return l.supported()
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +27,15 @@ type Process struct {
 	StopOnError  bool
 	Log          telegraf.Logger
 
+	// Limits of the resources available to the process
+	Limits Limits
+
+	// Maximum number of restarts within the restart window before giving
+	// up on the process, zero means unlimited restarts. Restarts are
+	// counted over the whole lifetime if no window is given.
+	MaxRestarts   int
+	RestartWindow time.Duration
+
 	name       string
 	args       []string
 	envs       []string
@@ -127,7 +137,7 @@ func (p *Process) cmdStart() error {
 
 	p.Log.Infof("Starting process: %s %s", p.name, p.args)
 
-	if err := p.Cmd.Start(); err != nil {
+	if err := startWithLimits(p.Cmd, p.Limits); err != nil {
 		return fmt.Errorf("error starting process: %w", err)
 	}
 	atomic.StoreInt32(&p.pid, int32(p.Cmd.Process.Pid))
@@ -136,6 +146,7 @@ func (p *Process) cmdStart() error {
 
 // cmdLoop watches an already running process, restarting it when appropriate.
 func (p *Process) cmdLoop(ctx context.Context) error {
+	var restarts []time.Time
 	for {
 		err := p.cmdWait(ctx)
 		if err != nil && p.StopOnError {
@@ -147,6 +158,25 @@ func (p *Process) cmdLoop(ctx context.Context) error {
 		}
 
 		p.Log.Errorf("Process %s exited: %v", p.Cmd.Path, err)
+
+		// Check the restart budget only counting the restarts within the
+		// window
+		if p.MaxRestarts > 0 {
+			now := time.Now()
+			if p.RestartWindow > 0 {
+				restarts = slices.DeleteFunc(restarts, func(t time.Time) bool {
+					return now.Sub(t) > p.RestartWindow
+				})
+			}
+			if len(restarts) >= p.MaxRestarts {
+				if p.RestartWindow > 0 {
+					return fmt.Errorf("exceeded %d restarts within %s", p.MaxRestarts, p.RestartWindow)
+				}
+				return fmt.Errorf("exceeded %d restarts", p.MaxRestarts)
+			}
+			restarts = append(restarts, now)
+		}
+
 		p.Log.Infof("Restarting in %s...", p.RestartDelay)
 
 		select {
@@ -175,15 +205,23 @@ func (p *Process) cmdWait(ctx context.Context) error {
 	processCtx, processCancel := context.WithCancel(context.Background())
 	defer processCancel()
 
+	stdout, stderr := io.Reader(p.Stdout), io.Reader(p.Stderr)
+	if p.Limits.StdoutSize > 0 {
+		stdout = &limitReader{r: stdout, remaining: int64(p.Limits.StdoutSize), exceeded: p.outputExceeded(p.Cmd, "stdout")}
+	}
+	if p.Limits.StderrSize > 0 {
+		stderr = &limitReader{r: stderr, remaining: int64(p.Limits.StderrSize), exceeded: p.outputExceeded(p.Cmd, "stderr")}
+	}
+
 	wg.Add(1)
 	go func() {
-		p.ReadStdoutFn(p.Stdout)
+		p.ReadStdoutFn(stdout)
 		wg.Done()
 	}()
 
 	wg.Add(1)
 	go func() {
-		p.ReadStderrFn(p.Stderr)
+		p.ReadStderrFn(stderr)
 		wg.Done()
 	}()
 
@@ -205,6 +243,20 @@ func (p *Process) cmdWait(ctx context.Context) error {
 	return err
 }
 
+// outputExceeded returns a function killing the process once the output
+// limit of the given stream is exceeded.
+func (p *Process) outputExceeded(cmd *exec.Cmd, stream string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.Log.Errorf("Process %s exceeded the %s size limit, killing it", cmd.Path, stream)
+			if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				p.Log.Errorf("Error killing process: %v", err)
+			}
+		})
+	}
+}
+
 func isQuitting(ctx context.Context) bool {
 	return ctx.Err() != nil
 }
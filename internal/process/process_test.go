@@ -4,10 +4,13 @@ package process
 
 import (
 	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"testing"
@@ -63,14 +66,98 @@ var external = flag.Bool("external", false,
 func TestMain(m *testing.M) {
 	flag.Parse()
 	runMode := os.Getenv("INTERNAL_PROCESS_MODE")
-	if *external && runMode == "application" {
-		externalProcess()
-		os.Exit(0)
+	if *external {
+		switch runMode {
+		case "application":
+			externalProcess()
+			os.Exit(0)
+		case "exit":
+			fmt.Fprintln(os.Stdout, "exiting")
+			os.Exit(1) //nolint:revive // os.Exit called intentionally
+		case "flood":
+			floodProcess()
+			os.Exit(0)
+		}
 	}
 	code := m.Run()
 	os.Exit(code)
 }
 
+func TestRestartBudget(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := New([]string{exe, "-external"}, []string{"INTERNAL_PROCESS_MODE=exit"})
+	require.NoError(t, err)
+	p.RestartDelay = time.Millisecond
+	p.MaxRestarts = 2
+	p.RestartWindow = time.Minute
+	p.Log = testutil.Logger{}
+
+	var runs atomic.Int64
+	p.ReadStdoutFn = func(r io.Reader) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			runs.Add(1)
+		}
+	}
+
+	require.NoError(t, p.cmdStart())
+	require.ErrorContains(t, p.cmdLoop(t.Context()), "exceeded 2 restarts within 1m0s")
+	require.Equal(t, int64(3), runs.Load())
+}
+
+func TestOutputLimit(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := New([]string{exe, "-external"}, []string{"INTERNAL_PROCESS_MODE=flood"})
+	require.NoError(t, err)
+	p.StopOnError = true
+	p.Limits.StdoutSize = 1024
+	p.Log = testutil.Logger{}
+
+	var read atomic.Int64
+	p.ReadStdoutFn = func(r io.Reader) {
+		n, err := io.Copy(io.Discard, r)
+		if err == nil {
+			read.Store(n)
+		}
+	}
+
+	// The flooding process is killed once exceeding the limit
+	require.NoError(t, p.cmdStart())
+	require.ErrorContains(t, p.cmdLoop(t.Context()), "killed")
+	require.Equal(t, int64(1024), read.Load())
+}
+
+func TestRunOutputLimit(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(exe, "-external")
+	cmd.Env = append(os.Environ(), "INTERNAL_PROCESS_MODE=flood")
+	cmd.Stdout = &stdout
+	require.ErrorIs(t, Run(cmd, 5*time.Second, Limits{StdoutSize: 1024}), ErrOutputLimit)
+	require.Equal(t, 1024, stdout.Len())
+
+	stdout.Reset()
+	cmd = exec.Command(exe, "-external")
+	cmd.Env = append(os.Environ(), "INTERNAL_PROCESS_MODE=exit")
+	cmd.Stdout = &stdout
+	require.Error(t, Run(cmd, 5*time.Second, Limits{StdoutSize: 1024}))
+	require.Equal(t, "exiting\n", stdout.String())
+}
+
+// floodProcess is an external process writing output until killed
+func floodProcess() {
+	line := strings.Repeat("x", 127) + "\n"
+	for {
+		fmt.Fprint(os.Stdout, line)
+	}
+}
+
 // externalProcess is an external "misbehaving" process that won't exit
 // cleanly.
 func externalProcess() {
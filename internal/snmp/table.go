@@ -20,6 +20,10 @@ type Table struct {
 	// Adds each row's table index as a tag.
 	IndexAsTag bool
 
+	// SNMPv3 context to query the table in, e.g. a VRF. If empty, the
+	// context of the connection is used.
+	ContextName string `toml:"context_name"`
+
 	// Fields is the tags and values to look up.
 	Fields []Field `toml:"field"`
 
@@ -40,6 +40,11 @@ func (gs GosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
 	return gs.GoSNMP.BulkWalk(oid, fn)
 }
 
+// SetContextName sets the SNMPv3 context name used for subsequent requests.
+func (gs GosnmpWrapper) SetContextName(name string) {
+	gs.ContextName = name
+}
+
 func NewWrapper(s ClientConfig) (GosnmpWrapper, error) {
 	gs := GosnmpWrapper{&gosnmp.GoSNMP{}}
 
@@ -8,13 +8,26 @@ import (
 const (
 	// DefaultSeparator is the default separation character to use when separating template parts.
 	DefaultSeparator = "."
+
+	// attributePrefix marks the optional trailing template attributes to
+	// distinguish them from default tags
+	attributePrefix = "@"
 )
 
+// attributes are the keys of the optional trailing template attributes
+var attributes = map[string]bool{
+	// separator overrides the joiner of the engine for a single template
+	"separator": true,
+	// priority resolves overlapping filters
+	"priority": true,
+}
+
 // Engine uses a Matcher to retrieve the appropriate template and applies the template
 // to the input string
 type Engine struct {
-	joiner  string
-	matcher *matcher
+	joiner      string
+	fieldJoiner string
+	matcher     *matcher
 }
 
 // Apply extracts the template fields from the given line and returns the measurement
@@ -22,7 +35,43 @@ type Engine struct {
 //
 //nolint:revive //function-result-limit conditionally 4 return results allowed
 func (e *Engine) Apply(line string) (measurementName string, tags map[string]string, field string, err error) {
-	return e.matcher.match(line).Apply(line, e.joiner)
+	tmpl := e.matcher.match(line)
+
+	joiner := e.joiner
+	if tmpl.joiner != "" {
+		joiner = tmpl.joiner
+	}
+	fieldJoiner := e.fieldJoiner
+	if fieldJoiner == "" {
+		fieldJoiner = joiner
+	}
+	return tmpl.apply(line, joiner, fieldJoiner)
+}
+
+// Match returns true if one of the configured templates applies to the given
+// line instead of the default template passed to the engine
+func (e *Engine) Match(line string) bool {
+	return e.matcher.matches(line)
+}
+
+// MatchedTemplate returns the specification of the configured template
+// applying to the given line or an empty string if the default template
+// passed to the engine applies
+func (e *Engine) MatchedTemplate(line string) string {
+	return e.matcher.match(line).spec
+}
+
+// Composite returns true if the template applying to the given line has
+// alternatives in its filter, i.e. the fields of the metrics of sibling paths
+// should be merged into one metric
+func (e *Engine) Composite(line string) bool {
+	return e.matcher.isComposite(line)
+}
+
+// SetFieldJoiner sets the string used to join multiple field parts, e.g. of
+// 'field*', if different from the measurement joiner
+func (e *Engine) SetFieldJoiner(joiner string) {
+	e.fieldJoiner = joiner
 }
 
 // NewEngine creates a new templating engine
@@ -46,18 +95,21 @@ func parseTemplateSpecs(templates []string) templateSpecs {
 	tmplts := templateSpecs{}
 	for _, pattern := range templates {
 		tmplt := templateSpec{
+			spec:      strings.Join(strings.Fields(pattern), " "),
 			separator: DefaultSeparator,
 		}
 
-		// Format is [separator] [filter] <template> [tag1=value1,tag2=value2]
-		parts := strings.Fields(pattern)
+		// Format is [separator] [filter] <template> [tag1=value1,tag2=value2] [@attribute=value...]
+		parts, attrs := SplitAttributes(strings.Fields(pattern))
+		tmplt.joiner = attrs["separator"]
+		tmplt.priority = attrs["priority"]
 		partsLength := len(parts)
 		if partsLength < 1 {
 			// ignore
 			continue
 		}
 		if partsLength == 1 {
-			tmplt.template = pattern
+			tmplt.template = parts[0]
 		} else if partsLength == 4 {
 			tmplt.separator = parts[0]
 			tmplt.filter = parts[1]
@@ -86,3 +138,25 @@ func parseTemplateSpecs(templates []string) templateSpecs {
 	sort.Sort(tmplts)
 	return tmplts
 }
+
+// SplitAttributes removes the optional trailing attributes, e.g.
+// '@separator=_' or '@priority=10', from the given template fields and returns
+// the remaining fields together with the attributes keyed without prefix.
+func SplitAttributes(fields []string) ([]string, map[string]string) {
+	attrs := make(map[string]string)
+	for len(fields) > 1 {
+		attr, found := strings.CutPrefix(fields[len(fields)-1], attributePrefix)
+		if !found {
+			break
+		}
+		key, value, found := strings.Cut(attr, "=")
+		if !found || !attributes[key] || strings.Contains(value, ",") {
+			break
+		}
+		if _, exists := attrs[key]; !exists {
+			attrs[key] = value
+		}
+		fields = fields[:len(fields)-1]
+	}
+	return fields, attrs
+}
@@ -22,6 +22,77 @@ func TestEngineAlternateSeparator(t *testing.T) {
 	require.Empty(t, field)
 }
 
+func TestEngineTemplateJoiner(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+	engine, err := NewEngine(".", defaultTemplate, []string{
+		"cpu.* measurement.measurement.field.field @separator=_",
+	})
+	require.NoError(t, err)
+
+	name, _, field, err := engine.Apply("cpu.load.short.term")
+	require.NoError(t, err)
+	require.Equal(t, "cpu_load", name)
+	require.Equal(t, "short_term", field)
+
+	// The engine's joiner is used for templates without the attribute
+	name, _, _, err = engine.Apply("mem.cached.bytes")
+	require.NoError(t, err)
+	require.Equal(t, "mem.cached.bytes", name)
+}
+
+func TestEngineAttributeNamedTags(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+	engine, err := NewEngine(".", defaultTemplate, []string{
+		"cpu.* measurement.field priority=high",
+		"mem.* measurement.measurement.field separator=_",
+	})
+	require.NoError(t, err)
+
+	// Default tags named like attributes are kept as tags
+	name, tags, field, err := engine.Apply("cpu.load")
+	require.NoError(t, err)
+	require.Equal(t, "cpu", name)
+	require.Equal(t, "load", field)
+	require.Equal(t, map[string]string{"priority": "high"}, tags)
+
+	name, tags, _, err = engine.Apply("mem.cached.bytes")
+	require.NoError(t, err)
+	require.Equal(t, "mem.cached", name)
+	require.Equal(t, map[string]string{"separator": "_"}, tags)
+}
+
+func TestEngineTagTemplates(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+	engine, err := NewEngine(".", defaultTemplate, []string{
+		"servers.* .dc.host.measurement* region={{dc}}-zone,site={{dc}}/{{rack}},static=yes,literal={dc}",
+	})
+	require.NoError(t, err)
+
+	_, tags, _, err := engine.Apply("servers.eu1.web01.cpu.load")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"dc":      "eu1",
+		"host":    "web01",
+		"region":  "eu1-zone",
+		"static":  "yes",
+		"literal": "{dc}",
+	}, tags)
+}
+
+func TestEngineInvalidTagTemplates(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	_, err = NewEngine(".", defaultTemplate, []string{"measurement.dc region={{dc-zone"})
+	require.ErrorContains(t, err, `invalid value for tag "region": unbalanced braces in "{{dc-zone"`)
+
+	_, err = NewEngine(".", defaultTemplate, []string{"measurement.dc region={{}}-zone"})
+	require.ErrorContains(t, err, `invalid value for tag "region": empty tag reference in "{{}}-zone"`)
+}
+
 func TestEngineWithWildcardTemplate(t *testing.T) {
 	var (
 		defaultTmpl, err = NewDefaultTemplateWithPattern("measurement*")
@@ -75,3 +146,75 @@ func TestEngineWithWildcardTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestEngineTransforms(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+	engine, err := NewEngine(".", defaultTemplate, []string{
+		"servers.* measurement.host|lowercase.region|replace(-,_)|uppercase.field*|trimsuffix(_total)",
+		"app.* measurement.host|trimprefix(srv-).measurement*|uppercase",
+	})
+	require.NoError(t, err)
+
+	name, tags, field, err := engine.Apply("servers.WEB-01.eu-west-1.requests_total")
+	require.NoError(t, err)
+	require.Equal(t, "servers", name)
+	require.Equal(t, map[string]string{
+		"host":   "web-01",
+		"region": "EU_WEST_1",
+	}, tags)
+	require.Equal(t, "requests", field)
+
+	name, tags, field, err = engine.Apply("app.srv-db.cpu.load")
+	require.NoError(t, err)
+	require.Equal(t, "app.CPU.LOAD", name)
+	require.Equal(t, map[string]string{"host": "db"}, tags)
+	require.Empty(t, field)
+}
+
+func TestEngineInvalidTransforms(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{
+			template: "measurement.host|foo",
+			expected: `invalid transform for "host": unknown transform "foo"`,
+		},
+		{
+			template: "measurement.host|replace(-)",
+			expected: `"replace" requires two arguments`,
+		},
+		{
+			template: "measurement.host|lowercase(x)",
+			expected: `"lowercase" does not take arguments`,
+		},
+		{
+			template: "measurement.host|replace(-,_",
+			expected: "unbalanced parenthesis",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := NewEngine(".", defaultTemplate, []string{tt.template})
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestEngineMatchedTemplate(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+	engine, err := NewEngine(".", defaultTemplate, []string{
+		"servers.*  .host.measurement.field",
+		"servers.db.* .role.host.measurement.field @priority=10",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "servers.db.* .role.host.measurement.field @priority=10", engine.MatchedTemplate("servers.db.db01.cpu.load"))
+	require.Equal(t, "servers.* .host.measurement.field", engine.MatchedTemplate("servers.web01.cpu.load"))
+	require.Empty(t, engine.MatchedTemplate("cpu.load"))
+}
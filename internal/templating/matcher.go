@@ -1,6 +1,9 @@
 package templating
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -9,6 +12,25 @@ import (
 type matcher struct {
 	root            *node
 	defaultTemplate *Template
+	// customDefault is set if the default template was configured explicitly
+	// by a template without filter
+	customDefault bool
+	// prioritized contains the templates with a negated filter or an explicit
+	// priority sorted by descending priority. Those with a positive priority
+	// take precedence over the templates in the tree, the others are only
+	// used if no template in the tree matches.
+	prioritized []*prioritizedTemplate
+	// composite is set if any template merges sibling paths into one metric
+	composite bool
+}
+
+// prioritizedTemplate is a template matched by comparing its filter with the
+// line instead of using the filter tree
+type prioritizedTemplate struct {
+	filter   []string
+	negate   bool
+	priority int
+	template *Template
 }
 
 // newMatcher creates a new matcher.
@@ -33,26 +55,180 @@ func (m *matcher) addSpec(tmplt templateSpec) error {
 	if err != nil {
 		return err
 	}
-	m.add(tmplt.filter, tmpl)
+	tmpl.joiner = tmplt.joiner
+	tmpl.spec = tmplt.spec
+
+	var priority int
+	if tmplt.priority != "" {
+		priority, err = strconv.Atoi(tmplt.priority)
+		if err != nil {
+			return fmt.Errorf("invalid priority %q for template %q", tmplt.priority, tmplt.template)
+		}
+	}
+
+	// Negated filters and explicit priorities cannot be represented in the
+	// filter tree
+	filter, negate := strings.CutPrefix(tmplt.filter, "!")
+	if negate && filter == "" {
+		return fmt.Errorf("empty negated filter for template %q", tmplt.template)
+	}
+
+	// Filters with alternatives in braces, e.g. "app.*.{count,errors}", are
+	// added once per alternative and mark the template as composite
+	filters := []string{filter}
+	if strings.ContainsAny(filter, "{}") {
+		if negate {
+			return fmt.Errorf("alternatives not supported in negated filter of template %q", tmplt.template)
+		}
+		filters, err = expandAlternatives(filter)
+		if err != nil {
+			return fmt.Errorf("invalid filter for template %q: %w", tmplt.template, err)
+		}
+		if !tmpl.hasField() {
+			return fmt.Errorf("composite template %q requires a field", tmplt.template)
+		}
+		tmpl.composite = true
+		m.composite = true
+	}
+
+	for _, f := range filters {
+		if tmplt.filter == "" || (!negate && tmplt.priority == "") {
+			m.add(f, tmpl)
+			continue
+		}
+		m.addPrioritized(&prioritizedTemplate{
+			filter:   strings.Split(f, tmpl.separator),
+			negate:   negate,
+			priority: priority,
+			template: tmpl,
+		})
+	}
 	return nil
 }
 
+// expandAlternatives returns all combinations of the comma-separated
+// alternatives enclosed in braces in the given filter, e.g. "a.{b,c}" is
+// expanded to "a.b" and "a.c"
+func expandAlternatives(filter string) ([]string, error) {
+	start := strings.IndexByte(filter, '{')
+	if start < 0 {
+		if strings.ContainsRune(filter, '}') {
+			return nil, fmt.Errorf("unbalanced braces in %q", filter)
+		}
+		return []string{filter}, nil
+	}
+	end := strings.IndexByte(filter[start:], '}')
+	if end < 0 || strings.ContainsRune(filter[:start], '}') {
+		return nil, fmt.Errorf("unbalanced braces in %q", filter)
+	}
+	end += start
+	if strings.ContainsRune(filter[start+1:end], '{') {
+		return nil, fmt.Errorf("nested braces not supported in %q", filter)
+	}
+
+	suffixes, err := expandAlternatives(filter[end+1:])
+	if err != nil {
+		return nil, err
+	}
+	alternatives := strings.Split(filter[start+1:end], ",")
+	expanded := make([]string, 0, len(alternatives)*len(suffixes))
+	for _, alternative := range alternatives {
+		if alternative == "" {
+			return nil, fmt.Errorf("empty alternative in %q", filter)
+		}
+		for _, suffix := range suffixes {
+			expanded = append(expanded, filter[:start]+alternative+suffix)
+		}
+	}
+	return expanded, nil
+}
+
 // add inserts the template in the filter tree based the given filter
 func (m *matcher) add(filter string, template *Template) {
 	if filter == "" {
 		m.defaultTemplate = template
+		m.customDefault = true
 		m.root.separator = template.separator
 		return
 	}
 	m.root.insert(filter, template)
 }
 
+// addPrioritized adds the template keeping the templates sorted by
+// descending priority. Templates of equal priority are ordered by the number
+// of filter parts, so the more specific filter is matched first, and by
+// their order of addition otherwise.
+func (m *matcher) addPrioritized(pt *prioritizedTemplate) {
+	m.prioritized = append(m.prioritized, pt)
+	sort.SliceStable(m.prioritized, func(i, j int) bool {
+		a, b := m.prioritized[i], m.prioritized[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return len(a.filter) > len(b.filter)
+	})
+}
+
 // match returns the template that matches the given measurement line.
 // If no template matches, the default template is returned.
 func (m *matcher) match(line string) *Template {
-	tmpl := m.root.search(line)
-	if tmpl != nil {
+	if tmpl := m.search(line); tmpl != nil {
 		return tmpl
 	}
 	return m.defaultTemplate
 }
+
+// isComposite returns true if the template applying to the given line merges
+// sibling paths into one metric
+func (m *matcher) isComposite(line string) bool {
+	return m.composite && m.match(line).composite
+}
+
+// matches returns true if one of the configured templates matches the given
+// measurement line, i.e. the line is not handled by the built-in default.
+func (m *matcher) matches(line string) bool {
+	return m.customDefault || m.search(line) != nil
+}
+
+// search returns the template with a filter matching the given line or nil
+func (m *matcher) search(line string) *Template {
+	i := 0
+	for ; i < len(m.prioritized) && m.prioritized[i].priority > 0; i++ {
+		if m.prioritized[i].matches(line) {
+			return m.prioritized[i].template
+		}
+	}
+
+	if tmpl := m.root.search(line); tmpl != nil {
+		return tmpl
+	}
+
+	for _, pt := range m.prioritized[i:] {
+		if pt.matches(line) {
+			return pt.template
+		}
+	}
+	return nil
+}
+
+// matches returns true if the line matches the filter of the template or, for
+// negated filters, if it does not match the filter. Like in the filter tree,
+// the filter matches lines with at least as many parts as the filter where
+// each part equals the filter part or the filter part is a wildcard.
+func (pt *prioritizedTemplate) matches(line string) bool {
+	matched := true
+	rest, more := line, true
+	for _, p := range pt.filter {
+		if !more {
+			matched = false
+			break
+		}
+		var part string
+		part, rest, more = strings.Cut(rest, pt.template.separator)
+		if p != "*" && p != part {
+			matched = false
+			break
+		}
+	}
+	return matched != pt.negate
+}
@@ -0,0 +1,252 @@
+package templating
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	m := newMatcher(defaultTemplate)
+	// Identify the matched template by its default tag
+	templates := map[string]string{
+		"servers.localhost":     "exact",
+		"servers.*":             "wildcard",
+		"servers.*.cpu":         "cpu",
+		"*.localhost.mem":       "mem",
+		"stats.*.*.requests":    "requests",
+		"stats.eu.web.requests": "eu",
+	}
+	for filter, id := range templates {
+		spec := templateSpec{
+			filter:    filter,
+			template:  "measurement.host",
+			tagstring: "template=" + id,
+			separator: DefaultSeparator,
+		}
+		require.NoError(t, m.addSpec(spec))
+	}
+
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{line: "servers.localhost", expected: "exact"},
+		{line: "servers.localhost.load", expected: "exact"},
+		{line: "servers.remote", expected: "wildcard"},
+		{line: "servers.remote.cpu", expected: "cpu"},
+		{line: "servers.localhost.cpu", expected: "exact"},
+		{line: "servers.localhost.mem", expected: "exact"},
+		{line: "other.localhost.mem", expected: "mem"},
+		{line: "stats.eu.web.requests", expected: "eu"},
+		{line: "stats.us.web.requests", expected: "requests"},
+		{line: "stats.us.web", expected: ""},
+		{line: "servers", expected: ""},
+		{line: "", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			require.Equal(t, tt.expected, m.match(tt.line).defaultTags["template"])
+		})
+	}
+}
+
+func TestMatcherPriority(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	m := newMatcher(defaultTemplate)
+	// Identify the matched template by its default tag
+	specs := []struct {
+		filter   string
+		priority string
+		id       string
+	}{
+		{filter: "servers.*", id: "servers"},
+		{filter: "servers.*.cpu", id: "cpu"},
+		{filter: "servers.db.*", priority: "10", id: "db"},
+		{filter: "servers.*", priority: "5", id: "layered"},
+		{filter: "!servers.*", id: "other"},
+		{filter: "!stats.*", priority: "-1", id: "nostats"},
+		{filter: "stats.*.load", priority: "-1", id: "statsload"},
+	}
+	for _, s := range specs {
+		spec := templateSpec{
+			filter:    s.filter,
+			template:  "measurement.host",
+			tagstring: "template=" + s.id,
+			separator: DefaultSeparator,
+			priority:  s.priority,
+		}
+		require.NoError(t, m.addSpec(spec))
+	}
+
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{line: "servers.db.cpu", expected: "db"},
+		{line: "servers.web.cpu", expected: "layered"},
+		{line: "servers", expected: "other"},
+		{line: "cpu.load", expected: "other"},
+		{line: "stats.eu.load", expected: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			require.Equal(t, tt.expected, m.match(tt.line).defaultTags["template"])
+			require.True(t, m.matches(tt.line))
+		})
+	}
+}
+
+func TestMatcherNegatedFilter(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	m := newMatcher(defaultTemplate)
+	require.NoError(t, m.addSpec(templateSpec{
+		filter:    "servers.*",
+		template:  "measurement.host",
+		tagstring: "template=servers",
+		separator: DefaultSeparator,
+	}))
+	require.NoError(t, m.addSpec(templateSpec{
+		filter:    "!stats.*",
+		template:  "measurement.host",
+		tagstring: "template=nostats",
+		separator: DefaultSeparator,
+	}))
+
+	require.Equal(t, "servers", m.match("servers.localhost").defaultTags["template"])
+	require.Equal(t, "nostats", m.match("cpu.load").defaultTags["template"])
+	require.Equal(t, "nostats", m.match("stats").defaultTags["template"])
+	require.Same(t, defaultTemplate, m.match("stats.load"))
+	require.False(t, m.matches("stats.load"))
+}
+
+func TestMatcherInvalidAttributes(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	_, err = NewEngine(".", defaultTemplate, []string{"cpu.* measurement.host @priority=high"})
+	require.ErrorContains(t, err, `invalid priority "high" for template "measurement.host"`)
+
+	_, err = NewEngine(".", defaultTemplate, []string{"! measurement.host"})
+	require.ErrorContains(t, err, `empty negated filter for template "measurement.host"`)
+}
+
+func TestMatcherComposite(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	m := newMatcher(defaultTemplate)
+	require.NoError(t, m.addSpec(templateSpec{
+		filter:    "app.*.requests.{count,errors,p95}",
+		template:  "measurement.host.measurement.field",
+		tagstring: "template=composite",
+		separator: DefaultSeparator,
+	}))
+	require.NoError(t, m.addSpec(templateSpec{
+		filter:    "app.*",
+		template:  "measurement.host.measurement.field",
+		tagstring: "template=app",
+		separator: DefaultSeparator,
+	}))
+
+	for _, line := range []string{"app.web01.requests.count", "app.web01.requests.errors", "app.web01.requests.p95"} {
+		require.Equal(t, "composite", m.match(line).defaultTags["template"], line)
+		require.True(t, m.isComposite(line), line)
+	}
+	require.Equal(t, "app", m.match("app.web01.requests.p99").defaultTags["template"])
+	require.False(t, m.isComposite("app.web01.requests.p99"))
+	require.False(t, m.isComposite("other.requests.count"))
+}
+
+func TestExpandAlternatives(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{filter: "app.*.count", expected: []string{"app.*.count"}},
+		{filter: "app.{a,b}", expected: []string{"app.a", "app.b"}},
+		{filter: "app.p{50,95}", expected: []string{"app.p50", "app.p95"}},
+		{filter: "{a,b}.{x,y}", expected: []string{"a.x", "a.y", "b.x", "b.y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			actual, err := expandAlternatives(tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestMatcherInvalidComposite(t *testing.T) {
+	defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+	require.NoError(t, err)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{
+			template: "app.{a,b measurement.field",
+			expected: `unbalanced braces in "app.{a,b"`,
+		},
+		{
+			template: "app.a,b} measurement.field",
+			expected: `unbalanced braces in "app.a,b}"`,
+		},
+		{
+			template: "app.{a,{b,c}} measurement.field",
+			expected: `nested braces not supported in "app.{a,{b,c}}"`,
+		},
+		{
+			template: "app.{a,} measurement.field",
+			expected: `empty alternative in "app.{a,}"`,
+		},
+		{
+			template: "!app.{a,b} measurement.field",
+			expected: `alternatives not supported in negated filter of template "measurement.field"`,
+		},
+		{
+			template: "app.{a,b} measurement.measurement",
+			expected: `composite template "measurement.measurement" requires a field`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := NewEngine(".", defaultTemplate, []string{tt.template})
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("templates=%d", n), func(b *testing.B) {
+			defaultTemplate, err := NewDefaultTemplateWithPattern("measurement*")
+			require.NoError(b, err)
+
+			templates := make([]string, 0, 2*n)
+			for i := range n {
+				templates = append(templates,
+					fmt.Sprintf("servers.dc%d.*.cpu .dc.host.measurement*", i),
+					fmt.Sprintf("apps.app%d.* .app.measurement.field*", i),
+				)
+			}
+			engine, err := NewEngine(".", defaultTemplate, templates)
+			require.NoError(b, err)
+
+			line := fmt.Sprintf("servers.dc%d.host42.cpu.load", n/2)
+			b.ResetTimer()
+			for range b.N {
+				engine.matcher.match(line)
+			}
+		})
+	}
+}
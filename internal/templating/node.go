@@ -1,16 +1,17 @@
 package templating
 
 import (
-	"sort"
 	"strings"
 )
 
-// node is an item in a sorted k-ary tree of filter parts. Each child is sorted by its part value.
-// The special value of "*", is always sorted last.
+// node is an item in a prefix tree of filter parts. The children are indexed
+// by their part value, the special value of "*" is kept separately as it
+// matches any part. Matching a line is thereby independent of the number of
+// templates and only depends on the number of parts of the line.
 type node struct {
 	separator string
-	value     string
-	children  nodes
+	children  map[string]*node
+	wildcard  *node
 	template  *Template
 }
 
@@ -29,108 +30,56 @@ func (n *node) recursiveInsert(values []string, template *Template) {
 		return
 	}
 
-	// See if the current element already exists in the tree. If so, insert the
-	// into that sub-tree
-	for _, v := range n.children {
-		if v.value == values[0] {
-			v.recursiveInsert(values[1:], template)
-			return
+	// Insert into the existing sub-tree of the element or create a new one
+	var child *node
+	if values[0] == "*" {
+		if n.wildcard == nil {
+			n.wildcard = &node{}
+		}
+		child = n.wildcard
+	} else {
+		if n.children == nil {
+			n.children = make(map[string]*node)
+		}
+		child = n.children[values[0]]
+		if child == nil {
+			child = &node{}
+			n.children[values[0]] = child
 		}
 	}
-
-	// New element, add it to the tree and sort the children
-	newNode := &node{value: values[0]}
-	n.children = append(n.children, newNode)
-	sort.Sort(&n.children)
-
-	// Now insert the rest of the tree into the new element
-	newNode.recursiveInsert(values[1:], template)
+	child.recursiveInsert(values[1:], template)
 }
 
 // search searches for a template matching the input string
 func (n *node) search(line string) *Template {
-	separator := n.separator
-	return n.recursiveSearch(strings.Split(line, separator))
+	return n.recursiveSearch(line, n.separator, false)
 }
 
-// recursiveSearch performs the actual recursive search
-func (n *node) recursiveSearch(lineParts []string) *Template {
+// recursiveSearch performs the actual recursive search on the remaining line
+// which is split lazily to avoid allocations. Exact matches take precedence
+// over wildcards; if neither matches, the template at this node is returned.
+func (n *node) recursiveSearch(line, separator string, done bool) *Template {
 	// nothing to search
-	if len(lineParts) == 0 || len(n.children) == 0 {
+	if done || (len(n.children) == 0 && n.wildcard == nil) {
 		return n.template
 	}
 
-	var (
-		hasWildcard bool
-		length      = len(n.children)
-	)
-
-	// exclude last child from search if it is a wildcard. sort.Search expects
-	// a lexicographically sorted set of children and we have artificially sorted
-	// wildcards to the end of the child set
-	// wildcards will be searched separately if no exact match is found
-	if hasWildcard = n.children[length-1].value == "*"; hasWildcard {
-		length--
-	}
-
-	i := sort.Search(length, func(i int) bool {
-		return n.children[i].value >= lineParts[0]
-	})
+	part, rest, found := strings.Cut(line, separator)
 
 	// given an exact match is found within children set
-	if i < length && n.children[i].value == lineParts[0] {
+	if child, ok := n.children[part]; ok {
 		// descend into the matching node
-		if tmpl := n.children[i].recursiveSearch(lineParts[1:]); tmpl != nil {
+		if tmpl := child.recursiveSearch(rest, separator, !found); tmpl != nil {
 			// given a template is found return it
 			return tmpl
 		}
 	}
 
-	// given no template is found and the last child is a wildcard
-	if hasWildcard {
-		// also search the wildcard child node
-		return n.children[length].recursiveSearch(lineParts[1:])
+	// given no template is found, also search the wildcard child node
+	if n.wildcard != nil {
+		return n.wildcard.recursiveSearch(rest, separator, !found)
 	}
 
 	// fallback to returning template at this node
 	return n.template
 }
-
-// nodes is simply an array of nodes implementing the sorting interface.
-type nodes []*node
-
-// Less returns a boolean indicating whether the filter at position j
-// is less than the filter at position k. Filters are order by string
-// comparison of each component parts.  A wildcard value "*" is never
-// less than a non-wildcard value.
-//
-// For example, the filters:
-//
-//	"*.*"
-//	"servers.*"
-//	"servers.localhost"
-//	"*.localhost"
-//
-// Would be sorted as:
-//
-//	"servers.localhost"
-//	"servers.*"
-//	"*.localhost"
-//	"*.*"
-func (n *nodes) Less(j, k int) bool {
-	if (*n)[j].value == "*" && (*n)[k].value != "*" {
-		return false
-	}
-
-	if (*n)[j].value != "*" && (*n)[k].value == "*" {
-		return true
-	}
-
-	return (*n)[j].value < (*n)[k].value
-}
-
-// Swap swaps two elements of the array
-func (n *nodes) Swap(i, j int) { (*n)[i], (*n)[j] = (*n)[j], (*n)[i] }
-
-// Len returns the length of the array
-func (n *nodes) Len() int { return len(*n) }
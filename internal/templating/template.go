@@ -2,16 +2,34 @@ package templating
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+
+	"github.com/influxdata/telegraf/logger"
 )
 
+var log = logger.New("templating", "", "")
+
+// tagReferenceRe matches references to extracted tags in default tag values,
+// e.g. "{{dc}}" in "region={{dc}}-zone". Single braces are kept literally.
+var tagReferenceRe = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
 // Template represents a pattern and tags to map a metric string to an influxdb Point
 type Template struct {
 	separator         string
+	joiner            string
 	parts             []string
+	transforms        [][]transform
 	defaultTags       map[string]string
+	tagTemplates      map[string]string
 	greedyField       bool
 	greedyMeasurement bool
+	// spec is the template specification the template was created from,
+	// empty for templates not created by the engine
+	spec string
+	// composite is set for templates with alternatives in their filter whose
+	// metrics of sibling paths are merged into one metric
+	composite bool
 }
 
 // Apply extracts the template fields from the given line and returns the measurement
@@ -19,6 +37,11 @@ type Template struct {
 //
 //nolint:revive //function-result-limit conditionally 4 return results allowed
 func (t *Template) Apply(line string, joiner string) (measurementName string, tags map[string]string, field string, err error) {
+	return t.apply(line, joiner, joiner)
+}
+
+//nolint:revive //function-result-limit conditionally 4 return results allowed
+func (t *Template) apply(line, joiner, fieldJoiner string) (measurementName string, tags map[string]string, field string, err error) {
 	allFields := strings.Split(line, t.separator)
 	var (
 		measurements []string
@@ -31,21 +54,6 @@ func (t *Template) Apply(line string, joiner string) (measurementName string, ta
 		tagsMap[k] = append(tagsMap[k], v)
 	}
 
-	// See if an invalid combination has been specified in the template:
-	for _, tag := range t.parts {
-		if tag == "measurement*" {
-			t.greedyMeasurement = true
-		} else if tag == "field*" {
-			t.greedyField = true
-		}
-	}
-	if t.greedyField && t.greedyMeasurement {
-		return "", nil, "",
-			fmt.Errorf("either 'field*' or 'measurement*' can be used in each "+
-				"template (but not both together): %q",
-				strings.Join(t.parts, joiner))
-	}
-
 	for i, tag := range t.parts {
 		if i >= len(allFields) {
 			continue
@@ -54,17 +62,25 @@ func (t *Template) Apply(line string, joiner string) (measurementName string, ta
 			continue
 		}
 
+		values := allFields[i : i+1]
+		if tag == "field*" || tag == "measurement*" {
+			values = allFields[i:]
+		}
+		if t.transforms != nil && len(t.transforms[i]) > 0 {
+			transformed := make([]string, 0, len(values))
+			for _, v := range values {
+				transformed = append(transformed, applyTransforms(v, t.transforms[i]))
+			}
+			values = transformed
+		}
+
 		switch tag {
-		case "measurement":
-			measurements = append(measurements, allFields[i])
-		case "field":
-			fields = append(fields, allFields[i])
-		case "field*":
-			fields = append(fields, allFields[i:]...)
-		case "measurement*":
-			measurements = append(measurements, allFields[i:]...)
+		case "measurement", "measurement*":
+			measurements = append(measurements, values...)
+		case "field", "field*":
+			fields = append(fields, values...)
 		default:
-			tagsMap[tag] = append(tagsMap[tag], allFields[i])
+			tagsMap[tag] = append(tagsMap[tag], values...)
 		}
 	}
 
@@ -74,7 +90,31 @@ func (t *Template) Apply(line string, joiner string) (measurementName string, ta
 		tags[k] = strings.Join(values, joiner)
 	}
 
-	return strings.Join(measurements, joiner), tags, strings.Join(fields, joiner), nil
+	// Resolve the default tags referencing extracted tags, skipping those
+	// with references to tags not found in the line
+	if len(t.tagTemplates) > 0 {
+		resolved := make(map[string]string, len(t.tagTemplates))
+		for k, tmpl := range t.tagTemplates {
+			if v, ok := resolveTagTemplate(tmpl, tags); ok {
+				resolved[k] = v
+			}
+		}
+		for k, v := range resolved {
+			tags[k] = v
+		}
+	}
+
+	return strings.Join(measurements, joiner), tags, strings.Join(fields, fieldJoiner), nil
+}
+
+// hasField returns true if the template extracts a field name from the line
+func (t *Template) hasField() bool {
+	for _, part := range t.parts {
+		if part == "field" || part == "field*" {
+			return true
+		}
+	}
+	return false
 }
 
 func NewDefaultTemplateWithPattern(pattern string) (*Template, error) {
@@ -82,36 +122,127 @@ func NewDefaultTemplateWithPattern(pattern string) (*Template, error) {
 }
 
 // NewTemplate returns a new template ensuring it has a measurement specified.
+// Each part of the pattern can be followed by transforms separated by a pipe,
+// e.g. "host|lowercase|replace(-,_)", modifying the extracted value.
 func NewTemplate(separator, pattern string, defaultTags map[string]string) (*Template, error) {
-	parts := strings.Split(pattern, separator)
+	rawParts, err := splitOutsideParens(pattern, separator)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(rawParts))
+	transforms := make([][]transform, 0, len(rawParts))
+	var hasTransforms bool
+	for _, raw := range rawParts {
+		part, fns, err := parsePart(raw)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		transforms = append(transforms, fns)
+		hasTransforms = hasTransforms || len(fns) > 0
+	}
+
+	// Separate the default tags referencing extracted tags from the static ones
+	var staticTags, tagTemplates map[string]string
+	for k, v := range defaultTags {
+		if !strings.Contains(v, "{{") && !strings.Contains(v, "}}") {
+			if staticTags == nil {
+				staticTags = make(map[string]string, len(defaultTags))
+			}
+			staticTags[k] = v
+			continue
+		}
+		if err := validateTagTemplate(v); err != nil {
+			return nil, fmt.Errorf("invalid value for tag %q: %w", k, err)
+		}
+		if tagTemplates == nil {
+			tagTemplates = make(map[string]string, len(defaultTags))
+		}
+		tagTemplates[k] = v
+	}
+
 	hasMeasurement := false
 	template := &Template{
-		separator:   separator,
-		parts:       parts,
-		defaultTags: defaultTags,
+		separator:    separator,
+		parts:        parts,
+		defaultTags:  staticTags,
+		tagTemplates: tagTemplates,
+	}
+	if hasTransforms {
+		template.transforms = transforms
 	}
 
-	for _, part := range parts {
+	// Greedy parts consume all remaining elements so they should be the last
+	// part of the template
+	var misplacedMeasurement, misplacedField bool
+	for i, part := range parts {
 		if strings.HasPrefix(part, "measurement") {
 			hasMeasurement = true
 		}
-		if part == "measurement*" {
+		last := i == len(parts)-1
+		switch part {
+		case "measurement*":
 			template.greedyMeasurement = true
-		} else if part == "field*" {
+			misplacedMeasurement = misplacedMeasurement || !last
+		case "field*":
 			template.greedyField = true
+			misplacedField = misplacedField || !last
 		}
 	}
 
 	if !hasMeasurement {
 		return nil, fmt.Errorf("no measurement specified for template. %q", pattern)
 	}
+	if template.greedyField && template.greedyMeasurement {
+		return nil, fmt.Errorf("either 'field*' or 'measurement*' can be used in each "+
+			"template (but not both together): %q", pattern)
+	}
+	if misplacedField {
+		return nil, fmt.Errorf("\"field*\" must be the last part of the template: %q", pattern)
+	}
+	if misplacedMeasurement {
+		// Kept for compatibility with existing templates
+		log.Warnf("DEPRECATED: \"measurement*\" not being the last part of the template %q; "+
+			"this will be an error in a future release", pattern)
+	}
 
 	return template, nil
 }
 
+// validateTagTemplate checks that all double braces in the given tag value
+// form references to non-empty tag names
+func validateTagTemplate(value string) error {
+	for _, match := range tagReferenceRe.FindAllStringSubmatch(value, -1) {
+		if match[1] == "" {
+			return fmt.Errorf("empty tag reference in %q", value)
+		}
+	}
+	remainder := tagReferenceRe.ReplaceAllString(value, "")
+	if strings.Contains(remainder, "{{") || strings.Contains(remainder, "}}") {
+		return fmt.Errorf("unbalanced braces in %q", value)
+	}
+	return nil
+}
+
+// resolveTagTemplate replaces the tag references in the given value by the
+// values of the referenced tags. The boolean is false if any of the
+// referenced tags does not exist.
+func resolveTagTemplate(value string, tags map[string]string) (string, bool) {
+	ok := true
+	resolved := tagReferenceRe.ReplaceAllStringFunc(value, func(ref string) string {
+		v, found := tags[ref[2:len(ref)-2]]
+		ok = ok && found
+		return v
+	})
+	return resolved, ok
+}
+
 // templateSpec is a template string split in its constituent parts
 type templateSpec struct {
+	spec      string
 	separator string
+	joiner    string
+	priority  string
 	filter    string
 	template  string
 	tagstring string
@@ -0,0 +1,101 @@
+package templating
+
+import (
+	"fmt"
+	"strings"
+)
+
+// transform modifies the value extracted for a template part
+type transform func(string) string
+
+// parsePart splits a template part of the form "name|func1|func2(arg)" into
+// the part name and the transforms to apply to the extracted value
+func parsePart(part string) (string, []transform, error) {
+	items, err := splitOutsideParens(part, "|")
+	if err != nil {
+		return "", nil, err
+	}
+
+	transforms := make([]transform, 0, len(items)-1)
+	for _, item := range items[1:] {
+		fn, err := parseTransform(item)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid transform for %q: %w", items[0], err)
+		}
+		transforms = append(transforms, fn)
+	}
+	return items[0], transforms, nil
+}
+
+func parseTransform(spec string) (transform, error) {
+	name, args, hasArgs := strings.Cut(spec, "(")
+	if hasArgs {
+		if !strings.HasSuffix(args, ")") {
+			return nil, fmt.Errorf("missing closing parenthesis in %q", spec)
+		}
+		args = strings.TrimSuffix(args, ")")
+	}
+
+	switch name {
+	case "lowercase":
+		if hasArgs {
+			return nil, fmt.Errorf("%q does not take arguments", name)
+		}
+		return strings.ToLower, nil
+	case "uppercase":
+		if hasArgs {
+			return nil, fmt.Errorf("%q does not take arguments", name)
+		}
+		return strings.ToUpper, nil
+	case "replace":
+		oldValue, newValue, found := strings.Cut(args, ",")
+		if !found || oldValue == "" {
+			return nil, fmt.Errorf("%q requires two arguments with a non-empty first argument", name)
+		}
+		return func(s string) string { return strings.ReplaceAll(s, oldValue, newValue) }, nil
+	case "trimprefix":
+		if args == "" {
+			return nil, fmt.Errorf("%q requires an argument", name)
+		}
+		return func(s string) string { return strings.TrimPrefix(s, args) }, nil
+	case "trimsuffix":
+		if args == "" {
+			return nil, fmt.Errorf("%q requires an argument", name)
+		}
+		return func(s string) string { return strings.TrimSuffix(s, args) }, nil
+	}
+	return nil, fmt.Errorf("unknown transform %q", name)
+}
+
+// splitOutsideParens splits the string at the separator but keeps the
+// arguments of transforms, enclosed in parentheses, intact
+func splitOutsideParens(s, sep string) ([]string, error) {
+	var parts []string
+	var depth, start int
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("unbalanced parenthesis in %q", s)
+			}
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parenthesis in %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+func applyTransforms(value string, transforms []transform) string {
+	for _, fn := range transforms {
+		value = fn(value)
+	}
+	return value
+}
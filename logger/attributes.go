@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// attributesFor returns the attributes of the logger extended by the
+// attributes derived from the given message arguments. Errors add their
+// class and, if known, the measurement they relate to while metrics add
+// their measurement name.
+func (l *logger) attributesFor(args []interface{}) map[string]interface{} {
+	var class, measurement string
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case error:
+			if class == "" {
+				class = errorClass(v)
+			}
+			var merr *internal.MetricError
+			if measurement == "" && errors.As(v, &merr) {
+				measurement = merr.Measurement
+			}
+		case telegraf.Metric:
+			if measurement == "" {
+				measurement = v.Name()
+			}
+		}
+	}
+	if class == "" && measurement == "" {
+		return l.attributes
+	}
+
+	attributes := maps.Clone(l.attributes)
+	if class != "" {
+		attributes["error_class"] = class
+	}
+	if measurement != "" {
+		attributes["measurement"] = measurement
+	}
+	return attributes
+}
+
+// errorClass classifies the given error into a coarse category allowing to
+// filter log messages without relying on the error text
+func errorClass(err error) string {
+	var fatalErr *internal.FatalError
+	var startupErr *internal.StartupError
+	var partialErr *internal.PartialWriteError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &fatalErr):
+		return "fatal"
+	case errors.As(err, &startupErr):
+		return "startup"
+	case errors.As(err, &partialErr):
+		return "partial_write"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, os.ErrPermission):
+		return "permission"
+	case errors.Is(err, os.ErrNotExist):
+		return "not_found"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, internal.ErrNotConnected):
+		return "not_connected"
+	case errors.Is(err, internal.ErrSerialization):
+		return "serialization"
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "other"
+}
@@ -82,7 +82,7 @@ func (l *logger) Level() telegraf.LogLevel {
 func (l *logger) AddAttribute(key string, value interface{}) {
 	// Do not allow to overwrite general keys
 	switch key {
-	case "category", "plugin", "alias":
+	case "category", "plugin", "alias", "error_class":
 	default:
 		l.attributes[key] = value
 	}
@@ -90,11 +90,16 @@ func (l *logger) AddAttribute(key string, value interface{}) {
 
 // Error logging including callbacks
 func (l *logger) Errorf(format string, args ...interface{}) {
-	l.Error(fmt.Sprintf(format, args...))
+	l.print(telegraf.Error, time.Now(), l.attributesFor(args), fmt.Sprintf(format, args...))
+	l.errorCallbacks()
 }
 
 func (l *logger) Error(args ...interface{}) {
 	l.Print(telegraf.Error, time.Now(), args...)
+	l.errorCallbacks()
+}
+
+func (l *logger) errorCallbacks() {
 	for _, f := range l.onError {
 		f()
 	}
@@ -102,7 +107,7 @@ func (l *logger) Error(args ...interface{}) {
 
 // Warning logging
 func (l *logger) Warnf(format string, args ...interface{}) {
-	l.Warn(fmt.Sprintf(format, args...))
+	l.print(telegraf.Warn, time.Now(), l.attributesFor(args), fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Warn(args ...interface{}) {
@@ -111,7 +116,7 @@ func (l *logger) Warn(args ...interface{}) {
 
 // Info logging
 func (l *logger) Infof(format string, args ...interface{}) {
-	l.Info(fmt.Sprintf(format, args...))
+	l.print(telegraf.Info, time.Now(), l.attributesFor(args), fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Info(args ...interface{}) {
@@ -120,7 +125,7 @@ func (l *logger) Info(args ...interface{}) {
 
 // Debug logging, this is suppressed on console
 func (l *logger) Debugf(format string, args ...interface{}) {
-	l.Debug(fmt.Sprintf(format, args...))
+	l.print(telegraf.Debug, time.Now(), l.attributesFor(args), fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Debug(args ...interface{}) {
@@ -129,7 +134,7 @@ func (l *logger) Debug(args ...interface{}) {
 
 // Trace logging, this is suppressed on console
 func (l *logger) Tracef(format string, args ...interface{}) {
-	l.Trace(fmt.Sprintf(format, args...))
+	l.print(telegraf.Trace, time.Now(), l.attributesFor(args), fmt.Sprintf(format, args...))
 }
 
 func (l *logger) Trace(args ...interface{}) {
@@ -137,9 +142,13 @@ func (l *logger) Trace(args ...interface{}) {
 }
 
 func (l *logger) Print(level telegraf.LogLevel, ts time.Time, args ...interface{}) {
+	l.print(level, ts, l.attributesFor(args), args...)
+}
+
+func (l *logger) print(level telegraf.LogLevel, ts time.Time, attributes map[string]interface{}, args ...interface{}) {
 	// Check if we are in early logging state and store the message in this case
 	if instance.impl == nil {
-		instance.add(level, ts, l.prefix, l.attributes, args...)
+		instance.add(level, ts, l.prefix, attributes, args...)
 	}
 
 	// Skip all messages with insufficient log-levels
@@ -147,7 +156,7 @@ func (l *logger) Print(level telegraf.LogLevel, ts time.Time, args ...interface{
 		return
 	}
 	if instance.impl != nil {
-		instance.impl.Print(level, ts.In(instance.timezone), l.prefix, l.attributes, args...)
+		instance.impl.Print(level, ts.In(instance.timezone), l.prefix, attributes, args...)
 	} else {
 		msg := append([]interface{}{ts.In(instance.timezone).Format(time.RFC3339), " ", level.Indicator(), " ", l.prefix}, args...)
 		instance.earlysink.Print(msg...)
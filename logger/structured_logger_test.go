@@ -1,18 +1,25 @@
 package logger
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 )
 
 func TestStructuredStderr(t *testing.T) {
@@ -262,6 +269,96 @@ func TestStructuredDerivedLoggerWithAttributes(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestStructuredErrorAttributes(t *testing.T) {
+	instance = defaultHandler()
+
+	tmpfile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	filename := tmpfile.Name()
+	require.NoError(t, tmpfile.Close())
+
+	cfg := &Config{
+		Logfile:             filename,
+		LogFormat:           "structured",
+		RotationMaxArchives: -1,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	l := New("inputs", "snmp", "")
+	l.AddAttribute("error_class", "foo") // Should be ignored
+
+	serr := &internal.MetricError{
+		Measurement: "interface",
+		Err:         fmt.Errorf("walking table failed: %w", context.DeadlineExceeded),
+	}
+	l.Errorf("Gathering failed: %v", serr)
+	l.Warn("Reading file failed: ", os.ErrNotExist)
+	l.Info("no error")
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{
+			"level":       "ERROR",
+			"msg":         "Gathering failed: walking table failed: context deadline exceeded",
+			"category":    "inputs",
+			"plugin":      "snmp",
+			"error_class": "timeout",
+			"measurement": "interface",
+		},
+		{
+			"level":       "WARN",
+			"msg":         "Reading file failed: file does not exist",
+			"category":    "inputs",
+			"plugin":      "snmp",
+			"error_class": "not_found",
+		},
+		{
+			"level":    "INFO",
+			"msg":      "no error",
+			"category": "inputs",
+			"plugin":   "snmp",
+		},
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf), []byte("\n"))
+	require.Len(t, lines, len(expected))
+	for i, line := range lines {
+		var actual map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &actual))
+		require.NotEmpty(t, actual["time"])
+		delete(actual, "time")
+		require.Equal(t, expected[i], actual)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: &internal.FatalError{Err: os.ErrPermission}, expected: "fatal"},
+		{err: &internal.StartupError{Err: internal.ErrNotConnected}, expected: "startup"},
+		{err: fmt.Errorf("wrapped: %w", context.Canceled), expected: "canceled"},
+		{err: os.ErrDeadlineExceeded, expected: "timeout"},
+		{err: &os.PathError{Op: "open", Path: "/etc/shadow", Err: syscall.EACCES}, expected: "permission"},
+		{err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, expected: "connection_refused"},
+		{err: internal.ErrNotConnected, expected: "not_connected"},
+		{err: &net.DNSError{Err: "no such host", Name: "foo.invalid"}, expected: "network"},
+		{err: errors.New("something"), expected: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			require.Equal(t, tt.expected, errorClass(tt.err))
+		})
+	}
+}
+
 func TestStructuredWriteToTruncatedFile(t *testing.T) {
 	tmpfile, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
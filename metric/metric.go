@@ -6,6 +6,8 @@ import (
 	"sort"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/influxdata/telegraf"
 )
 
@@ -16,6 +18,9 @@ type metric struct {
 	MetricTime   time.Time
 
 	MetricType telegraf.ValueType
+
+	// Span context of the pipeline stage the metric passed last
+	spanContext trace.SpanContext
 }
 
 func New(
@@ -73,6 +78,7 @@ func FromMetric(other telegraf.Metric) telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(other.FieldList())),
 		MetricTime:   other.Time(),
 		MetricType:   other.Type(),
+		spanContext:  SpanContext(other),
 	}
 
 	for i, tag := range other.TagList() {
@@ -253,6 +259,7 @@ func (m *metric) Copy() telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(m.MetricFields)),
 		MetricTime:   m.MetricTime,
 		MetricType:   m.MetricType,
+		spanContext:  m.spanContext,
 	}
 
 	for i, tag := range m.MetricTags {
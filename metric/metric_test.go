@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/influxdata/telegraf"
 )
@@ -326,3 +327,19 @@ func TestValueType(t *testing.T) {
 
 	require.Equal(t, telegraf.Gauge, m.Type())
 }
+
+func TestSpanContext(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x02},
+	})
+
+	m := New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.False(t, SpanContext(m).IsValid())
+
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	SetSpanContext(tm, sc)
+	require.Equal(t, sc, SpanContext(m))
+	require.Equal(t, sc, SpanContext(m.Copy()))
+	require.Equal(t, sc, SpanContext(FromMetric(tm)))
+}
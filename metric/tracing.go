@@ -0,0 +1,31 @@
+package metric
+
+import (
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/influxdata/telegraf"
+)
+
+// SpanContext returns the trace span context of the pipeline stage the metric
+// passed last or an invalid span context if the metric is not traced.
+func SpanContext(m telegraf.Metric) trace.SpanContext {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	if mm, ok := m.(*metric); ok {
+		return mm.spanContext
+	}
+	return trace.SpanContext{}
+}
+
+// SetSpanContext attaches the trace span context of a pipeline stage to the
+// metric to allow following the metric through the pipeline. The span context
+// is kept when copying the metric.
+func SetSpanContext(m telegraf.Metric, sc trace.SpanContext) {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	if mm, ok := m.(*metric); ok {
+		mm.spanContext = sc
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
@@ -92,10 +93,38 @@ type BufferStats struct {
 	MetricsDropped  selfstat.Stat
 	BufferSize      selfstat.Stat
 	BufferLimit     selfstat.Stat
+
+	// Dropped metrics by reason, either due to a full buffer or due to
+	// exceeding the maximum age
+	MetricsDroppedOverflow selfstat.Stat
+	MetricsDroppedExpired  selfstat.Stat
+
+	// Size of the buffer in bytes, only registered by disk buffers
+	BufferBytes selfstat.Stat
+}
+
+// DiskBufferConfig contains the settings of disk-backed buffers.
+type DiskBufferConfig struct {
+	// Directory to store the buffer files in, each output uses a
+	// subdirectory named by the output's ID.
+	Directory string
+
+	// SegmentSize is the target size of the individual buffer files in
+	// bytes, zero uses the default of the WAL implementation.
+	SegmentSize int64
+
+	// MaxSize limits the size of all buffer files in bytes, new metrics are
+	// dropped if the limit is reached. Zero means unlimited.
+	MaxSize int64
+
+	// MaxAge is the maximum age of buffered metrics according to their
+	// timestamp, older metrics are dropped when reading a batch. Zero means
+	// unlimited.
+	MaxAge time.Duration
 }
 
 // NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+func NewBuffer(name, id, alias string, capacity int, strategy string, disk DiskBufferConfig) (Buffer, error) {
 	registerGob()
 
 	bs := NewBufferStats(name, alias, capacity)
@@ -104,7 +133,7 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 	case "", "memory":
 		return NewMemoryBuffer(capacity, bs)
 	case "disk":
-		return NewDiskBuffer(name, id, path, bs)
+		return NewDiskBuffer(name, id, disk, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
@@ -146,6 +175,16 @@ func NewBufferStats(name, alias string, capacity int) BufferStats {
 			"buffer_limit",
 			tags,
 		),
+		MetricsDroppedOverflow: selfstat.Register(
+			"write",
+			"metrics_dropped_overflow",
+			tags,
+		),
+		MetricsDroppedExpired: selfstat.Register(
+			"write",
+			"metrics_dropped_expired",
+			tags,
+		),
 	}
 	bs.BufferSize.Set(int64(0))
 	bs.BufferLimit.Set(int64(capacity))
@@ -173,3 +212,13 @@ func (b *BufferStats) metricDropped(m telegraf.Metric) {
 	b.MetricsDropped.Incr(1)
 	m.Reject()
 }
+
+func (b *BufferStats) metricOverflowed(m telegraf.Metric) {
+	b.MetricsDroppedOverflow.Incr(1)
+	b.metricDropped(m)
+}
+
+func (b *BufferStats) metricExpired(m telegraf.Metric) {
+	b.MetricsDroppedExpired.Incr(1)
+	b.metricDropped(m)
+}
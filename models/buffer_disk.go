@@ -1,18 +1,22 @@
 package models
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/tidwall/wal"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 type DiskBuffer struct {
@@ -22,6 +26,10 @@ type DiskBuffer struct {
 	file *wal.Log
 	path string
 
+	maxSize int64         // Maximum size of the WAL files in bytes
+	maxAge  time.Duration // Maximum age of the metrics in the buffer
+	size    int64         // Current size of the WAL files in bytes
+
 	batchFirst uint64 // Index of the first metric in the batch
 	batchSize  uint64 // Number of metrics currently in the batch
 
@@ -40,9 +48,22 @@ type DiskBuffer struct {
 	mask []int
 }
 
-func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error) {
-	filePath := filepath.Join(path, id)
-	walFile, err := wal.Open(filePath, nil)
+func NewDiskBuffer(name, id string, cfg DiskBufferConfig, stats BufferStats) (*DiskBuffer, error) {
+	filePath := filepath.Join(cfg.Directory, id)
+	opts := &wal.Options{SegmentSize: int(cfg.SegmentSize)}
+	walFile, err := wal.Open(filePath, opts)
+	if errors.Is(err, wal.ErrCorrupt) {
+		// A crash while writing might leave an incomplete entry at the end of
+		// the WAL file, so try to recover by removing it.
+		removed, rerr := repairWAL(filePath)
+		if rerr != nil {
+			return nil, fmt.Errorf("failed to repair wal file: %w", rerr)
+		}
+		if removed > 0 {
+			log.Printf("W! Removed %d bytes of incomplete data from WAL file of plugin outputs.%s (%s)", removed, name, id)
+			walFile, err = wal.Open(filePath, opts)
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open wal file: %w", err)
 	}
@@ -53,17 +74,85 @@ func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error
 			"this can safely be ignored if you added this plugin instance for the first time", name, id)
 	}
 
+	stats.BufferBytes = selfstat.Register("write", "buffer_bytes", stats.BufferSize.Tags())
 	buf := &DiskBuffer{
 		BufferStats: stats,
 		file:        walFile,
 		path:        filePath,
+		maxSize:     cfg.MaxSize,
+		maxAge:      cfg.MaxAge,
 	}
 	if buf.length() > 0 {
 		buf.originalEnd = buf.writeIndex()
 	}
+	buf.size = buf.diskUsage()
+	buf.BufferBytes.Set(buf.size)
 	return buf, nil
 }
 
+// repairWAL truncates incomplete entries at the end of the last segment file
+// of the WAL in the given directory and returns the number of bytes removed.
+func repairWAL(path string) (int64, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// Segment files are named by their zero-padded first index, so the last
+	// file in the sorted list is the one written to
+	var last string
+	for _, f := range files {
+		if !f.IsDir() && len(f.Name()) == 20 {
+			last = filepath.Join(path, f.Name())
+		}
+	}
+	if last == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(last)
+	if err != nil {
+		return 0, err
+	}
+
+	// Each entry is prefixed by its length as unsigned varint
+	var pos int
+	for pos < len(data) {
+		size, n := binary.Uvarint(data[pos:])
+		if n <= 0 || uint64(len(data)-pos-n) < size {
+			break
+		}
+		pos += n + int(size)
+	}
+	if pos == len(data) {
+		return 0, nil
+	}
+
+	if err := os.Truncate(last, int64(pos)); err != nil {
+		return 0, err
+	}
+	return int64(len(data) - pos), nil
+}
+
+// diskUsage returns the size of all WAL files in bytes.
+func (b *DiskBuffer) diskUsage() int64 {
+	files, err := os.ReadDir(b.path)
+	if err != nil {
+		return 0
+	}
+
+	var size int64
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if info, err := f.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
 func (b *DiskBuffer) Len() int {
 	b.Lock()
 	defer b.Unlock()
@@ -124,8 +213,18 @@ func (b *DiskBuffer) addSingleMetric(m telegraf.Metric) bool {
 	if err != nil {
 		panic(err)
 	}
+
+	// Account for the length prefix of the entry in the file
+	size := int64(len(data) + binary.PutUvarint(make([]byte, binary.MaxVarintLen64), uint64(len(data))))
+	if b.maxSize > 0 && b.size+size > b.maxSize {
+		b.metricOverflowed(m)
+		return false
+	}
+
 	err = b.file.Write(b.writeIndex(), data)
 	if err == nil {
+		b.size += size
+		b.BufferBytes.Set(b.size)
 		b.metricAdded()
 		return true
 	}
@@ -142,8 +241,14 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 	b.batchFirst = b.readIndex()
 	b.batchSize = 0
 
+	var expiry time.Time
+	if b.maxAge > 0 {
+		expiry = time.Now().Add(-b.maxAge)
+	}
+
 	metrics := make([]telegraf.Metric, 0, batchSize)
 	offsets := make([]int, 0, batchSize)
+	var expired bool
 	readIndex := b.batchFirst
 	endIndex := b.writeIndex()
 	for offset := 0; batchSize > 0 && readIndex < endIndex; offset++ {
@@ -181,11 +286,31 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 			continue
 		}
 
+		// Drop metrics exceeding the maximum age, they are removed from the
+		// file together with the written metrics.
+		if !expiry.IsZero() && m.Time().Before(expiry) {
+			b.metricExpired(m)
+			b.mask = append(b.mask, offset)
+			expired = true
+			continue
+		}
+
 		metrics = append(metrics, m)
 		offsets = append(offsets, offset)
 		b.batchSize++
 		batchSize--
 	}
+
+	if expired {
+		sort.Ints(b.mask)
+		// Without a batch there is no transaction to end, so remove the
+		// expired metrics right away.
+		if len(metrics) == 0 {
+			b.removeMasked(0)
+		}
+		b.BufferSize.Set(int64(b.length()))
+	}
+
 	return &Transaction{Batch: metrics, valid: true, state: offsets}
 }
 
@@ -219,8 +344,12 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 	b.mask = append(b.mask, remove...)
 	sort.Ints(b.mask)
 
-	// Remove the metrics that are marked for removal from the front of the
-	// WAL file. All other metrics must be kept.
+	b.removeMasked(len(tx.Batch))
+}
+
+// removeMasked removes the metrics marked for removal from the front of the
+// WAL file. All other metrics must be kept.
+func (b *DiskBuffer) removeMasked(batchLen int) {
 	if len(b.mask) == 0 || b.mask[0] != 0 {
 		// Mask is empty or the first index is not the front of the file, so
 		// exit early as there is nothing to remove
@@ -249,9 +378,11 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 		removeIdx--
 	}
 	if err := b.file.TruncateFront(b.batchFirst + uint64(removeIdx)); err != nil {
-		log.Printf("E! batch length: %d, first: %d, size: %d", len(tx.Batch), b.batchFirst, b.batchSize)
+		log.Printf("E! batch length: %d, first: %d, size: %d", batchLen, b.batchFirst, b.batchSize)
 		panic(err)
 	}
+	b.size = b.diskUsage()
+	b.BufferBytes.Set(b.size)
 
 	// Truncate the mask and update the relative offsets
 	b.mask = b.mask[removeIdx:]
@@ -294,4 +425,13 @@ func (b *DiskBuffer) handleEmptyFile() {
 		panic(err)
 	}
 	b.isEmpty = false
+
+	// The removed entry is masked, so drop it from the mask as well to not
+	// hide the newly added metric
+	if len(b.mask) > 0 && b.mask[0] == 0 {
+		b.mask = b.mask[1:]
+		for i := range b.mask {
+			b.mask[i]--
+		}
+	}
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -19,7 +20,7 @@ func TestDiskBufferRetainsTrackingInformation(t *testing.T) {
 	var delivered int
 	mm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
 
-	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir()})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -78,7 +79,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	walfile.Close()
 
 	// Create a buffer
-	buf, err := NewBuffer("123", "123", "", 0, "disk", path)
+	buf, err := NewBuffer("123", "123", "", 0, "disk", DiskBufferConfig{Directory: path})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -98,7 +99,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 // https://github.com/influxdata/telegraf/issues/16696
 func TestDiskBufferTruncate(t *testing.T) {
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir()})
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
@@ -152,3 +153,103 @@ func TestDiskBufferTruncate(t *testing.T) {
 	tx = buf.BeginTransaction(4)
 	require.Empty(t, tx.Batch)
 }
+
+func TestDiskBufferMaxSize(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir(), MaxSize: 1024})
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+	buf.Stats().MetricsDroppedOverflow.Set(0)
+	defer buf.Close()
+	diskBuf, ok := buf.(*DiskBuffer)
+	require.True(t, ok, "buffer is not a disk buffer")
+
+	// Fill the buffer beyond its size limit
+	var dropped int
+	for i := range 100 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Now())
+		dropped += buf.Add(m)
+	}
+	require.Positive(t, dropped)
+	require.Equal(t, 100-dropped, buf.Len())
+	require.Equal(t, int64(dropped), buf.Stats().MetricsDropped.Get())
+	require.Equal(t, int64(dropped), buf.Stats().MetricsDroppedOverflow.Get())
+	require.LessOrEqual(t, diskBuf.diskUsage(), int64(1024))
+	require.Equal(t, diskBuf.size, buf.Stats().BufferBytes.Get())
+
+	// Writing metrics frees up space for new metrics
+	tx := buf.BeginTransaction(buf.Len())
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.Zero(t, buf.Add(m))
+	require.Equal(t, 1, buf.Len())
+}
+
+func TestDiskBufferMaxAge(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir(), MaxAge: time.Hour})
+	require.NoError(t, err)
+	buf.Stats().MetricsDropped.Set(0)
+	buf.Stats().MetricsDroppedExpired.Set(0)
+	defer buf.Close()
+
+	now := time.Now()
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-2*time.Hour)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 3}, now.Add(-3*time.Hour)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 4}, now),
+	}
+	buf.Add(metrics...)
+
+	// Expired metrics are dropped when reading the batch
+	tx := buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{metrics[1], metrics[3]}, tx.Batch)
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+	require.Equal(t, int64(2), buf.Stats().MetricsDroppedExpired.Get())
+	require.Equal(t, 2, buf.Len())
+
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Zero(t, buf.Len())
+
+	// Batches containing only expired metrics remove them immediately
+	buf.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 5}, now.Add(-2*time.Hour)))
+	tx = buf.BeginTransaction(4)
+	require.Empty(t, tx.Batch)
+	require.Zero(t, buf.Len())
+}
+
+func TestDiskBufferRecoverIncompleteWrite(t *testing.T) {
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: path})
+	require.NoError(t, err)
+
+	expected := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		buf.Add(m)
+		expected = append(expected, m)
+	}
+	require.NoError(t, buf.Close())
+
+	// Simulate a crash while writing by appending an incomplete entry
+	segments, err := filepath.Glob(filepath.Join(path, "123", "*"))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	f, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Reopening the buffer must recover all complete entries
+	buf, err = NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: path})
+	require.NoError(t, err)
+	defer buf.Close()
+	require.Equal(t, 3, buf.Len())
+
+	tx := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+}
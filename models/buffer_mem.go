@@ -111,7 +111,7 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 
 		// Drop all remaining metrics
 		for i := restore; i < len(keep); i++ {
-			b.metricDropped(tx.Batch[keep[i]])
+			b.metricOverflowed(tx.Batch[keep[i]])
 		}
 	}
 
@@ -135,7 +135,7 @@ func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
 	dropped := 0
 	// Check if Buffer is full
 	if b.size == b.cap {
-		b.metricDropped(b.buf[b.last])
+		b.metricOverflowed(b.buf[b.last])
 		dropped++
 
 		if b.batchSize > 0 {
@@ -14,6 +14,27 @@ func logName(pluginType, name, alias string) string {
 	return pluginType + "." + name + "::" + alias
 }
 
+// SetStateStoreOnPlugin sets the state store on the 'State' field of the
+// plugin and returns true if the plugin defines such a field.
+func SetStateStoreOnPlugin(i interface{}, store func() (telegraf.StateStore, error)) (bool, error) {
+	valI := reflect.ValueOf(i)
+	if valI.Type().Kind() != reflect.Ptr || valI.Elem().Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	field := valI.Elem().FieldByName("State")
+	if !field.IsValid() || !field.CanSet() || field.Type() != reflect.TypeOf((*telegraf.StateStore)(nil)).Elem() {
+		return false, nil
+	}
+
+	s, err := store()
+	if err != nil {
+		return true, err
+	}
+	field.Set(reflect.ValueOf(s))
+	return true, nil
+}
+
 func SetLoggerOnPlugin(i interface{}, logger telegraf.Logger) {
 	valI := reflect.ValueOf(i)
 
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Route selects the metrics delivered to an output. Each expression consists
+// of whitespace separated terms which must all match the metric, the route
+// matches if any of the expressions matches. Terms are glob patterns matching
+//   - the value of the routing tag, e.g. "errors"
+//   - the measurement name if prefixed by "name:", e.g. "name:syslog"
+//   - the value of a tag if prefixed by "tag:<key>=", e.g. "tag:level=err*"
+//
+// The routing tag is removed from all metrics delivered to the output.
+type Route struct {
+	Tag         string
+	Expressions []string
+
+	expressions [][]routeTerm
+}
+
+type routeTerm struct {
+	kind   string
+	key    string
+	filter filter.Filter
+}
+
+// Compile parses the route expressions
+func (r *Route) Compile() error {
+	r.expressions = make([][]routeTerm, 0, len(r.Expressions))
+	for _, expr := range r.Expressions {
+		fields := strings.Fields(expr)
+		if len(fields) == 0 {
+			return errors.New("empty route expression")
+		}
+
+		terms := make([]routeTerm, 0, len(fields))
+		for _, field := range fields {
+			term, err := r.compileTerm(field)
+			if err != nil {
+				return fmt.Errorf("invalid route expression %q: %w", expr, err)
+			}
+			terms = append(terms, term)
+		}
+		r.expressions = append(r.expressions, terms)
+	}
+
+	return nil
+}
+
+func (r *Route) compileTerm(term string) (routeTerm, error) {
+	var t routeTerm
+	pattern := term
+	if name, found := strings.CutPrefix(term, "name:"); found {
+		t.kind, pattern = "name", name
+	} else if tag, found := strings.CutPrefix(term, "tag:"); found {
+		key, value, found := strings.Cut(tag, "=")
+		if !found || key == "" {
+			return t, fmt.Errorf("tag term %q requires the form 'tag:<key>=<value>'", term)
+		}
+		t.kind, t.key, pattern = "tag", key, value
+	} else {
+		if r.Tag == "" {
+			return t, fmt.Errorf("term %q requires the agent's 'routing_tag' setting", term)
+		}
+		t.kind, t.key = "tag", r.Tag
+	}
+
+	f, err := filter.Compile([]string{pattern})
+	if err != nil {
+		return t, err
+	}
+	t.filter = f
+
+	return t, nil
+}
+
+// IsActive returns true if the route selects metrics
+func (r *Route) IsActive() bool {
+	return len(r.expressions) > 0
+}
+
+// Match returns true if the metric matches any of the route expressions or if
+// no expressions are configured
+func (r *Route) Match(metric telegraf.Metric) bool {
+	if !r.IsActive() {
+		return true
+	}
+
+	for _, terms := range r.expressions {
+		if matchTerms(terms, metric) {
+			return true
+		}
+	}
+	return false
+}
+
+// Modify removes the routing tag from the metric
+func (r *Route) Modify(metric telegraf.Metric) {
+	if r.Tag != "" {
+		metric.RemoveTag(r.Tag)
+	}
+}
+
+func matchTerms(terms []routeTerm, metric telegraf.Metric) bool {
+	for _, t := range terms {
+		switch t.kind {
+		case "name":
+			if !t.filter.Match(metric.Name()) {
+				return false
+			}
+		case "tag":
+			value, found := metric.GetTag(t.key)
+			if !found || !t.filter.Match(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
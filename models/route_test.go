@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestRouteCompileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    Route
+		expected string
+	}{
+		{
+			name:     "empty expression",
+			route:    Route{Tag: "route", Expressions: []string{" "}},
+			expected: "empty route expression",
+		},
+		{
+			name:     "missing routing tag",
+			route:    Route{Expressions: []string{"errors"}},
+			expected: "requires the agent's 'routing_tag' setting",
+		},
+		{
+			name:     "invalid tag term",
+			route:    Route{Expressions: []string{"tag:level"}},
+			expected: "requires the form 'tag:<key>=<value>'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.route.Compile(), tt.expected)
+		})
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	route := Route{
+		Tag: "route",
+		Expressions: []string{
+			"errors",
+			"name:syslog tag:severity=err*",
+		},
+	}
+	require.NoError(t, route.Compile())
+	require.True(t, route.IsActive())
+
+	tests := []struct {
+		name     string
+		measure  string
+		tags     map[string]string
+		expected bool
+	}{
+		{
+			name:     "routing tag",
+			measure:  "cpu",
+			tags:     map[string]string{"route": "errors"},
+			expected: true,
+		},
+		{
+			name:     "other routing tag",
+			measure:  "cpu",
+			tags:     map[string]string{"route": "audit"},
+			expected: false,
+		},
+		{
+			name:     "all terms",
+			measure:  "syslog",
+			tags:     map[string]string{"severity": "error"},
+			expected: true,
+		},
+		{
+			name:     "partial terms",
+			measure:  "syslog",
+			tags:     map[string]string{"severity": "info"},
+			expected: false,
+		},
+		{
+			name:     "no match",
+			measure:  "mem",
+			tags:     map[string]string{},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New(tt.measure, tt.tags, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+			require.Equal(t, tt.expected, route.Match(m))
+		})
+	}
+}
+
+func TestRouteEmpty(t *testing.T) {
+	route := Route{Tag: "route"}
+	require.NoError(t, route.Compile())
+	require.False(t, route.IsActive())
+
+	m := metric.New("cpu", map[string]string{"route": "errors"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.True(t, route.Match(m))
+
+	route.Modify(m)
+	require.False(t, m.HasTag("route"))
+}
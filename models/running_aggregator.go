@@ -4,12 +4,21 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
+// periodAwareAggregator is implemented by aggregators requiring the end of
+// the aggregation period being pushed, e.g. to account for durations up to the
+// end of the period.
+type periodAwareAggregator interface {
+	SetPeriodEnd(end time.Time)
+}
+
 type RunningAggregator struct {
 	sync.Mutex
 	Aggregator  telegraf.Aggregator
@@ -18,6 +27,10 @@ type RunningAggregator struct {
 	periodEnd   time.Time
 	log         telegraf.Logger
 
+	// Span of the running push attached to the aggregated metrics, protected
+	// by the lock
+	pushSpan trace.Span
+
 	MetricsPushed   selfstat.Stat
 	MetricsFiltered selfstat.Stat
 	MetricsDropped  selfstat.Stat
@@ -130,6 +143,9 @@ func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.
 		r.Config.Tags,
 		nil)
 
+	if r.pushSpan != nil {
+		propagateSpan(r.pushSpan, m)
+	}
 	r.MetricsPushed.Incr(1)
 
 	return m
@@ -175,6 +191,10 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Lock()
 	defer r.Unlock()
 
+	if p, ok := r.Aggregator.(periodAwareAggregator); ok {
+		p.SetPeriodEnd(r.periodEnd)
+	}
+
 	since := r.periodEnd
 	until := r.periodEnd.Add(r.Config.Period)
 
@@ -190,9 +210,13 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 
 	r.UpdateWindow(since, until)
 
+	nMetrics := r.MetricsPushed.Get()
+	r.pushSpan = startSpan("aggregate", r.LogName())
 	start := time.Now()
 	r.Aggregator.Push(acc)
 	elapsed := time.Since(start)
+	endSpan(r.pushSpan, int(r.MetricsPushed.Get()-nMetrics), nil)
+	r.pushSpan = nil
 	r.PushTime.Incr(elapsed.Nanoseconds())
 	r.Aggregator.Reset()
 }
@@ -239,6 +239,24 @@ func TestRunningAggregatorAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestRunningAggregatorPushPeriodEnd(t *testing.T) {
+	a := &mockPeriodAggregator{}
+	ra := NewRunningAggregator(a, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+
+	until := time.Now().Truncate(time.Minute)
+	ra.UpdateWindow(until.Add(-ra.Config.Period), until)
+
+	// The aggregator receives the end of the pushed period, not the one of
+	// the next period
+	acc := testutil.Accumulator{}
+	ra.Push(&acc)
+	require.Equal(t, until, a.periodEnd)
+	require.Equal(t, until.Add(ra.Config.Period), ra.EndPeriod())
+}
+
 type mockAggregator struct {
 	sum int64
 }
@@ -265,3 +283,12 @@ func (t *mockAggregator) Add(in telegraf.Metric) {
 		}
 	}
 }
+
+type mockPeriodAggregator struct {
+	mockAggregator
+	periodEnd time.Time
+}
+
+func (t *mockPeriodAggregator) SetPeriodEnd(end time.Time) {
+	t.periodEnd = end
+}
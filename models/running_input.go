@@ -3,8 +3,13 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
@@ -22,18 +27,32 @@ type RunningInput struct {
 	Config *InputConfig
 
 	log         telegraf.Logger
-	defaultTags map[string]string
+	defaultTags atomic.Pointer[map[string]string]
 
 	startAcc    telegraf.Accumulator
 	started     bool
 	retries     uint64
 	gatherStart time.Time
 	gatherEnd   time.Time
+	schedule    cron.Schedule
+
+	// Number of errors logged by the plugin, used to detect failed gathers
+	errorCount atomic.Uint64
+
+	// Span of the running gather attached to the gathered metrics
+	gatherSpan atomic.Pointer[trace.Span]
+
+	// Health status of the plugin, protected by the status lock
+	statusLock       sync.Mutex
+	lastGather       time.Time
+	lastGatherFailed bool
+	gatherFailures   int
 
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
 	StartupErrors   selfstat.Stat
+	GatherDuration  *selfstat.Histogram
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -44,16 +63,12 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 
 	inputErrorsRegister := selfstat.Register("gather", "errors", tags)
 	logger := logging.New("inputs", config.Name, config.Alias)
-	logger.RegisterErrorCallback(func() {
-		inputErrorsRegister.Incr(1)
-		GlobalGatherErrors.Incr(1)
-	})
 	if err := logger.SetLogLevel(config.LogLevel); err != nil {
 		logger.Error(err)
 	}
 	SetLoggerOnPlugin(input, logger)
 
-	return &RunningInput{
+	ri := &RunningInput{
 		Input:  input,
 		Config: config,
 		MetricsGathered: selfstat.Register(
@@ -76,8 +91,33 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"startup_errors",
 			tags,
 		),
+		GatherDuration: selfstat.RegisterHistogram(
+			"gather",
+			"gather_duration_seconds",
+			tags,
+			selfstat.DurationBuckets,
+		),
 		log: logger,
 	}
+	logger.RegisterErrorCallback(func() {
+		inputErrorsRegister.Incr(1)
+		GlobalGatherErrors.Incr(1)
+		ri.errorCount.Add(1)
+	})
+
+	return ri
+}
+
+// InputStatus is the health status of an input.
+type InputStatus struct {
+	// Completion time of the last gather cycle, zero if the input did not
+	// gather yet
+	LastGather time.Time
+
+	// Result of the last gather cycle and number of consecutive failed cycles.
+	// A cycle fails if the plugin reported any error during gathering.
+	LastGatherSuccess         bool
+	ConsecutiveGatherFailures int
 }
 
 // InputConfig is the common config for all inputs.
@@ -94,6 +134,11 @@ type InputConfig struct {
 	StartupErrorBehavior string
 	LogLevel             string
 
+	// Cron schedule to gather the input at instead of the interval and the
+	// policy for handling missed runs
+	Schedule        string
+	ScheduleCatchUp string
+
 	NameOverride            string
 	MeasurementPrefix       string
 	MeasurementSuffix       string
@@ -126,12 +171,31 @@ func (r *RunningInput) Init() error {
 		return fmt.Errorf("invalid 'time_source' setting %q", r.Config.TimeSource)
 	}
 
+	switch r.Config.ScheduleCatchUp {
+	case "", "skip", "once":
+	default:
+		return fmt.Errorf("invalid 'schedule_catchup' setting %q", r.Config.ScheduleCatchUp)
+	}
+	if r.Config.Schedule != "" {
+		schedule, err := cron.ParseStandard(r.Config.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid 'schedule' setting %q: %w", r.Config.Schedule, err)
+		}
+		r.schedule = schedule
+	}
+
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		return p.Init()
 	}
 	return nil
 }
 
+// Schedule returns the cron schedule of the input or nil if the input is
+// gathered at the configured interval.
+func (r *RunningInput) Schedule() cron.Schedule {
+	return r.schedule
+}
+
 func (r *RunningInput) Start(acc telegraf.Accumulator) error {
 	plugin, ok := r.Input.(telegraf.ServiceInput)
 	if !ok {
@@ -185,6 +249,24 @@ func (r *RunningInput) Stop() {
 	}
 }
 
+// Pause pauses the consumption of data of service inputs supporting it and
+// returns false if the input cannot be paused.
+func (r *RunningInput) Pause() bool {
+	plugin, ok := r.Input.(telegraf.PausableInput)
+	if !ok {
+		return false
+	}
+	plugin.Pause()
+	return true
+}
+
+// Resume resumes the consumption of data of a paused service input.
+func (r *RunningInput) Resume() {
+	if plugin, ok := r.Input.(telegraf.PausableInput); ok {
+		plugin.Resume()
+	}
+}
+
 func (r *RunningInput) ID() string {
 	if p, ok := r.Input.(telegraf.PluginWithID); ok {
 		return p.ID()
@@ -207,7 +289,7 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		r.Config.MeasurementPrefix,
 		r.Config.MeasurementSuffix,
 		r.Config.Tags,
-		r.defaultTags)
+		r.DefaultTags())
 
 	r.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
@@ -215,13 +297,17 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		return nil
 	}
 
+	if span := r.gatherSpan.Load(); span != nil {
+		propagateSpan(*span, metric)
+	}
+
 	if r.Config.AlwaysIncludeLocalTags || r.Config.AlwaysIncludeGlobalTags {
 		var local, global map[string]string
 		if r.Config.AlwaysIncludeLocalTags {
 			local = r.Config.Tags
 		}
 		if r.Config.AlwaysIncludeGlobalTags {
-			global = r.defaultTags
+			global = r.DefaultTags()
 		}
 		makeMetric(metric, "", "", "", local, global)
 	}
@@ -247,6 +333,7 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.updateStatus(time.Now(), false)
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -256,16 +343,61 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	nErrors := r.errorCount.Load()
+	nMetrics := r.MetricsGathered.Get()
+	span := startSpan("gather", r.LogName())
+	r.gatherSpan.Store(&span)
 	r.gatherStart = time.Now()
 	err := r.Input.Gather(acc)
 	r.gatherEnd = time.Now()
+	r.gatherSpan.Store(nil)
+	endSpan(span, int(r.MetricsGathered.Get()-nMetrics), err)
 
-	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+	elapsed := r.gatherEnd.Sub(r.gatherStart)
+	r.GatherTime.Incr(elapsed.Nanoseconds())
+	r.GatherDuration.ObserveDuration(elapsed)
+	r.updateStatus(r.gatherEnd, err == nil && r.errorCount.Load() == nErrors)
 	return err
 }
 
+func (r *RunningInput) updateStatus(t time.Time, success bool) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	r.lastGather = t
+	r.lastGatherFailed = !success
+	if success {
+		r.gatherFailures = 0
+	} else {
+		r.gatherFailures++
+	}
+}
+
+// Status returns the current health status of the input.
+func (r *RunningInput) Status() InputStatus {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	return InputStatus{
+		LastGather:                r.lastGather,
+		LastGatherSuccess:         !r.lastGather.IsZero() && !r.lastGatherFailed,
+		ConsecutiveGatherFailures: r.gatherFailures,
+	}
+}
+
+// SetDefaultTags sets the global tags added to the metrics of the input. The
+// tags can be replaced while the input is running but the given map must not
+// be modified afterwards.
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
-	r.defaultTags = tags
+	r.defaultTags.Store(&tags)
+}
+
+// DefaultTags returns the global tags added to the metrics of the input
+func (r *RunningInput) DefaultTags() map[string]string {
+	if tags := r.defaultTags.Load(); tags != nil {
+		return *tags
+	}
+	return nil
 }
 
 func (r *RunningInput) Log() telegraf.Logger {
@@ -518,7 +518,7 @@ func TestRunningInputProbingSuccess(t *testing.T) {
 		},
 		{
 			name:                 "probing plugin with probe value not set",
-			input:                &mockInput{probeErr},
+			input:                &mockInput{probeReturn: probeErr},
 			startupErrorBehavior: "ignore",
 		},
 	} {
@@ -533,8 +533,88 @@ func TestRunningInputProbingSuccess(t *testing.T) {
 	}
 }
 
+func TestRunningInputSchedule(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput"})
+	require.NoError(t, ri.Init())
+	require.Nil(t, ri.Schedule())
+
+	ri = NewRunningInput(&mockInput{}, &InputConfig{
+		Name:            "TestRunningInput",
+		Schedule:        "0 2 * * *",
+		ScheduleCatchUp: "once",
+	})
+	require.NoError(t, ri.Init())
+	require.NotNil(t, ri.Schedule())
+
+	ri = NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput", Schedule: "0 25 * * *"})
+	require.ErrorContains(t, ri.Init(), "invalid 'schedule' setting")
+
+	ri = NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput", ScheduleCatchUp: "all"})
+	require.ErrorContains(t, ri.Init(), "invalid 'schedule_catchup' setting")
+}
+
+func TestSetStateStoreOnPlugin(t *testing.T) {
+	type statePlugin struct {
+		State telegraf.StateStore `toml:"-"`
+	}
+
+	store := func() (telegraf.StateStore, error) {
+		return &mockStateStore{}, nil
+	}
+
+	plugin := &statePlugin{}
+	found, err := SetStateStoreOnPlugin(plugin, store)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotNil(t, plugin.State)
+
+	found, err = SetStateStoreOnPlugin(&mockInput{}, store)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+type mockStateStore struct{}
+
+func (*mockStateStore) Get(string, interface{}) (bool, error) { return false, nil }
+func (*mockStateStore) Set(string, interface{}) error         { return nil }
+func (*mockStateStore) Delete(string)                         {}
+
+func TestRunningInputStatus(t *testing.T) {
+	input := &mockInput{}
+	ri := NewRunningInput(input, &InputConfig{Name: "TestRunningInput"})
+	require.NoError(t, ri.Init())
+
+	status := ri.Status()
+	require.True(t, status.LastGather.IsZero())
+	require.False(t, status.LastGatherSuccess)
+
+	var acc testutil.Accumulator
+	input.gatherReturn = errors.New("failed")
+	require.Error(t, ri.Gather(&acc))
+	require.Error(t, ri.Gather(&acc))
+	status = ri.Status()
+	require.False(t, status.LastGather.IsZero())
+	require.False(t, status.LastGatherSuccess)
+	require.Equal(t, 2, status.ConsecutiveGatherFailures)
+
+	// Errors logged by the plugin during gathering fail the cycle
+	input.gatherReturn = nil
+	input.gatherLog = true
+	require.NoError(t, ri.Gather(&acc))
+	require.Equal(t, 3, ri.Status().ConsecutiveGatherFailures)
+
+	input.gatherLog = false
+	require.NoError(t, ri.Gather(&acc))
+	status = ri.Status()
+	require.True(t, status.LastGatherSuccess)
+	require.Zero(t, status.ConsecutiveGatherFailures)
+}
+
 type mockInput struct {
-	probeReturn error
+	probeReturn  error
+	gatherReturn error
+	gatherLog    bool
+	Log          telegraf.Logger `toml:"-"`
 }
 
 func (*mockInput) SampleConfig() string {
@@ -545,6 +625,9 @@ func (m *mockInput) Probe() error {
 	return m.probeReturn
 }
 
-func (*mockInput) Gather(telegraf.Accumulator) error {
-	return nil
+func (m *mockInput) Gather(telegraf.Accumulator) error {
+	if m.gatherLog {
+		m.Log.Error("failed")
+	}
+	return m.gatherReturn
 }
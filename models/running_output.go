@@ -1,15 +1,22 @@
 package models
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -29,22 +36,60 @@ type OutputConfig struct {
 	ID                   string
 	StartupErrorBehavior string
 	Filter               Filter
+	Route                Route
 
 	FlushInterval     time.Duration
 	FlushJitter       time.Duration
 	MetricBufferLimit int
 	MetricBatchSize   int
+	MetricBatchOrder  string
 
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
 
-	BufferStrategy  string
-	BufferDirectory string
+	BufferStrategy    string
+	BufferDirectory   string
+	BufferSegmentSize int64
+	BufferMaxSize     int64
+	BufferMaxAge      time.Duration
+
+	// Maximum number of metrics and bytes, in line-protocol representation,
+	// written per second. Zero means unlimited.
+	MetricRateLimit int64
+	ByteRateLimit   int64
+
+	// Percentage of the buffer limit above which the output signals
+	// backpressure once the fill level persisted for the given delay.
+	// Zero disables backpressure signaling.
+	BackpressureThreshold int
+	BackpressureDelay     time.Duration
+
+	// Destinations for metrics rejected by the output, i.e. a file receiving
+	// the metrics in line-protocol format and the alias of another output
+	DeadLetterFile   string
+	DeadLetterOutput string
+
+	// Maximum time to retry writing the buffered metrics on shutdown with
+	// the "drain" shutdown policy, overriding the agent setting if non-zero
+	DrainTimeout time.Duration
 
 	LogLevel string
 }
 
+// OutputStatus is the health status of an output.
+type OutputStatus struct {
+	// Number of metrics in the buffer and the buffer limit. The limit is zero
+	// for buffer strategies not limiting the number of metrics.
+	BufferSize  int
+	BufferLimit int
+
+	// Completion time of the last successful write, zero if the output did
+	// not write yet, and the number of consecutive failed writes
+	LastWrite                time.Time
+	ConsecutiveWriteFailures int
+}
+
 // RunningOutput contains the output configuration
 type RunningOutput struct {
 	// Must be 64-bit aligned
@@ -59,6 +104,8 @@ type RunningOutput struct {
 	MetricsFiltered selfstat.Stat
 	WriteTime       selfstat.Stat
 	StartupErrors   selfstat.Stat
+	MetricsDeferred selfstat.Stat
+	WriteDuration   *selfstat.Histogram
 
 	BatchReady chan time.Time
 
@@ -68,6 +115,26 @@ type RunningOutput struct {
 	started bool
 	retries uint64
 
+	metricLimiter *rate.Limiter
+	byteLimiter   *rate.Limiter
+	sizer         *influx.Serializer
+
+	// Start of the period the buffer is above the backpressure threshold
+	// and whether backpressure is signaled, only used by Backpressure
+	pressureStart time.Time
+	pressure      bool
+
+	// Destinations for rejected metrics
+	deadLetterOutput atomic.Pointer[RunningOutput]
+	deadLetterLock   sync.Mutex
+	deadLetterFile   *os.File
+	deadLetterSerial *influx.Serializer
+
+	// Health status of the plugin, protected by the status lock
+	statusLock    sync.Mutex
+	lastWrite     time.Time
+	writeFailures int
+
 	aggMutex sync.Mutex
 }
 
@@ -100,7 +167,13 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
+	diskConfig := DiskBufferConfig{
+		Directory:   config.BufferDirectory,
+		SegmentSize: config.BufferSegmentSize,
+		MaxSize:     config.BufferMaxSize,
+		MaxAge:      config.BufferMaxAge,
+	}
+	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, diskConfig)
 	if err != nil {
 		panic(err)
 	}
@@ -127,9 +200,31 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
+		MetricsDeferred: selfstat.Register(
+			"write",
+			"metrics_deferred",
+			tags,
+		),
+		WriteDuration: selfstat.RegisterHistogram(
+			"write",
+			"write_duration_seconds",
+			tags,
+			selfstat.DurationBuckets,
+		),
 		log: logger,
 	}
 
+	if config.MetricRateLimit > 0 {
+		ro.metricLimiter = rate.NewLimiter(rate.Limit(config.MetricRateLimit), int(config.MetricRateLimit))
+	}
+	if config.ByteRateLimit > 0 {
+		ro.byteLimiter = rate.NewLimiter(rate.Limit(config.ByteRateLimit), int(config.ByteRateLimit))
+		ro.sizer = &influx.Serializer{}
+		if err := ro.sizer.Init(); err != nil {
+			panic(err)
+		}
+	}
+
 	return ro
 }
 
@@ -156,15 +251,49 @@ func (r *RunningOutput) Init() error {
 		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
 	}
 
+	switch r.Config.MetricBatchOrder {
+	case "", "arrival", "measurement", "series":
+	default:
+		return fmt.Errorf("invalid 'metric_batch_order' setting %q", r.Config.MetricBatchOrder)
+	}
+
+	if r.Config.MetricRateLimit < 0 {
+		return fmt.Errorf("invalid 'metric_rate_limit' setting %d", r.Config.MetricRateLimit)
+	}
+	if r.Config.ByteRateLimit < 0 {
+		return fmt.Errorf("invalid 'byte_rate_limit' setting %d", r.Config.ByteRateLimit)
+	}
+	if r.Config.BackpressureThreshold < 0 || r.Config.BackpressureThreshold > 100 {
+		return fmt.Errorf("invalid 'backpressure_threshold' setting %d", r.Config.BackpressureThreshold)
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
 			return err
 		}
 	}
+
+	if r.Config.DeadLetterFile != "" {
+		f, err := os.OpenFile(r.Config.DeadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return fmt.Errorf("opening dead-letter file failed: %w", err)
+		}
+		r.deadLetterFile = f
+		r.deadLetterSerial = &influx.Serializer{}
+		if err := r.deadLetterSerial.Init(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// SetDeadLetterOutput sets the output receiving the metrics rejected by this
+// output. A nil value disables forwarding of rejected metrics.
+func (r *RunningOutput) SetDeadLetterOutput(output *RunningOutput) {
+	r.deadLetterOutput.Store(output)
+}
+
 func (r *RunningOutput) Connect() error {
 	// Try to connect and exit early on success
 	err := r.Output.Connect()
@@ -204,6 +333,34 @@ func (r *RunningOutput) Close() {
 	if err := r.buffer.Close(); err != nil {
 		r.log.Errorf("Error closing output buffer: %v", err)
 	}
+
+	r.deadLetterLock.Lock()
+	defer r.deadLetterLock.Unlock()
+	if r.deadLetterFile != nil {
+		if err := r.deadLetterFile.Close(); err != nil {
+			r.log.Errorf("Error closing dead-letter file: %v", err)
+		}
+		r.deadLetterFile = nil
+	}
+}
+
+// AdoptBuffer takes over the buffered metrics of the given output by
+// exchanging the buffers of both outputs. This is used to preserve unwritten
+// metrics when replacing an output at runtime. Buffers are only exchanged for
+// in-memory buffers of the same size, otherwise false is returned and the
+// metrics stay with the given output.
+// Neither output must be written to while adopting the buffer.
+func (r *RunningOutput) AdoptBuffer(other *RunningOutput) bool {
+	isMemory := func(strategy string) bool { return strategy == "" || strategy == "memory" }
+	if !isMemory(r.Config.BufferStrategy) || !isMemory(other.Config.BufferStrategy) {
+		return false
+	}
+	if r.MetricBufferLimit != other.MetricBufferLimit {
+		return false
+	}
+
+	r.buffer, other.buffer = other.buffer, r.buffer
+	return true
 }
 
 // AddMetric adds a metric to the output.
@@ -217,6 +374,11 @@ func (r *RunningOutput) AddMetric(metric telegraf.Metric) {
 		return
 	}
 
+	if !r.Config.Route.Match(metric) {
+		r.MetricsFiltered.Incr(1)
+		return
+	}
+
 	r.add(metric.Copy())
 }
 
@@ -231,10 +393,16 @@ func (r *RunningOutput) AddMetricNoCopy(metric telegraf.Metric) {
 		return
 	}
 
+	if !r.Config.Route.Match(metric) {
+		r.metricFiltered(metric)
+		return
+	}
+
 	r.add(metric)
 }
 
 func (r *RunningOutput) add(metric telegraf.Metric) {
+	r.Config.Route.Modify(metric)
 	r.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
 		r.metricFiltered(metric)
@@ -283,6 +451,7 @@ func (r *RunningOutput) Write() error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.updateStatus(internal.ErrNotConnected)
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -311,10 +480,8 @@ func (r *RunningOutput) Write() error {
 		if len(tx.Batch) == 0 {
 			return nil
 		}
-		err := r.writeMetrics(tx.Batch)
-		r.updateTransaction(tx, err)
-		r.buffer.EndTransaction(tx)
-		if err != nil {
+		limited, err := r.writeTransaction(tx)
+		if err != nil || limited {
 			return err
 		}
 	}
@@ -328,6 +495,7 @@ func (r *RunningOutput) WriteBatch() error {
 		r.retries++
 		if err := r.Output.Connect(); err != nil {
 			r.StartupErrors.Incr(1)
+			r.updateStatus(internal.ErrNotConnected)
 			return internal.ErrNotConnected
 		}
 		r.started = true
@@ -338,11 +506,141 @@ func (r *RunningOutput) WriteBatch() error {
 	if len(tx.Batch) == 0 {
 		return nil
 	}
-	err := r.writeMetrics(tx.Batch)
-	r.updateTransaction(tx, err)
+	_, err := r.writeTransaction(tx)
+	return err
+}
+
+// writeTransaction writes the metrics of the transaction permitted by the
+// rate limits and ends the transaction. Metrics exceeding the limits are kept
+// in the buffer for the next write. The returned flag is true if the rate
+// limits prevented writing the whole batch.
+func (r *RunningOutput) writeTransaction(tx *Transaction) (bool, error) {
+	n := r.reserve(tx.Batch, time.Now())
+	if n < len(tx.Batch) {
+		r.MetricsDeferred.Incr(int64(len(tx.Batch) - n))
+		r.log.Debugf("Rate limit reached; deferring %d metrics", len(tx.Batch)-n)
+	}
+	if n == 0 {
+		r.buffer.EndTransaction(tx)
+		return true, nil
+	}
+
+	err := r.writeMetrics(tx.Batch[:n])
+	r.updateStatus(err)
+	if err == nil && n < len(tx.Batch) {
+		// Accept the written metrics only and keep the remaining ones
+		tx.Accept = make([]int, n)
+		for i := range n {
+			tx.Accept[i] = i
+		}
+	} else {
+		r.updateTransaction(tx, err)
+		r.deadLetter(tx, err)
+	}
 	r.buffer.EndTransaction(tx)
 
-	return err
+	return n < len(tx.Batch), err
+}
+
+// reserve returns the number of metrics at the beginning of the batch that
+// can be written within the configured rate limits and consumes the
+// corresponding tokens.
+func (r *RunningOutput) reserve(batch []telegraf.Metric, now time.Time) int {
+	n := len(batch)
+	if r.metricLimiter != nil {
+		n = min(n, int(r.metricLimiter.TokensAt(now)))
+	}
+
+	var size int
+	if r.byteLimiter != nil {
+		available := int(r.byteLimiter.TokensAt(now))
+		for i, m := range batch[:n] {
+			// Metrics larger than the limit can only be written with the
+			// full burst available
+			msize := min(r.metricSize(m), r.byteLimiter.Burst())
+			if size+msize > available {
+				n = i
+				break
+			}
+			size += msize
+		}
+	}
+
+	if n == 0 {
+		return 0
+	}
+	if r.metricLimiter != nil {
+		r.metricLimiter.AllowN(now, n)
+	}
+	if r.byteLimiter != nil {
+		r.byteLimiter.AllowN(now, size)
+	}
+	return n
+}
+
+// metricSize returns the size of the metric in line-protocol representation
+func (r *RunningOutput) metricSize(m telegraf.Metric) int {
+	buf, err := r.sizer.Serialize(m)
+	if err != nil {
+		r.log.Debugf("Could not determine size of metric: %v", err)
+		return 0
+	}
+	return len(buf)
+}
+
+// Backpressure returns true if the buffer fill level exceeded the configured
+// threshold for at least the configured delay. Once signaled, backpressure is
+// released when the fill level drops below half of the threshold. The
+// function must not be called concurrently.
+func (r *RunningOutput) Backpressure(now time.Time) bool {
+	if r.Config.BackpressureThreshold <= 0 {
+		return false
+	}
+
+	fill := 100 * r.buffer.Len() / r.MetricBufferLimit
+	if r.pressure {
+		if 2*fill < r.Config.BackpressureThreshold {
+			r.pressure = false
+			r.pressureStart = time.Time{}
+		}
+		return r.pressure
+	}
+
+	if fill < r.Config.BackpressureThreshold {
+		r.pressureStart = time.Time{}
+		return false
+	}
+	if r.pressureStart.IsZero() {
+		r.pressureStart = now
+	}
+	r.pressure = now.Sub(r.pressureStart) >= r.Config.BackpressureDelay
+	return r.pressure
+}
+
+func (r *RunningOutput) updateStatus(err error) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	if err != nil {
+		r.writeFailures++
+		return
+	}
+	r.lastWrite = time.Now()
+	r.writeFailures = 0
+}
+
+// Status returns the current health status of the output.
+func (r *RunningOutput) Status() OutputStatus {
+	status := OutputStatus{BufferSize: r.buffer.Len()}
+	if r.Config.BufferStrategy != "disk" {
+		status.BufferLimit = r.MetricBufferLimit
+	}
+
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+	status.LastWrite = r.lastWrite
+	status.ConsecutiveWriteFailures = r.writeFailures
+	return status
 }
 
 func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
@@ -352,10 +650,26 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 		atomic.StoreInt64(&r.droppedMetrics, 0)
 	}
 
+	batch, order := r.orderBatch(metrics)
+
+	span := startSpan("write", r.LogName(), batch...)
 	start := time.Now()
-	err := r.Output.Write(metrics)
+	err := r.Output.Write(batch)
 	elapsed := time.Since(start)
+	endSpan(span, len(batch), err)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
+	r.WriteDuration.ObserveDuration(elapsed)
+
+	// Map the indices of a partial write back to the original batch
+	var writeErr *internal.PartialWriteError
+	if order != nil && errors.As(err, &writeErr) {
+		for i, idx := range writeErr.MetricsAccept {
+			writeErr.MetricsAccept[i] = order[idx]
+		}
+		for i, idx := range writeErr.MetricsReject {
+			writeErr.MetricsReject[i] = order[idx]
+		}
+	}
 
 	if err == nil {
 		r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
@@ -363,7 +677,61 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	return err
 }
 
-func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
+// orderBatch returns the metrics in the configured batch order, grouped by
+// measurement and optionally sorted by series and time, e.g. to improve the
+// encoding of columnar outputs. The second return value contains the index
+// of each ordered metric in the given batch and is nil if the batch is
+// passed in order of arrival.
+func (r *RunningOutput) orderBatch(metrics []telegraf.Metric) ([]telegraf.Metric, []int) {
+	var compare func(a, b telegraf.Metric) int
+	switch r.Config.MetricBatchOrder {
+	case "measurement":
+		compare = func(a, b telegraf.Metric) int {
+			return strings.Compare(a.Name(), b.Name())
+		}
+	case "series":
+		compare = func(a, b telegraf.Metric) int {
+			if c := strings.Compare(a.Name(), b.Name()); c != 0 {
+				return c
+			}
+			if c := compareTags(a.TagList(), b.TagList()); c != 0 {
+				return c
+			}
+			return a.Time().Compare(b.Time())
+		}
+	default:
+		return metrics, nil
+	}
+
+	order := make([]int, len(metrics))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return compare(metrics[i], metrics[j])
+	})
+
+	batch := make([]telegraf.Metric, 0, len(metrics))
+	for _, idx := range order {
+		batch = append(batch, metrics[idx])
+	}
+	return batch, order
+}
+
+// compareTags compares the tag lists, sorted by key, element-wise
+func compareTags(a, b []*telegraf.Tag) int {
+	for i := range min(len(a), len(b)) {
+		if c := strings.Compare(a[i].Key, b[i].Key); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a[i].Value, b[i].Value); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// No error indicates all metrics were written successfully
 	if err == nil {
 		tx.AcceptAll()
@@ -371,10 +739,21 @@ func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	}
 
 	// A non-partial-write-error indicated none of the metrics were written
-	// successfully and we should keep them for the next write cycle
+	// successfully and we should keep them for the next write cycle. Batches
+	// rejected as a whole are passed to the dead-letter destination instead
+	// if one is configured.
 	var writeErr *internal.PartialWriteError
 	if !errors.As(err, &writeErr) {
-		tx.KeepAll()
+		var rejectedErr *internal.RejectedError
+		rejected := errors.As(err, &rejectedErr) || errors.Is(err, internal.ErrSerialization)
+		if !rejected || !r.hasDeadLetter() {
+			tx.KeepAll()
+			return
+		}
+		tx.Reject = make([]int, len(tx.Batch))
+		for i := range tx.Batch {
+			tx.Reject[i] = i
+		}
 		return
 	}
 
@@ -383,6 +762,86 @@ func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	tx.Reject = writeErr.MetricsReject
 }
 
+// deadLetter passes copies of the metrics rejected in the transaction to the
+// configured dead-letter destinations. The copies are tagged with the class of
+// the rejection reason and the rejecting output to allow for later replay.
+func (r *RunningOutput) deadLetter(tx *Transaction, err error) {
+	target := r.deadLetterOutput.Load()
+	if len(tx.Reject) == 0 || (target == nil && r.deadLetterFile == nil) {
+		return
+	}
+
+	var writeErr *internal.PartialWriteError
+	errors.As(err, &writeErr)
+
+	metrics := make([]telegraf.Metric, 0, len(tx.Reject))
+	for i, idx := range tx.Reject {
+		m := tx.Batch[idx]
+		if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+			m = wm.Unwrap()
+		}
+		m = m.Copy()
+
+		reason := err
+		if writeErr != nil && len(writeErr.MetricsRejectErrors) == len(tx.Reject) && writeErr.MetricsRejectErrors[i] != nil {
+			reason = errors.Join(writeErr.MetricsRejectErrors[i], err)
+		}
+		m.AddTag("rejection_reason", rejectionReason(reason))
+		m.AddTag("rejected_by", r.LogName())
+		metrics = append(metrics, m)
+	}
+
+	if r.deadLetterFile != nil {
+		r.writeDeadLetterFile(metrics)
+	}
+	if target != nil {
+		for _, m := range metrics {
+			target.AddMetricNoCopy(m)
+		}
+	}
+}
+
+func (r *RunningOutput) hasDeadLetter() bool {
+	return r.deadLetterOutput.Load() != nil || r.deadLetterFile != nil
+}
+
+// rejectionReason returns the class of the rejection reason to keep the
+// cardinality of the tag bounded
+func rejectionReason(err error) string {
+	var rejectedErr *internal.RejectedError
+	switch {
+	case errors.Is(err, internal.ErrSerialization):
+		return "serialization"
+	case errors.Is(err, internal.ErrSizeLimitReached):
+		return "size_limit"
+	case errors.As(err, &rejectedErr):
+		return "rejected"
+	}
+	return "other"
+}
+
+func (r *RunningOutput) writeDeadLetterFile(metrics []telegraf.Metric) {
+	r.deadLetterLock.Lock()
+	defer r.deadLetterLock.Unlock()
+
+	// The file is already closed on shutdown
+	if r.deadLetterFile == nil {
+		return
+	}
+
+	for _, m := range metrics {
+		buf, err := r.deadLetterSerial.Serialize(m)
+		if err != nil {
+			r.log.Errorf("Could not serialize rejected metric: %v", err)
+			continue
+		}
+		if _, err := r.deadLetterFile.Write(buf); err != nil {
+			r.log.Errorf("Writing rejected metrics to dead-letter file failed: %v", err)
+			return
+		}
+	}
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
 	if r.Config.BufferStrategy == "disk" {
@@ -399,3 +858,34 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// SpillBuffer writes the buffered metrics in InfluxDB line-protocol format to
+// the given file. The metrics are kept in the buffer. The number of metrics
+// written to the file is returned.
+func (r *RunningOutput) SpillBuffer(filename string) (int, error) {
+	tx := r.buffer.BeginTransaction(r.buffer.Len())
+	defer r.buffer.EndTransaction(tx)
+	if len(tx.Batch) == 0 {
+		return 0, nil
+	}
+
+	serializer := &influx.Serializer{UintSupport: true}
+	if err := serializer.Init(); err != nil {
+		return 0, err
+	}
+	buf, err := serializer.SerializeBatch(tx.Batch)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filename, buf, 0640); err != nil {
+		return 0, err
+	}
+	return len(tx.Batch), nil
+}
+
+// AddBuffered adds the given metrics to the buffer without filtering and
+// modifying them, e.g. to restore previously spilled metrics. The number of
+// metrics dropped due to the buffer limit is returned.
+func (r *RunningOutput) AddBuffered(metrics []telegraf.Metric) int {
+	return r.buffer.Add(metrics...)
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -10,6 +13,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -80,6 +85,35 @@ func TestRunningOutputPassFilter(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+// Test that only routed metrics are added and the routing tag is removed
+func TestRunningOutputRoute(t *testing.T) {
+	conf := &OutputConfig{
+		Route: Route{
+			Tag:         "route",
+			Expressions: []string{"errors", "name:metric1"},
+		},
+	}
+	require.NoError(t, conf.Route.Compile())
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	ro.AddMetric(testutil.TestMetric(101, "metric2"))
+	routed := testutil.TestMetric(101, "metric3")
+	routed.AddTag("route", "errors")
+	ro.AddMetricNoCopy(routed)
+	unrouted := testutil.TestMetric(101, "metric4")
+	unrouted.AddTag("route", "audit")
+	ro.AddMetricNoCopy(unrouted)
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 2)
+	require.Equal(t, "metric1", m.Metrics()[0].Name())
+	require.Equal(t, "metric3", m.Metrics()[1].Name())
+	require.False(t, m.Metrics()[1].HasTag("route"))
+}
+
 // Test that tags are properly included
 func TestRunningOutputTagIncludeNoMatch(t *testing.T) {
 	conf := &OutputConfig{
@@ -271,6 +305,38 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+func TestRunningOutputAdoptBuffer(t *testing.T) {
+	oldPlugin := &mockOutput{}
+	oldOutput := NewRunningOutput(oldPlugin, &OutputConfig{Filter: Filter{}}, 1000, 10000)
+	for _, metric := range first5 {
+		oldOutput.AddMetric(metric)
+	}
+
+	newPlugin := &mockOutput{}
+	newOutput := NewRunningOutput(newPlugin, &OutputConfig{Filter: Filter{}}, 1000, 10000)
+	require.True(t, newOutput.AdoptBuffer(oldOutput))
+	require.Equal(t, 5, newOutput.BufferLength())
+	require.Zero(t, oldOutput.BufferLength())
+
+	require.NoError(t, newOutput.Write())
+	require.Len(t, newPlugin.Metrics(), 5)
+	require.NoError(t, oldOutput.Write())
+	require.Empty(t, oldPlugin.Metrics())
+
+	// Buffers of different sizes are not exchanged
+	otherOutput := NewRunningOutput(&mockOutput{}, &OutputConfig{Filter: Filter{}}, 1000, 100)
+	require.False(t, otherOutput.AdoptBuffer(newOutput))
+
+	// Disk buffers are bound to the output and are never exchanged
+	diskOutput := NewRunningOutput(
+		&mockOutput{},
+		&OutputConfig{Filter: Filter{}, BufferStrategy: "disk", BufferDirectory: t.TempDir()},
+		1000, 10000,
+	)
+	defer diskOutput.Close()
+	require.False(t, diskOutput.AdoptBuffer(newOutput))
+}
+
 // Verify that the order of points is preserved during write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -431,16 +497,19 @@ func TestRunningOutputInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
-				"buffer_limit":     10,
-				"buffer_size":      0,
-				"errors":           0,
-				"metrics_added":    0,
-				"metrics_rejected": 0,
-				"metrics_dropped":  0,
-				"metrics_filtered": 0,
-				"metrics_written":  0,
-				"write_time_ns":    0,
-				"startup_errors":   0,
+				"buffer_limit":             10,
+				"buffer_size":              0,
+				"errors":                   0,
+				"metrics_added":            0,
+				"metrics_deferred":         0,
+				"metrics_rejected":         0,
+				"metrics_dropped":          0,
+				"metrics_filtered":         0,
+				"metrics_written":          0,
+				"write_time_ns":            0,
+				"startup_errors":           0,
+				"metrics_dropped_expired":  0,
+				"metrics_dropped_overflow": 0,
 			},
 			time.Unix(0, 0),
 		),
@@ -471,6 +540,187 @@ func TestRunningOutputStartupBehaviorInvalid(t *testing.T) {
 	require.ErrorContains(t, ro.Init(), "invalid 'startup_error_behavior'")
 }
 
+func TestRunningOutputMetricBatchOrderInvalid(t *testing.T) {
+	ro := NewRunningOutput(
+		&mockOutput{},
+		&OutputConfig{
+			Filter:           Filter{},
+			Name:             "test_name",
+			MetricBatchOrder: "foo",
+		},
+		5, 10,
+	)
+	require.ErrorContains(t, ro.Init(), "invalid 'metric_batch_order'")
+}
+
+func TestRunningOutputMetricBatchOrder(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"value": 1}, now.Add(time.Second)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, now.Add(time.Second)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, now),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 4}, now),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 5}, now),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"value": 6}, now),
+	}
+
+	tests := []struct {
+		order    string
+		expected []int
+	}{
+		{order: "", expected: []int{0, 1, 2, 3, 4, 5}},
+		{order: "arrival", expected: []int{0, 1, 2, 3, 4, 5}},
+		{order: "measurement", expected: []int{1, 3, 4, 0, 2, 5}},
+		{order: "series", expected: []int{3, 4, 1, 2, 5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			plugin := &mockOutput{}
+			ro := NewRunningOutput(plugin, &OutputConfig{MetricBatchOrder: tt.order}, 10, 10)
+			require.NoError(t, ro.Init())
+			for _, m := range input {
+				ro.AddMetric(m)
+			}
+			require.NoError(t, ro.Write())
+
+			expected := make([]telegraf.Metric, 0, len(tt.expected))
+			for _, idx := range tt.expected {
+				expected = append(expected, input[idx])
+			}
+			testutil.RequireMetricsEqual(t, expected, plugin.Metrics())
+		})
+	}
+}
+
+func TestRunningOutputMetricBatchOrderPartialSuccess(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 1}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, now),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 3}, now.Add(time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4}, now.Add(time.Second)),
+	}
+
+	plugin := &mockOutput{batchAcceptSize: 2}
+	ro := NewRunningOutput(plugin, &OutputConfig{MetricBatchOrder: "measurement"}, 10, 10)
+	require.NoError(t, ro.Init())
+	for _, m := range input {
+		ro.AddMetric(m)
+	}
+
+	// Only the first two metrics of the ordered batch, i.e. the cpu metrics,
+	// are accepted and must be removed from the buffer
+	require.ErrorIs(t, ro.Write(), internal.ErrSizeLimitReached)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input[1], input[3]}, plugin.Metrics())
+	require.Equal(t, 2, ro.buffer.Len())
+
+	require.NoError(t, ro.Write())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input[1], input[3], input[0], input[2]}, plugin.Metrics())
+	require.Zero(t, ro.buffer.Len())
+}
+
+func TestRunningOutputRateLimitInvalid(t *testing.T) {
+	ro := NewRunningOutput(&mockOutput{}, &OutputConfig{MetricRateLimit: -1}, 5, 10)
+	require.ErrorContains(t, ro.Init(), "invalid 'metric_rate_limit'")
+
+	ro = NewRunningOutput(&mockOutput{}, &OutputConfig{BackpressureThreshold: 101}, 5, 10)
+	require.ErrorContains(t, ro.Init(), "invalid 'backpressure_threshold'")
+}
+
+func TestRunningOutputMetricRateLimit(t *testing.T) {
+	plugin := &mockOutput{}
+	ro := NewRunningOutput(plugin, &OutputConfig{MetricRateLimit: 3}, 10, 100)
+	require.NoError(t, ro.Init())
+	for _, m := range append(first5, next5...) {
+		ro.AddMetric(m)
+	}
+
+	// Only the metrics within the limit are written and the remaining ones
+	// must be kept for the next write
+	require.NoError(t, ro.Write())
+	require.Len(t, plugin.Metrics(), 3)
+	require.Equal(t, 7, ro.buffer.Len())
+
+	require.NoError(t, ro.WriteBatch())
+	require.Len(t, plugin.Metrics(), 3)
+	require.Equal(t, 7, ro.buffer.Len())
+	require.Equal(t, int64(14), ro.MetricsDeferred.Get())
+}
+
+func TestRunningOutputByteRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	input := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		// Each metric is 33 bytes in line-protocol
+		input = append(input, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, now))
+	}
+
+	plugin := &mockOutput{}
+	ro := NewRunningOutput(plugin, &OutputConfig{ByteRateLimit: 100}, 10, 100)
+	require.NoError(t, ro.Init())
+	for _, m := range input {
+		ro.AddMetric(m)
+	}
+
+	require.NoError(t, ro.Write())
+	testutil.RequireMetricsEqual(t, input[:3], plugin.Metrics())
+	require.Equal(t, 2, ro.buffer.Len())
+}
+
+func TestRunningOutputBackpressure(t *testing.T) {
+	ro := NewRunningOutput(
+		&mockOutput{},
+		&OutputConfig{BackpressureThreshold: 50, BackpressureDelay: 10 * time.Second},
+		1, 10,
+	)
+	require.NoError(t, ro.Init())
+
+	now := time.Now()
+	require.False(t, ro.Backpressure(now))
+
+	for _, m := range first5 {
+		ro.AddMetric(m)
+	}
+
+	// The fill level must exceed the threshold for the configured delay
+	require.False(t, ro.Backpressure(now))
+	require.False(t, ro.Backpressure(now.Add(5*time.Second)))
+	require.True(t, ro.Backpressure(now.Add(10*time.Second)))
+
+	// Backpressure is only released below half of the threshold
+	require.NoError(t, ro.WriteBatch())
+	require.NoError(t, ro.WriteBatch())
+	require.True(t, ro.Backpressure(now.Add(11*time.Second)))
+	require.NoError(t, ro.WriteBatch())
+	require.False(t, ro.Backpressure(now.Add(12*time.Second)))
+}
+
+func TestRunningOutputStatus(t *testing.T) {
+	output := &mockOutput{batchAcceptSize: -1}
+	ro := NewRunningOutput(output, &OutputConfig{}, 5, 10)
+	require.NoError(t, ro.Init())
+
+	for _, m := range first5 {
+		ro.AddMetric(m)
+	}
+
+	status := ro.Status()
+	require.Equal(t, 5, status.BufferSize)
+	require.Equal(t, 10, status.BufferLimit)
+	require.True(t, status.LastWrite.IsZero())
+
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	require.Equal(t, 2, ro.Status().ConsecutiveWriteFailures)
+
+	output.batchAcceptSize = 0
+	require.NoError(t, ro.Write())
+	status = ro.Status()
+	require.Zero(t, status.BufferSize)
+	require.False(t, status.LastWrite.IsZero())
+	require.Zero(t, status.ConsecutiveWriteFailures)
+}
+
 func TestRunningOutputRetryableStartupBehaviorDefault(t *testing.T) {
 	serr := &internal.StartupError{
 		Err:   errors.New("retryable err"),
@@ -779,6 +1029,98 @@ func TestRunningOutputWritePartialSuccessAndLoss(t *testing.T) {
 	require.Zero(t, model.buffer.Len())
 }
 
+func TestRunningOutputDeadLetter(t *testing.T) {
+	lost := 0
+	plugin := &mockOutput{
+		batchAcceptSize:  4,
+		metricFatalIndex: &lost,
+	}
+	filename := filepath.Join(t.TempDir(), "rejected.influx")
+	model := NewRunningOutput(plugin, &OutputConfig{Name: "mock", DeadLetterFile: filename}, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	target := NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "mock", Alias: "rejected"}, 5, 10)
+	require.NoError(t, target.Init())
+	model.SetDeadLetterOutput(target)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+
+	// The rejected metric should be forwarded to the dead-letter output with
+	// the rejection information added and the original metric left untouched
+	expected := first5[0].Copy()
+	expected.AddTag("rejection_reason", "size_limit")
+	expected.AddTag("rejected_by", "outputs.mock")
+	require.Equal(t, 1, target.BufferLength())
+	tx := target.buffer.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, tx.Batch)
+	require.False(t, first5[0].HasTag("rejection_reason"))
+
+	// The same metric should be written to the dead-letter file
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	buf, err := serializer.Serialize(expected)
+	require.NoError(t, err)
+	actual, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, string(buf), string(actual))
+}
+
+func TestRunningOutputDeadLetterRejectedBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "client error",
+			err:      &internal.RejectedError{Err: errors.New("received status code 400: unknown field")},
+			expected: "rejected",
+		},
+		{
+			name:     "serialization",
+			err:      fmt.Errorf("%w: invalid field", internal.ErrSerialization),
+			expected: "serialization",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &mockOutput{writeError: tt.err}
+			model := NewRunningOutput(plugin, &OutputConfig{Name: "mock"}, 5, 10)
+			require.NoError(t, model.Init())
+			require.NoError(t, model.Connect())
+			defer model.Close()
+
+			for _, metric := range first5 {
+				model.AddMetric(metric)
+			}
+
+			// Without a dead-letter destination the metrics are kept
+			require.ErrorIs(t, model.Write(), tt.err)
+			require.Equal(t, 5, model.BufferLength())
+
+			// The whole batch is passed to the dead-letter destination
+			target := NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "mock", Alias: "rejected"}, 5, 10)
+			require.NoError(t, target.Init())
+			model.SetDeadLetterOutput(target)
+
+			require.ErrorIs(t, model.Write(), tt.err)
+			require.Zero(t, model.BufferLength())
+			require.Equal(t, 5, target.BufferLength())
+			tx := target.buffer.BeginTransaction(5)
+			for _, m := range tx.Batch {
+				reason, _ := m.GetTag("rejection_reason")
+				require.Equal(t, tt.expected, reason)
+			}
+		})
+	}
+}
+
 func TestRunningOutputWriteBatchPartialSuccess(t *testing.T) {
 	plugin := &mockOutput{
 		batchAcceptSize: 4,
@@ -914,6 +1256,7 @@ type mockOutput struct {
 	// Failing output simulation
 	batchAcceptSize  int
 	metricFatalIndex *int
+	writeError       error
 
 	// Startup error simulation
 	startupError      error
@@ -946,6 +1289,9 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	defer m.Unlock()
 
 	// Simulate a failed write
+	if m.writeError != nil {
+		return m.writeError
+	}
 	if m.batchAcceptSize < 0 {
 		return errors.New("failed write")
 	}
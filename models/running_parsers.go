@@ -1,9 +1,12 @@
 package models
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -13,6 +16,9 @@ type RunningParser struct {
 	Config *ParserConfig
 	log    telegraf.Logger
 
+	decoder internal.ContentDecoder
+	mu      sync.Mutex
+
 	MetricsParsed selfstat.Stat
 	ParseTime     selfstat.Stat
 }
@@ -57,6 +63,9 @@ type ParserConfig struct {
 	DataFormat  string
 	DefaultTags map[string]string
 	LogLevel    string
+
+	// Compression of the data to remove before parsing
+	DataCompression string
 }
 
 func (r *RunningParser) LogName() string {
@@ -64,6 +73,24 @@ func (r *RunningParser) LogName() string {
 }
 
 func (r *RunningParser) Init() error {
+	switch r.Config.DataCompression {
+	case "", "none", "identity":
+	case "auto":
+		decoder, err := internal.NewDetectingDecoder()
+		if err != nil {
+			return fmt.Errorf("creating decoder failed: %w", err)
+		}
+		r.decoder = decoder
+	case "gzip", "zlib", "zstd", "snappy":
+		decoder, err := internal.NewContentDecoder(r.Config.DataCompression)
+		if err != nil {
+			return fmt.Errorf("creating decoder failed: %w", err)
+		}
+		r.decoder = decoder
+	default:
+		return fmt.Errorf("invalid 'data_compression' value %q", r.Config.DataCompression)
+	}
+
 	if p, ok := r.Parser.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -74,6 +101,19 @@ func (r *RunningParser) Init() error {
 }
 
 func (r *RunningParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	// The decoders reuse their internal buffers so we need to make sure the
+	// data is not overwritten before being parsed
+	if r.decoder != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		decoded, err := r.decoder.Decode(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing data failed: %w", err)
+		}
+		buf = decoded
+	}
+
 	start := time.Now()
 	m, err := r.Parser.Parse(buf)
 	elapsed := time.Since(start)
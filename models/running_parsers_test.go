@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// mockParser returns a single metric with the parsed data as field
+type mockParser struct{}
+
+func (*mockParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	return []telegraf.Metric{metric.New("test", nil, map[string]interface{}{"value": string(buf)}, time.Unix(0, 0))}, nil
+}
+
+func (p *mockParser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	return metrics[0], err
+}
+
+func (*mockParser) SetDefaultTags(map[string]string) {}
+
+func TestRunningParserDataCompression(t *testing.T) {
+	enc, err := internal.NewContentEncoder("gzip")
+	require.NoError(t, err)
+	compressed, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	for _, compression := range []string{"gzip", "auto"} {
+		t.Run(compression, func(t *testing.T) {
+			parser := NewRunningParser(&mockParser{}, &ParserConfig{DataFormat: "mock", DataCompression: compression})
+			require.NoError(t, parser.Init())
+
+			metrics, err := parser.Parse(compressed)
+			require.NoError(t, err)
+			require.Len(t, metrics, 1)
+			require.Equal(t, "howdy", metrics[0].Fields()["value"])
+		})
+	}
+
+	// Uncompressed data should be passed as-is in auto-detection mode
+	parser := NewRunningParser(&mockParser{}, &ParserConfig{DataFormat: "mock", DataCompression: "auto"})
+	require.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte("howdy"))
+	require.NoError(t, err)
+	require.Equal(t, "howdy", metrics[0].Fields()["value"])
+}
+
+func TestRunningParserInvalidDataCompression(t *testing.T) {
+	parser := NewRunningParser(&mockParser{}, &ParserConfig{DataFormat: "mock", DataCompression: "lzma"})
+	require.ErrorContains(t, parser.Init(), `invalid 'data_compression' value "lzma"`)
+}
@@ -109,7 +109,11 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
-	return rp.Processor.Add(m, acc)
+	span := startSpan("process", rp.LogName(), m)
+	propagateSpan(span, m)
+	err = rp.Processor.Add(m, acc)
+	endSpan(span, 1, err)
+	return err
 }
 
 func (rp *RunningProcessor) Stop() {
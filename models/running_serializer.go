@@ -22,9 +22,10 @@ type RunningSerializer struct {
 	Config     *SerializerConfig
 	log        telegraf.Logger
 
-	MetricsSerialized selfstat.Stat
-	BytesSerialized   selfstat.Stat
-	SerializationTime selfstat.Stat
+	MetricsSerialized   selfstat.Stat
+	BytesSerialized     selfstat.Stat
+	SerializationTime   selfstat.Stat
+	SerializationErrors selfstat.Stat
 }
 
 func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConfig) *RunningSerializer {
@@ -61,6 +62,11 @@ func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConf
 			"serialization_time_ns",
 			tags,
 		),
+		SerializationErrors: selfstat.Register(
+			"serializer",
+			"serialization_errors",
+			tags,
+		),
 		log: logger,
 	}
 }
@@ -80,23 +86,33 @@ func (r *RunningSerializer) Init() error {
 }
 
 func (r *RunningSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	span := startSpan("serialize", r.LogName(), metric)
 	start := time.Now()
 	buf, err := r.Serializer.Serialize(metric)
 	elapsed := time.Since(start)
+	endSpan(span, 1, err)
 	r.SerializationTime.Incr(elapsed.Nanoseconds())
 	r.MetricsSerialized.Incr(1)
 	r.BytesSerialized.Incr(int64(len(buf)))
+	if err != nil {
+		r.SerializationErrors.Incr(1)
+	}
 
 	return buf, err
 }
 
 func (r *RunningSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	span := startSpan("serialize", r.LogName(), metrics...)
 	start := time.Now()
 	buf, err := r.Serializer.SerializeBatch(metrics)
 	elapsed := time.Since(start)
+	endSpan(span, len(metrics), err)
 	r.SerializationTime.Incr(elapsed.Nanoseconds())
 	r.MetricsSerialized.Incr(int64(len(metrics)))
 	r.BytesSerialized.Incr(int64(len(buf)))
+	if err != nil {
+		r.SerializationErrors.Incr(1)
+	}
 
 	return buf, err
 }
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// maxSpanLinks limits the number of spans a batch span is linked to
+const maxSpanLinks = 128
+
+// tracerName is the name of the tracer creating the spans of the metric
+// pipeline stages
+const tracerName = "github.com/influxdata/telegraf/models"
+
+// startSpan starts a span for the given pipeline stage of the plugin handling
+// the given metrics. The span continues the trace of the metrics if they
+// share the same span context and is linked to the spans of the metrics
+// otherwise.
+func startSpan(stage, plugin string, metrics ...telegraf.Metric) trace.Span {
+	options := []trace.SpanStartOption{trace.WithAttributes(attribute.String("telegraf.plugin", plugin))}
+
+	var parent trace.SpanContext
+	seen := make(map[trace.SpanID]bool)
+	links := make([]trace.Link, 0, min(len(metrics), maxSpanLinks))
+	for _, m := range metrics {
+		sc := metric.SpanContext(m)
+		if !sc.IsValid() || seen[sc.SpanID()] {
+			continue
+		}
+		seen[sc.SpanID()] = true
+		parent = sc
+		if len(links) < maxSpanLinks {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	ctx := context.Background()
+	switch len(seen) {
+	case 0:
+	case 1:
+		ctx = trace.ContextWithSpanContext(ctx, parent)
+	default:
+		options = append(options, trace.WithLinks(links...))
+	}
+	// Use the current global tracer provider, which is a no-op unless tracing
+	// is enabled in the agent, as the provider is replaced on shutdown
+	_, span := otel.GetTracerProvider().Tracer(tracerName).Start(ctx, stage, options...)
+	return span
+}
+
+// propagateSpan attaches the context of the span to the metrics passed on to
+// the next pipeline stage
+func propagateSpan(span trace.Span, metrics ...telegraf.Metric) {
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return
+	}
+	for _, m := range metrics {
+		metric.SetSpanContext(m, sc)
+	}
+}
+
+// endSpan records the number of metrics handled and the error, if any, and
+// ends the span
+func endSpan(span trace.Span, metrics int, err error) {
+	if !span.IsRecording() {
+		span.End()
+		return
+	}
+	span.SetAttributes(attribute.Int("telegraf.metrics", metrics))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
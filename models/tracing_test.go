@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestPipelineSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	input := NewRunningInput(&mockInput{gatherReturn: errors.New("failed")}, &InputConfig{Name: "test"})
+	var acc testutil.Accumulator
+	require.ErrorContains(t, input.Gather(&acc), "failed")
+
+	output := NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "test"}, 1000, 10000)
+	output.AddMetric(testutil.TestMetric(1, "metric1"))
+	output.AddMetric(testutil.TestMetric(2, "metric2"))
+	require.NoError(t, output.Write())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "gather", spans[0].Name())
+	require.Contains(t, spans[0].Attributes(), attribute.String("telegraf.plugin", "inputs.test"))
+	require.Contains(t, spans[0].Attributes(), attribute.Int("telegraf.metrics", 0))
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+
+	require.Equal(t, "write", spans[1].Name())
+	require.Contains(t, spans[1].Attributes(), attribute.String("telegraf.plugin", "outputs.test"))
+	require.Contains(t, spans[1].Attributes(), attribute.Int("telegraf.metrics", 2))
+	require.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestPipelineSpanPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	// Gather two metrics within one gather and one outside of a gather
+	var input *RunningInput
+	var gathered []telegraf.Metric
+	plugin := &gatherFuncInput{gather: func() {
+		gathered = append(gathered, input.MakeMetric(testutil.TestMetric(1, "metric1")))
+		gathered = append(gathered, input.MakeMetric(testutil.TestMetric(2, "metric2")))
+	}}
+	input = NewRunningInput(plugin, &InputConfig{Name: "test"})
+	var acc testutil.Accumulator
+	require.NoError(t, input.Gather(&acc))
+	untraced := input.MakeMetric(testutil.TestMetric(3, "metric3"))
+	require.False(t, metric.SpanContext(untraced).IsValid())
+
+	processor := &RunningProcessor{
+		Processor: &passthroughProcessor{},
+		Config:    &ProcessorConfig{Name: "test"},
+	}
+	require.NoError(t, processor.Add(gathered[0], &acc))
+
+	// The write continues the trace of a batch from a single span
+	output := NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "test"}, 1000, 10000)
+	output.AddMetric(gathered[1])
+	require.NoError(t, output.Write())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	gather, process, write := spans[0], spans[1], spans[2]
+	require.Equal(t, "gather", gather.Name())
+	require.Equal(t, "process", process.Name())
+	require.Equal(t, "write", write.Name())
+
+	traceID := gather.SpanContext().TraceID()
+	require.Equal(t, traceID, process.SpanContext().TraceID())
+	require.Equal(t, gather.SpanContext().SpanID(), process.Parent().SpanID())
+	require.Equal(t, traceID, write.SpanContext().TraceID())
+	require.Equal(t, gather.SpanContext().SpanID(), write.Parent().SpanID())
+	require.Empty(t, write.Links())
+
+	// A batch of metrics from different stages links to the spans
+	recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	output.AddMetric(gathered[0])
+	output.AddMetric(gathered[1])
+	output.AddMetric(untraced)
+	require.NoError(t, output.Write())
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent().IsValid())
+	links := make([]trace.SpanID, 0, len(spans[0].Links()))
+	for _, link := range spans[0].Links() {
+		links = append(links, link.SpanContext.SpanID())
+	}
+	require.ElementsMatch(t, []trace.SpanID{gather.SpanContext().SpanID(), process.SpanContext().SpanID()}, links)
+}
+
+type gatherFuncInput struct {
+	gather func()
+}
+
+func (*gatherFuncInput) SampleConfig() string {
+	return ""
+}
+
+func (i *gatherFuncInput) Gather(telegraf.Accumulator) error {
+	i.gather()
+	return nil
+}
+
+type passthroughProcessor struct{}
+
+func (*passthroughProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*passthroughProcessor) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*passthroughProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	acc.AddMetric(m)
+	return nil
+}
+
+func (*passthroughProcessor) Stop() {}
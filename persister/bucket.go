@@ -0,0 +1,86 @@
+package persister
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Bucket is a key-value store holding the state of a single plugin instance.
+// It implements the telegraf.StateStore interface and is safe for concurrent
+// use.
+type Bucket struct {
+	values map[string]json.RawMessage
+
+	// Modification counter at the last change and at the last persisting
+	version   uint64
+	persisted uint64
+
+	sync.Mutex
+}
+
+// NewBucket creates an empty bucket not backed by a persister, e.g. for
+// running plugins without a state file.
+func NewBucket() *Bucket {
+	return &Bucket{values: make(map[string]json.RawMessage)}
+}
+
+func (b *Bucket) Get(key string, value interface{}) (bool, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	raw, found := b.values[key]
+	if !found {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return true, fmt.Errorf("unmarshalling state %q failed: %w", key, err)
+	}
+	return true, nil
+}
+
+func (b *Bucket) Set(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshalling state %q failed: %w", key, err)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.values[key] = raw
+	b.version++
+
+	return nil
+}
+
+func (b *Bucket) Delete(key string) {
+	b.Lock()
+	defer b.Unlock()
+
+	if _, found := b.values[key]; found {
+		delete(b.values, key)
+		b.version++
+	}
+}
+
+// serialize returns the serialized content of the bucket and its version
+func (b *Bucket) serialize() ([]byte, uint64, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	serialized, err := json.Marshal(b.values)
+	return serialized, b.version, err
+}
+
+// markPersisted records the version of the content successfully persisted
+func (b *Bucket) markPersisted(version uint64) {
+	b.Lock()
+	defer b.Unlock()
+	b.persisted = max(b.persisted, version)
+}
+
+func (b *Bucket) modified() bool {
+	b.Lock()
+	defer b.Unlock()
+	return b.version != b.persisted
+}
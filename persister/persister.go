@@ -2,9 +2,12 @@ package persister
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 )
@@ -13,25 +16,67 @@ type Persister struct {
 	Filename string
 
 	register map[string]telegraf.StatefulPlugin
+	buckets  map[string]*Bucket
+
+	// States of the last run read from the file or last persisted
+	states map[string][]byte
+
+	sync.Mutex
 }
 
 func (p *Persister) Init() error {
 	p.register = make(map[string]telegraf.StatefulPlugin)
+	p.buckets = make(map[string]*Bucket)
 
 	return nil
 }
 
 func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
+	p.Lock()
+	defer p.Unlock()
+
 	if _, found := p.register[id]; found {
 		return fmt.Errorf("plugin with ID %q already registered", id)
 	}
+	if _, found := p.buckets[id]; found {
+		return fmt.Errorf("plugin with ID %q already uses a state store", id)
+	}
 	p.register[id] = plugin
 
 	return nil
 }
 
-func (p *Persister) Load() error {
-	// Read the states from disk
+// Bucket returns the key-value store for the plugin with the given ID
+// containing the state of the last run if any. Read must be called before to
+// restore the state. Requesting the store for an ID again, e.g. for a plugin
+// removed and re-added on reload, returns the existing store.
+func (p *Persister) Bucket(id string) (*Bucket, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.buckets == nil {
+		p.buckets = make(map[string]*Bucket)
+	}
+	if bucket, found := p.buckets[id]; found {
+		return bucket, nil
+	}
+	if _, found := p.register[id]; found {
+		return nil, fmt.Errorf("plugin with ID %q already registered", id)
+	}
+
+	bucket := NewBucket()
+	if serialized, found := p.states[id]; found {
+		if err := json.Unmarshal(serialized, &bucket.values); err != nil {
+			return nil, fmt.Errorf("unmarshalling state store for %q failed: %w", id, err)
+		}
+	}
+	p.buckets[id] = bucket
+
+	return bucket, nil
+}
+
+// Read reads the states from disk without assigning them to the plugins
+func (p *Persister) Read() error {
 	in, err := os.ReadFile(p.Filename)
 	if err != nil {
 		return fmt.Errorf("reading states file failed: %w", err)
@@ -43,8 +88,29 @@ func (p *Persister) Load() error {
 		return fmt.Errorf("unmarshalling states failed: %w", err)
 	}
 
+	p.Lock()
+	p.states = states
+	p.Unlock()
+
+	return nil
+}
+
+func (p *Persister) Load() error {
+	// Read the states from disk if not done before
+	p.Lock()
+	read := p.states != nil
+	p.Unlock()
+	if !read {
+		if err := p.Read(); err != nil {
+			return err
+		}
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
 	// Get the initialized state as blueprint for unmarshalling
-	for id, serialized := range states {
+	for id, serialized := range p.states {
 		// Check if we have a plugin with that ID
 		plugin, found := p.register[id]
 		if !found {
@@ -69,8 +135,12 @@ func (p *Persister) Load() error {
 	return nil
 }
 
+// Store collects the states of all plugins and writes them to disk
 func (p *Persister) Store() error {
-	states := make(map[string][]byte)
+	p.Lock()
+	defer p.Unlock()
+
+	states := make(map[string][]byte, len(p.register)+len(p.buckets))
 
 	// Collect the states and serialize the individual data chunks
 	// to later serialize all items in the id / serialized-states map
@@ -82,20 +152,88 @@ func (p *Persister) Store() error {
 		states[id] = state
 	}
 
+	return p.storeBuckets(states)
+}
+
+// Checkpoint writes the states to disk if any state store was modified since
+// the last time. As the states of stateful plugins can only be collected
+// safely on shutdown, the states of those plugins are taken from the last run.
+func (p *Persister) Checkpoint() error {
+	p.Lock()
+	defer p.Unlock()
+
+	var modified bool
+	for _, bucket := range p.buckets {
+		if bucket.modified() {
+			modified = true
+			break
+		}
+	}
+	if !modified {
+		return nil
+	}
+
+	states := make(map[string][]byte, len(p.register)+len(p.buckets))
+	for id := range p.register {
+		if state, found := p.states[id]; found {
+			states[id] = state
+		}
+	}
+
+	return p.storeBuckets(states)
+}
+
+// storeBuckets adds the content of the state stores to the given states and
+// writes them to disk. The lock must be held by the caller.
+func (p *Persister) storeBuckets(states map[string][]byte) error {
+	versions := make(map[*Bucket]uint64, len(p.buckets))
+	for id, bucket := range p.buckets {
+		state, version, err := bucket.serialize()
+		if err != nil {
+			return fmt.Errorf("marshalling state store for id %q failed: %w", id, err)
+		}
+		states[id] = state
+		versions[bucket] = version
+	}
+
 	// Serialize the states
 	serialized, err := json.Marshal(states)
 	if err != nil {
 		return fmt.Errorf("marshalling states failed: %w", err)
 	}
 
-	// Write the states to disk
-	f, err := os.Create(p.Filename)
+	if err := p.write(serialized); err != nil {
+		return err
+	}
+
+	p.states = states
+	for bucket, version := range versions {
+		bucket.markPersisted(version)
+	}
+
+	return nil
+}
+
+// write replaces the states file atomically to not lose the previous states
+// when being interrupted
+func (p *Persister) write(serialized []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(p.Filename), filepath.Base(p.Filename)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
 	}
-	defer f.Close()
+	tmpname := f.Name()
 
-	if _, err := f.Write(serialized); err != nil {
+	_, err = f.Write(serialized)
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(tmpname, p.Filename)
+	}
+	if err != nil {
+		//nolint:errcheck // Best effort cleanup of the temporary file
+		os.Remove(tmpname)
 		return fmt.Errorf("writing states failed: %w", err)
 	}
 
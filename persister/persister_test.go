@@ -0,0 +1,120 @@
+package persister
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type statefulPlugin struct {
+	state map[string]int
+}
+
+func (p *statefulPlugin) GetState() interface{} {
+	return p.state
+}
+
+func (p *statefulPlugin) SetState(state interface{}) error {
+	p.state = state.(map[string]int)
+	return nil
+}
+
+func TestBucket(t *testing.T) {
+	bucket := NewBucket()
+
+	var offset int64
+	found, err := bucket.Get("/var/log/messages", &offset)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, bucket.Set("/var/log/messages", int64(42)))
+	found, err = bucket.Get("/var/log/messages", &offset)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, int64(42), offset)
+
+	var name string
+	_, err = bucket.Get("/var/log/messages", &name)
+	require.ErrorContains(t, err, "unmarshalling state")
+
+	bucket.Delete("/var/log/messages")
+	found, err = bucket.Get("/var/log/messages", &offset)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestStoreAndRestore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "states.json")
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.ErrorIs(t, p.Read(), os.ErrNotExist)
+
+	bucket, err := p.Bucket("a")
+	require.NoError(t, err)
+	require.NoError(t, bucket.Set("offset", 1234))
+	require.NoError(t, p.Register("b", &statefulPlugin{state: map[string]int{"count": 5}}))
+
+	// A plugin cannot use both mechanisms
+	require.ErrorContains(t, p.Register("a", &statefulPlugin{}), "already uses a state store")
+	_, err = p.Bucket("b")
+	require.ErrorContains(t, err, "already registered")
+
+	require.NoError(t, p.Store())
+
+	// Restore the states in a new run
+	restored := &Persister{Filename: filename}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.Read())
+
+	bucket, err = restored.Bucket("a")
+	require.NoError(t, err)
+	var offset int
+	found, err := bucket.Get("offset", &offset)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, 1234, offset)
+
+	plugin := &statefulPlugin{state: make(map[string]int)}
+	require.NoError(t, restored.Register("b", plugin))
+	require.NoError(t, restored.Load())
+	require.Equal(t, map[string]int{"count": 5}, plugin.state)
+}
+
+func TestCheckpoint(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "states.json")
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	bucket, err := p.Bucket("a")
+	require.NoError(t, err)
+	plugin := &statefulPlugin{state: map[string]int{"count": 5}}
+	require.NoError(t, p.Register("b", plugin))
+
+	// Nothing is written without modifications
+	require.NoError(t, p.Checkpoint())
+	require.NoFileExists(t, filename)
+
+	require.NoError(t, bucket.Set("offset", 1))
+	require.NoError(t, p.Checkpoint())
+	require.FileExists(t, filename)
+
+	// States of stateful plugins are not collected during checkpoints
+	restored := &Persister{Filename: filename}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.Read())
+	require.Contains(t, restored.states, "a")
+	require.NotContains(t, restored.states, "b")
+
+	// The file is not rewritten if the store did not change since
+	require.NoError(t, os.Remove(filename))
+	require.NoError(t, p.Checkpoint())
+	require.NoFileExists(t, filename)
+
+	// Requesting the store again returns the existing one
+	again, err := p.Bucket("a")
+	require.NoError(t, err)
+	require.Same(t, bucket, again)
+}
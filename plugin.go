@@ -57,6 +57,25 @@ type StatefulPlugin interface {
 	SetState(state interface{}) error
 }
 
+// StateStore is a key-value store for persisting small states of a plugin
+// instance, e.g. file offsets or last-seen timestamps, across Telegraf runs.
+// Plugins use the store by defining a field of this type in the plugin struct,
+// eg: State telegraf.StateStore `toml:"-"`
+// The field is set before calling the plugin's Init() function and contains
+// the states of the previous run if any. Without a state file configured, the
+// states are kept in memory only.
+type StateStore interface {
+	// Get unmarshals the value stored for the key into the given pointer and
+	// returns false if the key does not exist.
+	Get(key string, value interface{}) (bool, error)
+
+	// Set stores the JSON serializable value for the key.
+	Set(key string, value interface{}) error
+
+	// Delete removes the key from the store.
+	Delete(key string)
+}
+
 // ProbePlugin is an interface that all input/output plugins need to
 // implement in order to support the `probe` value of `startup_error_behavior`
 type ProbePlugin interface {
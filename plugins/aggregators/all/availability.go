@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.availability
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/availability" // register plugin
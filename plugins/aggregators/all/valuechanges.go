@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.valuechanges
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/valuechanges" // register plugin
@@ -0,0 +1,189 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package availability
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var defaultUpValues = []string{"true", "1", "ok", "up", "success"}
+
+type Availability struct {
+	Fields   []string `toml:"fields"`
+	UpValues []string `toml:"up_values"`
+
+	fieldFilter filter.Filter
+	upValues    map[string]bool
+	cache       map[uint64]*aggregate
+	periodEnd   time.Time
+	now         func() time.Time
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*status
+}
+
+// status keeps track of the state of a single field. The last state and its
+// timestamp are carried over to the next period to account for the time
+// between the last sample of a period and the first sample of the next one.
+type status struct {
+	lastTime time.Time
+	lastUp   bool
+	up       time.Duration
+	down     time.Duration
+	samples  int
+	upCount  int
+}
+
+func (*Availability) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Availability) Init() error {
+	if len(a.Fields) == 0 {
+		return errors.New("no fields specified")
+	}
+
+	f, err := filter.Compile(a.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	a.fieldFilter = f
+
+	if len(a.UpValues) == 0 {
+		a.UpValues = defaultUpValues
+	}
+	a.upValues = make(map[string]bool, len(a.UpValues))
+	for _, v := range a.UpValues {
+		a.upValues[strings.ToLower(v)] = true
+	}
+
+	if a.now == nil {
+		a.now = time.Now
+	}
+	a.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (a *Availability) Add(in telegraf.Metric) {
+	id := in.HashID()
+	agg, found := a.cache[id]
+	if !found {
+		agg = &aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*status),
+		}
+		a.cache[id] = agg
+	}
+
+	for _, field := range in.FieldList() {
+		if !a.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		up := a.upValues[strings.ToLower(fmt.Sprint(field.Value))]
+		s, found := agg.fields[field.Key]
+		if !found {
+			s = &status{}
+			agg.fields[field.Key] = s
+		}
+		// Ignore the state of out-of-order samples for the durations
+		if !in.Time().Before(s.lastTime) {
+			s.advance(in.Time())
+			s.lastTime = in.Time()
+			s.lastUp = up
+		}
+		s.samples++
+		if up {
+			s.upCount++
+		}
+	}
+}
+
+// SetPeriodEnd is called by the aggregator framework with the end of the
+// period before pushing.
+func (a *Availability) SetPeriodEnd(end time.Time) {
+	a.periodEnd = end
+}
+
+func (a *Availability) Push(acc telegraf.Accumulator) {
+	// Account for the time up to the end of the period unless pushing early,
+	// e.g. on shutdown
+	now := a.now()
+	if !a.periodEnd.IsZero() && a.periodEnd.Before(now) {
+		now = a.periodEnd
+	}
+
+	for id, agg := range a.cache {
+		fields := make(map[string]interface{}, 2*len(agg.fields))
+		for key, s := range agg.fields {
+			// Forget about fields not seen during the period
+			if s.samples == 0 {
+				delete(agg.fields, key)
+				continue
+			}
+			s.advance(now)
+			if !now.Before(s.lastTime) {
+				s.lastTime = now
+			}
+
+			// Fall back to the ratio of samples if no time has passed e.g.
+			// for a single sample in the period
+			availability := 100.0 * float64(s.upCount) / float64(s.samples)
+			if total := s.up + s.down; total > 0 {
+				availability = 100.0 * float64(s.up) / float64(total)
+			}
+			fields[key+"_availability"] = availability
+			fields[key+"_downtime"] = s.down.Seconds()
+		}
+
+		if len(agg.fields) == 0 {
+			delete(a.cache, id)
+			continue
+		}
+		acc.AddFields(agg.name, fields, agg.tags, now)
+	}
+}
+
+func (a *Availability) Reset() {
+	for _, agg := range a.cache {
+		for _, s := range agg.fields {
+			s.up = 0
+			s.down = 0
+			s.samples = 0
+			s.upCount = 0
+		}
+	}
+}
+
+// advance accounts the time since the last sample to the last state
+func (s *status) advance(t time.Time) {
+	if s.lastTime.IsZero() || !t.After(s.lastTime) {
+		return
+	}
+	if s.lastUp {
+		s.up += t.Sub(s.lastTime)
+	} else {
+		s.down += t.Sub(s.lastTime)
+	}
+}
+
+func init() {
+	aggregators.Add("availability", func() telegraf.Aggregator {
+		return &Availability{}
+	})
+}
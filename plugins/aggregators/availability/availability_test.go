@@ -0,0 +1,194 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitNoFields(t *testing.T) {
+	plugin := &Availability{}
+	require.ErrorContains(t, plugin.Init(), "no fields specified")
+}
+
+func TestAvailability(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(60 * time.Second)
+
+	plugin := &Availability{
+		Fields:   []string{"result_code"},
+		UpValues: []string{"0"},
+		now:      func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// Up for 30s, down for 15s and up again for the remaining 15s
+	for _, sample := range []struct {
+		offset time.Duration
+		code   int64
+	}{
+		{0, 0},
+		{10 * time.Second, 0},
+		{30 * time.Second, 2},
+		{45 * time.Second, 0},
+	} {
+		plugin.Add(metric.New(
+			"net_response",
+			map[string]string{"server": "example.org"},
+			map[string]interface{}{"result_code": sample.code, "response_time": 0.1},
+			start.Add(sample.offset),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net_response",
+			map[string]string{"server": "example.org"},
+			map[string]interface{}{
+				"result_code_availability": 75.0,
+				"result_code_downtime":     15.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestAvailabilityAcrossPeriods(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(30 * time.Second)
+
+	plugin := &Availability{
+		Fields: []string{"*_up"},
+		now:    func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// The mount goes down in the middle of the first period and stays down
+	// for the whole second period
+	plugin.Add(metric.New("nfs", map[string]string{}, map[string]interface{}{"mount_up": true}, start))
+	plugin.Add(metric.New("nfs", map[string]string{}, map[string]interface{}{"mount_up": false}, start.Add(15*time.Second)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	now = start.Add(60 * time.Second)
+	plugin.Add(metric.New("nfs", map[string]string{}, map[string]interface{}{"mount_up": false}, start.Add(45*time.Second)))
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// The series disappeared so nothing should be emitted
+	now = start.Add(90 * time.Second)
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs",
+			map[string]string{},
+			map[string]interface{}{
+				"mount_up_availability": 50.0,
+				"mount_up_downtime":     15.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs",
+			map[string]string{},
+			map[string]interface{}{
+				"mount_up_availability": 0.0,
+				"mount_up_downtime":     30.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestAvailabilitySingleSample(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &Availability{
+		Fields: []string{"status"},
+		now:    func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"status": "OK"}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{
+				"status_availability": 100.0,
+				"status_downtime":     0.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestAvailabilityPeriodEnd(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(62 * time.Second)
+
+	plugin := &Availability{
+		Fields:   []string{"result_code"},
+		UpValues: []string{"0"},
+		now:      func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// The push is delayed beyond the end of the period so the state must be
+	// accounted up to the end of the period only
+	plugin.Add(metric.New("net_response", map[string]string{}, map[string]interface{}{"result_code": 0}, start))
+	plugin.Add(metric.New("net_response", map[string]string{}, map[string]interface{}{"result_code": 2}, start.Add(30*time.Second)))
+	plugin.SetPeriodEnd(start.Add(60 * time.Second))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// Pushing before the end of the period, e.g. on shutdown, accounts up to
+	// the time of the push
+	now = start.Add(90 * time.Second)
+	plugin.Add(metric.New("net_response", map[string]string{}, map[string]interface{}{"result_code": 0}, start.Add(75*time.Second)))
+	plugin.SetPeriodEnd(start.Add(120 * time.Second))
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net_response",
+			map[string]string{},
+			map[string]interface{}{
+				"result_code_availability": 50.0,
+				"result_code_downtime":     30.0,
+			},
+			start.Add(60*time.Second),
+		),
+		metric.New(
+			"net_response",
+			map[string]string{},
+			map[string]interface{}{
+				"result_code_availability": 50.0,
+				"result_code_downtime":     15.0,
+			},
+			start.Add(90*time.Second),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
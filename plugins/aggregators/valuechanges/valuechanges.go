@@ -0,0 +1,136 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package valuechanges
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ValueChanges struct {
+	Stats []string `toml:"stats"`
+
+	first   bool
+	last    bool
+	changes bool
+
+	cache map[uint64]*series
+
+	// Last values of the series seen in the previous period to detect
+	// changes across period boundaries
+	previous map[uint64]map[string]interface{}
+}
+
+type series struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*fieldState
+}
+
+type fieldState struct {
+	first   interface{}
+	last    interface{}
+	changes int64
+}
+
+func (*ValueChanges) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *ValueChanges) Init() error {
+	if len(v.Stats) == 0 {
+		v.Stats = []string{"first", "last", "changes"}
+	}
+
+	for _, stat := range v.Stats {
+		switch stat {
+		case "first":
+			v.first = true
+		case "last":
+			v.last = true
+		case "changes":
+			v.changes = true
+		default:
+			return fmt.Errorf("invalid stat %q", stat)
+		}
+	}
+
+	v.cache = make(map[uint64]*series)
+	v.previous = make(map[uint64]map[string]interface{})
+
+	return nil
+}
+
+func (v *ValueChanges) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, found := v.cache[id]
+	if !found {
+		s = &series{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*fieldState, len(in.FieldList())),
+		}
+		v.cache[id] = s
+	}
+
+	for _, field := range in.FieldList() {
+		state, found := s.fields[field.Key]
+		if !found {
+			state = &fieldState{first: field.Value, last: field.Value}
+			// Account for a change since the last value of the previous period
+			if prev, ok := v.previous[id][field.Key]; ok && prev != field.Value {
+				state.changes = 1
+			}
+			s.fields[field.Key] = state
+			continue
+		}
+
+		if state.last != field.Value {
+			state.changes++
+		}
+		state.last = field.Value
+	}
+}
+
+func (v *ValueChanges) Push(acc telegraf.Accumulator) {
+	for _, s := range v.cache {
+		fields := make(map[string]interface{}, len(s.fields)*len(v.Stats))
+		for key, state := range s.fields {
+			if v.first {
+				fields[key+"_first"] = state.first
+			}
+			if v.last {
+				fields[key+"_last"] = state.last
+			}
+			if v.changes {
+				fields[key+"_changes"] = state.changes
+			}
+		}
+		acc.AddFields(s.name, fields, s.tags)
+	}
+}
+
+func (v *ValueChanges) Reset() {
+	// Only remember the series seen in this period to not accumulate stale
+	// series over time
+	v.previous = make(map[uint64]map[string]interface{}, len(v.cache))
+	for id, s := range v.cache {
+		last := make(map[string]interface{}, len(s.fields))
+		for key, state := range s.fields {
+			last[key] = state.last
+		}
+		v.previous[id] = last
+	}
+	v.cache = make(map[uint64]*series)
+}
+
+func init() {
+	aggregators.Add("valuechanges", func() telegraf.Aggregator {
+		return &ValueChanges{}
+	})
+}
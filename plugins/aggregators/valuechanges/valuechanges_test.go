@@ -0,0 +1,100 @@
+package valuechanges
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &ValueChanges{Stats: []string{"first", "median"}}
+	require.ErrorContains(t, plugin.Init(), `invalid stat "median"`)
+}
+
+func TestChanges(t *testing.T) {
+	plugin := &ValueChanges{}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for i, status := range []string{"up", "down", "up", "up"} {
+		plugin.Add(metric.New(
+			"interface",
+			map[string]string{"name": "eth0"},
+			map[string]interface{}{"oper_status": status, "speed": 1000},
+			now.Add(time.Duration(i)*time.Second),
+		))
+	}
+	plugin.Add(metric.New(
+		"interface",
+		map[string]string{"name": "eth1"},
+		map[string]interface{}{"oper_status": "down"},
+		now,
+	))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"interface",
+			map[string]string{"name": "eth0"},
+			map[string]interface{}{
+				"oper_status_first":   "up",
+				"oper_status_last":    "up",
+				"oper_status_changes": int64(2),
+				"speed_first":         1000,
+				"speed_last":          1000,
+				"speed_changes":       int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"interface",
+			map[string]string{"name": "eth1"},
+			map[string]interface{}{
+				"oper_status_first":   "down",
+				"oper_status_last":    "down",
+				"oper_status_changes": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestChangesAcrossPeriods(t *testing.T) {
+	plugin := &ValueChanges{Stats: []string{"changes"}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	m := metric.New("state", map[string]string{}, map[string]interface{}{"value": true}, now)
+	plugin.Add(m)
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// The change at the period boundary is accounted for in the new period
+	m = metric.New("state", map[string]string{}, map[string]interface{}{"value": false}, now.Add(time.Second))
+	plugin.Add(m)
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// Only series seen in the previous period are remembered
+	plugin.Reset()
+	m = metric.New("state", map[string]string{}, map[string]interface{}{"value": true}, now.Add(2*time.Second))
+	plugin.Add(m)
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("state", map[string]string{}, map[string]interface{}{"value_changes": int64(0)}, time.Unix(0, 0)),
+		metric.New("state", map[string]string{}, map[string]interface{}{"value_changes": int64(1)}, time.Unix(0, 0)),
+		metric.New("state", map[string]string{}, map[string]interface{}{"value_changes": int64(0)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
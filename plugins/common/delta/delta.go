@@ -0,0 +1,91 @@
+package delta
+
+import (
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Tracker converts cumulative counters into deltas between two consecutive
+// observations of the same series
+type Tracker struct {
+	expiry  time.Duration
+	entries map[key]*entry
+	now     func() time.Time
+}
+
+type key struct {
+	id    uint64
+	field string
+}
+
+type entry struct {
+	value    float64
+	ts       time.Time
+	lastSeen time.Time
+}
+
+// NewTracker creates a tracker forgetting series not observed within the
+// given expiry time
+func NewTracker(expiry time.Duration) *Tracker {
+	return &Tracker{
+		expiry:  expiry,
+		entries: make(map[key]*entry),
+		now:     time.Now,
+	}
+}
+
+// Delta returns the increase of the given field value since the last
+// observation of the metric's series as well as the time elapsed between the
+// two observations. The first observation of a series only records the value
+// and returns false. A decreasing value is treated as a counter reset and the
+// value itself is returned as increase.
+func (t *Tracker) Delta(m telegraf.Metric, field string, value float64) (float64, time.Duration, bool) {
+	k := key{id: m.HashID(), field: field}
+	now := t.now()
+
+	e, found := t.entries[k]
+	if !found {
+		t.entries[k] = &entry{value: value, ts: m.Time(), lastSeen: now}
+		return 0, 0, false
+	}
+
+	delta := value - e.value
+	if delta < 0 {
+		delta = value
+	}
+	elapsed := m.Time().Sub(e.ts)
+
+	e.value = value
+	e.ts = m.Time()
+	e.lastSeen = now
+	return delta, elapsed, true
+}
+
+// Expire removes all series not observed within the expiry time
+func (t *Tracker) Expire() {
+	if t.expiry <= 0 {
+		return
+	}
+	threshold := t.now().Add(-t.expiry)
+	for k, e := range t.entries {
+		if e.lastSeen.Before(threshold) {
+			delete(t.entries, k)
+		}
+	}
+}
+
+// IsCumulative returns true if the given field of the metric holds a
+// cumulative counter according to the metric's type. All fields of counters
+// and histograms are cumulative while only the count and sum of summaries
+// are cumulative, i.e. the quantiles are not.
+func IsCumulative(m telegraf.Metric, field string) bool {
+	switch m.Type() {
+	case telegraf.Counter, telegraf.Histogram:
+		return true
+	case telegraf.Summary:
+		return field == "count" || field == "sum" || strings.HasSuffix(field, "_count") || strings.HasSuffix(field, "_sum")
+	}
+	return false
+}
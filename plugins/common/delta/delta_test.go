@@ -0,0 +1,64 @@
+package delta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestTrackerDelta(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+
+	m1 := metric.New("requests", map[string]string{"host": "a"}, map[string]interface{}{"total": 10}, time.Unix(0, 0), telegraf.Counter)
+	m2 := metric.New("requests", map[string]string{"host": "b"}, map[string]interface{}{"total": 20}, time.Unix(0, 0), telegraf.Counter)
+
+	// The first observation only records the value
+	_, _, ok := tracker.Delta(m1, "total", 10)
+	require.False(t, ok)
+	_, _, ok = tracker.Delta(m2, "total", 20)
+	require.False(t, ok)
+
+	m1 = metric.New("requests", map[string]string{"host": "a"}, map[string]interface{}{"total": 15}, time.Unix(10, 0), telegraf.Counter)
+	delta, elapsed, ok := tracker.Delta(m1, "total", 15)
+	require.True(t, ok)
+	require.InDelta(t, 5.0, delta, 1e-9)
+	require.Equal(t, 10*time.Second, elapsed)
+
+	// Counter resets should report the new value
+	m1 = metric.New("requests", map[string]string{"host": "a"}, map[string]interface{}{"total": 3}, time.Unix(20, 0), telegraf.Counter)
+	delta, _, ok = tracker.Delta(m1, "total", 3)
+	require.True(t, ok)
+	require.InDelta(t, 3.0, delta, 1e-9)
+}
+
+func TestTrackerExpire(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := NewTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	m := metric.New("requests", map[string]string{}, map[string]interface{}{"total": 10}, time.Unix(0, 0), telegraf.Counter)
+	_, _, ok := tracker.Delta(m, "total", 10)
+	require.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	tracker.Expire()
+	_, _, ok = tracker.Delta(m, "total", 12)
+	require.False(t, ok)
+}
+
+func TestIsCumulative(t *testing.T) {
+	fields := map[string]interface{}{"value": 1}
+	require.True(t, IsCumulative(metric.New("m", nil, fields, time.Unix(0, 0), telegraf.Counter), "value"))
+	require.True(t, IsCumulative(metric.New("m", nil, fields, time.Unix(0, 0), telegraf.Histogram), "0.5"))
+	require.False(t, IsCumulative(metric.New("m", nil, fields, time.Unix(0, 0), telegraf.Gauge), "value"))
+	require.False(t, IsCumulative(metric.New("m", nil, fields, time.Unix(0, 0), telegraf.Untyped), "value"))
+
+	summary := metric.New("m", nil, fields, time.Unix(0, 0), telegraf.Summary)
+	require.True(t, IsCumulative(summary, "count"))
+	require.True(t, IsCumulative(summary, "latency_sum"))
+	require.False(t, IsCumulative(summary, "0.99"))
+}
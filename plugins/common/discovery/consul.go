@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulConfig discovers the instances of services registered in Consul
+type ConsulConfig struct {
+	Address          string            `toml:"address"`
+	Datacenter       string            `toml:"datacenter"`
+	Token            string            `toml:"token"`
+	Services         []string          `toml:"services"`
+	Tag              string            `toml:"tag"`
+	IncludeUnhealthy bool              `toml:"include_unhealthy"`
+	Tags             map[string]string `toml:"tags"`
+}
+
+type consulProvider struct {
+	cfg    *ConsulConfig
+	client *api.Client
+}
+
+func (c *ConsulConfig) provider() (Provider, error) {
+	if len(c.Services) == 0 {
+		return nil, errors.New("missing 'services'")
+	}
+
+	cfg := api.DefaultConfig()
+	if c.Address != "" {
+		cfg.Address = c.Address
+	}
+	cfg.Datacenter = c.Datacenter
+	cfg.Token = c.Token
+
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating client failed: %w", err)
+	}
+	return &consulProvider{cfg: c, client: client}, nil
+}
+
+func (p *consulProvider) Discover(ctx context.Context) ([]Target, error) {
+	opts := (&api.QueryOptions{Datacenter: p.cfg.Datacenter}).WithContext(ctx)
+
+	var targets []Target
+	for _, service := range p.cfg.Services {
+		entries, _, err := p.client.Health().Service(service, p.cfg.Tag, !p.cfg.IncludeUnhealthy, opts)
+		if err != nil {
+			return nil, fmt.Errorf("querying service %q failed: %w", service, err)
+		}
+		for _, e := range entries {
+			if e.Service == nil {
+				continue
+			}
+			host := e.Service.Address
+			if host == "" && e.Node != nil {
+				host = e.Node.Address
+			}
+			if host == "" {
+				continue
+			}
+			address := host
+			if e.Service.Port > 0 {
+				address = net.JoinHostPort(host, strconv.Itoa(e.Service.Port))
+			}
+
+			tags := map[string]string{"consul_service": service}
+			if e.Node != nil {
+				tags["consul_node"] = e.Node.Node
+			}
+			targets = append(targets, newTarget(address, withTags(p.cfg.Tags, tags)))
+		}
+	}
+	return targets, nil
+}
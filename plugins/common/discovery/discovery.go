@@ -0,0 +1,196 @@
+// Package discovery provides the discovery of targets, e.g. hosts or
+// services, from different sources for use in plugins.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Target is a discovered endpoint
+type Target struct {
+	// Address in the form "host:port" or "host" if no port is known
+	Address string
+	Host    string
+	Port    uint16
+	Tags    map[string]string
+}
+
+func newTarget(address string, tags map[string]string) Target {
+	t := Target{Address: address, Host: address, Tags: tags}
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		t.Host = host
+		if p, err := strconv.ParseUint(port, 10, 16); err == nil {
+			t.Port = uint16(p)
+		}
+	}
+	if t.Tags == nil {
+		t.Tags = make(map[string]string)
+	}
+	return t
+}
+
+// ID identifies the target including its tags, so changed tags are handled
+// as removal and addition of the target
+func (t *Target) ID() string {
+	keys := make([]string, 0, len(t.Tags))
+	for k := range t.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(t.Address)
+	for _, k := range keys {
+		b.WriteString("\x00" + k + "=" + t.Tags[k])
+	}
+	return b.String()
+}
+
+// Provider discovers targets from a single source
+type Provider interface {
+	Discover(ctx context.Context) ([]Target, error)
+}
+
+// Config contains the settings of all discovery sources to be embedded in
+// plugins
+type Config struct {
+	File       []FileConfig       `toml:"file"`
+	DNSSRV     []DNSSRVConfig     `toml:"dns_srv"`
+	Consul     []ConsulConfig     `toml:"consul"`
+	Kubernetes []KubernetesConfig `toml:"kubernetes"`
+}
+
+// Providers creates the providers for all configured sources
+func (c *Config) Providers() ([]Provider, error) {
+	providers := make([]Provider, 0, len(c.File)+len(c.DNSSRV)+len(c.Consul)+len(c.Kubernetes))
+	for i := range c.File {
+		p, err := c.File[i].provider()
+		if err != nil {
+			return nil, fmt.Errorf("file: %w", err)
+		}
+		providers = append(providers, p)
+	}
+	for i := range c.DNSSRV {
+		p, err := c.DNSSRV[i].provider()
+		if err != nil {
+			return nil, fmt.Errorf("dns_srv: %w", err)
+		}
+		providers = append(providers, p)
+	}
+	for i := range c.Consul {
+		p, err := c.Consul[i].provider()
+		if err != nil {
+			return nil, fmt.Errorf("consul: %w", err)
+		}
+		providers = append(providers, p)
+	}
+	for i := range c.Kubernetes {
+		p, err := c.Kubernetes[i].provider()
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes: %w", err)
+		}
+		providers = append(providers, p)
+	}
+
+	if len(providers) == 0 {
+		return nil, errors.New("no discovery source configured")
+	}
+	return providers, nil
+}
+
+// Watcher periodically queries the providers and calls the hooks for added
+// and removed targets
+type Watcher struct {
+	Providers []Provider
+	OnAdd     func(Target)
+	OnRemove  func(Target)
+	Log       telegraf.Logger
+
+	known map[string]Target
+}
+
+// Run refreshes the targets immediately and then in the given interval until
+// the context is cancelled
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Refresh(ctx); err != nil && ctx.Err() == nil {
+			w.Log.Errorf("Discovering targets failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh queries all providers and calls the hooks for the differences to
+// the previous refresh. The known targets are kept if any of the providers
+// fails to avoid flapping targets.
+func (w *Watcher) Refresh(ctx context.Context) error {
+	current := make(map[string]Target)
+	for _, p := range w.Providers {
+		targets, err := p.Discover(ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			current[t.ID()] = t
+		}
+	}
+
+	// Call the hooks in a stable order
+	removed := make([]string, 0)
+	for k := range w.known {
+		if _, found := current[k]; !found {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(removed)
+	added := make([]string, 0)
+	for k := range current {
+		if _, found := w.known[k]; !found {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+
+	for _, k := range removed {
+		if w.OnRemove != nil {
+			w.OnRemove(w.known[k])
+		}
+	}
+	for _, k := range added {
+		if w.OnAdd != nil {
+			w.OnAdd(current[k])
+		}
+	}
+	w.known = current
+
+	if len(added) > 0 || len(removed) > 0 {
+		w.Log.Debugf("Discovered %d targets, %d added and %d removed", len(current), len(added), len(removed))
+	}
+	return nil
+}
+
+// withTags returns the union of the given tag sets with later sets taking
+// precedence
+func withTags(sets ...map[string]string) map[string]string {
+	tags := make(map[string]string)
+	for _, s := range sets {
+		maps.Copy(tags, s)
+	}
+	return tags
+}
@@ -0,0 +1,239 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type staticProvider struct {
+	targets []Target
+	err     error
+}
+
+func (p *staticProvider) Discover(context.Context) ([]Target, error) {
+	return p.targets, p.err
+}
+
+func TestProvidersRequireSource(t *testing.T) {
+	var cfg Config
+	_, err := cfg.Providers()
+	require.ErrorContains(t, err, "no discovery source configured")
+
+	cfg.DNSSRV = []DNSSRVConfig{{}}
+	_, err = cfg.Providers()
+	require.ErrorContains(t, err, "dns_srv: missing 'names'")
+}
+
+func TestWatcher(t *testing.T) {
+	provider := &staticProvider{
+		targets: []Target{
+			newTarget("a:80", nil),
+			newTarget("b:80", map[string]string{"env": "prod"}),
+		},
+	}
+
+	var added, removed []string
+	w := &Watcher{
+		Providers: []Provider{provider},
+		OnAdd:     func(t Target) { added = append(added, t.Address) },
+		OnRemove:  func(t Target) { removed = append(removed, t.Address) },
+		Log:       testutil.Logger{},
+	}
+
+	require.NoError(t, w.Refresh(context.Background()))
+	require.Equal(t, []string{"a:80", "b:80"}, added)
+	require.Empty(t, removed)
+
+	// Unchanged targets must not call any hook
+	added = nil
+	require.NoError(t, w.Refresh(context.Background()))
+	require.Empty(t, added)
+	require.Empty(t, removed)
+
+	// Changed tags result in a removal and addition of the target
+	provider.targets = []Target{
+		newTarget("b:80", map[string]string{"env": "dev"}),
+		newTarget("c:80", nil),
+	}
+	require.NoError(t, w.Refresh(context.Background()))
+	require.Equal(t, []string{"b:80", "c:80"}, added)
+	require.Equal(t, []string{"a:80", "b:80"}, removed)
+
+	// Failing providers keep the known targets
+	added, removed = nil, nil
+	provider.err = errors.New("unavailable")
+	require.ErrorContains(t, w.Refresh(context.Background()), "unavailable")
+	require.Empty(t, added)
+	require.Empty(t, removed)
+}
+
+func TestTarget(t *testing.T) {
+	target := newTarget("[fd00::1]:8080", nil)
+	require.Equal(t, "fd00::1", target.Host)
+	require.Equal(t, uint16(8080), target.Port)
+	require.NotNil(t, target.Tags)
+
+	target = newTarget("example.com", nil)
+	require.Equal(t, "example.com", target.Host)
+	require.Zero(t, target.Port)
+}
+
+func TestFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "targets.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`[
+		{"targets": ["10.0.0.1:9100", "10.0.0.2:9100"], "labels": {"env": "prod"}},
+		{"targets": ["10.0.0.3:9100"]}
+	]`), 0600))
+
+	cfg := &FileConfig{Files: []string{fn}, Tags: map[string]string{"source": "file", "env": "unknown"}}
+	p, err := cfg.provider()
+	require.NoError(t, err)
+
+	targets, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Target{
+		{Address: "10.0.0.1:9100", Host: "10.0.0.1", Port: 9100, Tags: map[string]string{"source": "file", "env": "prod"}},
+		{Address: "10.0.0.2:9100", Host: "10.0.0.2", Port: 9100, Tags: map[string]string{"source": "file", "env": "prod"}},
+		{Address: "10.0.0.3:9100", Host: "10.0.0.3", Port: 9100, Tags: map[string]string{"source": "file", "env": "unknown"}},
+	}, targets)
+
+	require.NoError(t, os.WriteFile(fn, []byte(`{"targets": []}`), 0600))
+	_, err = p.Discover(context.Background())
+	require.ErrorContains(t, err, "parsing")
+}
+
+func TestDNSSRV(t *testing.T) {
+	p := &dnsSRVProvider{
+		cfg: &DNSSRVConfig{Names: []string{"_http._tcp.example.com"}},
+		lookup: func(_ context.Context, name string) ([]*net.SRV, error) {
+			require.Equal(t, "_http._tcp.example.com", name)
+			return []*net.SRV{
+				{Target: "web1.example.com.", Port: 8080},
+				{Target: "web2.example.com.", Port: 8081},
+			}, nil
+		},
+	}
+
+	targets, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	tags := map[string]string{"srv_name": "_http._tcp.example.com"}
+	require.Equal(t, []Target{
+		{Address: "web1.example.com:8080", Host: "web1.example.com", Port: 8080, Tags: tags},
+		{Address: "web2.example.com:8081", Host: "web2.example.com", Port: 8081, Tags: tags},
+	}, targets)
+}
+
+func TestConsul(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/web" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.RawQuery
+		if _, err := w.Write([]byte(`[
+			{"Node": {"Node": "node1", "Address": "10.0.0.1"}, "Service": {"Service": "web", "Port": 80}},
+			{"Node": {"Node": "node2", "Address": "10.0.0.2"}, "Service": {"Service": "web", "Address": "10.1.0.2", "Port": 8080}}
+		]`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &ConsulConfig{
+		Address:    server.Listener.Addr().String(),
+		Datacenter: "dc1",
+		Services:   []string{"web"},
+		Tag:        "v1",
+	}
+	p, err := cfg.provider()
+	require.NoError(t, err)
+
+	targets, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, query, "passing=1")
+	require.Contains(t, query, "tag=v1")
+	require.Contains(t, query, "dc=dc1")
+	require.Equal(t, []Target{
+		{
+			Address: "10.0.0.1:80",
+			Host:    "10.0.0.1",
+			Port:    80,
+			Tags:    map[string]string{"consul_service": "web", "consul_node": "node1"},
+		},
+		{
+			Address: "10.1.0.2:8080",
+			Host:    "10.1.0.2",
+			Port:    8080,
+			Tags:    map[string]string{"consul_service": "web", "consul_node": "node2"},
+		},
+	}, targets)
+}
+
+func TestKubernetes(t *testing.T) {
+	ready, notReady := true, false
+	node := "worker1"
+	httpName, metricsName := "http", "metrics"
+	httpPort, metricsPort := int32(80), int32(9100)
+
+	p := &kubernetesProvider{
+		cfg: &KubernetesConfig{Namespace: "monitoring", Services: []string{"web"}, PortName: "metrics"},
+		list: func(_ context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+			require.Equal(t, "monitoring", namespace)
+			require.Equal(t, "web", service)
+			return []discoveryv1.EndpointSlice{
+				{
+					Ports: []discoveryv1.EndpointPort{
+						{Name: &httpName, Port: &httpPort},
+						{Name: &metricsName, Port: &metricsPort},
+					},
+					Endpoints: []discoveryv1.Endpoint{
+						{
+							Addresses:  []string{"10.244.0.5"},
+							Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+							NodeName:   &node,
+							TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+						},
+						{
+							Addresses:  []string{"10.244.0.6"},
+							Conditions: discoveryv1.EndpointConditions{Ready: &notReady},
+						},
+					},
+				},
+				{
+					// Slices without the requested port are ignored
+					Ports:     []discoveryv1.EndpointPort{{Name: &httpName, Port: &httpPort}},
+					Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.244.0.7"}}},
+				},
+			}, nil
+		},
+	}
+
+	targets, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Target{
+		{
+			Address: "10.244.0.5:9100",
+			Host:    "10.244.0.5",
+			Port:    9100,
+			Tags: map[string]string{
+				"namespace": "monitoring",
+				"service":   "web",
+				"pod":       "web-0",
+				"node":      "worker1",
+			},
+		},
+	}, targets)
+}
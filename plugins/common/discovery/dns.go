@@ -0,0 +1,51 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DNSSRVConfig discovers targets from DNS SRV records. The records are
+// queried by their full name, e.g. "_http._tcp.example.com".
+type DNSSRVConfig struct {
+	Names []string          `toml:"names"`
+	Tags  map[string]string `toml:"tags"`
+}
+
+type dnsSRVProvider struct {
+	cfg    *DNSSRVConfig
+	lookup func(ctx context.Context, name string) ([]*net.SRV, error)
+}
+
+func (c *DNSSRVConfig) provider() (Provider, error) {
+	if len(c.Names) == 0 {
+		return nil, errors.New("missing 'names'")
+	}
+	return &dnsSRVProvider{
+		cfg: c,
+		lookup: func(ctx context.Context, name string) ([]*net.SRV, error) {
+			_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+			return records, err
+		},
+	}, nil
+}
+
+func (p *dnsSRVProvider) Discover(ctx context.Context) ([]Target, error) {
+	var targets []Target
+	for _, name := range p.cfg.Names {
+		records, err := p.lookup(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("looking up %q failed: %w", name, err)
+		}
+		for _, r := range records {
+			host := strings.TrimSuffix(r.Target, ".")
+			address := net.JoinHostPort(host, strconv.Itoa(int(r.Port)))
+			targets = append(targets, newTarget(address, withTags(p.cfg.Tags, map[string]string{"srv_name": name})))
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,58 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FileConfig discovers targets from files in the Prometheus file-based
+// service-discovery format, i.e. a JSON array of objects like
+//
+//	{"targets": ["host:port", ...], "labels": {"name": "value", ...}}
+//
+// with the labels being added as tags of the targets.
+type FileConfig struct {
+	Files []string          `toml:"files"`
+	Tags  map[string]string `toml:"tags"`
+}
+
+type fileGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type fileProvider struct {
+	cfg *FileConfig
+}
+
+func (c *FileConfig) provider() (Provider, error) {
+	if len(c.Files) == 0 {
+		return nil, errors.New("missing 'files'")
+	}
+	return &fileProvider{cfg: c}, nil
+}
+
+func (p *fileProvider) Discover(context.Context) ([]Target, error) {
+	var targets []Target
+	for _, fn := range p.cfg.Files {
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("reading %q failed: %w", fn, err)
+		}
+
+		var groups []fileGroup
+		if err := json.Unmarshal(buf, &groups); err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", fn, err)
+		}
+
+		for _, g := range groups {
+			for _, address := range g.Targets {
+				targets = append(targets, newTarget(address, withTags(p.cfg.Tags, g.Labels)))
+			}
+		}
+	}
+	return targets, nil
+}
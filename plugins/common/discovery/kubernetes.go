@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// KubernetesConfig discovers the ready endpoints of Kubernetes services
+type KubernetesConfig struct {
+	KubeConfig string            `toml:"kube_config"`
+	Namespace  string            `toml:"namespace"`
+	Services   []string          `toml:"services"`
+	PortName   string            `toml:"port_name"`
+	Tags       map[string]string `toml:"tags"`
+}
+
+type kubernetesProvider struct {
+	cfg  *KubernetesConfig
+	list func(ctx context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error)
+}
+
+func (c *KubernetesConfig) provider() (Provider, error) {
+	if len(c.Services) == 0 {
+		return nil, errors.New("missing 'services'")
+	}
+	if c.Namespace == "" {
+		c.Namespace = "default"
+	}
+
+	var cfg *rest.Config
+	var err error
+	if c.KubeConfig == "" {
+		cfg, err = rest.InClusterConfig()
+	} else {
+		cfg, err = clientcmd.BuildConfigFromFlags("", c.KubeConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading configuration failed: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating client failed: %w", err)
+	}
+
+	return &kubernetesProvider{
+		cfg: c,
+		list: func(ctx context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+			slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: discoveryv1.LabelServiceName + "=" + service,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return slices.Items, nil
+		},
+	}, nil
+}
+
+func (p *kubernetesProvider) Discover(ctx context.Context) ([]Target, error) {
+	var targets []Target
+	for _, service := range p.cfg.Services {
+		slices, err := p.list(ctx, p.cfg.Namespace, service)
+		if err != nil {
+			return nil, fmt.Errorf("listing endpoints of service %q failed: %w", service, err)
+		}
+
+		for _, slice := range slices {
+			port, found := p.port(slice.Ports)
+			if !found {
+				continue
+			}
+			for _, e := range slice.Endpoints {
+				// Endpoints with unknown readiness are considered ready
+				if e.Conditions.Ready != nil && !*e.Conditions.Ready {
+					continue
+				}
+
+				tags := map[string]string{
+					"namespace": p.cfg.Namespace,
+					"service":   service,
+				}
+				if e.TargetRef != nil && e.TargetRef.Kind == "Pod" {
+					tags["pod"] = e.TargetRef.Name
+				}
+				if e.NodeName != nil {
+					tags["node"] = *e.NodeName
+				}
+
+				for _, addr := range e.Addresses {
+					address := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+					targets = append(targets, newTarget(address, withTags(p.cfg.Tags, tags)))
+				}
+			}
+		}
+	}
+	return targets, nil
+}
+
+// port returns the port matching the configured name or the first port if no
+// name is configured
+func (p *kubernetesProvider) port(ports []discoveryv1.EndpointPort) (int32, bool) {
+	for _, port := range ports {
+		if port.Port == nil {
+			continue
+		}
+		if p.cfg.PortName == "" || (port.Name != nil && *port.Name == p.cfg.PortName) {
+			return *port.Port, true
+		}
+	}
+	return 0, false
+}
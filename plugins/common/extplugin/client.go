@@ -0,0 +1,236 @@
+package extplugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+)
+
+// AddressEnv is the environment variable telling a plugin started by Telegraf
+// the address to listen on
+const AddressEnv = "TELEGRAF_PLUGIN_ADDRESS"
+
+// Config of the connection to an external plugin
+type Config struct {
+	Command        []string          `toml:"command"`
+	Environment    []string          `toml:"environment"`
+	RestartDelay   config.Duration   `toml:"restart_delay"`
+	MaxRestarts    int               `toml:"max_restarts"`
+	RestartWindow  config.Duration   `toml:"restart_window"`
+	Address        string            `toml:"address"`
+	StartupTimeout config.Duration   `toml:"startup_timeout"`
+	Timeout        config.Duration   `toml:"timeout"`
+	Settings       map[string]string `toml:"settings"`
+	process.Limits
+}
+
+// Client is a connection to an external plugin
+type Client struct {
+	Conn    *grpc.ClientConn
+	Timeout time.Duration
+
+	pluginType     pluginv1.PluginType
+	settings       map[string]string
+	startupTimeout time.Duration
+	log            telegraf.Logger
+
+	process *process.Process
+	tmpdir  string
+
+	// Information of the plugin from the last handshake, reset if the plugin
+	// became unavailable to repeat the handshake with a restarted plugin
+	info *pluginv1.HandshakeResponse
+	sync.Mutex
+}
+
+// Validate checks the configuration
+func (cfg *Config) Validate() error {
+	if len(cfg.Command) == 0 && cfg.Address == "" {
+		return errors.New("either 'command' or 'address' required")
+	}
+	if cfg.StartupTimeout <= 0 {
+		return errors.New("'startup_timeout' must be positive")
+	}
+	if cfg.Timeout <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+	return cfg.Limits.Validate()
+}
+
+// Connect starts the plugin process if a command is configured, connects to
+// the plugin and performs the handshake
+func (cfg *Config) Connect(pluginType pluginv1.PluginType, log telegraf.Logger) (*Client, error) {
+	c := &Client{
+		Timeout:        time.Duration(cfg.Timeout),
+		pluginType:     pluginType,
+		settings:       cfg.Settings,
+		startupTimeout: time.Duration(cfg.StartupTimeout),
+		log:            log,
+	}
+
+	address := cfg.Address
+	if len(cfg.Command) > 0 {
+		if address == "" {
+			dir, err := os.MkdirTemp("", "telegraf-plugin-")
+			if err != nil {
+				return nil, fmt.Errorf("creating socket directory failed: %w", err)
+			}
+			c.tmpdir = dir
+			address = "unix://" + filepath.Join(dir, "plugin.sock")
+		}
+
+		envs := append(slices.Clone(cfg.Environment), AddressEnv+"="+address)
+		p, err := process.New(cfg.Command, envs)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("creating process failed: %w", err)
+		}
+		p.Log = log
+		p.RestartDelay = time.Duration(cfg.RestartDelay)
+		p.MaxRestarts = cfg.MaxRestarts
+		p.RestartWindow = time.Duration(cfg.RestartWindow)
+		p.Limits = cfg.Limits
+		p.ReadStdoutFn = func(r io.Reader) { readLog(r, log.Info) }
+		p.ReadStderrFn = func(r io.Reader) { readLog(r, log.Error) }
+		if err := p.Start(); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("starting process failed: %w", err)
+		}
+		c.process = p
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("creating connection to %q failed: %w", address, err)
+	}
+	c.Conn = conn
+
+	if _, err := c.Handshake(context.Background()); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Handshake returns the information of the plugin, performing the handshake
+// if it was not done yet or the plugin became unavailable since then. Calls
+// wait for the plugin to become available up to the startup timeout.
+func (c *Client) Handshake(ctx context.Context) (*pluginv1.HandshakeResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.info != nil {
+		return c.info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.startupTimeout)
+	defer cancel()
+
+	request := &pluginv1.HandshakeRequest{
+		ProtocolVersion: pluginv1.ProtocolVersion_PROTOCOL_VERSION_1,
+		PluginType:      c.pluginType,
+		TelegrafVersion: internal.Version,
+		Settings:        c.settings,
+	}
+	response, err := pluginv1.NewPluginClient(c.Conn).Handshake(ctx, request, grpc.WaitForReady(true))
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, fmt.Errorf("plugin refused handshake: %s", status.Convert(err).Message())
+		}
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+
+	if v := response.GetProtocolVersion(); v != pluginv1.ProtocolVersion_PROTOCOL_VERSION_1 {
+		return nil, fmt.Errorf("unsupported protocol version %d", v)
+	}
+	c.log.Debugf("Connected to plugin %q version %q", response.GetName(), response.GetVersion())
+	c.info = response
+
+	return c.info, nil
+}
+
+// Check inspects the error of a call to the plugin and requires a new
+// handshake if the plugin became unavailable, e.g. due to a restart
+func (c *Client) Check(err error) error {
+	if status.Code(err) == codes.Unavailable {
+		c.Lock()
+		c.info = nil
+		c.Unlock()
+	}
+	return err
+}
+
+// Split splits the metrics into batches respecting the maximum batch size of
+// the plugin
+func (c *Client) Split(metrics []*pluginv1.Metric) [][]*pluginv1.Metric {
+	c.Lock()
+	var size int
+	if c.info != nil {
+		size = int(c.info.GetMaxBatchSize())
+	}
+	c.Unlock()
+
+	if size == 0 || len(metrics) <= size {
+		return [][]*pluginv1.Metric{metrics}
+	}
+	return slices.Collect(slices.Chunk(metrics, size))
+}
+
+// Close closes the connection and stops the plugin process
+func (c *Client) Close() {
+	if c.Conn != nil {
+		if err := c.Conn.Close(); err != nil {
+			c.log.Errorf("Closing connection failed: %v", err)
+		}
+	}
+	if c.process != nil {
+		c.process.Stop()
+	}
+	if c.tmpdir != "" {
+		if err := os.RemoveAll(c.tmpdir); err != nil {
+			c.log.Errorf("Removing socket directory failed: %v", err)
+		}
+	}
+}
+
+// IsTemporary returns true if the error indicates a temporary failure and the
+// operation should be retried
+func IsTemporary(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// IsRejected returns true if the plugin rejected the data of the request
+func IsRejected(err error) bool {
+	return status.Code(err) == codes.InvalidArgument
+}
+
+func readLog(r io.Reader, logf func(args ...interface{})) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logf(scanner.Text())
+	}
+}
@@ -0,0 +1,132 @@
+package extplugin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+)
+
+var toProtoType = map[telegraf.ValueType]pluginv1.MetricType{
+	telegraf.Untyped:   pluginv1.MetricType_METRIC_TYPE_UNTYPED,
+	telegraf.Counter:   pluginv1.MetricType_METRIC_TYPE_COUNTER,
+	telegraf.Gauge:     pluginv1.MetricType_METRIC_TYPE_GAUGE,
+	telegraf.Summary:   pluginv1.MetricType_METRIC_TYPE_SUMMARY,
+	telegraf.Histogram: pluginv1.MetricType_METRIC_TYPE_HISTOGRAM,
+}
+
+var fromProtoType = map[pluginv1.MetricType]telegraf.ValueType{
+	pluginv1.MetricType_METRIC_TYPE_UNTYPED:   telegraf.Untyped,
+	pluginv1.MetricType_METRIC_TYPE_COUNTER:   telegraf.Counter,
+	pluginv1.MetricType_METRIC_TYPE_GAUGE:     telegraf.Gauge,
+	pluginv1.MetricType_METRIC_TYPE_SUMMARY:   telegraf.Summary,
+	pluginv1.MetricType_METRIC_TYPE_HISTOGRAM: telegraf.Histogram,
+}
+
+// ToProto converts the given metric to its protocol representation
+func ToProto(m telegraf.Metric) *pluginv1.Metric {
+	pm := &pluginv1.Metric{
+		Name:      m.Name(),
+		Tags:      make([]*pluginv1.Tag, 0, len(m.TagList())),
+		Fields:    make([]*pluginv1.Field, 0, len(m.FieldList())),
+		Timestamp: m.Time().UnixNano(),
+		Type:      toProtoType[m.Type()],
+	}
+	for _, tag := range m.TagList() {
+		pm.Tags = append(pm.Tags, &pluginv1.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	for _, field := range m.FieldList() {
+		f := &pluginv1.Field{Key: field.Key}
+		switch v := field.Value.(type) {
+		case float64:
+			f.Value = &pluginv1.Field_DoubleValue{DoubleValue: v}
+		case int64:
+			f.Value = &pluginv1.Field_IntValue{IntValue: v}
+		case uint64:
+			f.Value = &pluginv1.Field_UintValue{UintValue: v}
+		case string:
+			f.Value = &pluginv1.Field_StringValue{StringValue: v}
+		case bool:
+			f.Value = &pluginv1.Field_BoolValue{BoolValue: v}
+		default:
+			continue
+		}
+		pm.Fields = append(pm.Fields, f)
+	}
+	return pm
+}
+
+// ToProtoBatch converts the given metrics to their protocol representation
+func ToProtoBatch(metrics []telegraf.Metric) []*pluginv1.Metric {
+	batch := make([]*pluginv1.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		batch = append(batch, ToProto(m))
+	}
+	return batch
+}
+
+// FromProto converts the protocol representation to a metric
+func FromProto(pm *pluginv1.Metric) (telegraf.Metric, error) {
+	if pm.GetName() == "" {
+		return nil, errors.New("metric without name")
+	}
+	if len(pm.GetFields()) == 0 {
+		return nil, fmt.Errorf("metric %q without fields", pm.GetName())
+	}
+
+	tags := make(map[string]string, len(pm.GetTags()))
+	for _, tag := range pm.GetTags() {
+		tags[tag.GetKey()] = tag.GetValue()
+	}
+
+	fields := make(map[string]interface{}, len(pm.GetFields()))
+	for _, field := range pm.GetFields() {
+		switch v := field.GetValue().(type) {
+		case *pluginv1.Field_DoubleValue:
+			fields[field.GetKey()] = v.DoubleValue
+		case *pluginv1.Field_IntValue:
+			fields[field.GetKey()] = v.IntValue
+		case *pluginv1.Field_UintValue:
+			fields[field.GetKey()] = v.UintValue
+		case *pluginv1.Field_StringValue:
+			fields[field.GetKey()] = v.StringValue
+		case *pluginv1.Field_BoolValue:
+			fields[field.GetKey()] = v.BoolValue
+		default:
+			return nil, fmt.Errorf("field %q of metric %q without value", field.GetKey(), pm.GetName())
+		}
+	}
+
+	var tm time.Time
+	if pm.GetTimestamp() != 0 {
+		tm = time.Unix(0, pm.GetTimestamp())
+	} else {
+		tm = time.Now()
+	}
+
+	vt, found := fromProtoType[pm.GetType()]
+	if !found {
+		return nil, fmt.Errorf("metric %q has invalid type %d", pm.GetName(), pm.GetType())
+	}
+
+	return metric.New(pm.GetName(), tags, fields, tm, vt), nil
+}
+
+// FromProtoBatch converts the protocol representation to metrics, invalid
+// metrics are skipped and reported in the returned errors
+func FromProtoBatch(batch []*pluginv1.Metric) ([]telegraf.Metric, []error) {
+	metrics := make([]telegraf.Metric, 0, len(batch))
+	var errs []error
+	for _, pm := range batch {
+		m, err := FromProto(pm)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, errs
+}
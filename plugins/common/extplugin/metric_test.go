@@ -0,0 +1,75 @@
+package extplugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRoundTrip(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{
+				"usage":   12.5,
+				"count":   int64(-3),
+				"total":   uint64(42),
+				"state":   "idle",
+				"enabled": true,
+			},
+			time.Unix(1700000000, 123),
+			telegraf.Counter,
+		),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": 1.0}, time.Unix(1700000000, 0), telegraf.Histogram),
+	}
+
+	actual, errs := FromProtoBatch(ToProtoBatch(metrics))
+	require.Empty(t, errs)
+	testutil.RequireMetricsEqual(t, metrics, actual)
+}
+
+func TestFromProtoInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   *pluginv1.Metric
+		expected string
+	}{
+		{
+			name:     "no name",
+			metric:   &pluginv1.Metric{Fields: []*pluginv1.Field{{Key: "value", Value: &pluginv1.Field_IntValue{IntValue: 1}}}},
+			expected: "metric without name",
+		},
+		{
+			name:     "no fields",
+			metric:   &pluginv1.Metric{Name: "test"},
+			expected: `metric "test" without fields`,
+		},
+		{
+			name:     "field without value",
+			metric:   &pluginv1.Metric{Name: "test", Fields: []*pluginv1.Field{{Key: "value"}}},
+			expected: `field "value" of metric "test" without value`,
+		},
+		{
+			name: "invalid type",
+			metric: &pluginv1.Metric{
+				Name:   "test",
+				Fields: []*pluginv1.Field{{Key: "value", Value: &pluginv1.Field_IntValue{IntValue: 1}}},
+				Type:   42,
+			},
+			expected: `metric "test" has invalid type 42`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromProto(tt.metric)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
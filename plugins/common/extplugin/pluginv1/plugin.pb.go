@@ -0,0 +1,1226 @@
+// Protocol between Telegraf and external plugins.
+//
+// External plugins are gRPC servers implementing the Plugin service and one
+// of the Input, Processor or Output services. Telegraf connects to the plugin,
+// performs the handshake and then uses the service matching the plugin type.
+//
+// Errors are reported using the gRPC status codes:
+//   - UNAVAILABLE, RESOURCE_EXHAUSTED and DEADLINE_EXCEEDED are temporary and
+//     the operation is retried later
+//   - INVALID_ARGUMENT rejects the data of the request, e.g. an output
+//     rejecting a batch of metrics, which is then dropped
+//   - FAILED_PRECONDITION during the handshake indicates an incompatible
+//     protocol version or configuration and stops the plugin
+//   - all other codes are reported as errors
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: pluginv1/plugin.proto
+
+package pluginv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Version of the protocol defined in this file
+type ProtocolVersion int32
+
+const (
+	ProtocolVersion_PROTOCOL_VERSION_UNSPECIFIED ProtocolVersion = 0
+	ProtocolVersion_PROTOCOL_VERSION_1           ProtocolVersion = 1
+)
+
+// Enum value maps for ProtocolVersion.
+var (
+	ProtocolVersion_name = map[int32]string{
+		0: "PROTOCOL_VERSION_UNSPECIFIED",
+		1: "PROTOCOL_VERSION_1",
+	}
+	ProtocolVersion_value = map[string]int32{
+		"PROTOCOL_VERSION_UNSPECIFIED": 0,
+		"PROTOCOL_VERSION_1":           1,
+	}
+)
+
+func (x ProtocolVersion) Enum() *ProtocolVersion {
+	p := new(ProtocolVersion)
+	*p = x
+	return p
+}
+
+func (x ProtocolVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProtocolVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_pluginv1_plugin_proto_enumTypes[0].Descriptor()
+}
+
+func (ProtocolVersion) Type() protoreflect.EnumType {
+	return &file_pluginv1_plugin_proto_enumTypes[0]
+}
+
+func (x ProtocolVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProtocolVersion.Descriptor instead.
+func (ProtocolVersion) EnumDescriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+type PluginType int32
+
+const (
+	PluginType_PLUGIN_TYPE_UNSPECIFIED PluginType = 0
+	PluginType_PLUGIN_TYPE_INPUT       PluginType = 1
+	PluginType_PLUGIN_TYPE_PROCESSOR   PluginType = 2
+	PluginType_PLUGIN_TYPE_OUTPUT      PluginType = 3
+)
+
+// Enum value maps for PluginType.
+var (
+	PluginType_name = map[int32]string{
+		0: "PLUGIN_TYPE_UNSPECIFIED",
+		1: "PLUGIN_TYPE_INPUT",
+		2: "PLUGIN_TYPE_PROCESSOR",
+		3: "PLUGIN_TYPE_OUTPUT",
+	}
+	PluginType_value = map[string]int32{
+		"PLUGIN_TYPE_UNSPECIFIED": 0,
+		"PLUGIN_TYPE_INPUT":       1,
+		"PLUGIN_TYPE_PROCESSOR":   2,
+		"PLUGIN_TYPE_OUTPUT":      3,
+	}
+)
+
+func (x PluginType) Enum() *PluginType {
+	p := new(PluginType)
+	*p = x
+	return p
+}
+
+func (x PluginType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PluginType) Descriptor() protoreflect.EnumDescriptor {
+	return file_pluginv1_plugin_proto_enumTypes[1].Descriptor()
+}
+
+func (PluginType) Type() protoreflect.EnumType {
+	return &file_pluginv1_plugin_proto_enumTypes[1]
+}
+
+func (x PluginType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PluginType.Descriptor instead.
+func (PluginType) EnumDescriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+type MetricType int32
+
+const (
+	MetricType_METRIC_TYPE_UNTYPED   MetricType = 0
+	MetricType_METRIC_TYPE_COUNTER   MetricType = 1
+	MetricType_METRIC_TYPE_GAUGE     MetricType = 2
+	MetricType_METRIC_TYPE_SUMMARY   MetricType = 3
+	MetricType_METRIC_TYPE_HISTOGRAM MetricType = 4
+)
+
+// Enum value maps for MetricType.
+var (
+	MetricType_name = map[int32]string{
+		0: "METRIC_TYPE_UNTYPED",
+		1: "METRIC_TYPE_COUNTER",
+		2: "METRIC_TYPE_GAUGE",
+		3: "METRIC_TYPE_SUMMARY",
+		4: "METRIC_TYPE_HISTOGRAM",
+	}
+	MetricType_value = map[string]int32{
+		"METRIC_TYPE_UNTYPED":   0,
+		"METRIC_TYPE_COUNTER":   1,
+		"METRIC_TYPE_GAUGE":     2,
+		"METRIC_TYPE_SUMMARY":   3,
+		"METRIC_TYPE_HISTOGRAM": 4,
+	}
+)
+
+func (x MetricType) Enum() *MetricType {
+	p := new(MetricType)
+	*p = x
+	return p
+}
+
+func (x MetricType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetricType) Descriptor() protoreflect.EnumDescriptor {
+	return file_pluginv1_plugin_proto_enumTypes[2].Descriptor()
+}
+
+func (MetricType) Type() protoreflect.EnumType {
+	return &file_pluginv1_plugin_proto_enumTypes[2]
+}
+
+func (x MetricType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MetricType.Descriptor instead.
+func (MetricType) EnumDescriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+type Tag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Tag) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Tag) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Field struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*Field_DoubleValue
+	//	*Field_IntValue
+	//	*Field_UintValue
+	//	*Field_StringValue
+	//	*Field_BoolValue
+	Value         isField_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Field) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Field) GetValue() isField_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Field) GetDoubleValue() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*Field_DoubleValue); ok {
+			return x.DoubleValue
+		}
+	}
+	return 0
+}
+
+func (x *Field) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Value.(*Field_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Field) GetUintValue() uint64 {
+	if x != nil {
+		if x, ok := x.Value.(*Field_UintValue); ok {
+			return x.UintValue
+		}
+	}
+	return 0
+}
+
+func (x *Field) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Value.(*Field_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Field) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Value.(*Field_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+type isField_Value interface {
+	isField_Value()
+}
+
+type Field_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,2,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type Field_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Field_UintValue struct {
+	UintValue uint64 `protobuf:"varint,4,opt,name=uint_value,json=uintValue,proto3,oneof"`
+}
+
+type Field_StringValue struct {
+	StringValue string `protobuf:"bytes,5,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Field_BoolValue struct {
+	BoolValue bool `protobuf:"varint,6,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+func (*Field_DoubleValue) isField_Value() {}
+
+func (*Field_IntValue) isField_Value() {}
+
+func (*Field_UintValue) isField_Value() {}
+
+func (*Field_StringValue) isField_Value() {}
+
+func (*Field_BoolValue) isField_Value() {}
+
+type Metric struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Tags   []*Tag                 `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Fields []*Field               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Timestamp in nanoseconds since the Unix epoch, Telegraf uses the current
+	// time for metrics without timestamp
+	Timestamp     int64      `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Type          MetricType `protobuf:"varint,5,opt,name=type,proto3,enum=telegraf.plugin.v1.MetricType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metric) Reset() {
+	*x = Metric{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metric) ProtoMessage() {}
+
+func (x *Metric) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metric.ProtoReflect.Descriptor instead.
+func (*Metric) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Metric) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Metric) GetTags() []*Tag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Metric) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Metric) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Metric) GetType() MetricType {
+	if x != nil {
+		return x.Type
+	}
+	return MetricType_METRIC_TYPE_UNTYPED
+}
+
+// Non-fatal error reported along with data, e.g. a failure to collect parts
+// of the metrics of an input
+type PluginError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginError) Reset() {
+	*x = PluginError{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginError) ProtoMessage() {}
+
+func (x *PluginError) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginError.ProtoReflect.Descriptor instead.
+func (*PluginError) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PluginError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type HandshakeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Highest protocol version supported by Telegraf
+	ProtocolVersion ProtocolVersion `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3,enum=telegraf.plugin.v1.ProtocolVersion" json:"protocol_version,omitempty"`
+	// Type of plugin Telegraf expects
+	PluginType      PluginType `protobuf:"varint,2,opt,name=plugin_type,json=pluginType,proto3,enum=telegraf.plugin.v1.PluginType" json:"plugin_type,omitempty"`
+	TelegrafVersion string     `protobuf:"bytes,3,opt,name=telegraf_version,json=telegrafVersion,proto3" json:"telegraf_version,omitempty"`
+	// Plugin specific settings from the Telegraf configuration
+	Settings      map[string]string `protobuf:"bytes,4,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeRequest) Reset() {
+	*x = HandshakeRequest{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeRequest) ProtoMessage() {}
+
+func (x *HandshakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeRequest.ProtoReflect.Descriptor instead.
+func (*HandshakeRequest) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HandshakeRequest) GetProtocolVersion() ProtocolVersion {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return ProtocolVersion_PROTOCOL_VERSION_UNSPECIFIED
+}
+
+func (x *HandshakeRequest) GetPluginType() PluginType {
+	if x != nil {
+		return x.PluginType
+	}
+	return PluginType_PLUGIN_TYPE_UNSPECIFIED
+}
+
+func (x *HandshakeRequest) GetTelegrafVersion() string {
+	if x != nil {
+		return x.TelegrafVersion
+	}
+	return ""
+}
+
+func (x *HandshakeRequest) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type HandshakeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Protocol version used for the session, must not be higher than the one
+	// requested by Telegraf
+	ProtocolVersion ProtocolVersion `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3,enum=telegraf.plugin.v1.ProtocolVersion" json:"protocol_version,omitempty"`
+	// Name and version of the plugin used for logging
+	Name    string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Maximum number of metrics per request accepted by the plugin, zero
+	// means unlimited
+	MaxBatchSize  uint32 `protobuf:"varint,4,opt,name=max_batch_size,json=maxBatchSize,proto3" json:"max_batch_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse) Reset() {
+	*x = HandshakeResponse{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse) ProtoMessage() {}
+
+func (x *HandshakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HandshakeResponse) GetProtocolVersion() ProtocolVersion {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return ProtocolVersion_PROTOCOL_VERSION_UNSPECIFIED
+}
+
+func (x *HandshakeResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetMaxBatchSize() uint32 {
+	if x != nil {
+		return x.MaxBatchSize
+	}
+	return 0
+}
+
+type GatherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GatherRequest) Reset() {
+	*x = GatherRequest{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatherRequest) ProtoMessage() {}
+
+func (x *GatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatherRequest.ProtoReflect.Descriptor instead.
+func (*GatherRequest) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{6}
+}
+
+type GatherResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Errors        []*PluginError         `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GatherResponse) Reset() {
+	*x = GatherResponse{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GatherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatherResponse) ProtoMessage() {}
+
+func (x *GatherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatherResponse.ProtoReflect.Descriptor instead.
+func (*GatherResponse) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GatherResponse) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *GatherResponse) GetErrors() []*PluginError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type StreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of additional batches the plugin may send. Telegraf grants
+	// credits when the previous batches were accepted and the plugin must not
+	// send batches without available credits.
+	Credits       uint32 `protobuf:"varint,1,opt,name=credits,proto3" json:"credits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamRequest) GetCredits() uint32 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+type MetricBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Errors        []*PluginError         `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricBatch) Reset() {
+	*x = MetricBatch{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricBatch) ProtoMessage() {}
+
+func (x *MetricBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricBatch.ProtoReflect.Descriptor instead.
+func (*MetricBatch) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricBatch) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *MetricBatch) GetErrors() []*PluginError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type ProcessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identifier of the request to match the response
+	Id            uint64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metrics       []*Metric `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessRequest) Reset() {
+	*x = ProcessRequest{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessRequest) ProtoMessage() {}
+
+func (x *ProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessRequest.ProtoReflect.Descriptor instead.
+func (*ProcessRequest) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProcessRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProcessRequest) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type ProcessResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identifier of the request the response belongs to, each request must
+	// be answered exactly once
+	Id            uint64       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metrics       []*Metric    `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Error         *PluginError `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessResponse) Reset() {
+	*x = ProcessResponse{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessResponse) ProtoMessage() {}
+
+func (x *ProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessResponse.ProtoReflect.Descriptor instead.
+func (*ProcessResponse) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProcessResponse) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProcessResponse) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *ProcessResponse) GetError() *PluginError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type WriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRequest) Reset() {
+	*x = WriteRequest{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRequest) ProtoMessage() {}
+
+func (x *WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
+func (*WriteRequest) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WriteRequest) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type WriteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Indices of metrics in the request rejected by the plugin, those metrics
+	// are dropped while the others are considered written
+	Rejected      []uint32 `protobuf:"varint,1,rep,packed,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteResponse) Reset() {
+	*x = WriteResponse{}
+	mi := &file_pluginv1_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResponse) ProtoMessage() {}
+
+func (x *WriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pluginv1_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
+func (*WriteResponse) Descriptor() ([]byte, []int) {
+	return file_pluginv1_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WriteResponse) GetRejected() []uint32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+var File_pluginv1_plugin_proto protoreflect.FileDescriptor
+
+const file_pluginv1_plugin_proto_rawDesc = "" +
+	"\n" +
+	"\x15pluginv1/plugin.proto\x12\x12telegraf.plugin.v1\"-\n" +
+	"\x03Tag\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xcd\x01\n" +
+	"\x05Field\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\fdouble_value\x18\x02 \x01(\x01H\x00R\vdoubleValue\x12\x1d\n" +
+	"\tint_value\x18\x03 \x01(\x03H\x00R\bintValue\x12\x1f\n" +
+	"\n" +
+	"uint_value\x18\x04 \x01(\x04H\x00R\tuintValue\x12#\n" +
+	"\fstring_value\x18\x05 \x01(\tH\x00R\vstringValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x06 \x01(\bH\x00R\tboolValueB\a\n" +
+	"\x05value\"\xce\x01\n" +
+	"\x06Metric\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x04tags\x18\x02 \x03(\v2\x17.telegraf.plugin.v1.TagR\x04tags\x121\n" +
+	"\x06fields\x18\x03 \x03(\v2\x19.telegraf.plugin.v1.FieldR\x06fields\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x122\n" +
+	"\x04type\x18\x05 \x01(\x0e2\x1e.telegraf.plugin.v1.MetricTypeR\x04type\"'\n" +
+	"\vPluginError\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xdb\x02\n" +
+	"\x10HandshakeRequest\x12N\n" +
+	"\x10protocol_version\x18\x01 \x01(\x0e2#.telegraf.plugin.v1.ProtocolVersionR\x0fprotocolVersion\x12?\n" +
+	"\vplugin_type\x18\x02 \x01(\x0e2\x1e.telegraf.plugin.v1.PluginTypeR\n" +
+	"pluginType\x12)\n" +
+	"\x10telegraf_version\x18\x03 \x01(\tR\x0ftelegrafVersion\x12N\n" +
+	"\bsettings\x18\x04 \x03(\v22.telegraf.plugin.v1.HandshakeRequest.SettingsEntryR\bsettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x01\n" +
+	"\x11HandshakeResponse\x12N\n" +
+	"\x10protocol_version\x18\x01 \x01(\x0e2#.telegraf.plugin.v1.ProtocolVersionR\x0fprotocolVersion\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12$\n" +
+	"\x0emax_batch_size\x18\x04 \x01(\rR\fmaxBatchSize\"\x0f\n" +
+	"\rGatherRequest\"\x7f\n" +
+	"\x0eGatherResponse\x124\n" +
+	"\ametrics\x18\x01 \x03(\v2\x1a.telegraf.plugin.v1.MetricR\ametrics\x127\n" +
+	"\x06errors\x18\x02 \x03(\v2\x1f.telegraf.plugin.v1.PluginErrorR\x06errors\")\n" +
+	"\rStreamRequest\x12\x18\n" +
+	"\acredits\x18\x01 \x01(\rR\acredits\"|\n" +
+	"\vMetricBatch\x124\n" +
+	"\ametrics\x18\x01 \x03(\v2\x1a.telegraf.plugin.v1.MetricR\ametrics\x127\n" +
+	"\x06errors\x18\x02 \x03(\v2\x1f.telegraf.plugin.v1.PluginErrorR\x06errors\"V\n" +
+	"\x0eProcessRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x124\n" +
+	"\ametrics\x18\x02 \x03(\v2\x1a.telegraf.plugin.v1.MetricR\ametrics\"\x8e\x01\n" +
+	"\x0fProcessResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x124\n" +
+	"\ametrics\x18\x02 \x03(\v2\x1a.telegraf.plugin.v1.MetricR\ametrics\x125\n" +
+	"\x05error\x18\x03 \x01(\v2\x1f.telegraf.plugin.v1.PluginErrorR\x05error\"D\n" +
+	"\fWriteRequest\x124\n" +
+	"\ametrics\x18\x01 \x03(\v2\x1a.telegraf.plugin.v1.MetricR\ametrics\"+\n" +
+	"\rWriteResponse\x12\x1a\n" +
+	"\brejected\x18\x01 \x03(\rR\brejected*K\n" +
+	"\x0fProtocolVersion\x12 \n" +
+	"\x1cPROTOCOL_VERSION_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12PROTOCOL_VERSION_1\x10\x01*s\n" +
+	"\n" +
+	"PluginType\x12\x1b\n" +
+	"\x17PLUGIN_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11PLUGIN_TYPE_INPUT\x10\x01\x12\x19\n" +
+	"\x15PLUGIN_TYPE_PROCESSOR\x10\x02\x12\x16\n" +
+	"\x12PLUGIN_TYPE_OUTPUT\x10\x03*\x89\x01\n" +
+	"\n" +
+	"MetricType\x12\x17\n" +
+	"\x13METRIC_TYPE_UNTYPED\x10\x00\x12\x17\n" +
+	"\x13METRIC_TYPE_COUNTER\x10\x01\x12\x15\n" +
+	"\x11METRIC_TYPE_GAUGE\x10\x02\x12\x17\n" +
+	"\x13METRIC_TYPE_SUMMARY\x10\x03\x12\x19\n" +
+	"\x15METRIC_TYPE_HISTOGRAM\x10\x042b\n" +
+	"\x06Plugin\x12X\n" +
+	"\tHandshake\x12$.telegraf.plugin.v1.HandshakeRequest\x1a%.telegraf.plugin.v1.HandshakeResponse2\xaa\x01\n" +
+	"\x05Input\x12O\n" +
+	"\x06Gather\x12!.telegraf.plugin.v1.GatherRequest\x1a\".telegraf.plugin.v1.GatherResponse\x12P\n" +
+	"\x06Stream\x12!.telegraf.plugin.v1.StreamRequest\x1a\x1f.telegraf.plugin.v1.MetricBatch(\x010\x012c\n" +
+	"\tProcessor\x12V\n" +
+	"\aProcess\x12\".telegraf.plugin.v1.ProcessRequest\x1a#.telegraf.plugin.v1.ProcessResponse(\x010\x012V\n" +
+	"\x06Output\x12L\n" +
+	"\x05Write\x12 .telegraf.plugin.v1.WriteRequest\x1a!.telegraf.plugin.v1.WriteResponseBBZ@github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1b\x06proto3"
+
+var (
+	file_pluginv1_plugin_proto_rawDescOnce sync.Once
+	file_pluginv1_plugin_proto_rawDescData []byte
+)
+
+func file_pluginv1_plugin_proto_rawDescGZIP() []byte {
+	file_pluginv1_plugin_proto_rawDescOnce.Do(func() {
+		file_pluginv1_plugin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pluginv1_plugin_proto_rawDesc), len(file_pluginv1_plugin_proto_rawDesc)))
+	})
+	return file_pluginv1_plugin_proto_rawDescData
+}
+
+var file_pluginv1_plugin_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_pluginv1_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pluginv1_plugin_proto_goTypes = []any{
+	(ProtocolVersion)(0),      // 0: telegraf.plugin.v1.ProtocolVersion
+	(PluginType)(0),           // 1: telegraf.plugin.v1.PluginType
+	(MetricType)(0),           // 2: telegraf.plugin.v1.MetricType
+	(*Tag)(nil),               // 3: telegraf.plugin.v1.Tag
+	(*Field)(nil),             // 4: telegraf.plugin.v1.Field
+	(*Metric)(nil),            // 5: telegraf.plugin.v1.Metric
+	(*PluginError)(nil),       // 6: telegraf.plugin.v1.PluginError
+	(*HandshakeRequest)(nil),  // 7: telegraf.plugin.v1.HandshakeRequest
+	(*HandshakeResponse)(nil), // 8: telegraf.plugin.v1.HandshakeResponse
+	(*GatherRequest)(nil),     // 9: telegraf.plugin.v1.GatherRequest
+	(*GatherResponse)(nil),    // 10: telegraf.plugin.v1.GatherResponse
+	(*StreamRequest)(nil),     // 11: telegraf.plugin.v1.StreamRequest
+	(*MetricBatch)(nil),       // 12: telegraf.plugin.v1.MetricBatch
+	(*ProcessRequest)(nil),    // 13: telegraf.plugin.v1.ProcessRequest
+	(*ProcessResponse)(nil),   // 14: telegraf.plugin.v1.ProcessResponse
+	(*WriteRequest)(nil),      // 15: telegraf.plugin.v1.WriteRequest
+	(*WriteResponse)(nil),     // 16: telegraf.plugin.v1.WriteResponse
+	nil,                       // 17: telegraf.plugin.v1.HandshakeRequest.SettingsEntry
+}
+var file_pluginv1_plugin_proto_depIdxs = []int32{
+	3,  // 0: telegraf.plugin.v1.Metric.tags:type_name -> telegraf.plugin.v1.Tag
+	4,  // 1: telegraf.plugin.v1.Metric.fields:type_name -> telegraf.plugin.v1.Field
+	2,  // 2: telegraf.plugin.v1.Metric.type:type_name -> telegraf.plugin.v1.MetricType
+	0,  // 3: telegraf.plugin.v1.HandshakeRequest.protocol_version:type_name -> telegraf.plugin.v1.ProtocolVersion
+	1,  // 4: telegraf.plugin.v1.HandshakeRequest.plugin_type:type_name -> telegraf.plugin.v1.PluginType
+	17, // 5: telegraf.plugin.v1.HandshakeRequest.settings:type_name -> telegraf.plugin.v1.HandshakeRequest.SettingsEntry
+	0,  // 6: telegraf.plugin.v1.HandshakeResponse.protocol_version:type_name -> telegraf.plugin.v1.ProtocolVersion
+	5,  // 7: telegraf.plugin.v1.GatherResponse.metrics:type_name -> telegraf.plugin.v1.Metric
+	6,  // 8: telegraf.plugin.v1.GatherResponse.errors:type_name -> telegraf.plugin.v1.PluginError
+	5,  // 9: telegraf.plugin.v1.MetricBatch.metrics:type_name -> telegraf.plugin.v1.Metric
+	6,  // 10: telegraf.plugin.v1.MetricBatch.errors:type_name -> telegraf.plugin.v1.PluginError
+	5,  // 11: telegraf.plugin.v1.ProcessRequest.metrics:type_name -> telegraf.plugin.v1.Metric
+	5,  // 12: telegraf.plugin.v1.ProcessResponse.metrics:type_name -> telegraf.plugin.v1.Metric
+	6,  // 13: telegraf.plugin.v1.ProcessResponse.error:type_name -> telegraf.plugin.v1.PluginError
+	5,  // 14: telegraf.plugin.v1.WriteRequest.metrics:type_name -> telegraf.plugin.v1.Metric
+	7,  // 15: telegraf.plugin.v1.Plugin.Handshake:input_type -> telegraf.plugin.v1.HandshakeRequest
+	9,  // 16: telegraf.plugin.v1.Input.Gather:input_type -> telegraf.plugin.v1.GatherRequest
+	11, // 17: telegraf.plugin.v1.Input.Stream:input_type -> telegraf.plugin.v1.StreamRequest
+	13, // 18: telegraf.plugin.v1.Processor.Process:input_type -> telegraf.plugin.v1.ProcessRequest
+	15, // 19: telegraf.plugin.v1.Output.Write:input_type -> telegraf.plugin.v1.WriteRequest
+	8,  // 20: telegraf.plugin.v1.Plugin.Handshake:output_type -> telegraf.plugin.v1.HandshakeResponse
+	10, // 21: telegraf.plugin.v1.Input.Gather:output_type -> telegraf.plugin.v1.GatherResponse
+	12, // 22: telegraf.plugin.v1.Input.Stream:output_type -> telegraf.plugin.v1.MetricBatch
+	14, // 23: telegraf.plugin.v1.Processor.Process:output_type -> telegraf.plugin.v1.ProcessResponse
+	16, // 24: telegraf.plugin.v1.Output.Write:output_type -> telegraf.plugin.v1.WriteResponse
+	20, // [20:25] is the sub-list for method output_type
+	15, // [15:20] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_pluginv1_plugin_proto_init() }
+func file_pluginv1_plugin_proto_init() {
+	if File_pluginv1_plugin_proto != nil {
+		return
+	}
+	file_pluginv1_plugin_proto_msgTypes[1].OneofWrappers = []any{
+		(*Field_DoubleValue)(nil),
+		(*Field_IntValue)(nil),
+		(*Field_UintValue)(nil),
+		(*Field_StringValue)(nil),
+		(*Field_BoolValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pluginv1_plugin_proto_rawDesc), len(file_pluginv1_plugin_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_pluginv1_plugin_proto_goTypes,
+		DependencyIndexes: file_pluginv1_plugin_proto_depIdxs,
+		EnumInfos:         file_pluginv1_plugin_proto_enumTypes,
+		MessageInfos:      file_pluginv1_plugin_proto_msgTypes,
+	}.Build()
+	File_pluginv1_plugin_proto = out.File
+	file_pluginv1_plugin_proto_goTypes = nil
+	file_pluginv1_plugin_proto_depIdxs = nil
+}
@@ -0,0 +1,171 @@
+package httpconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// DefaultNegotiatedEncodings are the content encodings tried in order of
+// preference when negotiating the encoding with the server
+var DefaultNegotiatedEncodings = []string{"zstd", "gzip", "snappy", "identity"}
+
+// ContentEncodingNegotiator compresses request bodies using the most preferred
+// content encoding accepted by the server. If the server rejects an encoding
+// with status "415 Unsupported Media Type" the next encoding, or the first
+// one listed in the "Accept-Encoding" header of the response, is used for the
+// current and all subsequent requests.
+type ContentEncodingNegotiator struct {
+	encodings []string
+	encoders  map[string]internal.ContentEncoder
+	log       telegraf.Logger
+
+	sync.Mutex
+	current int
+
+	bytesUncompressed selfstat.Stat
+	bytesCompressed   selfstat.Stat
+	compressionRatio  selfstat.Stat
+}
+
+// NewContentEncodingNegotiator creates a negotiator for the given encodings in
+// order of preference. The statistics are reported using the given
+// measurement and tags.
+func NewContentEncodingNegotiator(
+	encodings []string,
+	measurement string,
+	tags map[string]string,
+	log telegraf.Logger,
+) (*ContentEncodingNegotiator, error) {
+	if len(encodings) == 0 {
+		return nil, errors.New("no content encodings given")
+	}
+
+	encoders := make(map[string]internal.ContentEncoder, len(encodings))
+	for _, encoding := range encodings {
+		if _, found := encoders[encoding]; found {
+			return nil, fmt.Errorf("duplicate content encoding %q", encoding)
+		}
+		encoder, err := internal.NewContentEncoder(encoding)
+		if err != nil {
+			return nil, fmt.Errorf("creating encoder for %q failed: %w", encoding, err)
+		}
+		encoders[encoding] = encoder
+	}
+
+	return &ContentEncodingNegotiator{
+		encodings:         encodings,
+		encoders:          encoders,
+		log:               log,
+		bytesUncompressed: selfstat.Register(measurement, "bytes_uncompressed", tags),
+		bytesCompressed:   selfstat.Register(measurement, "bytes_compressed", tags),
+		compressionRatio:  selfstat.Register(measurement, "compression_ratio_percent", tags),
+	}, nil
+}
+
+// Encode compresses the data using the current encoding and returns the
+// encoding used. The returned encoding must be passed to Rejected to handle
+// the response of the server.
+func (n *ContentEncodingNegotiator) Encode(data []byte) (string, []byte, error) {
+	n.Lock()
+	encoding := n.encodings[n.current]
+	n.Unlock()
+
+	encoded, err := n.encoders[encoding].Encode(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("encoding with %q failed: %w", encoding, err)
+	}
+
+	// The encoders might reuse their buffers so take a copy
+	if encoding != "identity" {
+		encoded = slices.Clone(encoded)
+	}
+
+	n.bytesUncompressed.Incr(int64(len(data)))
+	n.bytesCompressed.Incr(int64(len(encoded)))
+	if uncompressed, compressed := n.bytesUncompressed.Get(), n.bytesCompressed.Get(); uncompressed > 0 {
+		n.compressionRatio.Set(100 * compressed / uncompressed)
+	}
+
+	return encoding, encoded, nil
+}
+
+// Rejected checks if the server rejected the given encoding and switches to
+// the next acceptable encoding. It returns true if the request should be
+// retried with the new encoding.
+func (n *ContentEncodingNegotiator) Rejected(encoding string, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		return false
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	idx := slices.Index(n.encodings, encoding)
+	if idx < 0 || idx >= len(n.encodings)-1 {
+		return false
+	}
+	if idx < n.current {
+		// Another request already switched to a later encoding
+		return true
+	}
+
+	next := idx + 1
+	if accepted := parseAcceptEncoding(resp.Header.Get("Accept-Encoding")); len(accepted) > 0 {
+		next = -1
+		for i := idx + 1; i < len(n.encodings); i++ {
+			if acceptable(accepted, n.encodings[i]) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return false
+		}
+	}
+
+	n.log.Warnf("Server rejected content encoding %q, falling back to %q", encoding, n.encodings[next])
+	n.current = next
+	return true
+}
+
+// parseAcceptEncoding returns the encodings listed in the header value and
+// whether they are acceptable according to their weight
+func parseAcceptEncoding(value string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		coding, params, _ := strings.Cut(item, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		weight := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if w, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = w
+			}
+		}
+		accepted[coding] = weight > 0
+	}
+
+	// The identity encoding is acceptable unless excluded explicitly
+	if _, found := accepted["identity"]; !found && len(accepted) > 0 {
+		accepted["identity"] = true
+	}
+	return accepted
+}
+
+func acceptable(accepted map[string]bool, encoding string) bool {
+	if ok, found := accepted[encoding]; found {
+		return ok
+	}
+	return accepted["*"]
+}
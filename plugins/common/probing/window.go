@@ -0,0 +1,102 @@
+// Package probing provides the aggregation of probe results, e.g. of pings or
+// connection attempts, sent in sub-intervals of the gathering interval.
+package probing
+
+import (
+	"sync"
+	"time"
+)
+
+// Summary contains the aggregated probe results of a window
+type Summary struct {
+	Sent         int
+	Received     int
+	LossPercent  float64
+	MinRTT       time.Duration
+	AvgRTT       time.Duration
+	MaxRTT       time.Duration
+	Jitter       time.Duration
+	StateChanges int
+	Up           bool
+}
+
+// Window collects probe results until flushed and tracks the up/down state of
+// the probed target across windows
+type Window struct {
+	mu       sync.Mutex
+	sent     int
+	received int
+	rtts     []time.Duration
+	jitter   time.Duration
+	changes  int
+	known    bool
+	up       bool
+}
+
+// Add records the result of a probe with the given number of sent and
+// received packets and the measured round-trip times. The target is
+// considered up if any packet was received. The function returns true if the
+// state of the target changed with this probe; the first probe only
+// establishes the state.
+func (w *Window) Add(sent, received int, rtts []time.Duration) (changed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sent += sent
+	w.received += received
+	for _, rtt := range rtts {
+		// Jitter as mean deviation of consecutive round-trip times
+		if len(w.rtts) > 0 {
+			w.jitter += (rtt - w.rtts[len(w.rtts)-1]).Abs()
+		}
+		w.rtts = append(w.rtts, rtt)
+	}
+
+	up := received > 0
+	changed = w.known && up != w.up
+	if changed {
+		w.changes++
+	}
+	w.known = true
+	w.up = up
+
+	return changed
+}
+
+// Flush returns the summary of the results collected since the last flush and
+// resets the window. The boolean is false if no probe was recorded.
+func (w *Window) Flush() (Summary, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.sent == 0 {
+		return Summary{}, false
+	}
+
+	s := Summary{
+		Sent:         w.sent,
+		Received:     w.received,
+		LossPercent:  float64(w.sent-w.received) / float64(w.sent) * 100,
+		StateChanges: w.changes,
+		Up:           w.up,
+	}
+	if len(w.rtts) > 0 {
+		s.MinRTT, s.MaxRTT = w.rtts[0], w.rtts[0]
+		var sum time.Duration
+		for _, rtt := range w.rtts {
+			s.MinRTT = min(s.MinRTT, rtt)
+			s.MaxRTT = max(s.MaxRTT, rtt)
+			sum += rtt
+		}
+		s.AvgRTT = sum / time.Duration(len(w.rtts))
+	}
+	if len(w.rtts) > 1 {
+		s.Jitter = w.jitter / time.Duration(len(w.rtts)-1)
+	}
+
+	w.sent, w.received, w.changes = 0, 0, 0
+	w.rtts = w.rtts[:0]
+	w.jitter = 0
+
+	return s, true
+}
@@ -0,0 +1,40 @@
+package probing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	var w Window
+
+	_, ok := w.Flush()
+	require.False(t, ok)
+
+	require.False(t, w.Add(1, 1, []time.Duration{10 * time.Millisecond}))
+	require.False(t, w.Add(1, 1, []time.Duration{14 * time.Millisecond}))
+	require.True(t, w.Add(1, 0, nil))
+	require.True(t, w.Add(2, 2, []time.Duration{12 * time.Millisecond, 12 * time.Millisecond}))
+
+	s, ok := w.Flush()
+	require.True(t, ok)
+	require.Equal(t, Summary{
+		Sent:         5,
+		Received:     4,
+		LossPercent:  20,
+		MinRTT:       10 * time.Millisecond,
+		AvgRTT:       12 * time.Millisecond,
+		MaxRTT:       14 * time.Millisecond,
+		Jitter:       2 * time.Millisecond,
+		StateChanges: 2,
+		Up:           true,
+	}, s)
+
+	// The state is kept across windows
+	require.True(t, w.Add(1, 0, nil))
+	s, ok = w.Flush()
+	require.True(t, ok)
+	require.Equal(t, Summary{Sent: 1, LossPercent: 100, StateChanges: 1}, s)
+}
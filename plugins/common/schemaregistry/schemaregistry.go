@@ -0,0 +1,199 @@
+// Package schemaregistry implements a client for the Confluent Schema Registry
+// used by the Avro parser and serializer.
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+const (
+	schemaByID         = "%s/schemas/ids/%d"
+	schemaRegistration = "%s/subjects/%s/versions"
+)
+
+// SchemaAndCodec contains a schema fetched from the registry and its codec
+type SchemaAndCodec struct {
+	Schema string
+	Codec  *goavro.Codec
+}
+
+// Registry is a client of a schema registry caching the fetched and registered
+// schemas
+type Registry struct {
+	url        string
+	username   string
+	password   string
+	schemas    map[int]*SchemaAndCodec
+	registered map[string]int
+	client     *http.Client
+	mu         sync.RWMutex
+}
+
+// New creates a client for the registry at the given address. Basic
+// authentication credentials may be given as part of the address.
+func New(addr, caCertPath string) (*Registry, error) {
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		},
+		Timeout: 10 * time.Second,
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	registry := &Registry{
+		url:        u.String(),
+		username:   username,
+		password:   password,
+		schemas:    make(map[int]*SchemaAndCodec),
+		registered: make(map[string]int),
+		client:     client,
+	}
+
+	return registry, nil
+}
+
+// Helper function to make managing lock easier
+func (sr *Registry) getSchemaAndCodecFromCache(id int) (*SchemaAndCodec, bool) {
+	// Read-lock the cache map before access.
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	v, ok := sr.schemas[id]
+	return v, ok
+}
+
+// GetSchemaAndCodec returns the schema with the given ID and its codec
+func (sr *Registry) GetSchemaAndCodec(id int) (*SchemaAndCodec, error) {
+	if v, ok := sr.getSchemaAndCodecFromCache(id); ok {
+		return v, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(schemaByID, sr.url, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, err
+	}
+
+	schema, ok := jsonResponse["schema"]
+	if !ok {
+		return nil, errors.New("malformed response from schema registry: no 'schema' key")
+	}
+
+	schemaValue, ok := schema.(string)
+	if !ok {
+		return nil, fmt.Errorf("malformed response from schema registry: %v cannot be cast to string", schema)
+	}
+	codec, err := goavro.NewCodec(schemaValue)
+	if err != nil {
+		return nil, err
+	}
+	retval := &SchemaAndCodec{Schema: schemaValue, Codec: codec}
+	// Lock the cache map before update.
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.schemas[id] = retval
+	return retval, nil
+}
+
+// Register publishes the schema for the given subject and returns the ID
+// assigned by the registry. Registering an already known schema is a no-op
+// in the registry and returns the existing ID, so the result is cached.
+func (sr *Registry) Register(subject, schema string) (int, error) {
+	key := subject + "\x00" + schema
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if id, found := sr.registered[key]; found {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	addr := fmt.Sprintf(schemaRegistration, sr.url, url.PathEscape(subject))
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("registering schema failed with status %q: %s", resp.Status, string(msg))
+	}
+
+	var response struct {
+		ID *int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("decoding registry response failed: %w", err)
+	}
+	if response.ID == nil {
+		return 0, errors.New("malformed response from schema registry: no 'id' key")
+	}
+
+	sr.registered[key] = *response.ID
+	return *response.ID, nil
+}
@@ -0,0 +1,317 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// Size of a WASM memory page
+const pageSize = 64 * 1024
+
+// Config of a WASM module implementing a plugin
+type Config struct {
+	Module      string            `toml:"module"`
+	Settings    map[string]string `toml:"settings"`
+	Timeout     config.Duration   `toml:"timeout"`
+	MemoryLimit config.Size       `toml:"memory_limit"`
+}
+
+// Module is a loaded WASM module. Calls to the module are serialized and the
+// module is instantiated anew after a call failed, e.g. due to a trap or a
+// timeout, so that a faulty module does not require restarting Telegraf.
+type Module struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	instance api.Module
+	settings []byte
+	timeout  time.Duration
+	log      telegraf.Logger
+
+	// Error reported by the module during the current call
+	callErr string
+	sync.Mutex
+}
+
+// Load compiles the module and checks that it exports the given functions in
+// addition to the functions and memory required by all modules
+func (cfg *Config) Load(ctx context.Context, log telegraf.Logger, functions ...string) (*Module, error) {
+	if cfg.Module == "" {
+		return nil, errors.New("no module configured")
+	}
+	if cfg.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	code, err := os.ReadFile(cfg.Module)
+	if err != nil {
+		return nil, fmt.Errorf("reading module failed: %w", err)
+	}
+
+	settings, err := json.Marshal(cfg.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("encoding settings failed: %w", err)
+	}
+	if cfg.Settings == nil {
+		settings = []byte("{}")
+	}
+
+	rcfg := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cfg.MemoryLimit > 0 {
+		pages := uint32(max(int64(cfg.MemoryLimit)/pageSize, 1))
+		rcfg = rcfg.WithMemoryLimitPages(pages)
+	}
+
+	m := &Module{
+		runtime:  wazero.NewRuntimeWithConfig(ctx, rcfg),
+		settings: settings,
+		timeout:  time.Duration(cfg.Timeout),
+		log:      log,
+	}
+
+	// Modules compiled for WASI, e.g. by Rust or TinyGo, require the WASI
+	// functions. No filesystem, environment or arguments are exposed.
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		m.Close(ctx)
+		return nil, fmt.Errorf("instantiating WASI failed: %w", err)
+	}
+
+	_, err = m.runtime.NewHostModuleBuilder("telegraf").
+		NewFunctionBuilder().WithFunc(m.hostLog).Export("log").
+		NewFunctionBuilder().WithFunc(m.hostSetError).Export("set_error").
+		Instantiate(ctx)
+	if err != nil {
+		m.Close(ctx)
+		return nil, fmt.Errorf("instantiating host functions failed: %w", err)
+	}
+
+	m.compiled, err = m.runtime.CompileModule(ctx, code)
+	if err != nil {
+		m.Close(ctx)
+		return nil, fmt.Errorf("compiling module failed: %w", err)
+	}
+
+	if _, found := m.compiled.ExportedMemories()["memory"]; !found {
+		m.Close(ctx)
+		return nil, errors.New("module does not export memory")
+	}
+	exported := m.compiled.ExportedFunctions()
+	for _, name := range append([]string{"alloc"}, functions...) {
+		if _, found := exported[name]; !found {
+			m.Close(ctx)
+			return nil, fmt.Errorf("module does not export function %q", name)
+		}
+	}
+
+	// Instantiate the module directly to fail early on errors during the
+	// initialization of the module
+	m.Lock()
+	defer m.Unlock()
+	if err := m.instantiate(ctx); err != nil {
+		m.Close(ctx)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Call calls the given function of the module passing the input and returns
+// the output of the function
+func (m *Module) Call(ctx context.Context, function string, input []byte) ([]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.instance == nil {
+		if err := m.instantiate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	results, err := m.call(ctx, function, input)
+	if err != nil {
+		// The state of the instance is undefined after a failed call
+		m.reset(ctx)
+		return nil, fmt.Errorf("calling %q failed: %w", function, err)
+	}
+	if m.callErr != "" {
+		return nil, errors.New(m.callErr)
+	}
+
+	// The result contains the pointer to the output in the upper and the
+	// length in the lower 32 bits
+	ptr, length := uint32(results[0]>>32), uint32(results[0])
+	output, ok := m.instance.Memory().Read(ptr, length)
+	if !ok {
+		return nil, fmt.Errorf("output of %q out of memory range", function)
+	}
+	return bytes.Clone(output), nil
+}
+
+// Close releases all resources of the module
+func (m *Module) Close(ctx context.Context) {
+	if err := m.runtime.Close(ctx); err != nil {
+		m.log.Errorf("Closing runtime failed: %v", err)
+	}
+}
+
+func (m *Module) instantiate(ctx context.Context) error {
+	mcfg := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions().
+		WithStdout(&logWriter{logf: m.log.Info}).
+		WithStderr(&logWriter{logf: m.log.Error}).
+		WithSysWalltime().
+		WithSysNanotime()
+
+	instance, err := m.runtime.InstantiateModule(ctx, m.compiled, mcfg)
+	if err != nil {
+		return fmt.Errorf("instantiating module failed: %w", err)
+	}
+	m.instance = instance
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	// Reactor modules compiled for WASI need to initialize their runtime
+	if fn := instance.ExportedFunction("_initialize"); fn != nil {
+		if _, err := fn.Call(ctx); err != nil {
+			m.reset(ctx)
+			return fmt.Errorf("initializing module failed: %w", err)
+		}
+	}
+
+	if instance.ExportedFunction("init") == nil {
+		return nil
+	}
+	results, err := m.call(ctx, "init", m.settings)
+	if err != nil {
+		m.reset(ctx)
+		return fmt.Errorf("calling \"init\" failed: %w", err)
+	}
+	if results[0] != 0 || m.callErr != "" {
+		m.reset(ctx)
+		if m.callErr != "" {
+			return fmt.Errorf("initializing module failed: %s", m.callErr)
+		}
+		return fmt.Errorf("initializing module failed with code %d", int32(results[0]))
+	}
+
+	return nil
+}
+
+// call passes the input to the given function using memory allocated by the
+// module's "alloc" function
+func (m *Module) call(ctx context.Context, function string, input []byte) ([]uint64, error) {
+	fn := m.instance.ExportedFunction(function)
+	if fn == nil {
+		return nil, fmt.Errorf("function %q not exported", function)
+	}
+
+	results, err := m.instance.ExportedFunction("alloc").Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("allocating memory failed: %w", err)
+	}
+	ptr := uint32(results[0])
+	if !m.instance.Memory().Write(ptr, input) {
+		return nil, errors.New("allocated memory out of range")
+	}
+
+	m.callErr = ""
+	results, err = fn.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("function %q returned %d values", function, len(results))
+	}
+	return results, nil
+}
+
+func (m *Module) reset(ctx context.Context) {
+	if m.instance == nil {
+		return
+	}
+	if err := m.instance.Close(ctx); err != nil {
+		m.log.Debugf("Closing instance failed: %v", err)
+	}
+	m.instance = nil
+}
+
+// Log levels of the "log" host function
+const (
+	levelError = iota
+	levelWarn
+	levelInfo
+	levelDebug
+)
+
+// hostLog implements the "log(level, ptr, len)" host function
+func (m *Module) hostLog(_ context.Context, mod api.Module, level, ptr, length uint32) {
+	msg, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		m.log.Errorf("Module logged message out of memory range")
+		return
+	}
+
+	switch level {
+	case levelError:
+		m.log.Error(string(msg))
+	case levelWarn:
+		m.log.Warn(string(msg))
+	case levelInfo:
+		m.log.Info(string(msg))
+	case levelDebug:
+		m.log.Debug(string(msg))
+	default:
+		m.log.Trace(string(msg))
+	}
+}
+
+// hostSetError implements the "set_error(ptr, len)" host function reporting
+// an error for the current call
+func (m *Module) hostSetError(_ context.Context, mod api.Module, ptr, length uint32) {
+	msg, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		m.callErr = "module reported error out of memory range"
+		return
+	}
+	m.callErr = string(msg)
+}
+
+// logWriter logs each line written to the standard output or error of the
+// module
+type logWriter struct {
+	logf func(args ...interface{})
+	buf  []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(w.buf[:idx])); line != "" {
+			w.logf(line)
+		}
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.auditd
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/auditd" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.cassandra_native
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/cassandra_native" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.cloud_cost
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/cloud_cost" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.coredns
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/coredns" // register plugin
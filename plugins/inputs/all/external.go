@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.external
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/external" // register plugin
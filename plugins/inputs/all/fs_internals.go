@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.fs_internals
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/fs_internals" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.jmx_bridge
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/jmx_bridge" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ldap_sync
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ldap_sync" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.login_events
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/login_events" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.patroni
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/patroni" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.s3_health
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/s3_health" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.service_discovery
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/service_discovery" // register plugin
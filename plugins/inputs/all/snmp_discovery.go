@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.snmp_discovery
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/snmp_discovery" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.tc
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/tc" // register plugin
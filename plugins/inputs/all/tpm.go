@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.tpm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/tpm" // register plugin
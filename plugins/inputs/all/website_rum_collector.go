@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.website_rum_collector
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/website_rum_collector" // register plugin
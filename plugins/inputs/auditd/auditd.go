@@ -0,0 +1,361 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package auditd
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Key reported for events exceeding the configured number of keys
+const overflowKey = "_overflow"
+
+// Separator of multiple keys of an audit rule
+const keySeparator = "\x01"
+
+type Auditd struct {
+	Source             string          `toml:"source"`
+	File               string          `toml:"file"`
+	IncludeUnkeyed     bool            `toml:"include_unkeyed"`
+	MaxEventsPerSecond int64           `toml:"max_events_per_second"`
+	MaxKeys            int             `toml:"max_keys"`
+	Log                telegraf.Logger `toml:"-"`
+
+	netlink *netlinkReceiver
+	logfile *logReader
+
+	sync.Mutex
+	keys     map[string]*counter
+	syscalls map[string]*counter
+	events   int64
+	dropped  int64
+	lost     int64
+	second   int64
+	inSecond int64
+}
+
+type counter struct {
+	hits     int64
+	failures int64
+}
+
+// syscallEvent contains the information of an audit SYSCALL record
+type syscallEvent struct {
+	timestamp int64
+	keys      []string
+	syscall   string
+	success   bool
+}
+
+func (*Auditd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Auditd) Init() error {
+	switch a.Source {
+	case "":
+		a.Source = "netlink"
+	case "netlink", "file":
+	default:
+		return fmt.Errorf("invalid source %q", a.Source)
+	}
+
+	if a.File == "" {
+		a.File = "/var/log/audit/audit.log"
+	}
+	if a.MaxEventsPerSecond < 0 {
+		return fmt.Errorf("invalid maximum events per second %d", a.MaxEventsPerSecond)
+	}
+	if a.MaxKeys < 1 {
+		a.MaxKeys = 100
+	}
+
+	a.keys = make(map[string]*counter)
+	a.syscalls = make(map[string]*counter)
+
+	return nil
+}
+
+func (a *Auditd) Start(telegraf.Accumulator) error {
+	switch a.Source {
+	case "netlink":
+		receiver, err := newNetlinkReceiver()
+		if err != nil {
+			return fmt.Errorf("subscribing to audit events failed: %w", err)
+		}
+		a.netlink = receiver
+		a.netlink.run(a.handleRecord, a.handleLost, a.Log)
+	case "file":
+		reader, err := newLogReader(a.File)
+		if err != nil {
+			return fmt.Errorf("opening audit log failed: %w", err)
+		}
+		a.logfile = reader
+	}
+	return nil
+}
+
+func (a *Auditd) Gather(acc telegraf.Accumulator) error {
+	if a.logfile != nil {
+		if err := a.logfile.read(a.handleLine); err != nil {
+			acc.AddError(fmt.Errorf("reading audit log failed: %w", err))
+		}
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	for key, c := range a.keys {
+		acc.AddFields("auditd", c.fields(), map[string]string{"key": key})
+	}
+	for syscall, c := range a.syscalls {
+		acc.AddFields("auditd_syscall", c.fields(), map[string]string{"syscall": syscall})
+	}
+	acc.AddFields("auditd_summary", map[string]interface{}{
+		"events":  a.events,
+		"dropped": a.dropped,
+		"lost":    a.lost,
+	}, nil)
+
+	// Report the counts per interval
+	a.keys = make(map[string]*counter)
+	a.syscalls = make(map[string]*counter)
+	a.events, a.dropped, a.lost = 0, 0, 0
+
+	return nil
+}
+
+func (a *Auditd) Stop() {
+	if a.netlink != nil {
+		a.netlink.stop()
+		a.netlink = nil
+	}
+	if a.logfile != nil {
+		a.logfile.close()
+		a.logfile = nil
+	}
+}
+
+// handleLine processes a line of the audit log
+func (a *Auditd) handleLine(line string) {
+	if !strings.HasPrefix(line, "type=SYSCALL ") {
+		return
+	}
+	a.handleRecord(line)
+}
+
+// handleRecord processes the text of an audit SYSCALL record
+func (a *Auditd) handleRecord(text string) {
+	event, err := parseSyscallRecord(text)
+	if err != nil {
+		a.Log.Debugf("Ignoring record %q: %v", text, err)
+		return
+	}
+	a.add(event)
+}
+
+func (a *Auditd) handleLost() {
+	a.Lock()
+	a.lost++
+	a.Unlock()
+}
+
+func (a *Auditd) add(event *syscallEvent) {
+	a.Lock()
+	defer a.Unlock()
+
+	// Limit the events using their timestamp so the limit applies
+	// independently of the delivery of the events
+	if a.MaxEventsPerSecond > 0 {
+		if event.timestamp != a.second {
+			a.second = event.timestamp
+			a.inSecond = 0
+		}
+		a.inSecond++
+		if a.inSecond > a.MaxEventsPerSecond {
+			a.dropped++
+			return
+		}
+	}
+	a.events++
+
+	if len(event.keys) == 0 && !a.IncludeUnkeyed {
+		return
+	}
+	for _, key := range event.keys {
+		if _, found := a.keys[key]; !found && len(a.keys) >= a.MaxKeys {
+			key = overflowKey
+		}
+		a.keys[key] = a.keys[key].add(event.success)
+	}
+	a.syscalls[event.syscall] = a.syscalls[event.syscall].add(event.success)
+}
+
+func (c *counter) add(success bool) *counter {
+	if c == nil {
+		c = &counter{}
+	}
+	c.hits++
+	if !success {
+		c.failures++
+	}
+	return c
+}
+
+func (c *counter) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"hits":         c.hits,
+		"failures":     c.failures,
+		"failure_rate": float64(c.failures) / float64(c.hits),
+	}
+}
+
+// parseSyscallRecord parses the text of a SYSCALL record as received via
+// netlink, e.g. `audit(1700000000.123:456): arch=c000003e syscall=257 ...`,
+// or as written to the audit log, prefixed by `type=SYSCALL msg=`
+func parseSyscallRecord(text string) (*syscallEvent, error) {
+	start := strings.Index(text, "audit(")
+	if start < 0 {
+		return nil, errors.New("missing header")
+	}
+	header, body, found := strings.Cut(text[start+len("audit("):], "):")
+	if !found {
+		return nil, errors.New("invalid header")
+	}
+	seconds, _, _ := strings.Cut(header, ".")
+	timestamp, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	event := &syscallEvent{timestamp: timestamp}
+	var hasSuccess bool
+	for _, field := range strings.Fields(body) {
+		name, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+		switch name {
+		case "syscall":
+			event.syscall = value
+		case "success":
+			event.success = value == "yes"
+			hasSuccess = true
+		case "key":
+			event.keys = parseKeys(value)
+		}
+	}
+	if event.syscall == "" || !hasSuccess {
+		return nil, errors.New("missing syscall information")
+	}
+
+	return event, nil
+}
+
+// parseKeys returns the keys of the key field. Keys are quoted unless they
+// contain special characters in which case they are hex encoded.
+func parseKeys(value string) []string {
+	if value == "(null)" || value == "" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	} else if decoded, err := hex.DecodeString(value); err == nil {
+		value = string(decoded)
+	}
+	return strings.Split(value, keySeparator)
+}
+
+// logReader reads the lines appended to the audit log and handles log rotation
+type logReader struct {
+	path   string
+	file   *os.File
+	info   os.FileInfo
+	offset int64
+}
+
+// newLogReader opens the given log file skipping its current content
+func newLogReader(path string) (*logReader, error) {
+	r := &logReader{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	r.offset = r.info.Size()
+	return r, nil
+}
+
+func (r *logReader) open() error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.close()
+	r.file, r.info, r.offset = file, info, 0
+	return nil
+}
+
+func (r *logReader) read(handle func(string)) error {
+	// Start from the beginning of a new file if the log was rotated
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(info, r.info) || info.Size() < r.offset {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.file.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(r.file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// Incomplete lines are read again with the next call
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		r.offset += int64(len(line))
+		handle(strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func (r *logReader) close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func init() {
+	inputs.Add("auditd", func() telegraf.Input {
+		return &Auditd{
+			Source:             "netlink",
+			File:               "/var/log/audit/audit.log",
+			MaxEventsPerSecond: 1000,
+			MaxKeys:            100,
+		}
+	})
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package auditd
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// Multicast group of the kernel for reading audit events
+	auditNetlinkGroupReadLog = 1
+
+	// Message type of SYSCALL records
+	auditSyscall = 1300
+)
+
+// netlinkReceiver receives the audit events from the kernel via the audit
+// multicast group. Subscribing to the group does not interfere with auditd.
+type netlinkReceiver struct {
+	fd   int
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newNetlinkReceiver() (*netlinkReceiver, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_AUDIT)
+	if err != nil {
+		return nil, err
+	}
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: auditNetlinkGroupReadLog}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	// Use a receive timeout to be able to check for stopping
+	timeout := &unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, timeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return &netlinkReceiver{fd: fd, done: make(chan struct{})}, nil
+}
+
+func (r *netlinkReceiver) run(handle func(string), lost func(), log telegraf.Logger) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		buf := make([]byte, unix.Getpagesize()*4)
+		for {
+			select {
+			case <-r.done:
+				return
+			default:
+			}
+
+			n, _, err := unix.Recvfrom(r.fd, buf, 0)
+			if err != nil {
+				switch {
+				case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+				case errors.Is(err, unix.ENOBUFS):
+					// The kernel dropped messages as we did not keep up
+					lost()
+				default:
+					log.Errorf("Receiving audit events failed: %v", err)
+				}
+				continue
+			}
+
+			msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+			if err != nil {
+				log.Debugf("Parsing netlink message failed: %v", err)
+				continue
+			}
+			for _, msg := range msgs {
+				if msg.Header.Type == auditSyscall {
+					handle(strings.TrimRight(string(msg.Data), "\x00"))
+				}
+			}
+		}
+	}()
+}
+
+func (r *netlinkReceiver) stop() {
+	close(r.done)
+	r.wg.Wait()
+	unix.Close(r.fd)
+}
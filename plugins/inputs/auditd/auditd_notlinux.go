@@ -0,0 +1,19 @@
+//go:build !linux
+
+package auditd
+
+import (
+	"errors"
+
+	"github.com/influxdata/telegraf"
+)
+
+type netlinkReceiver struct{}
+
+func newNetlinkReceiver() (*netlinkReceiver, error) {
+	return nil, errors.New("the netlink source is only supported on Linux")
+}
+
+func (*netlinkReceiver) run(func(string), func(), telegraf.Logger) {}
+
+func (*netlinkReceiver) stop() {}
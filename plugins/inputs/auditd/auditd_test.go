@@ -0,0 +1,239 @@
+package auditd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Auditd{Source: "journald"}
+	require.ErrorContains(t, plugin.Init(), `invalid source "journald"`)
+
+	plugin = &Auditd{MaxEventsPerSecond: -1}
+	require.ErrorContains(t, plugin.Init(), "invalid maximum events per second -1")
+}
+
+func TestParseSyscallRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected *syscallEvent
+	}{
+		{
+			name: "netlink",
+			text: `audit(1700000000.123:456): arch=c000003e syscall=257 success=no exit=-13 a0=ffffff9c ` +
+				`ppid=1 pid=4242 auid=1000 uid=1000 comm="cat" exe="/usr/bin/cat" key="passwd"`,
+			expected: &syscallEvent{timestamp: 1700000000, keys: []string{"passwd"}, syscall: "257"},
+		},
+		{
+			name: "log file",
+			text: `type=SYSCALL msg=audit(1700000001.001:457): arch=c000003e syscall=59 success=yes exit=0 ` +
+				`comm="sudo" exe="/usr/bin/sudo" key="exec"`,
+			expected: &syscallEvent{timestamp: 1700000001, keys: []string{"exec"}, syscall: "59", success: true},
+		},
+		{
+			name:     "hex encoded keys",
+			text:     `audit(1700000002.000:458): arch=c000003e syscall=2 success=yes exit=3 key=6964656E746974790161757468`,
+			expected: &syscallEvent{timestamp: 1700000002, keys: []string{"identity", "auth"}, syscall: "2", success: true},
+		},
+		{
+			name:     "no key",
+			text:     `audit(1700000003.000:459): arch=c000003e syscall=2 success=yes exit=3 key=(null)`,
+			expected: &syscallEvent{timestamp: 1700000003, syscall: "2", success: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseSyscallRecord(tt.text)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseSyscallRecordInvalid(t *testing.T) {
+	_, err := parseSyscallRecord(`type=PATH msg=audit(1700000000.123:456): item=0 name="/etc/passwd"`)
+	require.ErrorContains(t, err, "missing syscall information")
+
+	_, err = parseSyscallRecord(`arch=c000003e syscall=257 success=no`)
+	require.ErrorContains(t, err, "missing header")
+}
+
+func TestAggregation(t *testing.T) {
+	plugin := &Auditd{
+		MaxEventsPerSecond: 3,
+		MaxKeys:            2,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	records := []string{
+		`audit(1700000000.100:1): arch=c000003e syscall=257 success=no exit=-13 key="passwd"`,
+		`audit(1700000000.200:2): arch=c000003e syscall=257 success=yes exit=3 key="passwd"`,
+		`audit(1700000000.300:3): arch=c000003e syscall=59 success=yes exit=0 key="exec"`,
+		`audit(1700000000.400:4): arch=c000003e syscall=59 success=yes exit=0 key="exec"`, // rate limited
+		`audit(1700000001.100:5): arch=c000003e syscall=42 success=no exit=-111 key="network"`,
+		`audit(1700000001.200:6): arch=c000003e syscall=2 success=yes exit=3 key=(null)`,
+	}
+	for _, record := range records {
+		plugin.handleRecord(record)
+	}
+	plugin.handleLost()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"auditd",
+			map[string]string{"key": "passwd"},
+			map[string]interface{}{"hits": int64(2), "failures": int64(1), "failure_rate": 0.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd",
+			map[string]string{"key": "exec"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(0), "failure_rate": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd",
+			map[string]string{"key": "_overflow"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(1), "failure_rate": 1.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_syscall",
+			map[string]string{"syscall": "257"},
+			map[string]interface{}{"hits": int64(2), "failures": int64(1), "failure_rate": 0.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_syscall",
+			map[string]string{"syscall": "59"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(0), "failure_rate": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_syscall",
+			map[string]string{"syscall": "42"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(1), "failure_rate": 1.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_summary",
+			map[string]string{},
+			map[string]interface{}{"events": int64(5), "dropped": int64(1), "lost": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The counts are reset after each gather
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{
+		metric.New(
+			"auditd_summary",
+			map[string]string{},
+			map[string]interface{}{"events": int64(0), "dropped": int64(0), "lost": int64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestFileSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	history := `type=SYSCALL msg=audit(1700000000.100:1): arch=c000003e syscall=257 success=no exit=-13 key="passwd"` + "\n"
+	require.NoError(t, os.WriteFile(filename, []byte(history), 0600))
+
+	plugin := &Auditd{
+		Source:         "file",
+		File:           filename,
+		IncludeUnkeyed: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Append new events including an incomplete line
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(
+		`type=SYSCALL msg=audit(1700000010.100:2): arch=c000003e syscall=59 success=yes exit=0 key="exec"` + "\n" +
+			`type=PATH msg=audit(1700000010.100:2): item=0 name="/usr/bin/sudo"` + "\n" +
+			`type=SYSCALL msg=audit(1700000010.200:3): arch=c000003e syscall=2 success=no exit=-2 key=(null)` + "\n" +
+			`type=SYSCALL msg=audit(1700000010.300:4): arch=c000003e sysc`,
+	)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"auditd",
+			map[string]string{"key": "exec"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(0), "failure_rate": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_syscall",
+			map[string]string{"syscall": "59"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(0), "failure_rate": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_syscall",
+			map[string]string{"syscall": "2"},
+			map[string]interface{}{"hits": int64(1), "failures": int64(1), "failure_rate": 1.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"auditd_summary",
+			map[string]string{},
+			map[string]interface{}{"events": int64(2), "dropped": int64(0), "lost": int64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Complete the line and rotate the log afterwards
+	f, err = os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`all=59 success=yes exit=0 key="exec"` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	summary, found := acc.Get("auditd_summary")
+	require.True(t, found)
+	require.Equal(t, int64(1), summary.Fields["events"])
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	rotated := `type=SYSCALL msg=audit(1700000020.100:5): arch=c000003e syscall=59 success=yes exit=0 key="exec"` + "\n"
+	require.NoError(t, os.WriteFile(filename, []byte(rotated), 0600))
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	summary, found = acc.Get("auditd_summary")
+	require.True(t, found)
+	require.Equal(t, int64(1), summary.Fields["events"])
+}
@@ -134,10 +134,42 @@ func gatherBcache(bdev string, acc telegraf.Accumulator) error {
 			fields[key] = value
 		}
 	}
+	gatherWritebackState(bdev, fields)
+
 	acc.AddFields("bcache", fields, tags)
 	return nil
 }
 
+// gatherWritebackState adds the state of the backing device and its writeback
+// to the fields. Files not available, e.g. on older kernels, are skipped.
+func gatherWritebackState(bdev string, fields map[string]interface{}) {
+	if state, ok := readValue(bdev + "/state"); ok {
+		fields["state"] = state
+	}
+	// The active mode is marked with brackets, e.g. "writethrough [writeback]"
+	if modes, ok := readValue(bdev + "/cache_mode"); ok {
+		for _, mode := range strings.Fields(modes) {
+			if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+				fields["cache_mode"] = strings.Trim(mode, "[]")
+			}
+		}
+	}
+	if running, ok := readValue(bdev + "/writeback_running"); ok {
+		fields["writeback_running"] = running == "1"
+	}
+	if rate, ok := readValue(bdev + "/writeback_rate"); ok && rate != "" {
+		fields["writeback_rate"] = prettyToBytes(rate)
+	}
+}
+
+func readValue(path string) (string, bool) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(file)), true
+}
+
 func init() {
 	inputs.Add("bcache", func() telegraf.Input {
 		return &Bcache{}
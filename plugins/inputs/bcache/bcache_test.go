@@ -109,3 +109,43 @@ func TestBcacheGeneratesMetrics(t *testing.T) {
 	require.NoError(t, err)
 	acc.AssertContainsTaggedFields(t, "bcache", fields, tags)
 }
+
+func TestBcacheWritebackState(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testBcacheUUIDPath := tmpDir + "/sys/fs/bcache/663955a3-765a-4737-a9fd-8250a7a78411"
+	testBcacheDevPath := tmpDir + "/sys/devices/virtual/block/bcache0"
+	testBcacheBackingDevPath := tmpDir + "/sys/devices/virtual/block/md10"
+
+	require.NoError(t, os.MkdirAll(testBcacheUUIDPath, 0750))
+	require.NoError(t, os.MkdirAll(testBcacheDevPath, 0750))
+	require.NoError(t, os.MkdirAll(testBcacheBackingDevPath+"/bcache/stats_total", 0750))
+	require.NoError(t, os.Symlink(testBcacheBackingDevPath+"/bcache", testBcacheUUIDPath+"/bdev0"))
+	require.NoError(t, os.Symlink(testBcacheDevPath, testBcacheUUIDPath+"/bdev0/dev"))
+
+	files := map[string]string{
+		"dirty_data":                  dirtyData,
+		"state":                       "dirty",
+		"cache_mode":                  "writethrough [writeback] writearound none",
+		"writeback_running":           "1",
+		"writeback_rate":              "4.0k",
+		"stats_total/cache_hit_ratio": cacheHitRatio,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(testBcacheUUIDPath+"/bdev0/"+name, []byte(content+"\n"), 0640))
+	}
+
+	var acc testutil.Accumulator
+	b := &Bcache{BcachePath: tmpDir + "/sys/fs/bcache"}
+	require.NoError(t, b.Gather(&acc))
+
+	fields := map[string]interface{}{
+		"dirty_data":        uint64(1610612736),
+		"cache_hit_ratio":   uint64(90),
+		"state":             "dirty",
+		"cache_mode":        "writeback",
+		"writeback_running": true,
+		"writeback_rate":    uint64(4096),
+	}
+	acc.AssertContainsTaggedFields(t, "bcache", fields, map[string]string{"backing_dev": "md10", "bcache_dev": "bcache0"})
+}
@@ -0,0 +1,222 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cassandra_native
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/toml"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common "github.com/influxdata/telegraf/plugins/common/jolokia2"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+//go:embed cassandra.toml
+var cassandraProfile []byte
+
+// Names of the latency attributes of Cassandra timers and the field suffix
+// they are reported as, all latencies are in microseconds
+var latencyFields = map[string]string{
+	"Count":          "count",
+	"Mean":           "latency_mean_us",
+	"Min":            "latency_min_us",
+	"Max":            "latency_max_us",
+	"99thPercentile": "latency_p99_us",
+}
+
+// Fields reported as integer
+var integerFields = map[string]bool{
+	"pending_tasks":       true,
+	"completed_tasks":     true,
+	"in_progress":         true,
+	"total":               true,
+	"pending_compactions": true,
+	"read_count":          true,
+	"write_count":         true,
+}
+
+type CassandraNative struct {
+	URLs            []string        `toml:"urls"`
+	Flavor          string          `toml:"flavor"`
+	Username        string          `toml:"username"`
+	Password        string          `toml:"password"`
+	KeyspaceInclude []string        `toml:"keyspace_include"`
+	KeyspaceExclude []string        `toml:"keyspace_exclude"`
+	ResponseTimeout config.Duration `toml:"response_timeout"`
+	Log             telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	keyspaces filter.Filter
+
+	// Jolokia gatherer and clients for Cassandra
+	gatherer *common.Gatherer
+	clients  []*common.Client
+
+	// HTTP client for the Scylla REST API
+	client *http.Client
+}
+
+func (*CassandraNative) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CassandraNative) Init() error {
+	if len(c.URLs) == 0 {
+		return errors.New("no URLs configured")
+	}
+
+	keyspaces, err := filter.NewIncludeExcludeFilter(c.KeyspaceInclude, c.KeyspaceExclude)
+	if err != nil {
+		return fmt.Errorf("creating keyspace filter failed: %w", err)
+	}
+	c.keyspaces = keyspaces
+
+	switch c.Flavor {
+	case "", "cassandra":
+		c.Flavor = "cassandra"
+		return c.initCassandra()
+	case "scylla":
+		return c.initScylla()
+	}
+	return fmt.Errorf("invalid flavor %q", c.Flavor)
+}
+
+func (c *CassandraNative) initCassandra() error {
+	var profile struct {
+		Metrics []common.MetricConfig `toml:"metric"`
+	}
+	if err := toml.Unmarshal(cassandraProfile, &profile); err != nil {
+		return fmt.Errorf("parsing metric definitions failed: %w", err)
+	}
+
+	metrics := make([]common.Metric, 0, len(profile.Metrics))
+	for _, cfg := range profile.Metrics {
+		metrics = append(metrics, common.NewMetric(cfg, "", ".", ""))
+	}
+	c.gatherer = common.NewGatherer(metrics)
+
+	c.clients = make([]*common.Client, 0, len(c.URLs))
+	for _, u := range c.URLs {
+		client, err := common.NewClient(u, &common.ClientConfig{
+			Username:        c.Username,
+			Password:        c.Password,
+			ResponseTimeout: time.Duration(c.ResponseTimeout),
+			ClientConfig:    c.ClientConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("creating client for %q failed: %w", u, err)
+		}
+		c.clients = append(c.clients, client)
+	}
+
+	return nil
+}
+
+func (c *CassandraNative) initScylla() error {
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: time.Duration(c.ResponseTimeout),
+	}
+
+	return nil
+}
+
+func (c *CassandraNative) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	if c.Flavor == "scylla" {
+		for _, u := range c.URLs {
+			wg.Add(1)
+			go func(address string) {
+				defer wg.Done()
+				if err := c.gatherScylla(acc, address); err != nil {
+					acc.AddError(fmt.Errorf("gathering metrics from %q failed: %w", address, err))
+				}
+			}(u)
+		}
+		wg.Wait()
+		return nil
+	}
+
+	for _, client := range c.clients {
+		wg.Add(1)
+		go func(client *common.Client) {
+			defer wg.Done()
+			cacc := &cassandraAccumulator{Accumulator: acc, keyspaces: c.keyspaces}
+			if err := c.gatherer.Gather(client, cacc); err != nil {
+				acc.AddError(fmt.Errorf("gathering metrics from %q failed: %w", client.URL, err))
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// cassandraAccumulator normalizes the metrics produced from the Jolokia
+// responses and applies the keyspace filter
+type cassandraAccumulator struct {
+	telegraf.Accumulator
+	keyspaces filter.Filter
+}
+
+func (a *cassandraAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if keyspace, found := tags["keyspace"]; found && !a.keyspaces.Match(keyspace) {
+		return
+	}
+
+	normalized := make(map[string]string, len(tags))
+	for k, v := range tags {
+		switch k {
+		case "jolokia_agent_url":
+			k = "url"
+		case "scope":
+			k = "table"
+		}
+		normalized[k] = v
+	}
+
+	for _, prefix := range []string{"read_", "write_"} {
+		for attribute, suffix := range latencyFields {
+			if v, found := fields[prefix+attribute]; found {
+				delete(fields, prefix+attribute)
+				fields[prefix+suffix] = v
+			}
+		}
+	}
+
+	// Jolokia returns all numbers as float, convert the counters to integers
+	// to match the Scylla metrics
+	for k, v := range fields {
+		if f, ok := v.(float64); ok && integerFields[k] {
+			fields[k] = int64(f)
+		}
+	}
+
+	a.Accumulator.AddFields(measurement, fields, normalized, t...)
+}
+
+func init() {
+	inputs.Add("cassandra_native", func() telegraf.Input {
+		return &CassandraNative{
+			ResponseTimeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package cassandra_native
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &CassandraNative{}
+	require.ErrorContains(t, plugin.Init(), "no URLs configured")
+
+	plugin = &CassandraNative{
+		URLs:   []string{"http://localhost:10000"},
+		Flavor: "mongodb",
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid flavor "mongodb"`)
+}
+
+func TestCassandra(t *testing.T) {
+	timer := func(count int, mean, p99 float64) map[string]interface{} {
+		return map[string]interface{}{
+			"Count":          count,
+			"Mean":           mean,
+			"Min":            10.0,
+			"Max":            900.0,
+			"99thPercentile": p99,
+		}
+	}
+	values := map[string]interface{}{
+		"org.apache.cassandra.metrics:type=Compaction,name=PendingTasks":      3,
+		"org.apache.cassandra.metrics:type=Compaction,name=CompletedTasks":    1532,
+		"org.apache.cassandra.metrics:type=Storage,name=TotalHintsInProgress": 0,
+		"org.apache.cassandra.metrics:type=Storage,name=TotalHints":           12,
+		"org.apache.cassandra.metrics:type=Table,keyspace=*,scope=*,name=PendingCompactions": map[string]interface{}{
+			"org.apache.cassandra.metrics:keyspace=shop,name=PendingCompactions,scope=orders,type=Table":  map[string]interface{}{"Value": 1},
+			"org.apache.cassandra.metrics:keyspace=system,name=PendingCompactions,scope=local,type=Table": map[string]interface{}{"Value": 0},
+		},
+		"org.apache.cassandra.metrics:type=Table,keyspace=*,scope=*,name=ReadLatency": map[string]interface{}{
+			"org.apache.cassandra.metrics:keyspace=shop,name=ReadLatency,scope=orders,type=Table":  timer(52311, 412.5, 1200.0),
+			"org.apache.cassandra.metrics:keyspace=system,name=ReadLatency,scope=local,type=Table": timer(10, 20.0, 30.0),
+		},
+		"org.apache.cassandra.metrics:type=Table,keyspace=*,scope=*,name=WriteLatency": map[string]interface{}{
+			"org.apache.cassandra.metrics:keyspace=shop,name=WriteLatency,scope=orders,type=Table":  timer(90412, 58.2, 210.0),
+			"org.apache.cassandra.metrics:keyspace=system,name=WriteLatency,scope=local,type=Table": timer(10, 20.0, 30.0),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Answer all requests of the bulk request
+		responses := make([]map[string]interface{}, 0, len(requests))
+		for _, req := range requests {
+			value, found := values[req["mbean"].(string)]
+			if !found {
+				responses = append(responses, map[string]interface{}{"request": req, "status": 404})
+				continue
+			}
+			responses = append(responses, map[string]interface{}{"request": req, "value": value, "status": 200})
+		}
+		if err := json.NewEncoder(w).Encode(responses); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &CassandraNative{
+		URLs:            []string{server.URL},
+		KeyspaceExclude: []string{"system*"},
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{"url": server.URL}
+	tableTags := map[string]string{"url": server.URL, "keyspace": "shop", "table": "orders"}
+	expected := []telegraf.Metric{
+		metric.New(
+			"cassandra_compaction",
+			tags,
+			map[string]interface{}{"pending_tasks": int64(3), "completed_tasks": int64(1532)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cassandra_hints",
+			tags,
+			map[string]interface{}{"in_progress": int64(0), "total": int64(12)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cassandra_table",
+			tableTags,
+			map[string]interface{}{
+				"pending_compactions":   int64(1),
+				"read_count":            int64(52311),
+				"read_latency_mean_us":  412.5,
+				"read_latency_min_us":   10.0,
+				"read_latency_max_us":   900.0,
+				"read_latency_p99_us":   1200.0,
+				"write_count":           int64(90412),
+				"write_latency_mean_us": 58.2,
+				"write_latency_min_us":  10.0,
+				"write_latency_max_us":  900.0,
+				"write_latency_p99_us":  210.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestScylla(t *testing.T) {
+	responses := map[string]string{
+		"/compaction_manager/metrics/pending_tasks":                   `3`,
+		"/compaction_manager/metrics/completed_tasks":                 `1532`,
+		"/storage_proxy/hints_in_progress":                            `0`,
+		"/storage_proxy/total_hints":                                  `12`,
+		"/column_family/name":                                         `["shop:orders", "system:local"]`,
+		"/column_family/metrics/pending_compactions/shop:orders":      `1`,
+		"/column_family/metrics/read_latency/histogram/shop:orders":   `{"count": 52311, "sum": 21578287, "min": 35, "max": 8120, "mean": 412.5, "variance": 12.1, "sample": [40, 50]}`,
+		"/column_family/metrics/write_latency/histogram/shop:orders":  `{"count": 90412, "sum": 5261978, "min": 9, "max": 2210, "mean": 58.2, "variance": 3.4, "sample": []}`,
+		"/column_family/metrics/pending_compactions/system:local":     `0`,
+		"/column_family/metrics/read_latency/histogram/system:local":  `{"count": 1, "min": 1, "max": 1, "mean": 1}`,
+		"/column_family/metrics/write_latency/histogram/system:local": `{"count": 1, "min": 1, "max": 1, "mean": 1}`,
+	}
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		body, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &CassandraNative{
+		URLs:            []string{server.URL},
+		Flavor:          "scylla",
+		KeyspaceInclude: []string{"shop"},
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// Excluded keyspaces are not requested
+	require.NotContains(t, requested, "/column_family/metrics/pending_compactions/system:local")
+
+	tags := map[string]string{"url": server.URL}
+	expected := []telegraf.Metric{
+		metric.New(
+			"cassandra_compaction",
+			tags,
+			map[string]interface{}{"pending_tasks": int64(3), "completed_tasks": int64(1532)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cassandra_hints",
+			tags,
+			map[string]interface{}{"in_progress": int64(0), "total": int64(12)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cassandra_table",
+			map[string]string{"url": server.URL, "keyspace": "shop", "table": "orders"},
+			map[string]interface{}{
+				"pending_compactions":   int64(1),
+				"read_count":            int64(52311),
+				"read_latency_mean_us":  412.5,
+				"read_latency_min_us":   int64(35),
+				"read_latency_max_us":   int64(8120),
+				"write_count":           int64(90412),
+				"write_latency_mean_us": 58.2,
+				"write_latency_min_us":  int64(9),
+				"write_latency_max_us":  int64(2210),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestScyllaError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	plugin := &CassandraNative{
+		URLs:   []string{server.URL},
+		Flavor: "scylla",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "500 Internal Server Error")
+}
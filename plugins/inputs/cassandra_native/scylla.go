@@ -0,0 +1,131 @@
+package cassandra_native
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// scyllaHistogram is the latency histogram returned by the Scylla REST API,
+// all values are in microseconds
+type scyllaHistogram struct {
+	Count int64   `json:"count"`
+	Min   int64   `json:"min"`
+	Max   int64   `json:"max"`
+	Mean  float64 `json:"mean"`
+}
+
+func (c *CassandraNative) gatherScylla(acc telegraf.Accumulator, address string) error {
+	now := time.Now()
+	base := strings.TrimSuffix(address, "/")
+	tags := map[string]string{"url": address}
+
+	var pending, completed int64
+	if err := c.getJSON(base+"/compaction_manager/metrics/pending_tasks", &pending); err != nil {
+		return err
+	}
+	if err := c.getJSON(base+"/compaction_manager/metrics/completed_tasks", &completed); err != nil {
+		return err
+	}
+	acc.AddFields("cassandra_compaction", map[string]interface{}{
+		"pending_tasks":   pending,
+		"completed_tasks": completed,
+	}, tags, now)
+
+	var inProgress, total int64
+	if err := c.getJSON(base+"/storage_proxy/hints_in_progress", &inProgress); err != nil {
+		return err
+	}
+	if err := c.getJSON(base+"/storage_proxy/total_hints", &total); err != nil {
+		return err
+	}
+	acc.AddFields("cassandra_hints", map[string]interface{}{
+		"in_progress": inProgress,
+		"total":       total,
+	}, tags, now)
+
+	var tables []string
+	if err := c.getJSON(base+"/column_family/name", &tables); err != nil {
+		return err
+	}
+	for _, name := range tables {
+		keyspace, table, ok := strings.Cut(name, ":")
+		if !ok {
+			c.Log.Debugf("Ignoring table with unexpected name %q", name)
+			continue
+		}
+		if !c.keyspaces.Match(keyspace) {
+			continue
+		}
+
+		escaped := url.PathEscape(name)
+		var pendingCompactions int64
+		if err := c.getJSON(base+"/column_family/metrics/pending_compactions/"+escaped, &pendingCompactions); err != nil {
+			acc.AddError(err)
+			continue
+		}
+		var read, write scyllaHistogram
+		if err := c.getJSON(base+"/column_family/metrics/read_latency/histogram/"+escaped, &read); err != nil {
+			acc.AddError(err)
+			continue
+		}
+		if err := c.getJSON(base+"/column_family/metrics/write_latency/histogram/"+escaped, &write); err != nil {
+			acc.AddError(err)
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"pending_compactions":   pendingCompactions,
+			"read_count":            read.Count,
+			"read_latency_mean_us":  read.Mean,
+			"read_latency_min_us":   read.Min,
+			"read_latency_max_us":   read.Max,
+			"write_count":           write.Count,
+			"write_latency_mean_us": write.Mean,
+			"write_latency_min_us":  write.Min,
+			"write_latency_max_us":  write.Max,
+		}
+		tableTags := map[string]string{
+			"url":      address,
+			"keyspace": keyspace,
+			"table":    table,
+		}
+		acc.AddFields("cassandra_table", fields, tableTags, now)
+	}
+
+	return nil
+}
+
+func (c *CassandraNative) getJSON(address string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		//nolint:errcheck // Body is only read for providing more context
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("requesting %q returned status %q: %s", address, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %q failed: %w", address, err)
+	}
+	return nil
+}
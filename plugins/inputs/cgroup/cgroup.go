@@ -3,19 +3,42 @@ package cgroup
 
 import (
 	_ "embed"
+	"fmt"
+	"path/filepath"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
+// defaultUnifiedFiles are the files gathered for the cgroups discovered in
+// the unified hierarchy if no files are configured
+var defaultUnifiedFiles = []string{
+	"cpu.stat",
+	"memory.current",
+	"memory.max",
+	"memory.events",
+	"memory.swap.current",
+	"io.stat",
+	"pids.current",
+	"*.pressure",
+}
+
 type CGroup struct {
-	Paths []string `toml:"paths"`
-	Files []string `toml:"files"`
+	Paths         []string `toml:"paths"`
+	Files         []string `toml:"files"`
+	CgroupInclude []string `toml:"cgroup_include"`
+	CgroupExclude []string `toml:"cgroup_exclude"`
 
 	logged map[string]bool
+	// root is the mount point of the unified hierarchy used for discovering
+	// the cgroups if no paths are configured
+	root   string
+	filter filter.Filter
 }
 
 func (*CGroup) SampleConfig() string {
@@ -25,7 +48,24 @@ func (*CGroup) SampleConfig() string {
 func (cg *CGroup) Init() error {
 	cg.logged = make(map[string]bool)
 
-	return nil
+	// Discover the cgroups of the unified hierarchy if no paths are given
+	if len(cg.Paths) > 0 {
+		return nil
+	}
+	if cg.root == "" {
+		cg.root = filepath.Join(internal.GetSysPath(), "fs", "cgroup")
+	}
+	if len(cg.Files) == 0 {
+		cg.Files = defaultUnifiedFiles
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(cg.CgroupInclude, cg.CgroupExclude)
+	if err != nil {
+		return fmt.Errorf("creating cgroup filter failed: %w", err)
+	}
+	cg.filter = f
+
+	return cg.checkUnified()
 }
 
 func init() {
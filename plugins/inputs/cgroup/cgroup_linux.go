@@ -3,7 +3,9 @@
 package cgroup
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
 	"path"
@@ -26,18 +28,18 @@ func (cg *CGroup) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(dir.err)
 			continue
 		}
-		if err := cg.gatherDir(acc, dir.path); err != nil {
+		if err := cg.gatherDir(acc, dir); err != nil {
 			acc.AddError(err)
 		}
 	}
 	return nil
 }
 
-func (cg *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
+func (cg *CGroup) gatherDir(acc telegraf.Accumulator, dir pathInfo) error {
 	fields := make(map[string]interface{})
 
 	list := make(chan pathInfo)
-	go cg.generateFiles(dir, list)
+	go cg.generateFiles(dir.path, list)
 
 	for file := range list {
 		if file.err != nil {
@@ -62,18 +64,55 @@ func (cg *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
 		}
 	}
 
-	tags := map[string]string{"path": dir}
+	tags := map[string]string{"path": dir.path}
+	if dir.cgroup != "" {
+		tags["cgroup"] = dir.cgroup
+		slice, unit := systemdUnits(dir.cgroup)
+		if slice != "" {
+			tags["slice"] = slice
+		}
+		if unit != "" {
+			tags["unit"] = unit
+		}
+	}
 
 	acc.AddFields(metricName, fields, tags)
 
 	return nil
 }
 
+// checkUnified returns an error if the root is not the mount point of the
+// unified cgroup hierarchy
+func (cg *CGroup) checkUnified() error {
+	if _, err := os.Stat(filepath.Join(cg.root, "cgroup.controllers")); err != nil {
+		return fmt.Errorf("no paths configured and no unified cgroup hierarchy found at %q", cg.root)
+	}
+	return nil
+}
+
+// systemdUnits returns the innermost systemd slice and unit, i.e. service or
+// scope, contained in the given cgroup name
+func systemdUnits(cgroup string) (slice, unit string) {
+	for _, part := range strings.Split(cgroup, "/") {
+		switch {
+		case strings.HasSuffix(part, ".slice"):
+			slice = part
+			unit = ""
+		case strings.HasSuffix(part, ".service"), strings.HasSuffix(part, ".scope"):
+			unit = part
+		}
+	}
+	return slice, unit
+}
+
 // ======================================================================
 
 type pathInfo struct {
 	path string
-	err  error
+	// cgroup is the name of a discovered cgroup relative to the root of the
+	// unified hierarchy, e.g. "/system.slice/nginx.service"
+	cgroup string
+	err    error
 }
 
 func isDir(pathToCheck string) (bool, error) {
@@ -86,6 +125,10 @@ func isDir(pathToCheck string) (bool, error) {
 
 func (cg *CGroup) generateDirs(list chan<- pathInfo) {
 	defer close(list)
+	if len(cg.Paths) == 0 {
+		cg.discoverDirs(list)
+		return
+	}
 	for _, dir := range cg.Paths {
 		// getting all dirs that match the pattern 'dir'
 		items, err := filepath.Glob(dir)
@@ -108,6 +151,39 @@ func (cg *CGroup) generateDirs(list chan<- pathInfo) {
 	}
 }
 
+// discoverDirs walks the unified hierarchy and supplies the directories of all
+// cgroups passing the filter
+func (cg *CGroup) discoverDirs(list chan<- pathInfo) {
+	err := filepath.WalkDir(cg.root, func(item string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// cgroups might be removed while walking the hierarchy
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(cg.root, item)
+		if err != nil {
+			return err
+		}
+		name := "/"
+		if rel != "." {
+			name += filepath.ToSlash(rel)
+		}
+		if cg.filter == nil || cg.filter.Match(name) {
+			list <- pathInfo{path: item, cgroup: name}
+		}
+		return nil
+	})
+	if err != nil {
+		list <- pathInfo{err: err}
+	}
+}
+
 func (cg *CGroup) generateFiles(dir string, list chan<- pathInfo) {
 	dir = strings.Replace(dir, "\\", "\\\\", -1)
 
@@ -326,3 +326,134 @@ func TestCgroupV2Pids(t *testing.T) {
 	require.NoError(t, acc.GatherError(cg.Gather))
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
+
+func TestCgroupV2Discovery(t *testing.T) {
+	var acc testutil.Accumulator
+	var cg = &CGroup{
+		CgroupExclude: []string{"/"},
+		root:          "testdata/unified",
+	}
+	require.NoError(t, cg.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/unified/system.slice",
+				"cgroup": "/system.slice",
+				"slice":  "system.slice",
+			},
+			map[string]interface{}{
+				"memory.current": int64(4096000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/unified/system.slice/nginx.service",
+				"cgroup": "/system.slice/nginx.service",
+				"slice":  "system.slice",
+				"unit":   "nginx.service",
+			},
+			map[string]interface{}{
+				"cpu.stat.usage_usec":          int64(1200000),
+				"cpu.stat.user_usec":           int64(800000),
+				"cpu.stat.system_usec":         int64(400000),
+				"memory.current":               int64(2097152),
+				"memory.max":                   int64(math.MaxInt64),
+				"memory.events.low":            int64(0),
+				"memory.events.high":           int64(12),
+				"memory.events.max":            int64(3),
+				"memory.events.oom":            int64(1),
+				"memory.events.oom_kill":       int64(1),
+				"memory.events.oom_group_kill": int64(0),
+				"memory.pressure.some.avg10":   float64(1.5),
+				"memory.pressure.some.avg60":   float64(0.75),
+				"memory.pressure.some.avg300":  float64(0.25),
+				"memory.pressure.some.total":   int64(1234567),
+				"memory.pressure.full.avg10":   float64(0.5),
+				"memory.pressure.full.avg60":   float64(0.25),
+				"memory.pressure.full.avg300":  float64(0.1),
+				"memory.pressure.full.total":   int64(654321),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/unified/user.slice/user-1000.slice/session-2.scope",
+				"cgroup": "/user.slice/user-1000.slice/session-2.scope",
+				"slice":  "user-1000.slice",
+				"unit":   "session-2.scope",
+			},
+			map[string]interface{}{
+				"memory.current": int64(1048576),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.NoError(t, acc.GatherError(cg.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestCgroupV2DiscoveryFilter(t *testing.T) {
+	var acc testutil.Accumulator
+	var cg = &CGroup{
+		Files:         []string{"memory.current"},
+		CgroupInclude: []string{"/system.slice/*", "/user.slice/*"},
+		CgroupExclude: []string{"/user.slice/*.scope"},
+		root:          "testdata/unified",
+	}
+	require.NoError(t, cg.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/unified/system.slice/nginx.service",
+				"cgroup": "/system.slice/nginx.service",
+				"slice":  "system.slice",
+				"unit":   "nginx.service",
+			},
+			map[string]interface{}{
+				"memory.current": int64(2097152),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.NoError(t, acc.GatherError(cg.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestCgroupV2DiscoveryNotUnified(t *testing.T) {
+	cg := &CGroup{root: "testdata/memory"}
+	require.ErrorContains(t, cg.Init(), `no unified cgroup hierarchy found at "testdata/memory"`)
+}
+
+func TestSystemdUnits(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		slice  string
+		unit   string
+	}{
+		{cgroup: "/"},
+		{cgroup: "/system.slice", slice: "system.slice"},
+		{cgroup: "/system.slice/docker.service/payload", slice: "system.slice", unit: "docker.service"},
+		{
+			cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/firefox.scope",
+			slice:  "app.slice",
+			unit:   "firefox.scope",
+		},
+		{cgroup: "/kubepods/burstable/pod1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cgroup, func(t *testing.T) {
+			slice, unit := systemdUnits(tt.cgroup)
+			require.Equal(t, tt.slice, slice)
+			require.Equal(t, tt.unit, unit)
+		})
+	}
+}
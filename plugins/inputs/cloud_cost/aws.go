@@ -0,0 +1,214 @@
+package cloud_cost
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+)
+
+// awsConfig configures querying AWS Cost Explorer
+type awsConfig struct {
+	CostMetric string `toml:"cost_metric"`
+	common_aws.CredentialConfig
+}
+
+type awsProvider struct {
+	metric   string
+	tagKey   string
+	region   string
+	endpoint string
+	cfg      aws.Config
+	client   *http.Client
+	signer   *v4.Signer
+}
+
+// ceRequest is the request of the Cost Explorer GetCostAndUsage action
+type ceRequest struct {
+	TimePeriod struct {
+		Start string `json:"Start"`
+		End   string `json:"End"`
+	} `json:"TimePeriod"`
+	Granularity   string      `json:"Granularity"`
+	Metrics       []string    `json:"Metrics"`
+	GroupBy       []ceGroupBy `json:"GroupBy"`
+	NextPageToken string      `json:"NextPageToken,omitempty"`
+}
+
+type ceGroupBy struct {
+	Type string `json:"Type"`
+	Key  string `json:"Key"`
+}
+
+// ceResponse is the response of the Cost Explorer GetCostAndUsage action
+type ceResponse struct {
+	NextPageToken string `json:"NextPageToken"`
+	ResultsByTime []struct {
+		TimePeriod struct {
+			Start string `json:"Start"`
+		} `json:"TimePeriod"`
+		Groups []struct {
+			Keys    []string `json:"Keys"`
+			Metrics map[string]struct {
+				Amount string `json:"Amount"`
+				Unit   string `json:"Unit"`
+			} `json:"Metrics"`
+		} `json:"Groups"`
+	} `json:"ResultsByTime"`
+}
+
+func (c *awsConfig) provider(tagKeys []string) (*awsProvider, error) {
+	switch c.CostMetric {
+	case "":
+		c.CostMetric = "UnblendedCost"
+	case "UnblendedCost", "BlendedCost", "AmortizedCost", "NetUnblendedCost", "NetAmortizedCost":
+	default:
+		return nil, fmt.Errorf("invalid cost metric %q", c.CostMetric)
+	}
+
+	// Cost Explorer allows to group by two keys, the first is the service
+	if len(tagKeys) > 1 {
+		return nil, errors.New("at most one tag can be mapped for AWS")
+	}
+
+	// The Cost Explorer API is only available in the us-east-1 region
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	endpoint := c.EndpointURL
+	if endpoint == "" {
+		endpoint = "https://ce." + c.Region + ".amazonaws.com"
+	}
+
+	cfg, err := c.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting credentials failed: %w", err)
+	}
+
+	p := &awsProvider{
+		metric:   c.CostMetric,
+		region:   c.Region,
+		endpoint: endpoint,
+		cfg:      cfg,
+		client:   &http.Client{},
+		signer:   v4.NewSigner(),
+	}
+	if len(tagKeys) > 0 {
+		p.tagKey = tagKeys[0]
+	}
+	return p, nil
+}
+
+func (*awsProvider) name() string {
+	return "aws"
+}
+
+func (p *awsProvider) query(ctx context.Context, start, end time.Time) ([]record, error) {
+	req := ceRequest{
+		Granularity: "DAILY",
+		Metrics:     []string{p.metric},
+		GroupBy:     []ceGroupBy{{Type: "DIMENSION", Key: "SERVICE"}},
+	}
+	req.TimePeriod.Start = start.Format(time.DateOnly)
+	req.TimePeriod.End = end.Format(time.DateOnly)
+	if p.tagKey != "" {
+		req.GroupBy = append(req.GroupBy, ceGroupBy{Type: "TAG", Key: p.tagKey})
+	}
+
+	var records []record
+	for {
+		resp, err := p.getCostAndUsage(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range resp.ResultsByTime {
+			day, err := time.Parse(time.DateOnly, result.TimePeriod.Start)
+			if err != nil {
+				return nil, fmt.Errorf("parsing date failed: %w", err)
+			}
+			for _, group := range result.Groups {
+				m, found := group.Metrics[p.metric]
+				if !found || len(group.Keys) == 0 {
+					continue
+				}
+				cost, err := strconv.ParseFloat(m.Amount, 64)
+				if err != nil {
+					return nil, fmt.Errorf("parsing cost of %q failed: %w", group.Keys[0], err)
+				}
+
+				r := record{
+					day:      day,
+					service:  group.Keys[0],
+					currency: m.Unit,
+					cost:     cost,
+				}
+				// Tag keys are returned as "<key>$<value>"
+				if p.tagKey != "" && len(group.Keys) > 1 {
+					r.tags = map[string]string{
+						p.tagKey: strings.TrimPrefix(group.Keys[1], p.tagKey+"$"),
+					}
+				}
+				records = append(records, r)
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return records, nil
+		}
+		req.NextPageToken = resp.NextPageToken
+	}
+}
+
+func (p *awsProvider) getCostAndUsage(ctx context.Context, r *ceRequest) (*ceResponse, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSInsightsIndexService.GetCostAndUsage")
+
+	creds, err := p.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving credentials failed: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "ce", p.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("signing request failed: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("received status %q: %s", resp.Status, msg)
+	}
+
+	var response ceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return &response, nil
+}
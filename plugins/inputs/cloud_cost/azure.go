@@ -0,0 +1,243 @@
+package cloud_cost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const (
+	azureManagementURL = "https://management.azure.com"
+	azureAPIVersion    = "2023-11-01"
+)
+
+// azureConfig configures querying Azure Cost Management
+type azureConfig struct {
+	Scope    string `toml:"scope"`
+	CostType string `toml:"cost_type"`
+}
+
+type azureProvider struct {
+	url      string
+	costType string
+	tagKey   string
+	cred     azcore.TokenCredential
+	client   *http.Client
+}
+
+// cmQuery is the request of the Cost Management query API
+type cmQuery struct {
+	Type       string `json:"type"`
+	Timeframe  string `json:"timeframe"`
+	TimePeriod struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"timePeriod"`
+	Dataset struct {
+		Granularity string                   `json:"granularity"`
+		Aggregation map[string]cmAggregation `json:"aggregation"`
+		Grouping    []cmGrouping             `json:"grouping"`
+	} `json:"dataset"`
+}
+
+type cmAggregation struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+}
+
+type cmGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// cmResult is the response of the Cost Management query API
+type cmResult struct {
+	Properties struct {
+		NextLink string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"properties"`
+}
+
+func (c *azureConfig) provider(tagKeys []string) (*azureProvider, error) {
+	if c.Scope == "" {
+		return nil, errors.New("'scope' must be set")
+	}
+
+	switch c.CostType {
+	case "":
+		c.CostType = "ActualCost"
+	case "ActualCost", "AmortizedCost":
+	default:
+		return nil, fmt.Errorf("invalid cost type %q", c.CostType)
+	}
+
+	// Cost Management allows to group by two keys, the first is the service
+	if len(tagKeys) > 1 {
+		return nil, errors.New("at most one tag can be mapped for Azure")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting credentials failed: %w", err)
+	}
+
+	scope := "/" + strings.Trim(c.Scope, "/")
+	p := &azureProvider{
+		url:      azureManagementURL + scope + "/providers/Microsoft.CostManagement/query?api-version=" + azureAPIVersion,
+		costType: c.CostType,
+		cred:     cred,
+		client:   &http.Client{},
+	}
+	if len(tagKeys) > 0 {
+		p.tagKey = tagKeys[0]
+	}
+	return p, nil
+}
+
+func (*azureProvider) name() string {
+	return "azure"
+}
+
+func (p *azureProvider) query(ctx context.Context, start, end time.Time) ([]record, error) {
+	q := cmQuery{
+		Type:      p.costType,
+		Timeframe: "Custom",
+	}
+	// The end of the time period is inclusive
+	q.TimePeriod.From = start.Format(time.RFC3339)
+	q.TimePeriod.To = end.Add(-time.Second).Format(time.RFC3339)
+	q.Dataset.Granularity = "Daily"
+	q.Dataset.Aggregation = map[string]cmAggregation{
+		"totalCost": {Name: "Cost", Function: "Sum"},
+	}
+	q.Dataset.Grouping = []cmGrouping{{Type: "Dimension", Name: "ServiceName"}}
+	if p.tagKey != "" {
+		q.Dataset.Grouping = append(q.Dataset.Grouping, cmGrouping{Type: "TagKey", Name: p.tagKey})
+	}
+
+	body, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	for url := p.url; url != ""; {
+		result, err := p.post(ctx, url, body)
+		if err != nil {
+			return nil, err
+		}
+		r, err := p.records(result)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r...)
+		url = result.Properties.NextLink
+	}
+
+	return records, nil
+}
+
+// records converts the rows of the result to records using the column names
+func (p *azureProvider) records(result *cmResult) ([]record, error) {
+	columns := make(map[string]int, len(result.Properties.Columns))
+	for i, c := range result.Properties.Columns {
+		columns[c.Name] = i
+	}
+	for _, name := range []string{"Cost", "UsageDate", "ServiceName"} {
+		if _, found := columns[name]; !found {
+			return nil, fmt.Errorf("column %q missing in result", name)
+		}
+	}
+
+	records := make([]record, 0, len(result.Properties.Rows))
+	for _, row := range result.Properties.Rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("unexpected number of columns %d", len(row))
+		}
+
+		cost, ok := row[columns["Cost"]].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for cost", row[columns["Cost"]])
+		}
+		// The usage date is returned as number, e.g. 20240131
+		date, ok := row[columns["UsageDate"]].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for usage date", row[columns["UsageDate"]])
+		}
+		day, err := time.Parse("20060102", strconv.FormatInt(int64(date), 10))
+		if err != nil {
+			return nil, fmt.Errorf("parsing usage date failed: %w", err)
+		}
+
+		r := record{
+			day:     day,
+			service: cellString(row, columns, "ServiceName"),
+			cost:    cost,
+		}
+		r.currency = cellString(row, columns, "Currency")
+		if p.tagKey != "" {
+			r.tags = map[string]string{p.tagKey: cellString(row, columns, "TagValue")}
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func (p *azureProvider) post(ctx context.Context, url string, body []byte) (*cmResult, error) {
+	token, err := p.cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{azureManagementURL + "/.default"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting token failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("received status %q: %s", resp.Status, msg)
+	}
+
+	var result cmResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return &result, nil
+}
+
+// cellString returns the string value of the given column in the row or an
+// empty string if the column does not exist
+func cellString(row []interface{}, columns map[string]int, name string) string {
+	i, found := columns[name]
+	if !found {
+		return ""
+	}
+	s, _ := row[i].(string)
+	return s
+}
@@ -0,0 +1,158 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cloud_cost
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CloudCost struct {
+	LookbackDays int               `toml:"lookback_days"`
+	Timeout      config.Duration   `toml:"timeout"`
+	TagMapping   map[string]string `toml:"tag_mapping"`
+	AWS          *awsConfig        `toml:"aws"`
+	GCP          *gcpConfig        `toml:"gcp"`
+	Azure        *azureConfig      `toml:"azure"`
+	Log          telegraf.Logger   `toml:"-"`
+
+	providers []provider
+	collected map[string]time.Time
+}
+
+// provider queries the daily costs of a cloud provider
+type provider interface {
+	// name returns the name of the provider used as "provider" tag
+	name() string
+	// query returns the costs of the days in the range [start, end)
+	query(ctx context.Context, start, end time.Time) ([]record, error)
+}
+
+// record is the cost of a service on a single day
+type record struct {
+	day      time.Time
+	service  string
+	account  string
+	currency string
+	cost     float64
+	// tags contains the values of the mapped cloud resource tags or labels
+	tags map[string]string
+}
+
+func (*CloudCost) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CloudCost) Init() error {
+	if c.LookbackDays < 1 {
+		return errors.New("'lookback_days' must be at least one")
+	}
+
+	// Use a stable order of the mapped tags for the queries
+	tagKeys := make([]string, 0, len(c.TagMapping))
+	for key, name := range c.TagMapping {
+		if name == "" {
+			return fmt.Errorf("empty tag name for %q in 'tag_mapping'", key)
+		}
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+
+	if c.AWS != nil {
+		p, err := c.AWS.provider(tagKeys)
+		if err != nil {
+			return fmt.Errorf("initializing AWS failed: %w", err)
+		}
+		c.providers = append(c.providers, p)
+	}
+	if c.GCP != nil {
+		p, err := c.GCP.provider(tagKeys)
+		if err != nil {
+			return fmt.Errorf("initializing GCP failed: %w", err)
+		}
+		c.providers = append(c.providers, p)
+	}
+	if c.Azure != nil {
+		p, err := c.Azure.provider(tagKeys)
+		if err != nil {
+			return fmt.Errorf("initializing Azure failed: %w", err)
+		}
+		c.providers = append(c.providers, p)
+	}
+	if len(c.providers) == 0 {
+		return errors.New("no cloud provider configured")
+	}
+
+	c.collected = make(map[string]time.Time, len(c.providers))
+
+	return nil
+}
+
+func (c *CloudCost) Gather(acc telegraf.Accumulator) error {
+	// Cost data is provided on a daily basis so only query each provider
+	// once a day. The last days are queried again as the costs of a day
+	// are updated for some time by the providers.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -c.LookbackDays)
+
+	for _, p := range c.providers {
+		if !c.collected[p.name()].Before(today) {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+		records, err := p.query(ctx, start, today)
+		cancel()
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying costs of %s failed: %w", p.name(), err))
+			continue
+		}
+
+		for _, r := range records {
+			c.add(acc, p.name(), r)
+		}
+		c.collected[p.name()] = today
+		c.Log.Debugf("Collected %d cost records of %s", len(records), p.name())
+	}
+
+	return nil
+}
+
+func (c *CloudCost) add(acc telegraf.Accumulator, providerName string, r record) {
+	tags := map[string]string{
+		"provider": providerName,
+		"service":  r.service,
+	}
+	if r.account != "" {
+		tags["account"] = r.account
+	}
+	if r.currency != "" {
+		tags["currency"] = r.currency
+	}
+	for key, value := range r.tags {
+		if value != "" {
+			tags[c.TagMapping[key]] = value
+		}
+	}
+
+	acc.AddFields("cloud_cost", map[string]interface{}{"cost": r.cost}, tags, r.day)
+}
+
+func init() {
+	inputs.Add("cloud_cost", func() telegraf.Input {
+		return &CloudCost{
+			LookbackDays: 3,
+			Timeout:      config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package cloud_cost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CloudCost
+		expected string
+	}{
+		{
+			name:     "no provider",
+			plugin:   &CloudCost{LookbackDays: 1},
+			expected: "no cloud provider configured",
+		},
+		{
+			name:     "invalid lookback",
+			plugin:   &CloudCost{AWS: &awsConfig{}},
+			expected: "'lookback_days' must be at least one",
+		},
+		{
+			name: "empty tag name",
+			plugin: &CloudCost{
+				LookbackDays: 1,
+				TagMapping:   map[string]string{"team": ""},
+				AWS:          &awsConfig{},
+			},
+			expected: `empty tag name for "team" in 'tag_mapping'`,
+		},
+		{
+			name:     "invalid AWS cost metric",
+			plugin:   &CloudCost{LookbackDays: 1, AWS: &awsConfig{CostMetric: "foo"}},
+			expected: `initializing AWS failed: invalid cost metric "foo"`,
+		},
+		{
+			name: "too many AWS tags",
+			plugin: &CloudCost{
+				LookbackDays: 1,
+				TagMapping:   map[string]string{"team": "team", "env": "env"},
+				AWS:          &awsConfig{},
+			},
+			expected: "initializing AWS failed: at most one tag can be mapped for AWS",
+		},
+		{
+			name:     "invalid GCP table",
+			plugin:   &CloudCost{LookbackDays: 1, GCP: &gcpConfig{Project: "test", Table: "foo` OR 1=1"}},
+			expected: "initializing GCP failed: invalid table name",
+		},
+		{
+			name:     "missing Azure scope",
+			plugin:   &CloudCost{LookbackDays: 1, Azure: &azureConfig{}},
+			expected: "initializing Azure failed: 'scope' must be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherAWS(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Amz-Target") != "AWSInsightsIndexService.GetCostAndUsage" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req ceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(req.GroupBy) != 2 || req.GroupBy[1] != (ceGroupBy{Type: "TAG", Key: "team"}) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Return the results in two pages
+		if req.NextPageToken == "" {
+			_, _ = w.Write([]byte(`{
+				"NextPageToken": "next",
+				"ResultsByTime": [{
+					"TimePeriod": {"Start": "2024-01-01", "End": "2024-01-02"},
+					"Groups": [
+						{"Keys": ["Amazon EC2", "team$web"], "Metrics": {"UnblendedCost": {"Amount": "12.5", "Unit": "USD"}}},
+						{"Keys": ["Amazon S3", "team$"], "Metrics": {"UnblendedCost": {"Amount": "0.25", "Unit": "USD"}}}
+					]
+				}]
+			}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"ResultsByTime": [{
+				"TimePeriod": {"Start": "2024-01-02", "End": "2024-01-03"},
+				"Groups": [
+					{"Keys": ["Amazon EC2", "team$web"], "Metrics": {"UnblendedCost": {"Amount": "11", "Unit": "USD"}}}
+				]
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	plugin := &CloudCost{
+		LookbackDays: 2,
+		Timeout:      config.Duration(5 * time.Second),
+		TagMapping:   map[string]string{"team": "owner"},
+		AWS: &awsConfig{
+			CredentialConfig: common_aws.CredentialConfig{
+				AccessKey:   "key",
+				SecretKey:   "secret",
+				EndpointURL: server.URL,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cloud_cost",
+			map[string]string{"provider": "aws", "service": "Amazon EC2", "currency": "USD", "owner": "web"},
+			map[string]interface{}{"cost": 12.5},
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"cloud_cost",
+			map[string]string{"provider": "aws", "service": "Amazon S3", "currency": "USD"},
+			map[string]interface{}{"cost": 0.25},
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"cloud_cost",
+			map[string]string{"provider": "aws", "service": "Amazon EC2", "currency": "USD", "owner": "web"},
+			map[string]interface{}{"cost": float64(11)},
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Equal(t, 2, requests)
+
+	// The costs are only queried once a day
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, 2, requests)
+}
+
+func TestGatherAWSError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type": "AccessDeniedException", "message": "denied"}`))
+	}))
+	defer server.Close()
+
+	plugin := &CloudCost{
+		LookbackDays: 1,
+		Timeout:      config.Duration(5 * time.Second),
+		AWS: &awsConfig{
+			CredentialConfig: common_aws.CredentialConfig{
+				AccessKey:   "key",
+				SecretKey:   "secret",
+				EndpointURL: server.URL,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying costs of aws failed")
+	require.ErrorContains(t, acc.Errors[0], "AccessDeniedException")
+	require.Empty(t, plugin.collected)
+}
+
+func TestQueryGCP(t *testing.T) {
+	p := &gcpProvider{
+		sql:    gcpQuery("project.billing.export", 1),
+		labels: []string{"team"},
+	}
+	p.read = func(_ context.Context, sql string, params []bigquery.QueryParameter) ([][]bigquery.Value, error) {
+		require.Equal(t, p.sql, sql)
+		require.Equal(t, []bigquery.QueryParameter{
+			{Name: "start", Value: civil.Date{Year: 2024, Month: 1, Day: 1}},
+			{Name: "end", Value: civil.Date{Year: 2024, Month: 1, Day: 3}},
+			{Name: "label0", Value: "team"},
+		}, params)
+
+		return [][]bigquery.Value{
+			{civil.Date{Year: 2024, Month: 1, Day: 1}, "Compute Engine", "my-project", "EUR", 3.5, "web"},
+			{civil.Date{Year: 2024, Month: 1, Day: 2}, "BigQuery", "", "EUR", 0.5, nil},
+		}, nil
+	}
+	require.Contains(t, p.sql, "FROM `project.billing.export`")
+	require.Contains(t, p.sql, "GROUP BY day, service, account, currency, label0")
+
+	records, err := p.query(
+		context.Background(),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []record{
+		{
+			day:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			service:  "Compute Engine",
+			account:  "my-project",
+			currency: "EUR",
+			cost:     3.5,
+			tags:     map[string]string{"team": "web"},
+		},
+		{
+			day:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			service:  "BigQuery",
+			currency: "EUR",
+			cost:     0.5,
+			tags:     map[string]string{"team": ""},
+		},
+	}, records)
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestQueryAzure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var q cmQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if q.TimePeriod.From != "2024-01-01T00:00:00Z" || q.TimePeriod.To != "2024-01-02T23:59:59Z" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		columns := `[{"name": "Cost"}, {"name": "UsageDate"}, {"name": "ServiceName"}, {"name": "TagKey"}, {"name": "TagValue"}, {"name": "Currency"}]`
+		if r.URL.Query().Get("page") == "" {
+			_, _ = w.Write([]byte(`{"properties": {
+				"nextLink": "` + server.URL + `/query?page=2",
+				"columns": ` + columns + `,
+				"rows": [[1.5, 20240101, "Storage", "team", "web", "EUR"]]
+			}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"properties": {
+			"columns": ` + columns + `,
+			"rows": [[2, 20240102, "Virtual Machines", "", "", "EUR"]]
+		}}`))
+	}))
+	defer server.Close()
+
+	p := &azureProvider{
+		url:      server.URL + "/query",
+		costType: "ActualCost",
+		tagKey:   "team",
+		cred:     fakeCredential{},
+		client:   server.Client(),
+	}
+
+	records, err := p.query(
+		context.Background(),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []record{
+		{
+			day:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			service:  "Storage",
+			currency: "EUR",
+			cost:     1.5,
+			tags:     map[string]string{"team": "web"},
+		},
+		{
+			day:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			service:  "Virtual Machines",
+			currency: "EUR",
+			cost:     2,
+			tags:     map[string]string{"team": ""},
+		},
+	}, records)
+}
@@ -0,0 +1,188 @@
+package cloud_cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// tableRe matches valid BigQuery table names to prevent injections as the
+// table cannot be passed as query parameter
+var tableRe = regexp.MustCompile(`^[A-Za-z0-9_:.\-]+$`)
+
+// gcpConfig configures querying the GCP Cloud Billing export in BigQuery
+type gcpConfig struct {
+	Project         string `toml:"project"`
+	Table           string `toml:"table"`
+	CredentialsFile string `toml:"credentials_file"`
+}
+
+type gcpProvider struct {
+	sql    string
+	labels []string
+
+	// read runs the given query and returns the resulting rows
+	read func(ctx context.Context, sql string, params []bigquery.QueryParameter) ([][]bigquery.Value, error)
+
+	client *bigquery.Client
+}
+
+func (c *gcpConfig) provider(tagKeys []string) (*gcpProvider, error) {
+	if c.Project == "" {
+		return nil, errors.New("'project' must be set")
+	}
+	if c.Table == "" {
+		return nil, errors.New("'table' must be set")
+	}
+	if !tableRe.MatchString(c.Table) {
+		return nil, fmt.Errorf("invalid table name %q", c.Table)
+	}
+
+	p := &gcpProvider{
+		sql:    gcpQuery(c.Table, len(tagKeys)),
+		labels: tagKeys,
+	}
+	p.read = p.readBigQuery
+
+	var credentialsOption option.ClientOption
+	if c.CredentialsFile != "" {
+		credentialsOption = option.WithCredentialsFile(c.CredentialsFile)
+	} else {
+		creds, err := google.FindDefaultCredentials(context.Background(), bigquery.Scope)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"unable to find Google Cloud Platform Application Default Credentials: %w. "+
+					"Either set ADC or provide CredentialsFile config", err)
+		}
+		credentialsOption = option.WithCredentials(creds)
+	}
+
+	client, err := bigquery.NewClient(context.Background(), c.Project,
+		credentialsOption,
+		option.WithUserAgent(internal.ProductToken()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating client failed: %w", err)
+	}
+	p.client = client
+
+	return p, nil
+}
+
+// gcpQuery returns the query for the daily costs including credits per
+// service and project in the given billing export table. The values of the
+// labels are selected as additional columns "label<n>" using the "@label<n>"
+// query parameters.
+func gcpQuery(table string, labels int) string {
+	columns := []string{
+		"DATE(usage_start_time) AS day",
+		"service.description AS service",
+		"IFNULL(project.id, '') AS account",
+		"currency",
+		"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS cost",
+	}
+	groups := []string{"day", "service", "account", "currency"}
+	for i := range labels {
+		columns = append(columns, fmt.Sprintf("(SELECT value FROM UNNEST(labels) WHERE key = @label%d) AS label%d", i, i))
+		groups = append(groups, fmt.Sprintf("label%d", i))
+	}
+
+	// Usage is exported after it occurred, so the partition time restricts
+	// the amount of scanned data without missing any usage of the range
+	return "SELECT " + strings.Join(columns, ", ") +
+		" FROM `" + table + "`" +
+		" WHERE _PARTITIONTIME >= TIMESTAMP(@start) AND DATE(usage_start_time) >= @start AND DATE(usage_start_time) < @end" +
+		" GROUP BY " + strings.Join(groups, ", ")
+}
+
+func (*gcpProvider) name() string {
+	return "gcp"
+}
+
+func (p *gcpProvider) query(ctx context.Context, start, end time.Time) ([]record, error) {
+	params := []bigquery.QueryParameter{
+		{Name: "start", Value: civil.DateOf(start)},
+		{Name: "end", Value: civil.DateOf(end)},
+	}
+	for i, label := range p.labels {
+		params = append(params, bigquery.QueryParameter{Name: fmt.Sprintf("label%d", i), Value: label})
+	}
+
+	rows, err := p.read(ctx, p.sql, params)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]record, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 5+len(p.labels) {
+			return nil, fmt.Errorf("unexpected number of columns %d", len(row))
+		}
+		day, ok := row[0].(civil.Date)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for day", row[0])
+		}
+		cost, ok := row[4].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for cost", row[4])
+		}
+
+		r := record{
+			day:      day.In(time.UTC),
+			service:  stringValue(row[1]),
+			account:  stringValue(row[2]),
+			currency: stringValue(row[3]),
+			cost:     cost,
+		}
+		if len(p.labels) > 0 {
+			r.tags = make(map[string]string, len(p.labels))
+			for i, label := range p.labels {
+				r.tags[label] = stringValue(row[5+i])
+			}
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func (p *gcpProvider) readBigQuery(ctx context.Context, sql string, params []bigquery.QueryParameter) ([][]bigquery.Value, error) {
+	q := p.client.Query(sql)
+	q.Parameters = params
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]bigquery.Value
+	for {
+		var row []bigquery.Value
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// stringValue returns the string of the given value or an empty string for
+// NULL values
+func stringValue(v bigquery.Value) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,289 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package coredns
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CoreDNS struct {
+	URLs                 []string        `toml:"urls"`
+	DnstapListen         string          `toml:"dnstap_listen"`
+	DnstapZones          []string        `toml:"dnstap_zones"`
+	DnstapClientPrefixV4 int             `toml:"dnstap_client_prefix_v4"`
+	DnstapClientPrefixV6 int             `toml:"dnstap_client_prefix_v6"`
+	DnstapMaxClients     int             `toml:"dnstap_max_clients"`
+	Log                  telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+	zones  []string
+	stats  *dnstapStats
+
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	connsMu  sync.Mutex
+	wg       sync.WaitGroup
+}
+
+func (*CoreDNS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CoreDNS) Init() error {
+	if len(c.URLs) == 0 && c.DnstapListen == "" {
+		return errors.New("neither 'urls' nor 'dnstap_listen' is set")
+	}
+
+	if c.DnstapClientPrefixV4 < 0 || c.DnstapClientPrefixV4 > 32 {
+		return fmt.Errorf("invalid 'dnstap_client_prefix_v4' %d", c.DnstapClientPrefixV4)
+	}
+	if c.DnstapClientPrefixV6 < 0 || c.DnstapClientPrefixV6 > 128 {
+		return fmt.Errorf("invalid 'dnstap_client_prefix_v6' %d", c.DnstapClientPrefixV6)
+	}
+	if c.DnstapMaxClients < 0 {
+		return errors.New("'dnstap_max_clients' must not be negative")
+	}
+
+	// Check the zones from longest to shortest to find the longest match
+	c.zones = make([]string, 0, len(c.DnstapZones))
+	for _, zone := range c.DnstapZones {
+		c.zones = append(c.zones, canonicalName(zone))
+	}
+	sort.SliceStable(c.zones, func(i, j int) bool {
+		return strings.Count(c.zones[i], ".") > strings.Count(c.zones[j], ".")
+	})
+
+	c.stats = newDnstapStats(c.DnstapMaxClients)
+
+	return nil
+}
+
+func (c *CoreDNS) Start(telegraf.Accumulator) error {
+	if len(c.URLs) > 0 {
+		client, err := c.HTTPClientConfig.CreateClient(context.Background(), c.Log)
+		if err != nil {
+			return fmt.Errorf("creating HTTP client failed: %w", err)
+		}
+		c.client = client
+	}
+
+	if c.DnstapListen == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.DnstapListen)
+	if err != nil {
+		return fmt.Errorf("parsing 'dnstap_listen' failed: %w", err)
+	}
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		c.listener, err = net.Listen(u.Scheme, u.Host)
+	case "unix":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing socket failed: %w", err)
+		}
+		c.listener, err = net.Listen("unix", path)
+	default:
+		return fmt.Errorf("unsupported scheme %q in 'dnstap_listen'", u.Scheme)
+	}
+	if err != nil {
+		return fmt.Errorf("listening for dnstap failed: %w", err)
+	}
+	c.Log.Debugf("Listening for dnstap on %s", c.listener.Addr())
+
+	c.conns = make(map[net.Conn]struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.accept()
+	}()
+
+	return nil
+}
+
+func (c *CoreDNS) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range c.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			if err := c.gatherURL(acc, u); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", u, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	if c.listener != nil {
+		c.stats.emit(acc)
+	}
+
+	return nil
+}
+
+func (c *CoreDNS) Stop() {
+	if c.listener != nil {
+		c.listener.Close()
+		c.connsMu.Lock()
+		for conn := range c.conns {
+			conn.Close()
+		}
+		c.connsMu.Unlock()
+	}
+	c.wg.Wait()
+
+	if c.client != nil {
+		c.client.CloseIdleConnections()
+	}
+}
+
+// Prometheus metrics of CoreDNS v1.7.0 and later and their predecessors
+// still exported by older kube-dns deployments
+var (
+	requestMetrics  = []string{"coredns_dns_requests_total", "coredns_dns_request_type_count_total"}
+	responseMetrics = []string{"coredns_dns_responses_total", "coredns_dns_response_rcode_count_total"}
+)
+
+func (c *CoreDNS) gatherURL(acc telegraf.Accumulator, u string) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("parsing metrics failed: %w", err)
+	}
+
+	// Sum up the counters over the labels not reported, e.g. the protocol
+	requests := make(map[[3]string]uint64)
+	for _, name := range requestMetrics {
+		for _, m := range families[name].GetMetric() {
+			labels := labelMap(m)
+			requests[[3]string{labels["server"], labels["zone"], labels["type"]}] += uint64(m.GetCounter().GetValue())
+		}
+	}
+	responses := make(map[[3]string]uint64)
+	for _, name := range responseMetrics {
+		for _, m := range families[name].GetMetric() {
+			labels := labelMap(m)
+			responses[[3]string{labels["server"], labels["zone"], labels["rcode"]}] += uint64(m.GetCounter().GetValue())
+		}
+	}
+	cache := make(map[[2]string]map[string]interface{})
+	for _, field := range []string{"hits", "misses"} {
+		for _, m := range families["coredns_cache_"+field+"_total"].GetMetric() {
+			labels := labelMap(m)
+			key := [2]string{labels["server"], labels["zones"]}
+			if cache[key] == nil {
+				cache[key] = map[string]interface{}{"hits": uint64(0), "misses": uint64(0)}
+			}
+			cache[key][field] = cache[key][field].(uint64) + uint64(m.GetCounter().GetValue())
+		}
+	}
+
+	for k, v := range requests {
+		tags := map[string]string{"url": u, "server": k[0], "zone": k[1], "type": k[2]}
+		acc.AddCounter("coredns_requests", map[string]interface{}{"requests": v}, tags)
+	}
+	for k, v := range responses {
+		tags := map[string]string{"url": u, "server": k[0], "zone": k[1], "rcode": k[2]}
+		acc.AddCounter("coredns_responses", map[string]interface{}{"responses": v}, tags)
+	}
+	for k, fields := range cache {
+		tags := map[string]string{"url": u, "server": k[0], "zone": k[1]}
+		acc.AddCounter("coredns_cache", fields, tags)
+	}
+
+	return nil
+}
+
+func labelMap(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+// zone returns the longest configured zone containing the given name
+func (c *CoreDNS) zone(name string) string {
+	for _, zone := range c.zones {
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			return zone
+		}
+	}
+	return "."
+}
+
+// clientPrefix returns the prefix of the given client address
+func (c *CoreDNS) clientPrefix(addr netip.Addr) string {
+	bits := c.DnstapClientPrefixV6
+	if addr.Is4() {
+		bits = c.DnstapClientPrefixV4
+	}
+	if bits == addr.BitLen() {
+		return addr.String()
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return addr.String()
+	}
+	return prefix.String()
+}
+
+// canonicalName returns the lower-case, fully qualified form of a name
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+func init() {
+	inputs.Add("coredns", func() telegraf.Input {
+		return &CoreDNS{
+			DnstapClientPrefixV4: 24,
+			DnstapClientPrefixV6: 56,
+			DnstapMaxClients:     100,
+		}
+	})
+}
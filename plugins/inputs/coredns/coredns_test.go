@@ -0,0 +1,472 @@
+package coredns
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*CoreDNS)
+		expected string
+	}{
+		{
+			name:     "nothing to gather",
+			modify:   func(c *CoreDNS) { c.URLs = nil },
+			expected: "neither 'urls' nor 'dnstap_listen' is set",
+		},
+		{
+			name:     "invalid IPv4 prefix",
+			modify:   func(c *CoreDNS) { c.DnstapClientPrefixV4 = 33 },
+			expected: "invalid 'dnstap_client_prefix_v4' 33",
+		},
+		{
+			name:     "invalid IPv6 prefix",
+			modify:   func(c *CoreDNS) { c.DnstapClientPrefixV6 = -1 },
+			expected: "invalid 'dnstap_client_prefix_v6' -1",
+		},
+		{
+			name:     "negative client limit",
+			modify:   func(c *CoreDNS) { c.DnstapMaxClients = -1 },
+			expected: "'dnstap_max_clients' must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+			plugin.URLs = []string{"http://localhost:9153/metrics"}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherPrometheus(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "current",
+			file: "metrics.txt",
+			expected: []telegraf.Metric{
+				metric.New(
+					"coredns_requests",
+					map[string]string{"server": "dns://:53", "zone": ".", "type": "A"},
+					map[string]interface{}{"requests": uint64(3600)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_requests",
+					map[string]string{"server": "dns://:53", "zone": ".", "type": "AAAA"},
+					map[string]interface{}{"requests": uint64(420)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_requests",
+					map[string]string{"server": "dns://:53", "zone": "cluster.local.", "type": "A"},
+					map[string]interface{}{"requests": uint64(2600)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": ".", "rcode": "NOERROR"},
+					map[string]interface{}{"responses": uint64(3900)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": ".", "rcode": "NXDOMAIN"},
+					map[string]interface{}{"responses": uint64(120)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": "cluster.local.", "rcode": "NXDOMAIN"},
+					map[string]interface{}{"responses": uint64(2600)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_cache",
+					map[string]string{"server": "dns://:53", "zone": "."},
+					map[string]interface{}{"hits": uint64(3520), "misses": uint64(560)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+		{
+			name: "legacy",
+			file: "metrics_legacy.txt",
+			expected: []telegraf.Metric{
+				metric.New(
+					"coredns_requests",
+					map[string]string{"server": "dns://:53", "zone": ".", "type": "A"},
+					map[string]interface{}{"requests": uint64(250)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_requests",
+					map[string]string{"server": "dns://:53", "zone": "cluster.local.", "type": "SRV"},
+					map[string]interface{}{"requests": uint64(30)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": ".", "rcode": "NOERROR"},
+					map[string]interface{}{"responses": uint64(240)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": ".", "rcode": "SERVFAIL"},
+					map[string]interface{}{"responses": uint64(10)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"coredns_responses",
+					map[string]string{"server": "dns://:53", "zone": "cluster.local.", "rcode": "NOERROR"},
+					map[string]interface{}{"responses": uint64(30)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			require.NoError(t, err)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if _, err := w.Write(buf); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+			plugin.URLs = []string{server.URL + "/metrics"}
+			plugin.Log = testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			for _, m := range tt.expected {
+				m.AddTag("url", server.URL+"/metrics")
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestGatherPrometheusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+	plugin.URLs = []string{server.URL + "/metrics"}
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `received status "404 Not Found"`)
+}
+
+func TestDnstap(t *testing.T) {
+	plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+	plugin.DnstapListen = "tcp://127.0.0.1:0"
+	plugin.DnstapZones = []string{"example.com", "Sub.Example.Com."}
+	plugin.DnstapMaxClients = 2
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	// Perform the bidirectional handshake
+	_, err = conn.Write(controlFrame(controlReady, dnstapContentType))
+	require.NoError(t, err)
+	frame, control, err := readFrame(r)
+	require.NoError(t, err)
+	require.True(t, control)
+	require.Equal(t, uint32(controlAccept), binary.BigEndian.Uint32(frame))
+	require.True(t, hasContentType(frame[4:]))
+	_, err = conn.Write(controlFrame(controlStart, dnstapContentType))
+	require.NoError(t, err)
+
+	queryTime := time.Unix(1700000000, 0)
+	messages := [][]byte{
+		dnstapQuery(t, "10.1.2.3", "www.example.com.", dns.TypeA, queryTime),
+		dnstapQuery(t, "10.1.2.200", "WWW.sub.example.com.", dns.TypeAAAA, queryTime),
+		dnstapQuery(t, "2001:db8::1", "example.org.", dns.TypeA, queryTime),
+		dnstapQuery(t, "192.168.0.1", "example.com.", dns.TypeMX, queryTime),
+		dnstapResponse(t, "www.example.com.", dns.RcodeSuccess, queryTime, queryTime.Add(2*time.Millisecond)),
+		dnstapResponse(t, "mail.example.com.", dns.RcodeSuccess, queryTime, queryTime.Add(4*time.Millisecond)),
+		dnstapResponse(t, "example.org.", dns.RcodeNameError, queryTime, queryTime.Add(10*time.Millisecond)),
+		[]byte("garbage"),
+	}
+	for _, msg := range messages {
+		_, err = conn.Write(dataFrame(msg))
+		require.NoError(t, err)
+	}
+
+	// Stop the stream and wait for the finish frame to make sure all
+	// messages are processed
+	_, err = conn.Write(controlFrame(controlStop, ""))
+	require.NoError(t, err)
+	frame, control, err = readFrame(r)
+	require.NoError(t, err)
+	require.True(t, control)
+	require.Equal(t, uint32(controlFinish), binary.BigEndian.Uint32(frame))
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"coredns_dnstap_queries",
+			map[string]string{"zone": "example.com.", "qtype": "A"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_queries",
+			map[string]string{"zone": "example.com.", "qtype": "MX"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_queries",
+			map[string]string{"zone": "sub.example.com.", "qtype": "AAAA"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_queries",
+			map[string]string{"zone": ".", "qtype": "A"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_responses",
+			map[string]string{"zone": "example.com.", "rcode": "NOERROR"},
+			map[string]interface{}{"count": uint64(2), "response_time_mean_ms": float64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_responses",
+			map[string]string{"zone": ".", "rcode": "NXDOMAIN"},
+			map[string]interface{}{"count": uint64(1), "response_time_mean_ms": float64(10)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_clients",
+			map[string]string{"client": "10.1.2.0/24"},
+			map[string]interface{}{"queries": uint64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_clients",
+			map[string]string{"client": "2001:db8::/56"},
+			map[string]interface{}{"queries": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap_clients",
+			map[string]string{"client": "other"},
+			map[string]interface{}{"queries": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"coredns_dnstap",
+			map[string]string{},
+			map[string]interface{}{"decode_errors": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The statistics are reset after each interval
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestDnstapUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "dnstap.sock")
+
+	plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+	plugin.DnstapListen = "unix://" + sock
+	plugin.DnstapMaxClients = 0
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Unidirectional senders start without handshake
+	conn, err := net.Dial("unix", sock)
+	require.NoError(t, err)
+	_, err = conn.Write(controlFrame(controlStart, dnstapContentType))
+	require.NoError(t, err)
+	_, err = conn.Write(dataFrame(dnstapQuery(t, "10.0.0.1", "example.com.", dns.TypeA, time.Now())))
+	require.NoError(t, err)
+	_, err = conn.Write(controlFrame(controlStop, ""))
+	require.NoError(t, err)
+	_, _, err = readFrame(conn)
+	require.NoError(t, err)
+	conn.Close()
+
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"coredns_dnstap_queries",
+			map[string]string{"zone": ".", "qtype": "A"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestDnstapContentTypeMismatch(t *testing.T) {
+	plugin := inputs.Inputs["coredns"]().(*CoreDNS)
+	plugin.DnstapListen = "tcp://127.0.0.1:0"
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The connection is closed without accepting foreign content
+	_, err = conn.Write(controlFrame(controlReady, "protobuf:other"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = readFrame(conn)
+	require.Error(t, err)
+}
+
+func dataFrame(payload []byte) []byte {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	return append(buf, payload...)
+}
+
+func controlFrame(typ uint32, contentType string) []byte {
+	payload := binary.BigEndian.AppendUint32(nil, typ)
+	if contentType != "" {
+		payload = binary.BigEndian.AppendUint32(payload, controlFieldContentType)
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(contentType)))
+		payload = append(payload, contentType...)
+	}
+	buf := binary.BigEndian.AppendUint32(nil, 0)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	return append(buf, payload...)
+}
+
+func dnstapQuery(t *testing.T, client, name string, qtype uint16, ts time.Time) []byte {
+	var q dns.Msg
+	q.SetQuestion(name, qtype)
+	wire, err := q.Pack()
+	require.NoError(t, err)
+
+	addr := netip.MustParseAddr(client)
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, dnstapClientQuery)
+	msg = protowire.AppendTag(msg, 4, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, addr.AsSlice())
+	msg = protowire.AppendTag(msg, 8, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(ts.Unix()))
+	msg = protowire.AppendTag(msg, 10, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, wire)
+	return dnstapFrame(msg)
+}
+
+func dnstapResponse(t *testing.T, name string, rcode int, query, response time.Time) []byte {
+	var q dns.Msg
+	q.SetQuestion(name, dns.TypeA)
+	var r dns.Msg
+	r.SetRcode(&q, rcode)
+	wire, err := r.Pack()
+	require.NoError(t, err)
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, dnstapClientResponse)
+	msg = protowire.AppendTag(msg, 8, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(query.Unix()))
+	msg = protowire.AppendTag(msg, 9, protowire.Fixed32Type)
+	msg = protowire.AppendFixed32(msg, uint32(query.Nanosecond()))
+	msg = protowire.AppendTag(msg, 12, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(response.Unix()))
+	msg = protowire.AppendTag(msg, 13, protowire.Fixed32Type)
+	msg = protowire.AppendFixed32(msg, uint32(response.Nanosecond()))
+	msg = protowire.AppendTag(msg, 14, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, wire)
+	return dnstapFrame(msg)
+}
+
+// dnstapFrame wraps the message into a dnstap frame including an identity
+// to check skipping unknown fields
+func dnstapFrame(msg []byte) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, []byte("coredns"))
+	buf = protowire.AppendTag(buf, 14, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, msg)
+	buf = protowire.AppendTag(buf, 15, protowire.VarintType)
+	return protowire.AppendVarint(buf, 1)
+}
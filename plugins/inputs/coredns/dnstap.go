@@ -0,0 +1,447 @@
+package coredns
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Frame Streams control frame types, see
+// https://farsightsec.github.io/fstrm/
+const (
+	controlAccept = 0x01
+	controlStart  = 0x02
+	controlStop   = 0x03
+	controlReady  = 0x04
+	controlFinish = 0x05
+
+	controlFieldContentType = 0x01
+
+	// Maximum size of a frame to protect against broken or malicious senders
+	maxFrameSize = 1 << 20
+)
+
+const dnstapContentType = "protobuf:dnstap.Dnstap"
+
+// dnstap message types relevant for the statistics, see
+// https://github.com/dnstap/dnstap.pb/blob/master/dnstap.proto
+const (
+	dnstapClientQuery    = 5
+	dnstapClientResponse = 6
+)
+
+// dnstapMessage contains the relevant parts of a dnstap message
+type dnstapMessage struct {
+	typ             uint64
+	queryAddress    []byte
+	queryMessage    []byte
+	responseMessage []byte
+	querySec        uint64
+	queryNsec       uint32
+	responseSec     uint64
+	responseNsec    uint32
+	hasQueryTime    bool
+	hasResponseTime bool
+}
+
+func (c *CoreDNS) accept() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				c.Log.Errorf("Accepting dnstap connection failed: %v", err)
+			}
+			return
+		}
+
+		c.connsMu.Lock()
+		c.conns[conn] = struct{}{}
+		c.connsMu.Unlock()
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer func() {
+				c.connsMu.Lock()
+				delete(c.conns, conn)
+				c.connsMu.Unlock()
+				conn.Close()
+			}()
+			if err := c.handleConnection(conn); err != nil && !errors.Is(err, net.ErrClosed) {
+				c.Log.Errorf("Handling dnstap connection from %q failed: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handleConnection receives the frames of a Frame Streams connection in
+// either unidirectional or bidirectional mode
+func (c *CoreDNS) handleConnection(conn io.ReadWriter) error {
+	r := bufio.NewReader(conn)
+	for {
+		frame, control, err := readFrame(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if !control {
+			if err := c.handleFrame(frame); err != nil {
+				c.stats.addError()
+				c.Log.Debugf("Decoding dnstap message failed: %v", err)
+			}
+			continue
+		}
+
+		if len(frame) < 4 {
+			return errors.New("short control frame")
+		}
+		switch binary.BigEndian.Uint32(frame) {
+		case controlReady:
+			if !hasContentType(frame[4:]) {
+				return errors.New("sender does not support dnstap content")
+			}
+			if err := writeControlFrame(conn, controlAccept); err != nil {
+				return fmt.Errorf("sending accept failed: %w", err)
+			}
+		case controlStart:
+		case controlStop:
+			// Only bidirectional senders expect the finish frame but
+			// unidirectional ones close the connection anyway
+			//nolint:errcheck // connection is closed anyway
+			writeControlFrame(conn, controlFinish)
+			return nil
+		default:
+			return fmt.Errorf("unexpected control frame type %d", binary.BigEndian.Uint32(frame))
+		}
+	}
+}
+
+// readFrame reads a data or control frame
+func readFrame(r io.Reader) ([]byte, bool, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, false, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	control := length == 0
+	if control {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, false, err
+		}
+		length = binary.BigEndian.Uint32(header[:])
+	}
+	if length > maxFrameSize {
+		return nil, false, fmt.Errorf("frame size %d exceeds limit", length)
+	}
+
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, false, err
+	}
+	return frame, control, nil
+}
+
+// hasContentType checks if the fields of a control frame list the dnstap
+// content type. Frames without content type accept any content.
+func hasContentType(fields []byte) bool {
+	found := false
+	for len(fields) >= 8 {
+		typ := binary.BigEndian.Uint32(fields)
+		length := binary.BigEndian.Uint32(fields[4:])
+		if uint64(len(fields)-8) < uint64(length) {
+			return false
+		}
+		value := fields[8 : 8+length]
+		fields = fields[8+length:]
+		if typ != controlFieldContentType {
+			continue
+		}
+		if string(value) == dnstapContentType {
+			return true
+		}
+		found = true
+	}
+	return !found
+}
+
+func writeControlFrame(w io.Writer, typ uint32) error {
+	var payload []byte
+	payload = binary.BigEndian.AppendUint32(payload, typ)
+	if typ == controlAccept {
+		payload = binary.BigEndian.AppendUint32(payload, controlFieldContentType)
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(dnstapContentType)))
+		payload = append(payload, dnstapContentType...)
+	}
+
+	buf := make([]byte, 0, 8+len(payload))
+	buf = binary.BigEndian.AppendUint32(buf, 0)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = append(buf, payload...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// handleFrame decodes a dnstap message and adds it to the statistics
+func (c *CoreDNS) handleFrame(frame []byte) error {
+	msg, err := decodeDnstap(frame)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return nil
+	}
+
+	switch msg.typ {
+	case dnstapClientQuery:
+		var q dns.Msg
+		if err := q.Unpack(msg.queryMessage); err != nil {
+			return fmt.Errorf("unpacking query failed: %w", err)
+		}
+		if len(q.Question) == 0 {
+			return errors.New("query without question")
+		}
+		client := ""
+		if addr, ok := netip.AddrFromSlice(msg.queryAddress); ok {
+			client = c.clientPrefix(addr.Unmap())
+		}
+		question := q.Question[0]
+		c.stats.addQuery(c.zone(canonicalName(question.Name)), dns.Type(question.Qtype).String(), client)
+	case dnstapClientResponse:
+		var r dns.Msg
+		if err := r.Unpack(msg.responseMessage); err != nil {
+			return fmt.Errorf("unpacking response failed: %w", err)
+		}
+		if len(r.Question) == 0 {
+			return errors.New("response without question")
+		}
+		var duration time.Duration
+		if msg.hasQueryTime && msg.hasResponseTime {
+			query := time.Unix(int64(msg.querySec), int64(msg.queryNsec))
+			response := time.Unix(int64(msg.responseSec), int64(msg.responseNsec))
+			duration = response.Sub(query)
+		}
+		rcode, found := dns.RcodeToString[r.Rcode]
+		if !found {
+			rcode = fmt.Sprintf("RCODE%d", r.Rcode)
+		}
+		c.stats.addResponse(c.zone(canonicalName(r.Question[0].Name)), rcode, duration)
+	}
+
+	return nil
+}
+
+// decodeDnstap decodes the protobuf encoded dnstap frame. Nil is returned for
+// frames not containing a message.
+func decodeDnstap(buf []byte) (*dnstapMessage, error) {
+	var message []byte
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+		if num == 14 && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(buf)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			message = v
+			buf = buf[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+	}
+	if message == nil {
+		return nil, nil
+	}
+
+	msg := &dnstapMessage{}
+	for len(message) > 0 {
+		num, typ, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		message = message[n:]
+
+		switch {
+		case typ == protowire.VarintType && (num == 1 || num == 8 || num == 12):
+			v, n := protowire.ConsumeVarint(message)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			message = message[n:]
+			switch num {
+			case 1:
+				msg.typ = v
+			case 8:
+				msg.querySec = v
+				msg.hasQueryTime = true
+			case 12:
+				msg.responseSec = v
+				msg.hasResponseTime = true
+			}
+		case typ == protowire.Fixed32Type && (num == 9 || num == 13):
+			v, n := protowire.ConsumeFixed32(message)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			message = message[n:]
+			if num == 9 {
+				msg.queryNsec = v
+			} else {
+				msg.responseNsec = v
+			}
+		case typ == protowire.BytesType && (num == 4 || num == 10 || num == 14):
+			v, n := protowire.ConsumeBytes(message)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			message = message[n:]
+			switch num {
+			case 4:
+				msg.queryAddress = v
+			case 10:
+				msg.queryMessage = v
+			case 14:
+				msg.responseMessage = v
+			}
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, message)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			message = message[n:]
+		}
+	}
+	return msg, nil
+}
+
+type queryKey struct {
+	zone  string
+	qtype string
+}
+
+type responseKey struct {
+	zone  string
+	rcode string
+}
+
+type responseStats struct {
+	count       uint64
+	timed       uint64
+	durationSum time.Duration
+}
+
+// dnstapStats aggregates the dnstap messages received within an interval
+type dnstapStats struct {
+	maxClients int
+
+	sync.Mutex
+	queries      map[queryKey]uint64
+	responses    map[responseKey]*responseStats
+	clients      map[string]uint64
+	otherClients uint64
+	errors       uint64
+}
+
+func newDnstapStats(maxClients int) *dnstapStats {
+	s := &dnstapStats{maxClients: maxClients}
+	s.reset()
+	return s
+}
+
+func (s *dnstapStats) reset() {
+	s.queries = make(map[queryKey]uint64)
+	s.responses = make(map[responseKey]*responseStats)
+	s.clients = make(map[string]uint64)
+	s.otherClients = 0
+	s.errors = 0
+}
+
+func (s *dnstapStats) addQuery(zone, qtype, client string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.queries[queryKey{zone, qtype}]++
+
+	// Limit the number of clients to bound the cardinality of the series
+	if s.maxClients == 0 || client == "" {
+		return
+	}
+	if _, found := s.clients[client]; found || len(s.clients) < s.maxClients {
+		s.clients[client]++
+	} else {
+		s.otherClients++
+	}
+}
+
+func (s *dnstapStats) addResponse(zone, rcode string, duration time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	key := responseKey{zone, rcode}
+	stats, found := s.responses[key]
+	if !found {
+		stats = &responseStats{}
+		s.responses[key] = stats
+	}
+	stats.count++
+	if duration > 0 {
+		stats.timed++
+		stats.durationSum += duration
+	}
+}
+
+func (s *dnstapStats) addError() {
+	s.Lock()
+	s.errors++
+	s.Unlock()
+}
+
+// emit adds the statistics of the current interval and starts a new one
+func (s *dnstapStats) emit(acc telegraf.Accumulator) {
+	s.Lock()
+	defer s.Unlock()
+
+	for k, v := range s.queries {
+		tags := map[string]string{"zone": k.zone, "qtype": k.qtype}
+		acc.AddFields("coredns_dnstap_queries", map[string]interface{}{"count": v}, tags)
+	}
+	for k, v := range s.responses {
+		tags := map[string]string{"zone": k.zone, "rcode": k.rcode}
+		fields := map[string]interface{}{"count": v.count}
+		if v.timed > 0 {
+			fields["response_time_mean_ms"] = float64(v.durationSum) / float64(v.timed) / float64(time.Millisecond)
+		}
+		acc.AddFields("coredns_dnstap_responses", fields, tags)
+	}
+	for k, v := range s.clients {
+		acc.AddFields("coredns_dnstap_clients", map[string]interface{}{"queries": v}, map[string]string{"client": k})
+	}
+	if s.otherClients > 0 {
+		acc.AddFields("coredns_dnstap_clients", map[string]interface{}{"queries": s.otherClients}, map[string]string{"client": "other"})
+	}
+	if s.errors > 0 {
+		acc.AddFields("coredns_dnstap", map[string]interface{}{"decode_errors": s.errors}, nil)
+	}
+
+	s.reset()
+}
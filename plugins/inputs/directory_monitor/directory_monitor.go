@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/selfstat"
@@ -54,6 +55,9 @@ type DirectoryMonitor struct {
 	Log                        telegraf.Logger `toml:"-"`
 	FileQueueSize              int             `toml:"file_queue_size"`
 	ParseMethod                string          `toml:"parse_method"`
+	ObjectStore                string          `toml:"object_store"`
+	CredentialsFile            string          `toml:"credentials_file"`
+	common_aws.CredentialConfig
 
 	filesInUse          sync.Map
 	cancel              context.CancelFunc
@@ -70,6 +74,13 @@ type DirectoryMonitor struct {
 	fileRegexesToMatch  []*regexp.Regexp
 	fileRegexesToIgnore []*regexp.Regexp
 	filesToProcess      chan string
+	store               objectStore
+	objectScheme        string
+	objectBucket        string
+	objectPrefix        string
+	pendingETags        sync.Map
+	etags               map[string]string
+	etagsMu             sync.Mutex
 }
 
 func (*DirectoryMonitor) SampleConfig() string {
@@ -81,7 +92,22 @@ func (monitor *DirectoryMonitor) SetParserFunc(fn telegraf.ParserFunc) {
 }
 
 func (monitor *DirectoryMonitor) Init() error {
-	if monitor.Directory == "" || monitor.FinishedDirectory == "" {
+	if monitor.ObjectStore != "" {
+		if monitor.Directory != "" {
+			return errors.New("'directory' and 'object_store' cannot be used together")
+		}
+		if monitor.FinishedDirectory != "" || monitor.ErrorDirectory != "" {
+			monitor.Log.Warn("Objects are not moved, ignoring 'finished_directory' and 'error_directory'")
+			monitor.FinishedDirectory = ""
+			monitor.ErrorDirectory = ""
+		}
+		var err error
+		monitor.objectScheme, monitor.objectBucket, monitor.objectPrefix, err = parseObjectStoreURL(monitor.ObjectStore)
+		if err != nil {
+			return err
+		}
+		monitor.etags = make(map[string]string)
+	} else if monitor.Directory == "" || monitor.FinishedDirectory == "" {
 		return errors.New("missing one of the following required config options: directory, finished_directory")
 	}
 
@@ -90,16 +116,21 @@ func (monitor *DirectoryMonitor) Init() error {
 	}
 
 	// Finished directory can be created if not exists for convenience.
-	if _, err := os.Stat(monitor.FinishedDirectory); os.IsNotExist(err) {
-		err = os.Mkdir(monitor.FinishedDirectory, 0750)
-		if err != nil {
-			return err
+	if monitor.FinishedDirectory != "" {
+		if _, err := os.Stat(monitor.FinishedDirectory); os.IsNotExist(err) {
+			err = os.Mkdir(monitor.FinishedDirectory, 0750)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	tags := map[string]string{
 		"directory": monitor.Directory,
 	}
+	if monitor.ObjectStore != "" {
+		tags["directory"] = monitor.ObjectStore
+	}
 	monitor.filesDropped = selfstat.Register("directory_monitor", "files_dropped", make(map[string]string))
 	monitor.filesDroppedDir = selfstat.Register("directory_monitor", "files_dropped_per_dir", tags)
 	monitor.filesProcessed = selfstat.Register("directory_monitor", "files_processed", make(map[string]string))
@@ -146,6 +177,14 @@ func (monitor *DirectoryMonitor) Init() error {
 }
 
 func (monitor *DirectoryMonitor) Start(acc telegraf.Accumulator) error {
+	if monitor.ObjectStore != "" && monitor.store == nil {
+		store, err := monitor.connectObjectStore(monitor.context)
+		if err != nil {
+			return fmt.Errorf("connecting to object store failed: %w", err)
+		}
+		monitor.store = store
+	}
+
 	// Use tracking to determine when more metrics can be added without overflowing the outputs.
 	monitor.acc = acc.WithTracking(monitor.MaxBufferedMetrics)
 	go func() {
@@ -165,6 +204,10 @@ func (monitor *DirectoryMonitor) Start(acc telegraf.Accumulator) error {
 }
 
 func (monitor *DirectoryMonitor) Gather(_ telegraf.Accumulator) error {
+	if monitor.store != nil {
+		return monitor.gatherObjects()
+	}
+
 	processFile := func(path string) error {
 		// We've been cancelled via Stop().
 		if monitor.context.Err() != nil {
@@ -230,6 +273,12 @@ func (monitor *DirectoryMonitor) Stop() {
 	close(monitor.filesToProcess)
 	monitor.Log.Warnf("Exiting the Directory Monitor plugin. Waiting to quit until all current files are finished.")
 	monitor.waitGroup.Wait()
+
+	if monitor.store != nil {
+		if err := monitor.store.close(); err != nil {
+			monitor.Log.Errorf("Closing object store client failed: %v", err)
+		}
+	}
 }
 
 func (monitor *DirectoryMonitor) monitor() {
@@ -243,7 +292,11 @@ func (monitor *DirectoryMonitor) monitor() {
 			continue
 		}
 
-		monitor.read(filePath)
+		if monitor.store != nil {
+			monitor.readObject(filePath)
+		} else {
+			monitor.read(filePath)
+		}
 
 		// We've finished reading the file and moved it away, delete it from files in use.
 		monitor.filesInUse.Delete(filePath)
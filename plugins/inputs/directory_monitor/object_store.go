@@ -0,0 +1,324 @@
+package directory_monitor
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// objectInfo contains the metadata of an object required to detect new or
+// changed objects
+type objectInfo struct {
+	key      string
+	etag     string
+	modified time.Time
+}
+
+// objectStore abstracts the different object-store providers
+type objectStore interface {
+	list(ctx context.Context, recursive bool) ([]objectInfo, error)
+	open(ctx context.Context, key string) (io.ReadCloser, error)
+	close() error
+}
+
+// parseObjectStoreURL returns the scheme, bucket and prefix of the given URL
+// in the form "s3://bucket/prefix"
+func parseObjectStoreURL(address string) (scheme, bucket, prefix string, err error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", "", "", fmt.Errorf("parsing object store URL failed: %w", err)
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+	default:
+		return "", "", "", fmt.Errorf("unsupported object store scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", "", errors.New("missing bucket in object store URL")
+	}
+
+	// Only list objects within the "directory" given by the prefix
+	prefix = strings.TrimPrefix(u.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return u.Scheme, u.Host, prefix, nil
+}
+
+func (monitor *DirectoryMonitor) connectObjectStore(ctx context.Context) (objectStore, error) {
+	switch monitor.objectScheme {
+	case "s3":
+		cfg, err := monitor.CredentialConfig.Credentials()
+		if err != nil {
+			return nil, fmt.Errorf("getting credentials failed: %w", err)
+		}
+		client := s3.NewFromConfig(cfg, func(options *s3.Options) {
+			if monitor.CredentialConfig.EndpointURL != "" {
+				options.BaseEndpoint = &monitor.CredentialConfig.EndpointURL
+				// S3 compatible stores like MinIO usually require path-style
+				// addressing
+				options.UsePathStyle = true
+			}
+		})
+		return &s3Store{client: client, bucket: monitor.objectBucket, prefix: monitor.objectPrefix}, nil
+	case "gs":
+		var options []option.ClientOption
+		if monitor.CredentialsFile != "" {
+			options = append(options, option.WithCredentialsFile(monitor.CredentialsFile))
+		}
+		client, err := gcs.NewClient(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("creating client failed: %w", err)
+		}
+		return &gcsStore{client: client, bucket: monitor.objectBucket, prefix: monitor.objectPrefix}, nil
+	}
+	return nil, fmt.Errorf("unsupported object store scheme %q", monitor.objectScheme)
+}
+
+// gatherObjects lists the objects of the monitored prefix and queues all new
+// or changed objects for processing
+func (monitor *DirectoryMonitor) gatherObjects() error {
+	objects, err := monitor.store.list(monitor.context, monitor.Recursive)
+	if err != nil {
+		if monitor.context.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("listing objects failed: %w", err)
+	}
+
+	current := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		// We've been cancelled via Stop().
+		if monitor.context.Err() != nil {
+			return nil
+		}
+		current[obj.key] = true
+
+		// Objects are only visible once completely written, however the
+		// threshold allows to wait for a set of related objects
+		if time.Since(obj.modified) < time.Duration(monitor.DirectoryDurationThreshold) {
+			continue
+		}
+
+		monitor.etagsMu.Lock()
+		etag, found := monitor.etags[obj.key]
+		monitor.etagsMu.Unlock()
+		if found && etag == obj.etag {
+			continue
+		}
+
+		basePath := strings.TrimPrefix(obj.key, monitor.objectPrefix)
+		if !monitor.isMonitoredFile(basePath) || monitor.isIgnoredFile(basePath) {
+			continue
+		}
+
+		monitor.pendingETags.Store(obj.key, obj.etag)
+		select {
+		case monitor.filesToProcess <- obj.key:
+		default:
+		}
+	}
+
+	// Forget about deleted objects
+	monitor.etagsMu.Lock()
+	for key := range monitor.etags {
+		if !current[key] {
+			delete(monitor.etags, key)
+		}
+	}
+	monitor.etagsMu.Unlock()
+
+	return nil
+}
+
+func (monitor *DirectoryMonitor) readObject(key string) {
+	v, found := monitor.pendingETags.Load(key)
+	if !found {
+		// Already processed as part of a duplicate queue entry
+		return
+	}
+	defer monitor.pendingETags.Delete(key)
+	etag := v.(string)
+
+	err := monitor.ingestObject(key)
+	if err != nil && monitor.context.Err() != nil {
+		// We've been cancelled via Stop(), so retry after restart
+		return
+	}
+
+	// Objects cannot be moved, so remember the processed version including
+	// erroneous objects to not process the object again
+	monitor.etagsMu.Lock()
+	monitor.etags[key] = etag
+	monitor.etagsMu.Unlock()
+
+	if err != nil {
+		monitor.Log.Errorf("Error while reading object %q: %v", key, err)
+		monitor.filesDropped.Incr(1)
+		monitor.filesDroppedDir.Incr(1)
+		return
+	}
+	monitor.filesProcessed.Incr(1)
+	monitor.filesProcessedDir.Incr(1)
+}
+
+func (monitor *DirectoryMonitor) ingestObject(key string) error {
+	body, err := monitor.store.open(monitor.context, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	parser, err := monitor.parserFunc()
+	if err != nil {
+		return fmt.Errorf("creating parser: %w", err)
+	}
+
+	// Handle gzipped objects.
+	var reader io.Reader = body
+	if path.Ext(key) == ".gz" {
+		reader, err = gzip.NewReader(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return monitor.parseFile(parser, reader, key)
+}
+
+func (monitor *DirectoryMonitor) GetState() interface{} {
+	monitor.etagsMu.Lock()
+	defer monitor.etagsMu.Unlock()
+
+	etags := make(map[string]string, len(monitor.etags))
+	for k, v := range monitor.etags {
+		etags[k] = v
+	}
+	return etags
+}
+
+func (monitor *DirectoryMonitor) SetState(state interface{}) error {
+	etags, ok := state.(map[string]string)
+	if !ok {
+		return errors.New("state has to be of type 'map[string]string'")
+	}
+
+	monitor.etagsMu.Lock()
+	defer monitor.etagsMu.Unlock()
+	for k, v := range etags {
+		monitor.etags[k] = v
+	}
+	return nil
+}
+
+type s3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func (s *s3Store) list(ctx context.Context, recursive bool) ([]objectInfo, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	}
+	if !recursive {
+		input.Delimiter = aws.String("/")
+	}
+
+	var objects []objectInfo
+	paginator := s3.NewListObjectsV2Paginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			// Skip "directory" placeholder objects
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			objects = append(objects, objectInfo{
+				key:      key,
+				etag:     aws.ToString(obj.ETag),
+				modified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (s *s3Store) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (*s3Store) close() error {
+	return nil
+}
+
+type gcsStore struct {
+	client *gcs.Client
+	bucket string
+	prefix string
+}
+
+func (s *gcsStore) list(ctx context.Context, recursive bool) ([]objectInfo, error) {
+	query := &gcs.Query{Prefix: s.prefix}
+	if !recursive {
+		query.Delimiter = "/"
+	}
+	if err := query.SetAttrSelection([]string{"Name", "Etag", "Updated"}); err != nil {
+		return nil, err
+	}
+
+	var objects []objectInfo
+	it := s.client.Bucket(s.bucket).Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Skip the synthetic "directory" entries of non-recursive listings
+		// and placeholder objects
+		if attrs.Name == "" || strings.HasSuffix(attrs.Name, "/") {
+			continue
+		}
+		objects = append(objects, objectInfo{
+			key:      attrs.Name,
+			etag:     attrs.Etag,
+			modified: attrs.Updated,
+		})
+	}
+	return objects, nil
+}
+
+func (s *gcsStore) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+}
+
+func (s *gcsStore) close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,213 @@
+package directory_monitor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockObject struct {
+	etag string
+	data []byte
+}
+
+type mockStore struct {
+	prefix string
+
+	sync.Mutex
+	objects map[string]mockObject
+}
+
+func (s *mockStore) put(key, etag, data string) {
+	s.Lock()
+	defer s.Unlock()
+	s.objects[s.prefix+key] = mockObject{etag: etag, data: []byte(data)}
+}
+
+func (s *mockStore) list(context.Context, bool) ([]objectInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	objects := make([]objectInfo, 0, len(s.objects))
+	for key, obj := range s.objects {
+		objects = append(objects, objectInfo{key: key, etag: obj.etag, modified: time.Now().Add(-time.Minute)})
+	}
+	return objects, nil
+}
+
+func (s *mockStore) open(_ context.Context, key string) (io.ReadCloser, error) {
+	s.Lock()
+	defer s.Unlock()
+	obj, found := s.objects[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (*mockStore) close() error {
+	return nil
+}
+
+func TestObjectStoreInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DirectoryMonitor
+		expected string
+	}{
+		{
+			name: "directory and object store",
+			plugin: &DirectoryMonitor{
+				Directory:   "/tmp",
+				ObjectStore: "s3://bucket/prefix",
+			},
+			expected: "'directory' and 'object_store' cannot be used together",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &DirectoryMonitor{ObjectStore: "ftp://bucket/prefix"},
+			expected: `unsupported object store scheme "ftp"`,
+		},
+		{
+			name:     "missing bucket",
+			plugin:   &DirectoryMonitor{ObjectStore: "gs:///prefix"},
+			expected: "missing bucket in object store URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.FileQueueSize = defaultFileQueueSize
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseObjectStoreURL(t *testing.T) {
+	scheme, bucket, prefix, err := parseObjectStoreURL("s3://metrics/incoming")
+	require.NoError(t, err)
+	require.Equal(t, "s3", scheme)
+	require.Equal(t, "metrics", bucket)
+	require.Equal(t, "incoming/", prefix)
+
+	scheme, bucket, prefix, err = parseObjectStoreURL("gs://metrics")
+	require.NoError(t, err)
+	require.Equal(t, "gs", scheme)
+	require.Equal(t, "metrics", bucket)
+	require.Empty(t, prefix)
+}
+
+func TestObjectStore(t *testing.T) {
+	plugin := &DirectoryMonitor{
+		ObjectStore:        "s3://bucket/incoming",
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        defaultParseMethod,
+		FilesToIgnore:      []string{`\.ignore$`},
+		Log:                testutil.Logger{},
+	}
+	plugin.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+	require.NoError(t, plugin.Init())
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write([]byte("test,source=gzip value=3i 1700000000000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	store := &mockStore{prefix: "incoming/", objects: make(map[string]mockObject)}
+	store.put("a.influx", "1", "test,source=a value=1i 1700000000000000000\ntest,source=a value=2i 1700000001000000000\n")
+	store.put("b.influx.gz", "1", compressed.String())
+	store.put("c.ignore", "1", "test,source=c value=4i 1700000000000000000\n")
+	plugin.store = store
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Process the new objects
+	require.NoError(t, plugin.Gather(&acc))
+	acc.Wait(3)
+	require.Eventually(t, func() bool {
+		return len(plugin.GetState().(map[string]string)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Unchanged objects must not be processed again
+	require.NoError(t, plugin.Gather(&acc))
+	require.Never(t, func() bool {
+		return acc.NMetrics() > 3
+	}, 500*time.Millisecond, 10*time.Millisecond)
+
+	// Changed objects are processed again
+	store.put("a.influx", "2", "test,source=a value=5i 1700000002000000000\n")
+	require.NoError(t, plugin.Gather(&acc))
+	acc.Wait(4)
+
+	require.Empty(t, acc.Errors)
+	expected := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(1)}, time.Unix(1700000000, 0)),
+		testutil.MustMetric("test", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(2)}, time.Unix(1700000001, 0)),
+		testutil.MustMetric("test", map[string]string{"source": "gzip"}, map[string]interface{}{"value": int64(3)}, time.Unix(1700000000, 0)),
+		testutil.MustMetric("test", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(5)}, time.Unix(1700000002, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	require.Eventually(t, func() bool {
+		return plugin.GetState().(map[string]string)["incoming/a.influx"] == "2"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestObjectStoreRestoreState(t *testing.T) {
+	plugin := &DirectoryMonitor{
+		ObjectStore:        "gs://bucket",
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        defaultParseMethod,
+		Log:                testutil.Logger{},
+	}
+	plugin.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState(map[string]string{"a.influx": "1", "deleted.influx": "1"}))
+
+	store := &mockStore{objects: make(map[string]mockObject)}
+	store.put("a.influx", "1", "test,source=a value=1i 1700000000000000000\n")
+	store.put("b.influx", "1", "test,source=b value=2i 1700000000000000000\n")
+	plugin.store = store
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Only the object not contained in the state must be processed
+	require.NoError(t, plugin.Gather(&acc))
+	acc.Wait(1)
+	require.Eventually(t, func() bool {
+		return len(plugin.GetState().(map[string]string)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Empty(t, acc.Errors)
+	require.Equal(t, map[string]string{"a.influx": "1", "b.influx": "1"}, plugin.GetState())
+	expected := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{"source": "b"}, map[string]interface{}{"value": int64(2)}, time.Unix(1700000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
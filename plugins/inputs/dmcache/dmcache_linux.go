@@ -30,6 +30,9 @@ type cacheStatus struct {
 	demotions         int64
 	promotions        int64
 	dirty             int64
+	ioMode            string
+	metadataMode      string
+	needsCheck        bool
 }
 
 func (c *DMCache) Gather(acc telegraf.Accumulator) error {
@@ -134,9 +137,45 @@ func parseDMSetupStatus(line string) (cacheStatus, error) {
 		return cacheStatus{}, err
 	}
 
+	// The remaining values are the features, the core and policy arguments and,
+	// depending on the kernel version, the metadata mode and the needs_check
+	// flag. All argument lists are prefixed by their length.
+	features, rest, ok := countedArgs(values[15:])
+	if !ok {
+		return status, nil
+	}
+	for _, feature := range features {
+		switch feature {
+		case "writeback", "writethrough", "passthrough":
+			status.ioMode = feature
+		}
+	}
+	if _, rest, ok = countedArgs(rest); !ok || len(rest) < 1 {
+		return status, nil
+	}
+	// Skip the policy name preceding the policy arguments
+	if _, rest, ok = countedArgs(rest[1:]); !ok || len(rest) < 2 {
+		return status, nil
+	}
+	status.metadataMode = rest[0]
+	status.needsCheck = rest[1] == "needs_check"
+
 	return status, nil
 }
 
+// countedArgs splits the argument list prefixed by the number of arguments
+// from the given values and returns the arguments and the remaining values
+func countedArgs(values []string) (args, rest []string, ok bool) {
+	if len(values) < 1 {
+		return nil, nil, false
+	}
+	n, err := strconv.Atoi(values[0])
+	if err != nil || n < 0 || len(values) < n+1 {
+		return nil, nil, false
+	}
+	return values[1 : n+1], values[n+1:], true
+}
+
 func aggregateStats(totalStatus *cacheStatus, status cacheStatus) {
 	totalStatus.length += status.length
 	totalStatus.metadataBlocksize += status.metadataBlocksize
@@ -170,6 +209,19 @@ func toFields(status cacheStatus) map[string]interface{} {
 	fields["demotions"] = status.demotions
 	fields["promotions"] = status.promotions
 	fields["dirty"] = status.dirty
+	if reads := status.readHits + status.readMisses; reads > 0 {
+		fields["read_hit_ratio"] = float64(status.readHits) / float64(reads) * 100
+	}
+	if writes := status.writeHits + status.writeMisses; writes > 0 {
+		fields["write_hit_ratio"] = float64(status.writeHits) / float64(writes) * 100
+	}
+	if status.ioMode != "" {
+		fields["io_mode"] = status.ioMode
+	}
+	if status.metadataMode != "" {
+		fields["metadata_mode"] = status.metadataMode
+		fields["needs_check"] = status.needsCheck
+	}
 	return fields
 }
 
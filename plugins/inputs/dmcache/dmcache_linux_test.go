@@ -52,6 +52,9 @@ func TestPerDeviceGoodOutput(t *testing.T) {
 		"demotions":          int64(0),
 		"promotions":         int64(7),
 		"dirty":              int64(0),
+		"read_hit_ratio":     float64(139) / float64(139+352643) * 100,
+		"write_hit_ratio":    float64(15) / float64(15+46) * 100,
+		"io_mode":            "writeback",
 	}
 	acc.AssertContainsTaggedFields(t, measurement, fields1, tags1)
 
@@ -73,6 +76,9 @@ func TestPerDeviceGoodOutput(t *testing.T) {
 		"demotions":          int64(0),
 		"promotions":         int64(0),
 		"dirty":              int64(0),
+		"read_hit_ratio":     float64(2409) / float64(2409+286) * 100,
+		"write_hit_ratio":    float64(265) / float64(265+524682) * 100,
+		"io_mode":            "writethrough",
 	}
 	acc.AssertContainsTaggedFields(t, measurement, fields2, tags2)
 
@@ -95,6 +101,8 @@ func TestPerDeviceGoodOutput(t *testing.T) {
 		"demotions":          int64(0),
 		"promotions":         int64(7),
 		"dirty":              int64(0),
+		"read_hit_ratio":     float64(2548) / float64(2548+352929) * 100,
+		"write_hit_ratio":    float64(280) / float64(280+524728) * 100,
 	}
 	acc.AssertContainsTaggedFields(t, measurement, fields3, tags3)
 }
@@ -130,10 +138,47 @@ func TestNotPerDeviceGoodOutput(t *testing.T) {
 		"demotions":          int64(0),
 		"promotions":         int64(7),
 		"dirty":              int64(0),
+		"read_hit_ratio":     float64(2548) / float64(2548+352929) * 100,
+		"write_hit_ratio":    float64(280) / float64(280+524728) * 100,
 	}
 	acc.AssertContainsTaggedFields(t, measurement, fields, tags)
 }
 
+func TestMetadataModeOutput(t *testing.T) {
+	var acc testutil.Accumulator
+	var plugin = &DMCache{
+		PerDevice: true,
+		getCurrentStatus: func() ([]string, error) {
+			return []string{
+				"cs-3: 0 2097152 cache 8 160/4096 128 0/16384 0 0 0 0 0 0 0 1 passthrough 2 migration_threshold 2048 smq 0 ro needs_check",
+			}, nil
+		},
+	}
+
+	require.NoError(t, plugin.Gather(&acc))
+
+	fields := map[string]interface{}{
+		"length":             int64(2097152),
+		"metadata_blocksize": int64(8),
+		"metadata_used":      int64(160),
+		"metadata_total":     int64(4096),
+		"cache_blocksize":    int64(128),
+		"cache_used":         int64(0),
+		"cache_total":        int64(16384),
+		"read_hits":          int64(0),
+		"read_misses":        int64(0),
+		"write_hits":         int64(0),
+		"write_misses":       int64(0),
+		"demotions":          int64(0),
+		"promotions":         int64(0),
+		"dirty":              int64(0),
+		"io_mode":            "passthrough",
+		"metadata_mode":      "ro",
+		"needs_check":        true,
+	}
+	acc.AssertContainsTaggedFields(t, measurement, fields, map[string]string{"device": "cs-3"})
+}
+
 func TestNoDevicesOutput(t *testing.T) {
 	var acc testutil.Accumulator
 	var plugin = &DMCache{
@@ -0,0 +1,407 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package envoy
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Listener statistics reported per listener, other statistics below the
+// listener prefix belong to e.g. the network filters of the listener
+var listenerStats = map[string]bool{
+	"downstream_cx_active":          true,
+	"downstream_cx_destroy":         true,
+	"downstream_cx_overflow":        true,
+	"downstream_cx_overload_reject": true,
+	"downstream_cx_total":           true,
+	"downstream_global_cx_overflow": true,
+	"downstream_pre_cx_active":      true,
+	"downstream_pre_cx_timeout":     true,
+	"no_filter_chain_match":         true,
+}
+
+type Envoy struct {
+	URLs                  []string        `toml:"urls"`
+	ClusterInclude        []string        `toml:"cluster_include"`
+	ClusterExclude        []string        `toml:"cluster_exclude"`
+	GatherUpstreams       bool            `toml:"gather_upstreams"`
+	GatherCircuitBreakers bool            `toml:"gather_circuit_breakers"`
+	GatherListeners       bool            `toml:"gather_listeners"`
+	Log                   telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	client  *http.Client
+}
+
+func (*Envoy) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Envoy) Init() error {
+	if len(e.URLs) == 0 {
+		e.URLs = []string{"http://localhost:9901"}
+	}
+	for i, u := range e.URLs {
+		e.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	e.include = make([]*regexp.Regexp, 0, len(e.ClusterInclude))
+	for _, pattern := range e.ClusterInclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling cluster include pattern %q failed: %w", pattern, err)
+		}
+		e.include = append(e.include, re)
+	}
+	e.exclude = make([]*regexp.Regexp, 0, len(e.ClusterExclude))
+	for _, pattern := range e.ClusterExclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling cluster exclude pattern %q failed: %w", pattern, err)
+		}
+		e.exclude = append(e.exclude, re)
+	}
+
+	client, err := e.HTTPClientConfig.CreateClient(context.Background(), e.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	e.client = client
+
+	return nil
+}
+
+func (e *Envoy) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range e.URLs {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			if err := e.gatherURL(acc, address); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", address, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (e *Envoy) Stop() {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+}
+
+func (e *Envoy) gatherURL(acc telegraf.Accumulator, address string) error {
+	var clusters clustersResponse
+	if err := e.loadJSON(address+"/clusters?format=json", &clusters); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	// The circuit breaker state and the listener connections are only
+	// available as statistics so query the ones required
+	var stats map[string]int64
+	if e.GatherCircuitBreakers || e.GatherListeners {
+		var patterns []string
+		if e.GatherCircuitBreakers {
+			patterns = append(patterns, `cluster\..+\.circuit_breakers\.`)
+		}
+		if e.GatherListeners {
+			patterns = append(patterns, `listener\.`)
+		}
+		query := url.Values{
+			"format": []string{"json"},
+			"filter": []string{"^(" + strings.Join(patterns, "|") + ")"},
+		}
+
+		var response statsResponse
+		if err := e.loadJSON(address+"/stats?"+query.Encode(), &response); err != nil {
+			acc.AddError(fmt.Errorf("querying statistics of %q failed: %w", address, err))
+		} else {
+			stats = make(map[string]int64, len(response.Stats))
+			for _, s := range response.Stats {
+				if s.Name == "" {
+					continue
+				}
+				if v, err := s.Value.Int64(); err == nil {
+					stats[s.Name] = v
+				}
+			}
+		}
+	}
+
+	for i := range clusters.ClusterStatuses {
+		cluster := &clusters.ClusterStatuses[i]
+		if !e.collectCluster(cluster.Name) {
+			continue
+		}
+		e.gatherCluster(acc, address, cluster, now)
+		if e.GatherCircuitBreakers {
+			gatherCircuitBreakers(acc, address, cluster, stats, now)
+		}
+	}
+
+	if e.GatherListeners && stats != nil {
+		gatherListeners(acc, address, stats, now)
+	}
+
+	return nil
+}
+
+func (e *Envoy) collectCluster(name string) bool {
+	for _, re := range e.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(e.include) == 0 {
+		return true
+	}
+	for _, re := range e.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Envoy) gatherCluster(acc telegraf.Accumulator, address string, cluster *clusterStatus, now time.Time) {
+	counts := map[string]int{
+		"healthy":   0,
+		"degraded":  0,
+		"unhealthy": 0,
+	}
+	for _, host := range cluster.HostStatuses {
+		health := host.HealthStatus.health()
+		counts[health]++
+
+		if !e.GatherUpstreams {
+			continue
+		}
+
+		tags := map[string]string{
+			"url":     address,
+			"cluster": cluster.Name,
+			"host":    host.Address.String(),
+		}
+		fields := map[string]interface{}{
+			"health":                       health,
+			"eds_health_status":            host.HealthStatus.edsHealthStatus(),
+			"failed_active_health_check":   host.HealthStatus.FailedActiveHealthCheck,
+			"failed_outlier_check":         host.HealthStatus.FailedOutlierCheck,
+			"failed_active_degraded_check": host.HealthStatus.FailedActiveDegradedCheck,
+			"pending_dynamic_removal":      host.HealthStatus.PendingDynamicRemoval,
+			"weight":                       int64(host.Weight),
+			"priority":                     int64(host.Priority),
+		}
+		for _, s := range host.Stats {
+			// Zero values are omitted by Envoy
+			var v int64
+			if s.Value != "" {
+				var err error
+				if v, err = s.Value.Int64(); err != nil {
+					continue
+				}
+			}
+			fields[s.Name] = v
+		}
+		// The success rate is only available with outlier detection
+		if host.SuccessRate != nil && host.SuccessRate.Value >= 0 {
+			fields["success_rate"] = host.SuccessRate.Value
+		}
+		acc.AddFields("envoy_upstream", fields, tags, now)
+	}
+
+	tags := map[string]string{
+		"url":     address,
+		"cluster": cluster.Name,
+	}
+	fields := map[string]interface{}{
+		"added_via_api":   cluster.AddedViaAPI,
+		"hosts":           len(cluster.HostStatuses),
+		"healthy_hosts":   counts["healthy"],
+		"degraded_hosts":  counts["degraded"],
+		"unhealthy_hosts": counts["unhealthy"],
+	}
+	acc.AddFields("envoy_cluster", fields, tags, now)
+}
+
+func gatherCircuitBreakers(acc telegraf.Accumulator, address string, cluster *clusterStatus, stats map[string]int64, now time.Time) {
+	fieldsByPriority := make(map[string]map[string]interface{})
+	fieldsFor := func(priority string) map[string]interface{} {
+		priority = strings.ToLower(priority)
+		if priority == "" {
+			priority = "default"
+		}
+		fields, found := fieldsByPriority[priority]
+		if !found {
+			fields = make(map[string]interface{})
+			fieldsByPriority[priority] = fields
+		}
+		return fields
+	}
+
+	for _, t := range cluster.CircuitBreakers.Thresholds {
+		fields := fieldsFor(t.Priority)
+		if t.MaxConnections != nil {
+			fields["max_connections"] = int64(t.MaxConnections.Value)
+		}
+		if t.MaxPendingRequests != nil {
+			fields["max_pending_requests"] = int64(t.MaxPendingRequests.Value)
+		}
+		if t.MaxRequests != nil {
+			fields["max_requests"] = int64(t.MaxRequests.Value)
+		}
+		if t.MaxRetries != nil {
+			fields["max_retries"] = int64(t.MaxRetries.Value)
+		}
+	}
+
+	// Statistics use the observability name which might differ from the
+	// cluster name, e.g. if an alternative statistics name is configured
+	name := cluster.ObservabilityName
+	if name == "" {
+		name = cluster.Name
+	}
+	prefix := "cluster." + name + ".circuit_breakers."
+	for key, value := range stats {
+		suffix, found := strings.CutPrefix(key, prefix)
+		if !found {
+			continue
+		}
+		priority, field, found := strings.Cut(suffix, ".")
+		if !found || strings.Contains(field, ".") {
+			continue
+		}
+		fieldsFor(priority)[field] = value
+	}
+
+	for priority, fields := range fieldsByPriority {
+		tags := map[string]string{
+			"url":      address,
+			"cluster":  cluster.Name,
+			"priority": priority,
+		}
+		acc.AddFields("envoy_circuit_breaker", fields, tags, now)
+	}
+}
+
+func gatherListeners(acc telegraf.Accumulator, address string, stats map[string]int64, now time.Time) {
+	fieldsByListener := make(map[string]map[string]interface{})
+	for key, value := range stats {
+		name, found := strings.CutPrefix(key, "listener.")
+		if !found {
+			continue
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			continue
+		}
+		listener, field := name[:idx], name[idx+1:]
+		if !listenerStats[field] {
+			continue
+		}
+
+		// Skip the per-worker statistics of the listener
+		if idx := strings.LastIndex(listener, "."); idx >= 0 {
+			last := listener[idx+1:]
+			if strings.HasPrefix(last, "worker_") || last == "main_thread" {
+				continue
+			}
+		}
+
+		fields, found := fieldsByListener[listener]
+		if !found {
+			fields = make(map[string]interface{})
+			fieldsByListener[listener] = fields
+		}
+		fields[field] = value
+	}
+
+	for listener, fields := range fieldsByListener {
+		tags := map[string]string{
+			"url":      address,
+			"listener": listener,
+		}
+		acc.AddFields("envoy_listener", fields, tags, now)
+	}
+}
+
+func (h *hostHealthStatus) edsHealthStatus() string {
+	if h.EDSHealthStatus == "" {
+		return "UNKNOWN"
+	}
+	return h.EDSHealthStatus
+}
+
+// health classifies the host the same way Envoy does for load balancing
+func (h *hostHealthStatus) health() string {
+	switch h.EDSHealthStatus {
+	case "UNHEALTHY", "DRAINING", "TIMEOUT":
+		return "unhealthy"
+	}
+	if h.FailedActiveHealthCheck || h.FailedOutlierCheck {
+		return "unhealthy"
+	}
+	if h.FailedActiveDegradedCheck || h.EDSHealthStatus == "DEGRADED" {
+		return "degraded"
+	}
+	return "healthy"
+}
+
+func (e *Envoy) loadJSON(address string, v interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making HTTP request to %q: %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %s", address, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing json response: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("envoy", func() telegraf.Input {
+		return &Envoy{
+			GatherUpstreams:       true,
+			GatherCircuitBreakers: true,
+			GatherListeners:       true,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
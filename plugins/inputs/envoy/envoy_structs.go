@@ -0,0 +1,93 @@
+package envoy
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+)
+
+// Response of the /clusters?format=json admin endpoint
+type clustersResponse struct {
+	ClusterStatuses []clusterStatus `json:"cluster_statuses"`
+}
+
+type clusterStatus struct {
+	Name              string          `json:"name"`
+	ObservabilityName string          `json:"observability_name"`
+	AddedViaAPI       bool            `json:"added_via_api"`
+	HostStatuses      []hostStatus    `json:"host_statuses"`
+	CircuitBreakers   circuitBreakers `json:"circuit_breakers"`
+}
+
+type circuitBreakers struct {
+	Thresholds []threshold `json:"thresholds"`
+}
+
+// Envoy omits the priority for the default routing priority
+type threshold struct {
+	Priority           string      `json:"priority"`
+	MaxConnections     *uint64Wrap `json:"max_connections"`
+	MaxPendingRequests *uint64Wrap `json:"max_pending_requests"`
+	MaxRequests        *uint64Wrap `json:"max_requests"`
+	MaxRetries         *uint64Wrap `json:"max_retries"`
+}
+
+type uint64Wrap struct {
+	Value uint64 `json:"value"`
+}
+
+type hostStatus struct {
+	Address      hostAddress      `json:"address"`
+	Stats        []simpleMetric   `json:"stats"`
+	HealthStatus hostHealthStatus `json:"health_status"`
+	SuccessRate  *percent         `json:"success_rate"`
+	Weight       uint32           `json:"weight"`
+	Priority     uint32           `json:"priority"`
+}
+
+type hostAddress struct {
+	SocketAddress *struct {
+		Address   string `json:"address"`
+		PortValue uint32 `json:"port_value"`
+	} `json:"socket_address"`
+	Pipe *struct {
+		Path string `json:"path"`
+	} `json:"pipe"`
+}
+
+func (a *hostAddress) String() string {
+	switch {
+	case a.SocketAddress != nil:
+		return net.JoinHostPort(a.SocketAddress.Address, strconv.FormatUint(uint64(a.SocketAddress.PortValue), 10))
+	case a.Pipe != nil:
+		return a.Pipe.Path
+	}
+	return ""
+}
+
+// Envoy encodes 64-bit integers as strings and omits zero values
+type simpleMetric struct {
+	Name  string      `json:"name"`
+	Value json.Number `json:"value"`
+}
+
+type hostHealthStatus struct {
+	FailedActiveHealthCheck   bool   `json:"failed_active_health_check"`
+	FailedOutlierCheck        bool   `json:"failed_outlier_check"`
+	FailedActiveDegradedCheck bool   `json:"failed_active_degraded_check"`
+	PendingDynamicRemoval     bool   `json:"pending_dynamic_removal"`
+	EDSHealthStatus           string `json:"eds_health_status"`
+}
+
+type percent struct {
+	Value float64 `json:"value"`
+}
+
+// Response of the /stats?format=json admin endpoint, histograms are reported
+// as a separate entry without a name and are ignored
+type statsResponse struct {
+	Stats []struct {
+		Name  string      `json:"name"`
+		Value json.Number `json:"value"`
+	} `json:"stats"`
+}
@@ -0,0 +1,356 @@
+package envoy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/clusters": "clusters.json",
+		"/stats":    "stats.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fn, found := responses[r.URL.Path]
+		if !found || r.URL.Query().Get("format") != "json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+}
+
+func TestGather(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	plugin := &Envoy{
+		URLs:                  []string{server.URL},
+		GatherUpstreams:       true,
+		GatherCircuitBreakers: true,
+		GatherListeners:       true,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"envoy_cluster",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "backend",
+			},
+			map[string]interface{}{
+				"added_via_api":   false,
+				"hosts":           2,
+				"healthy_hosts":   1,
+				"degraded_hosts":  0,
+				"unhealthy_hosts": 1,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_cluster",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "outbound|443||api.example.com",
+			},
+			map[string]interface{}{
+				"added_via_api":   true,
+				"hosts":           1,
+				"healthy_hosts":   0,
+				"degraded_hosts":  1,
+				"unhealthy_hosts": 0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_cluster",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "internal_metrics",
+			},
+			map[string]interface{}{
+				"added_via_api":   false,
+				"hosts":           1,
+				"healthy_hosts":   1,
+				"degraded_hosts":  0,
+				"unhealthy_hosts": 0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_upstream",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "backend",
+				"host":    "10.0.0.21:8080",
+			},
+			map[string]interface{}{
+				"health":                       "healthy",
+				"eds_health_status":            "HEALTHY",
+				"failed_active_health_check":   false,
+				"failed_outlier_check":         false,
+				"failed_active_degraded_check": false,
+				"pending_dynamic_removal":      false,
+				"weight":                       int64(1),
+				"priority":                     int64(0),
+				"cx_active":                    int64(2),
+				"cx_connect_fail":              int64(0),
+				"cx_total":                     int64(12),
+				"rq_active":                    int64(0),
+				"rq_error":                     int64(0),
+				"rq_success":                   int64(118),
+				"rq_timeout":                   int64(0),
+				"rq_total":                     int64(120),
+				"success_rate":                 98.5,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_upstream",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "backend",
+				"host":    "10.0.0.22:8080",
+			},
+			map[string]interface{}{
+				"health":                       "unhealthy",
+				"eds_health_status":            "HEALTHY",
+				"failed_active_health_check":   false,
+				"failed_outlier_check":         true,
+				"failed_active_degraded_check": false,
+				"pending_dynamic_removal":      false,
+				"weight":                       int64(1),
+				"priority":                     int64(1),
+				"cx_active":                    int64(0),
+				"cx_connect_fail":              int64(3),
+				"cx_total":                     int64(5),
+				"rq_active":                    int64(0),
+				"rq_error":                     int64(2),
+				"rq_success":                   int64(0),
+				"rq_timeout":                   int64(0),
+				"rq_total":                     int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_upstream",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "outbound|443||api.example.com",
+				"host":    "192.0.2.10:443",
+			},
+			map[string]interface{}{
+				"health":                       "degraded",
+				"eds_health_status":            "DEGRADED",
+				"failed_active_health_check":   false,
+				"failed_outlier_check":         false,
+				"failed_active_degraded_check": false,
+				"pending_dynamic_removal":      false,
+				"weight":                       int64(2),
+				"priority":                     int64(0),
+				"cx_active":                    int64(1),
+				"cx_total":                     int64(7),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_upstream",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "internal_metrics",
+				"host":    "/var/run/metrics.sock",
+			},
+			map[string]interface{}{
+				"health":                       "healthy",
+				"eds_health_status":            "UNKNOWN",
+				"failed_active_health_check":   false,
+				"failed_outlier_check":         false,
+				"failed_active_degraded_check": false,
+				"pending_dynamic_removal":      false,
+				"weight":                       int64(1),
+				"priority":                     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_circuit_breaker",
+			map[string]string{
+				"url":      server.URL,
+				"cluster":  "backend",
+				"priority": "default",
+			},
+			map[string]interface{}{
+				"max_connections":      int64(1024),
+				"max_pending_requests": int64(1024),
+				"max_requests":         int64(1024),
+				"max_retries":          int64(3),
+				"cx_open":              int64(0),
+				"cx_pool_open":         int64(0),
+				"remaining_cx":         int64(1022),
+				"rq_open":              int64(0),
+				"rq_pending_open":      int64(0),
+				"rq_retry_open":        int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_circuit_breaker",
+			map[string]string{
+				"url":      server.URL,
+				"cluster":  "backend",
+				"priority": "high",
+			},
+			map[string]interface{}{
+				"max_connections":      int64(2048),
+				"max_pending_requests": int64(2048),
+				"max_requests":         int64(2048),
+				"max_retries":          int64(5),
+				"cx_open":              int64(0),
+				"cx_pool_open":         int64(0),
+				"rq_open":              int64(0),
+				"rq_pending_open":      int64(0),
+				"rq_retry_open":        int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_circuit_breaker",
+			map[string]string{
+				"url":      server.URL,
+				"cluster":  "outbound|443||api.example.com",
+				"priority": "default",
+			},
+			map[string]interface{}{
+				"max_connections":      int64(100),
+				"max_pending_requests": int64(100),
+				"max_requests":         int64(100),
+				"max_retries":          int64(3),
+				"cx_open":              int64(1),
+				"cx_pool_open":         int64(0),
+				"rq_open":              int64(0),
+				"rq_pending_open":      int64(0),
+				"rq_retry_open":        int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_listener",
+			map[string]string{
+				"url":      server.URL,
+				"listener": "0.0.0.0_10000",
+			},
+			map[string]interface{}{
+				"downstream_cx_active":      int64(4),
+				"downstream_cx_destroy":     int64(96),
+				"downstream_cx_overflow":    int64(0),
+				"downstream_cx_total":       int64(100),
+				"downstream_pre_cx_active":  int64(0),
+				"downstream_pre_cx_timeout": int64(0),
+				"no_filter_chain_match":     int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"envoy_listener",
+			map[string]string{
+				"url":      server.URL,
+				"listener": "admin",
+			},
+			map[string]interface{}{
+				"downstream_cx_active":  int64(1),
+				"downstream_cx_destroy": int64(7),
+				"downstream_cx_total":   int64(8),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherClusterFilter(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	plugin := &Envoy{
+		URLs:           []string{server.URL},
+		ClusterInclude: []string{`^outbound\|`, `^backend$`},
+		ClusterExclude: []string{`^backend`},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"envoy_cluster",
+			map[string]string{
+				"url":     server.URL,
+				"cluster": "outbound|443||api.example.com",
+			},
+			map[string]interface{}{
+				"added_via_api":   true,
+				"hosts":           1,
+				"healthy_hosts":   0,
+				"degraded_hosts":  1,
+				"unhealthy_hosts": 0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitInvalidPattern(t *testing.T) {
+	plugin := &Envoy{
+		ClusterInclude: []string{"backend["},
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "compiling cluster include pattern")
+}
+
+func TestGatherFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	plugin := &Envoy{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "403 Forbidden")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
@@ -32,6 +33,7 @@ type Exec struct {
 	IgnoreError bool            `toml:"ignore_error"`
 	Timeout     config.Duration `toml:"timeout"`
 	Log         telegraf.Logger `toml:"-"`
+	process.Limits
 
 	parser telegraf.Parser
 
@@ -51,6 +53,7 @@ type runner interface {
 type commandRunner struct {
 	environment []string
 	timeout     time.Duration
+	limits      process.Limits
 	debug       bool
 }
 
@@ -64,9 +67,14 @@ func (e *Exec) Init() error {
 		e.Commands = append(e.Commands, e.Command)
 	}
 
+	if err := e.Limits.Validate(); err != nil {
+		return err
+	}
+
 	e.runner = &commandRunner{
 		environment: e.Environment,
 		timeout:     time.Duration(e.Timeout),
+		limits:      e.Limits,
 		debug:       e.Log.Level().Includes(telegraf.Debug),
 	}
 
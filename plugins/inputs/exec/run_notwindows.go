@@ -11,7 +11,7 @@ import (
 
 	"github.com/kballard/go-shellquote"
 
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
 )
 
 func (c *commandRunner) run(command string) (out, errout []byte, err error) {
@@ -31,7 +31,7 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 	cmd.Stdout = &outbuf
 	cmd.Stderr = &stderr
 
-	runErr := internal.RunTimeout(cmd, c.timeout)
+	runErr := process.Run(cmd, c.timeout, c.limits)
 
 	if stderr.Len() > 0 && !c.debug {
 		truncate(&stderr)
@@ -13,7 +13,7 @@ import (
 
 	"github.com/kballard/go-shellquote"
 
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
 )
 
 func (c *commandRunner) run(command string) (out, errout []byte, err error) {
@@ -35,7 +35,7 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 	cmd.Stdout = &outbuf
 	cmd.Stderr = &stderr
 
-	runErr := internal.RunTimeout(cmd, c.timeout)
+	runErr := process.Run(cmd, c.timeout, c.limits)
 
 	outbuf = removeWindowsCarriageReturns(outbuf)
 	stderr = removeWindowsCarriageReturns(stderr)
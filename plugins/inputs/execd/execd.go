@@ -27,13 +27,16 @@ var sampleConfig string
 var once sync.Once
 
 type Execd struct {
-	Command      []string        `toml:"command"`
-	Environment  []string        `toml:"environment"`
-	BufferSize   config.Size     `toml:"buffer_size"`
-	Signal       string          `toml:"signal"`
-	RestartDelay config.Duration `toml:"restart_delay"`
-	StopOnError  bool            `toml:"stop_on_error"`
-	Log          telegraf.Logger `toml:"-"`
+	Command       []string        `toml:"command"`
+	Environment   []string        `toml:"environment"`
+	BufferSize    config.Size     `toml:"buffer_size"`
+	Signal        string          `toml:"signal"`
+	RestartDelay  config.Duration `toml:"restart_delay"`
+	MaxRestarts   int             `toml:"max_restarts"`
+	RestartWindow config.Duration `toml:"restart_window"`
+	StopOnError   bool            `toml:"stop_on_error"`
+	Log           telegraf.Logger `toml:"-"`
+	process.Limits
 
 	process      *process.Process
 	acc          telegraf.Accumulator
@@ -49,7 +52,7 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
-	return nil
+	return e.Limits.Validate()
 }
 
 func (e *Execd) SetParser(parser telegraf.Parser) {
@@ -75,6 +78,9 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.ReadStderrFn = e.cmdReadErr
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.StopOnError = e.StopOnError
+	e.process.MaxRestarts = e.MaxRestarts
+	e.process.RestartWindow = time.Duration(e.RestartWindow)
+	e.process.Limits = e.Limits
 	e.process.Log = e.Log
 
 	if err = e.process.Start(); err != nil {
@@ -0,0 +1,244 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package external
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type External struct {
+	Streaming             bool            `toml:"streaming"`
+	MaxUndeliveredBatches int             `toml:"max_undelivered_batches"`
+	Log                   telegraf.Logger `toml:"-"`
+	extplugin.Config
+
+	client *extplugin.Client
+	acc    telegraf.TrackingAccumulator
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Stream currently open and the number of batches added to the
+	// accumulator but not yet delivered to the outputs
+	stream      grpc.BidiStreamingClient[pluginv1.StreamRequest, pluginv1.MetricBatch]
+	undelivered int
+	sync.Mutex
+}
+
+func (*External) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *External) Init() error {
+	if err := e.Config.Validate(); err != nil {
+		return err
+	}
+	if e.Streaming && e.MaxUndeliveredBatches < 1 {
+		return errors.New("'max_undelivered_batches' must be positive")
+	}
+	return nil
+}
+
+func (e *External) Start(acc telegraf.Accumulator) error {
+	client, err := e.Config.Connect(pluginv1.PluginType_PLUGIN_TYPE_INPUT, e.Log)
+	if err != nil {
+		return err
+	}
+	e.client = client
+
+	if !e.Streaming {
+		return nil
+	}
+
+	e.acc = acc.WithTracking(e.MaxUndeliveredBatches)
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		e.receive(ctx)
+	}()
+	go func() {
+		defer e.wg.Done()
+		e.delivered(ctx)
+	}()
+
+	return nil
+}
+
+func (e *External) Gather(acc telegraf.Accumulator) error {
+	if e.Streaming {
+		return nil
+	}
+
+	if _, err := e.client.Handshake(context.Background()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.client.Timeout)
+	defer cancel()
+	response, err := pluginv1.NewInputClient(e.client.Conn).Gather(ctx, &pluginv1.GatherRequest{})
+	if err != nil {
+		return fmt.Errorf("gathering failed: %w", e.client.Check(err))
+	}
+
+	for _, perr := range response.GetErrors() {
+		acc.AddError(errors.New(perr.GetMessage()))
+	}
+	metrics, errs := extplugin.FromProtoBatch(response.GetMetrics())
+	for _, err := range errs {
+		acc.AddError(err)
+	}
+	for _, m := range metrics {
+		acc.AddMetric(m)
+	}
+
+	return nil
+}
+
+func (e *External) Stop() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	if e.client != nil {
+		e.client.Close()
+	}
+}
+
+// receive reads the batches of the plugin and reopens the stream after
+// failures until the context is cancelled
+func (e *External) receive(ctx context.Context) {
+	for {
+		err := e.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		e.acc.AddError(fmt.Errorf("streaming failed: %w", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(e.RestartDelay)):
+		}
+	}
+}
+
+func (e *External) consume(ctx context.Context) error {
+	if _, err := e.client.Handshake(ctx); err != nil {
+		return err
+	}
+
+	stream, err := pluginv1.NewInputClient(e.client.Conn).Stream(ctx)
+	if err != nil {
+		return e.client.Check(err)
+	}
+	defer func() {
+		e.Lock()
+		e.stream = nil
+		e.Unlock()
+	}()
+
+	// Grant credits for all batches not waiting for delivery of a previous
+	// stream
+	e.Lock()
+	e.stream = stream
+	err = e.grant(e.MaxUndeliveredBatches - e.undelivered)
+	e.Unlock()
+	if err != nil {
+		return e.client.Check(err)
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("stream closed by plugin")
+		}
+		if err != nil {
+			return e.client.Check(err)
+		}
+
+		for _, perr := range batch.GetErrors() {
+			e.acc.AddError(errors.New(perr.GetMessage()))
+		}
+		metrics, errs := extplugin.FromProtoBatch(batch.GetMetrics())
+		for _, err := range errs {
+			e.acc.AddError(err)
+		}
+
+		// Batches without metrics are not tracked so return the credit
+		// immediately
+		if len(metrics) == 0 {
+			e.Lock()
+			err := e.grant(1)
+			e.Unlock()
+			if err != nil {
+				return e.client.Check(err)
+			}
+			continue
+		}
+
+		e.Lock()
+		e.undelivered++
+		e.Unlock()
+		e.acc.AddTrackingMetricGroup(metrics)
+	}
+}
+
+// delivered returns a credit to the plugin for each batch delivered to the
+// outputs
+func (e *External) delivered(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.acc.Delivered():
+			e.Lock()
+			e.undelivered--
+			if e.stream != nil {
+				if err := e.grant(1); err != nil {
+					e.Log.Debugf("Granting credit failed: %v", err)
+				}
+			}
+			e.Unlock()
+		}
+	}
+}
+
+// grant sends the given number of credits on the current stream, the caller
+// must hold the lock
+func (e *External) grant(credits int) error {
+	if credits <= 0 {
+		return nil
+	}
+	return e.stream.Send(&pluginv1.StreamRequest{Credits: uint32(credits)})
+}
+
+func init() {
+	inputs.Add("external", func() telegraf.Input {
+		return &External{
+			MaxUndeliveredBatches: 100,
+			Config: extplugin.Config{
+				RestartDelay:   config.Duration(10 * time.Second),
+				StartupTimeout: config.Duration(30 * time.Second),
+				Timeout:        config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
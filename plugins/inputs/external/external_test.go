@@ -0,0 +1,225 @@
+package external
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *External
+		expected string
+	}{
+		{
+			name:     "missing command and address",
+			plugin:   &External{Config: extplugin.Config{StartupTimeout: config.Duration(time.Second), Timeout: config.Duration(time.Second)}},
+			expected: "either 'command' or 'address' required",
+		},
+		{
+			name: "no undelivered batches",
+			plugin: &External{
+				Streaming: true,
+				Config: extplugin.Config{
+					Address:        "localhost:5000",
+					StartupTimeout: config.Duration(time.Second),
+					Timeout:        config.Duration(time.Second),
+				},
+			},
+			expected: "'max_undelivered_batches' must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	srv := &server{
+		gather: func() (*pluginv1.GatherResponse, error) {
+			return &pluginv1.GatherResponse{
+				Metrics: []*pluginv1.Metric{
+					{
+						Name:      "example",
+						Tags:      []*pluginv1.Tag{{Key: "host", Value: "a"}},
+						Fields:    []*pluginv1.Field{{Key: "value", Value: &pluginv1.Field_IntValue{IntValue: 42}}},
+						Timestamp: 1700000000000000000,
+					},
+					{Name: "invalid"},
+				},
+				Errors: []*pluginv1.PluginError{{Message: "device unreachable"}},
+			}, nil
+		},
+	}
+	plugin := newPlugin(t, srv)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	// The settings are passed to the plugin in the handshake
+	require.Equal(t, "value", srv.settings["key"])
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"example",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(0, 1700000000000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	require.Len(t, acc.Errors, 2)
+	require.EqualError(t, acc.Errors[0], "device unreachable")
+	require.EqualError(t, acc.Errors[1], `metric "invalid" without fields`)
+}
+
+func TestGatherError(t *testing.T) {
+	srv := &server{
+		gather: func() (*pluginv1.GatherResponse, error) {
+			return nil, status.Error(codes.Internal, "collecting failed")
+		},
+	}
+	plugin := newPlugin(t, srv)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.ErrorContains(t, plugin.Gather(&acc), "collecting failed")
+}
+
+func TestHandshakeRefused(t *testing.T) {
+	srv := &server{
+		handshake: func(*pluginv1.HandshakeRequest) (*pluginv1.HandshakeResponse, error) {
+			return nil, status.Error(codes.FailedPrecondition, "setting 'key' unknown")
+		},
+	}
+	plugin := newPlugin(t, srv)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), "plugin refused handshake: setting 'key' unknown")
+}
+
+func TestStreaming(t *testing.T) {
+	plugin := newPlugin(t, &server{})
+	plugin.Streaming = true
+	plugin.MaxUndeliveredBatches = 2
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The plugin must not send more batches than undelivered batches allowed
+	acc.Wait(2)
+	require.Never(t, func() bool {
+		return acc.NMetrics() > 2
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	// Delivering a batch allows the plugin to send the next one
+	acc.GetTelegrafMetrics()[0].Accept()
+	acc.Wait(3)
+	require.Empty(t, acc.Errors)
+}
+
+func newPlugin(t *testing.T, srv *server) *External {
+	return &External{
+		Log: testutil.Logger{},
+		Config: extplugin.Config{
+			Address:        newServer(t, srv),
+			StartupTimeout: config.Duration(5 * time.Second),
+			Timeout:        config.Duration(5 * time.Second),
+			RestartDelay:   config.Duration(100 * time.Millisecond),
+			Settings:       map[string]string{"key": "value"},
+		},
+	}
+}
+
+type server struct {
+	pluginv1.UnimplementedPluginServer
+	pluginv1.UnimplementedInputServer
+
+	handshake func(*pluginv1.HandshakeRequest) (*pluginv1.HandshakeResponse, error)
+	gather    func() (*pluginv1.GatherResponse, error)
+	settings  map[string]string
+}
+
+func (s *server) Handshake(_ context.Context, req *pluginv1.HandshakeRequest) (*pluginv1.HandshakeResponse, error) {
+	if s.handshake != nil {
+		return s.handshake(req)
+	}
+	if req.GetPluginType() != pluginv1.PluginType_PLUGIN_TYPE_INPUT {
+		return nil, status.Error(codes.FailedPrecondition, "not an input")
+	}
+	s.settings = req.GetSettings()
+	return &pluginv1.HandshakeResponse{
+		ProtocolVersion: pluginv1.ProtocolVersion_PROTOCOL_VERSION_1,
+		Name:            "test",
+	}, nil
+}
+
+func (s *server) Gather(context.Context, *pluginv1.GatherRequest) (*pluginv1.GatherResponse, error) {
+	return s.gather()
+}
+
+// Stream sends a batch with a single metric for each credit received
+func (*server) Stream(stream grpc.BidiStreamingServer[pluginv1.StreamRequest, pluginv1.MetricBatch]) error {
+	var count int64
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		for range req.GetCredits() {
+			count++
+			batch := &pluginv1.MetricBatch{
+				Metrics: []*pluginv1.Metric{{
+					Name:   "example",
+					Fields: []*pluginv1.Field{{Key: "count", Value: &pluginv1.Field_IntValue{IntValue: count}}},
+				}},
+			}
+			if err := stream.Send(batch); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newServer(t *testing.T, srv *server) string {
+	path := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	pluginv1.RegisterPluginServer(s, srv)
+	pluginv1.RegisterInputServer(s, srv)
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	return "unix://" + path
+}
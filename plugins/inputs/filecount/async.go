@@ -0,0 +1,200 @@
+package filecount
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// scanState contains the results of the last completed scan and the partial
+// results of interrupted scans per scan root. The state is persisted across
+// restarts to report results and resume interrupted scans immediately.
+type scanState struct {
+	Results map[string][]dirResult          `json:"results"`
+	Partial map[string]map[string]*dirStats `json:"partial"`
+}
+
+// scanRoot is a base directory to walk for a configured directory pattern
+type scanRoot struct {
+	key     string
+	basedir string
+	index   int
+}
+
+// asyncScanner scans the directories in the background, decoupled from the
+// gather cycle
+type asyncScanner struct {
+	trigger chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu    sync.Mutex
+	state scanState
+}
+
+func newAsyncScanner() *asyncScanner {
+	return &asyncScanner{
+		trigger: make(chan struct{}, 1),
+		state: scanState{
+			Results: make(map[string][]dirResult),
+			Partial: make(map[string]map[string]*dirStats),
+		},
+	}
+}
+
+func (fc *FileCount) Start(acc telegraf.Accumulator) error {
+	if fc.scanner == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fc.scanner.cancel = cancel
+
+	fc.scanner.wg.Add(1)
+	go func() {
+		defer fc.scanner.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-fc.scanner.trigger:
+				fc.scanAll(ctx, acc)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (fc *FileCount) Stop() {
+	if fc.scanner == nil || fc.scanner.cancel == nil {
+		return
+	}
+	fc.scanner.cancel()
+	fc.scanner.wg.Wait()
+}
+
+func (fc *FileCount) GetState() interface{} {
+	if fc.scanner == nil {
+		return scanState{}
+	}
+
+	fc.scanner.mu.Lock()
+	defer fc.scanner.mu.Unlock()
+
+	state := scanState{
+		Results: make(map[string][]dirResult, len(fc.scanner.state.Results)),
+		Partial: make(map[string]map[string]*dirStats, len(fc.scanner.state.Partial)),
+	}
+	for k, v := range fc.scanner.state.Results {
+		state.Results[k] = v
+	}
+	for k, v := range fc.scanner.state.Partial {
+		state.Partial[k] = v
+	}
+	return state
+}
+
+func (fc *FileCount) SetState(state interface{}) error {
+	s, ok := state.(scanState)
+	if !ok {
+		return errors.New("state has to be of type 'scanState'")
+	}
+	if fc.scanner == nil {
+		return nil
+	}
+
+	fc.scanner.mu.Lock()
+	defer fc.scanner.mu.Unlock()
+
+	for k, v := range s.Results {
+		fc.scanner.state.Results[k] = v
+	}
+	for k, v := range s.Partial {
+		fc.scanner.state.Partial[k] = v
+	}
+	return nil
+}
+
+// gatherAsync reports the results of the last completed scan and triggers a
+// new scan unless a scan is still in progress
+func (fc *FileCount) gatherAsync(acc telegraf.Accumulator) {
+	select {
+	case fc.scanner.trigger <- struct{}{}:
+	default:
+		fc.Log.Debug("Previous scan still in progress")
+	}
+
+	fc.scanner.mu.Lock()
+	keys := make([]string, 0, len(fc.scanner.state.Results))
+	for k := range fc.scanner.state.Results {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	results := make([]dirResult, 0, len(keys))
+	for _, k := range keys {
+		results = append(results, fc.scanner.state.Results[k]...)
+	}
+	fc.scanner.mu.Unlock()
+
+	fc.report(acc, results)
+}
+
+// scanAll scans all roots with the configured concurrency and replaces the
+// results once all scans are completed
+func (fc *FileCount) scanAll(ctx context.Context, acc telegraf.Accumulator) {
+	roots := fc.scanRoots()
+
+	fc.scanner.mu.Lock()
+	partials := make(map[string]map[string]*dirStats, len(roots))
+	for _, root := range roots {
+		partials[root.key] = fc.scanner.state.Partial[root.key]
+	}
+	fc.scanner.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[string][]dirResult, len(roots))
+	sem := make(chan struct{}, fc.ScanConcurrency)
+	for _, root := range roots {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(root scanRoot) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			glob := fc.globPaths[root.index]
+			res, partial, err := fc.scan(ctx, acc, root.basedir, glob, partials[root.key])
+
+			fc.scanner.mu.Lock()
+			if partial != nil {
+				fc.scanner.state.Partial[root.key] = partial
+			} else {
+				delete(fc.scanner.state.Partial, root.key)
+			}
+			fc.scanner.mu.Unlock()
+
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			if err != nil {
+				acc.AddError(err)
+			}
+			mu.Lock()
+			results[root.key] = res
+			mu.Unlock()
+		}(root)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	fc.scanner.mu.Lock()
+	fc.scanner.state.Results = results
+	fc.scanner.mu.Unlock()
+}
@@ -2,11 +2,12 @@
 package filecount
 
 import (
+	"context"
 	_ "embed"
 	"errors"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/karrick/godirwalk"
@@ -21,19 +22,26 @@ import (
 var sampleConfig string
 
 type FileCount struct {
-	Directory      string          `toml:"directory" deprecated:"1.9.0;1.35.0;use 'directories' instead"`
-	Directories    []string        `toml:"directories"`
-	Name           string          `toml:"name"`
-	Recursive      bool            `toml:"recursive"`
-	RegularOnly    bool            `toml:"regular_only"`
-	FollowSymlinks bool            `toml:"follow_symlinks"`
-	Size           config.Size     `toml:"size"`
-	MTime          config.Duration `toml:"mtime"`
-	Log            telegraf.Logger `toml:"-"`
-
-	fs          fileSystem
-	fileFilters []fileFilterFunc
-	globPaths   []globpath.GlobPath
+	Directory       string            `toml:"directory" deprecated:"1.9.0;1.35.0;use 'directories' instead"`
+	Directories     []string          `toml:"directories"`
+	Name            string            `toml:"name"`
+	Recursive       bool              `toml:"recursive"`
+	RegularOnly     bool              `toml:"regular_only"`
+	FollowSymlinks  bool              `toml:"follow_symlinks"`
+	Size            config.Size       `toml:"size"`
+	MTime           config.Duration   `toml:"mtime"`
+	AgeBuckets      []config.Duration `toml:"age_buckets"`
+	SizeBuckets     []config.Size     `toml:"size_buckets"`
+	Async           bool              `toml:"async"`
+	ScanConcurrency int               `toml:"scan_concurrency"`
+	ScanRateLimit   int               `toml:"scan_rate_limit"`
+	Log             telegraf.Logger   `toml:"-"`
+
+	fs           fileSystem
+	fileFilters  []fileFilterFunc
+	globPaths    []globpath.GlobPath
+	globPatterns []string
+	scanner      *asyncScanner
 }
 
 type fileFilterFunc func(os.FileInfo) (bool, error)
@@ -42,20 +50,119 @@ func (*FileCount) SampleConfig() string {
 	return sampleConfig
 }
 
+func (fc *FileCount) Init() error {
+	for i, bound := range fc.AgeBuckets {
+		if i > 0 && bound <= fc.AgeBuckets[i-1] {
+			return errors.New("age buckets must be in strictly ascending order")
+		}
+	}
+	for i, bound := range fc.SizeBuckets {
+		if i > 0 && bound <= fc.SizeBuckets[i-1] {
+			return errors.New("size buckets must be in strictly ascending order")
+		}
+	}
+
+	if fc.ScanConcurrency < 1 {
+		fc.ScanConcurrency = 1
+	}
+	if fc.ScanRateLimit < 0 {
+		return errors.New("scan rate limit must not be negative")
+	}
+
+	// Setup the filters here as the filters are used concurrently in
+	// asynchronous mode
+	fc.initFileFilters()
+
+	if fc.Async {
+		fc.scanner = newAsyncScanner()
+	}
+
+	return nil
+}
+
 func (fc *FileCount) Gather(acc telegraf.Accumulator) error {
 	if fc.globPaths == nil {
 		fc.initGlobPaths(acc)
 	}
 
-	for _, glob := range fc.globPaths {
-		for _, dir := range fc.onlyDirectories(glob.GetRoots()) {
-			fc.count(acc, dir, glob)
+	if fc.scanner != nil {
+		fc.gatherAsync(acc)
+		return nil
+	}
+
+	for _, root := range fc.scanRoots() {
+		results, _, err := fc.scan(context.Background(), acc, root.basedir, fc.globPaths[root.index], nil)
+		fc.report(acc, results)
+		if err != nil {
+			acc.AddError(err)
 		}
 	}
 
 	return nil
 }
 
+// scanRoots returns the base directories to walk for all directory patterns
+func (fc *FileCount) scanRoots() []scanRoot {
+	var roots []scanRoot
+	for i, glob := range fc.globPaths {
+		for _, dir := range fc.onlyDirectories(glob.GetRoots()) {
+			roots = append(roots, scanRoot{
+				key:     fc.globPatterns[i] + "\x00" + dir,
+				basedir: dir,
+				index:   i,
+			})
+		}
+	}
+	return roots
+}
+
+func (fc *FileCount) report(acc telegraf.Accumulator, results []dirResult) {
+	for _, r := range results {
+		tags := map[string]string{"directory": r.Directory}
+		acc.AddGauge("filecount",
+			map[string]interface{}{
+				"count":                 r.Stats.Count,
+				"size_bytes":            r.Stats.Size,
+				"oldest_file_timestamp": r.Stats.Oldest,
+				"newest_file_timestamp": r.Stats.Newest,
+			},
+			tags,
+		)
+
+		if len(r.Stats.AgeBuckets) == len(fc.AgeBuckets)+1 {
+			bounds := make([]string, 0, len(fc.AgeBuckets))
+			for _, b := range fc.AgeBuckets {
+				bounds = append(bounds, strconv.FormatFloat(time.Duration(b).Seconds(), 'f', -1, 64))
+			}
+			addHistogram(acc, "filecount_age_bucket", r.Directory, bounds, r.Stats.AgeBuckets)
+		}
+		if len(r.Stats.SizeBuckets) == len(fc.SizeBuckets)+1 {
+			bounds := make([]string, 0, len(fc.SizeBuckets))
+			for _, b := range fc.SizeBuckets {
+				bounds = append(bounds, strconv.FormatInt(int64(b), 10))
+			}
+			addHistogram(acc, "filecount_size_bucket", r.Directory, bounds, r.Stats.SizeBuckets)
+		}
+	}
+}
+
+// addHistogram emits the cumulative histogram as one metric per bucket with
+// the upper bound in the "le" tag
+func addHistogram(acc telegraf.Accumulator, measurement, directory string, bounds []string, buckets []int64) {
+	var count int64
+	for i, v := range buckets {
+		count += v
+		le := "+Inf"
+		if i < len(bounds) {
+			le = bounds[i]
+		}
+		acc.AddGauge(measurement,
+			map[string]interface{}{"count": count},
+			map[string]string{"directory": directory, "le": le},
+		)
+	}
+}
+
 func rejectNilFilters(filters []fileFilterFunc) []fileFilterFunc {
 	filtered := make([]fileFilterFunc, 0, len(filters))
 	for _, f := range filters {
@@ -138,95 +245,6 @@ func (fc *FileCount) initFileFilters() {
 	fc.fileFilters = rejectNilFilters(filters)
 }
 
-func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpath.GlobPath) {
-	childCount := make(map[string]int64)
-	childSize := make(map[string]int64)
-	oldestFileTimestamp := make(map[string]int64)
-	newestFileTimestamp := make(map[string]int64)
-
-	walkFn := func(path string, _ *godirwalk.Dirent) error {
-		rel, err := filepath.Rel(basedir, path)
-		if err == nil && rel == "." {
-			return nil
-		}
-		file, err := fc.resolveLink(path)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
-			}
-			return err
-		}
-		match, err := fc.filter(file)
-		if err != nil {
-			acc.AddError(err)
-			return nil
-		}
-		if match {
-			parent := filepath.Dir(path)
-			childCount[parent]++
-			childSize[parent] += file.Size()
-			if oldestFileTimestamp[parent] == 0 || oldestFileTimestamp[parent] > file.ModTime().UnixNano() {
-				oldestFileTimestamp[parent] = file.ModTime().UnixNano()
-			}
-			if newestFileTimestamp[parent] == 0 || newestFileTimestamp[parent] < file.ModTime().UnixNano() {
-				newestFileTimestamp[parent] = file.ModTime().UnixNano()
-			}
-		}
-		if file.IsDir() && !fc.Recursive && !glob.HasSuperMeta {
-			return filepath.SkipDir
-		}
-		return nil
-	}
-
-	postChildrenFn := func(path string, _ *godirwalk.Dirent) error {
-		if glob.MatchString(path) {
-			gauge := map[string]interface{}{
-				"count":      childCount[path],
-				"size_bytes": childSize[path],
-			}
-			gauge["oldest_file_timestamp"] = oldestFileTimestamp[path]
-			gauge["newest_file_timestamp"] = newestFileTimestamp[path]
-			acc.AddGauge("filecount", gauge,
-				map[string]string{
-					"directory": path,
-				})
-		}
-		parent := filepath.Dir(path)
-		if fc.Recursive {
-			childCount[parent] += childCount[path]
-			childSize[parent] += childSize[path]
-			if oldestFileTimestamp[parent] == 0 || oldestFileTimestamp[parent] > oldestFileTimestamp[path] {
-				oldestFileTimestamp[parent] = oldestFileTimestamp[path]
-			}
-			if newestFileTimestamp[parent] == 0 || newestFileTimestamp[parent] < newestFileTimestamp[path] {
-				newestFileTimestamp[parent] = newestFileTimestamp[path]
-			}
-		}
-		delete(childCount, path)
-		delete(childSize, path)
-		delete(oldestFileTimestamp, path)
-		delete(newestFileTimestamp, path)
-		return nil
-	}
-
-	err := godirwalk.Walk(basedir, &godirwalk.Options{
-		Callback:             walkFn,
-		PostChildrenCallback: postChildrenFn,
-		Unsorted:             true,
-		FollowSymbolicLinks:  fc.FollowSymlinks,
-		ErrorCallback: func(_ string, err error) godirwalk.ErrorAction {
-			if errors.Is(err, fs.ErrPermission) {
-				fc.Log.Debug(err)
-				return godirwalk.SkipNode
-			}
-			return godirwalk.Halt
-		},
-	})
-	if err != nil {
-		acc.AddError(err)
-	}
-}
-
 func (fc *FileCount) filter(file os.FileInfo) (bool, error) {
 	if fc.fileFilters == nil {
 		fc.initFileFilters()
@@ -293,6 +311,7 @@ func (fc *FileCount) initGlobPaths(acc telegraf.Accumulator) {
 			acc.AddError(err)
 		} else {
 			fc.globPaths = append(fc.globPaths, *glob)
+			fc.globPatterns = append(fc.globPatterns, directory)
 		}
 	}
 }
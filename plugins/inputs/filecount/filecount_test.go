@@ -6,6 +6,7 @@
 package filecount
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -247,3 +249,211 @@ func fileCountEquals(t *testing.T, fc FileCount, expectedCount, expectedSize int
 	require.True(t, acc.HasPoint("filecount", tags, "count", int64(expectedCount)))
 	require.True(t, acc.HasPoint("filecount", tags, "size_bytes", int64(expectedSize)))
 }
+
+func TestInitInvalidBuckets(t *testing.T) {
+	fc := newFileCount()
+	fc.AgeBuckets = []config.Duration{config.Duration(time.Hour), config.Duration(time.Minute)}
+	require.ErrorContains(t, fc.Init(), "age buckets must be in strictly ascending order")
+
+	fc = newFileCount()
+	fc.SizeBuckets = []config.Size{100, 100}
+	require.ErrorContains(t, fc.Init(), "size buckets must be in strictly ascending order")
+}
+
+func TestHistograms(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"new", 10, time.Minute},
+		{"medium", 500, 2 * time.Hour},
+		{"old", 5000, 72 * time.Hour},
+	}
+	for _, f := range files {
+		fn := filepath.Join(dir, f.name)
+		require.NoError(t, os.WriteFile(fn, make([]byte, f.size), 0o600))
+		require.NoError(t, os.Chtimes(fn, now.Add(-f.age), now.Add(-f.age)))
+	}
+
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{dir}
+	fc.AgeBuckets = []config.Duration{config.Duration(time.Hour), config.Duration(48 * time.Hour)}
+	fc.SizeBuckets = []config.Size{100, 1000}
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, fc.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"filecount",
+			map[string]string{"directory": dir},
+			map[string]interface{}{
+				"count":                 int64(3),
+				"size_bytes":            int64(5510),
+				"oldest_file_timestamp": now.Add(-72 * time.Hour).UnixNano(),
+				"newest_file_timestamp": now.Add(-time.Minute).UnixNano(),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New("filecount_age_bucket", map[string]string{"directory": dir, "le": "3600"},
+			map[string]interface{}{"count": int64(1)}, time.Unix(0, 0), telegraf.Gauge),
+		metric.New("filecount_age_bucket", map[string]string{"directory": dir, "le": "172800"},
+			map[string]interface{}{"count": int64(2)}, time.Unix(0, 0), telegraf.Gauge),
+		metric.New("filecount_age_bucket", map[string]string{"directory": dir, "le": "+Inf"},
+			map[string]interface{}{"count": int64(3)}, time.Unix(0, 0), telegraf.Gauge),
+		metric.New("filecount_size_bucket", map[string]string{"directory": dir, "le": "100"},
+			map[string]interface{}{"count": int64(1)}, time.Unix(0, 0), telegraf.Gauge),
+		metric.New("filecount_size_bucket", map[string]string{"directory": dir, "le": "1000"},
+			map[string]interface{}{"count": int64(2)}, time.Unix(0, 0), telegraf.Gauge),
+		metric.New("filecount_size_bucket", map[string]string{"directory": dir, "le": "+Inf"},
+			map[string]interface{}{"count": int64(3)}, time.Unix(0, 0), telegraf.Gauge),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestScanResumesPartialState(t *testing.T) {
+	dir := t.TempDir()
+	for _, fn := range []string{"a", "done/b", "done/nested/c", "todo/d"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, fn)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fn), nil, 0o600))
+	}
+
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{dir}
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	fc.initGlobPaths(&acc)
+
+	// The "done" directory was completed in a previous, interrupted scan and
+	// must not be walked again. Use fake counts to be able to tell.
+	partial := map[string]*dirStats{
+		filepath.Join(dir, "done"): {Count: 10, Size: 100},
+	}
+	results, remaining, err := fc.scan(context.Background(), &acc, dir, fc.globPaths[0], partial)
+	require.NoError(t, err)
+	require.Nil(t, remaining)
+	require.Len(t, results, 1)
+	require.Equal(t, dir, results[0].Directory)
+	require.Equal(t, int64(12), results[0].Stats.Count)
+	require.Equal(t, int64(100), results[0].Stats.Size)
+}
+
+func TestScanSkipsDeletedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "root", "exists"), 0o750))
+
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{filepath.Join(dir, "root", "**")}
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	fc.initGlobPaths(&acc)
+
+	// Directories of the partial state deleted in the meantime must not be
+	// reported
+	partial := map[string]*dirStats{
+		filepath.Join(dir, "root", "gone"): {Count: 1},
+	}
+	results, _, err := fc.scan(context.Background(), &acc, filepath.Join(dir, "root"), fc.globPaths[0], partial)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, filepath.Join(dir, "root", "exists"), results[0].Directory)
+}
+
+func TestScanInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), nil, 0o600))
+
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{dir}
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	fc.initGlobPaths(&acc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	partial := map[string]*dirStats{filepath.Join(dir, "sub"): {Count: 1}}
+	results, remaining, err := fc.scan(ctx, &acc, dir, fc.globPaths[0], partial)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, results)
+	require.Equal(t, partial, remaining)
+}
+
+func TestAsync(t *testing.T) {
+	dir := t.TempDir()
+	for _, fn := range []string{"a", "b", "sub/c"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, fn)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fn), []byte("foo"), 0o600))
+	}
+
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{dir}
+	fc.Async = true
+	fc.ScanConcurrency = 2
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, fc.Start(&acc))
+	defer fc.Stop()
+
+	// The first gather only triggers the scan, so wait for the results
+	require.Eventually(t, func() bool {
+		require.NoError(t, fc.Gather(&acc))
+		return acc.HasMeasurement("filecount")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, acc.HasPoint("filecount", map[string]string{"directory": dir}, "count", int64(3)))
+
+	// The results of the completed scan are part of the state
+	state, ok := fc.GetState().(scanState)
+	require.True(t, ok)
+	require.Len(t, state.Results, 1)
+	require.Empty(t, state.Partial)
+}
+
+func TestAsyncRestoreState(t *testing.T) {
+	fc := newFileCount()
+	fc.Log = testutil.Logger{}
+	fc.Directories = []string{"/does/not/exist"}
+	fc.Async = true
+	require.NoError(t, fc.Init())
+
+	state := scanState{
+		Results: map[string][]dirResult{
+			"/data\x00/data": {{Directory: "/data", Stats: &dirStats{Count: 42, Size: 1024, Oldest: 1, Newest: 2}}},
+		},
+	}
+	require.NoError(t, fc.SetState(state))
+
+	// Report the restored results without waiting for the first scan
+	var acc testutil.Accumulator
+	require.NoError(t, fc.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"filecount",
+			map[string]string{"directory": "/data"},
+			map[string]interface{}{
+				"count":                 int64(42),
+				"size_bytes":            int64(1024),
+				"oldest_file_timestamp": int64(1),
+				"newest_file_timestamp": int64(2),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
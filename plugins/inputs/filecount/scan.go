@@ -0,0 +1,274 @@
+package filecount
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/karrick/godirwalk"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+)
+
+// dirStats contains the statistics of the matching files of a directory,
+// including the files of all sub-directories in recursive mode. The histogram
+// buckets contain the number of files per bucket, the last bucket holds the
+// files exceeding the largest bound.
+type dirStats struct {
+	Count       int64   `json:"count"`
+	Size        int64   `json:"size"`
+	Oldest      int64   `json:"oldest"`
+	Newest      int64   `json:"newest"`
+	AgeBuckets  []int64 `json:"age_buckets,omitempty"`
+	SizeBuckets []int64 `json:"size_buckets,omitempty"`
+}
+
+// dirResult contains the statistics of a directory matching the configured
+// directory pattern
+type dirResult struct {
+	Directory string    `json:"directory"`
+	Stats     *dirStats `json:"stats"`
+}
+
+func (fc *FileCount) newDirStats() *dirStats {
+	s := &dirStats{}
+	if len(fc.AgeBuckets) > 0 {
+		s.AgeBuckets = make([]int64, len(fc.AgeBuckets)+1)
+	}
+	if len(fc.SizeBuckets) > 0 {
+		s.SizeBuckets = make([]int64, len(fc.SizeBuckets)+1)
+	}
+	return s
+}
+
+func (fc *FileCount) addFile(s *dirStats, file os.FileInfo, now time.Time) {
+	mtime := file.ModTime().UnixNano()
+	s.Count++
+	s.Size += file.Size()
+	if s.Oldest == 0 || s.Oldest > mtime {
+		s.Oldest = mtime
+	}
+	if s.Newest == 0 || s.Newest < mtime {
+		s.Newest = mtime
+	}
+
+	if s.AgeBuckets != nil {
+		age := now.Sub(file.ModTime())
+		idx := sort.Search(len(fc.AgeBuckets), func(i int) bool { return age <= time.Duration(fc.AgeBuckets[i]) })
+		s.AgeBuckets[idx]++
+	}
+	if s.SizeBuckets != nil {
+		size := file.Size()
+		idx := sort.Search(len(fc.SizeBuckets), func(i int) bool { return size <= int64(fc.SizeBuckets[i]) })
+		s.SizeBuckets[idx]++
+	}
+}
+
+func (s *dirStats) merge(other *dirStats) {
+	s.Count += other.Count
+	s.Size += other.Size
+	if s.Oldest == 0 || (other.Oldest != 0 && s.Oldest > other.Oldest) {
+		s.Oldest = other.Oldest
+	}
+	if s.Newest == 0 || s.Newest < other.Newest {
+		s.Newest = other.Newest
+	}
+	// The number of buckets might differ for statistics restored from a
+	// previous run with different settings
+	if len(s.AgeBuckets) == len(other.AgeBuckets) {
+		for i, v := range other.AgeBuckets {
+			s.AgeBuckets[i] += v
+		}
+	}
+	if len(s.SizeBuckets) == len(other.SizeBuckets) {
+		for i, v := range other.SizeBuckets {
+			s.SizeBuckets[i] += v
+		}
+	}
+}
+
+// scan walks the given base directory and returns the statistics of all
+// directories matching the glob pattern sorted by directory. Directories
+// completed in a previous, interrupted scan are taken from the given partial
+// state instead of walking them again. If the context is cancelled the scan
+// is interrupted and the statistics of all completed directories required to
+// resume the scan are returned alongside the context error.
+func (fc *FileCount) scan(ctx context.Context, acc telegraf.Accumulator, basedir string, glob globpath.GlobPath, partial map[string]*dirStats) ([]dirResult, map[string]*dirStats, error) {
+	now := time.Now()
+	pacer := newPacer(fc.ScanRateLimit)
+
+	// Statistics of directories currently being walked
+	stats := make(map[string]*dirStats)
+	get := func(dir string) *dirStats {
+		s, found := stats[dir]
+		if !found {
+			s = fc.newDirStats()
+			stats[dir] = s
+		}
+		return s
+	}
+
+	// Statistics of completed directories either matching the pattern or
+	// being a child of a directory not yet completed. The children of
+	// completed directories are only required for resuming and thus removed
+	// once their parent is completed.
+	completed := make(map[string]*dirStats, len(partial))
+	for dir, s := range partial {
+		completed[dir] = s
+	}
+	children := make(map[string][]string)
+	current := make(map[string]bool)
+	complete := func(path string, s *dirStats) {
+		completed[path] = s
+		if glob.MatchString(path) {
+			current[path] = true
+		}
+		parent := filepath.Dir(path)
+		children[parent] = append(children[parent], path)
+		for _, child := range children[path] {
+			if !glob.MatchString(child) {
+				delete(completed, child)
+			}
+		}
+		delete(children, path)
+		if fc.Recursive {
+			get(parent).merge(s)
+		}
+	}
+
+	walkFn := func(path string, _ *godirwalk.Dirent) error {
+		if err := pacer.wait(ctx); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(basedir, path)
+		if err == nil && rel == "." {
+			return nil
+		}
+		file, err := fc.resolveLink(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		match, err := fc.filter(file)
+		if err != nil {
+			acc.AddError(err)
+			return nil
+		}
+		if match {
+			fc.addFile(get(filepath.Dir(path)), file, now)
+		}
+		if file.IsDir() {
+			if s, found := partial[path]; found {
+				// Directory was completed before the previous scan was
+				// interrupted, so account for the results of the directory
+				// and all matching sub-directories
+				current[path] = true
+				complete(path, s)
+				return filepath.SkipDir
+			}
+			if !fc.Recursive && !glob.HasSuperMeta {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	}
+
+	postChildrenFn := func(path string, _ *godirwalk.Dirent) error {
+		s, found := stats[path]
+		if !found {
+			s = fc.newDirStats()
+		}
+		delete(stats, path)
+		complete(path, s)
+		return nil
+	}
+
+	err := godirwalk.Walk(basedir, &godirwalk.Options{
+		Callback:             walkFn,
+		PostChildrenCallback: postChildrenFn,
+		Unsorted:             true,
+		FollowSymbolicLinks:  fc.FollowSymlinks,
+		ErrorCallback: func(_ string, err error) godirwalk.ErrorAction {
+			if errors.Is(err, fs.ErrPermission) {
+				fc.Log.Debug(err)
+				return godirwalk.SkipNode
+			}
+			return godirwalk.Halt
+		},
+	})
+	if ctx.Err() != nil {
+		return nil, completed, ctx.Err()
+	}
+
+	// Only report directories either completed in this scan or being part of
+	// a directory restored from the partial state to skip directories
+	// deleted in the meantime.
+	results := make([]dirResult, 0, len(current))
+	for dir, s := range completed {
+		if !glob.MatchString(dir) || !isCurrent(dir, basedir, current) {
+			continue
+		}
+		results = append(results, dirResult{Directory: dir, Stats: s})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Directory < results[j].Directory })
+
+	return results, nil, err
+}
+
+func isCurrent(dir, basedir string, current map[string]bool) bool {
+	for {
+		if current[dir] {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if dir == basedir || parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// pacer limits the number of file-system entries inspected per second
+type pacer struct {
+	limit   int
+	start   time.Time
+	entries int
+}
+
+func newPacer(limit int) *pacer {
+	return &pacer{limit: limit, start: time.Now()}
+}
+
+// wait blocks until the next entry can be inspected without exceeding the
+// limit or the context is cancelled
+func (p *pacer) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.limit <= 0 {
+		return nil
+	}
+
+	p.entries++
+	expected := p.start.Add(time.Duration(p.entries) * time.Second / time.Duration(p.limit))
+	delay := time.Until(expected)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
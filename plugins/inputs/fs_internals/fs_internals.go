@@ -0,0 +1,330 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package fs_internals
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// xfsStats maps the sections of the XFS statistics to the names of their
+// values, see https://docs.kernel.org/admin-guide/xfs.html and
+// fs/xfs/xfs_stats.h in the kernel sources. Sections not listed here, e.g.
+// the btree statistics of newer kernels, are ignored.
+var xfsStats = map[string][]string{
+	"extent_alloc": {"extents_allocated", "blocks_allocated", "extents_freed", "blocks_freed"},
+	"abt":          {"lookup", "compare", "insrec", "delrec"},
+	"blk_map":      {"read_ops", "write_ops", "unmap", "add_exlist", "del_exlist", "look_exlist", "cmp_exlist"},
+	"bmbt":         {"lookup", "compare", "insrec", "delrec"},
+	"dir":          {"lookup", "create", "remove", "getdents"},
+	"trans":        {"sync", "async", "empty"},
+	"ig":           {"attempts", "found", "frecycle", "missed", "dup", "reclaims", "attrchg"},
+	"log":          {"writes", "blocks", "noiclogs", "force", "force_sleep"},
+	"push_ail": {
+		"try_logspace", "sleep_logspace", "pushes", "success", "pushbuf",
+		"pinned", "locked", "flushing", "restarts", "flush",
+	},
+	"xstrat":   {"quick", "split"},
+	"rw":       {"write_calls", "read_calls"},
+	"attr":     {"get", "set", "remove", "list"},
+	"icluster": {"iflush_count", "flushcnt", "flushinode"},
+	"vnodes":   {"active", "alloc", "get", "hold", "rele", "reclaim", "remove", "free"},
+	"buf": {
+		"get", "create", "get_locked", "get_locked_waited", "busy_locked",
+		"miss_locked", "page_retries", "page_found", "get_read",
+	},
+	"xpc": {"xstrat_bytes", "write_bytes", "read_bytes"},
+}
+
+// ext4Stats are the counters in the sysfs directory of ext4 filesystems,
+// files missing in older kernels are skipped
+var ext4Stats = []string{
+	"delayed_allocation_blocks",
+	"lifetime_write_kbytes",
+	"session_write_kbytes",
+	"reserved_clusters",
+	"errors_count",
+	"warning_count",
+	"msg_count",
+}
+
+type FsInternals struct {
+	Filesystems   []string        `toml:"filesystems"`
+	DeviceInclude []string        `toml:"device_include"`
+	DeviceExclude []string        `toml:"device_exclude"`
+	XFSTotal      bool            `toml:"xfs_total"`
+	Log           telegraf.Logger `toml:"-"`
+
+	deviceFilter filter.Filter
+	xfs          bool
+	ext4         bool
+
+	procPath string
+	sysPath  string
+}
+
+func (*FsInternals) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FsInternals) Init() error {
+	if len(f.Filesystems) == 0 {
+		f.Filesystems = []string{"xfs", "ext4"}
+	}
+	if err := choice.CheckSlice(f.Filesystems, []string{"xfs", "ext4"}); err != nil {
+		return fmt.Errorf("invalid 'filesystems' setting: %w", err)
+	}
+	f.xfs = choice.Contains("xfs", f.Filesystems)
+	f.ext4 = choice.Contains("ext4", f.Filesystems)
+
+	var err error
+	f.deviceFilter, err = filter.NewIncludeExcludeFilter(f.DeviceInclude, f.DeviceExclude)
+	if err != nil {
+		return fmt.Errorf("creating device filter failed: %w", err)
+	}
+
+	if f.procPath == "" {
+		f.procPath = internal.GetProcPath()
+	}
+	if f.sysPath == "" {
+		f.sysPath = internal.GetSysPath()
+	}
+
+	return nil
+}
+
+func (f *FsInternals) Gather(acc telegraf.Accumulator) error {
+	if f.xfs {
+		if err := f.gatherXFS(acc); err != nil {
+			return fmt.Errorf("gathering XFS statistics failed: %w", err)
+		}
+	}
+
+	if f.ext4 {
+		if err := f.gatherExt4(acc); err != nil {
+			return fmt.Errorf("gathering ext4 statistics failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (f *FsInternals) gatherXFS(acc telegraf.Accumulator) error {
+	devices, err := f.devices(filepath.Join(f.sysPath, "fs", "xfs"))
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		// The directory also contains the global statistics and might contain
+		// directories other than filesystems
+		fn := filepath.Join(f.sysPath, "fs", "xfs", device, "stats", "stats")
+		if device == "stats" || !fileExists(fn) {
+			continue
+		}
+
+		fields, err := parseXFSStats(fn)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading statistics of %q failed: %w", device, err))
+			continue
+		}
+		acc.AddFields("xfs", fields, map[string]string{"device": device})
+	}
+
+	if f.XFSTotal {
+		fn := filepath.Join(f.procPath, "fs", "xfs", "stat")
+		if !fileExists(fn) {
+			return nil
+		}
+		fields, err := parseXFSStats(fn)
+		if err != nil {
+			return fmt.Errorf("reading total statistics failed: %w", err)
+		}
+		acc.AddFields("xfs", fields, map[string]string{"device": "total"})
+	}
+
+	return nil
+}
+
+func (f *FsInternals) gatherExt4(acc telegraf.Accumulator) error {
+	devices, err := f.devices(filepath.Join(f.sysPath, "fs", "ext4"))
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		dir := filepath.Join(f.sysPath, "fs", "ext4", device)
+		// Skip directories not belonging to a filesystem, e.g. "features"
+		if !fileExists(filepath.Join(dir, "lifetime_write_kbytes")) {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(ext4Stats))
+		for _, name := range ext4Stats {
+			buf, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				acc.AddError(fmt.Errorf("reading %q of %q failed: %w", name, device, err))
+				continue
+			}
+			v, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 64)
+			if err != nil {
+				acc.AddError(fmt.Errorf("parsing %q of %q failed: %w", name, device, err))
+				continue
+			}
+			fields[name] = v
+		}
+
+		fn := filepath.Join(f.procPath, "fs", "ext4", device, "mb_stats")
+		if fileExists(fn) {
+			if err := parseExt4MballocStats(fn, fields); err != nil {
+				acc.AddError(fmt.Errorf("reading allocator statistics of %q failed: %w", device, err))
+			}
+		}
+
+		if len(fields) > 0 {
+			acc.AddFields("ext4", fields, map[string]string{"device": device})
+		}
+	}
+
+	return nil
+}
+
+// devices returns the names of the entries in the given directory matching
+// the device filter. A missing directory means the filesystem module is not
+// loaded, so no devices are returned.
+func (f *FsInternals) devices(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	devices := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		if f.deviceFilter.Match(entry.Name()) {
+			devices = append(devices, entry.Name())
+		}
+	}
+	return devices, nil
+}
+
+// parseXFSStats parses the XFS statistics consisting of one line per section
+// starting with the section name followed by the values
+func parseXFSStats(fn string) (map[string]interface{}, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		names, found := xfsStats[parts[0]]
+		if !found {
+			continue
+		}
+		for i, value := range parts[1:] {
+			if i >= len(names) {
+				break
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing value %q of section %q failed: %w", value, parts[0], err)
+			}
+			fields[parts[0]+"_"+names[i]] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// parseExt4MballocStats adds the statistics of the multi-block allocator to
+// the given fields. The per-criteria sections are ignored and nothing is added
+// if collecting the statistics is disabled for the filesystem.
+func parseExt4MballocStats(fn string, fields map[string]interface{}) error {
+	file, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	skipIndent := -1
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimLeft(line, "\t ")
+		indent := len(line) - len(trimmed)
+		if skipIndent >= 0 {
+			if indent > skipIndent {
+				continue
+			}
+			skipIndent = -1
+		}
+
+		name, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			// Start of a section, only the values of the top-level
+			// section are reported
+			if name != "mballoc" {
+				skipIndent = indent
+			}
+			continue
+		}
+
+		// Some values are reported as "<count>/<total>"
+		value, _, _ = strings.Cut(value, "/")
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		fields["mballoc_"+strings.ReplaceAll(name, "^", "")] = v
+	}
+	return scanner.Err()
+}
+
+func fileExists(fn string) bool {
+	_, err := os.Stat(fn)
+	return err == nil
+}
+
+func init() {
+	inputs.Add("fs_internals", func() telegraf.Input {
+		return &FsInternals{}
+	})
+}
@@ -0,0 +1,35 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package fs_internals
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type FsInternals struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*FsInternals) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FsInternals) Init() error {
+	f.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*FsInternals) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("fs_internals", func() telegraf.Input {
+		return &FsInternals{}
+	})
+}
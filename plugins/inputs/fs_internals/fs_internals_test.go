@@ -0,0 +1,225 @@
+//go:build linux
+
+package fs_internals
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &FsInternals{Filesystems: []string{"btrfs"}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'filesystems' setting")
+}
+
+func TestGatherXFS(t *testing.T) {
+	plugin := &FsInternals{
+		Filesystems: []string{"xfs"},
+		XFSTotal:    true,
+		procPath:    "testdata/proc",
+		sysPath:     "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"xfs",
+			map[string]string{"device": "dm-0"},
+			map[string]interface{}{
+				"extent_alloc_extents_allocated": uint64(12),
+				"extent_alloc_blocks_allocated":  uint64(48),
+				"extent_alloc_extents_freed":     uint64(3),
+				"extent_alloc_blocks_freed":      uint64(9),
+				"dir_lookup":                     uint64(120),
+				"dir_create":                     uint64(30),
+				"dir_remove":                     uint64(10),
+				"dir_getdents":                   uint64(45),
+				"trans_sync":                     uint64(0),
+				"trans_async":                    uint64(512),
+				"trans_empty":                    uint64(0),
+				"ig_attempts":                    uint64(200),
+				"ig_found":                       uint64(150),
+				"ig_frecycle":                    uint64(0),
+				"ig_missed":                      uint64(50),
+				"ig_dup":                         uint64(0),
+				"ig_reclaims":                    uint64(40),
+				"ig_attrchg":                     uint64(2),
+				"log_writes":                     uint64(64),
+				"log_blocks":                     uint64(1024),
+				"log_noiclogs":                   uint64(0),
+				"log_force":                      uint64(80),
+				"log_force_sleep":                uint64(64),
+				"rw_write_calls":                 uint64(1500),
+				"rw_read_calls":                  uint64(3000),
+				"vnodes_active":                  uint64(60),
+				"vnodes_alloc":                   uint64(0),
+				"vnodes_get":                     uint64(0),
+				"vnodes_hold":                    uint64(0),
+				"vnodes_rele":                    uint64(40),
+				"vnodes_reclaim":                 uint64(40),
+				"vnodes_remove":                  uint64(40),
+				"vnodes_free":                    uint64(0),
+				"xpc_xstrat_bytes":               uint64(1048576),
+				"xpc_write_bytes":                uint64(4194304),
+				"xpc_read_bytes":                 uint64(2097152),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"xfs",
+			map[string]string{"device": "total"},
+			map[string]interface{}{
+				"extent_alloc_extents_allocated": uint64(4260861),
+				"extent_alloc_blocks_allocated":  uint64(125170345),
+				"extent_alloc_extents_freed":     uint64(4618729),
+				"extent_alloc_blocks_freed":      uint64(131131906),
+				"dir_lookup":                     uint64(21254027),
+				"dir_create":                     uint64(6921900),
+				"dir_remove":                     uint64(6921374),
+				"dir_getdents":                   uint64(18347567),
+				"trans_sync":                     uint64(0),
+				"trans_async":                    uint64(91010278),
+				"trans_empty":                    uint64(0),
+				"rw_write_calls":                 uint64(3217439),
+				"rw_read_calls":                  uint64(4315853),
+				"xpc_xstrat_bytes":               uint64(400772096),
+				"xpc_write_bytes":                uint64(97017407),
+				"xpc_read_bytes":                 uint64(88316386),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Only check the complete fields of the small test files
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["device"] == "sda1" {
+			require.Len(t, m.FieldList(), 79)
+			require.Equal(t, uint64(94977125), m.Fields()["push_ail_try_logspace"])
+			require.Equal(t, uint64(16619), m.Fields()["buf_get_read"])
+			require.Equal(t, uint64(4037876), m.Fields()["icluster_flushinode"])
+			continue
+		}
+		actual = append(actual, m)
+	}
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherExt4(t *testing.T) {
+	plugin := &FsInternals{
+		Filesystems: []string{"ext4"},
+		procPath:    "testdata/proc",
+		sysPath:     "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ext4",
+			map[string]string{"device": "dm-1"},
+			map[string]interface{}{
+				"delayed_allocation_blocks": uint64(0),
+				"lifetime_write_kbytes":     uint64(2048),
+				"session_write_kbytes":      uint64(512),
+				"reserved_clusters":         uint64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ext4",
+			map[string]string{"device": "sdb1"},
+			map[string]interface{}{
+				"delayed_allocation_blocks": uint64(16),
+				"lifetime_write_kbytes":     uint64(734003200),
+				"session_write_kbytes":      uint64(1048576),
+				"reserved_clusters":         uint64(32768),
+				"errors_count":              uint64(0),
+				"warning_count":             uint64(2),
+				"msg_count":                 uint64(5),
+				"mballoc_reqs":              uint64(1021),
+				"mballoc_success":           uint64(1002),
+				"mballoc_groups_scanned":    uint64(1026),
+				"mballoc_extents_scanned":   uint64(3204),
+				"mballoc_goal_hits":         uint64(822),
+				"mballoc_len_goal_hits":     uint64(0),
+				"mballoc_2n_hits":           uint64(5),
+				"mballoc_breaks":            uint64(0),
+				"mballoc_lost":              uint64(0),
+				"mballoc_buddies_generated": uint64(24),
+				"mballoc_buddies_time_used": uint64(1638392),
+				"mballoc_preallocated":      uint64(12451),
+				"mballoc_discarded":         uint64(11093),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherDeviceFilter(t *testing.T) {
+	plugin := &FsInternals{
+		DeviceInclude: []string{"sd*"},
+		procPath:      "testdata/proc",
+		sysPath:       "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		require.Contains(t, []string{"sda1", "sdb1"}, m.Tags()["device"])
+	}
+}
+
+func TestGatherNotLoaded(t *testing.T) {
+	plugin := &FsInternals{
+		procPath: t.TempDir(),
+		sysPath:  t.TempDir(),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestGatherInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fs", "xfs", "sda1", "stats"), 0750))
+	fn := filepath.Join(dir, "fs", "xfs", "sda1", "stats", "stats")
+	require.NoError(t, os.WriteFile(fn, []byte("rw 1 foo\n"), 0640))
+
+	plugin := &FsInternals{
+		Filesystems: []string{"xfs"},
+		procPath:    dir,
+		sysPath:     dir,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `parsing value "foo" of section "rw" failed`)
+}
@@ -0,0 +1,52 @@
+package http_response
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/tidwall/gjson"
+)
+
+// jsonAssertion checks the value at the given GJSON path of the response body
+type jsonAssertion struct {
+	Path  string `toml:"path"`
+	Value string `toml:"value"`
+	Match string `toml:"match"`
+
+	regex *regexp.Regexp
+}
+
+func (a *jsonAssertion) init() error {
+	if a.Path == "" {
+		return errors.New("path required")
+	}
+	if a.Value != "" && a.Match != "" {
+		return errors.New("'value' and 'match' are mutually exclusive")
+	}
+	if a.Match != "" {
+		regex, err := regexp.Compile(a.Match)
+		if err != nil {
+			return fmt.Errorf("failed to compile regular expression %q: %w", a.Match, err)
+		}
+		a.regex = regex
+	}
+	return nil
+}
+
+// check returns true if the path exists in the body and its value matches
+// the expected value or regular expression if any
+func (a *jsonAssertion) check(body []byte) bool {
+	result := gjson.GetBytes(body, a.Path)
+	if !result.Exists() {
+		return false
+	}
+
+	switch {
+	case a.regex != nil:
+		return a.regex.MatchString(result.String())
+	case a.Value != "":
+		return result.String() == a.Value
+	}
+	return true
+}
@@ -36,6 +36,8 @@ const (
 	defaultResponseBodyMaxSize = 32 * 1024 * 1024
 )
 
+var errTooManyRedirects = errors.New("too many redirects")
+
 type HTTPResponse struct {
 	Address         string              `toml:"address" deprecated:"1.12.0;1.35.0;use 'urls' instead"`
 	URLs            []string            `toml:"urls"`
@@ -47,13 +49,17 @@ type HTTPResponse struct {
 	HTTPHeaderTags  map[string]string   `toml:"http_header_tags"`
 	Headers         map[string]string   `toml:"headers"`
 	FollowRedirects bool                `toml:"follow_redirects"`
+	MaxRedirects    int                 `toml:"max_redirects"`
+	RedirectMetrics bool                `toml:"redirect_metrics"`
 	// Absolute path to file with Bearer token
-	BearerToken         string      `toml:"bearer_token"`
-	ResponseBodyField   string      `toml:"response_body_field"`
-	ResponseBodyMaxSize config.Size `toml:"response_body_max_size"`
-	ResponseStringMatch string      `toml:"response_string_match"`
-	ResponseStatusCode  int         `toml:"response_status_code"`
-	Interface           string      `toml:"interface"`
+	BearerToken            string          `toml:"bearer_token"`
+	ResponseBodyField      string          `toml:"response_body_field"`
+	ResponseBodyMaxSize    config.Size     `toml:"response_body_max_size"`
+	ResponseStringMatch    string          `toml:"response_string_match"`
+	ResponseStatusCode     int             `toml:"response_status_code"`
+	ResponseJSONAssertions []jsonAssertion `toml:"response_json_assertion"`
+	Interface              string          `toml:"interface"`
+	TimingBreakdown        bool            `toml:"timing_breakdown"`
 	// HTTP Basic Auth Credentials
 	Username config.Secret `toml:"username"`
 	Password config.Secret `toml:"password"`
@@ -90,6 +96,16 @@ func (h *HTTPResponse) Init() error {
 		}
 	}
 
+	for i := range h.ResponseJSONAssertions {
+		if err := h.ResponseJSONAssertions[i].init(); err != nil {
+			return fmt.Errorf("invalid JSON assertion %d: %w", i+1, err)
+		}
+	}
+
+	if h.MaxRedirects < 0 {
+		return errors.New("'max_redirects' must not be negative")
+	}
+
 	// Set default values
 	if h.ResponseTimeout < config.Duration(time.Second) {
 		h.ResponseTimeout = config.Duration(time.Second * 5)
@@ -134,8 +150,14 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		var fields map[string]interface{}
 		var tags map[string]string
 
+		// Only record the individual requests if necessary
+		var rec *recorder
+		if h.TimingBreakdown || h.RedirectMetrics {
+			rec = &recorder{}
+		}
+
 		// Gather data
-		fields, tags, err := h.httpGather(c)
+		fields, tags, err := h.httpGather(c, rec)
 		if err != nil {
 			acc.AddError(err)
 			continue
@@ -143,6 +165,9 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 
 		// Add metrics
 		acc.AddFields("http_response", fields, tags)
+		if h.RedirectMetrics {
+			h.addRedirectMetrics(acc, rec, c.address)
+		}
 	}
 
 	return nil
@@ -182,19 +207,29 @@ func (h *HTTPResponse) createHTTPClient(address url.URL) (*http.Client, error) {
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:             getProxyFunc(h.HTTPProxy),
-			DialContext:       dialer.DialContext,
-			DisableKeepAlives: true,
-			TLSClientConfig:   tlsCfg,
+		Transport: &tracingTransport{
+			RoundTripper: &http.Transport{
+				Proxy:             getProxyFunc(h.HTTPProxy),
+				DialContext:       dialer.DialContext,
+				DisableKeepAlives: true,
+				TLSClientConfig:   tlsCfg,
+			},
 		},
 		Timeout: time.Duration(h.ResponseTimeout),
 	}
 
-	if !h.FollowRedirects {
+	switch {
+	case !h.FollowRedirects:
 		client.CheckRedirect = func(*http.Request, []*http.Request) error {
 			return http.ErrUseLastResponse
 		}
+	case h.MaxRedirects > 0:
+		client.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+			if len(via) > h.MaxRedirects {
+				return errTooManyRedirects
+			}
+			return nil
+		}
 	}
 
 	if h.CookieAuthConfig.URL != "" {
@@ -265,6 +300,8 @@ func setResult(resultString string, fields map[string]interface{}, tags map[stri
 		"timeout":                       4,
 		"dns_error":                     5,
 		"response_status_code_mismatch": 6,
+		"response_json_mismatch":        7,
+		"too_many_redirects":            8,
 	}
 
 	tags["result"] = resultString
@@ -273,6 +310,11 @@ func setResult(resultString string, fields map[string]interface{}, tags map[stri
 }
 
 func setError(err error, fields map[string]interface{}, tags map[string]string) error {
+	if errors.Is(err, errTooManyRedirects) {
+		setResult("too_many_redirects", fields, tags)
+		return errTooManyRedirects
+	}
+
 	var timeoutError net.Error
 	if errors.As(err, &timeoutError) && timeoutError.Timeout() {
 		setResult("timeout", fields, tags)
@@ -304,7 +346,7 @@ func setError(err error, fields map[string]interface{}, tags map[string]string)
 }
 
 // HTTPGather gathers all fields and returns any errors it encounters
-func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string]string, error) {
+func (h *HTTPResponse) httpGather(cl client, rec *recorder) (map[string]interface{}, map[string]string, error) {
 	// Prepare fields and tags
 	fields := make(map[string]interface{})
 	tags := map[string]string{"server": cl.address, "method": h.Method}
@@ -351,6 +393,10 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		return nil, nil, err
 	}
 
+	if rec != nil {
+		request = withRecorder(request, rec)
+	}
+
 	// Start Timer
 	start := time.Now()
 	resp, err := cl.httpClient.Do(request)
@@ -374,6 +420,9 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	if _, ok := fields["response_time"]; !ok {
 		fields["response_time"] = responseTime
 	}
+	if h.TimingBreakdown {
+		rec.summary().addTimingFields(fields)
+	}
 
 	// This function closes the response body, as
 	// required by the net/http library
@@ -439,6 +488,22 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		}
 	}
 
+	// Check the values of the JSON response
+	if len(h.ResponseJSONAssertions) > 0 {
+		match := 1
+		for i := range h.ResponseJSONAssertions {
+			if !h.ResponseJSONAssertions[i].check(bodyBytes) {
+				match = 0
+				break
+			}
+		}
+		fields["response_json_match"] = match
+		if match == 0 {
+			success = false
+			setResult("response_json_mismatch", fields, tags)
+		}
+	}
+
 	if success {
 		setResult("success", fields, tags)
 	}
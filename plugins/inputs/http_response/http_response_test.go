@@ -1479,3 +1479,187 @@ func parseURL(t *testing.T, address string) url.URL {
 	require.NotNil(t, u)
 	return *u
 }
+
+func TestMaxRedirects(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/badredirect"},
+		ResponseTimeout: config.Duration(time.Second * 20),
+		FollowRedirects: true,
+		MaxRedirects:    3,
+		RedirectMetrics: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expectedFields := map[string]interface{}{
+		"result_type": "too_many_redirects",
+		"result_code": 8,
+	}
+	expectedTags := map[string]interface{}{
+		"server": nil,
+		"method": "GET",
+		"result": "too_many_redirects",
+	}
+	checkOutput(t, &acc, expectedFields, expectedTags, []string{"http_response_code"}, []string{"status_code"})
+
+	// The original request and the three allowed redirects should be reported
+	var hops []string
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "http_response_redirect" {
+			continue
+		}
+		hop, _ := m.GetTag("hop")
+		status, _ := m.GetTag("status_code")
+		hops = append(hops, hop+":"+status)
+		require.True(t, m.HasField("response_time"))
+	}
+	require.Equal(t, []string{"0:301", "1:301", "2:301", "3:301"}, hops)
+}
+
+func TestRedirectMetrics(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/redirect"},
+		ResponseTimeout: config.Duration(time.Second * 20),
+		FollowRedirects: true,
+		RedirectMetrics: true,
+		TimingBreakdown: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"http_response_redirect",
+			map[string]string{
+				"server":      ts.URL + "/redirect",
+				"method":      "GET",
+				"hop":         "0",
+				"url":         ts.URL + "/redirect",
+				"status_code": "301",
+			},
+			map[string]interface{}{},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http_response_redirect",
+			map[string]string{
+				"server":      ts.URL + "/redirect",
+				"method":      "GET",
+				"hop":         "1",
+				"url":         ts.URL + "/good",
+				"status_code": "200",
+			},
+			map[string]interface{}{},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	timingFields := []string{"response_time", "dns_time", "connect_time", "tls_handshake_time", "first_byte_time"}
+	for _, m := range acc.GetTelegrafMetrics() {
+		for _, field := range timingFields {
+			v, found := m.GetField(field)
+			require.Truef(t, found, "field %q missing in %q", field, m.Name())
+			require.GreaterOrEqual(t, v, float64(0))
+			m.RemoveField(field)
+		}
+		if m.Name() == "http_response_redirect" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+
+	// The connection setup and the first byte should take some time
+	connect, ok := acc.FloatField("http_response", "connect_time")
+	require.True(t, ok)
+	require.Positive(t, connect)
+}
+
+func TestResponseJSONAssertions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "up", "checks": [{"name": "db", "healthy": true}], "version": "1.2.3"}`)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		assertions []jsonAssertion
+		result     string
+		match      int
+	}{
+		{
+			name: "match",
+			assertions: []jsonAssertion{
+				{Path: "status", Value: "up"},
+				{Path: "checks.#(name==\"db\").healthy", Value: "true"},
+				{Path: "version", Match: `^1\.`},
+				{Path: "checks"},
+			},
+			result: "success",
+			match:  1,
+		},
+		{
+			name:       "value mismatch",
+			assertions: []jsonAssertion{{Path: "status", Value: "down"}},
+			result:     "response_json_mismatch",
+		},
+		{
+			name:       "regex mismatch",
+			assertions: []jsonAssertion{{Path: "version", Match: `^2\.`}},
+			result:     "response_json_mismatch",
+		},
+		{
+			name:       "missing path",
+			assertions: []jsonAssertion{{Path: "uptime"}},
+			result:     "response_json_mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:                    testutil.Logger{},
+				URLs:                   []string{ts.URL},
+				ResponseJSONAssertions: tt.assertions,
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Init())
+			require.NoError(t, h.Gather(&acc))
+
+			expectedFields := map[string]interface{}{
+				"result_type":         tt.result,
+				"response_json_match": tt.match,
+			}
+			expectedTags := map[string]interface{}{
+				"result": tt.result,
+			}
+			checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+		})
+	}
+}
+
+func TestInvalidJSONAssertions(t *testing.T) {
+	h := &HTTPResponse{ResponseJSONAssertions: []jsonAssertion{{Value: "up"}}}
+	require.ErrorContains(t, h.Init(), "invalid JSON assertion 1: path required")
+
+	h = &HTTPResponse{ResponseJSONAssertions: []jsonAssertion{{Path: "status", Value: "up", Match: "u.*"}}}
+	require.ErrorContains(t, h.Init(), "'value' and 'match' are mutually exclusive")
+
+	h = &HTTPResponse{ResponseJSONAssertions: []jsonAssertion{{Path: "status", Match: "[a-"}}}
+	require.ErrorContains(t, h.Init(), "failed to compile regular expression")
+}
@@ -0,0 +1,164 @@
+package http_response
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type recorderKey struct{}
+
+// hop contains the timing information of a single request, i.e. of the
+// original request or a request following a redirect
+type hop struct {
+	url        string
+	statusCode int
+	dns        time.Duration
+	connect    time.Duration
+	tls        time.Duration
+	firstByte  time.Duration
+	total      time.Duration
+}
+
+func (h *hop) addTimingFields(fields map[string]interface{}) {
+	fields["dns_time"] = h.dns.Seconds()
+	fields["connect_time"] = h.connect.Seconds()
+	fields["tls_handshake_time"] = h.tls.Seconds()
+	fields["first_byte_time"] = h.firstByte.Seconds()
+}
+
+// recorder collects the hops of a single gather cycle
+type recorder struct {
+	hops []*hop
+	mu   sync.Mutex
+}
+
+// summary returns the timing information summed up across all hops
+func (r *recorder) summary() *hop {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sum hop
+	for _, h := range r.hops {
+		sum.dns += h.dns
+		sum.connect += h.connect
+		sum.tls += h.tls
+		sum.firstByte += h.firstByte
+		sum.total += h.total
+	}
+	return &sum
+}
+
+// tracingTransport records the timing breakdown of each request passing the
+// transport if a recorder is attached to the request's context. As the
+// client calls the transport for each redirect, each hop is recorded
+// separately.
+type tracingTransport struct {
+	http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec, ok := req.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return t.RoundTripper.RoundTrip(req)
+	}
+
+	h := &hop{url: req.URL.String()}
+
+	// The callbacks might be called concurrently, e.g. when dialing
+	// multiple addresses of a host
+	var mu sync.Mutex
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			dnsStart = time.Now()
+			mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			h.dns = time.Since(dnsStart)
+			mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			mu.Lock()
+			connectStart = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			h.connect = time.Since(connectStart)
+			mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			tlsStart = time.Now()
+			mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			h.tls = time.Since(tlsStart)
+			mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			h.firstByte = time.Since(start)
+			mu.Unlock()
+		},
+	}
+
+	resp, err := t.RoundTripper.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+	mu.Lock()
+	h.total = time.Since(start)
+	if resp != nil {
+		h.statusCode = resp.StatusCode
+	}
+	mu.Unlock()
+
+	rec.mu.Lock()
+	rec.hops = append(rec.hops, h)
+	rec.mu.Unlock()
+
+	return resp, err
+}
+
+// withRecorder attaches the recorder to the request
+func withRecorder(req *http.Request, rec *recorder) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), recorderKey{}, rec))
+}
+
+// addRedirectMetrics emits a metric for each hop of the request
+func (h *HTTPResponse) addRedirectMetrics(acc telegraf.Accumulator, rec *recorder, server string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	for i, hp := range rec.hops {
+		tags := map[string]string{
+			"server": server,
+			"method": h.Method,
+			"hop":    strconv.Itoa(i),
+			"url":    hp.url,
+		}
+		if hp.statusCode > 0 {
+			tags["status_code"] = strconv.Itoa(hp.statusCode)
+		}
+		fields := map[string]interface{}{
+			"response_time": hp.total.Seconds(),
+		}
+		if h.TimingBreakdown {
+			hp.addTimingFields(fields)
+		}
+		acc.AddFields("http_response_redirect", fields, tags)
+	}
+}
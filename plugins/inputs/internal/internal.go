@@ -18,8 +18,9 @@ import (
 var sampleConfig string
 
 type Internal struct {
-	CollectMemstats bool `toml:"collect_memstats"`
-	CollectGostats  bool `toml:"collect_gostats"`
+	CollectMemstats   bool `toml:"collect_memstats"`
+	CollectGostats    bool `toml:"collect_gostats"`
+	CollectHistograms bool `toml:"collect_histograms"`
 }
 
 func (*Internal) SampleConfig() string {
@@ -35,6 +36,13 @@ func (s *Internal) Gather(acc telegraf.Accumulator) error {
 		acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
 	}
 
+	if s.CollectHistograms {
+		for _, m := range selfstat.Histograms() {
+			m.AddTag("version", inter.Version)
+			acc.AddHistogram(m.Name(), m.Fields(), m.Tags(), m.Time())
+		}
+	}
+
 	if s.CollectMemstats {
 		collectMemStat(acc)
 	}
@@ -67,6 +67,41 @@ func TestSelfPlugin(t *testing.T) {
 	)
 }
 
+func TestHistograms(t *testing.T) {
+	s := Internal{}
+	acc := &testutil.Accumulator{}
+
+	h := selfstat.RegisterHistogram("mytest", "test_seconds", map[string]string{"test": "foo"}, []float64{1})
+	h.Observe(0.5)
+
+	// Histograms are only collected if enabled
+	require.NoError(t, s.Gather(acc))
+	require.False(t, acc.HasField("internal_mytest", "test_seconds_count"))
+
+	s.CollectHistograms = true
+	require.NoError(t, s.Gather(acc))
+	acc.AssertContainsTaggedFields(t, "internal_mytest",
+		map[string]interface{}{
+			"test_seconds_bucket": uint64(1),
+		},
+		map[string]string{
+			"test":    "foo",
+			"le":      "1",
+			"version": "unknown",
+		},
+	)
+	acc.AssertContainsTaggedFields(t, "internal_mytest",
+		map[string]interface{}{
+			"test_seconds_count": uint64(1),
+			"test_seconds_sum":   0.5,
+		},
+		map[string]string{
+			"test":    "foo",
+			"version": "unknown",
+		},
+	)
+}
+
 func TestNoMemStat(t *testing.T) {
 	s := Internal{
 		CollectMemstats: false,
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -18,33 +21,93 @@ import (
 var sampleConfig string
 
 type Interrupts struct {
-	CPUAsTag bool `toml:"cpu_as_tag"`
+	CPUAsTag     bool `toml:"cpu_as_tag"`
+	AffinityTag  bool `toml:"affinity_tag"`
+	Distribution bool `toml:"distribution"`
+	SoftIRQRates bool `toml:"softirq_rates"`
+
+	procPath string
+	previous map[string]snapshot
 }
 
 type irq struct {
-	id     string
-	typ    string
-	device string
-	total  int64
-	cpus   []int64
+	id       string
+	typ      string
+	device   string
+	affinity string
+	total    int64
+	cpus     []int64
+}
+
+// snapshot holds the per-CPU counters of the last gather to compute rates
+// and the distribution of interrupts in the last interval
+type snapshot struct {
+	timestamp time.Time
+	cpus      map[string][]int64
 }
 
 func (*Interrupts) SampleConfig() string {
 	return sampleConfig
 }
 
+func (s *Interrupts) Init() error {
+	s.procPath = internal.GetProcPath()
+	s.previous = make(map[string]snapshot, 2)
+	return nil
+}
+
 func (s *Interrupts) Gather(acc telegraf.Accumulator) error {
-	for measurement, file := range map[string]string{"interrupts": "/proc/interrupts", "soft_interrupts": "/proc/softirqs"} {
+	now := time.Now()
+	files := map[string]string{
+		"interrupts":      filepath.Join(s.procPath, "interrupts"),
+		"soft_interrupts": filepath.Join(s.procPath, "softirqs"),
+	}
+	for measurement, file := range files {
 		irqs, err := parseFile(file)
 		if err != nil {
 			acc.AddError(err)
 			continue
 		}
+		if s.AffinityTag && measurement == "interrupts" {
+			s.addAffinity(irqs)
+		}
 		reportMetrics(measurement, irqs, acc, s.CPUAsTag)
+
+		if !s.Distribution && !s.SoftIRQRates {
+			continue
+		}
+		current := snapshot{timestamp: now, cpus: make(map[string][]int64, len(irqs))}
+		for _, irq := range irqs {
+			current.cpus[irq.id] = irq.cpus
+		}
+		if previous, found := s.previous[measurement]; found {
+			if s.Distribution && measurement == "interrupts" {
+				reportDistribution(irqs, previous, acc)
+			}
+			if s.SoftIRQRates && measurement == "soft_interrupts" {
+				reportRates(irqs, previous, now, acc)
+			}
+		}
+		s.previous[measurement] = current
 	}
 	return nil
 }
 
+// addAffinity reads the list of CPUs the numbered IRQs are bound to
+func (s *Interrupts) addAffinity(irqs []irq) {
+	for i := range irqs {
+		if _, err := strconv.ParseUint(irqs[i].id, 10, 64); err != nil {
+			continue
+		}
+		dir := filepath.Join(s.procPath, "irq", irqs[i].id)
+		if buf, err := os.ReadFile(filepath.Join(dir, "smp_affinity_list")); err == nil {
+			irqs[i].affinity = strings.TrimSpace(string(buf))
+		} else if buf, err := os.ReadFile(filepath.Join(dir, "smp_affinity")); err == nil {
+			irqs[i].affinity = strings.TrimSpace(string(buf))
+		}
+	}
+}
+
 func parseInterrupts(r io.Reader) ([]irq, error) {
 	var irqs []irq
 	var cpucount int
@@ -95,6 +158,9 @@ scan:
 
 func gatherTagsFields(irq irq) (map[string]string, map[string]interface{}) {
 	tags := map[string]string{"irq": irq.id, "type": irq.typ, "device": irq.device}
+	if irq.affinity != "" {
+		tags["affinity"] = irq.affinity
+	}
 	fields := map[string]interface{}{"total": irq.total}
 	for i := 0; i < len(irq.cpus); i++ {
 		cpu := fmt.Sprintf("CPU%d", i)
@@ -134,6 +200,82 @@ func reportMetrics(measurement string, irqs []irq, acc telegraf.Accumulator, cpu
 	}
 }
 
+// deltas returns the per-CPU increase of the counters since the previous
+// gather. Counter resets, e.g. by CPU hotplugging, are reported as not found.
+func deltas(irq irq, previous snapshot) ([]int64, bool) {
+	last, found := previous.cpus[irq.id]
+	if !found || len(last) != len(irq.cpus) {
+		return nil, false
+	}
+	result := make([]int64, len(irq.cpus))
+	for i, v := range irq.cpus {
+		if v < last[i] {
+			return nil, false
+		}
+		result[i] = v - last[i]
+	}
+	return result, true
+}
+
+// reportDistribution emits the distribution of the interrupts of each IRQ
+// across the CPUs in the last interval. The skew is the ratio between the
+// interrupts on the busiest CPU and the mean over all CPUs, i.e. one for an
+// even distribution and the number of CPUs if a single CPU handles all
+// interrupts.
+func reportDistribution(irqs []irq, previous snapshot, acc telegraf.Accumulator) {
+	for _, irq := range irqs {
+		delta, ok := deltas(irq, previous)
+		if !ok || len(delta) == 0 {
+			continue
+		}
+
+		var total, busiest int64
+		var active int
+		for _, v := range delta {
+			total += v
+			busiest = max(busiest, v)
+			if v > 0 {
+				active++
+			}
+		}
+
+		fields := map[string]interface{}{
+			"count":       total,
+			"cpus_active": active,
+			"max_share":   0.0,
+			"skew":        0.0,
+		}
+		if total > 0 {
+			fields["max_share"] = float64(busiest) / float64(total)
+			fields["skew"] = float64(busiest) * float64(len(delta)) / float64(total)
+		}
+		tags := map[string]string{"irq": irq.id, "type": irq.typ, "device": irq.device}
+		if irq.affinity != "" {
+			tags["affinity"] = irq.affinity
+		}
+		acc.AddFields("interrupts_distribution", fields, tags)
+	}
+}
+
+// reportRates emits the rate of each softirq type across all CPUs
+func reportRates(irqs []irq, previous snapshot, now time.Time, acc telegraf.Accumulator) {
+	elapsed := now.Sub(previous.timestamp).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	for _, irq := range irqs {
+		delta, ok := deltas(irq, previous)
+		if !ok {
+			continue
+		}
+		var total int64
+		for _, v := range delta {
+			total += v
+		}
+		acc.AddFields("soft_interrupts_rate", map[string]interface{}{"rate": float64(total) / elapsed}, map[string]string{"irq": irq.id})
+	}
+}
+
 func newIRQ(id string) *irq {
 	return &irq{id: id}
 }
@@ -3,10 +3,15 @@ package interrupts
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -158,3 +163,138 @@ func TestCpuAsFieldsHwIrqs(t *testing.T) {
 		expectCPUAsFields(acc, t, "interrupts", irq)
 	}
 }
+
+// =====================================================================================
+//	Affinity, distribution and rates
+// =====================================================================================
+
+func TestAffinityTag(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	interrupts := `           CPU0       CPU1
+  30:        100        300   PCI-MSI 65537-edge      virtio1-input.0
+  31:         10          0   PCI-MSI 65538-edge      virtio1-output.0
+ LOC:       1000       1000   Local timer interrupts
+`
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "interrupts"), []byte(interrupts), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "softirqs"), []byte("  CPU0 CPU1\n NET_RX: 5 6\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "irq", "30"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "irq", "30", "smp_affinity_list"), []byte("0-1\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "irq", "31"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "irq", "31", "smp_affinity"), []byte("2\n"), 0o600))
+
+	plugin := &Interrupts{AffinityTag: true}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	acc.AssertContainsTaggedFields(t, "interrupts",
+		map[string]interface{}{"CPU0": int64(100), "CPU1": int64(300), "total": int64(400)},
+		map[string]string{"irq": "30", "type": "PCI-MSI", "device": "65537-edge virtio1-input.0", "affinity": "0-1"},
+	)
+	acc.AssertContainsTaggedFields(t, "interrupts",
+		map[string]interface{}{"CPU0": int64(10), "CPU1": int64(0), "total": int64(10)},
+		map[string]string{"irq": "31", "type": "PCI-MSI", "device": "65538-edge virtio1-output.0", "affinity": "2"},
+	)
+	acc.AssertContainsTaggedFields(t, "interrupts",
+		map[string]interface{}{"CPU0": int64(1000), "CPU1": int64(1000), "total": int64(2000)},
+		map[string]string{"irq": "LOC", "type": "Local timer interrupts", "device": ""},
+	)
+	require.False(t, acc.HasMeasurement("interrupts_distribution"))
+}
+
+func TestDistribution(t *testing.T) {
+	previous := snapshot{
+		cpus: map[string][]int64{
+			"30":  {100, 100, 100, 100},
+			"31":  {10, 10, 10, 10},
+			"32":  {5, 5, 5, 5},
+			"LOC": {1000, 1000},
+		},
+	}
+	irqs := []irq{
+		{id: "30", typ: "PCI-MSI", device: "eth0-rx-0", cpus: []int64{500, 100, 100, 100}},
+		{id: "31", typ: "PCI-MSI", device: "eth0-rx-1", cpus: []int64{20, 20, 20, 20}},
+		{id: "32", typ: "PCI-MSI", device: "eth0-tx-0", cpus: []int64{5, 5, 5, 5}},
+		{id: "33", typ: "PCI-MSI", device: "new", cpus: []int64{1, 1, 1, 1}},
+		{id: "LOC", typ: "Local timer interrupts", cpus: []int64{0, 0}},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"interrupts_distribution",
+			map[string]string{"irq": "30", "type": "PCI-MSI", "device": "eth0-rx-0"},
+			map[string]interface{}{"count": int64(400), "cpus_active": 1, "max_share": 1.0, "skew": 4.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"interrupts_distribution",
+			map[string]string{"irq": "31", "type": "PCI-MSI", "device": "eth0-rx-1"},
+			map[string]interface{}{"count": int64(40), "cpus_active": 4, "max_share": 0.25, "skew": 1.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"interrupts_distribution",
+			map[string]string{"irq": "32", "type": "PCI-MSI", "device": "eth0-tx-0"},
+			map[string]interface{}{"count": int64(0), "cpus_active": 0, "max_share": 0.0, "skew": 0.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	reportDistribution(irqs, previous, &acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSoftIRQRates(t *testing.T) {
+	now := time.Now()
+	previous := snapshot{
+		timestamp: now.Add(-10 * time.Second),
+		cpus: map[string][]int64{
+			"NET_RX":  {1000, 500},
+			"TASKLET": {10, 0},
+		},
+	}
+	irqs := []irq{
+		{id: "NET_RX", cpus: []int64{1600, 900}},
+		{id: "TASKLET", cpus: []int64{20, 0}},
+		{id: "HRTIMER", cpus: []int64{5, 5}},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("soft_interrupts_rate", map[string]string{"irq": "NET_RX"}, map[string]interface{}{"rate": 100.0}, time.Unix(0, 0)),
+		metric.New("soft_interrupts_rate", map[string]string{"irq": "TASKLET"}, map[string]interface{}{"rate": 1.0}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	reportRates(irqs, previous, now, &acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherDistributionAndRates(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	write := func(interrupts, softirqs string) {
+		require.NoError(t, os.WriteFile(filepath.Join(proc, "interrupts"), []byte(interrupts), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(proc, "softirqs"), []byte(softirqs), 0o600))
+	}
+
+	plugin := &Interrupts{Distribution: true, SoftIRQRates: true}
+	require.NoError(t, plugin.Init())
+
+	// The first gather only records the counters
+	write("  CPU0 CPU1\n 30: 10 10 PCI-MSI 1-edge eth0\n", "  CPU0 CPU1\n NET_RX: 10 10\n")
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.False(t, acc.HasMeasurement("interrupts_distribution"))
+	require.False(t, acc.HasMeasurement("soft_interrupts_rate"))
+
+	write("  CPU0 CPU1\n 30: 30 10 PCI-MSI 1-edge eth0\n", "  CPU0 CPU1\n NET_RX: 20 20\n")
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	acc.AssertContainsTaggedFields(t, "interrupts_distribution",
+		map[string]interface{}{"count": int64(20), "cpus_active": 1, "max_share": 1.0, "skew": 2.0},
+		map[string]string{"irq": "30", "type": "PCI-MSI", "device": "1-edge eth0"},
+	)
+	require.True(t, acc.HasFloatField("soft_interrupts_rate", "rate"))
+}
@@ -0,0 +1,268 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jmx_bridge
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/toml"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common "github.com/influxdata/telegraf/plugins/common/jolokia2"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+//go:embed profiles/*.toml
+var bundledProfiles embed.FS
+
+type JMXBridge struct {
+	URLs            []string        `toml:"urls"`
+	Username        string          `toml:"username"`
+	Password        string          `toml:"password"`
+	Origin          string          `toml:"origin"`
+	ResponseTimeout config.Duration `toml:"response_timeout"`
+
+	Profiles     []string `toml:"profiles"`
+	ProfileFiles []string `toml:"profile_files"`
+
+	DefaultFieldPrefix    string `toml:"default_field_prefix"`
+	DefaultFieldSeparator string `toml:"default_field_separator"`
+	DefaultTagPrefix      string `toml:"default_tag_prefix"`
+
+	Metrics []metricDefinition `toml:"metric"`
+	Log     telegraf.Logger    `toml:"-"`
+	tls.ClientConfig
+
+	gatherer  *common.Gatherer
+	clients   []*common.Client
+	coercions map[string][]coercion
+}
+
+// metricDefinition is a Jolokia metric with the types to coerce the
+// resulting fields to
+type metricDefinition struct {
+	common.MetricConfig
+	FieldTypes map[string]string `toml:"field_types"`
+}
+
+// profile is the content of a file containing reusable metric definitions
+type profile struct {
+	Metrics []metricDefinition `toml:"metric"`
+}
+
+type coercion struct {
+	fields    filter.Filter
+	fieldType string
+}
+
+func (*JMXBridge) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JMXBridge) Init() error {
+	if len(j.URLs) == 0 {
+		return errors.New("no URLs configured")
+	}
+
+	// Collect the metric definitions of the profiles followed by the ones
+	// defined in the configuration
+	definitions := make([]metricDefinition, 0, len(j.Metrics))
+	seen := make(map[string]bool, len(j.Profiles))
+	for _, name := range j.Profiles {
+		if seen[name] {
+			return fmt.Errorf("duplicate profile %q", name)
+		}
+		seen[name] = true
+
+		buf, err := bundledProfiles.ReadFile("profiles/" + name + ".toml")
+		if err != nil {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		p, err := parseProfile(buf)
+		if err != nil {
+			return fmt.Errorf("parsing profile %q failed: %w", name, err)
+		}
+		definitions = append(definitions, p.Metrics...)
+	}
+	for _, fn := range j.ProfileFiles {
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			return fmt.Errorf("reading profile file failed: %w", err)
+		}
+		p, err := parseProfile(buf)
+		if err != nil {
+			return fmt.Errorf("parsing profile file %q failed: %w", fn, err)
+		}
+		definitions = append(definitions, p.Metrics...)
+	}
+	definitions = append(definitions, j.Metrics...)
+	if len(definitions) == 0 {
+		return errors.New("no metrics defined")
+	}
+
+	// Setup the metrics and field type coercions
+	metrics := make([]common.Metric, 0, len(definitions))
+	j.coercions = make(map[string][]coercion)
+	for _, d := range definitions {
+		if d.Name == "" || d.Mbean == "" {
+			return errors.New("metric definitions require 'name' and 'mbean'")
+		}
+		metrics = append(metrics, common.NewMetric(d.MetricConfig, j.DefaultFieldPrefix, j.DefaultFieldSeparator, j.DefaultTagPrefix))
+
+		// Sort the patterns to get a deterministic matching order
+		patterns := make([]string, 0, len(d.FieldTypes))
+		for pattern := range d.FieldTypes {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			fieldType := d.FieldTypes[pattern]
+			switch fieldType {
+			case "float", "integer", "unsigned", "string", "boolean":
+			default:
+				return fmt.Errorf("invalid field type %q for %q in metric %q", fieldType, pattern, d.Name)
+			}
+			f, err := filter.Compile([]string{pattern})
+			if err != nil {
+				return fmt.Errorf("invalid field pattern %q in metric %q: %w", pattern, d.Name, err)
+			}
+			j.coercions[d.Name] = append(j.coercions[d.Name], coercion{fields: f, fieldType: fieldType})
+		}
+	}
+	j.gatherer = common.NewGatherer(metrics)
+
+	j.clients = make([]*common.Client, 0, len(j.URLs))
+	for _, u := range j.URLs {
+		client, err := common.NewClient(u, &common.ClientConfig{
+			Username:        j.Username,
+			Password:        j.Password,
+			Origin:          j.Origin,
+			ResponseTimeout: time.Duration(j.ResponseTimeout),
+			ClientConfig:    j.ClientConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("creating client for %q failed: %w", u, err)
+		}
+		j.clients = append(j.clients, client)
+	}
+
+	return nil
+}
+
+func (j *JMXBridge) Gather(acc telegraf.Accumulator) error {
+	// Each client sends all metric requests in a single bulk request
+	cacc := &coercingAccumulator{Accumulator: acc, plugin: j}
+	var wg sync.WaitGroup
+	for _, client := range j.clients {
+		wg.Add(1)
+		go func(client *common.Client) {
+			defer wg.Done()
+			if err := j.gatherer.Gather(client, cacc); err != nil {
+				acc.AddError(fmt.Errorf("gathering metrics from %q failed: %w", client.URL, err))
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// coerce converts the fields of the measurement to the configured types,
+// dropping fields that cannot be converted
+func (j *JMXBridge) coerce(measurement string, fields map[string]interface{}) {
+	coercions := j.coercions[measurement]
+	if len(coercions) == 0 {
+		return
+	}
+
+	for key, value := range fields {
+		for _, c := range coercions {
+			if !c.fields.Match(key) {
+				continue
+			}
+			converted, err := convert(value, c.fieldType)
+			if err != nil {
+				j.Log.Debugf("Converting field %q of %q to %s failed: %v", key, measurement, c.fieldType, err)
+				delete(fields, key)
+			} else {
+				fields[key] = converted
+			}
+			break
+		}
+	}
+}
+
+func convert(value interface{}, fieldType string) (interface{}, error) {
+	// Jolokia reports some numbers as strings e.g. for BigDecimal values
+	if s, ok := value.(string); ok && fieldType != "string" {
+		value = strings.TrimSpace(s)
+	}
+
+	switch fieldType {
+	case "float":
+		return internal.ToFloat64(value)
+	case "integer":
+		// Numbers are decoded as float from JSON
+		if v, ok := value.(float64); ok {
+			return int64(v), nil
+		}
+		return internal.ToInt64(value)
+	case "unsigned":
+		if v, ok := value.(float64); ok {
+			if v < 0 {
+				return nil, fmt.Errorf("negative value %v", v)
+			}
+			return uint64(v), nil
+		}
+		return internal.ToUint64(value)
+	case "string":
+		return internal.ToString(value)
+	case "boolean":
+		return internal.ToBool(value)
+	}
+	return nil, fmt.Errorf("unknown type %q", fieldType)
+}
+
+func parseProfile(buf []byte) (*profile, error) {
+	var p profile
+	if err := toml.Unmarshal(buf, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// coercingAccumulator applies the field type coercions to the fields added
+type coercingAccumulator struct {
+	telegraf.Accumulator
+	plugin *JMXBridge
+}
+
+func (a *coercingAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.plugin.coerce(measurement, fields)
+	if len(fields) == 0 {
+		return
+	}
+	a.Accumulator.AddFields(measurement, fields, tags, t...)
+}
+
+func init() {
+	inputs.Add("jmx_bridge", func() telegraf.Input {
+		return &JMXBridge{
+			DefaultFieldSeparator: ".",
+			ResponseTimeout:       config.Duration(5 * time.Second),
+		}
+	})
+}
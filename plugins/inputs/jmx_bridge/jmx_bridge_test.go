@@ -0,0 +1,211 @@
+package jmx_bridge
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	common "github.com/influxdata/telegraf/plugins/common/jolokia2"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JMXBridge
+		expected string
+	}{
+		{
+			name:     "no urls",
+			plugin:   &JMXBridge{Profiles: []string{"kafka"}},
+			expected: "no URLs configured",
+		},
+		{
+			name:     "no metrics",
+			plugin:   &JMXBridge{URLs: []string{"http://localhost:8080/jolokia"}},
+			expected: "no metrics defined",
+		},
+		{
+			name: "unknown profile",
+			plugin: &JMXBridge{
+				URLs:     []string{"http://localhost:8080/jolokia"},
+				Profiles: []string{"jboss"},
+			},
+			expected: `unknown profile "jboss"`,
+		},
+		{
+			name: "duplicate profile",
+			plugin: &JMXBridge{
+				URLs:     []string{"http://localhost:8080/jolokia"},
+				Profiles: []string{"kafka", "kafka"},
+			},
+			expected: `duplicate profile "kafka"`,
+		},
+		{
+			name: "invalid type",
+			plugin: &JMXBridge{
+				URLs: []string{"http://localhost:8080/jolokia"},
+				Metrics: []metricDefinition{
+					{
+						MetricConfig: common.MetricConfig{Name: "runtime", Mbean: "java.lang:type=Runtime"},
+						FieldTypes:   map[string]string{"*": "timestamp"},
+					},
+				},
+			},
+			expected: `invalid field type "timestamp"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBundledProfiles(t *testing.T) {
+	entries, err := bundledProfiles.ReadDir("profiles")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	for _, entry := range entries {
+		name := entry.Name()[:len(entry.Name())-len(filepath.Ext(entry.Name()))]
+		t.Run(name, func(t *testing.T) {
+			plugin := &JMXBridge{
+				URLs:     []string{"http://localhost:8080/jolokia"},
+				Profiles: []string{name},
+			}
+			require.NoError(t, plugin.Init())
+			require.NotEmpty(t, plugin.coercions)
+		})
+	}
+}
+
+func TestGatherBulkRequest(t *testing.T) {
+	response := `[{
+		"request": {"mbean": "Catalina:name=*,type=ThreadPool", "attribute": ["maxThreads", "currentThreadCount", "currentThreadsBusy"], "type": "read"},
+		"value": {
+			"Catalina:name=\"http-nio-8080\",type=ThreadPool": {"maxThreads": 200, "currentThreadCount": 10, "currentThreadsBusy": "2"}
+		},
+		"status": 200
+	}, {
+		"request": {"mbean": "java.lang:type=OperatingSystem", "attribute": ["SystemCpuLoad", "Arch"], "type": "read"},
+		"value": {"SystemCpuLoad": 0.25, "Arch": "amd64"},
+		"status": 200
+	}]`
+
+	var requests int
+	var requested []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := json.Unmarshal(body, &requested); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	// Define a custom profile in a file
+	profileFile := filepath.Join(t.TempDir(), "os.toml")
+	profile := `
+[[metric]]
+  name  = "java_os"
+  mbean = "java.lang:type=OperatingSystem"
+  paths = ["SystemCpuLoad", "Arch"]
+  [metric.field_types]
+    "Arch" = "integer"
+`
+	require.NoError(t, os.WriteFile(profileFile, []byte(profile), 0600))
+
+	plugin := &JMXBridge{
+		URLs:         []string{server.URL},
+		ProfileFiles: []string{profileFile},
+		Metrics: []metricDefinition{
+			{
+				MetricConfig: common.MetricConfig{
+					Name:    "tomcat_thread_pool",
+					Mbean:   "Catalina:name=*,type=ThreadPool",
+					Paths:   []string{"maxThreads", "currentThreadCount", "currentThreadsBusy"},
+					TagKeys: []string{"name"},
+				},
+				FieldTypes: map[string]string{"*": "integer"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// All metrics are requested in a single bulk request
+	require.Equal(t, 1, requests)
+	require.Len(t, requested, 2)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"tomcat_thread_pool",
+			map[string]string{
+				"jolokia_agent_url": server.URL,
+				"name":              "http-nio-8080",
+			},
+			map[string]interface{}{
+				"maxThreads":         int64(200),
+				"currentThreadCount": int64(10),
+				"currentThreadsBusy": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		// The non-numeric field failing the conversion is dropped
+		metric.New(
+			"java_os",
+			map[string]string{"jolokia_agent_url": server.URL},
+			map[string]interface{}{"SystemCpuLoad": 0.25},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value     interface{}
+		fieldType string
+		expected  interface{}
+	}{
+		{value: 12.7, fieldType: "integer", expected: int64(12)},
+		{value: " 42 ", fieldType: "integer", expected: int64(42)},
+		{value: 42.0, fieldType: "unsigned", expected: uint64(42)},
+		{value: "1.5", fieldType: "float", expected: 1.5},
+		{value: 3.0, fieldType: "string", expected: "3"},
+		{value: "true", fieldType: "boolean", expected: true},
+	}
+
+	for _, tt := range tests {
+		actual, err := convert(tt.value, tt.fieldType)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, actual)
+	}
+
+	_, err := convert(-1.0, "unsigned")
+	require.Error(t, err)
+}
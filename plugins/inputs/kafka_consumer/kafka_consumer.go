@@ -67,6 +67,7 @@ type KafkaConsumer struct {
 	topicLock sync.Mutex
 	wg        sync.WaitGroup
 	cancel    context.CancelFunc
+	pause     internal.Gate
 }
 
 // consumerGroupHandler is a sarama.ConsumerGroupHandler implementation.
@@ -80,6 +81,7 @@ type consumerGroupHandler struct {
 	acc    telegraf.TrackingAccumulator
 	sem    semaphore
 	parser telegraf.Parser
+	pause  *internal.Gate
 	wg     sync.WaitGroup
 	cancel context.CancelFunc
 
@@ -276,6 +278,7 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			}
 			handler.msgHeadersToTags = msgHeadersMap
 			handler.timestampSource = k.TimestampSource
+			handler.pause = &k.pause
 
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
@@ -315,6 +318,16 @@ func (k *KafkaConsumer) Stop() {
 	k.wg.Wait()
 }
 
+// Pause stops consuming messages until Resume is called
+func (k *KafkaConsumer) Pause() {
+	k.pause.Close()
+}
+
+// Resume continues consuming messages after Pause
+func (k *KafkaConsumer) Resume() {
+	k.pause.Open()
+}
+
 func (k *KafkaConsumer) compileTopicRegexps() error {
 	// While we can add new topics matching extant regexps, we can't
 	// update that list on the fly.  We compile them once at startup.
@@ -450,6 +463,14 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 			return err
 		}
 
+		// Wait while the consumer is paused due to output backpressure
+		if h.pause != nil {
+			if err := h.pause.Wait(ctx); err != nil {
+				h.release()
+				return err
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			return nil
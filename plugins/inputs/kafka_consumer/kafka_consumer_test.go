@@ -385,6 +385,47 @@ func TestConsumerGroupHandlerConsumeClaim(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestConsumerGroupHandlerPause(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+
+	var pause internal.Gate
+	pause.Close()
+	cg := newConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
+	cg.pause = &pause
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	session := &FakeConsumerGroupSession{ctx: ctx}
+	claim := &FakeConsumerGroupClaim{
+		messages: make(chan *sarama.ConsumerMessage, 1),
+	}
+	require.NoError(t, cg.Setup(session))
+
+	claim.messages <- &sarama.ConsumerMessage{
+		Topic: "telegraf",
+		Value: []byte("42"),
+	}
+	go func() {
+		cg.ConsumeClaim(session, claim) //nolint:errcheck // context cancellation is expected
+	}()
+
+	// No message must be consumed while paused
+	time.Sleep(100 * time.Millisecond)
+	require.Zero(t, acc.NMetrics())
+	require.Len(t, claim.messages, 1)
+
+	pause.Open()
+	acc.Wait(1)
+	cancel()
+	require.NoError(t, cg.Cleanup(session))
+}
+
 func TestConsumerGroupHandlerHandle(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -0,0 +1,130 @@
+package ldap_sync
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/influxdata/telegraf"
+)
+
+// partner is an inbound replication partner as reported by the
+// msDS-ReplNeighbor attribute of Active Directory
+type partner struct {
+	NamingContext       string    `xml:"pszNamingContext"`
+	SourceDsaDN         string    `xml:"pszSourceDsaDN"`
+	SourceDsaAddress    string    `xml:"pszSourceDsaAddress"`
+	LastSyncSuccess     time.Time `xml:"ftimeLastSyncSuccess"`
+	LastSyncAttempt     time.Time `xml:"ftimeLastSyncAttempt"`
+	LastSyncResult      int64     `xml:"dwLastSyncResult"`
+	ConsecutiveFailures int64     `xml:"cNumConsecutiveSyncFailures"`
+}
+
+// name returns the name of the partner's domain controller derived from the
+// DN of its NTDS settings, e.g. "CN=NTDS Settings,CN=DC2,CN=Servers,..."
+func (p *partner) name() string {
+	dn, err := ldap.ParseDN(p.SourceDsaDN)
+	if err != nil || len(dn.RDNs) < 2 || len(dn.RDNs[1].Attributes) == 0 {
+		return p.SourceDsaAddress
+	}
+	return dn.RDNs[1].Attributes[0].Value
+}
+
+// queryReplicationPartners retrieves the inbound replication partners of the
+// naming context
+func queryReplicationPartners(conn *ldap.Conn, namingContext string) ([]partner, error) {
+	req := ldap.NewSearchRequest(
+		namingContext,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=*)",
+		[]string{"msDS-ReplNeighbor"},
+		nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying replication partners of %q failed: %w", namingContext, err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, fmt.Errorf("naming context %q not found", namingContext)
+	}
+
+	return parseReplicationPartners(result.Entries[0].GetAttributeValues("msDS-ReplNeighbor"))
+}
+
+// parseReplicationPartners decodes the XML representation of the
+// msDS-ReplNeighbor attribute values
+func parseReplicationPartners(values []string) ([]partner, error) {
+	partners := make([]partner, 0, len(values))
+	for _, v := range values {
+		var p partner
+		if err := xml.Unmarshal([]byte(strings.TrimSpace(v)), &p); err != nil {
+			return nil, fmt.Errorf("parsing replication partner failed: %w", err)
+		}
+		partners = append(partners, p)
+	}
+	return partners, nil
+}
+
+// addReplicationPartners reports the time since the last successful
+// replication of each inbound partner of the replicas
+func (l *LDAPSync) addReplicationPartners(acc telegraf.Accumulator, states []*state, now time.Time) {
+	for _, st := range states {
+		if st == nil {
+			continue
+		}
+
+		var maxLag time.Duration
+		var failing int
+		for _, p := range st.partners {
+			tags := map[string]string{
+				"server":         st.server.host,
+				"port":           st.server.port,
+				"naming_context": st.namingContext,
+				"partner":        p.name(),
+			}
+			fields := map[string]interface{}{
+				"last_sync_result":     p.LastSyncResult,
+				"consecutive_failures": p.ConsecutiveFailures,
+			}
+
+			// Active Directory reports 1601-01-01 for partners that never
+			// replicated successfully
+			if p.LastSyncSuccess.Year() > 1601 {
+				lag := now.Sub(p.LastSyncSuccess)
+				if lag > maxLag {
+					maxLag = lag
+				}
+				fields["lag"] = lag.Seconds()
+			}
+			if p.LastSyncAttempt.Year() > 1601 {
+				fields["last_attempt_age"] = now.Sub(p.LastSyncAttempt).Seconds()
+			}
+			if p.LastSyncResult != 0 || p.ConsecutiveFailures > 0 {
+				failing++
+			}
+			acc.AddFields("ldap_sync_partner", fields, tags, now)
+		}
+
+		tags := map[string]string{
+			"server":         st.server.host,
+			"port":           st.server.port,
+			"naming_context": st.namingContext,
+			"dialect":        l.Dialect,
+		}
+		fields := map[string]interface{}{
+			"bind_time":        st.bindTime.Seconds(),
+			"replication_lag":  maxLag.Seconds(),
+			"partners":         len(st.partners),
+			"failing_partners": failing,
+			"in_sync":          failing == 0,
+		}
+		acc.AddFields("ldap_sync", fields, tags, now)
+	}
+}
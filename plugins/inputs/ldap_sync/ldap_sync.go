@@ -0,0 +1,274 @@
+//go:generate ../../../tools/config_includer/generator
+//go:generate ../../../tools/readme_config_includer/generator
+package ldap_sync
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LDAPSync struct {
+	Servers      []string        `toml:"servers"`
+	Dialect      string          `toml:"dialect"`
+	BaseDN       string          `toml:"base_dn"`
+	BindDn       string          `toml:"bind_dn"`
+	BindPassword config.Secret   `toml:"bind_password"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	servers []*server
+	tlsCfg  *tls.Config
+}
+
+type server struct {
+	mode    string
+	address string
+	host    string
+	port    string
+}
+
+// state of a server retrieved in one gather cycle
+type state struct {
+	server        *server
+	namingContext string
+	bindTime      time.Duration
+
+	// Change sequence numbers of the naming context by server ID (OpenLDAP)
+	csns map[string]time.Time
+
+	// Inbound replication partners (Active Directory)
+	partners []partner
+}
+
+func (*LDAPSync) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *LDAPSync) Init() error {
+	if len(l.Servers) == 0 {
+		return errors.New("'servers' required")
+	}
+
+	switch l.Dialect {
+	case "":
+		l.Dialect = "openldap"
+	case "openldap", "active_directory":
+	default:
+		return fmt.Errorf("invalid dialect %q", l.Dialect)
+	}
+
+	if l.Timeout <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+
+	l.servers = make([]*server, 0, len(l.Servers))
+	for _, s := range l.Servers {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("parsing server %q failed: %w", s, err)
+		}
+
+		// Verify the server setting and set the default ports
+		switch u.Scheme {
+		case "ldap", "starttls":
+			if u.Port() == "" {
+				u.Host = u.Host + ":389"
+			}
+		case "ldaps":
+			if u.Port() == "" {
+				u.Host = u.Host + ":636"
+			}
+		default:
+			return fmt.Errorf("invalid scheme %q of server %q", u.Scheme, s)
+		}
+		l.servers = append(l.servers, &server{
+			mode:    u.Scheme,
+			address: u.Host,
+			host:    u.Hostname(),
+			port:    u.Port(),
+		})
+	}
+
+	// Setup TLS configuration used for the ldaps and starttls servers
+	tlsEnable := true
+	l.ClientConfig.Enable = &tlsEnable
+	tlsCfg, err := l.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+	l.tlsCfg = tlsCfg
+
+	return nil
+}
+
+func (l *LDAPSync) Gather(acc telegraf.Accumulator) error {
+	states := make([]*state, len(l.servers))
+	var wg sync.WaitGroup
+	for i, s := range l.servers {
+		wg.Add(1)
+		go func(idx int, s *server) {
+			defer wg.Done()
+			st, err := l.query(s)
+			if err != nil {
+				acc.AddError(fmt.Errorf("querying %q failed: %w", s.address, err))
+				return
+			}
+			states[idx] = st
+		}(i, s)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	switch l.Dialect {
+	case "openldap":
+		l.addContextCSN(acc, states, now)
+	case "active_directory":
+		l.addReplicationPartners(acc, states, now)
+	}
+
+	return nil
+}
+
+// query connects to the server measuring the bind time and retrieves the
+// replication state of the naming context
+func (l *LDAPSync) query(s *server) (*state, error) {
+	conn, err := l.connect(s)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	st := &state{server: s}
+
+	start := time.Now()
+	if err := l.bind(conn); err != nil {
+		return nil, err
+	}
+	st.bindTime = time.Since(start)
+
+	st.namingContext = l.BaseDN
+	if st.namingContext == "" {
+		if st.namingContext, err = defaultNamingContext(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	switch l.Dialect {
+	case "openldap":
+		st.csns, err = queryContextCSN(conn, st.namingContext)
+	case "active_directory":
+		st.partners, err = queryReplicationPartners(conn, st.namingContext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+func (l *LDAPSync) connect(s *server) (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: time.Duration(l.Timeout)}
+
+	var conn *ldap.Conn
+	var err error
+	switch s.mode {
+	case "ldap":
+		conn, err = ldap.DialURL("ldap://"+s.address, ldap.DialWithDialer(dialer))
+	case "ldaps":
+		conn, err = ldap.DialURL("ldaps://"+s.address, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(l.tlsCfg))
+	case "starttls":
+		conn, err = ldap.DialURL("ldap://"+s.address, ldap.DialWithDialer(dialer))
+		if err == nil {
+			if err := conn.StartTLS(l.tlsCfg); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(time.Duration(l.Timeout))
+
+	return conn, nil
+}
+
+// bind authenticates with the configured credentials or performs an anonymous
+// bind to measure the bind time in any case
+func (l *LDAPSync) bind(conn *ldap.Conn) error {
+	if l.BindDn == "" && l.BindPassword.Empty() {
+		if err := conn.UnauthenticatedBind(""); err != nil {
+			return fmt.Errorf("anonymous bind failed: %w", err)
+		}
+		return nil
+	}
+
+	passwd, err := l.BindPassword.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer passwd.Destroy()
+
+	if err := conn.Bind(l.BindDn, passwd.String()); err != nil {
+		return fmt.Errorf("binding credentials failed: %w", err)
+	}
+	return nil
+}
+
+// defaultNamingContext returns the default naming context of Active Directory
+// servers or the first naming context of other servers from the root DSE
+func defaultNamingContext(conn *ldap.Conn) (string, error) {
+	req := ldap.NewSearchRequest(
+		"",
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=*)",
+		[]string{"defaultNamingContext", "namingContexts"},
+		nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return "", fmt.Errorf("querying root DSE failed: %w", err)
+	}
+	if len(result.Entries) == 0 {
+		return "", errors.New("no root DSE found")
+	}
+
+	entry := result.Entries[0]
+	if nc := entry.GetAttributeValue("defaultNamingContext"); nc != "" {
+		return nc, nil
+	}
+	if nc := entry.GetAttributeValue("namingContexts"); nc != "" {
+		return nc, nil
+	}
+	return "", errors.New("no naming context found, please specify 'base_dn'")
+}
+
+func init() {
+	inputs.Add("ldap_sync", func() telegraf.Input {
+		return &LDAPSync{
+			Dialect: "openldap",
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
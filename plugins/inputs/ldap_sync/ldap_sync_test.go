@@ -0,0 +1,226 @@
+package ldap_sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *LDAPSync
+		expected string
+	}{
+		{
+			name:     "no servers",
+			plugin:   &LDAPSync{Timeout: config.Duration(time.Second)},
+			expected: "'servers' required",
+		},
+		{
+			name: "invalid dialect",
+			plugin: &LDAPSync{
+				Servers: []string{"ldap://localhost"},
+				Dialect: "389ds",
+				Timeout: config.Duration(time.Second),
+			},
+			expected: `invalid dialect "389ds"`,
+		},
+		{
+			name: "invalid scheme",
+			plugin: &LDAPSync{
+				Servers: []string{"http://localhost"},
+				Timeout: config.Duration(time.Second),
+			},
+			expected: `invalid scheme "http"`,
+		},
+		{
+			name:     "invalid timeout",
+			plugin:   &LDAPSync{Servers: []string{"ldap://localhost"}},
+			expected: "'timeout' must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDefaultPorts(t *testing.T) {
+	plugin := &LDAPSync{
+		Servers: []string{"ldap://a", "starttls://b", "ldaps://c", "ldap://d:1389"},
+		Timeout: config.Duration(time.Second),
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "openldap", plugin.Dialect)
+
+	addresses := make([]string, 0, len(plugin.servers))
+	for _, s := range plugin.servers {
+		addresses = append(addresses, s.address)
+	}
+	require.Equal(t, []string{"a:389", "b:389", "c:636", "d:1389"}, addresses)
+}
+
+func TestParseContextCSN(t *testing.T) {
+	csns, err := parseContextCSN([]string{
+		"20240115102030.123456Z#000000#001#000000",
+		"20240115102000.000000Z#000000#002#000000",
+		"20240115101000.000000Z#000003#001#000000",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Time{
+		"001": time.Date(2024, 1, 15, 10, 20, 30, 123456000, time.UTC),
+		"002": time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC),
+	}, csns)
+
+	_, err = parseContextCSN([]string{"20240115102030Z#001"})
+	require.ErrorContains(t, err, "invalid CSN")
+}
+
+func TestContextCSNLag(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 21, 0, 0, time.UTC)
+	base := time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC)
+
+	plugin := &LDAPSync{Dialect: "openldap"}
+	states := []*state{
+		{
+			server:        &server{host: "ldap1", port: "389"},
+			namingContext: "dc=example,dc=com",
+			bindTime:      2 * time.Millisecond,
+			csns: map[string]time.Time{
+				"001": base.Add(30 * time.Second),
+				"002": base,
+			},
+		},
+		{
+			server:        &server{host: "ldap2", port: "389"},
+			namingContext: "dc=example,dc=com",
+			bindTime:      4 * time.Millisecond,
+			csns: map[string]time.Time{
+				"001": base.Add(20 * time.Second),
+			},
+		},
+		nil, // failed server
+	}
+
+	var acc testutil.Accumulator
+	plugin.addContextCSN(&acc, states, now)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ldap_sync_csn",
+			map[string]string{"server": "ldap1", "port": "389", "naming_context": "dc=example,dc=com", "sid": "001"},
+			map[string]interface{}{"lag": float64(0), "change_age": float64(30)},
+			now,
+		),
+		metric.New(
+			"ldap_sync_csn",
+			map[string]string{"server": "ldap1", "port": "389", "naming_context": "dc=example,dc=com", "sid": "002"},
+			map[string]interface{}{"lag": float64(0), "change_age": float64(60)},
+			now,
+		),
+		metric.New(
+			"ldap_sync",
+			map[string]string{"server": "ldap1", "port": "389", "naming_context": "dc=example,dc=com", "dialect": "openldap"},
+			map[string]interface{}{"bind_time": 0.002, "replication_lag": float64(0), "missing_sids": 0, "in_sync": true},
+			now,
+		),
+		metric.New(
+			"ldap_sync_csn",
+			map[string]string{"server": "ldap2", "port": "389", "naming_context": "dc=example,dc=com", "sid": "001"},
+			map[string]interface{}{"lag": float64(10), "change_age": float64(40)},
+			now,
+		),
+		metric.New(
+			"ldap_sync",
+			map[string]string{"server": "ldap2", "port": "389", "naming_context": "dc=example,dc=com", "dialect": "openldap"},
+			map[string]interface{}{"bind_time": 0.004, "replication_lag": float64(10), "missing_sids": 1, "in_sync": false},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestReplicationPartners(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	partners, err := parseReplicationPartners([]string{
+		`<DS_REPL_NEIGHBOR>
+	<pszNamingContext>DC=example,DC=com</pszNamingContext>
+	<pszSourceDsaDN>CN=NTDS Settings,CN=DC2,CN=Servers,CN=Default-First-Site-Name,CN=Sites,CN=Configuration,DC=example,DC=com</pszSourceDsaDN>
+	<pszSourceDsaAddress>0c1f4e6a-0000-0000-0000-000000000000._msdcs.example.com</pszSourceDsaAddress>
+	<dwLastSyncResult>0</dwLastSyncResult>
+	<cNumConsecutiveSyncFailures>0</cNumConsecutiveSyncFailures>
+	<ftimeLastSyncSuccess>2024-01-15T10:25:00Z</ftimeLastSyncSuccess>
+	<ftimeLastSyncAttempt>2024-01-15T10:25:00Z</ftimeLastSyncAttempt>
+</DS_REPL_NEIGHBOR>`,
+		`<DS_REPL_NEIGHBOR>
+	<pszNamingContext>DC=example,DC=com</pszNamingContext>
+	<pszSourceDsaDN>CN=NTDS Settings,CN=DC3,CN=Servers,CN=Default-First-Site-Name,CN=Sites,CN=Configuration,DC=example,DC=com</pszSourceDsaDN>
+	<dwLastSyncResult>1722</dwLastSyncResult>
+	<cNumConsecutiveSyncFailures>3</cNumConsecutiveSyncFailures>
+	<ftimeLastSyncSuccess>1601-01-01T00:00:00Z</ftimeLastSyncSuccess>
+	<ftimeLastSyncAttempt>2024-01-15T10:29:00Z</ftimeLastSyncAttempt>
+</DS_REPL_NEIGHBOR>`,
+	})
+	require.NoError(t, err)
+	require.Len(t, partners, 2)
+
+	plugin := &LDAPSync{Dialect: "active_directory"}
+	states := []*state{
+		{
+			server:        &server{host: "dc1", port: "389"},
+			namingContext: "DC=example,DC=com",
+			bindTime:      time.Millisecond,
+			partners:      partners,
+		},
+	}
+
+	var acc testutil.Accumulator
+	plugin.addReplicationPartners(&acc, states, now)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ldap_sync_partner",
+			map[string]string{"server": "dc1", "port": "389", "naming_context": "DC=example,DC=com", "partner": "DC2"},
+			map[string]interface{}{
+				"lag":                  float64(300),
+				"last_attempt_age":     float64(300),
+				"last_sync_result":     int64(0),
+				"consecutive_failures": int64(0),
+			},
+			now,
+		),
+		metric.New(
+			"ldap_sync_partner",
+			map[string]string{"server": "dc1", "port": "389", "naming_context": "DC=example,DC=com", "partner": "DC3"},
+			map[string]interface{}{
+				"last_attempt_age":     float64(60),
+				"last_sync_result":     int64(1722),
+				"consecutive_failures": int64(3),
+			},
+			now,
+		),
+		metric.New(
+			"ldap_sync",
+			map[string]string{"server": "dc1", "port": "389", "naming_context": "DC=example,DC=com", "dialect": "active_directory"},
+			map[string]interface{}{
+				"bind_time":        0.001,
+				"replication_lag":  float64(300),
+				"partners":         2,
+				"failing_partners": 1,
+				"in_sync":          false,
+			},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
@@ -0,0 +1,136 @@
+package ldap_sync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Layout of the timestamp part of a change sequence number (CSN), e.g.
+// "20240115102030.123456Z#000000#001#000000"
+const csnTimeLayout = "20060102150405.999999Z"
+
+// queryContextCSN retrieves the contextCSN values of the naming context
+func queryContextCSN(conn *ldap.Conn, namingContext string) (map[string]time.Time, error) {
+	req := ldap.NewSearchRequest(
+		namingContext,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=*)",
+		[]string{"contextCSN"},
+		nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying contextCSN of %q failed: %w", namingContext, err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, fmt.Errorf("naming context %q not found", namingContext)
+	}
+
+	return parseContextCSN(result.Entries[0].GetAttributeValues("contextCSN"))
+}
+
+// parseContextCSN converts the given contextCSN values to the timestamp of
+// the latest change per server ID
+func parseContextCSN(values []string) (map[string]time.Time, error) {
+	csns := make(map[string]time.Time, len(values))
+	for _, v := range values {
+		sid, ts, err := parseCSN(v)
+		if err != nil {
+			return nil, err
+		}
+		if prev, found := csns[sid]; !found || ts.After(prev) {
+			csns[sid] = ts
+		}
+	}
+	return csns, nil
+}
+
+// parseCSN splits a change sequence number into the server ID and the time
+// of the change
+func parseCSN(csn string) (string, time.Time, error) {
+	parts := strings.Split(csn, "#")
+	if len(parts) != 4 {
+		return "", time.Time{}, fmt.Errorf("invalid CSN %q", csn)
+	}
+	ts, err := time.Parse(csnTimeLayout, parts[0])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("parsing time of CSN %q failed: %w", csn, err)
+	}
+	if parts[2] == "" {
+		return "", time.Time{}, fmt.Errorf("empty server ID in CSN %q", csn)
+	}
+	return parts[2], ts, nil
+}
+
+// addContextCSN compares the contextCSN of all replicas and reports the lag
+// of each replica behind the most recent change per server ID
+func (l *LDAPSync) addContextCSN(acc telegraf.Accumulator, states []*state, now time.Time) {
+	// Determine the most recent change of each server ID across all replicas
+	latest := make(map[string]time.Time)
+	for _, st := range states {
+		if st == nil {
+			continue
+		}
+		for sid, ts := range st.csns {
+			if ts.After(latest[sid]) {
+				latest[sid] = ts
+			}
+		}
+	}
+
+	for _, st := range states {
+		if st == nil {
+			continue
+		}
+
+		var maxLag time.Duration
+		var missing int
+		for sid, newest := range latest {
+			ts, found := st.csns[sid]
+			if !found {
+				// The replica did not receive any change of this server yet
+				missing++
+				continue
+			}
+			lag := newest.Sub(ts)
+			if lag > maxLag {
+				maxLag = lag
+			}
+
+			tags := map[string]string{
+				"server":         st.server.host,
+				"port":           st.server.port,
+				"naming_context": st.namingContext,
+				"sid":            sid,
+			}
+			fields := map[string]interface{}{
+				"lag":        lag.Seconds(),
+				"change_age": now.Sub(ts).Seconds(),
+			}
+			acc.AddFields("ldap_sync_csn", fields, tags, now)
+		}
+
+		tags := map[string]string{
+			"server":         st.server.host,
+			"port":           st.server.port,
+			"naming_context": st.namingContext,
+			"dialect":        l.Dialect,
+		}
+		fields := map[string]interface{}{
+			"bind_time":       st.bindTime.Seconds(),
+			"replication_lag": maxLag.Seconds(),
+			"missing_sids":    missing,
+			"in_sync":         maxLag == 0 && missing == 0,
+		}
+		acc.AddFields("ldap_sync", fields, tags, now)
+	}
+}
@@ -0,0 +1,254 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package login_events
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Tag value reported for users or sources exceeding the configured limits
+const overflowValue = "_overflow"
+
+type LoginEvents struct {
+	Source     string          `toml:"source"`
+	WtmpFile   string          `toml:"wtmp_file"`
+	BtmpFile   string          `toml:"btmp_file"`
+	MaxUsers   int             `toml:"max_users"`
+	MaxSources int             `toml:"max_sources"`
+	Log        telegraf.Logger `toml:"-"`
+
+	wtmp   *utmpReader
+	btmp   *utmpReader
+	logind *logindWatcher
+
+	sync.Mutex
+	sessions map[string]session
+	counts   map[eventKey]int64
+	users    map[string]bool
+	sources  map[string]bool
+}
+
+// session contains the user and origin of a login session
+type session struct {
+	user   string
+	source string
+}
+
+type eventKey struct {
+	event  string
+	user   string
+	source string
+}
+
+func (*LoginEvents) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *LoginEvents) Init() error {
+	switch l.Source {
+	case "":
+		l.Source = "utmp"
+	case "utmp", "logind":
+	default:
+		return fmt.Errorf("invalid source %q", l.Source)
+	}
+
+	if l.MaxUsers < 1 {
+		l.MaxUsers = 100
+	}
+	if l.MaxSources < 1 {
+		l.MaxSources = 100
+	}
+
+	l.sessions = make(map[string]session)
+	l.counts = make(map[eventKey]int64)
+	l.users = make(map[string]bool)
+	l.sources = make(map[string]bool)
+
+	return nil
+}
+
+func (l *LoginEvents) Start(telegraf.Accumulator) error {
+	switch l.Source {
+	case "utmp":
+		if l.WtmpFile != "" {
+			reader, err := newUtmpReader(l.WtmpFile)
+			if err != nil {
+				return fmt.Errorf("opening wtmp file failed: %w", err)
+			}
+			// Replay the history to know the sessions open before starting
+			if err := reader.read(func(r *utmpRecord) { l.handleWtmp(r, false) }); err != nil {
+				reader.close()
+				return fmt.Errorf("reading wtmp file failed: %w", err)
+			}
+			l.wtmp = reader
+		}
+		if l.BtmpFile != "" {
+			reader, err := newUtmpReader(l.BtmpFile)
+			if err != nil {
+				l.Stop()
+				return fmt.Errorf("opening btmp file failed: %w", err)
+			}
+			reader.skip()
+			l.btmp = reader
+		}
+	case "logind":
+		watcher, err := newLogindWatcher()
+		if err != nil {
+			return fmt.Errorf("connecting to logind failed: %w", err)
+		}
+		if err := watcher.run(l.sessionOpened, l.sessionClosed, l.Log); err != nil {
+			watcher.stop()
+			return fmt.Errorf("subscribing to logind sessions failed: %w", err)
+		}
+		l.logind = watcher
+	}
+	return nil
+}
+
+func (l *LoginEvents) Gather(acc telegraf.Accumulator) error {
+	if l.wtmp != nil {
+		if err := l.wtmp.read(func(r *utmpRecord) { l.handleWtmp(r, true) }); err != nil {
+			acc.AddError(fmt.Errorf("reading wtmp file failed: %w", err))
+		}
+	}
+	if l.btmp != nil {
+		if err := l.btmp.read(l.handleBtmp); err != nil {
+			acc.AddError(fmt.Errorf("reading btmp file failed: %w", err))
+		}
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	for key, count := range l.counts {
+		tags := map[string]string{
+			"event":  key.event,
+			"user":   key.user,
+			"source": key.source,
+		}
+		acc.AddFields("login_events", map[string]interface{}{"count": count}, tags)
+	}
+
+	// Report the counts per interval
+	l.counts = make(map[eventKey]int64)
+	l.users = make(map[string]bool)
+	l.sources = make(map[string]bool)
+
+	return nil
+}
+
+func (l *LoginEvents) Stop() {
+	if l.logind != nil {
+		l.logind.stop()
+		l.logind = nil
+	}
+	if l.wtmp != nil {
+		l.wtmp.close()
+		l.wtmp = nil
+	}
+	if l.btmp != nil {
+		l.btmp.close()
+		l.btmp = nil
+	}
+}
+
+// handleWtmp tracks the sessions using the login and logout records of the
+// wtmp file, logouts only refer to the terminal line of the session
+func (l *LoginEvents) handleWtmp(r *utmpRecord, count bool) {
+	switch r.kind {
+	case utmpUserProcess:
+		if r.user != "" {
+			l.sessionOpened(r.line, session{user: r.user, source: sourceOf(r.host)}, count)
+		}
+	case utmpDeadProcess:
+		l.sessionClosed(r.line, count)
+	}
+}
+
+// handleBtmp counts the failed authentications recorded in the btmp file
+func (l *LoginEvents) handleBtmp(r *utmpRecord) {
+	if r.user == "" {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	l.add("failed", session{user: r.user, source: sourceOf(r.host)})
+}
+
+func (l *LoginEvents) sessionOpened(id string, s session, count bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.sessions[id] = s
+	if count {
+		l.add("login", s)
+	}
+}
+
+func (l *LoginEvents) sessionClosed(id string, count bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	s, found := l.sessions[id]
+	if !found {
+		return
+	}
+	delete(l.sessions, id)
+	if count {
+		l.add("logout", s)
+	}
+}
+
+func (l *LoginEvents) add(event string, s session) {
+	key := eventKey{
+		event:  event,
+		user:   limit(l.users, s.user, l.MaxUsers),
+		source: limit(l.sources, s.source, l.MaxSources),
+	}
+	l.counts[key]++
+}
+
+// limit returns the value if it was seen before or the number of distinct
+// values is below the limit and the overflow value otherwise
+func limit(seen map[string]bool, value string, maximum int) string {
+	if !seen[value] {
+		if len(seen) >= maximum {
+			return overflowValue
+		}
+		seen[value] = true
+	}
+	return value
+}
+
+// sourceOf returns the remote host of a session or "local" for sessions on a
+// local terminal or X display
+func sourceOf(host string) string {
+	if host == "" || strings.HasPrefix(host, ":") {
+		return "local"
+	}
+	return host
+}
+
+func init() {
+	inputs.Add("login_events", func() telegraf.Input {
+		return &LoginEvents{
+			Source:     "utmp",
+			WtmpFile:   "/var/log/wtmp",
+			BtmpFile:   "/var/log/btmp",
+			MaxUsers:   100,
+			MaxSources: 100,
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package login_events
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LoginEvents struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*LoginEvents) SampleConfig() string { return sampleConfig }
+
+func (l *LoginEvents) Init() error {
+	l.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*LoginEvents) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("login_events", func() telegraf.Input {
+		return &LoginEvents{}
+	})
+}
@@ -0,0 +1,167 @@
+//go:build linux
+
+package login_events
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &LoginEvents{Source: "journald"}
+	require.ErrorContains(t, plugin.Init(), `invalid source "journald"`)
+}
+
+func TestParseUtmpRecord(t *testing.T) {
+	buf := utmpEntry(utmpUserProcess, "pts/0", "alice", "192.0.2.10")
+	require.Len(t, buf, utmpRecordSize)
+
+	expected := &utmpRecord{kind: utmpUserProcess, line: "pts/0", user: "alice", host: "192.0.2.10"}
+	require.Equal(t, expected, parseUtmpRecord(buf))
+}
+
+func TestSourceOf(t *testing.T) {
+	require.Equal(t, "local", sourceOf(""))
+	require.Equal(t, "local", sourceOf(":0"))
+	require.Equal(t, "192.0.2.10", sourceOf("192.0.2.10"))
+}
+
+func TestUtmpSource(t *testing.T) {
+	dir := t.TempDir()
+	wtmp := filepath.Join(dir, "wtmp")
+	btmp := filepath.Join(dir, "btmp")
+
+	// History of an open session and a failed authentication
+	require.NoError(t, os.WriteFile(wtmp, utmpEntry(utmpUserProcess, "pts/0", "alice", "192.0.2.10"), 0600))
+	require.NoError(t, os.WriteFile(btmp, utmpEntry(6, "ssh:notty", "admin", "203.0.113.5"), 0600))
+
+	plugin := &LoginEvents{
+		WtmpFile: wtmp,
+		BtmpFile: btmp,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The history is not reported
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	appendFile(t, wtmp,
+		utmpEntry(utmpDeadProcess, "pts/0", "", ""),
+		utmpEntry(utmpUserProcess, "tty1", "bob", ""),
+		utmpEntry(utmpDeadProcess, "pts/7", "", ""), // unknown session
+		utmpEntry(2, "~", "reboot", "6.1.0"),        // boot record
+	)
+	appendFile(t, btmp,
+		utmpEntry(6, "ssh:notty", "root", "203.0.113.5"),
+		utmpEntry(6, "ssh:notty", "root", "203.0.113.5"),
+		utmpEntry(6, "ssh:notty", "oracle", "198.51.100.7"),
+		utmpEntry(6, "ssh:notty", "root", "203.0.113.5")[:100], // incomplete record
+	)
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		loginMetric("logout", "alice", "192.0.2.10", 1),
+		loginMetric("login", "bob", "local", 1),
+		loginMetric("failed", "root", "203.0.113.5", 2),
+		loginMetric("failed", "oracle", "198.51.100.7", 1),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Complete the record and rotate the btmp file afterwards
+	appendFile(t, btmp, utmpEntry(6, "ssh:notty", "root", "203.0.113.5")[100:])
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{loginMetric("failed", "root", "203.0.113.5", 1)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	require.NoError(t, os.Rename(btmp, btmp+".1"))
+	require.NoError(t, os.WriteFile(btmp, utmpEntry(6, "ssh:notty", "guest", "198.51.100.7"), 0600))
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	expected = []telegraf.Metric{loginMetric("failed", "guest", "198.51.100.7", 1)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestCardinalityLimits(t *testing.T) {
+	plugin := &LoginEvents{
+		MaxUsers:   2,
+		MaxSources: 1,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.sessionOpened("1", session{user: "alice", source: "local"}, true)
+	plugin.sessionOpened("2", session{user: "bob", source: "192.0.2.10"}, true)
+	plugin.sessionOpened("3", session{user: "carol", source: "local"}, true)
+	plugin.sessionClosed("1", true)
+	plugin.sessionClosed("3", true)
+	plugin.sessionClosed("4", true)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		loginMetric("login", "alice", "local", 1),
+		loginMetric("login", "bob", "_overflow", 1),
+		loginMetric("login", "_overflow", "local", 1),
+		loginMetric("logout", "alice", "local", 1),
+		loginMetric("logout", "_overflow", "local", 1),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The limits apply per interval
+	plugin.sessionClosed("2", true)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{loginMetric("logout", "bob", "192.0.2.10", 1)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func loginMetric(event, user, source string, count int64) telegraf.Metric {
+	return metric.New(
+		"login_events",
+		map[string]string{"event": event, "user": user, "source": source},
+		map[string]interface{}{"count": count},
+		time.Unix(0, 0),
+	)
+}
+
+func utmpEntry(kind int16, line, user, host string) []byte {
+	buf := make([]byte, utmpRecordSize)
+	binary.NativeEndian.PutUint16(buf[0:2], uint16(kind))
+	copy(buf[utmpLineOffset:utmpLineOffset+utmpLineSize], line)
+	copy(buf[utmpUserOffset:utmpUserOffset+utmpUserSize], user)
+	copy(buf[utmpHostOffset:utmpHostOffset+utmpHostSize], host)
+	return buf
+}
+
+func appendFile(t *testing.T, filename string, records ...[]byte) {
+	t.Helper()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, record := range records {
+		_, err := f.Write(record)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,109 @@
+//go:build linux
+
+package login_events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/login1"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	logindSessionNew     = "org.freedesktop.login1.Manager.SessionNew"
+	logindSessionRemoved = "org.freedesktop.login1.Manager.SessionRemoved"
+	logindTimeout        = 5 * time.Second
+)
+
+// logindWatcher tracks the user sessions via the signals of systemd-logind
+type logindWatcher struct {
+	conn *login1.Conn
+	wg   sync.WaitGroup
+}
+
+func newLogindWatcher() (*logindWatcher, error) {
+	conn, err := login1.New()
+	if err != nil {
+		return nil, err
+	}
+	return &logindWatcher{conn: conn}, nil
+}
+
+func (w *logindWatcher) run(opened func(string, session, bool), closed func(string, bool), log telegraf.Logger) error {
+	// Subscribe before listing the sessions to not miss any change
+	signals := w.conn.Subscribe("SessionNew", "SessionRemoved")
+
+	current, err := w.conn.ListSessions()
+	if err != nil {
+		return err
+	}
+	for _, s := range current {
+		if info, ok := w.lookup(s.ID, log); ok {
+			opened(s.ID, info, false)
+		}
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		// The channel is closed when closing the connection
+		for signal := range signals {
+			if len(signal.Body) < 1 {
+				continue
+			}
+			id, ok := signal.Body[0].(string)
+			if !ok {
+				continue
+			}
+
+			switch signal.Name {
+			case logindSessionNew:
+				if info, ok := w.lookup(id, log); ok {
+					opened(id, info, true)
+				}
+			case logindSessionRemoved:
+				closed(id, true)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// lookup returns the user and remote host of the given session, sessions
+// not belonging to a user such as greeters are skipped
+func (w *logindWatcher) lookup(id string, log telegraf.Logger) (session, bool) {
+	path, err := w.conn.GetSession(id)
+	if err != nil {
+		log.Debugf("Getting session %q failed: %v", id, err)
+		return session{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logindTimeout)
+	defer cancel()
+	props, err := w.conn.GetSessionPropertiesContext(ctx, path)
+	if err != nil {
+		log.Debugf("Getting properties of session %q failed: %v", id, err)
+		return session{}, false
+	}
+
+	if class, _ := props["Class"].Value().(string); class != "user" {
+		return session{}, false
+	}
+	user, _ := props["Name"].Value().(string)
+	if user == "" {
+		return session{}, false
+	}
+	host, _ := props["RemoteHost"].Value().(string)
+
+	return session{user: user, source: sourceOf(host)}, true
+}
+
+func (w *logindWatcher) stop() {
+	w.conn.Close()
+	w.wg.Wait()
+}
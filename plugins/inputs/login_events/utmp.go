@@ -0,0 +1,128 @@
+//go:build linux
+
+package login_events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// Layout of the utmp records written by glibc, see utmp(5)
+const (
+	utmpRecordSize = 384
+	utmpLineOffset = 8
+	utmpLineSize   = 32
+	utmpUserOffset = 44
+	utmpUserSize   = 32
+	utmpHostOffset = 76
+	utmpHostSize   = 256
+)
+
+// Record types of interest
+const (
+	utmpUserProcess = 7
+	utmpDeadProcess = 8
+)
+
+type utmpRecord struct {
+	kind int16
+	line string
+	user string
+	host string
+}
+
+func parseUtmpRecord(buf []byte) *utmpRecord {
+	return &utmpRecord{
+		kind: int16(binary.NativeEndian.Uint16(buf[0:2])),
+		line: cstring(buf[utmpLineOffset : utmpLineOffset+utmpLineSize]),
+		user: cstring(buf[utmpUserOffset : utmpUserOffset+utmpUserSize]),
+		host: cstring(buf[utmpHostOffset : utmpHostOffset+utmpHostSize]),
+	}
+}
+
+// cstring returns the content of the NUL padded field
+func cstring(buf []byte) string {
+	if idx := bytes.IndexByte(buf, 0); idx >= 0 {
+		buf = buf[:idx]
+	}
+	return string(buf)
+}
+
+// utmpReader reads the records appended to a wtmp or btmp file and handles
+// rotation of the file
+type utmpReader struct {
+	path   string
+	file   *os.File
+	info   os.FileInfo
+	offset int64
+}
+
+// newUtmpReader opens the given file starting at the first record
+func newUtmpReader(path string) (*utmpReader, error) {
+	r := &utmpReader{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *utmpReader) open() error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.close()
+	r.file, r.info, r.offset = file, info, 0
+	return nil
+}
+
+// skip continues reading after the last complete record of the file
+func (r *utmpReader) skip() {
+	r.offset = r.info.Size() - r.info.Size()%utmpRecordSize
+}
+
+func (r *utmpReader) read(handle func(*utmpRecord)) error {
+	// Start from the beginning of a new file if the file was rotated
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(info, r.info) || info.Size() < r.offset {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.file.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(r.file)
+	buf := make([]byte, utmpRecordSize)
+	for {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			// Incomplete records are read again with the next call
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		r.offset += utmpRecordSize
+		handle(parseUtmpRecord(buf))
+	}
+}
+
+func (r *utmpReader) close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
@@ -22,6 +22,7 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -45,7 +46,11 @@ var (
 )
 
 type Mdstat struct {
-	FileName string `toml:"file_name"`
+	FileName      string `toml:"file_name"`
+	DeviceDetails bool   `toml:"device_details"`
+
+	// path of the sys filesystem, set in tests
+	sysPath string
 }
 
 type statusLine struct {
@@ -261,12 +266,69 @@ func (k *Mdstat) Gather(acc telegraf.Accumulator) error {
 			"ActivityState": state,
 			"Devices":       evalComponentDevices(deviceFields),
 		}
+		if k.DeviceDetails {
+			k.gatherDeviceDetails(acc, mdName, fields)
+		}
 		acc.AddFields("mdstat", fields, tags)
 	}
 
 	return nil
 }
 
+// gatherDeviceDetails adds the mismatch count and sync action of the array to
+// the given fields and reports the error counters and states of the component
+// devices as read from sysfs. Missing files, e.g. for inactive arrays, are
+// skipped.
+func (k *Mdstat) gatherDeviceDetails(acc telegraf.Accumulator, mdName string, fields map[string]interface{}) {
+	sysPath := k.sysPath
+	if sysPath == "" {
+		sysPath = internal.GetSysPath()
+	}
+	mdPath := filepath.Join(sysPath, "block", mdName, "md")
+
+	if v, ok := readSysfsValue(filepath.Join(mdPath, "mismatch_cnt")); ok {
+		if count, err := strconv.ParseInt(v, 10, 64); err == nil {
+			fields["MismatchCount"] = count
+		}
+	}
+	if v, ok := readSysfsValue(filepath.Join(mdPath, "sync_action")); ok {
+		fields["SyncAction"] = v
+	}
+
+	devices, err := filepath.Glob(filepath.Join(mdPath, "dev-*"))
+	if err != nil {
+		return
+	}
+	for _, devicePath := range devices {
+		deviceFields := make(map[string]interface{}, 2)
+		if v, ok := readSysfsValue(filepath.Join(devicePath, "errors")); ok {
+			if count, err := strconv.ParseInt(v, 10, 64); err == nil {
+				deviceFields["Errors"] = count
+			}
+		}
+		if v, ok := readSysfsValue(filepath.Join(devicePath, "state")); ok {
+			deviceFields["State"] = v
+		}
+		if len(deviceFields) == 0 {
+			continue
+		}
+
+		tags := map[string]string{
+			"Name":   mdName,
+			"Device": strings.TrimPrefix(filepath.Base(devicePath), "dev-"),
+		}
+		acc.AddFields("mdstat_device", deviceFields, tags)
+	}
+}
+
+func readSysfsValue(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
 func (k *Mdstat) getProcMdstat() ([]byte, error) {
 	var mdStatFile string
 	if k.FileName == "" {
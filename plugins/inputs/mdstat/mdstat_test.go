@@ -4,10 +4,14 @@ package mdstat
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -114,6 +118,78 @@ func TestInvalidMdStatProcFile1(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestDeviceDetails(t *testing.T) {
+	filename := makeFakeMDStatFile([]byte(mdStatFileFailedDisk))
+	defer os.Remove(filename)
+
+	sysPath := t.TempDir()
+	mdPath := filepath.Join(sysPath, "block", "md0", "md")
+	files := map[string]string{
+		"mismatch_cnt":     "128",
+		"sync_action":      "idle",
+		"dev-sda1/errors":  "0",
+		"dev-sda1/state":   "in_sync",
+		"dev-sdb1/errors":  "17",
+		"dev-sdb1/state":   "in_sync,write_error",
+		"dev-sdd1/errors":  "523",
+		"dev-sdd1/state":   "faulty",
+		"dev-sdd1/unknown": "ignored",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(mdPath, name)), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(mdPath, name), []byte(content+"\n"), 0640))
+	}
+
+	k := Mdstat{
+		FileName:      filename,
+		DeviceDetails: true,
+		sysPath:       sysPath,
+	}
+	acc := testutil.Accumulator{}
+	require.NoError(t, k.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mdstat_device",
+			map[string]string{"Name": "md0", "Device": "sda1"},
+			map[string]interface{}{"Errors": int64(0), "State": "in_sync"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mdstat_device",
+			map[string]string{"Name": "md0", "Device": "sdb1"},
+			map[string]interface{}{"Errors": int64(17), "State": "in_sync,write_error"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mdstat_device",
+			map[string]string{"Name": "md0", "Device": "sdd1"},
+			map[string]interface{}{"Errors": int64(523), "State": "faulty"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mdstat",
+			map[string]string{"Name": "md0", "ActivityState": "active", "Devices": "sda1,sdb1,sdd1"},
+			map[string]interface{}{
+				"BlocksSynced":           int64(5860144128),
+				"BlocksSyncedFinishTime": float64(0),
+				"BlocksSyncedPct":        float64(0),
+				"BlocksSyncedSpeed":      float64(0),
+				"BlocksTotal":            int64(5860144128),
+				"DisksActive":            int64(3),
+				"DisksFailed":            int64(0),
+				"DisksSpare":             int64(0),
+				"DisksTotal":             int64(4),
+				"DisksDown":              int64(1),
+				"MismatchCount":          int64(128),
+				"SyncAction":             "idle",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 const mdStatFileFull = `
 Personalities : [raid1] [raid10] [linear] [multipath] [raid0] [raid6] [raid5] [raid4]
 md2 : active raid10 sde[2] sdl[9] sdf[3] sdk[8] sdh[5] sdd[1] sdg[4] sdn[11] sdm[10] sdj[7] sdc[0] sdi[6]
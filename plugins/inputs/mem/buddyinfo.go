@@ -0,0 +1,67 @@
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// gatherBuddyinfo collects the free blocks per order of each memory zone and
+// NUMA node from /proc/buddyinfo. The fragmentation index of an order is the
+// fraction of free memory not usable for allocations of that order, i.e.
+// located in smaller blocks.
+func (ms *Mem) gatherBuddyinfo(acc telegraf.Accumulator) error {
+	buf, err := os.ReadFile(filepath.Join(ms.procPath, "buddyinfo"))
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		// Format: Node 0, zone   Normal   3412   2114   1230 ...
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 5 || parts[0] != "Node" || parts[2] != "zone" {
+			continue
+		}
+
+		blocks := make([]uint64, 0, len(parts)-4)
+		for _, v := range parts[4:] {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing free blocks %q failed: %w", v, err)
+			}
+			blocks = append(blocks, n)
+		}
+
+		var free uint64
+		for order, n := range blocks {
+			free += n << order
+		}
+
+		fields := map[string]interface{}{
+			"free_pages": free,
+		}
+		var usable uint64
+		for order := len(blocks) - 1; order >= 0; order-- {
+			usable += blocks[order] << order
+			fields["free_blocks_order_"+strconv.Itoa(order)] = blocks[order]
+			if order > 0 && free > 0 {
+				fields["fragmentation_index_order_"+strconv.Itoa(order)] = float64(free-usable) / float64(free)
+			}
+		}
+
+		tags := map[string]string{
+			"node": strings.TrimSuffix(parts[1], ","),
+			"zone": parts[3],
+		}
+		acc.AddGauge("mem_buddyinfo", fields, tags)
+	}
+
+	return scanner.Err()
+}
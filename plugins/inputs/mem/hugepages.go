@@ -0,0 +1,84 @@
+package mem
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// gatherHugepages collects the usage of the huge page pools of all supported
+// page sizes, e.g. 2M and 1G, from /sys/kernel/mm/hugepages
+func (ms *Mem) gatherHugepages(acc telegraf.Accumulator) error {
+	dir := filepath.Join(ms.sysPath, "kernel", "mm", "hugepages")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// Pools are named after their page size, e.g. "hugepages-2048kB"
+		name := entry.Name()
+		size, found := strings.CutPrefix(name, "hugepages-")
+		if !found {
+			continue
+		}
+		sizeKB, err := strconv.ParseUint(strings.TrimSuffix(size, "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		values := make(map[string]uint64, 5)
+		for _, file := range []string{"nr_hugepages", "free_hugepages", "resv_hugepages", "surplus_hugepages", "nr_overcommit_hugepages"} {
+			v, err := readUint(filepath.Join(dir, name, file))
+			if err != nil {
+				return err
+			}
+			values[file] = v
+		}
+
+		total, free := values["nr_hugepages"], values["free_hugepages"]
+		fields := map[string]interface{}{
+			"total":      total,
+			"free":       free,
+			"used":       total - free,
+			"reserved":   values["resv_hugepages"],
+			"surplus":    values["surplus_hugepages"],
+			"overcommit": values["nr_overcommit_hugepages"],
+		}
+		if total > 0 {
+			fields["used_percent"] = 100 * float64(total-free) / float64(total)
+		}
+		acc.AddGauge("mem_hugepages", fields, map[string]string{"size": formatPageSize(sizeKB)})
+	}
+
+	return nil
+}
+
+// formatPageSize returns the given size in kB using the largest unit dividing
+// the size, e.g. "2M" for 2048kB
+func formatPageSize(kb uint64) string {
+	switch {
+	case kb%(1024*1024) == 0:
+		return strconv.FormatUint(kb/(1024*1024), 10) + "G"
+	case kb%1024 == 0:
+		return strconv.FormatUint(kb/1024, 10) + "M"
+	}
+	return strconv.FormatUint(kb, 10) + "k"
+}
+
+func readUint(path string) (uint64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(string(bytes.TrimSpace(buf)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %q failed: %w", path, err)
+	}
+	return v, nil
+}
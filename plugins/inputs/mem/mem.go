@@ -5,8 +5,10 @@ import (
 	_ "embed"
 	"fmt"
 	"runtime"
+	"slices"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/psutil"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -15,8 +17,13 @@ import (
 var sampleConfig string
 
 type Mem struct {
+	Collect []string        `toml:"collect"`
+	Log     telegraf.Logger `toml:"-"`
+
 	ps       psutil.PS
 	platform string
+	procPath string
+	sysPath  string
 }
 
 func (*Mem) SampleConfig() string {
@@ -25,6 +32,19 @@ func (*Mem) SampleConfig() string {
 
 func (ms *Mem) Init() error {
 	ms.platform = runtime.GOOS
+
+	for _, c := range ms.Collect {
+		switch c {
+		case "buddyinfo", "hugepages":
+		default:
+			return fmt.Errorf("invalid 'collect' option %q", c)
+		}
+	}
+	if len(ms.Collect) > 0 && ms.platform != "linux" {
+		ms.Log.Warn("Additional 'collect' options are only supported on Linux, ignoring them")
+		ms.Collect = nil
+	}
+
 	return nil
 }
 
@@ -96,12 +116,27 @@ func (ms *Mem) Gather(acc telegraf.Accumulator) error {
 
 	acc.AddGauge("mem", fields, nil)
 
+	if slices.Contains(ms.Collect, "buddyinfo") {
+		if err := ms.gatherBuddyinfo(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering buddyinfo failed: %w", err))
+		}
+	}
+	if slices.Contains(ms.Collect, "hugepages") {
+		if err := ms.gatherHugepages(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering hugepages failed: %w", err))
+		}
+	}
+
 	return nil
 }
 
 func init() {
 	ps := psutil.NewSystemPS()
 	inputs.Add("mem", func() telegraf.Input {
-		return &Mem{ps: ps}
+		return &Mem{
+			ps:       ps,
+			procPath: internal.GetProcPath(),
+			sysPath:  internal.GetSysPath(),
+		}
 	})
 }
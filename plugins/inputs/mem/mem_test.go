@@ -1,9 +1,12 @@
 package mem
 
 import (
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/stretchr/testify/require"
 
@@ -115,3 +118,117 @@ func TestMemStats(t *testing.T) {
 
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
+
+func TestMemStatsCollect(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Additional statistics are only supported on Linux")
+	}
+
+	var mps psutil.MockPS
+	defer mps.AssertExpectations(t)
+	mps.On("VMStat").Return(&mem.VirtualMemoryStat{Total: 100, Available: 50, Used: 50}, nil)
+
+	plugin := &Mem{
+		Collect:  []string{"buddyinfo", "hugepages"},
+		Log:      testutil.Logger{},
+		ps:       &mps,
+		procPath: filepath.Join("testdata", "proc"),
+		sysPath:  filepath.Join("testdata", "sys"),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"mem_buddyinfo",
+			map[string]string{"node": "0", "zone": "DMA"},
+			map[string]interface{}{
+				"free_pages":                   uint64(3840),
+				"free_blocks_order_0":          uint64(0),
+				"free_blocks_order_1":          uint64(0),
+				"free_blocks_order_2":          uint64(0),
+				"free_blocks_order_3":          uint64(0),
+				"free_blocks_order_4":          uint64(0),
+				"free_blocks_order_5":          uint64(0),
+				"free_blocks_order_6":          uint64(0),
+				"free_blocks_order_7":          uint64(0),
+				"free_blocks_order_8":          uint64(1),
+				"free_blocks_order_9":          uint64(1),
+				"free_blocks_order_10":         uint64(3),
+				"fragmentation_index_order_1":  0.0,
+				"fragmentation_index_order_2":  0.0,
+				"fragmentation_index_order_3":  0.0,
+				"fragmentation_index_order_4":  0.0,
+				"fragmentation_index_order_5":  0.0,
+				"fragmentation_index_order_6":  0.0,
+				"fragmentation_index_order_7":  0.0,
+				"fragmentation_index_order_8":  0.0,
+				"fragmentation_index_order_9":  0.0667,
+				"fragmentation_index_order_10": 0.2,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"mem_buddyinfo",
+			map[string]string{"node": "1", "zone": "Normal"},
+			map[string]interface{}{
+				"free_pages":           uint64(0),
+				"free_blocks_order_0":  uint64(0),
+				"free_blocks_order_1":  uint64(0),
+				"free_blocks_order_2":  uint64(0),
+				"free_blocks_order_3":  uint64(0),
+				"free_blocks_order_4":  uint64(0),
+				"free_blocks_order_5":  uint64(0),
+				"free_blocks_order_6":  uint64(0),
+				"free_blocks_order_7":  uint64(0),
+				"free_blocks_order_8":  uint64(0),
+				"free_blocks_order_9":  uint64(0),
+				"free_blocks_order_10": uint64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"mem_hugepages",
+			map[string]string{"size": "1G"},
+			map[string]interface{}{
+				"total":      uint64(0),
+				"free":       uint64(0),
+				"used":       uint64(0),
+				"reserved":   uint64(0),
+				"surplus":    uint64(0),
+				"overcommit": uint64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"mem_hugepages",
+			map[string]string{"size": "2M"},
+			map[string]interface{}{
+				"total":        uint64(512),
+				"free":         uint64(128),
+				"used":         uint64(384),
+				"used_percent": 75.0,
+				"reserved":     uint64(16),
+				"surplus":      uint64(0),
+				"overcommit":   uint64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	// Only check the additional metrics, the memory statistics are covered above
+	actual := acc.GetTelegrafMetrics()[1:]
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), cmpopts.EquateApprox(0.001, 0))
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &Mem{Collect: []string{"zswap"}, Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' option "zswap"`)
+}
@@ -61,6 +61,7 @@ type MQTTConsumer struct {
 	topicParsers  []*topicParser
 	ctx           context.Context
 	cancel        context.CancelFunc
+	pause         internal.Gate
 	payloadSize   selfstat.Stat
 	messagesRecv  selfstat.Stat
 	wg            sync.WaitGroup
@@ -169,6 +170,17 @@ func (m *MQTTConsumer) Stop() {
 	}
 }
 
+// Pause stops processing messages until Resume is called. Incoming messages
+// are held back by the client and the broker in the meantime.
+func (m *MQTTConsumer) Pause() {
+	m.pause.Close()
+}
+
+// Resume continues processing messages after Pause
+func (m *MQTTConsumer) Resume() {
+	m.pause.Open()
+}
+
 func (m *MQTTConsumer) connect() error {
 	m.client = m.clientFactory(m.opts)
 	// AddRoute sets up the function for handling messages.  These need to be
@@ -244,6 +256,12 @@ func (m *MQTTConsumer) onDelivered(track telegraf.DeliveryInfo) {
 }
 
 func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
+	// Wait while the consumer is paused due to output backpressure, messages
+	// received during shutdown are dropped unacknowledged
+	if err := m.pause.Wait(m.ctx); err != nil {
+		return
+	}
+
 	m.sem <- empty{}
 
 	payloadBytes := len(msg.Payload())
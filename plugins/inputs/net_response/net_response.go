@@ -3,17 +3,20 @@ package net_response
 
 import (
 	"bufio"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"net"
 	"net/textproto"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/common/probing"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -31,12 +34,19 @@ const (
 )
 
 type NetResponse struct {
-	Address     string          `toml:"address"`
-	Timeout     config.Duration `toml:"timeout"`
-	ReadTimeout config.Duration `toml:"read_timeout"`
-	Send        string          `toml:"send"`
-	Expect      string          `toml:"expect"`
-	Protocol    string          `toml:"protocol"`
+	Address       string          `toml:"address"`
+	Timeout       config.Duration `toml:"timeout"`
+	ReadTimeout   config.Duration `toml:"read_timeout"`
+	Send          string          `toml:"send"`
+	Expect        string          `toml:"expect"`
+	Protocol      string          `toml:"protocol"`
+	ProbeInterval config.Duration `toml:"probe_interval"`
+
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	window     probing.Window
+	mu         sync.Mutex
+	lastResult resultType
 }
 
 func (*NetResponse) SampleConfig() string {
@@ -77,7 +87,45 @@ func (n *NetResponse) Init() error {
 	return nil
 }
 
+func (n *NetResponse) Start(acc telegraf.Accumulator) error {
+	if n.ProbeInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(n.ProbeInterval))
+		defer ticker.Stop()
+		for {
+			n.probe(acc)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (n *NetResponse) Stop() {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	n.wg.Wait()
+}
+
 func (n *NetResponse) Gather(acc telegraf.Accumulator) error {
+	if n.ProbeInterval > 0 {
+		return n.gatherProbes(acc)
+	}
+
 	// Prepare host and port
 	host, port, err := net.SplitHostPort(n.Address)
 	if err != nil {
@@ -85,24 +133,12 @@ func (n *NetResponse) Gather(acc telegraf.Accumulator) error {
 	}
 
 	// Prepare data
-	tags := map[string]string{"server": host, "port": port}
-	var fields map[string]interface{}
-	var returnTags map[string]string
+	tags := map[string]string{"server": host, "port": port, "protocol": n.Protocol}
 
 	// Gather data
-	switch n.Protocol {
-	case "tcp":
-		returnTags, fields, err = n.tcpGather()
-		if err != nil {
-			return err
-		}
-		tags["protocol"] = "tcp"
-	case "udp":
-		returnTags, fields, err = n.udpGather()
-		if err != nil {
-			return err
-		}
-		tags["protocol"] = "udp"
+	returnTags, fields, err := n.check()
+	if err != nil {
+		return err
 	}
 
 	// Merge the tags
@@ -114,6 +150,84 @@ func (n *NetResponse) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func (n *NetResponse) check() (map[string]string, map[string]interface{}, error) {
+	if n.Protocol == "udp" {
+		return n.udpGather()
+	}
+	return n.tcpGather()
+}
+
+// probe checks the server once and reports a change of the server's state
+// immediately
+func (n *NetResponse) probe(acc telegraf.Accumulator) {
+	result := connectionFailed
+	var rtts []time.Duration
+	if _, fields, err := n.check(); err == nil {
+		result = resultType(fields["result_code"].(uint64))
+		if result == success {
+			rtts = append(rtts, time.Duration(fields["response_time"].(float64)*float64(time.Second)))
+		}
+	}
+
+	n.mu.Lock()
+	n.lastResult = result
+	n.mu.Unlock()
+
+	var changed bool
+	if result == success {
+		changed = n.window.Add(1, 1, rtts)
+	} else {
+		changed = n.window.Add(1, 0, nil)
+	}
+
+	if changed {
+		host, port, err := net.SplitHostPort(n.Address)
+		if err != nil {
+			acc.AddError(err)
+			return
+		}
+		tags := map[string]string{"server": host, "port": port, "protocol": n.Protocol, "result": result.String()}
+		acc.AddFields("net_response_state", map[string]interface{}{"up": result == success}, tags)
+	}
+}
+
+// gatherProbes reports the aggregated results of the probes since the last
+// gather
+func (n *NetResponse) gatherProbes(acc telegraf.Accumulator) error {
+	s, ok := n.window.Flush()
+	if !ok {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(n.Address)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{"server": host, "port": port, "protocol": n.Protocol}
+	fields := map[string]interface{}{
+		"probes":           s.Sent,
+		"probes_succeeded": s.Received,
+		"percent_failed":   s.LossPercent,
+		"state_changes":    s.StateChanges,
+	}
+	if s.Received > 0 {
+		fields["response_time"] = s.AvgRTT.Seconds()
+		fields["response_time_min"] = s.MinRTT.Seconds()
+		fields["response_time_max"] = s.MaxRTT.Seconds()
+		fields["jitter"] = s.Jitter.Seconds()
+	}
+
+	// Report the result of the most recent probe
+	n.mu.Lock()
+	result := n.lastResult
+	n.mu.Unlock()
+	tags["result"] = result.String()
+	fields["result_code"] = uint64(result)
+
+	acc.AddFields("net_response", fields, tags)
+	return nil
+}
+
 func (n *NetResponse) tcpGather() (map[string]string, map[string]interface{}, error) {
 	// Prepare returns
 	tags := make(map[string]string)
@@ -233,20 +347,24 @@ func (n *NetResponse) udpGather() (map[string]string, map[string]interface{}, er
 	return tags, fields, nil
 }
 
-func setResult(result resultType, fields map[string]interface{}, tags map[string]string, expect string) {
-	var tag string
-	switch result {
+func (r resultType) String() string {
+	switch r {
 	case success:
-		tag = "success"
+		return "success"
 	case timeout:
-		tag = "timeout"
+		return "timeout"
 	case connectionFailed:
-		tag = "connection_failed"
+		return "connection_failed"
 	case readFailed:
-		tag = "read_failed"
+		return "read_failed"
 	case stringMismatch:
-		tag = "string_mismatch"
+		return "string_mismatch"
 	}
+	return ""
+}
+
+func setResult(result resultType, fields map[string]interface{}, tags map[string]string, expect string) {
+	tag := result.String()
 
 	tags["result"] = tag
 	fields["result_code"] = uint64(result)
@@ -339,3 +339,64 @@ func tcpServer(t *testing.T, wg *sync.WaitGroup) {
 		return
 	}
 }
+
+func TestProbeInterval(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	c := NetResponse{
+		Protocol:      "tcp",
+		Address:       listener.Addr().String(),
+		ProbeInterval: config.Duration(time.Hour),
+	}
+	require.NoError(t, c.Init())
+
+	// Starting probes the server immediately
+	var acc testutil.Accumulator
+	require.NoError(t, c.Start(&acc))
+	c.Stop()
+	c.probe(&acc)
+
+	// Simulate an outage of the server
+	require.NoError(t, listener.Close())
+	c.probe(&acc)
+	require.NoError(t, c.Gather(&acc))
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	tags := map[string]string{
+		"server":   "127.0.0.1",
+		"port":     port,
+		"protocol": "tcp",
+		"result":   "connection_failed",
+	}
+	acc.AssertContainsTaggedFields(t, "net_response_state", map[string]interface{}{"up": false}, tags)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	m := metrics[1]
+	require.Equal(t, "net_response", m.Name())
+	require.Equal(t, tags, m.Tags())
+	fields := m.Fields()
+	require.Equal(t, int64(3), fields["probes"])
+	require.Equal(t, int64(2), fields["probes_succeeded"])
+	require.InDelta(t, float64(100)/3, fields["percent_failed"], testutil.DefaultDelta)
+	require.Equal(t, int64(1), fields["state_changes"])
+	require.Equal(t, uint64(connectionFailed), fields["result_code"])
+	require.Contains(t, fields, "response_time")
+	require.Contains(t, fields, "jitter")
+
+	// Nothing is reported without new probes
+	acc.ClearMetrics()
+	require.NoError(t, c.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
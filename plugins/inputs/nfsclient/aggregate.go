@@ -0,0 +1,145 @@
+package nfsclient
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// The RTT and execution time, the transport's idle time, i.e. the time since
+// the last use of the connection, as well as fields containing ratios are
+// averaged across all mounts of a server. All other fields, including the
+// cumulative queue, response and total times, are counters and thus summed
+// up. The per-operation RTT is recomputed from the summed values.
+var averagedFields = map[string]bool{
+	"rtt":             true,
+	"exe":             true,
+	"idle_time":       true,
+	"kb_per_op":       true,
+	"retrans_percent": true,
+	"errors_percent":  true,
+	"avg_rtt_ms":      true,
+	"avg_exe_ms":      true,
+	"avg_queue_ms":    true,
+}
+
+// Fields for which the smallest value across all mounts of a server is kept,
+// the age of the most recent mount reflects the last statistics reset.
+var minimumFields = map[string]bool{
+	"mount_age_seconds": true,
+}
+
+type aggregatedSeries struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	counts map[string]int
+}
+
+// serverAggregator intercepts the metrics of individual mounts and merges
+// them into one series per NFS server, dropping the given tag, e.g. the
+// mountpoint tag. With an empty tag, metrics with identical tags are merged.
+type serverAggregator struct {
+	telegraf.Accumulator
+
+	dropTag string
+	series  map[string]*aggregatedSeries
+	order   []string
+}
+
+func newServerAggregator(acc telegraf.Accumulator, dropTag string) *serverAggregator {
+	return &serverAggregator{
+		Accumulator: acc,
+		dropTag:     dropTag,
+		series:      make(map[string]*aggregatedSeries),
+	}
+}
+
+func (a *serverAggregator) AddFields(name string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	aggTags := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if k == a.dropTag {
+			continue
+		}
+		aggTags[k] = v
+	}
+	key := seriesKey(name, aggTags)
+
+	s, found := a.series[key]
+	if !found {
+		s = &aggregatedSeries{
+			name:   name,
+			tags:   aggTags,
+			fields: make(map[string]interface{}, len(fields)),
+			counts: make(map[string]int, len(fields)),
+		}
+		a.series[key] = s
+		a.order = append(a.order, key)
+	}
+
+	for k, v := range fields {
+		s.counts[k]++
+		switch value := v.(type) {
+		case uint64:
+			if minimumFields[k] {
+				if current, ok := s.fields[k].(uint64); !ok || value < current {
+					s.fields[k] = value
+				}
+				continue
+			}
+			current, _ := s.fields[k].(uint64)
+			s.fields[k] = current + value
+		case float64:
+			current, _ := s.fields[k].(float64)
+			s.fields[k] = current + value
+		default:
+			s.fields[k] = v
+		}
+	}
+}
+
+// flush emits the aggregated series to the underlying accumulator
+func (a *serverAggregator) flush() {
+	for _, key := range a.order {
+		s := a.series[key]
+		if _, found := s.fields["rtt_per_op"]; found {
+			ops, _ := s.fields["ops"].(uint64)
+			rtt, _ := s.fields["rtt"].(uint64)
+			s.fields["rtt_per_op"] = 0.0
+			if ops > 0 {
+				s.fields["rtt_per_op"] = float64(rtt) / float64(ops)
+			}
+		}
+		for k, v := range s.fields {
+			if !averagedFields[k] || s.counts[k] < 2 {
+				continue
+			}
+			switch value := v.(type) {
+			case uint64:
+				s.fields[k] = value / uint64(s.counts[k])
+			case float64:
+				s.fields[k] = value / float64(s.counts[k])
+			}
+		}
+		a.Accumulator.AddFields(s.name, s.fields, s.tags)
+	}
+	a.series = make(map[string]*aggregatedSeries)
+	a.order = nil
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("," + k + "=" + tags[k])
+	}
+	return b.String()
+}
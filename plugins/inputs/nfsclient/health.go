@@ -0,0 +1,91 @@
+package nfsclient
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// mountSnapshot contains the values of a mount relevant for the health
+// check as read in a single gather cycle.
+type mountSnapshot struct {
+	mountpoint string
+	export     string
+	age        uint64
+	hasAge     bool
+	ops        uint64
+}
+
+// mountState keeps track of a mount's health across gather cycles.
+type mountState struct {
+	age       uint64
+	ops       uint64
+	unchanged int
+}
+
+func (s *mountSnapshot) update(line []string) {
+	if len(line) < 2 {
+		return
+	}
+
+	switch {
+	case line[0] == "age:":
+		if v, err := strconv.ParseUint(line[1], 10, 64); err == nil {
+			s.age = v
+			s.hasAge = true
+		}
+	case strings.HasSuffix(line[0], ":") && line[0] == strings.ToUpper(line[0]):
+		// Per-operation statistics, the first column is the number of
+		// completed operations
+		if v, err := strconv.ParseUint(line[1], 10, 64); err == nil {
+			s.ops += v
+		}
+	}
+}
+
+// checkHealth compares the current snapshots with the state of the previous
+// gather cycles and emits a health metric for each mount.
+func (n *NFSClient) checkHealth(acc telegraf.Accumulator, snapshots map[string]*mountSnapshot) {
+	states := make(map[string]*mountState, len(snapshots))
+	for key, snap := range snapshots {
+		stale := false
+		state, found := n.mountStates[key]
+		if !found {
+			state = &mountState{}
+		} else {
+			// The age of a mount stopping to increase indicates a hung mount
+			// while stalled counters might also be caused by an idle mount.
+			if snap.hasAge && snap.age <= state.age {
+				stale = true
+			}
+			if snap.ops == state.ops {
+				state.unchanged++
+			} else {
+				state.unchanged = 0
+			}
+			if state.unchanged >= n.StaleIntervals {
+				stale = true
+			}
+		}
+		state.age = snap.age
+		state.ops = snap.ops
+		states[key] = state
+
+		tags := map[string]string{
+			"mountpoint":   snap.mountpoint,
+			"serverexport": snap.export,
+		}
+		fields := map[string]interface{}{
+			"stale":               stale,
+			"unchanged_intervals": state.unchanged,
+		}
+		if snap.hasAge {
+			fields["age"] = snap.age
+		}
+		acc.AddFields("nfs_mount_health", fields, tags)
+	}
+
+	// Forget about mounts that disappeared
+	n.mountStates = states
+}
@@ -0,0 +1,88 @@
+package nfsclient
+
+import (
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Indices of the relevant columns in the per-operation statistics
+const (
+	opsIndex          = 0
+	queueTimeIndex    = 5
+	responseTimeIndex = 6
+	totalTimeIndex    = 7
+)
+
+var latencyTypes = []string{"queue", "response", "total"}
+
+// latencyState keeps the raw counters of the last gather cycle and the
+// cumulative bucket counts per latency type of a single operation.
+type latencyState struct {
+	ops     uint64
+	times   [3]uint64
+	buckets [3][]uint64
+}
+
+// addLatencyHistogram derives the average latency of the operations completed
+// since the last gather cycle and adds those operations to the cumulative
+// histogram buckets matching the latency. The resulting histogram is emitted
+// as one metric per bucket with the upper bound in the "le" tag.
+func (n *NFSClient) addLatencyHistogram(tags map[string]string, nline []uint64, acc telegraf.Accumulator) {
+	if len(nline) <= totalTimeIndex {
+		return
+	}
+
+	if n.latencyStates == nil {
+		n.latencyStates = make(map[string]*latencyState)
+	}
+	key := tags["mountpoint"] + "\x00" + tags["serverexport"] + "\x00" + tags["operation"]
+
+	ops := nline[opsIndex]
+	times := [3]uint64{nline[queueTimeIndex], nline[responseTimeIndex], nline[totalTimeIndex]}
+
+	state, found := n.latencyStates[key]
+	if !found {
+		state = &latencyState{}
+		for i := range state.buckets {
+			state.buckets[i] = make([]uint64, len(n.LatencyBuckets)+1)
+		}
+		n.latencyStates[key] = state
+	} else if ops > state.ops {
+		// Only account for operations if the counters did not reset
+		deltaOps := ops - state.ops
+		for i := range times {
+			if times[i] < state.times[i] {
+				continue
+			}
+			latency := float64(times[i]-state.times[i]) / float64(deltaOps)
+			for j, bound := range n.LatencyBuckets {
+				if latency <= bound {
+					state.buckets[i][j] += deltaOps
+				}
+			}
+			state.buckets[i][len(n.LatencyBuckets)] += deltaOps
+		}
+	}
+	state.ops = ops
+	state.times = times
+
+	for j := 0; j <= len(n.LatencyBuckets); j++ {
+		le := "+Inf"
+		if j < len(n.LatencyBuckets) {
+			le = strconv.FormatFloat(n.LatencyBuckets[j], 'f', -1, 64)
+		}
+
+		btags := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			btags[k] = v
+		}
+		btags["le"] = le
+
+		fields := make(map[string]interface{}, len(latencyTypes))
+		for i, t := range latencyTypes {
+			fields[t] = state.buckets[i][j]
+		}
+		acc.AddFields("nfs_ops_latency_bucket", fields, btags)
+	}
+}
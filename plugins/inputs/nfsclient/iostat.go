@@ -0,0 +1,105 @@
+package nfsclient
+
+import (
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Indices of the per-operation statistics columns used by nfsiostat in
+// addition to the ones used for the latency histogram
+const (
+	transIndex     = 1
+	bytesSentIndex = 3
+	bytesRecvIndex = 4
+	errorsIndex    = 8
+)
+
+// iostatState keeps the raw counters of an operation of the last gather cycle
+type iostatState struct {
+	timestamp time.Time
+	counters  []uint64
+}
+
+// addIostat derives the values printed by nfsiostat for the given operation.
+// Like nfsiostat, the values are computed from the changes since the last
+// gather cycle or, for the first cycle and after a reset of the counters,
+// from the statistics since the mount using the mount age.
+func (n *NFSClient) addIostat(mount mountInfo, tags map[string]string, nline []uint64, acc telegraf.Accumulator) {
+	if len(nline) <= totalTimeIndex {
+		return
+	}
+
+	if n.iostatStates == nil {
+		n.iostatStates = make(map[string]*iostatState)
+	}
+	key := tags["mountpoint"] + "\x00" + tags["serverexport"] + "\x00" + tags["operation"]
+
+	now := time.Now()
+	state := n.iostatStates[key]
+	n.iostatStates[key] = &iostatState{timestamp: now, counters: slices.Clone(nline)}
+
+	delta := nline
+	elapsed := float64(mount.age)
+	if state != nil && !isReset(nline, state.counters) {
+		delta = make([]uint64, len(nline))
+		for i := range nline {
+			delta[i] = nline[i] - state.counters[i]
+		}
+		elapsed = now.Sub(state.timestamp).Seconds()
+	}
+
+	ops := delta[opsIndex]
+	var retrans uint64
+	if delta[transIndex] > ops {
+		retrans = delta[transIndex] - ops
+	}
+	kilobytes := float64(delta[bytesSentIndex]+delta[bytesRecvIndex]) / 1024
+
+	fields := map[string]interface{}{
+		"ops":             ops,
+		"retrans":         retrans,
+		"kb_per_op":       0.0,
+		"retrans_percent": 0.0,
+		"avg_rtt_ms":      0.0,
+		"avg_exe_ms":      0.0,
+		"avg_queue_ms":    0.0,
+	}
+	if elapsed > 0 {
+		fields["ops_per_second"] = float64(ops) / elapsed
+		fields["kb_per_second"] = kilobytes / elapsed
+	}
+	if ops > 0 {
+		fields["kb_per_op"] = kilobytes / float64(ops)
+		fields["retrans_percent"] = float64(retrans) * 100 / float64(ops)
+		fields["avg_rtt_ms"] = float64(delta[responseTimeIndex]) / float64(ops)
+		fields["avg_exe_ms"] = float64(delta[totalTimeIndex]) / float64(ops)
+		fields["avg_queue_ms"] = float64(delta[queueTimeIndex]) / float64(ops)
+	}
+
+	// The number of errors is only available with RPC iostats version 1.1
+	if len(delta) > errorsIndex && mount.iostats != "1.0" {
+		fields["errors"] = delta[errorsIndex]
+		fields["errors_percent"] = 0.0
+		if ops > 0 {
+			fields["errors_percent"] = float64(delta[errorsIndex]) * 100 / float64(ops)
+		}
+	}
+
+	acc.AddFields("nfs_iostat", fields, tags)
+}
+
+// isReset returns true if any of the counters decreased or the number of
+// columns changed, e.g. due to a remount
+func isReset(current, previous []uint64) bool {
+	if len(current) != len(previous) {
+		return true
+	}
+	for i := range current {
+		if current[i] < previous[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package nfsclient
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Names of all measurements emitted by the plugin
+var measurements = []string{
+	"nfsstat",
+	"nfs_mount",
+	"nfs_events",
+	"nfs_bytes",
+	"nfs_xprt_tcp",
+	"nfs_xprt_udp",
+	"nfs_xprt_rdma",
+	"nfs_ops",
+	"nfs_ops_latency_bucket",
+	"nfs_iostat",
+	"nfs_pnfs_layout",
+	"nfs_mount_health",
+	"nfs_mount_event",
+	"nfs_server",
+	"nfs_volume",
+}
+
+// measurementAccumulator renames the measurements according to the
+// configured mapping
+type measurementAccumulator struct {
+	telegraf.Accumulator
+	mapping map[string]string
+}
+
+func (a *measurementAccumulator) AddFields(name string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if newName, found := a.mapping[name]; found {
+		name = newName
+	}
+	a.Accumulator.AddFields(name, fields, tags, t...)
+}
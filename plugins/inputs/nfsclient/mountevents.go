@@ -0,0 +1,38 @@
+package nfsclient
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// mountKey identifies a mount across gather cycles
+type mountKey struct {
+	mountpoint string
+	export     string
+}
+
+// emitMountEvents compares the mounts found in the current gather cycle with
+// the ones of the previous cycle and emits an event for each mount that
+// appeared or disappeared. The first cycle only records the mounts.
+func (n *NFSClient) emitMountEvents(acc telegraf.Accumulator, mounts map[mountKey]bool) {
+	if n.knownMounts != nil {
+		for key := range mounts {
+			if !n.knownMounts[key] {
+				addMountEvent(acc, key, "added")
+			}
+		}
+		for key := range n.knownMounts {
+			if !mounts[key] {
+				addMountEvent(acc, key, "removed")
+			}
+		}
+	}
+	n.knownMounts = mounts
+}
+
+func addMountEvent(acc telegraf.Accumulator, key mountKey, action string) {
+	tags := map[string]string{
+		"mountpoint":   key.mountpoint,
+		"serverexport": key.export,
+	}
+	acc.AddFields("nfs_mount_event", map[string]interface{}{"action": action}, tags)
+}
@@ -7,31 +7,84 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
+// Number of statistics columns present in all RPC iostats versions, newer
+// versions expose additional columns
+const (
+	xprttcpBaseFields  = 9
+	xprtudpBaseFields  = 6
+	xprtrdmaBaseFields = 9
+	nfsopBaseFields    = 8
+)
+
+// mountInfo contains the information of the mount currently being parsed
+type mountInfo struct {
+	mountpoint string
+	export     string
+	version    string
+	iostats    string
+	transport  string
+	layout     string
+	xprts      int
+	age        uint64
+}
+
 type NFSClient struct {
-	Fullstat          bool            `toml:"fullstat"`
-	IncludeMounts     []string        `toml:"include_mounts"`
-	ExcludeMounts     []string        `toml:"exclude_mounts"`
-	IncludeOperations []string        `toml:"include_operations"`
-	ExcludeOperations []string        `toml:"exclude_operations"`
-	Log               telegraf.Logger `toml:"-"`
-	nfs3Ops           map[string]bool
-	nfs4Ops           map[string]bool
-	mountstatsPath    string
+	Fullstat           bool              `toml:"fullstat" deprecated:"1.36.0;1.40.0;use 'collect' instead"`
+	Collect            []string          `toml:"collect"`
+	IncludeMounts      []string          `toml:"include_mounts"`
+	ExcludeMounts      []string          `toml:"exclude_mounts"`
+	IncludeServers     []string          `toml:"include_servers"`
+	ExcludeServers     []string          `toml:"exclude_servers"`
+	IncludeOperations  []string          `toml:"include_operations"`
+	ExcludeOperations  []string          `toml:"exclude_operations"`
+	AggregateBy        string            `toml:"aggregate_by"`
+	XprtConnections    string            `toml:"xprt_connections"`
+	HealthCheck        bool              `toml:"health_check"`
+	StaleIntervals     int               `toml:"stale_intervals"`
+	MountEvents        bool              `toml:"mount_events"`
+	Timeout            config.Duration   `toml:"timeout"`
+	DeriveIostat       bool              `toml:"derive_iostat"`
+	LatencyBuckets     []float64         `toml:"latency_buckets"`
+	ResolveServerNames bool              `toml:"resolve_server_names"`
+	ResolveCacheTTL    config.Duration   `toml:"resolve_cache_ttl"`
+	MeasurementMap     map[string]string `toml:"measurement_map"`
+	RemoteHosts        []*remoteHost     `toml:"remote_hosts"`
+	Log                telegraf.Logger   `toml:"-"`
+	nfs3Ops            map[string]bool
+	nfs4Ops            map[string]bool
+	mountstatsPath     string
+	nfsfsPath          string
+	mountinfoPath      string
 	// Add compiled regex patterns
 	includeMountRegex []*regexp.Regexp
 	excludeMountRegex []*regexp.Regexp
+	includeServers    *serverFilter
+	excludeServers    *serverFilter
+	collect           map[string]bool
+	mountStates       map[string]*mountState
+	knownMounts       map[mountKey]bool
+	latencyStates     map[string]*latencyState
+	iostatStates      map[string]*iostatState
+	resolver          *serverNameResolver
+	incompleteGathers selfstat.Stat
 }
 
 func (*NFSClient) SampleConfig() string {
@@ -139,6 +192,8 @@ func (n *NFSClient) Init() error {
 	nfs4Ops := make(map[string]bool)
 
 	n.mountstatsPath = n.getMountStatsPath()
+	n.nfsfsPath = filepath.Join(internal.GetProcPath(), "fs", "nfsfs")
+	n.mountinfoPath = filepath.Join(internal.GetProcPath(), "self", "mountinfo")
 
 	if len(n.IncludeOperations) == 0 {
 		for _, Op := range nfs3Fields {
@@ -170,6 +225,42 @@ func (n *NFSClient) Init() error {
 	n.nfs3Ops = nfs3Ops
 	n.nfs4Ops = nfs4Ops
 
+	n.collect = make(map[string]bool, len(n.Collect))
+	for _, group := range n.Collect {
+		switch group {
+		case "events", "bytes", "xprt", "ops", "pnfs", "nfsfs":
+			n.collect[group] = true
+		default:
+			return fmt.Errorf("invalid 'collect' value %q", group)
+		}
+	}
+
+	switch n.XprtConnections {
+	case "":
+		n.XprtConnections = "split"
+	case "split", "sum":
+	default:
+		return fmt.Errorf("invalid 'xprt_connections' value %q", n.XprtConnections)
+	}
+
+	switch n.AggregateBy {
+	case "":
+		n.AggregateBy = "mount"
+	case "mount", "server":
+	default:
+		return fmt.Errorf("invalid 'aggregate_by' value %q", n.AggregateBy)
+	}
+
+	if n.StaleIntervals <= 0 {
+		n.StaleIntervals = 3
+	}
+
+	for i, bound := range n.LatencyBuckets {
+		if i > 0 && bound <= n.LatencyBuckets[i-1] {
+			return errors.New("latency buckets must be in strictly ascending order")
+		}
+	}
+
 	if len(n.IncludeMounts) > 0 {
 		n.Log.Debugf("Including these mount patterns: %v", n.IncludeMounts)
 	} else {
@@ -218,14 +309,80 @@ func (n *NFSClient) Init() error {
 		}
 	}
 
+	if len(n.IncludeServers) > 0 {
+		f, err := newServerFilter(n.IncludeServers)
+		if err != nil {
+			return fmt.Errorf("parsing include servers failed: %w", err)
+		}
+		n.includeServers = f
+	}
+
+	if len(n.ExcludeServers) > 0 {
+		f, err := newServerFilter(n.ExcludeServers)
+		if err != nil {
+			return fmt.Errorf("parsing exclude servers failed: %w", err)
+		}
+		n.excludeServers = f
+	}
+
+	for name, newName := range n.MeasurementMap {
+		if !slices.Contains(measurements, name) {
+			return fmt.Errorf("unknown measurement %q in 'measurement_map'", name)
+		}
+		if newName == "" {
+			return fmt.Errorf("empty name for measurement %q in 'measurement_map'", name)
+		}
+	}
+
+	if n.ResolveServerNames {
+		if n.ResolveCacheTTL <= 0 {
+			n.ResolveCacheTTL = config.Duration(time.Hour)
+		}
+		n.resolver = newServerNameResolver(time.Duration(n.ResolveCacheTTL))
+	}
+
+	if n.Timeout > 0 {
+		n.incompleteGathers = selfstat.Register("nfsclient", "gather_errors", map[string]string{"incomplete": "true"})
+	}
+
+	for i, r := range n.RemoteHosts {
+		if err := r.init(n); err != nil {
+			return fmt.Errorf("initializing remote host %d failed: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
 
 func (n *NFSClient) Gather(acc telegraf.Accumulator) error {
+	// Only gather the remote hosts if configured
+	if len(n.RemoteHosts) > 0 {
+		n.gatherRemote(acc)
+		return nil
+	}
+
 	if _, err := os.Stat(n.mountstatsPath); os.IsNotExist(err) {
 		return err
 	}
 
+	if n.Timeout > 0 {
+		if err := n.gatherMountstatsWithTimeout(acc); err != nil {
+			return err
+		}
+	} else if err := n.gatherMountstats(acc); err != nil {
+		return err
+	}
+
+	if n.collects("nfsfs") {
+		if err := n.gatherNFSFS(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering nfsfs statistics failed: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (n *NFSClient) gatherMountstats(acc telegraf.Accumulator) error {
 	// Attempt to read the file to see if we have permissions before opening
 	// which can lead to a panic
 	if _, err := os.ReadFile(n.mountstatsPath); err != nil {
@@ -243,12 +400,11 @@ func (n *NFSClient) Gather(acc telegraf.Accumulator) error {
 	if err := n.processText(scanner, acc); err != nil {
 		return err
 	}
-
 	return scanner.Err()
 }
 
-func (n *NFSClient) parseStat(mountpoint, export, version string, line []string, acc telegraf.Accumulator) error {
-	tags := map[string]string{"mountpoint": mountpoint, "serverexport": export}
+func (n *NFSClient) parseStat(mount mountInfo, line []string, acc telegraf.Accumulator) error {
+	tags := map[string]string{"mountpoint": mount.mountpoint, "serverexport": mount.export}
 	nline, err := convertToUint64(line)
 	if err != nil {
 		return err
@@ -309,6 +465,9 @@ func (n *NFSClient) parseStat(mountpoint, export, version string, line []string,
 		"badxids",
 		"inflightsends",
 		"backlogutil",
+		"max_slots",
+		"sending_queue",
+		"pending_queue",
 	}
 
 	var xprttcpFields = []string{
@@ -321,6 +480,38 @@ func (n *NFSClient) parseStat(mountpoint, export, version string, line []string,
 		"badxids",
 		"inflightsends",
 		"backlogutil",
+		"max_slots",
+		"sending_queue",
+		"pending_queue",
+	}
+
+	var xprtrdmaFields = []string{
+		"bind_count",
+		"connect_count",
+		"connect_time",
+		"idle_time",
+		"rpcsends",
+		"rpcreceives",
+		"badxids",
+		"inflightsends",
+		"backlogutil",
+		"read_chunk_count",
+		"write_chunk_count",
+		"reply_chunk_count",
+		"total_rdma_request",
+		"total_rdma_reply",
+		"pullup_copy_count",
+		"fixup_copy_count",
+		"hardway_register_count",
+		"failed_marshal_count",
+		"bad_reply_count",
+		"nomsg_call_count",
+		"mrs_recycled",
+		"mrs_orphaned",
+		"mrs_allocated",
+		"local_inv_needed",
+		"empty_sendctx_q",
+		"reply_waits_for_send",
 	}
 
 	var nfsopFields = []string{
@@ -349,56 +540,93 @@ func (n *NFSClient) parseStat(mountpoint, export, version string, line []string,
 			fields["rtt_per_op"] = float64(nline[6]) / float64(nline[0])
 		}
 		tags["operation"] = first
+		if mount.transport != "" {
+			tags["transport"] = mount.transport
+		}
 		acc.AddFields("nfsstat", fields, tags)
+		if n.DeriveIostat {
+			n.addIostat(mount, tags, nline, acc)
+		}
 	}
 
-	if n.Fullstat {
-		switch first {
-		case "events":
-			if len(nline) >= len(eventsFields) {
-				for i, t := range eventsFields {
-					fields[t] = nline[i]
-				}
-				acc.AddFields("nfs_events", fields, tags)
+	switch first {
+	case "age":
+		fields["mount_age_seconds"] = nline[0]
+		acc.AddFields("nfs_mount", fields, tags)
+
+	case "events":
+		if n.collects("events") && len(nline) >= len(eventsFields) {
+			for i, t := range eventsFields {
+				fields[t] = nline[i]
 			}
+			acc.AddFields("nfs_events", fields, tags)
+		}
 
-		case "bytes":
-			if len(nline) >= len(bytesFields) {
-				for i, t := range bytesFields {
-					fields[t] = nline[i]
-				}
-				acc.AddFields("nfs_bytes", fields, tags)
+	case "bytes":
+		if n.collects("bytes") && len(nline) >= len(bytesFields) {
+			for i, t := range bytesFields {
+				fields[t] = nline[i]
 			}
+			acc.AddFields("nfs_bytes", fields, tags)
+		}
 
-		case "xprt":
-			if len(line) > 1 {
-				switch line[1] {
-				case "tcp":
-					if len(nline)+2 >= len(xprttcpFields) {
-						for i, t := range xprttcpFields {
-							fields[t] = nline[i+2]
-						}
-						acc.AddFields("nfs_xprt_tcp", fields, tags)
+	case "xprt":
+		// The first two columns are the protocol and the port, the
+		// queue statistics at the end are missing in old kernels. Mounts
+		// using nconnect list one line per connection.
+		if n.collects("xprt") && len(line) > 1 {
+			if n.XprtConnections == "split" {
+				tags["connection_index"] = strconv.Itoa(mount.xprts - 1)
+			}
+			switch line[1] {
+			case "tcp":
+				if len(nline)-2 >= xprttcpBaseFields {
+					columns := min(len(nline)-2, knownColumns(mount.iostats, xprttcpBaseFields, len(xprttcpFields)))
+					for i, t := range xprttcpFields[:columns] {
+						fields[t] = nline[i+2]
 					}
-				case "udp":
-					if len(nline)+2 >= len(xprtudpFields) {
-						for i, t := range xprtudpFields {
-							fields[t] = nline[i+2]
-						}
-						acc.AddFields("nfs_xprt_udp", fields, tags)
+					acc.AddFields("nfs_xprt_tcp", fields, tags)
+				}
+			case "udp":
+				if len(nline)-2 >= xprtudpBaseFields {
+					columns := min(len(nline)-2, knownColumns(mount.iostats, xprtudpBaseFields, len(xprtudpFields)))
+					for i, t := range xprtudpFields[:columns] {
+						fields[t] = nline[i+2]
+					}
+					acc.AddFields("nfs_xprt_udp", fields, tags)
+				}
+			case "rdma":
+				if len(nline)-2 >= xprtrdmaBaseFields {
+					columns := min(len(nline)-2, len(xprtrdmaFields))
+					for i, t := range xprtrdmaFields[:columns] {
+						fields[t] = nline[i+2]
 					}
+					acc.AddFields("nfs_xprt_rdma", fields, tags)
 				}
 			}
 		}
 
-		if (version == "3" && n.nfs3Ops[first]) || (version == "4" && n.nfs4Ops[first]) {
-			tags["operation"] = first
-			if len(nline) <= len(nfsopFields) {
-				for i, t := range nline {
-					fields[nfsopFields[i]] = t
-				}
-				acc.AddFields("nfs_ops", fields, tags)
-			}
+	case "ds":
+		if n.collects("pnfs") {
+			n.parseLayoutStat(mount, line, nline, acc)
+		}
+	}
+
+	if n.collects("ops") && ((mount.version == "3" && n.nfs3Ops[first]) || (mount.version == "4" && n.nfs4Ops[first])) {
+		tags["operation"] = first
+		if mount.transport != "" {
+			tags["transport"] = mount.transport
+		}
+		columns := min(len(nline), knownColumns(mount.iostats, nfsopBaseFields, len(nfsopFields)))
+		if len(nline) > columns {
+			n.Log.Debugf("Ignoring %d unknown columns of operation %q", len(nline)-columns, first)
+		}
+		for i, t := range nline[:columns] {
+			fields[nfsopFields[i]] = t
+		}
+		acc.AddFields("nfs_ops", fields, tags)
+		if len(n.LatencyBuckets) > 0 {
+			n.addLatencyHistogram(tags, nline, acc)
 		}
 	}
 
@@ -406,11 +634,40 @@ func (n *NFSClient) parseStat(mountpoint, export, version string, line []string,
 }
 
 func (n *NFSClient) processText(scanner *bufio.Scanner, acc telegraf.Accumulator) error {
-	var mount string
-	var version string
-	var export string
+	var mount mountInfo
 	var skip bool
 
+	if len(n.MeasurementMap) > 0 {
+		acc = &measurementAccumulator{Accumulator: acc, mapping: n.MeasurementMap}
+	}
+	if n.resolver != nil {
+		acc = &serverNameAccumulator{Accumulator: acc, resolver: n.resolver}
+	}
+
+	// Merge the metrics of all mounts of the same server export if requested
+	var agg *serverAggregator
+	collector := acc
+	if n.AggregateBy == "server" {
+		agg = newServerAggregator(acc, "mountpoint")
+		collector = agg
+	}
+
+	// Merge the transports of mounts with multiple connections if requested
+	var xprtAgg *serverAggregator
+	if n.XprtConnections == "sum" {
+		xprtAgg = newServerAggregator(collector, "")
+	}
+
+	var snapshots map[string]*mountSnapshot
+	if n.HealthCheck {
+		snapshots = make(map[string]*mountSnapshot)
+	}
+
+	var mounts map[mountKey]bool
+	if n.MountEvents {
+		mounts = make(map[mountKey]bool)
+	}
+
 	for scanner.Scan() {
 		line := strings.Fields(scanner.Text())
 		lineLength := len(line)
@@ -424,13 +681,28 @@ func (n *NFSClient) processText(scanner *bufio.Scanner, acc telegraf.Accumulator
 		// This denotes a new mount has been found, so set
 		// mount and export, and stop skipping (for now)
 		if lineLength > 4 && choice.Contains("fstype", line) && (choice.Contains("nfs", line) || choice.Contains("nfs4", line)) {
-			mount = line[4]
-			export = line[1]
+			mount = mountInfo{mountpoint: line[4], export: line[1], version: mount.version}
 		} else if lineLength > 5 && (choice.Contains("(nfs)", line) || choice.Contains("(nfs4)", line)) {
-			version = strings.Split(line[5], "/")[1]
+			mount.version = strings.Split(line[5], "/")[1]
+		}
+
+		switch {
+		case lineLength > 3 && line[0] == "RPC" && line[1] == "iostats":
+			mount.iostats = line[3]
+		case lineLength > 1 && line[0] == "opts:":
+			mount.transport = parseTransport(line[1:])
+		case lineLength > 1 && line[0] == "xprt:":
+			mount.transport = line[1]
+			mount.xprts++
+		case lineLength > 1 && line[0] == "pnfs:":
+			mount.layout = parsePNFSLayout(line[1])
+		case lineLength > 1 && line[0] == "age:":
+			if age, err := strconv.ParseUint(line[1], 10, 64); err == nil {
+				mount.age = age
+			}
 		}
 
-		if mount == "" {
+		if mount.mountpoint == "" {
 			continue
 		}
 
@@ -438,7 +710,7 @@ func (n *NFSClient) processText(scanner *bufio.Scanner, acc telegraf.Accumulator
 		if len(n.includeMountRegex) > 0 {
 			skip = true
 			for _, regex := range n.includeMountRegex {
-				if regex.MatchString(mount) {
+				if regex.MatchString(mount.mountpoint) {
 					skip = false
 					break
 				}
@@ -448,24 +720,118 @@ func (n *NFSClient) processText(scanner *bufio.Scanner, acc telegraf.Accumulator
 		// Check exclude patterns using compiled regex
 		if !skip && len(n.excludeMountRegex) > 0 {
 			for _, regex := range n.excludeMountRegex {
-				if regex.MatchString(mount) {
+				if regex.MatchString(mount.mountpoint) {
 					skip = true
 					break
 				}
 			}
 		}
 
+		// Check the server of the export
+		if !skip && n.includeServers != nil && !n.includeServers.match(mount.export) {
+			skip = true
+		}
+		if !skip && n.excludeServers != nil && n.excludeServers.match(mount.export) {
+			skip = true
+		}
+
+		if !skip && mounts != nil {
+			mounts[mountKey{mount.mountpoint, mount.export}] = true
+		}
+
+		if !skip && snapshots != nil {
+			snap, found := snapshots[mount.mountpoint]
+			if !found {
+				snap = &mountSnapshot{mountpoint: mount.mountpoint, export: mount.export}
+				snapshots[mount.mountpoint] = snap
+			}
+			snap.update(line)
+		}
+
 		if !skip {
-			err := n.parseStat(mount, export, version, line, acc)
+			acc := collector
+			if xprtAgg != nil && line[0] == "xprt:" {
+				acc = xprtAgg
+			}
+			err := n.parseStat(mount, line, acc)
 			if err != nil {
 				return fmt.Errorf("could not parseStat: %w", err)
 			}
 		}
 	}
 
+	if xprtAgg != nil {
+		xprtAgg.flush()
+	}
+	if agg != nil {
+		agg.flush()
+	}
+
+	// Mounts not read due to a timeout would wrongly be reported as stale or
+	// removed, so skip the checks relying on the complete list of mounts
+	if errors.Is(scanner.Err(), errReadTimeout) {
+		return nil
+	}
+
+	if snapshots != nil {
+		n.checkHealth(acc, snapshots)
+	}
+
+	if mounts != nil {
+		n.emitMountEvents(acc, mounts)
+	}
+
 	return nil
 }
 
+// parseTransport extracts the transport protocol from the mount options
+func parseTransport(opts []string) string {
+	for _, field := range opts {
+		for _, opt := range strings.Split(field, ",") {
+			if proto, found := strings.CutPrefix(opt, "proto="); found {
+				// Treat IPv6 transports like their IPv4 counterparts
+				return strings.TrimSuffix(proto, "6")
+			}
+		}
+	}
+	return ""
+}
+
+// parsePNFSLayout returns the name of the pNFS layout driver of a mount
+func parsePNFSLayout(driver string) string {
+	switch driver {
+	case "LAYOUT_FLEX_FILES":
+		return "flexfiles"
+	case "LAYOUT_NFSV4_1_FILES":
+		return "files"
+	case "LAYOUT_BLOCK_VOLUME":
+		return "block"
+	case "LAYOUT_SCSI":
+		return "scsi"
+	case "not":
+		// pNFS is not configured for the mount
+		return ""
+	}
+	return strings.ToLower(driver)
+}
+
+// collects returns true if the given group of statistics should be collected.
+// The deprecated "fullstat" option enables all groups.
+func (n *NFSClient) collects(group string) bool {
+	return n.Fullstat || n.collect[group]
+}
+
+// knownColumns returns the number of columns to parse for statistics with
+// the given number of fields. RPC iostats version 1.0 lacks the fields added
+// in version 1.1, i.e. the transport queue statistics and the per-operation
+// errors, so only the base fields are parsed for that version.
+func knownColumns(iostats string, base, known int) int {
+	if iostats == "1.0" {
+		return base
+	}
+	return known
+}
+
 func (n *NFSClient) getMountStatsPath() string {
 	path := "/proc/self/mountstats"
 	if os.Getenv("MOUNT_PROC") != "" {
@@ -2,12 +2,22 @@ package nfsclient
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -27,7 +37,7 @@ func TestNFSClientParsev3(t *testing.T) {
 	nfsclient.nfs3Ops = map[string]bool{"READLINK": true, "GETATTR": false}
 	nfsclient.nfs4Ops = map[string]bool{"READLINK": true, "GETATTR": false}
 	data := strings.Fields("         READLINK: 500 501 502 503 504 505 506 507")
-	err := nfsclient.parseStat("1.2.3.4:/storage/NFS", "/A", "3", data, &acc)
+	err := nfsclient.parseStat(mountInfo{mountpoint: "1.2.3.4:/storage/NFS", export: "/A", version: "3", iostats: "1.0"}, data, &acc)
 	require.NoError(t, err)
 
 	fieldsOps := map[string]interface{}{
@@ -50,7 +60,7 @@ func TestNFSClientParsev4(t *testing.T) {
 	nfsclient.nfs3Ops = map[string]bool{"DESTROY_SESSION": true, "GETATTR": false}
 	nfsclient.nfs4Ops = map[string]bool{"DESTROY_SESSION": true, "GETATTR": false}
 	data := strings.Fields("    DESTROY_SESSION: 500 501 502 503 504 505 506 507")
-	err := nfsclient.parseStat("2.2.2.2:/nfsdata/", "/B", "4", data, &acc)
+	err := nfsclient.parseStat(mountInfo{mountpoint: "2.2.2.2:/nfsdata/", export: "/B", version: "4", iostats: "1.0"}, data, &acc)
 	require.NoError(t, err)
 
 	fieldsOps := map[string]interface{}{
@@ -73,7 +83,7 @@ func TestNFSClientParseLargeValue(t *testing.T) {
 	nfsclient.nfs3Ops = map[string]bool{"SETCLIENTID": true, "GETATTR": false}
 	nfsclient.nfs4Ops = map[string]bool{"SETCLIENTID": true, "GETATTR": false}
 	data := strings.Fields("    SETCLIENTID: 218 216 0 53568 12960 18446744073709531008 134 197")
-	err := nfsclient.parseStat("2.2.2.2:/nfsdata/", "/B", "4", data, &acc)
+	err := nfsclient.parseStat(mountInfo{mountpoint: "2.2.2.2:/nfsdata/", export: "/B", version: "4", iostats: "1.0"}, data, &acc)
 	require.NoError(t, err)
 
 	fieldsOps := map[string]interface{}{
@@ -117,6 +127,7 @@ func TestNFSClientProcessStat(t *testing.T) {
 		"serverexport": "1.2.3.4:/storage/NFS",
 		"mountpoint":   "/A",
 		"operation":    "READ",
+		"transport":    "tcp",
 	}
 
 	acc.AssertContainsTaggedFields(t, "nfsstat", fieldsReadstat, readTags)
@@ -134,6 +145,7 @@ func TestNFSClientProcessStat(t *testing.T) {
 		"serverexport": "1.2.3.4:/storage/NFS",
 		"mountpoint":   "/A",
 		"operation":    "WRITE",
+		"transport":    "tcp",
 	}
 	acc.AssertContainsTaggedFields(t, "nfsstat", fieldsWritestat, writeTags)
 }
@@ -339,3 +351,1147 @@ func TestNFSClientInvalidExcludeRegex(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to compile exclude mount pattern")
 }
+
+func TestNFSClientAggregateByServer(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	100
+	RPC iostats version: 1.0  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300
+	       WRITE: 10 10 0 100 200 5 20 30
+device filer:/vol/a mounted on /mnt/b with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	100
+	RPC iostats version: 1.0  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 300 300 0 3000 4000 150 400 500
+	       WRITE: 30 31 0 300 400 15 40 50
+`
+
+	nfsclient := NFSClient{
+		AggregateBy: "server",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_mount",
+			map[string]string{"serverexport": "filer:/vol/a"},
+			map[string]interface{}{"mount_age_seconds": uint64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsstat",
+			map[string]string{"serverexport": "filer:/vol/a", "operation": "READ", "transport": "tcp"},
+			map[string]interface{}{
+				"ops":        uint64(400),
+				"retrans":    uint64(1),
+				"bytes":      uint64(10000),
+				"rtt":        uint64(300),
+				"exe":        uint64(400),
+				"rtt_per_op": float64(1.5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsstat",
+			map[string]string{"serverexport": "filer:/vol/a", "operation": "WRITE", "transport": "tcp"},
+			map[string]interface{}{
+				"ops":        uint64(40),
+				"retrans":    uint64(1),
+				"bytes":      uint64(1000),
+				"rtt":        uint64(30),
+				"exe":        uint64(40),
+				"rtt_per_op": float64(1.5),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Cumulative times are summed up while ratios are averaged
+	acc.ClearMetrics()
+	agg := newServerAggregator(&acc, "mountpoint")
+	agg.AddFields("nfs_ops",
+		map[string]interface{}{"queue_time": uint64(100), "avg_rtt_ms": 2.0},
+		map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+	)
+	agg.AddFields("nfs_ops",
+		map[string]interface{}{"queue_time": uint64(300), "avg_rtt_ms": 4.0},
+		map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/b"},
+	)
+	agg.flush()
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"nfs_ops",
+			map[string]string{"serverexport": "filer:/vol/a"},
+			map[string]interface{}{"queue_time": uint64(400), "avg_rtt_ms": 3.0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNFSClientInvalidAggregateBy(t *testing.T) {
+	nfsclient := &NFSClient{
+		AggregateBy: "cluster",
+		Log:         testutil.Logger{},
+	}
+	require.ErrorContains(t, nfsclient.Init(), "invalid 'aggregate_by' value")
+}
+
+func TestNFSClientHealthCheck(t *testing.T) {
+	template := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	%d
+	RPC iostats version: 1.0  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: %d 100 0 1000 2000 50 200 300
+	       WRITE: 10 10 0 100 200 5 20 30
+`
+
+	nfsclient := NFSClient{
+		HealthCheck:    true,
+		StaleIntervals: 2,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	tests := []struct {
+		age       int
+		reads     int
+		stale     bool
+		unchanged int
+	}{
+		{age: 100, reads: 100},
+		{age: 110, reads: 150},
+		{age: 120, reads: 150, unchanged: 1},
+		{age: 130, reads: 150, unchanged: 2, stale: true},
+		{age: 140, reads: 160},
+		{age: 140, reads: 170, stale: true},
+	}
+
+	for _, tt := range tests {
+		data := fmt.Sprintf(template, tt.age, tt.reads)
+
+		var acc testutil.Accumulator
+		require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+		expected := []telegraf.Metric{
+			metric.New(
+				"nfs_mount_health",
+				map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+				map[string]interface{}{
+					"age":                 uint64(tt.age),
+					"stale":               tt.stale,
+					"unchanged_intervals": tt.unchanged,
+				},
+				time.Unix(0, 0),
+			),
+		}
+		var actual []telegraf.Metric
+		for _, m := range acc.GetTelegrafMetrics() {
+			if m.Name() == "nfs_mount_health" {
+				actual = append(actual, m)
+			}
+		}
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	}
+}
+
+func TestNFSClientLatencyHistogram(t *testing.T) {
+	nfsclient := NFSClient{
+		Fullstat:       true,
+		LatencyBuckets: []float64{1, 5, 10},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	tags := map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "READ"}
+	lines := []string{
+		"READ: 100 100 0 1000 2000 100 200 300",
+		// 100 ops with 0.5ms queue, 4ms response and 6ms total time each
+		"READ: 200 200 0 2000 4000 150 600 900",
+		// 50 ops with 2ms queue, 20ms response and 22ms total time each
+		"READ: 250 250 0 3000 5000 250 1600 2000",
+	}
+
+	var acc testutil.Accumulator
+	for _, line := range lines {
+		acc.ClearMetrics()
+		require.NoError(t, nfsclient.parseStat(mountInfo{mountpoint: tags["mountpoint"], export: tags["serverexport"], version: "3", iostats: "1.1"}, strings.Fields(line), &acc))
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_ops_latency_bucket",
+			map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "READ", "le": "1"},
+			map[string]interface{}{"queue": uint64(100), "response": uint64(0), "total": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_ops_latency_bucket",
+			map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "READ", "le": "5"},
+			map[string]interface{}{"queue": uint64(150), "response": uint64(100), "total": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_ops_latency_bucket",
+			map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "READ", "le": "10"},
+			map[string]interface{}{"queue": uint64(150), "response": uint64(100), "total": uint64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_ops_latency_bucket",
+			map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "READ", "le": "+Inf"},
+			map[string]interface{}{"queue": uint64(150), "response": uint64(150), "total": uint64(150)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "nfs_ops_latency_bucket" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestNFSClientDeriveIostat(t *testing.T) {
+	template := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	%d
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: %s
+	       WRITE: 0 0 0 0 0 0 0 0 0
+`
+	nfsclient := NFSClient{DeriveIostat: true, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	tags := map[string]string{"mountpoint": "/mnt/a", "serverexport": "filer:/vol/a", "operation": "READ", "transport": "tcp"}
+	samples := []struct {
+		name     string
+		age      int
+		read     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "since mount",
+			age:  100,
+			read: "1000 1000 0 102400 6451200 10 1500 1600 0",
+			expected: map[string]interface{}{
+				"ops":             uint64(1000),
+				"ops_per_second":  10.0,
+				"kb_per_second":   64.0,
+				"kb_per_op":       6.4,
+				"retrans":         uint64(0),
+				"retrans_percent": 0.0,
+				"avg_rtt_ms":      1.5,
+				"avg_exe_ms":      1.6,
+				"avg_queue_ms":    0.01,
+				"errors":          uint64(0),
+				"errors_percent":  0.0,
+			},
+		},
+		{
+			name: "interval",
+			age:  110,
+			read: "1100 1102 0 112640 7106560 12 1700 1820 1",
+			expected: map[string]interface{}{
+				"ops":             uint64(100),
+				"ops_per_second":  10.0,
+				"kb_per_second":   65.0,
+				"kb_per_op":       6.5,
+				"retrans":         uint64(2),
+				"retrans_percent": 2.0,
+				"avg_rtt_ms":      2.0,
+				"avg_exe_ms":      2.2,
+				"avg_queue_ms":    0.02,
+				"errors":          uint64(1),
+				"errors_percent":  1.0,
+			},
+		},
+		{
+			name: "remount",
+			age:  5,
+			read: "50 50 0 5120 322560 0 50 60 0",
+			expected: map[string]interface{}{
+				"ops":             uint64(50),
+				"ops_per_second":  10.0,
+				"kb_per_second":   64.0,
+				"kb_per_op":       6.4,
+				"retrans":         uint64(0),
+				"retrans_percent": 0.0,
+				"avg_rtt_ms":      1.0,
+				"avg_exe_ms":      1.2,
+				"avg_queue_ms":    0.0,
+				"errors":          uint64(0),
+				"errors_percent":  0.0,
+			},
+		},
+	}
+
+	for _, sample := range samples {
+		// Pretend the previous gather happened ten seconds ago
+		for _, state := range nfsclient.iostatStates {
+			state.timestamp = state.timestamp.Add(-10 * time.Second)
+		}
+
+		var acc testutil.Accumulator
+		data := fmt.Sprintf(template, sample.age, sample.read)
+		require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc), sample.name)
+
+		var actual []telegraf.Metric
+		for _, m := range acc.GetTelegrafMetrics() {
+			if m.Name() == "nfs_iostat" && m.Tags()["operation"] == "READ" {
+				actual = append(actual, m)
+			}
+		}
+		expected := []telegraf.Metric{metric.New("nfs_iostat", tags, sample.expected, time.Unix(0, 0))}
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), cmpopts.EquateApprox(0.01, 0))
+	}
+}
+
+func TestNFSClientInvalidLatencyBuckets(t *testing.T) {
+	nfsclient := &NFSClient{
+		LatencyBuckets: []float64{10, 5},
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, nfsclient.Init(), "strictly ascending")
+}
+
+func TestNFSClientParseErrors(t *testing.T) {
+	var acc testutil.Accumulator
+
+	nfsclient := NFSClient{Fullstat: true, Log: testutil.Logger{}}
+	nfsclient.nfs4Ops = map[string]bool{"GETATTR": true}
+	data := strings.Fields("    GETATTR: 500 501 502 503 504 505 506 507 508 509")
+	err := nfsclient.parseStat(mountInfo{mountpoint: "/B", export: "2.2.2.2:/nfsdata/", version: "4", iostats: "1.1"}, data, &acc)
+	require.NoError(t, err)
+
+	fieldsOps := map[string]interface{}{
+		"ops":           uint64(500),
+		"trans":         uint64(501),
+		"timeouts":      uint64(502),
+		"bytes_sent":    uint64(503),
+		"bytes_recv":    uint64(504),
+		"queue_time":    uint64(505),
+		"response_time": uint64(506),
+		"total_time":    uint64(507),
+		"errors":        uint64(508),
+	}
+	acc.AssertContainsFields(t, "nfs_ops", fieldsOps)
+}
+
+func TestNFSClientProcessIostatsVersion(t *testing.T) {
+	var acc testutil.Accumulator
+
+	nfsclient := NFSClient{Fullstat: true, IncludeMounts: []string{"^/A$", "^/D$"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	file, err := os.Open(getMountStatsPath())
+	require.NoError(t, err)
+	defer file.Close()
+
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(file), &acc))
+
+	// Version 1.0 does not provide the queue statistics and errors
+	acc.AssertContainsTaggedFields(t, "nfs_xprt_tcp",
+		map[string]interface{}{
+			"bind_count":    uint64(1),
+			"connect_count": uint64(1),
+			"connect_time":  uint64(0),
+			"idle_time":     uint64(0),
+			"rpcsends":      uint64(96172963),
+			"rpcreceives":   uint64(96172963),
+			"badxids":       uint64(0),
+			"inflightsends": uint64(620878754),
+			"backlogutil":   uint64(0),
+		},
+		map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "connection_index": "0"},
+	)
+	acc.AssertContainsTaggedFields(t, "nfs_ops",
+		map[string]interface{}{
+			"ops":           uint64(0),
+			"trans":         uint64(0),
+			"timeouts":      uint64(0),
+			"bytes_sent":    uint64(0),
+			"bytes_recv":    uint64(0),
+			"queue_time":    uint64(0),
+			"response_time": uint64(0),
+			"total_time":    uint64(0),
+		},
+		map[string]string{"mountpoint": "/A", "serverexport": "1.2.3.4:/storage/NFS", "operation": "NULL", "transport": "tcp"},
+	)
+
+	// Version 1.1 adds the queue statistics and errors
+	acc.AssertContainsTaggedFields(t, "nfs_xprt_tcp",
+		map[string]interface{}{
+			"bind_count":    uint64(0),
+			"connect_count": uint64(2),
+			"connect_time":  uint64(0),
+			"idle_time":     uint64(2),
+			"rpcsends":      uint64(39),
+			"rpcreceives":   uint64(39),
+			"badxids":       uint64(0),
+			"inflightsends": uint64(42),
+			"backlogutil":   uint64(0),
+			"max_slots":     uint64(2),
+			"sending_queue": uint64(0),
+			"pending_queue": uint64(3),
+		},
+		map[string]string{"mountpoint": "/D", "serverexport": "nfsserver2:/tank/os2warp", "connection_index": "0"},
+	)
+	acc.AssertContainsTaggedFields(t, "nfs_ops",
+		map[string]interface{}{
+			"ops":           uint64(1),
+			"trans":         uint64(1),
+			"timeouts":      uint64(0),
+			"bytes_sent":    uint64(44),
+			"bytes_recv":    uint64(24),
+			"queue_time":    uint64(0),
+			"response_time": uint64(0),
+			"total_time":    uint64(1),
+			"errors":        uint64(0),
+		},
+		map[string]string{"mountpoint": "/D", "serverexport": "nfsserver2:/tank/os2warp", "operation": "NULL", "transport": "tcp"},
+	)
+}
+
+func TestNFSClientCollect(t *testing.T) {
+	var acc testutil.Accumulator
+
+	nfsclient := NFSClient{Collect: []string{"xprt"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	file, err := os.Open(getMountStatsPath())
+	require.NoError(t, err)
+	defer file.Close()
+
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(file), &acc))
+
+	require.True(t, acc.HasMeasurement("nfsstat"))
+	require.True(t, acc.HasMeasurement("nfs_xprt_tcp"))
+	require.False(t, acc.HasMeasurement("nfs_events"))
+	require.False(t, acc.HasMeasurement("nfs_bytes"))
+	require.False(t, acc.HasMeasurement("nfs_ops"))
+}
+
+func TestNFSClientInvalidCollect(t *testing.T) {
+	nfsclient := &NFSClient{
+		Collect: []string{"xprt", "foo"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, nfsclient.Init(), `invalid 'collect' value "foo"`)
+}
+
+func TestNFSClientRDMA(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs4 statvers=1.1
+	opts:	rw,vers=4.1,proto=rdma,port=20049
+	age:	100
+	RPC iostats version: 1.1  p/v: 100003/4 (nfs)
+	xprt:	rdma 0 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20 21 22 23 24 25 26
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+`
+
+	nfsclient := NFSClient{Collect: []string{"xprt"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_mount",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+			map[string]interface{}{"mount_age_seconds": uint64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_xprt_rdma",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "connection_index": "0"},
+			map[string]interface{}{
+				"bind_count":             uint64(1),
+				"connect_count":          uint64(2),
+				"connect_time":           uint64(3),
+				"idle_time":              uint64(4),
+				"rpcsends":               uint64(5),
+				"rpcreceives":            uint64(6),
+				"badxids":                uint64(7),
+				"inflightsends":          uint64(8),
+				"backlogutil":            uint64(9),
+				"read_chunk_count":       uint64(10),
+				"write_chunk_count":      uint64(11),
+				"reply_chunk_count":      uint64(12),
+				"total_rdma_request":     uint64(13),
+				"total_rdma_reply":       uint64(14),
+				"pullup_copy_count":      uint64(15),
+				"fixup_copy_count":       uint64(16),
+				"hardway_register_count": uint64(17),
+				"failed_marshal_count":   uint64(18),
+				"bad_reply_count":        uint64(19),
+				"nomsg_call_count":       uint64(20),
+				"mrs_recycled":           uint64(21),
+				"mrs_orphaned":           uint64(22),
+				"mrs_allocated":          uint64(23),
+				"local_inv_needed":       uint64(24),
+				"empty_sendctx_q":        uint64(25),
+				"reply_waits_for_send":   uint64(26),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsstat",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "operation": "READ", "transport": "rdma"},
+			map[string]interface{}{
+				"ops":        uint64(100),
+				"retrans":    uint64(1),
+				"bytes":      uint64(3000),
+				"rtt":        uint64(200),
+				"exe":        uint64(300),
+				"rtt_per_op": float64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNFSClientPNFSLayout(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs4 statvers=1.1
+	opts:	rw,vers=4.2,proto=tcp,port=2049
+	age:	100
+	pnfs:	LAYOUT_FLEX_FILES
+	RPC iostats version: 1.1  p/v: 100003/4 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+	 LAYOUTSTATS: 4 4 0 800 400 1 10 11 0
+	ds 192.168.1.10:2049 read: 100 409600 98 401408 8192 2 1500 1800
+	ds 192.168.1.10:2049 write: 50 204800 50 204800 0 0 900 1100
+	ds 192.168.1.11:2049 read: 10 40960
+`
+
+	nfsclient := NFSClient{Collect: []string{"pnfs"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_mount",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+			map[string]interface{}{"mount_age_seconds": uint64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsstat",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "operation": "READ", "transport": "tcp"},
+			map[string]interface{}{
+				"ops":        uint64(100),
+				"retrans":    uint64(1),
+				"bytes":      uint64(3000),
+				"rtt":        uint64(200),
+				"exe":        uint64(300),
+				"rtt_per_op": float64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_pnfs_layout",
+			map[string]string{
+				"serverexport": "filer:/vol/a",
+				"mountpoint":   "/mnt/a",
+				"data_server":  "192.168.1.10:2049",
+				"operation":    "READ",
+				"layout":       "flexfiles",
+			},
+			map[string]interface{}{
+				"ops_requested":       uint64(100),
+				"bytes_requested":     uint64(409600),
+				"ops_completed":       uint64(98),
+				"bytes_completed":     uint64(401408),
+				"bytes_not_delivered": uint64(8192),
+				"errors":              uint64(2),
+				"busy_time":           uint64(1500),
+				"completion_time":     uint64(1800),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_pnfs_layout",
+			map[string]string{
+				"serverexport": "filer:/vol/a",
+				"mountpoint":   "/mnt/a",
+				"data_server":  "192.168.1.10:2049",
+				"operation":    "WRITE",
+				"layout":       "flexfiles",
+			},
+			map[string]interface{}{
+				"ops_requested":       uint64(50),
+				"bytes_requested":     uint64(204800),
+				"ops_completed":       uint64(50),
+				"bytes_completed":     uint64(204800),
+				"bytes_not_delivered": uint64(0),
+				"errors":              uint64(0),
+				"busy_time":           uint64(900),
+				"completion_time":     uint64(1100),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNFSClientResolveServerNames(t *testing.T) {
+	data := `device 10.0.0.1:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+device [fd00::2]:/vol/b mounted on /mnt/b with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp6
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+device filer3:/vol/c mounted on /mnt/c with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+`
+
+	nfsclient := NFSClient{ResolveServerNames: true, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	var lookups int
+	nfsclient.resolver.lookup = func(_ context.Context, addr string) ([]string, error) {
+		lookups++
+		if addr == "10.0.0.1" {
+			return []string{"filer1.example.org."}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	for range 2 {
+		var acc testutil.Accumulator
+		require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+		names := make(map[string]string)
+		for _, m := range acc.GetTelegrafMetrics() {
+			export, _ := m.GetTag("serverexport")
+			name, _ := m.GetTag("server_name")
+			names[export] = name
+		}
+		expected := map[string]string{
+			"10.0.0.1:/vol/a":  "filer1.example.org",
+			"[fd00::2]:/vol/b": "fd00::2",
+			"filer3:/vol/c":    "filer3",
+		}
+		require.Equal(t, expected, names)
+	}
+
+	// Both successful and failed lookups should be cached
+	require.Equal(t, 2, lookups)
+}
+
+func TestNFSClientResolveCacheExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var lookups int
+	resolver := newServerNameResolver(time.Minute)
+	resolver.now = func() time.Time { return now }
+	resolver.lookup = func(context.Context, string) ([]string, error) {
+		lookups++
+		return []string{fmt.Sprintf("filer%d.", lookups)}, nil
+	}
+
+	require.Equal(t, "filer1", resolver.resolve("10.0.0.1:/vol/a"))
+	now = now.Add(30 * time.Second)
+	require.Equal(t, "filer1", resolver.resolve("10.0.0.1:/vol/a"))
+	now = now.Add(time.Minute)
+	require.Equal(t, "filer2", resolver.resolve("10.0.0.1:/vol/a"))
+	require.Equal(t, 2, lookups)
+}
+
+func TestNFSClientMeasurementMap(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	1136770
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	bytes:	1 2 3 4 5 6 7 8
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+`
+
+	nfsclient := NFSClient{
+		Collect:        []string{"bytes"},
+		MeasurementMap: map[string]string{"nfsstat": "nfs_client", "nfs_mount": "nfs_client_mount"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+	names := make([]string, 0, len(acc.Metrics))
+	for _, m := range acc.GetTelegrafMetrics() {
+		names = append(names, m.Name())
+	}
+	require.ElementsMatch(t, []string{"nfs_bytes", "nfs_client", "nfs_client_mount"}, names)
+}
+
+func TestNFSClientInvalidMeasurementMap(t *testing.T) {
+	nfsclient := NFSClient{MeasurementMap: map[string]string{"nfs_foo": "bar"}, Log: testutil.Logger{}}
+	require.ErrorContains(t, nfsclient.Init(), `unknown measurement "nfs_foo" in 'measurement_map'`)
+
+	nfsclient = NFSClient{MeasurementMap: map[string]string{"nfsstat": ""}, Log: testutil.Logger{}}
+	require.ErrorContains(t, nfsclient.Init(), `empty name for measurement "nfsstat" in 'measurement_map'`)
+}
+
+func TestNFSClientNFSFS(t *testing.T) {
+	nfsclient := NFSClient{Collect: []string{"nfsfs"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+	nfsclient.nfsfsPath = "./testdata/nfsfs"
+	nfsclient.mountinfoPath = "./testdata/nfsfs/mountinfo"
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.gatherNFSFS(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_volume",
+			map[string]string{"version": "v4", "server": "10.0.0.1", "port": "2049", "device": "0:53", "fsid": "a3b5c0d3e4f2a1b0:9c8d7e6f5a4b3c2d"},
+			map[string]interface{}{"fscache": false, "mounts": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_volume",
+			map[string]string{"version": "v4", "server": "10.0.0.1", "port": "2049", "device": "0:54", "fsid": "a3b5c0d3e4f2a1b0:1a2b3c4d5e6f7a8b"},
+			map[string]interface{}{"fscache": true, "mounts": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_volume",
+			map[string]string{"version": "v3", "server": "10.0.0.2", "port": "2049", "device": "0:55", "fsid": "9c8d7e6f5a4b3c2d"},
+			map[string]interface{}{"fscache": false, "mounts": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_volume",
+			map[string]string{"version": "v4", "server": "fd00::2", "port": "2049", "device": "0:56", "fsid": "1:1"},
+			map[string]interface{}{"fscache": false, "mounts": 0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_server",
+			map[string]string{"version": "v4", "server": "10.0.0.1", "port": "2049", "hostname": "filer1"},
+			map[string]interface{}{"use_count": uint64(3), "volumes": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_server",
+			map[string]string{"version": "v3", "server": "10.0.0.2", "port": "2049", "hostname": "10.0.0.2"},
+			map[string]interface{}{"use_count": uint64(1), "volumes": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_server",
+			map[string]string{"version": "v4", "server": "fd00::2", "port": "2049", "hostname": "filer6"},
+			map[string]interface{}{"use_count": uint64(1), "volumes": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Without mount information the number of mounts is omitted
+	nfsclient.mountinfoPath = "/does_not_exist"
+	acc.ClearMetrics()
+	require.NoError(t, nfsclient.gatherNFSFS(&acc))
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.False(t, m.HasField("mounts"))
+	}
+}
+
+func TestNFSClientNFSFSInvalidAddress(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "servers"), []byte("NV SERVER   PORT USE HOSTNAME\nv4 0a0000  801   1 filer\n"), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "volumes"), nil, 0o640))
+
+	nfsclient := NFSClient{Collect: []string{"nfsfs"}, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+	nfsclient.nfsfsPath = dir
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, nfsclient.gatherNFSFS(&acc), `invalid server address "0a0000"`)
+}
+
+func TestNFSClientMountEvents(t *testing.T) {
+	mountA := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+`
+	mountB := `device filer:/vol/b mounted on /mnt/b with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+`
+
+	nfsclient := NFSClient{MountEvents: true, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	gatherEvents := func(data string) []telegraf.Metric {
+		var acc testutil.Accumulator
+		require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+		var events []telegraf.Metric
+		for _, m := range acc.GetTelegrafMetrics() {
+			if m.Name() == "nfs_mount_event" {
+				events = append(events, m)
+			}
+		}
+		return events
+	}
+
+	// Mounts present at startup are considered known
+	require.Empty(t, gatherEvents(mountA))
+	require.Empty(t, gatherEvents(mountA))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_mount_event",
+			map[string]string{"mountpoint": "/mnt/b", "serverexport": "filer:/vol/b"},
+			map[string]interface{}{"action": "added"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfs_mount_event",
+			map[string]string{"mountpoint": "/mnt/a", "serverexport": "filer:/vol/a"},
+			map[string]interface{}{"action": "removed"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, gatherEvents(mountB), testutil.IgnoreTime())
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"nfs_mount_event",
+			map[string]string{"mountpoint": "/mnt/a", "serverexport": "filer:/vol/a"},
+			map[string]interface{}{"action": "added"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, gatherEvents(mountA+mountB), testutil.IgnoreTime())
+}
+
+func TestNFSClientTimeout(t *testing.T) {
+	// Simulate a hung mount blocking after the first mount and in the middle
+	// of a line of the second mount
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101 0 1000 2000 50 200 300 0
+device filer:/vol/b mounted on /mnt/b with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	per-op statistics
+	        READ: 100 101`
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		//nolint:errcheck // The reader is closed when the test ends
+		pw.Write([]byte(data))
+	}()
+
+	nfsclient := NFSClient{Timeout: config.Duration(100 * time.Millisecond), HealthCheck: true, MountEvents: true, Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+
+	open := func() (io.ReadCloser, error) { return pr, nil }
+	reader := newTimeoutReader(open, time.Duration(nfsclient.Timeout))
+	defer reader.Close()
+
+	var acc testutil.Accumulator
+	scanner := bufio.NewScanner(reader)
+	require.NoError(t, nfsclient.processText(scanner, &acc))
+	require.ErrorIs(t, scanner.Err(), errReadTimeout)
+
+	// Only the complete statistics should be emitted and the checks relying
+	// on all mounts should be skipped
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfsstat",
+			map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "operation": "READ", "transport": "tcp"},
+			map[string]interface{}{
+				"ops":        uint64(100),
+				"retrans":    uint64(1),
+				"bytes":      uint64(3000),
+				"rtt":        uint64(200),
+				"exe":        uint64(300),
+				"rtt_per_op": float64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Nil(t, nfsclient.mountStates)
+	require.Nil(t, nfsclient.knownMounts)
+}
+
+func TestNFSClientGatherTimeout(t *testing.T) {
+	nfsclient := NFSClient{Timeout: config.Duration(5 * time.Second), Log: testutil.Logger{}}
+	require.NoError(t, nfsclient.Init())
+	nfsclient.mountstatsPath = getMountStatsPath()
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.NotEmpty(t, acc.GetTelegrafMetrics())
+	require.Zero(t, nfsclient.incompleteGathers.Get())
+}
+
+func TestNFSClientMountAge(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	3600
+	RPC iostats version: 1.0  p/v: 100003/3 (nfs)
+device filer:/vol/a mounted on /mnt/b with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp
+	age:	60
+	RPC iostats version: 1.0  p/v: 100003/3 (nfs)
+`
+
+	tests := []struct {
+		name        string
+		aggregateBy string
+		expected    []telegraf.Metric
+	}{
+		{
+			name: "per mount",
+			expected: []telegraf.Metric{
+				metric.New(
+					"nfs_mount",
+					map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+					map[string]interface{}{"mount_age_seconds": uint64(3600)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"nfs_mount",
+					map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/b"},
+					map[string]interface{}{"mount_age_seconds": uint64(60)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:        "per server",
+			aggregateBy: "server",
+			expected: []telegraf.Metric{
+				metric.New(
+					"nfs_mount",
+					map[string]string{"serverexport": "filer:/vol/a"},
+					map[string]interface{}{"mount_age_seconds": uint64(60)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nfsclient := NFSClient{AggregateBy: tt.aggregateBy, Log: testutil.Logger{}}
+			require.NoError(t, nfsclient.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestNFSClientXprtConnections(t *testing.T) {
+	data := `device filer:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	opts:	rw,vers=3,proto=tcp,nconnect=2
+	RPC iostats version: 1.1  p/v: 100003/3 (nfs)
+	xprt:	tcp 0 1 2 0 4 100 100 0 500 0 64 0 0
+	xprt:	tcp 0 1 1 0 8 300 300 0 700 0 64 2 0
+`
+
+	tests := []struct {
+		name        string
+		connections string
+		expected    []telegraf.Metric
+	}{
+		{
+			name: "split",
+			expected: []telegraf.Metric{
+				metric.New(
+					"nfs_xprt_tcp",
+					map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "connection_index": "0"},
+					map[string]interface{}{
+						"bind_count":    uint64(1),
+						"connect_count": uint64(2),
+						"connect_time":  uint64(0),
+						"idle_time":     uint64(4),
+						"rpcsends":      uint64(100),
+						"rpcreceives":   uint64(100),
+						"badxids":       uint64(0),
+						"inflightsends": uint64(500),
+						"backlogutil":   uint64(0),
+						"max_slots":     uint64(64),
+						"sending_queue": uint64(0),
+						"pending_queue": uint64(0),
+					},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"nfs_xprt_tcp",
+					map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a", "connection_index": "1"},
+					map[string]interface{}{
+						"bind_count":    uint64(1),
+						"connect_count": uint64(1),
+						"connect_time":  uint64(0),
+						"idle_time":     uint64(8),
+						"rpcsends":      uint64(300),
+						"rpcreceives":   uint64(300),
+						"badxids":       uint64(0),
+						"inflightsends": uint64(700),
+						"backlogutil":   uint64(0),
+						"max_slots":     uint64(64),
+						"sending_queue": uint64(2),
+						"pending_queue": uint64(0),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:        "sum",
+			connections: "sum",
+			expected: []telegraf.Metric{
+				metric.New(
+					"nfs_xprt_tcp",
+					map[string]string{"serverexport": "filer:/vol/a", "mountpoint": "/mnt/a"},
+					map[string]interface{}{
+						"bind_count":    uint64(2),
+						"connect_count": uint64(3),
+						"connect_time":  uint64(0),
+						"idle_time":     uint64(6),
+						"rpcsends":      uint64(400),
+						"rpcreceives":   uint64(400),
+						"badxids":       uint64(0),
+						"inflightsends": uint64(1200),
+						"backlogutil":   uint64(0),
+						"max_slots":     uint64(128),
+						"sending_queue": uint64(2),
+						"pending_queue": uint64(0),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nfsclient := NFSClient{
+				Collect:         []string{"xprt"},
+				XprtConnections: tt.connections,
+				Log:             testutil.Logger{},
+			}
+			require.NoError(t, nfsclient.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestNFSClientInvalidXprtConnections(t *testing.T) {
+	nfsclient := NFSClient{XprtConnections: "foo", Log: testutil.Logger{}}
+	require.ErrorContains(t, nfsclient.Init(), `invalid 'xprt_connections' value "foo"`)
+}
+
+func TestNFSClientServerFilter(t *testing.T) {
+	data := `device filer1.example.com:/vol/a mounted on /mnt/a with fstype nfs statvers=1.1
+	age:	10
+device 10.1.2.3:/vol/b mounted on /mnt/b with fstype nfs statvers=1.1
+	age:	20
+device 10.2.0.1:/vol/c mounted on /mnt/c with fstype nfs4 statvers=1.1
+	age:	30
+device [fd00::1]:/vol/d mounted on /mnt/d with fstype nfs4 statvers=1.1
+	age:	40
+`
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"/mnt/a", "/mnt/b", "/mnt/c", "/mnt/d"},
+		},
+		{
+			name:     "include cidr",
+			include:  []string{"10.1.0.0/16"},
+			expected: []string{"/mnt/b"},
+		},
+		{
+			name:     "exclude cidr",
+			exclude:  []string{"10.0.0.0/8"},
+			expected: []string{"/mnt/a", "/mnt/d"},
+		},
+		{
+			name:     "include hostname glob and ip",
+			include:  []string{"FILER*.example.com", "10.2.0.1"},
+			expected: []string{"/mnt/a", "/mnt/c"},
+		},
+		{
+			name:     "include ipv6 exclude hostname",
+			include:  []string{"fd00::/64", "filer1.example.com"},
+			exclude:  []string{"filer1.example.com"},
+			expected: []string{"/mnt/d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nfsclient := NFSClient{
+				IncludeServers: tt.include,
+				ExcludeServers: tt.exclude,
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, nfsclient.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, nfsclient.processText(bufio.NewScanner(strings.NewReader(data)), &acc))
+
+			actual := make([]string, 0, len(tt.expected))
+			for _, m := range acc.GetTelegrafMetrics() {
+				mountpoint, _ := m.GetTag("mountpoint")
+				actual = append(actual, mountpoint)
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestNFSClientInvalidServerFilter(t *testing.T) {
+	nfsclient := NFSClient{IncludeServers: []string{"10.1.2.0/33"}, Log: testutil.Logger{}}
+	require.ErrorContains(t, nfsclient.Init(), `invalid CIDR range "10.1.2.0/33"`)
+}
@@ -0,0 +1,223 @@
+package nfsclient
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// nfsfsServer is an entry of /proc/fs/nfsfs/servers, i.e. a client
+// connection to a NFS server shared by all superblocks of that server
+type nfsfsServer struct {
+	version  string
+	server   string
+	port     string
+	useCount uint64
+	hostname string
+}
+
+// nfsfsVolume is an entry of /proc/fs/nfsfs/volumes, i.e. a superblock
+type nfsfsVolume struct {
+	version string
+	server  string
+	port    string
+	device  string
+	fsid    string
+	fscache string
+}
+
+// gatherNFSFS emits the per-server and per-volume metrics of the NFS client
+// connections listed below /proc/fs/nfsfs
+func (n *NFSClient) gatherNFSFS(acc telegraf.Accumulator) error {
+	if len(n.MeasurementMap) > 0 {
+		acc = &measurementAccumulator{Accumulator: acc, mapping: n.MeasurementMap}
+	}
+
+	servers, err := readNFSFSServers(filepath.Join(n.nfsfsPath, "servers"))
+	if err != nil {
+		return err
+	}
+	volumes, err := readNFSFSVolumes(filepath.Join(n.nfsfsPath, "volumes"))
+	if err != nil {
+		return err
+	}
+
+	// The mount information is optional and only used to determine the
+	// number of mounts sharing a superblock
+	mounts, err := readMountDevices(n.mountinfoPath)
+	if err != nil {
+		n.Log.Debugf("Reading mount information failed: %v", err)
+	}
+
+	superblocks := make(map[string]int, len(servers))
+	for _, v := range volumes {
+		superblocks[v.version+"\x00"+v.server+"\x00"+v.port]++
+
+		tags := map[string]string{
+			"version": v.version,
+			"server":  v.server,
+			"port":    v.port,
+			"device":  v.device,
+			"fsid":    v.fsid,
+		}
+		fields := make(map[string]interface{}, 2)
+		if v.fscache != "" {
+			fields["fscache"] = v.fscache == "yes"
+		}
+		if mounts != nil {
+			fields["mounts"] = mounts[v.device]
+		}
+		acc.AddFields("nfs_volume", fields, tags)
+	}
+
+	for _, s := range servers {
+		tags := map[string]string{
+			"version": s.version,
+			"server":  s.server,
+			"port":    s.port,
+		}
+		if s.hostname != "" {
+			tags["hostname"] = s.hostname
+		}
+		fields := map[string]interface{}{
+			"use_count": s.useCount,
+			"volumes":   superblocks[s.version+"\x00"+s.server+"\x00"+s.port],
+		}
+		acc.AddFields("nfs_server", fields, tags)
+	}
+
+	return nil
+}
+
+// readNFSFSServers parses the server list of the form
+//
+//	NV SERVER   PORT USE HOSTNAME
+//	v4 0a000001  801   2 filer
+func readNFSFSServers(path string) ([]nfsfsServer, error) {
+	var servers []nfsfsServer
+	err := scanNFSFSFile(path, 4, func(line []string) error {
+		server, port, err := parseNFSFSAddress(line[1], line[2])
+		if err != nil {
+			return err
+		}
+		useCount, err := strconv.ParseUint(line[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing use count %q failed: %w", line[3], err)
+		}
+		s := nfsfsServer{
+			version:  line[0],
+			server:   server,
+			port:     port,
+			useCount: useCount,
+		}
+		if len(line) > 4 {
+			s.hostname = line[4]
+		}
+		servers = append(servers, s)
+		return nil
+	})
+	return servers, err
+}
+
+// readNFSFSVolumes parses the volume list of the form
+//
+//	NV SERVER   PORT DEV          FSID                              FSC
+//	v4 0a000001  801 0:53         a3b5c0d3e4f2a1b0:9c8d7e6f5a4b3c2d no
+//
+// where the FSC column is missing in older kernels
+func readNFSFSVolumes(path string) ([]nfsfsVolume, error) {
+	var volumes []nfsfsVolume
+	err := scanNFSFSFile(path, 5, func(line []string) error {
+		server, port, err := parseNFSFSAddress(line[1], line[2])
+		if err != nil {
+			return err
+		}
+		v := nfsfsVolume{
+			version: line[0],
+			server:  server,
+			port:    port,
+			device:  line[3],
+			fsid:    line[4],
+		}
+		if len(line) > 5 {
+			v.fscache = line[5]
+		}
+		volumes = append(volumes, v)
+		return nil
+	})
+	return volumes, err
+}
+
+// scanNFSFSFile calls the given function for each line of the file with at
+// least the given number of columns skipping the header line
+func scanNFSFSFile(path string, columns int, fn func(line []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < columns || line[0] == "NV" {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("parsing %q failed: %w", path, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseNFSFSAddress converts the hexadecimal server address and port to
+// their usual textual representation
+func parseNFSFSAddress(addr, port string) (server, p string, err error) {
+	raw, err := hex.DecodeString(addr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", "", fmt.Errorf("invalid server address %q", addr)
+	}
+	num, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid server port %q", port)
+	}
+	return net.IP(raw).String(), strconv.FormatUint(num, 10), nil
+}
+
+// readMountDevices returns the number of NFS mounts per device number read
+// from a mountinfo file of the form
+//
+//	36 25 0:53 / /mnt/a rw,relatime shared:1 - nfs4 filer:/vol/a rw,vers=4.2
+func readMountDevices(path string) (map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mounts := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < 3 {
+			continue
+		}
+		// The filesystem type follows the separator of the optional fields
+		for i := 6; i < len(line)-1; i++ {
+			if line[i] == "-" {
+				if line[i+1] == "nfs" || line[i+1] == "nfs4" {
+					mounts[line[2]]++
+				}
+				break
+			}
+		}
+	}
+	return mounts, scanner.Err()
+}
@@ -0,0 +1,53 @@
+package nfsclient
+
+import (
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Columns of the per data-server I/O statistics of the flexfiles layout
+// driver, the same counters are reported to the metadata server via the
+// LAYOUTSTATS operation. The lines have the form
+//
+//	ds <address> <read|write>: <counters...>
+var pnfsLayoutFields = []string{
+	"ops_requested",
+	"bytes_requested",
+	"ops_completed",
+	"bytes_completed",
+	"bytes_not_delivered",
+	"errors",
+	"busy_time",
+	"completion_time",
+}
+
+// parseLayoutStat emits the I/O statistics of a single pNFS data server and
+// direction of the given mount.
+func (*NFSClient) parseLayoutStat(mount mountInfo, line []string, nline []uint64, acc telegraf.Accumulator) {
+	// The first two converted columns are the address and the direction
+	if len(line) < 3 || len(nline)-2 < len(pnfsLayoutFields) {
+		return
+	}
+
+	direction := strings.ToUpper(strings.TrimSuffix(line[2], ":"))
+	if direction != "READ" && direction != "WRITE" {
+		return
+	}
+
+	tags := map[string]string{
+		"mountpoint":   mount.mountpoint,
+		"serverexport": mount.export,
+		"data_server":  line[1],
+		"operation":    direction,
+	}
+	if mount.layout != "" {
+		tags["layout"] = mount.layout
+	}
+
+	fields := make(map[string]interface{}, len(pnfsLayoutFields))
+	for i, t := range pnfsLayoutFields {
+		fields[t] = nline[i+2]
+	}
+	acc.AddFields("nfs_pnfs_layout", fields, tags)
+}
@@ -0,0 +1,222 @@
+package nfsclient
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// remoteHost describes a machine to read the mountstats from via SSH
+type remoteHost struct {
+	Address               string          `toml:"address"`
+	Username              config.Secret   `toml:"username"`
+	Password              config.Secret   `toml:"password"`
+	PrivateKey            string          `toml:"private_key"`
+	PrivateKeyPassphrase  config.Secret   `toml:"private_key_passphrase"`
+	UseAgent              bool            `toml:"use_agent"`
+	KnownHosts            string          `toml:"known_hosts"`
+	InsecureIgnoreHostKey bool            `toml:"insecure_ignore_host_key"`
+	MountstatsPath        string          `toml:"mountstats_path"`
+	Timeout               config.Duration `toml:"timeout"`
+
+	host   string
+	auth   []ssh.AuthMethod
+	verify ssh.HostKeyCallback
+	// Each host uses its own client instance to keep the state of the
+	// health check and latency histograms separated
+	client *NFSClient
+}
+
+func (r *remoteHost) init(parent *NFSClient) error {
+	if r.Address == "" {
+		return errors.New("address required")
+	}
+	if _, _, err := net.SplitHostPort(r.Address); err != nil {
+		r.Address = net.JoinHostPort(r.Address, "22")
+	}
+	r.host, _, _ = net.SplitHostPort(r.Address)
+
+	if r.Username.Empty() {
+		return errors.New("username required")
+	}
+	if r.MountstatsPath == "" {
+		r.MountstatsPath = "/proc/self/mountstats"
+	}
+	if r.Timeout <= 0 {
+		r.Timeout = config.Duration(10 * time.Second)
+	}
+
+	// Setup the host-key verification
+	switch {
+	case r.InsecureIgnoreHostKey:
+		r.verify = ssh.InsecureIgnoreHostKey() //nolint:gosec // explicitly requested by the user
+	case r.KnownHosts != "":
+		verify, err := knownhosts.New(r.KnownHosts)
+		if err != nil {
+			return fmt.Errorf("reading known hosts failed: %w", err)
+		}
+		r.verify = verify
+	default:
+		return errors.New("either 'known_hosts' or 'insecure_ignore_host_key' must be set")
+	}
+
+	// Setup the authentication methods in order of preference
+	if r.PrivateKey != "" {
+		signer, err := r.loadPrivateKey()
+		if err != nil {
+			return err
+		}
+		r.auth = append(r.auth, ssh.PublicKeys(signer))
+	}
+	if r.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return errors.New("SSH agent requested but 'SSH_AUTH_SOCK' is not set")
+		}
+		r.auth = append(r.auth, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				return nil, fmt.Errorf("connecting to SSH agent failed: %w", err)
+			}
+			defer conn.Close()
+			return agent.NewClient(conn).Signers()
+		}))
+	}
+	if !r.Password.Empty() {
+		r.auth = append(r.auth, ssh.PasswordCallback(func() (string, error) {
+			password, err := r.Password.Get()
+			if err != nil {
+				return "", fmt.Errorf("getting password failed: %w", err)
+			}
+			defer password.Destroy()
+			return password.String(), nil
+		}))
+	}
+	if len(r.auth) == 0 {
+		return errors.New("no authentication method configured")
+	}
+
+	client := *parent
+	client.RemoteHosts = nil
+	client.mountStates = nil
+	client.latencyStates = nil
+	client.iostatStates = nil
+	r.client = &client
+
+	return nil
+}
+
+func (r *remoteHost) loadPrivateKey() (ssh.Signer, error) {
+	key, err := os.ReadFile(r.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key failed: %w", err)
+	}
+
+	if r.PrivateKeyPassphrase.Empty() {
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parsing private key failed: %w", err)
+		}
+		return signer, nil
+	}
+
+	passphrase, err := r.PrivateKeyPassphrase.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting private key passphrase failed: %w", err)
+	}
+	defer passphrase.Destroy()
+
+	signer, err := ssh.ParsePrivateKeyWithPassphrase(key, passphrase.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key failed: %w", err)
+	}
+	return signer, nil
+}
+
+// read fetches the content of the mountstats file from the remote host
+func (r *remoteHost) read() ([]byte, error) {
+	username, err := r.Username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+
+	cfg := &ssh.ClientConfig{
+		User:            username.String(),
+		Auth:            r.auth,
+		HostKeyCallback: r.verify,
+		Timeout:         time.Duration(r.Timeout),
+	}
+	conn, err := ssh.Dial("tcp", r.Address, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connecting failed: %w", err)
+	}
+	defer conn.Close()
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("creating session failed: %w", err)
+	}
+	defer session.Close()
+
+	// Quote the path for the remote shell
+	path := "'" + strings.ReplaceAll(r.MountstatsPath, "'", `'\''`) + "'"
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	out, err := session.Output("cat " + path)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("reading %q failed: %w (%s)", r.MountstatsPath, err, msg)
+		}
+		return nil, fmt.Errorf("reading %q failed: %w", r.MountstatsPath, err)
+	}
+
+	return out, nil
+}
+
+func (n *NFSClient) gatherRemote(acc telegraf.Accumulator) {
+	var wg sync.WaitGroup
+	for _, r := range n.RemoteHosts {
+		wg.Add(1)
+		go func(r *remoteHost) {
+			defer wg.Done()
+
+			content, err := r.read()
+			if err != nil {
+				acc.AddError(fmt.Errorf("gathering from %q failed: %w", r.Address, err))
+				return
+			}
+
+			hostAcc := &hostAccumulator{Accumulator: acc, host: r.host}
+			scanner := bufio.NewScanner(bytes.NewReader(content))
+			if err := r.client.processText(scanner, hostAcc); err != nil {
+				acc.AddError(fmt.Errorf("processing data of %q failed: %w", r.Address, err))
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// hostAccumulator adds the remote host's name as tag to all metrics
+type hostAccumulator struct {
+	telegraf.Accumulator
+	host string
+}
+
+func (a *hostAccumulator) AddFields(name string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	tags["host"] = a.host
+	a.Accumulator.AddFields(name, fields, tags, t...)
+}
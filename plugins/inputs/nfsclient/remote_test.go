@@ -0,0 +1,198 @@
+package nfsclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// startSSHServer starts a minimal SSH server accepting the given password and
+// answering all commands with the given content.
+func startSSHServer(t *testing.T, password string, content []byte) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) != password {
+				return nil, errors.New("invalid password")
+			}
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, cfg, content)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveSSH(conn net.Conn, cfg *ssh.ServerConfig, content []byte) {
+	defer conn.Close()
+
+	_, channels, requests, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, reqs, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		for req := range reqs {
+			if req.Type != "exec" {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			_, _ = channel.Write(content)
+			status := make([]byte, 4)
+			binary.BigEndian.PutUint32(status, 0)
+			_, _ = channel.SendRequest("exit-status", false, status)
+			channel.Close()
+		}
+	}
+}
+
+func TestNFSClientRemote(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "mountstats"))
+	require.NoError(t, err)
+	addr := startSSHServer(t, "secret", content)
+
+	nfsclient := &NFSClient{
+		IncludeMounts: []string{"^/A$"},
+		RemoteHosts: []*remoteHost{
+			{
+				Address:               addr,
+				Username:              config.NewSecret([]byte("telegraf")),
+				Password:              config.NewSecret([]byte("secret")),
+				InsecureIgnoreHostKey: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	acc.AssertContainsTaggedFields(t, "nfsstat",
+		map[string]interface{}{
+			"ops":        uint64(600),
+			"retrans":    uint64(1),
+			"bytes":      uint64(1207),
+			"rtt":        uint64(606),
+			"exe":        uint64(607),
+			"rtt_per_op": float64(1.01),
+		},
+		map[string]string{
+			"host":         "127.0.0.1",
+			"serverexport": "1.2.3.4:/storage/NFS",
+			"mountpoint":   "/A",
+			"operation":    "READ",
+			"transport":    "tcp",
+		},
+	)
+}
+
+func TestNFSClientRemoteAuthFailure(t *testing.T) {
+	addr := startSSHServer(t, "secret", nil)
+
+	nfsclient := &NFSClient{
+		RemoteHosts: []*remoteHost{
+			{
+				Address:               addr,
+				Username:              config.NewSecret([]byte("telegraf")),
+				Password:              config.NewSecret([]byte("wrong")),
+				InsecureIgnoreHostKey: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, nfsclient.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, nfsclient.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "connecting failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestNFSClientRemoteInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     *remoteHost
+		expected string
+	}{
+		{
+			name:     "no address",
+			host:     &remoteHost{},
+			expected: "address required",
+		},
+		{
+			name:     "no username",
+			host:     &remoteHost{Address: "localhost"},
+			expected: "username required",
+		},
+		{
+			name: "no host key verification",
+			host: &remoteHost{
+				Address:  "localhost",
+				Username: config.NewSecret([]byte("telegraf")),
+				Password: config.NewSecret([]byte("secret")),
+			},
+			expected: "either 'known_hosts' or 'insecure_ignore_host_key' must be set",
+		},
+		{
+			name: "no authentication",
+			host: &remoteHost{
+				Address:               "localhost",
+				Username:              config.NewSecret([]byte("telegraf")),
+				InsecureIgnoreHostKey: true,
+			},
+			expected: "no authentication method configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nfsclient := &NFSClient{
+				RemoteHosts: []*remoteHost{tt.host},
+				Log:         testutil.Logger{},
+			}
+			require.ErrorContains(t, nfsclient.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package nfsclient
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Maximum time to wait for a single reverse-DNS lookup
+const resolveTimeout = 2 * time.Second
+
+type resolvedName struct {
+	name    string
+	expires time.Time
+}
+
+// serverNameResolver resolves the server addresses of NFS exports to host
+// names, caching the results for the configured TTL. The resolver is shared
+// by the clients of all remote hosts and thus safe for concurrent use.
+type serverNameResolver struct {
+	ttl    time.Duration
+	lookup func(ctx context.Context, addr string) ([]string, error)
+	now    func() time.Time
+
+	cache map[string]resolvedName
+	mu    sync.Mutex
+}
+
+func newServerNameResolver(ttl time.Duration) *serverNameResolver {
+	return &serverNameResolver{
+		ttl:    ttl,
+		lookup: net.DefaultResolver.LookupAddr,
+		now:    time.Now,
+		cache:  make(map[string]resolvedName),
+	}
+}
+
+// resolve returns the name of the server of the given export. Exports
+// referencing the server by name are returned as-is and the address is used
+// as name if the lookup fails.
+func (r *serverNameResolver) resolve(export string) string {
+	addr := exportServer(export)
+	if addr == "" {
+		return ""
+	}
+	if net.ParseIP(addr) == nil {
+		return addr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if entry, found := r.cache[addr]; found && now.Before(entry.expires) {
+		return entry.name
+	}
+
+	// Also cache failed lookups to avoid querying the DNS server on each
+	// gather cycle
+	name := addr
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	if names, err := r.lookup(ctx, addr); err == nil && len(names) > 0 {
+		name = strings.TrimSuffix(names[0], ".")
+	}
+	r.cache[addr] = resolvedName{name: name, expires: now.Add(r.ttl)}
+
+	// Forget about expired entries of servers not in use anymore
+	for k, entry := range r.cache {
+		if !now.Before(entry.expires) {
+			delete(r.cache, k)
+		}
+	}
+
+	return name
+}
+
+// serverNameAccumulator adds the resolved name of the NFS server as tag to all
+// metrics containing a server export
+type serverNameAccumulator struct {
+	telegraf.Accumulator
+	resolver *serverNameResolver
+}
+
+func (a *serverNameAccumulator) AddFields(name string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if export, found := tags["serverexport"]; found {
+		if serverName := a.resolver.resolve(export); serverName != "" {
+			tags["server_name"] = serverName
+		}
+	}
+	a.Accumulator.AddFields(name, fields, tags, t...)
+}
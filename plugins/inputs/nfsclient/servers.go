@@ -0,0 +1,91 @@
+package nfsclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/influxdata/telegraf/filter"
+)
+
+// serverFilter matches the server of NFS exports against a list of host
+// names, IP addresses and CIDR ranges
+type serverFilter struct {
+	networks []*net.IPNet
+	names    filter.Filter
+}
+
+func newServerFilter(entries []string) (*serverFilter, error) {
+	f := &serverFilter{}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return nil, errors.New("empty server entry")
+		}
+
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q: %w", entry, err)
+			}
+			f.networks = append(f.networks, network)
+			continue
+		}
+
+		if ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(entry, "["), "]")); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			f.networks = append(f.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		// Host names are compared case-insensitive and may contain globs
+		names = append(names, strings.ToLower(entry))
+	}
+
+	if len(names) > 0 {
+		compiled, err := filter.Compile(names)
+		if err != nil {
+			return nil, fmt.Errorf("compiling server names failed: %w", err)
+		}
+		f.names = compiled
+	}
+
+	return f, nil
+}
+
+// match checks if the server of the given export matches any entry. IP and
+// CIDR entries only match exports referencing the server by address while
+// host name entries only match exports referencing the server by name, i.e.
+// no DNS lookups are performed.
+func (f *serverFilter) match(export string) bool {
+	server := exportServer(export)
+	if server == "" {
+		return false
+	}
+
+	if ip := net.ParseIP(server); ip != nil {
+		for _, network := range f.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return f.names != nil && f.names.Match(strings.ToLower(server))
+}
+
+// exportServer returns the server part of an export in the form
+// "server:/path" with IPv6 address brackets removed
+func exportServer(export string) string {
+	server, _, found := strings.Cut(export, ":/")
+	if !found {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(server, "["), "]")
+}
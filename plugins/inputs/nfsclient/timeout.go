@@ -0,0 +1,128 @@
+package nfsclient
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+var errReadTimeout = errors.New("read timed out")
+
+// gatherMountstatsWithTimeout processes the mountstats file until the end of
+// the file or until the configured timeout passed. In the latter case the
+// metrics of the mounts read so far are kept and the gather is counted as
+// incomplete.
+func (n *NFSClient) gatherMountstatsWithTimeout(acc telegraf.Accumulator) error {
+	open := func() (io.ReadCloser, error) { return os.Open(n.mountstatsPath) }
+	reader := newTimeoutReader(open, time.Duration(n.Timeout))
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	if err := n.processText(scanner, acc); err != nil {
+		return err
+	}
+
+	err := scanner.Err()
+	if errors.Is(err, errReadTimeout) {
+		n.incompleteGathers.Incr(1)
+		acc.AddError(fmt.Errorf("reading %q timed out after %s, metrics are incomplete", n.mountstatsPath, time.Duration(n.Timeout)))
+		return nil
+	}
+	return err
+}
+
+// lineResult is a single line read from the mountstats file or the error
+// that stopped reading
+type lineResult struct {
+	line []byte
+	err  error
+}
+
+// timeoutReader reads the lines of a file in a separate goroutine and
+// returns errReadTimeout if the deadline passed before the end of the file.
+// Reading mountstats can block indefinitely for hard-hung mounts, in this
+// case the goroutine is left behind as the read cannot be interrupted.
+type timeoutReader struct {
+	lines    chan lineResult
+	done     chan struct{}
+	deadline *time.Timer
+	timedOut bool
+	buf      []byte
+}
+
+func newTimeoutReader(open func() (io.ReadCloser, error), timeout time.Duration) *timeoutReader {
+	r := &timeoutReader{
+		lines:    make(chan lineResult),
+		done:     make(chan struct{}),
+		deadline: time.NewTimer(timeout),
+	}
+	go r.read(open)
+	return r
+}
+
+func (r *timeoutReader) read(open func() (io.ReadCloser, error)) {
+	defer close(r.lines)
+
+	send := func(res lineResult) bool {
+		select {
+		case r.lines <- res:
+			return true
+		case <-r.done:
+			return false
+		}
+	}
+
+	file, err := open()
+	if err != nil {
+		send(lineResult{err: err})
+		return
+	}
+	defer file.Close()
+
+	// Only pass on complete lines to avoid parsing truncated statistics
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := append(scanner.Bytes(), '\n')
+		if !send(lineResult{line: append([]byte(nil), line...)}) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		send(lineResult{err: err})
+	}
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	if r.timedOut {
+		return 0, errReadTimeout
+	}
+	if len(r.buf) == 0 {
+		select {
+		case res, ok := <-r.lines:
+			if !ok {
+				return 0, io.EOF
+			}
+			if res.err != nil {
+				return 0, res.err
+			}
+			r.buf = res.line
+		case <-r.deadline.C:
+			r.timedOut = true
+			return 0, errReadTimeout
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close stops the reading goroutine unless it is blocked in the kernel
+func (r *timeoutReader) Close() {
+	r.deadline.Stop()
+	close(r.done)
+}
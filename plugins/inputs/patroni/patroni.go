@@ -0,0 +1,249 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package patroni
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Patroni struct {
+	URLs          []string        `toml:"urls"`
+	GatherCluster bool            `toml:"gather_cluster"`
+	GatherHistory bool            `toml:"gather_history"`
+	Username      config.Secret   `toml:"username"`
+	Password      config.Secret   `toml:"password"`
+	Log           telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+func (*Patroni) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Patroni) Init() error {
+	if len(p.URLs) == 0 {
+		p.URLs = []string{"http://localhost:8008"}
+	}
+	for i, u := range p.URLs {
+		p.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	p.client = client
+
+	return nil
+}
+
+func (p *Patroni) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range p.URLs {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			if err := p.gatherURL(acc, address); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", address, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *Patroni) Stop() {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+}
+
+func (p *Patroni) gatherURL(acc telegraf.Accumulator, address string) error {
+	var status nodeStatus
+	if err := p.loadJSON(address+"/patroni", &status); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	tags := map[string]string{
+		"url":    address,
+		"scope":  status.Patroni.Scope,
+		"member": status.Patroni.Name,
+		"role":   status.Role,
+		"state":  status.State,
+	}
+	fields := map[string]interface{}{
+		"timeline":         status.Timeline,
+		"server_version":   status.ServerVersion,
+		"patroni_version":  status.Patroni.Version,
+		"pending_restart":  status.PendingRestart,
+		"cluster_unlocked": status.ClusterUnlocked,
+		"paused":           status.Pause,
+		"replicas":         len(status.Replication),
+	}
+	if status.Xlog.Location != nil {
+		fields["xlog_location"] = *status.Xlog.Location
+	}
+	if status.Xlog.ReceivedLocation != nil {
+		fields["xlog_received_location"] = *status.Xlog.ReceivedLocation
+	}
+	if status.Xlog.ReplayedLocation != nil {
+		fields["xlog_replayed_location"] = *status.Xlog.ReplayedLocation
+	}
+	if status.Xlog.ReceivedLocation != nil && status.Xlog.ReplayedLocation != nil &&
+		*status.Xlog.ReceivedLocation >= *status.Xlog.ReplayedLocation {
+		fields["xlog_replay_lag_bytes"] = *status.Xlog.ReceivedLocation - *status.Xlog.ReplayedLocation
+	}
+	if status.Xlog.Paused != nil {
+		fields["xlog_replay_paused"] = *status.Xlog.Paused
+	}
+	if status.Xlog.ReplayedTimestamp != nil {
+		if ts, err := parseTime(*status.Xlog.ReplayedTimestamp); err == nil {
+			fields["xlog_replayed_timestamp"] = ts.Unix()
+		}
+	}
+	if status.PostmasterStartTime != "" {
+		if ts, err := parseTime(status.PostmasterStartTime); err == nil {
+			fields["postmaster_start_time"] = ts.Unix()
+		}
+	}
+	acc.AddFields("patroni", fields, tags, now)
+
+	if p.GatherCluster {
+		var cluster clusterStatus
+		if err := p.loadJSON(address+"/cluster", &cluster); err != nil {
+			acc.AddError(fmt.Errorf("querying cluster of %q failed: %w", address, err))
+		} else {
+			gatherCluster(acc, address, status.Patroni.Scope, &cluster, now)
+		}
+	}
+
+	if p.GatherHistory {
+		var history []historyEntry
+		if err := p.loadJSON(address+"/history", &history); err != nil {
+			acc.AddError(fmt.Errorf("querying history of %q failed: %w", address, err))
+		} else {
+			gatherHistory(acc, address, status.Patroni.Scope, history, now)
+		}
+	}
+
+	return nil
+}
+
+func gatherCluster(acc telegraf.Accumulator, address, scope string, cluster *clusterStatus, now time.Time) {
+	for _, member := range cluster.Members {
+		tags := map[string]string{
+			"url":    address,
+			"scope":  scope,
+			"member": member.Name,
+			"role":   member.Role,
+			"state":  member.State,
+			"host":   member.Host,
+		}
+		fields := map[string]interface{}{
+			"timeline":        member.Timeline,
+			"pending_restart": member.PendingRestart,
+		}
+
+		// The lag is reported as "unknown" if the member is not streaming
+		var lag int64
+		if err := json.Unmarshal(member.Lag, &lag); err == nil {
+			fields["lag_bytes"] = lag
+		}
+		acc.AddFields("patroni_member", fields, tags, now)
+	}
+}
+
+func gatherHistory(acc telegraf.Accumulator, address, scope string, history []historyEntry, now time.Time) {
+	tags := map[string]string{
+		"url":   address,
+		"scope": scope,
+	}
+	fields := map[string]interface{}{
+		"switches": len(history),
+	}
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		fields["last_switch_timeline"] = last.Timeline
+		fields["last_switch_lsn"] = last.LSN
+		fields["last_switch_reason"] = last.Reason
+		if last.NewLeader != "" {
+			fields["last_switch_new_leader"] = last.NewLeader
+		}
+		if !last.Timestamp.IsZero() {
+			fields["last_switch_timestamp"] = last.Timestamp.Unix()
+		}
+	}
+	acc.AddFields("patroni_history", fields, tags, now)
+}
+
+func (p *Patroni) loadJSON(address string, v interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	if !p.Username.Empty() || !p.Password.Empty() {
+		username, err := p.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+
+		password, err := p.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making HTTP request to %q: %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	// Patroni answers the /patroni endpoint with 503 on members not running
+	// PostgreSQL but still delivers a valid status document.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return fmt.Errorf("%s returned HTTP status %s", address, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing json response: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("patroni", func() telegraf.Input {
+		return &Patroni{
+			GatherCluster: true,
+			GatherHistory: true,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
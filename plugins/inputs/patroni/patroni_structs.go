@@ -0,0 +1,115 @@
+package patroni
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Patroni reports timestamps either in PostgreSQL or in ISO-8601 format
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999Z07:00",
+	time.RFC3339Nano,
+}
+
+type nodeStatus struct {
+	State               string        `json:"state"`
+	PostmasterStartTime string        `json:"postmaster_start_time"`
+	Role                string        `json:"role"`
+	ServerVersion       int64         `json:"server_version"`
+	Timeline            int64         `json:"timeline"`
+	PendingRestart      bool          `json:"pending_restart"`
+	ClusterUnlocked     bool          `json:"cluster_unlocked"`
+	Pause               bool          `json:"pause"`
+	Xlog                xlogStatus    `json:"xlog"`
+	Replication         []replication `json:"replication"`
+	Patroni             struct {
+		Version string `json:"version"`
+		Scope   string `json:"scope"`
+		Name    string `json:"name"`
+	} `json:"patroni"`
+}
+
+type xlogStatus struct {
+	Location          *int64  `json:"location"`
+	ReceivedLocation  *int64  `json:"received_location"`
+	ReplayedLocation  *int64  `json:"replayed_location"`
+	ReplayedTimestamp *string `json:"replayed_timestamp"`
+	Paused            *bool   `json:"paused"`
+}
+
+type replication struct {
+	Username        string `json:"usename"`
+	ApplicationName string `json:"application_name"`
+	ClientAddr      string `json:"client_addr"`
+	State           string `json:"state"`
+	SyncState       string `json:"sync_state"`
+	SyncPriority    int64  `json:"sync_priority"`
+}
+
+type clusterStatus struct {
+	Members []struct {
+		Name           string          `json:"name"`
+		Role           string          `json:"role"`
+		State          string          `json:"state"`
+		Host           string          `json:"host"`
+		Timeline       int64           `json:"timeline"`
+		PendingRestart bool            `json:"pending_restart"`
+		Lag            json.RawMessage `json:"lag"`
+	} `json:"members"`
+}
+
+type historyEntry struct {
+	Timeline  int64
+	LSN       int64
+	Reason    string
+	Timestamp time.Time
+	NewLeader string
+}
+
+// UnmarshalJSON decodes the array representation of a history entry having
+// the form [timeline, lsn, reason, timestamp, new_leader] where the last
+// element is optional and only available in newer Patroni versions.
+func (h *historyEntry) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 3 {
+		return errors.New("invalid history entry")
+	}
+	if err := json.Unmarshal(raw[0], &h.Timeline); err != nil {
+		return fmt.Errorf("decoding timeline failed: %w", err)
+	}
+	if err := json.Unmarshal(raw[1], &h.LSN); err != nil {
+		return fmt.Errorf("decoding lsn failed: %w", err)
+	}
+	if err := json.Unmarshal(raw[2], &h.Reason); err != nil {
+		return fmt.Errorf("decoding reason failed: %w", err)
+	}
+	if len(raw) > 3 {
+		var ts string
+		if err := json.Unmarshal(raw[3], &ts); err == nil {
+			if t, err := parseTime(ts); err == nil {
+				h.Timestamp = t
+			}
+		}
+	}
+	if len(raw) > 4 {
+		// Ignore errors as the field might be null
+		_ = json.Unmarshal(raw[4], &h.NewLeader)
+	}
+
+	return nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown timestamp format")
+}
@@ -0,0 +1,213 @@
+package patroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T, status string) *httptest.Server {
+	responses := map[string]string{
+		"/patroni": status,
+		"/cluster": "cluster.json",
+		"/history": "history.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fn, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+}
+
+func TestGatherPrimary(t *testing.T) {
+	server := newServer(t, "patroni_primary.json")
+	defer server.Close()
+
+	plugin := &Patroni{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"patroni",
+			map[string]string{
+				"url":    server.URL,
+				"scope":  "demo",
+				"member": "pg-node1",
+				"role":   "primary",
+				"state":  "running",
+			},
+			map[string]interface{}{
+				"timeline":              int64(3),
+				"server_version":        int64(160002),
+				"patroni_version":       "3.2.2",
+				"pending_restart":       false,
+				"cluster_unlocked":      false,
+				"paused":                false,
+				"replicas":              1,
+				"xlog_location":         int64(67184640),
+				"postmaster_start_time": int64(1709284542),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherReplica(t *testing.T) {
+	server := newServer(t, "patroni_replica.json")
+	defer server.Close()
+
+	plugin := &Patroni{
+		URLs:          []string{server.URL},
+		GatherCluster: true,
+		GatherHistory: true,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"patroni",
+			map[string]string{
+				"url":    server.URL,
+				"scope":  "demo",
+				"member": "pg-node2",
+				"role":   "replica",
+				"state":  "running",
+			},
+			map[string]interface{}{
+				"timeline":                int64(3),
+				"server_version":          int64(160002),
+				"patroni_version":         "3.2.2",
+				"pending_restart":         true,
+				"cluster_unlocked":        false,
+				"paused":                  false,
+				"replicas":                0,
+				"xlog_received_location":  int64(67184640),
+				"xlog_replayed_location":  int64(67180544),
+				"xlog_replay_lag_bytes":   int64(4096),
+				"xlog_replay_paused":      false,
+				"xlog_replayed_timestamp": int64(1709287331),
+				"postmaster_start_time":   int64(1709284561),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"patroni_member",
+			map[string]string{
+				"url":    server.URL,
+				"scope":  "demo",
+				"member": "pg-node1",
+				"role":   "leader",
+				"state":  "running",
+				"host":   "10.0.0.11",
+			},
+			map[string]interface{}{
+				"timeline":        int64(3),
+				"pending_restart": false,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"patroni_member",
+			map[string]string{
+				"url":    server.URL,
+				"scope":  "demo",
+				"member": "pg-node2",
+				"role":   "replica",
+				"state":  "streaming",
+				"host":   "10.0.0.12",
+			},
+			map[string]interface{}{
+				"timeline":        int64(3),
+				"pending_restart": false,
+				"lag_bytes":       int64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"patroni_member",
+			map[string]string{
+				"url":    server.URL,
+				"scope":  "demo",
+				"member": "pg-node3",
+				"role":   "replica",
+				"state":  "stopped",
+				"host":   "10.0.0.13",
+			},
+			map[string]interface{}{
+				"timeline":        int64(0),
+				"pending_restart": false,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"patroni_history",
+			map[string]string{
+				"url":   server.URL,
+				"scope": "demo",
+			},
+			map[string]interface{}{
+				"switches":               2,
+				"last_switch_timeline":   int64(2),
+				"last_switch_lsn":        int64(50331808),
+				"last_switch_reason":     "no recovery target specified",
+				"last_switch_new_leader": "pg-node2",
+				"last_switch_timestamp":  int64(1709107902),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plugin := &Patroni{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "401 Unauthorized")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -2,6 +2,7 @@
 package ping
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -17,7 +18,9 @@ import (
 	ping "github.com/prometheus-community/pro-bing"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/probing"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -39,11 +42,13 @@ type Ping struct {
 	Percentiles  []int    `toml:"percentiles"`   // Calculate the given percentiles when using native method
 	Binary       string   `toml:"binary"`        // Ping executable binary
 	// Arguments for ping command. When arguments are not empty, system binary will be used and other options (ping_interval, timeout, etc.) will be ignored
-	Arguments []string        `toml:"arguments"`
-	IPv4      bool            `toml:"ipv4"` // Whether to resolve addresses using ipv4 or not.
-	IPv6      bool            `toml:"ipv6"` // Whether to resolve addresses using ipv6 or not.
-	Size      *int            `toml:"size"` // Packet size
-	Log       telegraf.Logger `toml:"-"`
+	Arguments []string `toml:"arguments"`
+	IPv4      bool     `toml:"ipv4"` // Whether to resolve addresses using ipv4 or not.
+	IPv6      bool     `toml:"ipv6"` // Whether to resolve addresses using ipv6 or not.
+	Size      *int     `toml:"size"` // Packet size
+	// Interval to probe the hosts in the background, aggregating the results until the next gather
+	ProbeInterval config.Duration `toml:"probe_interval"`
+	Log           telegraf.Logger `toml:"-"`
 
 	wg             sync.WaitGroup // wg is used to wait for ping with multiple URLs
 	calcInterval   time.Duration  // Pre-calculated interval and timeout
@@ -51,6 +56,10 @@ type Ping struct {
 	sourceAddress  string
 	pingHost       hostPingerFunc // host ping function
 	nativePingFunc nativePingFunc
+
+	cancel  context.CancelFunc
+	probes  sync.WaitGroup
+	windows map[string]*probing.Window
 }
 
 // hostPingerFunc is a function that runs the "ping" function using a list of
@@ -90,10 +99,59 @@ func (p *Ping) Init() error {
 		p.calcTimeout = time.Duration(p.Timeout) * time.Second
 	}
 
+	if p.ProbeInterval > 0 && p.Method != "native" {
+		return errors.New("'probe_interval' requires the native method")
+	}
+
 	return nil
 }
 
+func (p *Ping) Start(acc telegraf.Accumulator) error {
+	if p.ProbeInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.windows = make(map[string]*probing.Window, len(p.Urls))
+	for _, host := range p.Urls {
+		w := &probing.Window{}
+		p.windows[host] = w
+
+		p.probes.Add(1)
+		go func(host string) {
+			defer p.probes.Done()
+
+			ticker := time.NewTicker(time.Duration(p.ProbeInterval))
+			defer ticker.Stop()
+			for {
+				p.probe(host, w, acc)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(host)
+	}
+
+	return nil
+}
+
+func (p *Ping) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.probes.Wait()
+}
+
 func (p *Ping) Gather(acc telegraf.Accumulator) error {
+	if p.ProbeInterval > 0 {
+		p.gatherProbes(acc)
+		return nil
+	}
+
 	for _, host := range p.Urls {
 		p.wg.Add(1)
 		go func(host string) {
@@ -250,6 +308,52 @@ func (p *Ping) pingToURLNative(destination string, acc telegraf.Accumulator) {
 	acc.AddFields("ping", fields, tags)
 }
 
+// probe pings the host once and reports a change of the host's state
+// immediately
+func (p *Ping) probe(host string, w *probing.Window, acc telegraf.Accumulator) {
+	var changed bool
+	stats, err := p.nativePingFunc(host)
+	if err != nil {
+		p.Log.Debugf("Probing %q failed: %v", host, err)
+		changed = w.Add(1, 0, nil)
+	} else {
+		changed = w.Add(stats.PacketsSent, stats.PacketsRecv, stats.Rtts)
+	}
+
+	if changed {
+		up := err == nil && stats.PacketsRecv > 0
+		acc.AddFields("ping_state", map[string]interface{}{"up": up}, map[string]string{"url": host})
+	}
+}
+
+// gatherProbes reports the aggregated results of the probes since the last
+// gather
+func (p *Ping) gatherProbes(acc telegraf.Accumulator) {
+	for _, host := range p.Urls {
+		s, ok := p.windows[host].Flush()
+		if !ok {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"result_code":         0,
+			"packets_transmitted": s.Sent,
+			"packets_received":    s.Received,
+			"percent_packet_loss": s.LossPercent,
+			"state_changes":       s.StateChanges,
+		}
+		if s.Received == 0 {
+			fields["result_code"] = 1
+		} else {
+			fields["minimum_response_ms"] = float64(s.MinRTT) / float64(time.Millisecond)
+			fields["average_response_ms"] = float64(s.AvgRTT) / float64(time.Millisecond)
+			fields["maximum_response_ms"] = float64(s.MaxRTT) / float64(time.Millisecond)
+			fields["jitter_ms"] = float64(s.Jitter) / float64(time.Millisecond)
+		}
+		acc.AddFields("ping", fields, map[string]string{"url": host})
+	}
+}
+
 func (p durationSlice) Len() int { return len(p) }
 
 func (p durationSlice) Less(i, j int) bool { return p[i] < p[j] }
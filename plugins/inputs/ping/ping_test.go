@@ -11,6 +11,9 @@ import (
 	ping "github.com/prometheus-community/pro-bing"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -529,3 +532,76 @@ func TestDNSLookupError(t *testing.T) {
 	require.True(t, testAcc.HasField("ping", "result_code"))
 	require.Equal(t, 1, testAcc.Metrics[0].Fields["result_code"])
 }
+
+func TestProbeIntervalRequiresNative(t *testing.T) {
+	p := &Ping{
+		Count:         1,
+		Urls:          []string{"localhost"},
+		Method:        "exec",
+		ProbeInterval: config.Duration(time.Second),
+	}
+	require.ErrorContains(t, p.Init(), "'probe_interval' requires the native method")
+}
+
+func TestProbeInterval(t *testing.T) {
+	// Simulate a host going down for one probe
+	responses := []*pingStats{
+		{Statistics: ping.Statistics{PacketsSent: 1, PacketsRecv: 1, Rtts: []time.Duration{2 * time.Millisecond}}},
+		{Statistics: ping.Statistics{PacketsSent: 1, PacketsRecv: 0}},
+		{Statistics: ping.Statistics{PacketsSent: 1, PacketsRecv: 1, Rtts: []time.Duration{4 * time.Millisecond}}},
+		{Statistics: ping.Statistics{PacketsSent: 1, PacketsRecv: 1, Rtts: []time.Duration{3 * time.Millisecond}}},
+	}
+	var probes int
+	p := &Ping{
+		Count:         1,
+		Log:           testutil.Logger{},
+		Urls:          []string{"localhost"},
+		Method:        "native",
+		ProbeInterval: config.Duration(time.Hour),
+		nativePingFunc: func(string) (*pingStats, error) {
+			s := responses[probes%len(responses)]
+			probes++
+			return s, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	// Starting probes the host immediately
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	p.Stop()
+	require.Equal(t, 1, probes)
+
+	w := p.windows["localhost"]
+	for range len(responses) - 1 {
+		p.probe("localhost", w, &acc)
+	}
+	require.NoError(t, p.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("ping_state", map[string]string{"url": "localhost"}, map[string]interface{}{"up": false}, time.Unix(0, 0)),
+		metric.New("ping_state", map[string]string{"url": "localhost"}, map[string]interface{}{"up": true}, time.Unix(0, 0)),
+		metric.New(
+			"ping",
+			map[string]string{"url": "localhost"},
+			map[string]interface{}{
+				"result_code":         0,
+				"packets_transmitted": 4,
+				"packets_received":    3,
+				"percent_packet_loss": float64(25),
+				"state_changes":       2,
+				"minimum_response_ms": float64(2),
+				"average_response_ms": float64(3),
+				"maximum_response_ms": float64(4),
+				"jitter_ms":           float64(1.5),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Nothing is reported without new probes
+	acc.ClearMetrics()
+	require.NoError(t, p.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
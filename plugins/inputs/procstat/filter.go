@@ -137,48 +137,9 @@ func (f *filter) applyFilter() ([]processGroup, error) {
 	for _, g := range groups {
 		var matched []*gopsprocess.Process
 		for _, p := range g.processes {
-			// Users
-			if f.filterUser != nil {
-				if username, err := p.Username(); err != nil || !f.filterUser.Match(username) {
-					// Errors can happen if we don't have permissions or the process no longer exists
-					continue
-				}
-			}
-
-			// Executables
-			if f.filterExecutable != nil {
-				if exe, err := p.Exe(); err != nil || !f.filterExecutable.Match(exe) {
-					continue
-				}
-			}
-
-			// Process names
-			if f.filterProcessName != nil {
-				if name, err := p.Name(); err != nil || !f.filterProcessName.Match(name) {
-					continue
-				}
-			}
-
-			// Patterns
-			if len(f.filterCmds) > 0 {
-				cmd, err := p.Cmdline()
-				if err != nil {
-					// This can happen if we don't have permissions or the process no longer exists
-					continue
-				}
-				var found bool
-				for _, re := range f.filterCmds {
-					if re.MatchString(cmd) {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
+			if f.matches(p) {
+				matched = append(matched, p)
 			}
-
-			matched = append(matched, p)
 		}
 		result = append(result, processGroup{processes: matched, tags: g.tags})
 	}
@@ -220,6 +181,49 @@ func (f *filter) applyFilter() ([]processGroup, error) {
 	return result, nil
 }
 
+// matches checks the process against the user, executable, process-name and
+// pattern filters
+func (f *filter) matches(p *gopsprocess.Process) bool {
+	// Users
+	if f.filterUser != nil {
+		if username, err := p.Username(); err != nil || !f.filterUser.Match(username) {
+			// Errors can happen if we don't have permissions or the process no longer exists
+			return false
+		}
+	}
+
+	// Executables
+	if f.filterExecutable != nil {
+		if exe, err := p.Exe(); err != nil || !f.filterExecutable.Match(exe) {
+			return false
+		}
+	}
+
+	// Process names
+	if f.filterProcessName != nil {
+		if name, err := p.Name(); err != nil || !f.filterProcessName.Match(name) {
+			return false
+		}
+	}
+
+	// Patterns
+	if len(f.filterCmds) > 0 {
+		cmd, err := p.Cmdline()
+		if err != nil {
+			// This can happen if we don't have permissions or the process no longer exists
+			return false
+		}
+		for _, re := range f.filterCmds {
+			if re.MatchString(cmd) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
 func getChildren(p *gopsprocess.Process) ([]*gopsprocess.Process, error) {
 	children, err := p.Children()
 	// Check for cases that do not really mean error but rather means that there
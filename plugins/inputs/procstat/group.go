@@ -0,0 +1,196 @@
+package procstat
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	gopsprocess "github.com/shirou/gopsutil/v4/process"
+
+	"github.com/influxdata/telegraf"
+)
+
+// namedGroup aggregates all processes matching the given criteria into a
+// single series, similar to the process-exporter.
+type namedGroup struct {
+	Name         string   `toml:"name"`
+	Patterns     []string `toml:"patterns"`
+	Users        []string `toml:"users"`
+	Executables  []string `toml:"executables"`
+	ProcessNames []string `toml:"process_names"`
+
+	matcher *filter
+}
+
+// groupMember is a process assigned to a group when first seen
+type groupMember struct {
+	*proc
+	group string
+}
+
+type groupStats struct {
+	procs      int
+	threads    int32
+	fds        int32
+	readBytes  uint64
+	writeBytes uint64
+	cpuUser    float64
+	cpuSystem  float64
+	cpuUsage   float64
+	memRSS     uint64
+	memVMS     uint64
+}
+
+func (g *namedGroup) init(p *Procstat) error {
+	name := g.Name
+	if name == "" {
+		name = "<process name>"
+	}
+	g.matcher = &filter{
+		Name:         name,
+		Patterns:     g.Patterns,
+		Users:        g.Users,
+		Executables:  g.Executables,
+		ProcessNames: g.ProcessNames,
+		Log:          p.Log,
+	}
+	return g.matcher.init()
+}
+
+// assignGroup returns the name of the first group matching the process
+func (p *Procstat) assignGroup(gp *gopsprocess.Process) (string, bool) {
+	for _, g := range p.Groups {
+		if !g.matcher.matches(gp) {
+			continue
+		}
+		if g.Name != "" {
+			return g.Name, true
+		}
+		// Unnamed groups create a group per process name
+		name, err := gp.Name()
+		if err != nil || name == "" {
+			return "", false
+		}
+		return name, true
+	}
+	return "", false
+}
+
+func (p *Procstat) gatherGroups(acc telegraf.Accumulator) error {
+	now := time.Now()
+	procs, err := gopsprocess.Processes()
+	if err != nil {
+		return fmt.Errorf("listing processes failed: %w", err)
+	}
+
+	// Report all named groups even without processes to allow alerting on
+	// missing processes
+	stats := make(map[string]*groupStats, len(p.Groups))
+	for _, g := range p.Groups {
+		if g.Name != "" {
+			stats[g.Name] = &groupStats{}
+		}
+	}
+
+	running := make(map[pid]bool, len(procs))
+	for _, gp := range procs {
+		// Use the cached processes as we need the existing instances to
+		// compute delta-metrics (e.g. cpu-usage) and the group assignment
+		// should not change over the lifetime of a process.
+		id := pid(gp.Pid)
+		member, found := p.groupMembers[id]
+		if !found {
+			group, ok := p.assignGroup(gp)
+			if !ok {
+				continue
+			}
+			member = &groupMember{
+				proc:  &proc{Process: gp, tags: make(map[string]string)},
+				group: group,
+			}
+			p.groupMembers[id] = member
+		}
+		running[id] = true
+
+		s, found := stats[member.group]
+		if !found {
+			s = &groupStats{}
+			stats[member.group] = s
+		}
+		p.addGroupMember(s, member)
+	}
+
+	// Cleanup processes that are not running anymore
+	for id := range p.groupMembers {
+		if !running[id] {
+			delete(p.groupMembers, id)
+		}
+	}
+
+	prefix := p.Prefix
+	if prefix != "" {
+		prefix += "_"
+	}
+	for name, s := range stats {
+		fields := map[string]interface{}{
+			prefix + "num_procs":   s.procs,
+			prefix + "num_threads": s.threads,
+			prefix + "num_fds":     s.fds,
+			prefix + "read_bytes":  s.readBytes,
+			prefix + "write_bytes": s.writeBytes,
+		}
+		if p.cfg.features["cpu"] {
+			fields[prefix+"cpu_time_user"] = s.cpuUser
+			fields[prefix+"cpu_time_system"] = s.cpuSystem
+			fields[prefix+"cpu_usage"] = s.cpuUsage
+		}
+		if p.cfg.features["memory"] {
+			fields[prefix+"memory_rss"] = s.memRSS
+			fields[prefix+"memory_vms"] = s.memVMS
+		}
+		acc.AddFields("procstat_group", fields, map[string]string{"group": name}, now)
+	}
+
+	return nil
+}
+
+// addGroupMember adds the statistics of the process to the group. Errors are
+// ignored as processes might end or be inaccessible due to permissions.
+func (p *Procstat) addGroupMember(s *groupStats, member *groupMember) {
+	s.procs++
+
+	if threads, err := member.NumThreads(); err == nil {
+		s.threads += threads
+	}
+	if fds, err := member.NumFDs(); err == nil {
+		s.fds += fds
+	}
+
+	if rc, wc, err := collectTotalReadWrite(member.proc); err == nil {
+		s.readBytes += rc
+		s.writeBytes += wc
+	} else if io, err := member.IOCounters(); err == nil {
+		s.readBytes += io.ReadBytes
+		s.writeBytes += io.WriteBytes
+	}
+
+	if p.cfg.features["cpu"] {
+		if cpuTime, err := member.Times(); err == nil {
+			s.cpuUser += cpuTime.User
+			s.cpuSystem += cpuTime.System
+		}
+		if cpuPerc, err := member.percent(time.Duration(0)); err == nil {
+			if p.cfg.solarisMode {
+				cpuPerc /= float64(runtime.NumCPU())
+			}
+			s.cpuUsage += cpuPerc
+		}
+	}
+
+	if p.cfg.features["memory"] {
+		if mem, err := member.MemoryInfo(); err == nil {
+			s.memRSS += mem.RSS
+			s.memVMS += mem.VMS
+		}
+	}
+}
@@ -51,12 +51,14 @@ type Procstat struct {
 	SocketProtocols        []string        `toml:"socket_protocols"`
 	TagWith                []string        `toml:"tag_with"`
 	Filter                 []filter        `toml:"filter"`
+	Groups                 []*namedGroup   `toml:"group"`
 	Log                    telegraf.Logger `toml:"-"`
 
-	finder    pidFinder
-	processes map[pid]process
-	cfg       collectionConfig
-	oldMode   bool
+	finder       pidFinder
+	processes    map[pid]process
+	groupMembers map[pid]*groupMember
+	cfg          collectionConfig
+	oldMode      bool
 
 	createProcess func(pid) (process, error)
 }
@@ -144,7 +146,7 @@ func (p *Procstat) Init() error {
 
 	// Check if we got any new-style configuration options and determine
 	// operation mode.
-	p.oldMode = len(p.Filter) == 0
+	p.oldMode = len(p.Filter) == 0 && len(p.Groups) == 0
 	if p.oldMode {
 		// Keep the old settings for compatibility
 		for _, u := range p.SupervisorUnit {
@@ -191,7 +193,7 @@ func (p *Procstat) Init() error {
 		case p.PidFile != "", p.Exe != "", p.Pattern != "", p.User != "",
 			p.SystemdUnit != "", len(p.SupervisorUnit) > 0,
 			len(p.SupervisorUnits) > 0, p.CGroup != "", p.WinService != "":
-			return errors.New("cannot operate in mixed mode with filters and old-style config")
+			return errors.New("cannot operate in mixed mode with filters or groups and old-style config")
 		}
 
 		// New-style operations
@@ -201,10 +203,24 @@ func (p *Procstat) Init() error {
 				return fmt.Errorf("initializing filter %d failed: %w", i, err)
 			}
 		}
+
+		names := make(map[string]bool, len(p.Groups))
+		for i, g := range p.Groups {
+			if g.Name != "" {
+				if names[g.Name] {
+					return fmt.Errorf("duplicate group name %q", g.Name)
+				}
+				names[g.Name] = true
+			}
+			if err := g.init(p); err != nil {
+				return fmt.Errorf("initializing group %d failed: %w", i, err)
+			}
+		}
 	}
 
 	// Initialize the running process cache
 	p.processes = make(map[pid]process)
+	p.groupMembers = make(map[pid]*groupMember)
 
 	return nil
 }
@@ -214,7 +230,17 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 		return p.gatherOld(acc)
 	}
 
-	return p.gatherNew(acc)
+	if len(p.Filter) > 0 {
+		if err := p.gatherNew(acc); err != nil {
+			return err
+		}
+	}
+
+	if len(p.Groups) > 0 {
+		return p.gatherGroups(acc)
+	}
+
+	return nil
 }
 
 func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
@@ -674,3 +674,90 @@ func TestGather_MoresupervisorUnitPIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestInitGroupsMixedMode(t *testing.T) {
+	p := Procstat{
+		Exe:        "foo",
+		Groups:     []*namedGroup{{Name: "test"}},
+		Properties: []string{"cpu", "memory"},
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "cannot operate in mixed mode")
+}
+
+func TestInitGroupsDuplicateName(t *testing.T) {
+	p := Procstat{
+		Groups:     []*namedGroup{{Name: "test"}, {Name: "test"}},
+		Properties: []string{"cpu", "memory"},
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), `duplicate group name "test"`)
+}
+
+func TestInitGroupsInvalidPattern(t *testing.T) {
+	p := Procstat{
+		Groups:     []*namedGroup{{Name: "test", Patterns: []string{"a("}}},
+		Properties: []string{"cpu", "memory"},
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "initializing group 0 failed")
+}
+
+func TestGatherGroups(t *testing.T) {
+	self, err := gopsprocess.NewProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+	name, err := self.Name()
+	require.NoError(t, err)
+
+	p := Procstat{
+		Groups: []*namedGroup{
+			{Name: "self", ProcessNames: []string{name}},
+			{Name: "missing", ProcessNames: []string{"telegraf-non-existing-process"}},
+		},
+		Properties: []string{"cpu", "memory"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "procstat_group", m.Name())
+		switch group, _ := m.GetTag("group"); group {
+		case "self":
+			require.GreaterOrEqual(t, m.Fields()["num_procs"], int64(1))
+			require.Contains(t, m.Fields(), "memory_rss")
+			require.Contains(t, m.Fields(), "cpu_usage")
+		case "missing":
+			require.Equal(t, int64(0), m.Fields()["num_procs"])
+		default:
+			require.Failf(t, "unexpected group", "group %q", group)
+		}
+	}
+
+	// Processes keep their assigned group
+	require.Contains(t, p.groupMembers, pid(os.Getpid()))
+	require.Equal(t, "self", p.groupMembers[pid(os.Getpid())].group)
+}
+
+func TestGatherGroupsByProcessName(t *testing.T) {
+	self, err := gopsprocess.NewProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+	name, err := self.Name()
+	require.NoError(t, err)
+
+	p := Procstat{
+		Groups:     []*namedGroup{{ProcessNames: []string{name}}},
+		Properties: []string{"cpu"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	require.True(t, acc.HasTag("procstat_group", "group"))
+	require.Equal(t, name, acc.TagValue("procstat_group", "group"))
+	require.False(t, acc.HasField("procstat_group", "memory_rss"))
+}
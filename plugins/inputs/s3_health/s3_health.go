@@ -0,0 +1,215 @@
+//go:generate ../../../tools/config_includer/generator
+//go:generate ../../../tools/readme_config_includer/generator
+package s3_health
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var errContentMismatch = errors.New("content of canary does not match")
+
+type S3Health struct {
+	Bucket       string          `toml:"bucket"`
+	Canary       bool            `toml:"canary"`
+	CanaryPrefix string          `toml:"canary_prefix"`
+	CanarySize   config.Size     `toml:"canary_size"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+	common_aws.CredentialConfig
+	common_tls.ClientConfig
+
+	client   *s3.Client
+	endpoint string
+}
+
+func (*S3Health) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *S3Health) Init() error {
+	if s.Bucket == "" {
+		return errors.New("'bucket' required")
+	}
+	if s.Timeout <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+	if s.Canary && s.CanarySize <= 0 {
+		return errors.New("'canary_size' must be positive")
+	}
+
+	s.endpoint = s.EndpointURL
+	if s.endpoint == "" {
+		s.endpoint = "aws"
+		if s.Region != "" {
+			s.endpoint += ":" + s.Region
+		}
+	}
+
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return fmt.Errorf("getting credentials failed: %w", err)
+	}
+
+	tlsCfg, err := s.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	cfg.HTTPClient = &http.Client{Transport: transport}
+
+	s.client = s3.NewFromConfig(cfg, func(options *s3.Options) {
+		if s.EndpointURL != "" {
+			options.BaseEndpoint = &s.EndpointURL
+			// S3 compatible stores like MinIO usually require path-style
+			// addressing
+			options.UsePathStyle = true
+		}
+		// Never retry to report the actual availability of the endpoint
+		options.RetryMaxAttempts = 1
+	})
+
+	return nil
+}
+
+func (s *S3Health) Gather(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	ok := s.probe(acc, "head_bucket", func() error {
+		_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.Bucket)})
+		return err
+	})
+	if !ok || !s.Canary {
+		return nil
+	}
+
+	// Use a unique canary object to not interfere with other instances
+	// probing the same bucket
+	suffix, err := internal.RandomString(16)
+	if err != nil {
+		return fmt.Errorf("generating canary key failed: %w", err)
+	}
+	key := s.CanaryPrefix + suffix
+
+	content := make([]byte, int(s.CanarySize))
+	if _, err := rand.Read(content); err != nil {
+		return fmt.Errorf("generating canary content failed: %w", err)
+	}
+
+	ok = s.probe(acc, "put_object", func() error {
+		_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(s.Bucket),
+			Key:           aws.String(key),
+			Body:          bytes.NewReader(content),
+			ContentLength: aws.Int64(int64(len(content))),
+		})
+		return err
+	})
+	if !ok {
+		return nil
+	}
+
+	s.probe(acc, "get_object", func() error {
+		out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.Bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return err
+		}
+		defer out.Body.Close()
+
+		received, err := io.ReadAll(out.Body)
+		if err != nil {
+			return fmt.Errorf("reading canary failed: %w", err)
+		}
+		if !bytes.Equal(received, content) {
+			return errContentMismatch
+		}
+		return nil
+	})
+
+	// Always clean up the canary even if reading it back failed
+	s.probe(acc, "delete_object", func() error {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.Bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+
+	return nil
+}
+
+// probe runs the given operation and reports its latency and result. The
+// returned value is true if the operation succeeded.
+func (s *S3Health) probe(acc telegraf.Accumulator, operation string, op func() error) bool {
+	start := time.Now()
+	err := op()
+	elapsed := time.Since(start)
+
+	tags := map[string]string{
+		"endpoint":  s.endpoint,
+		"bucket":    s.Bucket,
+		"operation": operation,
+	}
+	fields := map[string]interface{}{
+		"response_time": elapsed.Seconds(),
+		"success":       err == nil,
+	}
+
+	var respErr *awshttp.ResponseError
+	switch {
+	case err == nil:
+		fields["result"] = "success"
+	case errors.Is(err, context.DeadlineExceeded):
+		fields["result"] = "timeout"
+	case errors.Is(err, errContentMismatch):
+		fields["result"] = "content_mismatch"
+	case errors.As(err, &respErr):
+		fields["result"] = "error"
+		fields["http_response_code"] = respErr.HTTPStatusCode()
+	default:
+		fields["result"] = "error"
+	}
+	if err != nil {
+		s.Log.Debugf("Operation %q on bucket %q of %q failed: %v", operation, s.Bucket, s.endpoint, err)
+	}
+
+	acc.AddFields("s3_health", fields, tags, start)
+	return err == nil
+}
+
+func init() {
+	inputs.Add("s3_health", func() telegraf.Input {
+		return &S3Health{
+			Canary:       true,
+			CanaryPrefix: "telegraf-canary/",
+			CanarySize:   config.Size(1024),
+			Timeout:      config.Duration(10 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package s3_health
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeStore is a minimal S3 compatible server using path-style addressing
+type fakeStore struct {
+	bucket string
+
+	// Status code returned for all operations of the given method
+	fail map[string]int
+
+	sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if code, found := s.fail[r.Method]; found {
+		w.WriteHeader(code)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	switch r.Method {
+	case http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = data
+		w.Header().Set("ETag", `"canary"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		data, found := s.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newPlugin(endpoint, bucket string) *S3Health {
+	return &S3Health{
+		Bucket:       bucket,
+		Canary:       true,
+		CanaryPrefix: "telegraf-canary/",
+		CanarySize:   config.Size(64),
+		Timeout:      config.Duration(5 * time.Second),
+		Log:          testutil.Logger{},
+		CredentialConfig: common_aws.CredentialConfig{
+			Region:      "us-east-1",
+			AccessKey:   "telegraf",
+			SecretKey:   "secret",
+			EndpointURL: endpoint,
+		},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &S3Health{Timeout: config.Duration(time.Second)}
+	require.ErrorContains(t, plugin.Init(), "'bucket' required")
+
+	plugin = &S3Health{Bucket: "test"}
+	require.ErrorContains(t, plugin.Init(), "'timeout' must be positive")
+
+	plugin = &S3Health{Bucket: "test", Canary: true, Timeout: config.Duration(time.Second)}
+	require.ErrorContains(t, plugin.Init(), "'canary_size' must be positive")
+}
+
+func TestGather(t *testing.T) {
+	tests := []struct {
+		name     string
+		bucket   string
+		canary   bool
+		fail     map[string]int
+		expected map[string]map[string]interface{}
+	}{
+		{
+			name:   "success",
+			bucket: "canary",
+			canary: true,
+			expected: map[string]map[string]interface{}{
+				"head_bucket":   {"success": true, "result": "success"},
+				"put_object":    {"success": true, "result": "success"},
+				"get_object":    {"success": true, "result": "success"},
+				"delete_object": {"success": true, "result": "success"},
+			},
+		},
+		{
+			name:   "bucket only",
+			bucket: "canary",
+			expected: map[string]map[string]interface{}{
+				"head_bucket": {"success": true, "result": "success"},
+			},
+		},
+		{
+			name:   "missing bucket",
+			bucket: "unknown",
+			canary: true,
+			expected: map[string]map[string]interface{}{
+				"head_bucket": {"success": false, "result": "error", "http_response_code": int64(404)},
+			},
+		},
+		{
+			name:   "read failure",
+			bucket: "canary",
+			canary: true,
+			fail:   map[string]int{http.MethodGet: http.StatusServiceUnavailable},
+			expected: map[string]map[string]interface{}{
+				"head_bucket":   {"success": true, "result": "success"},
+				"put_object":    {"success": true, "result": "success"},
+				"get_object":    {"success": false, "result": "error", "http_response_code": int64(503)},
+				"delete_object": {"success": true, "result": "success"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{
+				bucket:  "canary",
+				fail:    tt.fail,
+				objects: make(map[string][]byte),
+			}
+			server := httptest.NewServer(store)
+			defer server.Close()
+
+			plugin := newPlugin(server.URL, tt.bucket)
+			plugin.Canary = tt.canary
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			// The canary must always be cleaned up
+			require.Empty(t, store.objects)
+
+			actual := make(map[string]map[string]interface{}, len(acc.Metrics))
+			for _, m := range acc.GetTelegrafMetrics() {
+				require.Equal(t, "s3_health", m.Name())
+				require.Equal(t, server.URL, m.Tags()["endpoint"])
+				require.Equal(t, tt.bucket, m.Tags()["bucket"])
+
+				fields := m.Fields()
+				require.Contains(t, fields, "response_time")
+				delete(fields, "response_time")
+				actual[m.Tags()["operation"]] = fields
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestGatherContentMismatch(t *testing.T) {
+	// Return different content than written
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte("corrupted"))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL, "canary")
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["operation"] != "get_object" {
+			continue
+		}
+		found = true
+		result, _ := m.GetField("result")
+		require.Equal(t, "content_mismatch", result)
+	}
+	require.True(t, found)
+}
+
+func TestGatherTimeout(t *testing.T) {
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL, "canary")
+	plugin.Timeout = config.Duration(100 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"s3_health",
+			map[string]string{"endpoint": server.URL, "bucket": "canary", "operation": "head_bucket"},
+			map[string]interface{}{"success": false, "result": "timeout", "response_time": float64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.IgnoreFields("response_time"))
+}
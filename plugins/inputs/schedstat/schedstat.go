@@ -0,0 +1,289 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package schedstat
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// reclaimCounters maps the reclaim fields to the /proc/vmstat counters
+// summed up for the field. Older kernels report the counters per zone,
+// e.g. "pgscan_kswapd_normal", newer kernels only report the total.
+var reclaimCounters = []struct {
+	field  string
+	prefix string
+}{
+	{"kswapd_scanned", "pgscan_kswapd"},
+	{"kswapd_reclaimed", "pgsteal_kswapd"},
+	{"kswapd_runs", "pageoutrun"},
+	{"direct_scanned", "pgscan_direct"},
+	{"direct_reclaimed", "pgsteal_direct"},
+	{"direct_stalls", "allocstall"},
+}
+
+type Schedstat struct {
+	Collect []string        `toml:"collect"`
+	Log     telegraf.Logger `toml:"-"`
+
+	procPath string
+	collect  map[string]bool
+	previous *sample
+}
+
+// cpuStats contains the scheduler statistics of a single CPU
+type cpuStats struct {
+	schedules  uint64
+	runTime    uint64
+	runDelay   uint64
+	timeslices uint64
+}
+
+// sample holds the counters of the last gather to compute the rates of the
+// current interval
+type sample struct {
+	timestamp       time.Time
+	cpus            map[string]cpuStats
+	contextSwitches uint64
+	reclaim         map[string]uint64
+}
+
+func (*Schedstat) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Schedstat) Init() error {
+	if len(s.Collect) == 0 {
+		s.Collect = []string{"cpu", "context_switches", "reclaim"}
+	}
+	s.collect = make(map[string]bool, len(s.Collect))
+	for _, group := range s.Collect {
+		switch group {
+		case "cpu", "context_switches", "reclaim":
+		default:
+			return fmt.Errorf("invalid group %q in 'collect'", group)
+		}
+		s.collect[group] = true
+	}
+
+	s.procPath = internal.GetProcPath()
+	return nil
+}
+
+func (s *Schedstat) Gather(acc telegraf.Accumulator) error {
+	s.gather(acc, time.Now())
+	return nil
+}
+
+func (s *Schedstat) gather(acc telegraf.Accumulator, now time.Time) {
+	current := &sample{timestamp: now}
+
+	var elapsed float64
+	if s.previous != nil {
+		elapsed = now.Sub(s.previous.timestamp).Seconds()
+	}
+
+	if s.collect["cpu"] {
+		cpus, err := readFile(filepath.Join(s.procPath, "schedstat"), parseSchedstat)
+		if err != nil {
+			acc.AddError(err)
+		} else {
+			current.cpus = cpus
+			s.reportCPUs(acc, cpus, elapsed, now)
+		}
+	}
+
+	if s.collect["context_switches"] {
+		ctxt, err := readFile(filepath.Join(s.procPath, "stat"), parseContextSwitches)
+		if err != nil {
+			acc.AddError(err)
+		} else {
+			current.contextSwitches = ctxt
+			fields := map[string]interface{}{"context_switches": ctxt}
+			if s.previous != nil && elapsed > 0 && ctxt >= s.previous.contextSwitches && s.previous.contextSwitches > 0 {
+				fields["context_switches_rate"] = float64(ctxt-s.previous.contextSwitches) / elapsed
+			}
+			acc.AddFields("schedstat_system", fields, map[string]string{}, now)
+		}
+	}
+
+	if s.collect["reclaim"] {
+		reclaim, err := readFile(filepath.Join(s.procPath, "vmstat"), parseReclaim)
+		if err != nil {
+			acc.AddError(err)
+		} else {
+			current.reclaim = reclaim
+			s.reportReclaim(acc, reclaim, elapsed, now)
+		}
+	}
+
+	s.previous = current
+}
+
+// reportCPUs emits the scheduler statistics of each CPU. The average run
+// delay is the time tasks waited on the run-queue per timeslice in the last
+// interval and the run-queue waiting the average number of tasks waiting.
+func (s *Schedstat) reportCPUs(acc telegraf.Accumulator, cpus map[string]cpuStats, elapsed float64, now time.Time) {
+	for cpu, stats := range cpus {
+		fields := map[string]interface{}{
+			"schedules":    stats.schedules,
+			"run_time_ns":  stats.runTime,
+			"run_delay_ns": stats.runDelay,
+			"timeslices":   stats.timeslices,
+		}
+
+		if s.previous != nil && elapsed > 0 {
+			last, found := s.previous.cpus[cpu]
+			if found && stats.runDelay >= last.runDelay && stats.timeslices >= last.timeslices && stats.schedules >= last.schedules {
+				fields["schedules_rate"] = float64(stats.schedules-last.schedules) / elapsed
+				fields["run_queue_waiting"] = float64(stats.runDelay-last.runDelay) / (elapsed * float64(time.Second))
+				if slices := stats.timeslices - last.timeslices; slices > 0 {
+					fields["run_delay_avg_ns"] = float64(stats.runDelay-last.runDelay) / float64(slices)
+				} else {
+					fields["run_delay_avg_ns"] = 0.0
+				}
+			}
+		}
+
+		acc.AddFields("schedstat", fields, map[string]string{"cpu": cpu}, now)
+	}
+}
+
+// reportReclaim emits the memory reclaim activity of kswapd and the direct
+// reclaim of allocating tasks
+func (s *Schedstat) reportReclaim(acc telegraf.Accumulator, reclaim map[string]uint64, elapsed float64, now time.Time) {
+	fields := make(map[string]interface{}, 2*len(reclaim))
+	for field, value := range reclaim {
+		fields[field] = value
+		if s.previous == nil || elapsed <= 0 {
+			continue
+		}
+		if last, found := s.previous.reclaim[field]; found && value >= last {
+			fields[field+"_rate"] = float64(value-last) / elapsed
+		}
+	}
+	acc.AddFields("schedstat_reclaim", fields, map[string]string{}, now)
+}
+
+func readFile[T any](filename string, parse func(io.Reader) (T, error)) (T, error) {
+	var result T
+	f, err := os.Open(filename)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	result, err = parse(f)
+	if err != nil {
+		return result, fmt.Errorf("parsing %q failed: %w", filename, err)
+	}
+	return result, nil
+}
+
+// parseSchedstat parses the per-CPU lines of /proc/schedstat, see
+// https://docs.kernel.org/scheduler/sched-stats.html for the format
+func parseSchedstat(r io.Reader) (map[string]cpuStats, error) {
+	cpus := make(map[string]cpuStats)
+	var version string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "version" && len(fields) > 1:
+			version = fields[1]
+		case strings.HasPrefix(fields[0], "cpu"):
+			if len(fields) < 10 {
+				return nil, fmt.Errorf("unexpected number of fields for %q", fields[0])
+			}
+			values := make([]uint64, 0, 9)
+			for _, field := range fields[1:10] {
+				v, err := strconv.ParseUint(field, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("parsing value for %q failed: %w", fields[0], err)
+				}
+				values = append(values, v)
+			}
+			cpus[fields[0]] = cpuStats{
+				schedules:  values[2],
+				runTime:    values[6],
+				runDelay:   values[7],
+				timeslices: values[8],
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if version == "" {
+		return nil, errors.New("missing version")
+	}
+	return cpus, nil
+}
+
+// parseContextSwitches returns the total number of context switches from
+// /proc/stat
+func parseContextSwitches(r io.Reader) (uint64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "ctxt" {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("missing context switches")
+}
+
+// parseReclaim sums up the reclaim counters of /proc/vmstat
+func parseReclaim(r io.Reader) (map[string]uint64, error) {
+	result := make(map[string]uint64, len(reclaimCounters))
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] == "pgscan_direct_throttle" {
+			continue
+		}
+		for _, c := range reclaimCounters {
+			if fields[0] != c.prefix && !strings.HasPrefix(fields[0], c.prefix+"_") {
+				continue
+			}
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing value for %q failed: %w", fields[0], err)
+			}
+			result[c.field] += v
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func init() {
+	inputs.Add("schedstat", func() telegraf.Input {
+		return &Schedstat{}
+	})
+}
@@ -0,0 +1,176 @@
+//go:build linux
+
+package schedstat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidGroup(t *testing.T) {
+	plugin := &Schedstat{Collect: []string{"cpu", "foo"}}
+	require.ErrorContains(t, plugin.Init(), `invalid group "foo" in 'collect'`)
+}
+
+func TestParseReclaimZones(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "vmstat_zones"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	reclaim, err := parseReclaim(f)
+	require.NoError(t, err)
+
+	expected := map[string]uint64{
+		"kswapd_scanned":   4000,
+		"kswapd_reclaimed": 3000,
+		"kswapd_runs":      50,
+		"direct_scanned":   400,
+		"direct_reclaimed": 200,
+		"direct_stalls":    10,
+	}
+	require.Equal(t, expected, reclaim)
+}
+
+func TestParseSchedstatInvalid(t *testing.T) {
+	proc := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "schedstat"), []byte("version 15\ncpu0 1 2 3\n"), 0o600))
+
+	_, err := readFile(filepath.Join(proc, "schedstat"), parseSchedstat)
+	require.ErrorContains(t, err, `unexpected number of fields for "cpu0"`)
+}
+
+func TestGather(t *testing.T) {
+	t.Setenv("HOST_PROC", "testdata")
+
+	plugin := &Schedstat{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"schedstat",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{
+				"schedules":    uint64(1000),
+				"run_time_ns":  uint64(2000000000),
+				"run_delay_ns": uint64(100000000),
+				"timeslices":   uint64(900),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"schedstat",
+			map[string]string{"cpu": "cpu1"},
+			map[string]interface{}{
+				"schedules":    uint64(2000),
+				"run_time_ns":  uint64(3000000000),
+				"run_delay_ns": uint64(400000000),
+				"timeslices":   uint64(1900),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"schedstat_system",
+			map[string]string{},
+			map[string]interface{}{"context_switches": uint64(500000)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"schedstat_reclaim",
+			map[string]string{},
+			map[string]interface{}{
+				"kswapd_scanned":   uint64(4000),
+				"kswapd_reclaimed": uint64(3000),
+				"kswapd_runs":      uint64(50),
+				"direct_scanned":   uint64(400),
+				"direct_reclaimed": uint64(200),
+				"direct_stalls":    uint64(10),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherRates(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	write := func(schedstat, stat, vmstat string) {
+		require.NoError(t, os.WriteFile(filepath.Join(proc, "schedstat"), []byte(schedstat), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(proc, "stat"), []byte(stat), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(proc, "vmstat"), []byte(vmstat), 0o600))
+	}
+
+	plugin := &Schedstat{}
+	require.NoError(t, plugin.Init())
+
+	// The first gather only records the counters
+	now := time.Now()
+	write(
+		"version 15\ncpu0 0 0 1000 0 0 0 1000 5000000000 1000\n",
+		"ctxt 1000\n",
+		"pgscan_kswapd 100\npgsteal_kswapd 50\npgscan_direct 0\npgsteal_direct 0\nallocstall_normal 0\npageoutrun 1\n",
+	)
+	var acc testutil.Accumulator
+	plugin.gather(&acc, now)
+	require.Empty(t, acc.Errors)
+	require.False(t, acc.HasField("schedstat", "run_delay_avg_ns"))
+	require.False(t, acc.HasField("schedstat_system", "context_switches_rate"))
+	require.False(t, acc.HasField("schedstat_reclaim", "kswapd_scanned_rate"))
+
+	// Within 10 seconds, tasks waited 20 seconds in total for 4000 timeslices
+	write(
+		"version 15\ncpu0 0 0 3000 0 0 0 1000 25000000000 5000\n",
+		"ctxt 6000\n",
+		"pgscan_kswapd 1100\npgsteal_kswapd 550\npgscan_direct 200\npgsteal_direct 100\nallocstall_normal 3\npageoutrun 11\n",
+	)
+	acc.ClearMetrics()
+	plugin.gather(&acc, now.Add(10*time.Second))
+	require.Empty(t, acc.Errors)
+
+	acc.AssertContainsTaggedFields(t, "schedstat",
+		map[string]interface{}{
+			"schedules":         uint64(3000),
+			"run_time_ns":       uint64(1000),
+			"run_delay_ns":      uint64(25000000000),
+			"timeslices":        uint64(5000),
+			"schedules_rate":    float64(200),
+			"run_queue_waiting": float64(2),
+			"run_delay_avg_ns":  float64(5000000),
+		},
+		map[string]string{"cpu": "cpu0"},
+	)
+	acc.AssertContainsFields(t, "schedstat_system",
+		map[string]interface{}{
+			"context_switches":      uint64(6000),
+			"context_switches_rate": float64(500),
+		},
+	)
+	acc.AssertContainsFields(t, "schedstat_reclaim",
+		map[string]interface{}{
+			"kswapd_scanned":        uint64(1100),
+			"kswapd_scanned_rate":   float64(100),
+			"kswapd_reclaimed":      uint64(550),
+			"kswapd_reclaimed_rate": float64(50),
+			"kswapd_runs":           uint64(11),
+			"kswapd_runs_rate":      float64(1),
+			"direct_scanned":        uint64(200),
+			"direct_scanned_rate":   float64(20),
+			"direct_reclaimed":      uint64(100),
+			"direct_reclaimed_rate": float64(10),
+			"direct_stalls":         uint64(3),
+			"direct_stalls_rate":    float64(0.3),
+		},
+	)
+}
@@ -0,0 +1,75 @@
+package service_discovery
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// targetAccumulator applies the settings of the templated input and the tags
+// of the target to the metrics before passing them on
+type targetAccumulator struct {
+	acc       telegraf.Accumulator
+	inst      *instance
+	precision time.Duration
+}
+
+func (a *targetAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.add(measurement, tags, fields, telegraf.Untyped, t...)
+}
+
+func (a *targetAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.add(measurement, tags, fields, telegraf.Gauge, t...)
+}
+
+func (a *targetAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.add(measurement, tags, fields, telegraf.Counter, t...)
+}
+
+func (a *targetAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.add(measurement, tags, fields, telegraf.Summary, t...)
+}
+
+func (a *targetAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.add(measurement, tags, fields, telegraf.Histogram, t...)
+}
+
+func (a *targetAccumulator) AddMetric(m telegraf.Metric) {
+	m.SetTime(m.Time().Round(a.precision))
+	a.forward(m)
+}
+
+func (a *targetAccumulator) SetPrecision(precision time.Duration) {
+	a.precision = precision
+}
+
+func (a *targetAccumulator) AddError(err error) {
+	if err == nil {
+		return
+	}
+	a.inst.input.Log().Errorf("Error in plugin for target %q: %v", a.inst.target.Address, err)
+}
+
+func (a *targetAccumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	return a.acc.WithTracking(maxTracked)
+}
+
+func (a *targetAccumulator) add(measurement string, tags map[string]string, fields map[string]interface{}, tp telegraf.ValueType, t ...time.Time) {
+	timestamp := time.Now()
+	if len(t) > 0 {
+		timestamp = t[0]
+	}
+	a.forward(metric.New(measurement, tags, fields, timestamp.Round(a.precision), tp))
+}
+
+func (a *targetAccumulator) forward(m telegraf.Metric) {
+	m = a.inst.input.MakeMetric(m)
+	if m == nil {
+		return
+	}
+	for k, v := range a.inst.target.Tags {
+		m.AddTag(k, v)
+	}
+	a.acc.AddMetric(m)
+}
@@ -0,0 +1,189 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package service_discovery
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/discovery"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ServiceDiscovery struct {
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Template        string          `toml:"template"`
+	Log             telegraf.Logger `toml:"-"`
+	discovery.Config
+
+	tmpl    *template.Template
+	watcher *discovery.Watcher
+
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	instances map[string]*instance
+}
+
+// instance is an input plugin created from the template for a target
+type instance struct {
+	target discovery.Target
+	input  *models.RunningInput
+}
+
+func (*ServiceDiscovery) SampleConfig() string {
+	return sampleConfig
+}
+
+func (sd *ServiceDiscovery) Init() error {
+	if sd.Template == "" {
+		return errors.New("'template' must be set")
+	}
+
+	tmpl, err := template.New("template").Option("missingkey=zero").Parse(sd.Template)
+	if err != nil {
+		return fmt.Errorf("parsing template failed: %w", err)
+	}
+	sd.tmpl = tmpl
+
+	// Check the template by creating an instance for an example target
+	example := discovery.Target{
+		Address: "localhost:80",
+		Host:    "localhost",
+		Port:    80,
+		Tags:    make(map[string]string),
+	}
+	if _, err := sd.newInput(example); err != nil {
+		return fmt.Errorf("checking template failed: %w", err)
+	}
+
+	providers, err := sd.Config.Providers()
+	if err != nil {
+		return err
+	}
+	sd.watcher = &discovery.Watcher{
+		Providers: providers,
+		OnAdd:     sd.add,
+		OnRemove:  sd.remove,
+		Log:       sd.Log,
+	}
+	sd.instances = make(map[string]*instance)
+
+	return nil
+}
+
+func (sd *ServiceDiscovery) Start(telegraf.Accumulator) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	sd.cancel = cancel
+
+	sd.wg.Add(1)
+	go func() {
+		defer sd.wg.Done()
+		sd.watcher.Run(ctx, time.Duration(sd.RefreshInterval))
+	}()
+
+	return nil
+}
+
+func (sd *ServiceDiscovery) Stop() {
+	if sd.cancel != nil {
+		sd.cancel()
+	}
+	sd.wg.Wait()
+}
+
+func (sd *ServiceDiscovery) Gather(acc telegraf.Accumulator) error {
+	sd.mu.Lock()
+	instances := make([]*instance, 0, len(sd.instances))
+	for _, inst := range sd.instances {
+		instances = append(instances, inst)
+	}
+	sd.mu.Unlock()
+	sort.Slice(instances, func(i, j int) bool { return instances[i].target.Address < instances[j].target.Address })
+
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		wg.Add(1)
+		go func(inst *instance) {
+			defer wg.Done()
+			tacc := &targetAccumulator{acc: acc, inst: inst, precision: time.Nanosecond}
+			if err := inst.input.Gather(tacc); err != nil {
+				acc.AddError(fmt.Errorf("target %s: %w", inst.target.Address, err))
+			}
+		}(inst)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// add is called by the watcher for newly discovered targets
+func (sd *ServiceDiscovery) add(target discovery.Target) {
+	ri, err := sd.newInput(target)
+	if err != nil {
+		sd.Log.Errorf("Creating input for target %q failed: %v", target.Address, err)
+		return
+	}
+	if err := ri.Init(); err != nil {
+		sd.Log.Errorf("Initializing input for target %q failed: %v", target.Address, err)
+		return
+	}
+
+	sd.mu.Lock()
+	sd.instances[target.ID()] = &instance{target: target, input: ri}
+	sd.mu.Unlock()
+	sd.Log.Debugf("Added target %q", target.Address)
+}
+
+// remove is called by the watcher for vanished targets
+func (sd *ServiceDiscovery) remove(target discovery.Target) {
+	sd.mu.Lock()
+	delete(sd.instances, target.ID())
+	sd.mu.Unlock()
+	sd.Log.Debugf("Removed target %q", target.Address)
+}
+
+// newInput renders the template for the given target and creates the
+// resulting input plugin
+func (sd *ServiceDiscovery) newInput(target discovery.Target) (*models.RunningInput, error) {
+	var buf bytes.Buffer
+	if err := sd.tmpl.Execute(&buf, target); err != nil {
+		return nil, fmt.Errorf("rendering template failed: %w", err)
+	}
+
+	// The global tags are added by the agent when passing the metrics on
+	cfg := config.NewConfig()
+	cfg.Agent.OmitHostname = true
+	if err := cfg.LoadConfigData(buf.Bytes(), config.EmptySourcePath); err != nil {
+		return nil, err
+	}
+	if len(cfg.Inputs) != 1 || len(cfg.Outputs) > 0 || len(cfg.Processors) > 0 || len(cfg.Aggregators) > 0 {
+		return nil, errors.New("template must define exactly one input plugin")
+	}
+
+	ri := cfg.Inputs[0]
+	if _, ok := ri.Input.(telegraf.ServiceInput); ok {
+		return nil, fmt.Errorf("service input %q is not supported", ri.Config.Name)
+	}
+	return ri, nil
+}
+
+func init() {
+	inputs.Add("service_discovery", func() telegraf.Input {
+		return &ServiceDiscovery{
+			RefreshInterval: config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package service_discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/discovery"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// probeInput is a minimal input reporting its configured address
+type probeInput struct {
+	Address string `toml:"address"`
+}
+
+func (*probeInput) SampleConfig() string {
+	return ""
+}
+
+func (p *probeInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("probe", map[string]interface{}{"up": 1}, map[string]string{"address": p.Address})
+	return nil
+}
+
+// listenerInput is a service input not supported in templates
+type listenerInput struct {
+	probeInput
+}
+
+func (*listenerInput) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*listenerInput) Stop() {}
+
+func init() {
+	inputs.Add("sd_probe", func() telegraf.Input { return &probeInput{} })
+	inputs.Add("sd_listener", func() telegraf.Input { return &listenerInput{} })
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "missing template",
+			expected: "'template' must be set",
+		},
+		{
+			name:     "invalid template",
+			template: `[[inputs.sd_probe]]\n address = "{{.Address}"`,
+			expected: "parsing template failed",
+		},
+		{
+			name:     "multiple inputs",
+			template: "[[inputs.sd_probe]]\n[[inputs.sd_probe]]",
+			expected: "template must define exactly one input plugin",
+		},
+		{
+			name:     "unknown option",
+			template: "[[inputs.sd_probe]]\n  foo = \"bar\"",
+			expected: "foo",
+		},
+		{
+			name:     "service input",
+			template: "[[inputs.sd_listener]]",
+			expected: `service input "sd_listener" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &ServiceDiscovery{
+				Template: tt.template,
+				Config: discovery.Config{
+					File: []discovery.FileConfig{{Files: []string{"targets.json"}}},
+				},
+				Log: testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitNoSource(t *testing.T) {
+	plugin := &ServiceDiscovery{
+		Template: "[[inputs.sd_probe]]",
+		Log:      testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "no discovery source configured")
+}
+
+func TestGather(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "targets.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`[
+		{"targets": ["10.0.0.1:8080"], "labels": {"env": "prod"}},
+		{"targets": ["10.0.0.2:9090"], "labels": {"env": "dev"}}
+	]`), 0600))
+
+	plugin := &ServiceDiscovery{
+		RefreshInterval: config.Duration(time.Minute),
+		Template: `
+			[[inputs.sd_probe]]
+			  address = "{{.Host}}/{{.Port}}/{{.Tags.env}}"
+			  name_override = "target"
+		`,
+		Config: discovery.Config{
+			File: []discovery.FileConfig{{Files: []string{fn}, Tags: map[string]string{"source": "file"}}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.watcher.Refresh(context.Background()))
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"target",
+			map[string]string{"address": "10.0.0.1/8080/prod", "env": "prod", "source": "file"},
+			map[string]interface{}{"up": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"target",
+			map[string]string{"address": "10.0.0.2/9090/dev", "env": "dev", "source": "file"},
+			map[string]interface{}{"up": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Removed targets must not be gathered anymore
+	require.NoError(t, os.WriteFile(fn, []byte(`[{"targets": ["10.0.0.2:9090"], "labels": {"env": "dev"}}]`), 0600))
+	require.NoError(t, plugin.watcher.Refresh(context.Background()))
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected[1:], acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestStartStop(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "targets.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`[{"targets": ["10.0.0.1:8080"]}]`), 0600))
+
+	plugin := &ServiceDiscovery{
+		RefreshInterval: config.Duration(time.Minute),
+		Template:        "[[inputs.sd_probe]]\n  address = \"{{.Address}}\"",
+		Config: discovery.Config{
+			File: []discovery.FileConfig{{Files: []string{fn}}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, plugin.Gather(&acc))
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,81 @@
+package service_mesh
+
+import "strconv"
+
+// schema describes the metrics of the sidecar proxies of a service mesh
+type schema struct {
+	// Counter of requests and histogram of the request duration in
+	// milliseconds
+	requests string
+	duration string
+
+	// Labels forming the series key
+	sourceWorkload       string
+	sourceNamespace      string
+	destinationService   string
+	destinationWorkload  string
+	destinationNamespace string
+
+	// Label used if the destination service is unknown
+	serviceFallback string
+
+	filter  func(labels map[string]string) bool
+	isError func(labels map[string]string) bool
+}
+
+// Istio reports each request on both the client and the server side, so only
+// the reports of one side must be used to not count requests twice.
+func istioSchema(reporter string) *schema {
+	return &schema{
+		requests:             "istio_requests_total",
+		duration:             "istio_request_duration_milliseconds",
+		sourceWorkload:       "source_workload",
+		sourceNamespace:      "source_workload_namespace",
+		destinationService:   "destination_service",
+		destinationWorkload:  "destination_workload",
+		destinationNamespace: "destination_workload_namespace",
+		filter: func(labels map[string]string) bool {
+			return labels["reporter"] == reporter
+		},
+		isError: func(labels map[string]string) bool {
+			code, err := strconv.Atoi(labels["response_code"])
+			return err == nil && code >= 500
+		},
+	}
+}
+
+// Linkerd proxies only know the destination of outbound requests. The source
+// labels are not reported by the proxy itself but are added when scraping the
+// Prometheus instance of Linkerd Viz.
+func linkerdSchema() *schema {
+	return &schema{
+		requests:             "response_total",
+		duration:             "response_latency_ms",
+		sourceWorkload:       "deployment",
+		sourceNamespace:      "namespace",
+		destinationService:   "dst_service",
+		destinationWorkload:  "dst_deployment",
+		destinationNamespace: "dst_namespace",
+		serviceFallback:      "authority",
+		filter: func(labels map[string]string) bool {
+			return labels["direction"] == "outbound"
+		},
+		isError: func(labels map[string]string) bool {
+			return labels["classification"] == "failure"
+		},
+	}
+}
+
+func (s *schema) key(labels map[string]string) seriesKey {
+	service := labels[s.destinationService]
+	if service == "" && s.serviceFallback != "" {
+		service = labels[s.serviceFallback]
+	}
+	return seriesKey{
+		sourceWorkload:       labels[s.sourceWorkload],
+		sourceNamespace:      labels[s.sourceNamespace],
+		destinationService:   service,
+		destinationWorkload:  labels[s.destinationWorkload],
+		destinationNamespace: labels[s.destinationNamespace],
+	}
+}
@@ -0,0 +1,417 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package service_mesh
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ServiceMesh struct {
+	URLs      []string        `toml:"urls"`
+	Mesh      string          `toml:"mesh"`
+	Reporter  string          `toml:"reporter"`
+	Quantiles []float64       `toml:"quantiles"`
+	MaxSeries int             `toml:"max_series"`
+	Log       telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+	schema *schema
+
+	// Last scrape of each URL used to compute the changes in the interval
+	previous map[string]*scrape
+	sync.Mutex
+}
+
+// Series are identified by the service-to-service relation only, all other
+// labels such as the pod or response code are aggregated
+type seriesKey struct {
+	sourceWorkload       string
+	sourceNamespace      string
+	destinationService   string
+	destinationWorkload  string
+	destinationNamespace string
+}
+
+var otherKey = seriesKey{"other", "other", "other", "other", "other"}
+
+type scrape struct {
+	timestamp time.Time
+	series    map[seriesKey]*counters
+}
+
+// counters of a series, cumulative for a scrape or the change in the interval
+type counters struct {
+	requests float64
+	errors   float64
+	count    float64
+	sum      float64
+	buckets  map[float64]float64
+}
+
+type window struct {
+	counters
+	rate float64
+}
+
+func (*ServiceMesh) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *ServiceMesh) Init() error {
+	if len(s.URLs) == 0 {
+		return errors.New("'urls' required")
+	}
+
+	switch s.Mesh {
+	case "istio":
+		switch s.Reporter {
+		case "source", "destination":
+		default:
+			return fmt.Errorf("invalid 'reporter' %q", s.Reporter)
+		}
+		s.schema = istioSchema(s.Reporter)
+	case "linkerd":
+		s.schema = linkerdSchema()
+	default:
+		return fmt.Errorf("invalid 'mesh' %q", s.Mesh)
+	}
+
+	for _, q := range s.Quantiles {
+		if q <= 0 || q >= 1 {
+			return fmt.Errorf("invalid quantile %v", q)
+		}
+	}
+
+	if s.MaxSeries < 0 {
+		return fmt.Errorf("invalid 'max_series' %d", s.MaxSeries)
+	}
+
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	s.client = client
+	s.previous = make(map[string]*scrape, len(s.URLs))
+
+	return nil
+}
+
+func (s *ServiceMesh) Gather(acc telegraf.Accumulator) error {
+	scrapes := make([]*scrape, len(s.URLs))
+	var wg sync.WaitGroup
+	for i, u := range s.URLs {
+		wg.Add(1)
+		go func(idx int, address string) {
+			defer wg.Done()
+			current, err := s.scrape(address)
+			if err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", address, err))
+				return
+			}
+			scrapes[idx] = current
+		}(i, u)
+	}
+	wg.Wait()
+
+	s.Lock()
+	defer s.Unlock()
+
+	// Combine the changes since the previous scrape of all endpoints. Scrapes
+	// failing are skipped and the next successful scrape covers the gap.
+	windows := make(map[seriesKey]*window)
+	for i, current := range scrapes {
+		if current == nil {
+			continue
+		}
+		previous := s.previous[s.URLs[i]]
+		s.previous[s.URLs[i]] = current
+		if previous == nil {
+			continue
+		}
+
+		elapsed := current.timestamp.Sub(previous.timestamp).Seconds()
+		for key, c := range current.series {
+			delta := c.delta(previous.series[key])
+			if delta.requests == 0 && delta.count == 0 {
+				continue
+			}
+			w, found := windows[key]
+			if !found {
+				w = &window{counters: counters{buckets: make(map[float64]float64)}}
+				windows[key] = w
+			}
+			w.add(delta)
+			if elapsed > 0 {
+				w.rate += delta.requests / elapsed
+			}
+		}
+	}
+
+	s.limit(windows)
+
+	for key, w := range windows {
+		tags := map[string]string{
+			"mesh":                  s.Mesh,
+			"source_workload":       key.sourceWorkload,
+			"source_namespace":      key.sourceNamespace,
+			"destination_service":   key.destinationService,
+			"destination_workload":  key.destinationWorkload,
+			"destination_namespace": key.destinationNamespace,
+		}
+		fields := map[string]interface{}{
+			"requests":     uint64(w.requests),
+			"errors":       uint64(w.errors),
+			"request_rate": w.rate,
+		}
+		if w.requests > 0 {
+			fields["error_ratio"] = w.errors / w.requests
+		}
+		if w.count > 0 {
+			fields["duration_mean_ms"] = w.sum / w.count
+			for _, q := range s.Quantiles {
+				if v, ok := w.quantile(q); ok {
+					fields["duration_p"+strconv.FormatFloat(q*100, 'f', -1, 64)+"_ms"] = v
+				}
+			}
+		}
+		acc.AddFields("service_mesh", fields, tags)
+	}
+
+	return nil
+}
+
+func (s *ServiceMesh) Stop() {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+}
+
+// limit folds the series with the least requests into a single series if the
+// number of series exceeds the configured maximum
+func (s *ServiceMesh) limit(windows map[seriesKey]*window) {
+	if s.MaxSeries == 0 || len(windows) <= s.MaxSeries {
+		return
+	}
+
+	keys := make([]seriesKey, 0, len(windows))
+	for key := range windows {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		if d := windows[b].requests - windows[a].requests; d != 0 {
+			if d > 0 {
+				return 1
+			}
+			return -1
+		}
+		return compareKeys(a, b)
+	})
+
+	other := &window{counters: counters{buckets: make(map[float64]float64)}}
+	for _, key := range keys[s.MaxSeries-1:] {
+		other.add(&windows[key].counters)
+		other.rate += windows[key].rate
+		delete(windows, key)
+	}
+	if existing, found := windows[otherKey]; found {
+		other.add(&existing.counters)
+		other.rate += existing.rate
+	}
+	windows[otherKey] = other
+}
+
+func (s *ServiceMesh) scrape(address string) (*scrape, error) {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing metrics failed: %w", err)
+	}
+
+	result := &scrape{
+		timestamp: time.Now(),
+		series:    make(map[seriesKey]*counters),
+	}
+	get := func(key seriesKey) *counters {
+		c, found := result.series[key]
+		if !found {
+			c = &counters{buckets: make(map[float64]float64)}
+			result.series[key] = c
+		}
+		return c
+	}
+
+	for _, m := range families[s.schema.requests].GetMetric() {
+		labels := labelMap(m)
+		if !s.schema.filter(labels) {
+			continue
+		}
+		c := get(s.schema.key(labels))
+		c.requests += m.GetCounter().GetValue()
+		if s.schema.isError(labels) {
+			c.errors += m.GetCounter().GetValue()
+		}
+	}
+	for _, m := range families[s.schema.duration].GetMetric() {
+		labels := labelMap(m)
+		if !s.schema.filter(labels) {
+			continue
+		}
+		h := m.GetHistogram()
+		c := get(s.schema.key(labels))
+		c.count += float64(h.GetSampleCount())
+		c.sum += h.GetSampleSum()
+		for _, b := range h.GetBucket() {
+			c.buckets[b.GetUpperBound()] += float64(b.GetCumulativeCount())
+		}
+	}
+
+	return result, nil
+}
+
+// delta returns the change of the counters since the previous scrape. If any
+// of the counters decreased, e.g. due to a restart of a sidecar, the current
+// values are used as the change.
+func (c *counters) delta(previous *counters) *counters {
+	if previous == nil || c.requests < previous.requests || c.errors < previous.errors || c.count < previous.count {
+		return c
+	}
+	for le, v := range c.buckets {
+		if v < previous.buckets[le] {
+			return c
+		}
+	}
+
+	d := &counters{
+		requests: c.requests - previous.requests,
+		errors:   c.errors - previous.errors,
+		count:    c.count - previous.count,
+		sum:      c.sum - previous.sum,
+		buckets:  make(map[float64]float64, len(c.buckets)),
+	}
+	for le, v := range c.buckets {
+		d.buckets[le] = v - previous.buckets[le]
+	}
+	return d
+}
+
+func (c *counters) add(other *counters) {
+	c.requests += other.requests
+	c.errors += other.errors
+	c.count += other.count
+	c.sum += other.sum
+	for le, v := range other.buckets {
+		c.buckets[le] += v
+	}
+}
+
+// quantile estimates the given quantile from the histogram buckets by linear
+// interpolation within the bucket, similar to Prometheus' histogram_quantile
+func (c *counters) quantile(q float64) (float64, bool) {
+	bounds := make([]float64, 0, len(c.buckets))
+	for le := range c.buckets {
+		bounds = append(bounds, le)
+	}
+	slices.Sort(bounds)
+	if len(bounds) == 0 {
+		return 0, false
+	}
+
+	if c.count == 0 {
+		return 0, false
+	}
+
+	rank := q * c.count
+	var lower, below float64
+	for _, le := range bounds {
+		count := c.buckets[le]
+		if count >= rank {
+			// Observations above the highest finite bucket can only be
+			// estimated by its upper bound
+			if math.IsInf(le, 1) {
+				return lower, true
+			}
+			if count == below {
+				return le, true
+			}
+			return lower + (le-lower)*(rank-below)/(count-below), true
+		}
+		lower, below = le, count
+	}
+	return lower, true
+}
+
+func compareKeys(a, b seriesKey) int {
+	for _, pair := range [][2]string{
+		{a.sourceWorkload, b.sourceWorkload},
+		{a.sourceNamespace, b.sourceNamespace},
+		{a.destinationService, b.destinationService},
+		{a.destinationWorkload, b.destinationWorkload},
+		{a.destinationNamespace, b.destinationNamespace},
+	} {
+		switch {
+		case pair[0] < pair[1]:
+			return -1
+		case pair[0] > pair[1]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func labelMap(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+func init() {
+	inputs.Add("service_mesh", func() telegraf.Input {
+		return &ServiceMesh{
+			Mesh:      "istio",
+			Reporter:  "destination",
+			Quantiles: []float64{0.5, 0.9, 0.99},
+			MaxSeries: 1000,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
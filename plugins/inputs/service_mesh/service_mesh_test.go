@@ -0,0 +1,253 @@
+package service_mesh
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// newServer serves the given files in consecutive requests
+func newServer(t *testing.T, files ...string) *httptest.Server {
+	var requests atomic.Int64
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		idx := min(int(requests.Add(1))-1, len(files)-1)
+		buf, err := os.ReadFile(filepath.Join("testdata", files[idx]))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *ServiceMesh
+		expected string
+	}{
+		{
+			name:     "missing urls",
+			plugin:   &ServiceMesh{Mesh: "istio", Reporter: "destination"},
+			expected: "'urls' required",
+		},
+		{
+			name:     "invalid mesh",
+			plugin:   &ServiceMesh{URLs: []string{"http://localhost"}, Mesh: "consul"},
+			expected: `invalid 'mesh' "consul"`,
+		},
+		{
+			name:     "invalid reporter",
+			plugin:   &ServiceMesh{URLs: []string{"http://localhost"}, Mesh: "istio", Reporter: "both"},
+			expected: `invalid 'reporter' "both"`,
+		},
+		{
+			name:     "invalid quantile",
+			plugin:   &ServiceMesh{URLs: []string{"http://localhost"}, Mesh: "linkerd", Quantiles: []float64{99}},
+			expected: "invalid quantile 99",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherIstio(t *testing.T) {
+	server := newServer(t, "istio_1.txt", "istio_2.txt")
+	defer server.Close()
+
+	plugin := &ServiceMesh{
+		URLs:      []string{server.URL},
+		Mesh:      "istio",
+		Reporter:  "destination",
+		Quantiles: []float64{0.5, 0.9, 0.99},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first scrape only establishes the baseline
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	plugin.previous[server.URL].timestamp = time.Now().Add(-10 * time.Second)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"service_mesh",
+			map[string]string{
+				"mesh":                  "istio",
+				"source_workload":       "frontend",
+				"source_namespace":      "shop",
+				"destination_service":   "cart.shop.svc.cluster.local",
+				"destination_workload":  "cart",
+				"destination_namespace": "shop",
+			},
+			map[string]interface{}{
+				"requests":         uint64(100),
+				"errors":           uint64(20),
+				"request_rate":     10.0,
+				"error_ratio":      0.2,
+				"duration_mean_ms": 12.0,
+				"duration_p50_ms":  6.6667,
+				"duration_p90_ms":  25.0,
+				"duration_p99_ms":  25.0,
+			},
+			time.Unix(0, 0),
+		),
+		// Counter reset by a restart of the sidecar
+		testutil.MustMetric(
+			"service_mesh",
+			map[string]string{
+				"mesh":                  "istio",
+				"source_workload":       "checkout",
+				"source_namespace":      "shop",
+				"destination_service":   "payment.shop.svc.cluster.local",
+				"destination_workload":  "payment",
+				"destination_namespace": "shop",
+			},
+			map[string]interface{}{
+				"requests":         uint64(4),
+				"errors":           uint64(0),
+				"request_rate":     0.4,
+				"error_ratio":      0.0,
+				"duration_mean_ms": 10.0,
+				"duration_p50_ms":  10.0,
+				"duration_p90_ms":  22.0,
+				"duration_p99_ms":  24.7,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.SortMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0.01, 0))
+}
+
+func TestGatherLinkerd(t *testing.T) {
+	server := newServer(t, "linkerd_1.txt", "linkerd_2.txt")
+	defer server.Close()
+
+	plugin := &ServiceMesh{
+		URLs:      []string{server.URL},
+		Mesh:      "linkerd",
+		Quantiles: []float64{0.5, 0.99},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.previous[server.URL].timestamp = time.Now().Add(-10 * time.Second)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"service_mesh",
+			map[string]string{
+				"mesh":                  "linkerd",
+				"source_workload":       "web",
+				"source_namespace":      "emojivoto",
+				"destination_service":   "emoji-svc",
+				"destination_workload":  "emoji",
+				"destination_namespace": "emojivoto",
+			},
+			map[string]interface{}{
+				"requests":         uint64(60),
+				"errors":           uint64(10),
+				"request_rate":     6.0,
+				"error_ratio":      0.1667,
+				"duration_mean_ms": 1.5,
+				"duration_p50_ms":  1.6667,
+				"duration_p99_ms":  2.0,
+			},
+			time.Unix(0, 0),
+		),
+		// Requests to destinations outside of the mesh
+		testutil.MustMetric(
+			"service_mesh",
+			map[string]string{
+				"mesh":                  "linkerd",
+				"source_workload":       "web",
+				"source_namespace":      "emojivoto",
+				"destination_service":   "api.example.com:443",
+				"destination_workload":  "",
+				"destination_namespace": "",
+			},
+			map[string]interface{}{
+				"requests":     uint64(2),
+				"errors":       uint64(0),
+				"request_rate": 0.2,
+				"error_ratio":  0.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.SortMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0.01, 0))
+}
+
+func TestMaxSeries(t *testing.T) {
+	plugin := &ServiceMesh{MaxSeries: 3}
+
+	windows := make(map[seriesKey]*window)
+	for i := range 5 {
+		key := seriesKey{sourceWorkload: fmt.Sprintf("client%d", i), destinationService: "backend"}
+		windows[key] = &window{
+			counters: counters{
+				requests: float64(10 * (i + 1)),
+				errors:   1,
+				buckets:  map[float64]float64{},
+			},
+			rate: float64(i + 1),
+		}
+	}
+	plugin.limit(windows)
+
+	require.Len(t, windows, 3)
+	require.Contains(t, windows, seriesKey{sourceWorkload: "client4", destinationService: "backend"})
+	require.Contains(t, windows, seriesKey{sourceWorkload: "client3", destinationService: "backend"})
+	require.Equal(t, 60.0, windows[otherKey].requests)
+	require.Equal(t, 3.0, windows[otherKey].errors)
+	require.Equal(t, 6.0, windows[otherKey].rate)
+}
+
+func TestGatherFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	plugin := &ServiceMesh{
+		URLs:     []string{server.URL},
+		Mesh:     "istio",
+		Reporter: "destination",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "404 Not Found")
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -27,6 +28,9 @@ type Snmp struct {
 
 	snmp.ClientConfig
 
+	// Credentials overriding the client configuration for specific agents
+	AgentCredentials []agentCredentials `toml:"agent_credentials"`
+
 	Tables []snmp.Table `toml:"table"`
 
 	// Name & Fields are the elements of a Table.
@@ -39,9 +43,31 @@ type Snmp struct {
 
 	connectionCache []snmp.Connection
 
+	// Client configuration of each agent including the credential overrides
+	agentConfigs []snmp.ClientConfig
+
 	translator snmp.Translator
 }
 
+// agentCredentials holds the credentials for the listed agents overriding
+// the ones of the plugin. Empty settings are inherited from the plugin.
+type agentCredentials struct {
+	Agents       []string      `toml:"agents"`
+	Community    string        `toml:"community"`
+	ContextName  string        `toml:"context_name"`
+	SecLevel     string        `toml:"sec_level"`
+	SecName      string        `toml:"sec_name"`
+	AuthProtocol string        `toml:"auth_protocol"`
+	AuthPassword config.Secret `toml:"auth_password"`
+	PrivProtocol string        `toml:"priv_protocol"`
+	PrivPassword config.Secret `toml:"priv_password"`
+}
+
+// contextConnection is a connection allowing to switch the SNMPv3 context.
+type contextConnection interface {
+	SetContextName(name string)
+}
+
 func (*Snmp) SampleConfig() string {
 	return sampleConfig
 }
@@ -66,7 +92,32 @@ func (s *Snmp) Init() error {
 
 	s.connectionCache = make([]snmp.Connection, len(s.Agents))
 
+	s.agentConfigs = make([]snmp.ClientConfig, len(s.Agents))
+	for i := range s.Agents {
+		s.agentConfigs[i] = s.ClientConfig
+	}
+	overridden := make(map[int]bool, len(s.Agents))
+	for _, creds := range s.AgentCredentials {
+		if len(creds.Agents) == 0 {
+			return errors.New("no agents specified for agent credentials")
+		}
+		for _, agent := range creds.Agents {
+			idx := slices.Index(s.Agents, agent)
+			if idx < 0 {
+				return fmt.Errorf("agent %q of agent credentials not found in agents", agent)
+			}
+			if overridden[idx] {
+				return fmt.Errorf("multiple agent credentials for agent %q", agent)
+			}
+			overridden[idx] = true
+			creds.apply(&s.agentConfigs[idx])
+		}
+	}
+
 	for i := range s.Tables {
+		if s.Tables[i].ContextName != "" && s.Version != 3 {
+			return fmt.Errorf("context name of table %s requires SNMP version 3", s.Tables[i].Name)
+		}
 		if err := s.Tables[i].Init(s.translator); err != nil {
 			return fmt.Errorf("initializing table %s: %w", s.Tables[i].Name, err)
 		}
@@ -115,7 +166,7 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 
 			// Now is the real tables.
 			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+				if err := s.gatherContextTable(acc, gs, t, topTags, s.agentConfig(i).ContextName); err != nil {
 					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
 				}
 			}
@@ -126,6 +177,23 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// gatherContextTable gathers the table in its SNMPv3 context, if any, and
+// switches the connection back to the given default context afterwards.
+func (s *Snmp) gatherContextTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.Table, topTags map[string]string, defaultContext string) error {
+	if t.ContextName == "" {
+		return s.gatherTable(acc, gs, t, topTags, true)
+	}
+
+	cc, ok := gs.(contextConnection)
+	if !ok {
+		return errors.New("connection does not support contexts")
+	}
+	cc.SetContextName(t.ContextName)
+	defer cc.SetContextName(defaultContext)
+
+	return s.gatherTable(acc, gs, t, topTags, true)
+}
+
 func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.Table, topTags map[string]string, walk bool) error {
 	rt, err := t.Build(gs, walk)
 	if err != nil {
@@ -149,6 +217,11 @@ func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.
 		if _, ok := tr.Tags[s.AgentHostTag]; !ok {
 			tr.Tags[s.AgentHostTag] = gs.Host()
 		}
+		if t.ContextName != "" {
+			if _, ok := tr.Tags["context_name"]; !ok {
+				tr.Tags["context_name"] = t.ContextName
+			}
+		}
 		acc.AddFields(rt.Name, tr.Fields, tr.Tags, rt.Time)
 	}
 
@@ -170,7 +243,7 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 
 	agent := s.Agents[idx]
 
-	gs, err := snmp.NewWrapper(s.ClientConfig)
+	gs, err := snmp.NewWrapper(s.agentConfig(idx))
 	if err != nil {
 		return nil, err
 	}
@@ -189,6 +262,44 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 	return gs, nil
 }
 
+// agentConfig returns the client configuration of the agent with the given
+// index including its credential overrides.
+func (s *Snmp) agentConfig(idx int) snmp.ClientConfig {
+	if idx < len(s.agentConfigs) {
+		return s.agentConfigs[idx]
+	}
+	return s.ClientConfig
+}
+
+// apply overrides the credentials of the given configuration with the
+// non-empty settings.
+func (c *agentCredentials) apply(cfg *snmp.ClientConfig) {
+	if c.Community != "" {
+		cfg.Community = c.Community
+	}
+	if c.ContextName != "" {
+		cfg.ContextName = c.ContextName
+	}
+	if c.SecLevel != "" {
+		cfg.SecLevel = c.SecLevel
+	}
+	if c.SecName != "" {
+		cfg.SecName = c.SecName
+	}
+	if c.AuthProtocol != "" {
+		cfg.AuthProtocol = c.AuthProtocol
+	}
+	if !c.AuthPassword.Empty() {
+		cfg.AuthPassword = c.AuthPassword
+	}
+	if c.PrivProtocol != "" {
+		cfg.PrivProtocol = c.PrivProtocol
+	}
+	if !c.PrivPassword.Empty() {
+		cfg.PrivPassword = c.PrivPassword
+	}
+}
+
 func init() {
 	inputs.Add("snmp", func() telegraf.Input {
 		return &Snmp{
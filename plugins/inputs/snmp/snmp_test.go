@@ -62,6 +62,17 @@ func (*testSNMPConnection) Reconnect() error {
 	return nil
 }
 
+type testContextSNMPConnection struct {
+	*testSNMPConnection
+	context  string
+	switched []string
+}
+
+func (tsc *testContextSNMPConnection) SetContextName(name string) {
+	tsc.context = name
+	tsc.switched = append(tsc.switched, name)
+}
+
 var tsc = &testSNMPConnection{
 	host: "tsc",
 	values: map[string]interface{}{
@@ -278,6 +289,108 @@ func TestGetSNMPConnection_v3(t *testing.T) {
 	require.EqualValues(t, 2, sp.AuthoritativeEngineTime)
 }
 
+func TestGetSNMPConnection_agentCredentials(t *testing.T) {
+	s := &Snmp{
+		Agents: []string{"1.2.3.4", "1.2.3.5"},
+		ClientConfig: snmp.ClientConfig{
+			Version:      3,
+			ContextName:  "mycontext",
+			SecLevel:     "authNoPriv",
+			SecName:      "myuser",
+			AuthProtocol: "md5",
+			AuthPassword: config.NewSecret([]byte("password123")),
+			Translator:   "netsnmp",
+		},
+		AgentCredentials: []agentCredentials{
+			{
+				Agents:       []string{"1.2.3.5"},
+				ContextName:  "vrf-blue",
+				SecLevel:     "authPriv",
+				SecName:      "otheruser",
+				AuthProtocol: "sha",
+				AuthPassword: config.NewSecret([]byte("otherpassword")),
+				PrivProtocol: "aes",
+				PrivPassword: config.NewSecret([]byte("321drowssap")),
+			},
+		},
+	}
+	require.NoError(t, s.Init())
+
+	gsc, err := s.getConnection(0)
+	require.NoError(t, err)
+	gs := gsc.(snmp.GosnmpWrapper)
+	sp := gs.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	require.Equal(t, "mycontext", gs.ContextName)
+	require.Equal(t, "myuser", sp.UserName)
+	require.Equal(t, gosnmp.MD5, sp.AuthenticationProtocol)
+	require.Equal(t, "password123", sp.AuthenticationPassphrase)
+	require.Equal(t, gosnmp.NoPriv, sp.PrivacyProtocol)
+
+	gsc, err = s.getConnection(1)
+	require.NoError(t, err)
+	gs = gsc.(snmp.GosnmpWrapper)
+	sp = gs.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	require.Equal(t, "vrf-blue", gs.ContextName)
+	require.Equal(t, gosnmp.AuthPriv, gs.MsgFlags&gosnmp.AuthPriv)
+	require.Equal(t, "otheruser", sp.UserName)
+	require.Equal(t, gosnmp.SHA, sp.AuthenticationProtocol)
+	require.Equal(t, "otherpassword", sp.AuthenticationPassphrase)
+	require.Equal(t, gosnmp.AES, sp.PrivacyProtocol)
+	require.Equal(t, "321drowssap", sp.PrivacyPassphrase)
+}
+
+func TestSnmpInit_agentCredentialsInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials []agentCredentials
+		expected    string
+	}{
+		{
+			name:        "no agents",
+			credentials: []agentCredentials{{SecName: "myuser"}},
+			expected:    "no agents specified for agent credentials",
+		},
+		{
+			name:        "unknown agent",
+			credentials: []agentCredentials{{Agents: []string{"1.2.3.5"}}},
+			expected:    `agent "1.2.3.5" of agent credentials not found in agents`,
+		},
+		{
+			name: "duplicate agent",
+			credentials: []agentCredentials{
+				{Agents: []string{"1.2.3.4"}},
+				{Agents: []string{"1.2.3.4"}},
+			},
+			expected: `multiple agent credentials for agent "1.2.3.4"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Snmp{
+				Agents:           []string{"1.2.3.4"},
+				ClientConfig:     snmp.ClientConfig{Version: 3, Translator: "netsnmp"},
+				AgentCredentials: tt.credentials,
+			}
+			require.ErrorContains(t, s.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSnmpInit_tableContextRequiresV3(t *testing.T) {
+	s := &Snmp{
+		Agents:       []string{"1.2.3.4"},
+		ClientConfig: snmp.ClientConfig{Version: 2, Translator: "netsnmp"},
+		Tables: []snmp.Table{
+			{
+				Name:        "mytable",
+				ContextName: "vrf-blue",
+			},
+		},
+	}
+	require.ErrorContains(t, s.Init(), "context name of table mytable requires SNMP version 3")
+}
+
 func TestGetSNMPConnection_v3_blumenthal(t *testing.T) {
 	testCases := []struct {
 		Name      string
@@ -590,6 +703,58 @@ func TestGather(t *testing.T) {
 	require.Equal(t, 123456, m2.Fields["myOtherField"])
 }
 
+func TestGather_tableContext(t *testing.T) {
+	conn := &testContextSNMPConnection{
+		testSNMPConnection: tsc,
+		context:            "default",
+	}
+	s := &Snmp{
+		Agents: []string{"TestGather"},
+		Name:   "mytable",
+		Tables: []snmp.Table{
+			{
+				Name:        "myContextTable",
+				ContextName: "vrf-blue",
+				Fields: []snmp.Field{
+					{
+						Name: "myOtherField",
+						Oid:  ".1.0.0.0.1.5",
+					},
+				},
+			},
+			{
+				Name: "myOtherTable",
+				Fields: []snmp.Field{
+					{
+						Name: "myOtherField",
+						Oid:  ".1.0.0.0.1.5",
+					},
+				},
+			},
+		},
+		agentConfigs: []snmp.ClientConfig{
+			{ContextName: "default"},
+		},
+
+		connectionCache: []snmp.Connection{
+			conn,
+		},
+	}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+
+	// The context should be switched for the table with a context and
+	// restored afterwards
+	require.Equal(t, []string{"vrf-blue", "default"}, conn.switched)
+	require.Equal(t, "default", conn.context)
+
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, "myContextTable", acc.Metrics[0].Measurement)
+	require.Equal(t, "vrf-blue", acc.Metrics[0].Tags["context_name"])
+	require.Equal(t, "myOtherTable", acc.Metrics[1].Measurement)
+	require.NotContains(t, acc.Metrics[1].Tags, "context_name")
+}
+
 func TestGather_host(t *testing.T) {
 	s := &Snmp{
 		Agents: []string{"TestGather"},
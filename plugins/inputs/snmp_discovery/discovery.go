@@ -0,0 +1,319 @@
+package snmp_discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+// maxNetworkBits limits the number of hosts to probe in a sweep
+const maxNetworkBits = 16
+
+// sysObjectID is queried to check if an agent responds to the credentials
+const sysObjectID = ".1.3.6.1.2.1.1.2.0"
+
+// Credential is a set of SNMP credentials to try for the targets
+type Credential struct {
+	Name         string        `toml:"name"`
+	Version      uint8         `toml:"version"`
+	Community    string        `toml:"community"`
+	ContextName  string        `toml:"context_name"`
+	SecLevel     string        `toml:"sec_level"`
+	SecName      string        `toml:"sec_name"`
+	AuthProtocol string        `toml:"auth_protocol"`
+	AuthPassword config.Secret `toml:"auth_password"`
+	PrivProtocol string        `toml:"priv_protocol"`
+	PrivPassword config.Secret `toml:"priv_password"`
+}
+
+// clientConfig returns the client configuration using the credentials on top
+// of the given base configuration
+func (c *Credential) clientConfig(base snmp.ClientConfig) snmp.ClientConfig {
+	cfg := base
+	if c.Version != 0 {
+		cfg.Version = c.Version
+	}
+	if c.Community != "" {
+		cfg.Community = c.Community
+	}
+	if c.ContextName != "" {
+		cfg.ContextName = c.ContextName
+	}
+	if c.SecLevel != "" {
+		cfg.SecLevel = c.SecLevel
+	}
+	if c.SecName != "" {
+		cfg.SecName = c.SecName
+	}
+	if c.AuthProtocol != "" {
+		cfg.AuthProtocol = c.AuthProtocol
+	}
+	if !c.AuthPassword.Empty() {
+		cfg.AuthPassword = c.AuthPassword
+	}
+	if c.PrivProtocol != "" {
+		cfg.PrivProtocol = c.PrivProtocol
+	}
+	if !c.PrivPassword.Empty() {
+		cfg.PrivPassword = c.PrivPassword
+	}
+	return cfg
+}
+
+// inventoryEntry is a target as provided by the inventory endpoint
+type inventoryEntry struct {
+	Agent       string            `json:"agent"`
+	Credentials string            `json:"credentials"`
+	Tags        map[string]string `json:"tags"`
+
+	fromInventory bool
+}
+
+// discover determines the current targets and replaces the known targets.
+// Connections of targets still present with the same credentials are kept.
+func (d *SnmpDiscovery) discover(ctx context.Context) error {
+	candidates := make(map[string]inventoryEntry)
+	for _, network := range d.networks {
+		for _, ip := range hosts(network) {
+			agent := "udp://" + net.JoinHostPort(ip.String(), strconv.Itoa(int(d.Port)))
+			candidates[agent] = inventoryEntry{Agent: agent}
+		}
+	}
+
+	// Inventory entries take precedence over swept hosts as they might
+	// specify credentials and tags
+	if d.InventoryURL != "" {
+		entries, err := d.fetchInventory(ctx)
+		if err != nil {
+			return fmt.Errorf("querying inventory failed: %w", err)
+		}
+		for _, e := range entries {
+			if e.Agent == "" {
+				d.Log.Warn("Ignoring inventory entry without agent")
+				continue
+			}
+			if e.Credentials != "" && d.credential(e.Credentials) == nil {
+				d.Log.Warnf("Ignoring agent %q with unknown credentials %q", e.Agent, e.Credentials)
+				continue
+			}
+			e.fromInventory = true
+			candidates[e.Agent] = e
+		}
+	}
+
+	discovered := d.probeCandidates(ctx, candidates)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var kept, removed int
+	for agent, t := range d.targets {
+		if n, found := discovered[agent]; found && n.credential == t.credential {
+			// Keep the existing target including its connection
+			t.mu.Lock()
+			t.tags = n.tags
+			t.mu.Unlock()
+			discovered[agent] = t
+			kept++
+			continue
+		}
+		t.close()
+		removed++
+	}
+	added := len(discovered) - kept
+	d.targets = discovered
+
+	d.Log.Debugf("Discovered %d targets, %d added and %d removed", len(discovered), added, removed)
+	return nil
+}
+
+// probeCandidates determines the credentials of the candidates by probing
+// the agents with the configured credentials in order
+func (d *SnmpDiscovery) probeCandidates(ctx context.Context, candidates map[string]inventoryEntry) map[string]*target {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	discovered := make(map[string]*target, len(candidates))
+	sem := make(chan struct{}, d.MaxConcurrency)
+	for _, candidate := range candidates {
+		// Credentials given by the inventory do not require probing
+		if candidate.Credentials != "" {
+			c := d.credential(candidate.Credentials)
+			mu.Lock()
+			discovered[candidate.Agent] = &target{
+				agent:      candidate.Agent,
+				credential: c.Name,
+				config:     c.clientConfig(d.ClientConfig),
+				tags:       maps.Clone(candidate.Tags),
+			}
+			mu.Unlock()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(candidate inventoryEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for i := range d.Credentials {
+				c := &d.Credentials[i]
+				cfg := c.clientConfig(d.ClientConfig)
+
+				probeCfg := cfg
+				probeCfg.Timeout = d.ProbeTimeout
+				probeCfg.Retries = 0
+				if err := d.probe(candidate.Agent, probeCfg); err != nil {
+					d.Log.Tracef("Probing %q with credentials %q failed: %v", candidate.Agent, c.Name, err)
+					continue
+				}
+
+				mu.Lock()
+				discovered[candidate.Agent] = &target{
+					agent:      candidate.Agent,
+					credential: c.Name,
+					config:     cfg,
+					tags:       maps.Clone(candidate.Tags),
+				}
+				mu.Unlock()
+				return
+			}
+
+			// Targets from the inventory are expected to respond
+			if candidate.fromInventory {
+				d.Log.Warnf("Agent %q did not respond to any of the credentials", candidate.Agent)
+			}
+		}(candidate)
+	}
+	wg.Wait()
+
+	return discovered
+}
+
+func (d *SnmpDiscovery) credential(name string) *Credential {
+	for i := range d.Credentials {
+		if d.Credentials[i].Name == name {
+			return &d.Credentials[i]
+		}
+	}
+	return nil
+}
+
+func (d *SnmpDiscovery) fetchInventory(ctx context.Context) ([]inventoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(d.InventoryTimeout))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.InventoryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if !d.InventoryToken.Empty() {
+		token, err := d.InventoryToken.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var entries []inventoryEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return entries, nil
+}
+
+// hosts returns the host addresses of the network excluding the network and
+// broadcast address for IPv4 networks larger than two addresses
+func hosts(network *net.IPNet) []net.IP {
+	ones, bits := network.Mask.Size()
+	size := 1 << (bits - ones)
+
+	start := network.IP.Mask(network.Mask)
+	addresses := make([]net.IP, 0, size)
+	for i := 0; i < size; i++ {
+		ip := make(net.IP, len(start))
+		copy(ip, start)
+		// Add the offset to the network address
+		carry := i
+		for j := len(ip) - 1; j >= 0 && carry > 0; j-- {
+			sum := int(ip[j]) + carry
+			ip[j] = byte(sum)
+			carry = sum >> 8
+		}
+		addresses = append(addresses, ip)
+	}
+
+	if network.IP.To4() != nil && size > 2 {
+		return addresses[1 : size-1]
+	}
+	return addresses
+}
+
+func probeAgent(agent string, cfg snmp.ClientConfig) error {
+	conn, err := connectAgent(agent, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.(snmp.GosnmpWrapper).Conn.Close()
+
+	packet, err := conn.Get([]string{sysObjectID})
+	if err != nil {
+		return err
+	}
+	if packet.Error != gosnmp.NoError {
+		return fmt.Errorf("received error %v", packet.Error)
+	}
+	if len(packet.Variables) == 0 {
+		return errors.New("empty response")
+	}
+	switch packet.Variables[0].Type {
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.Null:
+		return errors.New("missing sysObjectID")
+	}
+	return nil
+}
+
+func connectAgent(agent string, cfg snmp.ClientConfig) (snmp.Connection, error) {
+	conn, err := snmp.NewWrapper(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetAgent(agent); err != nil {
+		return nil, err
+	}
+	if err := conn.Connect(); err != nil {
+		return nil, fmt.Errorf("setting up connection: %w", err)
+	}
+	return conn, nil
+}
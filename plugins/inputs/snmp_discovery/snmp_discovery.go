@@ -0,0 +1,320 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package snmp_discovery
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SnmpDiscovery struct {
+	// Targets to discover, either by probing all hosts of the given networks
+	// or by querying an inventory endpoint
+	Networks         []string        `toml:"networks"`
+	Port             uint16          `toml:"port"`
+	InventoryURL     string          `toml:"inventory_url"`
+	InventoryToken   config.Secret   `toml:"inventory_token"`
+	InventoryTimeout config.Duration `toml:"inventory_timeout"`
+	RefreshInterval  config.Duration `toml:"refresh_interval"`
+	ProbeTimeout     config.Duration `toml:"probe_timeout"`
+	MaxConcurrency   int             `toml:"max_concurrency"`
+	Credentials      []Credential    `toml:"credentials"`
+
+	// The tag used to name the agent host
+	AgentHostTag string `toml:"agent_host_tag"`
+
+	snmp.ClientConfig
+
+	Tables []snmp.Table `toml:"table"`
+	Name   string       `toml:"name"`
+	Fields []snmp.Field `toml:"field"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	translator snmp.Translator
+	networks   []*net.IPNet
+	probe      func(agent string, cfg snmp.ClientConfig) error
+	connect    func(agent string, cfg snmp.ClientConfig) (snmp.Connection, error)
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	targets map[string]*target
+}
+
+// target is a discovered SNMP agent together with the credentials the agent
+// responded to
+type target struct {
+	agent      string
+	credential string
+	config     snmp.ClientConfig
+
+	mu   sync.Mutex
+	tags map[string]string
+	conn snmp.Connection
+}
+
+func (*SnmpDiscovery) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *SnmpDiscovery) SetTranslator(name string) {
+	d.Translator = name
+}
+
+func (d *SnmpDiscovery) Init() error {
+	if len(d.Networks) == 0 && d.InventoryURL == "" {
+		return errors.New("either 'networks' or 'inventory_url' must be set")
+	}
+
+	var hosts uint64
+	for _, n := range d.Networks {
+		_, network, err := net.ParseCIDR(n)
+		if err != nil {
+			return fmt.Errorf("invalid network %q: %w", n, err)
+		}
+		ones, bits := network.Mask.Size()
+		if bits-ones > maxNetworkBits {
+			return fmt.Errorf("network %q exceeds the maximum of %d hosts", n, 1<<maxNetworkBits)
+		}
+		hosts += 1 << (bits - ones)
+		d.networks = append(d.networks, network)
+	}
+	if hosts > 1<<maxNetworkBits {
+		return fmt.Errorf("networks exceed the maximum of %d hosts", 1<<maxNetworkBits)
+	}
+
+	if d.MaxConcurrency < 1 {
+		return errors.New("'max_concurrency' must be positive")
+	}
+
+	// Use the client settings as credentials if none are given explicitly
+	if len(d.Credentials) == 0 {
+		d.Credentials = []Credential{{Name: "default"}}
+	}
+	seen := make(map[string]bool, len(d.Credentials))
+	for _, c := range d.Credentials {
+		if c.Name == "" {
+			return errors.New("credentials require a 'name'")
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate credentials %q", c.Name)
+		}
+		seen[c.Name] = true
+
+		if _, err := snmp.NewWrapper(c.clientConfig(d.ClientConfig)); err != nil {
+			return fmt.Errorf("invalid credentials %q: %w", c.Name, err)
+		}
+	}
+
+	var err error
+	switch d.Translator {
+	case "gosmi":
+		d.translator, err = snmp.NewGosmiTranslator(d.Path, d.Log)
+		if err != nil {
+			return err
+		}
+	case "netsnmp":
+		d.translator = snmp.NewNetsnmpTranslator(d.Log)
+	default:
+		return errors.New("invalid translator value")
+	}
+
+	for i := range d.Tables {
+		if err := d.Tables[i].Init(d.translator); err != nil {
+			return fmt.Errorf("initializing table %s: %w", d.Tables[i].Name, err)
+		}
+	}
+
+	for i := range d.Fields {
+		if err := d.Fields[i].Init(d.translator); err != nil {
+			return fmt.Errorf("initializing field %s: %w", d.Fields[i].Name, err)
+		}
+	}
+
+	if d.AgentHostTag == "" {
+		d.AgentHostTag = "source"
+	}
+
+	d.probe = probeAgent
+	d.connect = connectAgent
+	d.targets = make(map[string]*target)
+
+	return nil
+}
+
+func (d *SnmpDiscovery) Start(telegraf.Accumulator) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(d.RefreshInterval))
+		defer ticker.Stop()
+		for {
+			if err := d.discover(ctx); err != nil && ctx.Err() == nil {
+				d.Log.Errorf("Discovering targets failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (d *SnmpDiscovery) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range d.targets {
+		t.close()
+	}
+	d.targets = make(map[string]*target)
+}
+
+func (d *SnmpDiscovery) Gather(acc telegraf.Accumulator) error {
+	d.mu.Lock()
+	targets := make([]*target, 0, len(d.targets))
+	for _, t := range d.targets {
+		targets = append(targets, t)
+	}
+	d.mu.Unlock()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].agent < targets[j].agent })
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			d.gatherTarget(acc, t)
+		}(t)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (d *SnmpDiscovery) gatherTarget(acc telegraf.Accumulator, t *target) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		conn, err := d.connect(t.agent, t.config)
+		if err != nil {
+			acc.AddError(fmt.Errorf("agent %s: %w", t.agent, err))
+			return
+		}
+		t.conn = conn
+	} else if err := t.conn.Reconnect(); err != nil {
+		acc.AddError(fmt.Errorf("agent %s: reconnecting: %w", t.agent, err))
+		return
+	}
+
+	// First is the top-level fields. We treat the fields as table prefixes with an empty index.
+	topTags := make(map[string]string)
+	table := snmp.Table{
+		Name:   d.Name,
+		Fields: d.Fields,
+	}
+	if err := d.gatherTable(acc, t, table, topTags, false); err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", t.agent, err))
+	}
+
+	// Now is the real tables.
+	for _, table := range d.Tables {
+		if err := d.gatherTable(acc, t, table, topTags, true); err != nil {
+			acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", t.agent, table.Name, err))
+		}
+	}
+}
+
+func (d *SnmpDiscovery) gatherTable(acc telegraf.Accumulator, t *target, table snmp.Table, topTags map[string]string, walk bool) error {
+	rt, err := table.Build(t.conn, walk)
+	if err != nil {
+		return err
+	}
+
+	for _, tr := range rt.Rows {
+		if !walk {
+			// top-level table. Add tags to topTags.
+			for k, v := range tr.Tags {
+				topTags[k] = v
+			}
+		} else {
+			// real table. Inherit any specified tags.
+			for _, k := range table.InheritTags {
+				if v, ok := topTags[k]; ok {
+					tr.Tags[k] = v
+				}
+			}
+		}
+		// Add the tags provided by the inventory
+		for k, v := range t.tags {
+			if _, ok := tr.Tags[k]; !ok {
+				tr.Tags[k] = v
+			}
+		}
+		if _, ok := tr.Tags[d.AgentHostTag]; !ok {
+			tr.Tags[d.AgentHostTag] = t.conn.Host()
+		}
+		acc.AddFields(rt.Name, tr.Fields, tr.Tags, rt.Time)
+	}
+
+	return nil
+}
+
+// close closes the connection of the target if any, the caller must not hold
+// the lock of the target
+func (t *target) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if w, ok := t.conn.(snmp.GosnmpWrapper); ok && w.Conn != nil {
+		w.Conn.Close()
+	}
+	t.conn = nil
+}
+
+func init() {
+	inputs.Add("snmp_discovery", func() telegraf.Input {
+		return &SnmpDiscovery{
+			Name:             "snmp",
+			Port:             161,
+			InventoryTimeout: config.Duration(5 * time.Second),
+			RefreshInterval:  config.Duration(time.Hour),
+			ProbeTimeout:     config.Duration(time.Second),
+			MaxConcurrency:   64,
+			ClientConfig: snmp.ClientConfig{
+				Retries:        3,
+				MaxRepetitions: 10,
+				Timeout:        config.Duration(5 * time.Second),
+				Version:        2,
+				Path:           []string{"/usr/share/snmp/mibs"},
+				Community:      "public",
+			},
+		}
+	})
+}
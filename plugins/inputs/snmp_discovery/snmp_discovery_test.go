@@ -0,0 +1,330 @@
+package snmp_discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type testConnection struct {
+	host   string
+	values map[string]interface{}
+}
+
+func (c *testConnection) Host() string {
+	return c.host
+}
+
+func (c *testConnection) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	sp := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		v, ok := c.values[oid]
+		if !ok {
+			sp.Variables = append(sp.Variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject})
+			continue
+		}
+		sp.Variables = append(sp.Variables, gosnmp.SnmpPDU{Name: oid, Value: v})
+	}
+	return sp, nil
+}
+
+func (*testConnection) Walk(string, gosnmp.WalkFunc) error {
+	return nil
+}
+
+func (*testConnection) Reconnect() error {
+	return nil
+}
+
+// testAgents simulates agents responding to the given credentials
+type testAgents struct {
+	sync.Mutex
+	credentials map[string]string
+	connects    map[string]int
+}
+
+func (a *testAgents) probe(agent string, cfg snmp.ClientConfig) error {
+	a.Lock()
+	defer a.Unlock()
+	if community, found := a.credentials[agent]; found && community == cfg.Community {
+		return nil
+	}
+	return errors.New("timeout")
+}
+
+func (a *testAgents) connect(agent string, _ snmp.ClientConfig) (snmp.Connection, error) {
+	a.Lock()
+	defer a.Unlock()
+	a.connects[agent]++
+
+	host := strings.TrimSuffix(strings.TrimPrefix(agent, "udp://"), ":161")
+	return &testConnection{
+		host: host,
+		values: map[string]interface{}{
+			".1.3.6.1.2.1.1.5.0": "device-" + host,
+			".1.3.6.1.2.1.1.3.0": uint32(42),
+		},
+	}, nil
+}
+
+func newTestPlugin(networks []string, inventory string) *SnmpDiscovery {
+	return &SnmpDiscovery{
+		Networks:         networks,
+		Port:             161,
+		InventoryURL:     inventory,
+		InventoryTimeout: config.Duration(5 * time.Second),
+		RefreshInterval:  config.Duration(time.Hour),
+		ProbeTimeout:     config.Duration(time.Second),
+		MaxConcurrency:   4,
+		Credentials: []Credential{
+			{Name: "public", Community: "public"},
+			{Name: "private", Community: "private"},
+		},
+		ClientConfig: snmp.ClientConfig{
+			Version:    2,
+			Translator: "netsnmp",
+		},
+		Name: "snmp",
+		Fields: []snmp.Field{
+			{Name: "sysName", Oid: ".1.3.6.1.2.1.1.5.0", IsTag: true},
+			{Name: "sysUptime", Oid: ".1.3.6.1.2.1.1.3.0"},
+		},
+		Log: testutil.Logger{},
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*SnmpDiscovery)
+		expected string
+	}{
+		{
+			name: "missing targets",
+			modify: func(d *SnmpDiscovery) {
+				d.Networks = nil
+			},
+			expected: "either 'networks' or 'inventory_url' must be set",
+		},
+		{
+			name: "invalid network",
+			modify: func(d *SnmpDiscovery) {
+				d.Networks = []string{"10.0.0.1"}
+			},
+			expected: `invalid network "10.0.0.1"`,
+		},
+		{
+			name: "network too large",
+			modify: func(d *SnmpDiscovery) {
+				d.Networks = []string{"10.0.0.0/8"}
+			},
+			expected: `network "10.0.0.0/8" exceeds the maximum of 65536 hosts`,
+		},
+		{
+			name: "networks too large",
+			modify: func(d *SnmpDiscovery) {
+				d.Networks = []string{"10.0.0.0/16", "10.1.0.0/24"}
+			},
+			expected: "networks exceed the maximum of 65536 hosts",
+		},
+		{
+			name: "unnamed credentials",
+			modify: func(d *SnmpDiscovery) {
+				d.Credentials = append(d.Credentials, Credential{Community: "foo"})
+			},
+			expected: "credentials require a 'name'",
+		},
+		{
+			name: "duplicate credentials",
+			modify: func(d *SnmpDiscovery) {
+				d.Credentials = append(d.Credentials, Credential{Name: "public"})
+			},
+			expected: `duplicate credentials "public"`,
+		},
+		{
+			name: "invalid credentials",
+			modify: func(d *SnmpDiscovery) {
+				d.Credentials = append(d.Credentials, Credential{Name: "v5", Version: 5})
+			},
+			expected: `invalid credentials "v5": invalid version`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin([]string{"10.0.0.0/30"}, "")
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestHosts(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.0.0.254/23")
+	require.NoError(t, err)
+	addresses := hosts(network)
+	require.Len(t, addresses, 510)
+	require.Equal(t, "10.0.0.1", addresses[0].String())
+	require.Equal(t, "10.0.1.0", addresses[255].String())
+	require.Equal(t, "10.0.1.254", addresses[509].String())
+
+	_, network, err = net.ParseCIDR("10.0.0.5/32")
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.ParseIP("10.0.0.5").To4()}, hosts(network))
+
+	_, network, err = net.ParseCIDR("fd00::/127")
+	require.NoError(t, err)
+	addresses = hosts(network)
+	require.Len(t, addresses, 2)
+	require.Equal(t, "fd00::1", addresses[1].String())
+}
+
+func TestDiscoverAndGather(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, err := w.Write([]byte(`[
+			{"agent": "udp://10.1.0.1:161", "credentials": "private", "tags": {"site": "fra1"}},
+			{"agent": "udp://10.1.0.2:161", "tags": {"site": "ams1"}},
+			{"agent": "udp://10.1.0.3:161"},
+			{"agent": "udp://10.1.0.4:161", "credentials": "unknown"}
+		]`))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	agents := &testAgents{
+		credentials: map[string]string{
+			"udp://10.0.0.1:161": "private",
+			"udp://10.1.0.2:161": "public",
+		},
+		connects: make(map[string]int),
+	}
+
+	plugin := newTestPlugin([]string{"10.0.0.0/30"}, server.URL)
+	plugin.InventoryToken = config.NewSecret([]byte("mytoken"))
+	require.NoError(t, plugin.Init())
+	plugin.probe = agents.probe
+	plugin.connect = agents.connect
+
+	require.NoError(t, plugin.discover(context.Background()))
+	require.Equal(t, "Bearer mytoken", authorization)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"snmp",
+			map[string]string{"source": "10.0.0.1", "sysName": "device-10.0.0.1"},
+			map[string]interface{}{"sysUptime": uint32(42)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"snmp",
+			map[string]string{"source": "10.1.0.1", "sysName": "device-10.1.0.1", "site": "fra1"},
+			map[string]interface{}{"sysUptime": uint32(42)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"snmp",
+			map[string]string{"source": "10.1.0.2", "sysName": "device-10.1.0.2", "site": "ams1"},
+			map[string]interface{}{"sysUptime": uint32(42)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	plugin.mu.Lock()
+	require.Equal(t, "private", plugin.targets["udp://10.0.0.1:161"].credential)
+	require.Equal(t, "public", plugin.targets["udp://10.1.0.2:161"].credential)
+	plugin.mu.Unlock()
+}
+
+func TestRefresh(t *testing.T) {
+	agents := &testAgents{
+		credentials: map[string]string{
+			"udp://10.0.0.1:161": "public",
+			"udp://10.0.0.2:161": "public",
+		},
+		connects: make(map[string]int),
+	}
+
+	plugin := newTestPlugin([]string{"10.0.0.0/30"}, "")
+	require.NoError(t, plugin.Init())
+	plugin.probe = agents.probe
+	plugin.connect = agents.connect
+
+	require.NoError(t, plugin.discover(context.Background()))
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+
+	// Remove one agent and change the credentials of the other
+	agents.Lock()
+	delete(agents.credentials, "udp://10.0.0.1:161")
+	agents.credentials["udp://10.0.0.2:161"] = "private"
+	agents.Unlock()
+
+	require.NoError(t, plugin.discover(context.Background()))
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.True(t, acc.HasTag("snmp", "source"))
+	source, _ := acc.GetTelegrafMetrics()[0].GetTag("source")
+	require.Equal(t, "10.0.0.2", source)
+
+	// Changed credentials require a new connection, unchanged targets keep
+	// their connection
+	require.NoError(t, plugin.discover(context.Background()))
+	require.NoError(t, plugin.Gather(&acc))
+	agents.Lock()
+	require.Equal(t, map[string]int{"udp://10.0.0.1:161": 1, "udp://10.0.0.2:161": 2}, agents.connects)
+	agents.Unlock()
+}
+
+func TestInventoryFailureKeepsTargets(t *testing.T) {
+	var fail bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := w.Write([]byte(`[{"agent": "udp://10.1.0.1:161", "credentials": "public"}]`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	agents := &testAgents{connects: make(map[string]int)}
+	plugin := newTestPlugin(nil, server.URL)
+	require.NoError(t, plugin.Init())
+	plugin.probe = agents.probe
+	plugin.connect = agents.connect
+
+	require.NoError(t, plugin.discover(context.Background()))
+	fail = true
+	require.ErrorContains(t, plugin.discover(context.Background()), "503 Service Unavailable")
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -14,6 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/influxdata/tail"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -30,14 +32,21 @@ const (
 )
 
 type Suricata struct {
-	Source    string `toml:"source"`
-	Delimiter string `toml:"delimiter"`
-	Alerts    bool   `toml:"alerts"`
-	Version   string `toml:"version"`
+	Source          string `toml:"source"`
+	SourceType      string `toml:"source_type"`
+	FromBeginning   bool   `toml:"from_beginning"`
+	Delimiter       string `toml:"delimiter"`
+	Alerts          bool   `toml:"alerts"`
+	AggregateAlerts bool   `toml:"aggregate_alerts"`
+	Version         string `toml:"version"`
 
 	inputListener *net.UnixListener
+	tailer        *tail.Tail
 	cancel        context.CancelFunc
 
+	alertCounts map[alertGroup]uint64
+	alertsMu    sync.Mutex
+
 	Log telegraf.Logger `toml:"-"`
 
 	wg sync.WaitGroup
@@ -64,11 +73,26 @@ func (s *Suricata) Init() error {
 		return fmt.Errorf("invalid version %q, use either 1 or 2", s.Version)
 	}
 
+	switch s.SourceType {
+	case "":
+		s.SourceType = "socket"
+	case "socket", "file":
+	default:
+		return fmt.Errorf("invalid source type %q, use either 'socket' or 'file'", s.SourceType)
+	}
+
+	s.alertCounts = make(map[alertGroup]uint64)
+
 	return nil
 }
 
-// Start initiates background collection of JSON data from the socket provided to Suricata.
+// Start initiates background collection of JSON data from the socket provided to Suricata
+// or from the EVE log file.
 func (s *Suricata) Start(acc telegraf.Accumulator) error {
+	if s.SourceType == "file" {
+		return s.startTail(acc)
+	}
+
 	var err error
 	s.inputListener, err = net.ListenUnix("unix", &net.UnixAddr{
 		Name: s.Source,
@@ -88,15 +112,77 @@ func (s *Suricata) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
-// Gather measures and submits one full set of telemetry to Telegraf.
-// Not used here, submission is completely input-driven.
-func (*Suricata) Gather(telegraf.Accumulator) error {
+func (s *Suricata) startTail(acc telegraf.Accumulator) error {
+	seek := &tail.SeekInfo{Whence: io.SeekEnd}
+	if s.FromBeginning {
+		seek.Whence = io.SeekStart
+	}
+
+	var err error
+	s.tailer, err = tail.TailFile(s.Source, tail.Config{
+		ReOpen:    true,
+		Follow:    true,
+		Location:  seek,
+		MustExist: false,
+		Logger:    tail.DiscardingLogger,
+	})
+	if err != nil {
+		return fmt.Errorf("tailing %q failed: %w", s.Source, err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for line := range s.tailer.Lines {
+			if line.Err != nil {
+				acc.AddError(fmt.Errorf("reading %q failed: %w", s.Source, line.Err))
+				continue
+			}
+			text := strings.TrimSpace(line.Text)
+			if text == "" {
+				continue
+			}
+			if err := s.parse(acc, []byte(text)); err != nil {
+				acc.AddError(err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Gather submits the alert counts aggregated since the last call if alert
+// aggregation is enabled. All other submissions are completely input-driven.
+func (s *Suricata) Gather(acc telegraf.Accumulator) error {
+	if !s.AggregateAlerts {
+		return nil
+	}
+
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+	for group, count := range s.alertCounts {
+		tags := map[string]string{
+			"category": group.category,
+			"severity": group.severity,
+		}
+		acc.AddCounter("suricata_alert_summary", map[string]interface{}{"count": count}, tags)
+	}
+	s.alertCounts = make(map[alertGroup]uint64)
+
 	return nil
 }
 
 // Stop causes the plugin to cease collecting JSON data from the socket provided to Suricata.
 func (s *Suricata) Stop() {
-	s.inputListener.Close()
+	if s.inputListener != nil {
+		s.inputListener.Close()
+	}
+	if s.tailer != nil {
+		if err := s.tailer.Stop(); err != nil {
+			s.Log.Errorf("Stopping tail on %q failed: %v", s.Source, err)
+		}
+		s.tailer.Cleanup()
+	}
 	if s.cancel != nil {
 		s.cancel()
 	}
@@ -240,6 +326,10 @@ func (s *Suricata) parseStats(acc telegraf.Accumulator, result map[string]interf
 	}
 	fields["total"] = totalmap
 
+	for k := range fields {
+		s.addCaptureLoss(fields[k])
+	}
+
 	for k := range fields {
 		if k == "Global" {
 			acc.AddFields("suricata", fields[k], nil)
@@ -314,10 +404,59 @@ func (s *Suricata) parseGeneric(acc telegraf.Accumulator, result map[string]inte
 		}
 	}
 
+	if eventType == "stats" {
+		s.addCaptureLoss(fields)
+	}
+
 	acc.AddFields("suricata", fields, tags, timestamp)
 	return nil
 }
 
+// addCaptureLoss computes the percentage of packets dropped by the kernel
+// before reaching Suricata if the corresponding capture counters exist.
+func (s *Suricata) addCaptureLoss(fields map[string]interface{}) {
+	packets, ok := fields["capture"+s.Delimiter+"kernel_packets"].(float64)
+	if !ok {
+		return
+	}
+	drops, ok := fields["capture"+s.Delimiter+"kernel_drops"].(float64)
+	if !ok {
+		return
+	}
+
+	loss := 0.0
+	if packets > 0 {
+		loss = drops / packets * 100.0
+	}
+	fields["capture"+s.Delimiter+"loss_percent"] = loss
+}
+
+// countAlert adds the given alert to the aggregated counts grouped by
+// rule category and severity.
+func (s *Suricata) countAlert(result map[string]interface{}) {
+	alert, ok := result["alert"].(map[string]interface{})
+	if !ok {
+		s.Log.Debug("'alert' sub-object does not have required structure")
+		return
+	}
+
+	var group alertGroup
+	if v, err := internal.ToString(alert["category"]); err == nil && v != "" {
+		group.category = v
+	} else {
+		group.category = "unknown"
+	}
+	if v, err := internal.ToString(alert["severity"]); err == nil && v != "" {
+		group.severity = v
+	} else {
+		group.severity = "unknown"
+	}
+
+	s.alertsMu.Lock()
+	s.alertCounts[group]++
+	s.alertsMu.Unlock()
+}
+
 func (s *Suricata) parse(acc telegraf.Accumulator, sjson []byte) error {
 	// initial parsing
 	var result map[string]interface{}
@@ -327,6 +466,10 @@ func (s *Suricata) parse(acc telegraf.Accumulator, sjson []byte) error {
 	}
 
 	if s.Version == "2" {
+		if s.AggregateAlerts && result["event_type"] == "alert" {
+			s.countAlert(result)
+			return nil
+		}
 		return s.parseGeneric(acc, result)
 	}
 
@@ -334,7 +477,9 @@ func (s *Suricata) parse(acc telegraf.Accumulator, sjson []byte) error {
 	if _, ok := result["stats"]; ok {
 		s.parseStats(acc, result)
 	} else if _, ok := result["alert"]; ok {
-		if s.Alerts {
+		if s.AggregateAlerts {
+			s.countAlert(result)
+		} else if s.Alerts {
 			s.parseAlert(acc, result)
 		}
 	} else {
@@ -345,6 +490,11 @@ func (s *Suricata) parse(acc telegraf.Accumulator, sjson []byte) error {
 	return nil
 }
 
+type alertGroup struct {
+	category string
+	severity string
+}
+
 func init() {
 	inputs.Add("suricata", func() telegraf.Input {
 		return &Suricata{}
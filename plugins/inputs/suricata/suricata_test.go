@@ -149,6 +149,7 @@ func TestSuricata(t *testing.T) {
 				"capture.kernel_drops":         float64(78355440),
 				"capture.kernel_packets_delta": float64(2376742),
 				"capture.kernel_drops_delta":   float64(82049),
+				"capture.loss_percent":         float64(78355440) / float64(905344474) * 100,
 			},
 			time.Unix(0, 0),
 		),
@@ -197,6 +198,7 @@ func TestThreadStats(t *testing.T) {
 			map[string]interface{}{
 				"capture.kernel_packets": float64(905344474),
 				"capture.kernel_drops":   float64(78355440),
+				"capture.loss_percent":   float64(78355440) / float64(905344474) * 100,
 			},
 			time.Unix(0, 0),
 		),
@@ -534,6 +536,7 @@ func TestSuricataParseVersion2(t *testing.T) {
 						"captureerrors":          float64(0),
 						"capturekernel_drops":    float64(0),
 						"capturekernel_packets":  float64(522),
+						"captureloss_percent":    float64(0),
 						"flowemerg_mode_entered": float64(0),
 						"flowemerg_mode_over":    float64(0),
 						"flowmemcap":             float64(0),
@@ -568,3 +571,92 @@ func TestSuricataParseVersion2(t *testing.T) {
 		testutil.RequireMetricsEqual(t, tc.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 	}
 }
+
+func TestSuricataAggregateAlerts(t *testing.T) {
+	data, err := os.ReadFile("testdata/v2/alert.json")
+	require.NoError(t, err)
+
+	for _, version := range []string{"1", "2"} {
+		t.Run("version "+version, func(t *testing.T) {
+			s := Suricata{
+				Version:         version,
+				AggregateAlerts: true,
+				Log:             testutil.Logger{},
+			}
+			require.NoError(t, s.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, s.parse(&acc, data))
+			require.NoError(t, s.parse(&acc, data))
+			require.Empty(t, acc.GetTelegrafMetrics())
+
+			require.NoError(t, s.Gather(&acc))
+			expected := []telegraf.Metric{
+				testutil.MustMetric(
+					"suricata_alert_summary",
+					map[string]string{
+						"category": "Misc activity",
+						"severity": "3",
+					},
+					map[string]interface{}{
+						"count": uint64(2),
+					},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+			// Counts must be reset after gathering
+			acc.ClearMetrics()
+			require.NoError(t, s.Gather(&acc))
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestSuricataTailFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "eve.json")
+	require.NoError(t, os.WriteFile(fn, []byte(ex2+"\n"), 0600))
+
+	s := Suricata{
+		Source:        fn,
+		SourceType:    "file",
+		FromBeginning: true,
+		Delimiter:     ".",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	defer s.Stop()
+
+	acc.Wait(1)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"suricata",
+			map[string]string{
+				"thread": "total",
+			},
+			map[string]interface{}{
+				"capture.kernel_packets":       float64(905344474),
+				"capture.kernel_drops":         float64(78355440),
+				"capture.kernel_packets_delta": float64(2376742),
+				"capture.kernel_drops_delta":   float64(82049),
+				"capture.loss_percent":         float64(78355440) / float64(905344474) * 100,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSuricataInvalidSourceType(t *testing.T) {
+	s := Suricata{
+		SourceType: "pipe",
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, s.Init(), "invalid source type")
+}
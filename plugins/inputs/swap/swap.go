@@ -4,8 +4,11 @@ package swap
 import (
 	_ "embed"
 	"fmt"
+	"runtime"
+	"slices"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/psutil"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -14,13 +17,34 @@ import (
 var sampleConfig string
 
 type Swap struct {
-	ps psutil.PS
+	Collect []string        `toml:"collect"`
+	Log     telegraf.Logger `toml:"-"`
+
+	ps       psutil.PS
+	procPath string
+	sysPath  string
 }
 
 func (*Swap) SampleConfig() string {
 	return sampleConfig
 }
 
+func (ss *Swap) Init() error {
+	for _, c := range ss.Collect {
+		switch c {
+		case "zswap", "zram":
+		default:
+			return fmt.Errorf("invalid 'collect' option %q", c)
+		}
+	}
+	if len(ss.Collect) > 0 && runtime.GOOS != "linux" {
+		ss.Log.Warn("Additional 'collect' options are only supported on Linux, ignoring them")
+		ss.Collect = nil
+	}
+
+	return nil
+}
+
 func (ss *Swap) Gather(acc telegraf.Accumulator) error {
 	swap, err := ss.ps.SwapStat()
 	if err != nil {
@@ -40,12 +64,27 @@ func (ss *Swap) Gather(acc telegraf.Accumulator) error {
 	acc.AddGauge("swap", fieldsG, nil)
 	acc.AddCounter("swap", fieldsC, nil)
 
+	if slices.Contains(ss.Collect, "zswap") {
+		if err := ss.gatherZswap(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering zswap failed: %w", err))
+		}
+	}
+	if slices.Contains(ss.Collect, "zram") {
+		if err := ss.gatherZram(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering zram failed: %w", err))
+		}
+	}
+
 	return nil
 }
 
 func init() {
 	ps := psutil.NewSystemPS()
 	inputs.Add("swap", func() telegraf.Input {
-		return &Swap{ps: ps}
+		return &Swap{
+			ps:       ps,
+			procPath: internal.GetProcPath(),
+			sysPath:  internal.GetSysPath(),
+		}
 	})
 }
@@ -1,11 +1,15 @@
 package swap
 
 import (
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/common/psutil"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -27,7 +31,7 @@ func TestSwapStats(t *testing.T) {
 
 	mps.On("SwapStat").Return(sms, nil)
 
-	err = (&Swap{&mps}).Gather(&acc)
+	err = (&Swap{ps: &mps}).Gather(&acc)
 	require.NoError(t, err)
 
 	swapfields := map[string]interface{}{
@@ -38,3 +42,91 @@ func TestSwapStats(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "swap", swapfields, make(map[string]string))
 }
+
+func TestSwapStatsCollect(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Additional statistics are only supported on Linux")
+	}
+
+	var mps psutil.MockPS
+	defer mps.AssertExpectations(t)
+	mps.On("SwapStat").Return(&mem.SwapMemoryStat{Total: 100, Used: 20, Free: 80, UsedPercent: 20}, nil)
+
+	plugin := &Swap{
+		Collect:  []string{"zswap", "zram"},
+		Log:      testutil.Logger{},
+		ps:       &mps,
+		procPath: filepath.Join("testdata", "proc"),
+		sysPath:  filepath.Join("testdata", "sys"),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"swap_zswap",
+			map[string]string{},
+			map[string]interface{}{
+				"enabled":           true,
+				"pool_bytes":        uint64(10485760),
+				"stored_bytes":      uint64(41943040),
+				"compression_ratio": 4.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"swap_zswap",
+			map[string]string{},
+			map[string]interface{}{
+				"written_back_pages":   uint64(12),
+				"pool_limit_hit":       uint64(0),
+				"reject_compress_poor": uint64(3),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"swap_zram",
+			map[string]string{"device": "zram0"},
+			map[string]interface{}{
+				"disk_size":         uint64(4294967296),
+				"orig_data_size":    uint64(1073741824),
+				"compr_data_size":   uint64(268435456),
+				"mem_used_total":    uint64(285212672),
+				"mem_limit":         uint64(0),
+				"mem_used_max":      uint64(301989888),
+				"same_pages":        uint64(1024),
+				"huge_pages":        uint64(8),
+				"compression_ratio": 4.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"swap_zram",
+			map[string]string{"device": "zram0"},
+			map[string]interface{}{
+				"pages_compacted": uint64(64),
+				"failed_reads":    uint64(0),
+				"failed_writes":   uint64(0),
+				"invalid_io":      uint64(0),
+				"notify_free":     uint64(5120),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+
+	// Only check the additional metrics, the swap statistics are covered above
+	actual := acc.GetTelegrafMetrics()[2:]
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &Swap{Collect: []string{"hugepages"}, Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' option "hugepages"`)
+}
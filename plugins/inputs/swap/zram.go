@@ -0,0 +1,105 @@
+package swap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Columns of the mm_stat and io_stat files of zram devices, see
+// https://docs.kernel.org/admin-guide/blockdev/zram.html
+var (
+	zramMemoryStats = []string{
+		"orig_data_size",
+		"compr_data_size",
+		"mem_used_total",
+		"mem_limit",
+		"mem_used_max",
+		"same_pages",
+		"pages_compacted",
+		"huge_pages",
+	}
+	zramIOStats = []string{
+		"failed_reads",
+		"failed_writes",
+		"invalid_io",
+		"notify_free",
+	}
+)
+
+// gatherZram collects the statistics of all compressed RAM block devices
+func (ss *Swap) gatherZram(acc telegraf.Accumulator) error {
+	devices, err := filepath.Glob(filepath.Join(ss.sysPath, "block", "zram*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range devices {
+		device := filepath.Base(dir)
+
+		size, err := readUint(filepath.Join(dir, "disksize"))
+		if err != nil {
+			return err
+		}
+		memStats, err := readColumns(filepath.Join(dir, "mm_stat"), zramMemoryStats)
+		if err != nil {
+			return err
+		}
+		ioStats, err := readColumns(filepath.Join(dir, "io_stat"), zramIOStats)
+		if err != nil {
+			return err
+		}
+
+		fieldsG := map[string]interface{}{
+			"disk_size": size,
+		}
+		fieldsC := make(map[string]interface{}, len(ioStats)+1)
+		for k, v := range memStats {
+			if k == "pages_compacted" {
+				fieldsC[k] = v
+				continue
+			}
+			fieldsG[k] = v
+		}
+		for k, v := range ioStats {
+			fieldsC[k] = v
+		}
+		if compressed := memStats["compr_data_size"]; compressed > 0 {
+			fieldsG["compression_ratio"] = float64(memStats["orig_data_size"]) / float64(compressed)
+		}
+
+		tags := map[string]string{"device": device}
+		acc.AddGauge("swap_zram", fieldsG, tags)
+		acc.AddCounter("swap_zram", fieldsC, tags)
+	}
+
+	return nil
+}
+
+// readColumns reads the whitespace separated values of the given file and
+// assigns them to the given names. Additional columns of newer kernels are
+// ignored and columns missing in older kernels are skipped.
+func readColumns(path string, names []string) (map[string]uint64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := strings.Fields(string(buf))
+	values := make(map[string]uint64, len(names))
+	for i, name := range names {
+		if i >= len(columns) {
+			break
+		}
+		v, err := strconv.ParseUint(columns[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q of %q failed: %w", name, path, err)
+		}
+		values[name] = v
+	}
+	return values, nil
+}
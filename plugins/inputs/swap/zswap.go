@@ -0,0 +1,121 @@
+package swap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Counters of the zswap debugfs interface, only readable by root
+var zswapCounters = []string{
+	"written_back_pages",
+	"pool_limit_hit",
+	"reject_reclaim_fail",
+	"reject_alloc_fail",
+	"reject_kmemcache_fail",
+	"reject_compress_fail",
+	"reject_compress_poor",
+}
+
+// gatherZswap collects the state of the compressed swap cache. The pool size
+// is taken from /proc/meminfo on kernels providing it or from debugfs.
+func (ss *Swap) gatherZswap(acc telegraf.Accumulator) error {
+	buf, err := os.ReadFile(filepath.Join(ss.sysPath, "module", "zswap", "parameters", "enabled"))
+	if err != nil {
+		return err
+	}
+	fieldsG := map[string]interface{}{
+		"enabled": strings.TrimSpace(string(buf)) == "Y",
+	}
+
+	meminfo, err := readMeminfo(filepath.Join(ss.procPath, "meminfo"))
+	if err != nil {
+		return err
+	}
+	pool, foundPool := meminfo["Zswap"]
+	stored, foundStored := meminfo["Zswapped"]
+
+	debugDir := filepath.Join(ss.sysPath, "kernel", "debug", "zswap")
+	if !foundPool {
+		if v, err := readUint(filepath.Join(debugDir, "pool_total_size")); err == nil {
+			pool, foundPool = v, true
+		}
+	}
+	if !foundStored {
+		if v, err := readUint(filepath.Join(debugDir, "stored_pages")); err == nil {
+			stored, foundStored = v*uint64(os.Getpagesize()), true
+		}
+	}
+	if foundPool {
+		fieldsG["pool_bytes"] = pool
+	}
+	if foundStored {
+		fieldsG["stored_bytes"] = stored
+	}
+	if foundPool && foundStored && pool > 0 {
+		fieldsG["compression_ratio"] = float64(stored) / float64(pool)
+	}
+
+	fieldsC := make(map[string]interface{}, len(zswapCounters))
+	for _, name := range zswapCounters {
+		if v, err := readUint(filepath.Join(debugDir, name)); err == nil {
+			fieldsC[name] = v
+		}
+	}
+
+	acc.AddGauge("swap_zswap", fieldsG, nil)
+	if len(fieldsC) > 0 {
+		acc.AddCounter("swap_zswap", fieldsC, nil)
+	}
+
+	return nil
+}
+
+// readMeminfo returns the values of /proc/meminfo in bytes
+func readMeminfo(path string) (map[string]uint64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		// Format: Zswap:             1024 kB
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		parts := strings.Fields(value)
+		if len(parts) == 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing value of %q failed: %w", key, err)
+		}
+		if len(parts) > 1 && parts[1] == "kB" {
+			v *= 1024
+		}
+		values[key] = v
+	}
+	return values, scanner.Err()
+}
+
+func readUint(path string) (uint64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(string(bytes.TrimSpace(buf)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %q failed: %w", path, err)
+	}
+	return v, nil
+}
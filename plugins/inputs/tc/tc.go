@@ -0,0 +1,13 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tc
+
+import (
+	_ "embed"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+func (*TC) SampleConfig() string {
+	return sampleConfig
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+package tc
+
+import (
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+type TC struct {
+	InterfaceInclude []string        `toml:"interface_include"`
+	InterfaceExclude []string        `toml:"interface_exclude"`
+	Collect          []string        `toml:"collect"`
+	Log              telegraf.Logger `toml:"-"`
+
+	interfaceFilter filter.Filter
+	qdiscs          bool
+	classes         bool
+
+	// client queries the kernel for the traffic control objects
+	client client
+}
+
+type client interface {
+	links() ([]netlink.Link, error)
+	qdiscs(link netlink.Link) ([]netlink.Qdisc, error)
+	classes(link netlink.Link) ([]netlink.Class, error)
+}
+
+type netlinkClient struct{}
+
+func (*netlinkClient) links() ([]netlink.Link, error) {
+	return netlink.LinkList()
+}
+
+func (*netlinkClient) qdiscs(link netlink.Link) ([]netlink.Qdisc, error) {
+	return netlink.QdiscList(link)
+}
+
+func (*netlinkClient) classes(link netlink.Link) ([]netlink.Class, error) {
+	return netlink.ClassList(link, netlink.HANDLE_NONE)
+}
+
+func (t *TC) Init() error {
+	var err error
+	t.interfaceFilter, err = filter.NewIncludeExcludeFilter(t.InterfaceInclude, t.InterfaceExclude)
+	if err != nil {
+		return fmt.Errorf("creating interface filter failed: %w", err)
+	}
+
+	if len(t.Collect) == 0 {
+		t.Collect = []string{"qdisc", "class"}
+	}
+	if err := choice.CheckSlice(t.Collect, []string{"qdisc", "class"}); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+	t.qdiscs = choice.Contains("qdisc", t.Collect)
+	t.classes = choice.Contains("class", t.Collect)
+
+	if t.client == nil {
+		t.client = &netlinkClient{}
+	}
+
+	return nil
+}
+
+func (t *TC) Gather(acc telegraf.Accumulator) error {
+	links, err := t.client.links()
+	if err != nil {
+		return fmt.Errorf("listing interfaces failed: %w", err)
+	}
+
+	for _, link := range links {
+		name := link.Attrs().Name
+		if !t.interfaceFilter.Match(name) {
+			continue
+		}
+
+		if t.qdiscs {
+			qdiscs, err := t.client.qdiscs(link)
+			if err != nil {
+				acc.AddError(fmt.Errorf("listing qdiscs of %q failed: %w", name, err))
+			}
+			for _, qdisc := range qdiscs {
+				attrs := qdisc.Attrs()
+				if attrs.Statistics == nil {
+					continue
+				}
+				tags := map[string]string{
+					"interface": name,
+					"kind":      qdisc.Type(),
+					"handle":    netlink.HandleStr(attrs.Handle),
+					"parent":    netlink.HandleStr(attrs.Parent),
+				}
+				acc.AddFields("tc_qdisc", fields((*netlink.ClassStatistics)(attrs.Statistics)), tags)
+			}
+		}
+
+		if t.classes {
+			classes, err := t.client.classes(link)
+			if err != nil {
+				acc.AddError(fmt.Errorf("listing classes of %q failed: %w", name, err))
+			}
+			for _, class := range classes {
+				attrs := class.Attrs()
+				if attrs.Statistics == nil {
+					continue
+				}
+				tags := map[string]string{
+					"interface": name,
+					"kind":      class.Type(),
+					"class":     netlink.HandleStr(attrs.Handle),
+					"parent":    netlink.HandleStr(attrs.Parent),
+				}
+				acc.AddFields("tc_class", fields(attrs.Statistics), tags)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fields converts the generic networking statistics of a qdisc or class
+func fields(stats *netlink.ClassStatistics) map[string]interface{} {
+	f := make(map[string]interface{}, 9)
+	if stats.Basic != nil {
+		f["bytes"] = stats.Basic.Bytes
+		f["packets"] = uint64(stats.Basic.Packets)
+	}
+	if stats.Queue != nil {
+		f["qlen"] = uint64(stats.Queue.Qlen)
+		f["backlog"] = uint64(stats.Queue.Backlog)
+		f["drops"] = uint64(stats.Queue.Drops)
+		f["requeues"] = uint64(stats.Queue.Requeues)
+		f["overlimits"] = uint64(stats.Queue.Overlimits)
+	}
+	if stats.RateEst != nil {
+		f["rate_bps"] = uint64(stats.RateEst.Bps)
+		f["rate_pps"] = uint64(stats.RateEst.Pps)
+	}
+	return f
+}
+
+func init() {
+	inputs.Add("tc", func() telegraf.Input {
+		return &TC{}
+	})
+}
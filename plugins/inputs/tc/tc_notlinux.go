@@ -0,0 +1,27 @@
+//go:build !linux
+
+package tc
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+type TC struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (t *TC) Init() error {
+	t.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*TC) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func init() {
+	inputs.Add("tc", func() telegraf.Input {
+		return &TC{}
+	})
+}
@@ -0,0 +1,193 @@
+//go:build linux
+
+package tc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockClient struct {
+	qdiscList map[string][]netlink.Qdisc
+	classList map[string][]netlink.Class
+}
+
+func (*mockClient) links() ([]netlink.Link, error) {
+	return []netlink.Link{
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}},
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "lo"}},
+	}, nil
+}
+
+func (c *mockClient) qdiscs(link netlink.Link) ([]netlink.Qdisc, error) {
+	if link.Attrs().Name == "lo" {
+		return nil, errors.New("permission denied")
+	}
+	return c.qdiscList[link.Attrs().Name], nil
+}
+
+func (c *mockClient) classes(link netlink.Link) ([]netlink.Class, error) {
+	return c.classList[link.Attrs().Name], nil
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{
+		qdiscList: map[string][]netlink.Qdisc{
+			"eth0": {
+				&netlink.Htb{
+					QdiscAttrs: netlink.QdiscAttrs{
+						Handle: netlink.MakeHandle(1, 0),
+						Parent: netlink.HANDLE_ROOT,
+						Statistics: &netlink.QdiscStatistics{
+							Basic:   &netlink.GnetStatsBasic{Bytes: 123456, Packets: 1000},
+							Queue:   &netlink.GnetStatsQueue{Qlen: 2, Backlog: 3028, Drops: 5, Overlimits: 17},
+							RateEst: &netlink.GnetStatsRateEst{Bps: 1200, Pps: 10},
+						},
+					},
+				},
+				&netlink.FqCodel{
+					QdiscAttrs: netlink.QdiscAttrs{
+						Handle: netlink.MakeHandle(0x10, 0),
+						Parent: netlink.MakeHandle(1, 0x10),
+						Statistics: &netlink.QdiscStatistics{
+							Basic: &netlink.GnetStatsBasic{Bytes: 4096, Packets: 8},
+							Queue: &netlink.GnetStatsQueue{Drops: 1, Requeues: 2},
+						},
+					},
+				},
+				// qdisc without statistics
+				&netlink.Ingress{
+					QdiscAttrs: netlink.QdiscAttrs{
+						Handle: netlink.MakeHandle(0xffff, 0),
+						Parent: netlink.HANDLE_INGRESS,
+					},
+				},
+			},
+		},
+		classList: map[string][]netlink.Class{
+			"eth0": {
+				&netlink.HtbClass{
+					ClassAttrs: netlink.ClassAttrs{
+						Handle: netlink.MakeHandle(1, 0x10),
+						Parent: netlink.MakeHandle(1, 0),
+						Leaf:   netlink.MakeHandle(0x10, 0),
+						Statistics: &netlink.ClassStatistics{
+							Basic:   &netlink.GnetStatsBasic{Bytes: 4096, Packets: 8},
+							Queue:   &netlink.GnetStatsQueue{Overlimits: 3},
+							RateEst: &netlink.GnetStatsRateEst{Bps: 100, Pps: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &TC{Collect: []string{"filter"}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'collect' setting")
+}
+
+func TestGather(t *testing.T) {
+	plugin := &TC{
+		InterfaceExclude: []string{"lo"},
+		Log:              testutil.Logger{},
+		client:           newMockClient(),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"tc_qdisc",
+			map[string]string{"interface": "eth0", "kind": "htb", "handle": "1:0", "parent": "root"},
+			map[string]interface{}{
+				"bytes":      uint64(123456),
+				"packets":    uint64(1000),
+				"qlen":       uint64(2),
+				"backlog":    uint64(3028),
+				"drops":      uint64(5),
+				"requeues":   uint64(0),
+				"overlimits": uint64(17),
+				"rate_bps":   uint64(1200),
+				"rate_pps":   uint64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tc_qdisc",
+			map[string]string{"interface": "eth0", "kind": "fq_codel", "handle": "10:0", "parent": "1:10"},
+			map[string]interface{}{
+				"bytes":      uint64(4096),
+				"packets":    uint64(8),
+				"qlen":       uint64(0),
+				"backlog":    uint64(0),
+				"drops":      uint64(1),
+				"requeues":   uint64(2),
+				"overlimits": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tc_class",
+			map[string]string{"interface": "eth0", "kind": "htb", "class": "1:10", "parent": "1:0"},
+			map[string]interface{}{
+				"bytes":      uint64(4096),
+				"packets":    uint64(8),
+				"qlen":       uint64(0),
+				"backlog":    uint64(0),
+				"drops":      uint64(0),
+				"requeues":   uint64(0),
+				"overlimits": uint64(3),
+				"rate_bps":   uint64(100),
+				"rate_pps":   uint64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherCollectQdiscOnly(t *testing.T) {
+	plugin := &TC{
+		InterfaceInclude: []string{"eth*"},
+		Collect:          []string{"qdisc"},
+		Log:              testutil.Logger{},
+		client:           newMockClient(),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "tc_qdisc", m.Name())
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	plugin := &TC{
+		Log:    testutil.Logger{},
+		client: newMockClient(),
+	}
+	require.NoError(t, plugin.Init())
+
+	// Errors of an interface must not prevent gathering the others
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `listing qdiscs of "lo" failed`)
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+}
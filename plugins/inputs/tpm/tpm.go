@@ -0,0 +1,271 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package tpm
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// GUID of the EFI global variables such as "SecureBoot" and "SetupMode"
+const efiGlobalVariableGUID = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+type TPM struct {
+	HostSys      string            `toml:"host_sys"`
+	Collect      []string          `toml:"collect"`
+	PCRBanks     []string          `toml:"pcr_banks"`
+	PCRs         []int             `toml:"pcrs"`
+	ExpectedPCRs map[string]string `toml:"expected_pcrs"`
+	Log          telegraf.Logger   `toml:"-"`
+
+	expected map[string]string
+}
+
+func (*TPM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TPM) Init() error {
+	if t.HostSys == "" {
+		t.HostSys = internal.GetSysPath()
+	}
+
+	if len(t.Collect) == 0 {
+		t.Collect = []string{"pcrs", "secure_boot", "ima"}
+	}
+	if err := choice.CheckSlice(t.Collect, []string{"pcrs", "secure_boot", "ima"}); err != nil {
+		return fmt.Errorf("config option 'collect': %w", err)
+	}
+
+	if len(t.PCRBanks) == 0 {
+		t.PCRBanks = []string{"sha256"}
+	}
+	for _, idx := range t.PCRs {
+		if idx < 0 || idx > 23 {
+			return fmt.Errorf("invalid PCR index %d", idx)
+		}
+	}
+
+	t.expected = make(map[string]string, len(t.ExpectedPCRs))
+	for key, value := range t.ExpectedPCRs {
+		bank, index, found := strings.Cut(key, ":")
+		if !found || bank == "" {
+			return fmt.Errorf("invalid expected PCR %q, use '<bank>:<index>'", key)
+		}
+		if _, err := strconv.ParseUint(index, 10, 8); err != nil {
+			return fmt.Errorf("invalid index of expected PCR %q: %w", key, err)
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+		if _, err := hex.DecodeString(value); err != nil {
+			return fmt.Errorf("invalid value of expected PCR %q: %w", key, err)
+		}
+		t.expected[key] = value
+	}
+
+	return nil
+}
+
+func (t *TPM) Gather(acc telegraf.Accumulator) error {
+	for _, what := range t.Collect {
+		var err error
+		switch what {
+		case "pcrs":
+			err = t.gatherPCRs(acc)
+		case "secure_boot":
+			err = t.gatherSecureBoot(acc)
+		case "ima":
+			err = t.gatherIMA(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s failed: %w", what, err))
+		}
+	}
+	return nil
+}
+
+// gatherPCRs reads the PCR values exposed by the kernel for TPM 2.0 devices
+// in "/sys/class/tpm/<device>/pcr-<bank>/<index>"
+func (t *TPM) gatherPCRs(acc telegraf.Accumulator) error {
+	devices, err := filepath.Glob(filepath.Join(t.HostSys, "class", "tpm", "tpm*"))
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		t.Log.Debug("No TPM devices found")
+		return nil
+	}
+
+	for _, dir := range devices {
+		device := filepath.Base(dir)
+		fields := make(map[string]interface{}, 2)
+		if version, err := readInt(filepath.Join(dir, "tpm_version_major")); err == nil {
+			fields["version"] = version
+		}
+
+		var mismatches int64
+		for _, bank := range t.PCRBanks {
+			bankDir := filepath.Join(dir, "pcr-"+bank)
+			entries, err := os.ReadDir(bankDir)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					t.Log.Debugf("PCR bank %q not available for %q", bank, device)
+					continue
+				}
+				return err
+			}
+
+			for _, entry := range entries {
+				index, err := strconv.Atoi(entry.Name())
+				if err != nil || len(t.PCRs) > 0 && !slices.Contains(t.PCRs, index) {
+					continue
+				}
+				buf, err := os.ReadFile(filepath.Join(bankDir, entry.Name()))
+				if err != nil {
+					return err
+				}
+				value := strings.ToLower(strings.TrimSpace(string(buf)))
+
+				pcrFields := map[string]interface{}{"value": value}
+				if expected, found := t.expected[bank+":"+entry.Name()]; found {
+					pcrFields["matches_expected"] = value == expected
+					if value != expected {
+						mismatches++
+					}
+				}
+				tags := map[string]string{
+					"device": device,
+					"bank":   bank,
+					"pcr":    entry.Name(),
+				}
+				acc.AddFields("tpm_pcr", pcrFields, tags)
+			}
+		}
+
+		if len(t.expected) > 0 {
+			fields["pcr_mismatches"] = mismatches
+		}
+		if len(fields) > 0 {
+			acc.AddFields("tpm", fields, map[string]string{"device": device})
+		}
+	}
+
+	return nil
+}
+
+// gatherSecureBoot determines the secure boot state from the EFI variables
+func (t *TPM) gatherSecureBoot(acc telegraf.Accumulator) error {
+	if _, err := os.Stat(filepath.Join(t.HostSys, "firmware", "efi")); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// Legacy BIOS boot without EFI support
+		acc.AddFields("tpm_secure_boot", map[string]interface{}{
+			"state":      "unsupported",
+			"enabled":    false,
+			"setup_mode": false,
+		}, nil)
+		return nil
+	}
+
+	enabled, err := t.readEFIBool("SecureBoot")
+	if err != nil {
+		return err
+	}
+	setupMode, err := t.readEFIBool("SetupMode")
+	if err != nil {
+		return err
+	}
+
+	var state string
+	switch {
+	case setupMode:
+		state = "setup_mode"
+	case enabled:
+		state = "enabled"
+	default:
+		state = "disabled"
+	}
+	acc.AddFields("tpm_secure_boot", map[string]interface{}{
+		"state":      state,
+		"enabled":    enabled,
+		"setup_mode": setupMode,
+	}, nil)
+
+	return nil
+}
+
+// readEFIBool reads a boolean EFI global variable. The content of the
+// variable file consists of four bytes of attributes followed by the value.
+// Missing variables are treated as false.
+func (t *TPM) readEFIBool(name string) (bool, error) {
+	fn := filepath.Join(t.HostSys, "firmware", "efi", "efivars", name+"-"+efiGlobalVariableGUID)
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(buf) < 5 {
+		return false, fmt.Errorf("invalid content of EFI variable %q", name)
+	}
+	return buf[4] == 1, nil
+}
+
+// gatherIMA reads the counters of the Integrity Measurement Architecture
+// exposed via securityfs
+func (t *TPM) gatherIMA(acc telegraf.Accumulator) error {
+	dir := filepath.Join(t.HostSys, "kernel", "security", "ima")
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			t.Log.Debug("IMA not enabled or securityfs not mounted")
+			return nil
+		}
+		return err
+	}
+
+	measurements, err := readInt(filepath.Join(dir, "runtime_measurements_count"))
+	if err != nil {
+		return err
+	}
+	violations, err := readInt(filepath.Join(dir, "violations"))
+	if err != nil {
+		return err
+	}
+	acc.AddCounter("tpm_ima", map[string]interface{}{
+		"measurements": measurements,
+		"violations":   violations,
+	}, nil)
+
+	return nil
+}
+
+func readInt(fn string) (int64, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+}
+
+func init() {
+	inputs.Add("tpm", func() telegraf.Input {
+		return &TPM{}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package tpm
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TPM struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*TPM) SampleConfig() string { return sampleConfig }
+
+func (t *TPM) Init() error {
+	t.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*TPM) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("tpm", func() telegraf.Input {
+		return &TPM{}
+	})
+}
@@ -0,0 +1,195 @@
+//go:build linux
+
+package tpm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const (
+	pcr0 = "3DCAD7A1D2F0D8EB4A2F4A5DD4E2EEDE9D4F1F8C4CA3B3C3D3E3F30313233343"
+	pcr7 = "65CAF8DD1E0EA7A6347B635D2B379C93B9A1351EDC2AFC3ECDA700E534EB3068"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TPM
+		expected string
+	}{
+		{
+			name:     "invalid collect",
+			plugin:   &TPM{Collect: []string{"pcrs", "bios"}},
+			expected: "config option 'collect'",
+		},
+		{
+			name:     "invalid index",
+			plugin:   &TPM{PCRs: []int{0, 24}},
+			expected: "invalid PCR index 24",
+		},
+		{
+			name:     "invalid expected key",
+			plugin:   &TPM{ExpectedPCRs: map[string]string{"7": pcr7}},
+			expected: `invalid expected PCR "7"`,
+		},
+		{
+			name:     "invalid expected value",
+			plugin:   &TPM{ExpectedPCRs: map[string]string{"sha256:7": "xyz"}},
+			expected: `invalid value of expected PCR "sha256:7"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	sys := t.TempDir()
+	writeFile(t, sys, "class/tpm/tpm0/tpm_version_major", "2\n")
+	writeFile(t, sys, "class/tpm/tpm0/pcr-sha256/0", pcr0+"\n")
+	writeFile(t, sys, "class/tpm/tpm0/pcr-sha256/7", pcr7+"\n")
+	writeFile(t, sys, "class/tpm/tpm0/pcr-sha256/10", strings.Repeat("0", 64)+"\n")
+	writeFile(t, sys, "class/tpm/tpm0/pcr-sha1/0", strings.Repeat("0", 40)+"\n")
+	writeFile(t, sys, "firmware/efi/efivars/SecureBoot-"+efiGlobalVariableGUID, "\x06\x00\x00\x00\x01")
+	writeFile(t, sys, "firmware/efi/efivars/SetupMode-"+efiGlobalVariableGUID, "\x06\x00\x00\x00\x00")
+	writeFile(t, sys, "kernel/security/ima/runtime_measurements_count", "1482\n")
+	writeFile(t, sys, "kernel/security/ima/violations", "3\n")
+
+	plugin := &TPM{
+		HostSys: sys,
+		PCRs:    []int{0, 7},
+		ExpectedPCRs: map[string]string{
+			"sha256:0": strings.Repeat("0", 64),
+			"sha256:7": pcr7,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"tpm_pcr",
+			map[string]string{"device": "tpm0", "bank": "sha256", "pcr": "0"},
+			map[string]interface{}{"value": strings.ToLower(pcr0), "matches_expected": false},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tpm_pcr",
+			map[string]string{"device": "tpm0", "bank": "sha256", "pcr": "7"},
+			map[string]interface{}{"value": strings.ToLower(pcr7), "matches_expected": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tpm",
+			map[string]string{"device": "tpm0"},
+			map[string]interface{}{"version": int64(2), "pcr_mismatches": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tpm_secure_boot",
+			map[string]string{},
+			map[string]interface{}{"state": "enabled", "enabled": true, "setup_mode": false},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"tpm_ima",
+			map[string]string{},
+			map[string]interface{}{"measurements": int64(1482), "violations": int64(3)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherSecureBoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "legacy bios",
+			expected: map[string]interface{}{"state": "unsupported", "enabled": false, "setup_mode": false},
+		},
+		{
+			name: "disabled",
+			files: map[string]string{
+				"firmware/efi/efivars/SecureBoot-" + efiGlobalVariableGUID: "\x06\x00\x00\x00\x00",
+			},
+			expected: map[string]interface{}{"state": "disabled", "enabled": false, "setup_mode": false},
+		},
+		{
+			name: "setup mode",
+			files: map[string]string{
+				"firmware/efi/efivars/SecureBoot-" + efiGlobalVariableGUID: "\x06\x00\x00\x00\x00",
+				"firmware/efi/efivars/SetupMode-" + efiGlobalVariableGUID:  "\x06\x00\x00\x00\x01",
+			},
+			expected: map[string]interface{}{"state": "setup_mode", "enabled": false, "setup_mode": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sys := t.TempDir()
+			for fn, content := range tt.files {
+				writeFile(t, sys, fn, content)
+			}
+
+			plugin := &TPM{
+				HostSys: sys,
+				Collect: []string{"secure_boot"},
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			expected := []telegraf.Metric{
+				metric.New("tpm_secure_boot", map[string]string{}, tt.expected, time.Unix(0, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestGatherNoDevices(t *testing.T) {
+	plugin := &TPM{
+		HostSys: t.TempDir(),
+		Collect: []string{"pcrs", "ima"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func writeFile(t *testing.T, root, fn, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, fn)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
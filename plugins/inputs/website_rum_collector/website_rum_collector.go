@@ -0,0 +1,437 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package website_rum_collector
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum number of samples kept per timing and page in each interval,
+// further samples are reservoir sampled
+const maxSamples = 10000
+
+type WebsiteRUMCollector struct {
+	ServiceAddress string          `toml:"service_address"`
+	Path           string          `toml:"path"`
+	AllowedOrigins []string        `toml:"allowed_origins"`
+	SampleRate     float64         `toml:"sample_rate"`
+	PathDepth      int             `toml:"path_depth"`
+	MaxPaths       int             `toml:"max_paths"`
+	Percentiles    []float64       `toml:"percentiles"`
+	MaxBodySize    config.Size     `toml:"max_body_size"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	WriteTimeout   config.Duration `toml:"write_timeout"`
+	Log            telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	tlsConf  *tls.Config
+	server   *http.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	// Function deciding whether to sample a beacon, replaceable for testing
+	sample func() bool
+
+	sync.Mutex
+	pages map[pageKey]*pageStats
+}
+
+type pageKey struct {
+	origin string
+	path   string
+}
+
+type pageStats struct {
+	beacons int64
+	timings map[string]*samples
+}
+
+type samples struct {
+	count  int64
+	sum    float64
+	values []float64
+}
+
+// beacon is the payload posted by the browser
+type beacon struct {
+	URL        string            `json:"url"`
+	Navigation *navigationTiming `json:"navigation"`
+	Resources  []resourceTiming  `json:"resources"`
+	Vitals     []webVital        `json:"vitals"`
+}
+
+// navigationTiming holds the relevant attributes of the W3C
+// PerformanceNavigationTiming entry in milliseconds relative to the start of
+// the navigation
+type navigationTiming struct {
+	DomainLookupStart        float64 `json:"domainLookupStart"`
+	DomainLookupEnd          float64 `json:"domainLookupEnd"`
+	ConnectStart             float64 `json:"connectStart"`
+	ConnectEnd               float64 `json:"connectEnd"`
+	SecureConnectionStart    float64 `json:"secureConnectionStart"`
+	RequestStart             float64 `json:"requestStart"`
+	ResponseStart            float64 `json:"responseStart"`
+	ResponseEnd              float64 `json:"responseEnd"`
+	DomInteractive           float64 `json:"domInteractive"`
+	DomContentLoadedEventEnd float64 `json:"domContentLoadedEventEnd"`
+	LoadEventEnd             float64 `json:"loadEventEnd"`
+}
+
+// resourceTiming holds the relevant attributes of a W3C
+// PerformanceResourceTiming entry
+type resourceTiming struct {
+	Duration float64 `json:"duration"`
+}
+
+// webVital is a Core Web Vitals measurement as reported by the web-vitals
+// library
+type webVital struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func (*WebsiteRUMCollector) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WebsiteRUMCollector) Init() error {
+	if w.ServiceAddress == "" {
+		w.ServiceAddress = ":8087"
+	}
+	if w.Path == "" {
+		w.Path = "/rum"
+	}
+	if w.SampleRate == 0 {
+		w.SampleRate = 1
+	}
+	if w.SampleRate < 0 || w.SampleRate > 1 {
+		return fmt.Errorf("invalid sample rate %v, must be in range (0, 1]", w.SampleRate)
+	}
+	if w.PathDepth < 0 {
+		return fmt.Errorf("invalid path depth %d", w.PathDepth)
+	}
+	if w.MaxPaths == 0 {
+		w.MaxPaths = 1000
+	}
+	if len(w.Percentiles) == 0 {
+		w.Percentiles = []float64{50, 75, 95}
+	}
+	for _, p := range w.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile %v, must be in range (0, 100]", p)
+		}
+	}
+	if w.MaxBodySize == 0 {
+		w.MaxBodySize = config.Size(64 * 1024)
+	}
+	if w.ReadTimeout == 0 {
+		w.ReadTimeout = config.Duration(10 * time.Second)
+	}
+	if w.WriteTimeout == 0 {
+		w.WriteTimeout = config.Duration(10 * time.Second)
+	}
+	for i, origin := range w.AllowedOrigins {
+		w.AllowedOrigins[i] = strings.TrimSuffix(origin, "/")
+	}
+
+	tlsConf, err := w.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	w.tlsConf = tlsConf
+
+	if w.sample == nil {
+		rate := w.SampleRate
+		w.sample = func() bool { return rate >= 1 || rand.Float64() < rate }
+	}
+	w.pages = make(map[pageKey]*pageStats)
+
+	return nil
+}
+
+func (w *WebsiteRUMCollector) Start(telegraf.Accumulator) error {
+	var err error
+	if w.tlsConf != nil {
+		w.listener, err = tls.Listen("tcp", w.ServiceAddress, w.tlsConf)
+	} else {
+		w.listener, err = net.Listen("tcp", w.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(w.Path, w.serveBeacon)
+	w.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  time.Duration(w.ReadTimeout),
+		WriteTimeout: time.Duration(w.WriteTimeout),
+		TLSConfig:    w.tlsConf,
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.server.Serve(w.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			w.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+	w.Log.Infof("Listening on %s", w.listener.Addr())
+
+	return nil
+}
+
+func (w *WebsiteRUMCollector) Gather(acc telegraf.Accumulator) error {
+	w.Lock()
+	pages := w.pages
+	w.pages = make(map[pageKey]*pageStats, len(pages))
+	w.Unlock()
+
+	now := time.Now()
+	for key, stats := range pages {
+		fields := map[string]interface{}{
+			"beacons": stats.beacons,
+		}
+		for name, s := range stats.timings {
+			fields[name+"_count"] = s.count
+			fields[name+"_mean"] = s.sum / float64(s.count)
+			slices.Sort(s.values)
+			for _, p := range w.Percentiles {
+				fields[name+"_p"+strconv.FormatFloat(p, 'f', -1, 64)] = percentile(s.values, p)
+			}
+		}
+		tags := map[string]string{
+			"origin": key.origin,
+			"path":   key.path,
+		}
+		acc.AddFields("website_rum", fields, tags, now)
+	}
+
+	return nil
+}
+
+func (w *WebsiteRUMCollector) Stop() {
+	if w.server != nil {
+		if err := w.server.Close(); err != nil {
+			w.Log.Errorf("Closing server failed: %v", err)
+		}
+	}
+	w.wg.Wait()
+}
+
+func (w *WebsiteRUMCollector) serveBeacon(res http.ResponseWriter, req *http.Request) {
+	origin := strings.TrimSuffix(req.Header.Get("Origin"), "/")
+	if origin != "" && !w.originAllowed(origin) {
+		w.Log.Debugf("Rejecting beacon from origin %q", origin)
+		http.Error(res, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if origin != "" {
+		res.Header().Set("Access-Control-Allow-Origin", origin)
+		res.Header().Set("Vary", "Origin")
+	}
+
+	switch req.Method {
+	case http.MethodOptions:
+		// Answer CORS preflight requests of beacons sent via fetch
+		res.Header().Set("Access-Control-Allow-Methods", "POST")
+		res.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		res.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+	default:
+		res.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(res, req.Body, int64(w.MaxBodySize)))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(res, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(res, "reading request body failed", http.StatusBadRequest)
+		return
+	}
+
+	beacons, err := parseBeacons(body)
+	if err != nil {
+		w.Log.Debugf("Parsing beacon failed: %v", err)
+		http.Error(res, "invalid beacon", http.StatusBadRequest)
+		return
+	}
+
+	for _, b := range beacons {
+		if !w.sample() {
+			continue
+		}
+		if err := w.add(origin, b); err != nil {
+			w.Log.Debugf("Adding beacon failed: %v", err)
+			http.Error(res, "invalid beacon", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (w *WebsiteRUMCollector) originAllowed(origin string) bool {
+	return len(w.AllowedOrigins) == 0 || slices.Contains(w.AllowedOrigins, origin)
+}
+
+// parseBeacons parses a single beacon or an array of beacons
+func parseBeacons(body []byte) ([]beacon, error) {
+	var beacons []beacon
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(body, &beacons); err != nil {
+			return nil, err
+		}
+		return beacons, nil
+	}
+
+	var b beacon
+	if err := json.Unmarshal(body, &b); err != nil {
+		return nil, err
+	}
+	return []beacon{b}, nil
+}
+
+func (w *WebsiteRUMCollector) add(origin string, b beacon) error {
+	u, err := url.Parse(b.URL)
+	if err != nil || b.URL == "" {
+		return fmt.Errorf("invalid page url %q", b.URL)
+	}
+	if origin == "" && u.Host != "" {
+		origin = u.Scheme + "://" + u.Host
+		if !w.originAllowed(origin) {
+			return fmt.Errorf("origin %q not allowed", origin)
+		}
+	}
+
+	timings := make(map[string][]float64)
+	if nav := b.Navigation; nav != nil {
+		timings["dns"] = []float64{nav.DomainLookupEnd - nav.DomainLookupStart}
+		timings["connect"] = []float64{nav.ConnectEnd - nav.ConnectStart}
+		if nav.SecureConnectionStart > 0 {
+			timings["tls"] = []float64{nav.ConnectEnd - nav.SecureConnectionStart}
+		}
+		timings["request"] = []float64{nav.ResponseStart - nav.RequestStart}
+		timings["response"] = []float64{nav.ResponseEnd - nav.ResponseStart}
+		timings["dom_interactive"] = []float64{nav.DomInteractive}
+		timings["dom_content_loaded"] = []float64{nav.DomContentLoadedEventEnd}
+		timings["load"] = []float64{nav.LoadEventEnd}
+	}
+	for _, r := range b.Resources {
+		timings["resource"] = append(timings["resource"], r.Duration)
+	}
+	for _, v := range b.Vitals {
+		switch name := strings.ToLower(v.Name); name {
+		case "cls", "fcp", "fid", "inp", "lcp", "ttfb":
+			timings[name] = append(timings[name], v.Value)
+		default:
+			w.Log.Debugf("Ignoring unknown web vital %q", v.Name)
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	key := pageKey{origin: origin, path: w.normalizePath(u.Path)}
+	stats, found := w.pages[key]
+	if !found {
+		if len(w.pages) >= w.MaxPaths {
+			key.path = "other"
+			stats, found = w.pages[key]
+		}
+		if !found {
+			stats = &pageStats{timings: make(map[string]*samples)}
+			w.pages[key] = stats
+		}
+	}
+
+	stats.beacons++
+	for name, values := range timings {
+		s, found := stats.timings[name]
+		if !found {
+			s = &samples{}
+			stats.timings[name] = s
+		}
+		for _, v := range values {
+			// Skip timings of incomplete page loads or bogus values
+			if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			s.add(v)
+		}
+		if s.count == 0 {
+			delete(stats.timings, name)
+		}
+	}
+
+	return nil
+}
+
+// normalizePath truncates the path to the configured depth
+func (w *WebsiteRUMCollector) normalizePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	if w.PathDepth == 0 {
+		return path
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) <= w.PathDepth {
+		return path
+	}
+	return "/" + strings.Join(parts[:w.PathDepth], "/")
+}
+
+func (s *samples) add(v float64) {
+	s.count++
+	s.sum += v
+	if len(s.values) < maxSamples {
+		s.values = append(s.values, v)
+		return
+	}
+	if idx := rand.Int64N(s.count); idx < maxSamples {
+		s.values[idx] = v
+	}
+}
+
+// percentile returns the given percentile of the sorted values using the
+// nearest-rank method
+func percentile(values []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	return values[max(rank-1, 0)]
+}
+
+func init() {
+	inputs.Add("website_rum_collector", func() telegraf.Input {
+		return &WebsiteRUMCollector{}
+	})
+}
@@ -0,0 +1,249 @@
+package website_rum_collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &WebsiteRUMCollector{SampleRate: 1.5}
+	require.ErrorContains(t, plugin.Init(), "invalid sample rate")
+
+	plugin = &WebsiteRUMCollector{Percentiles: []float64{0}}
+	require.ErrorContains(t, plugin.Init(), "invalid percentile")
+}
+
+func TestBeacons(t *testing.T) {
+	plugin := &WebsiteRUMCollector{
+		AllowedOrigins: []string{"https://www.example.com/"},
+		PathDepth:      1,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	beacons := []string{
+		`{
+			"url": "https://www.example.com/products/1234?ref=home",
+			"navigation": {
+				"domainLookupStart": 2,
+				"domainLookupEnd": 12,
+				"connectStart": 12,
+				"connectEnd": 42,
+				"requestStart": 45,
+				"responseStart": 120,
+				"responseEnd": 130,
+				"domInteractive": 350,
+				"domContentLoadedEventEnd": 410,
+				"loadEventEnd": 800
+			},
+			"resources": [{"duration": 80}, {"duration": 20}],
+			"vitals": [{"name": "LCP", "value": 1200}, {"name": "CLS", "value": 0.1}]
+		}`,
+		`[
+			{"url": "https://www.example.com/products/5678", "vitals": [{"name": "LCP", "value": 1600}]},
+			{"url": "https://www.example.com/", "vitals": [{"name": "INP", "value": 40}]}
+		]`,
+	}
+	for _, b := range beacons {
+		code := postBeacon(plugin, "https://www.example.com", b)
+		require.Equal(t, http.StatusNoContent, code)
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"website_rum",
+			map[string]string{
+				"origin": "https://www.example.com",
+				"path":   "/",
+			},
+			map[string]interface{}{
+				"beacons":   int64(1),
+				"inp_count": int64(1),
+				"inp_mean":  40.0,
+				"inp_p50":   40.0,
+				"inp_p75":   40.0,
+				"inp_p95":   40.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"website_rum",
+			map[string]string{
+				"origin": "https://www.example.com",
+				"path":   "/products",
+			},
+			map[string]interface{}{
+				"beacons":                  int64(2),
+				"dns_count":                int64(1),
+				"dns_mean":                 10.0,
+				"dns_p50":                  10.0,
+				"dns_p75":                  10.0,
+				"dns_p95":                  10.0,
+				"connect_count":            int64(1),
+				"connect_mean":             30.0,
+				"connect_p50":              30.0,
+				"connect_p75":              30.0,
+				"connect_p95":              30.0,
+				"request_count":            int64(1),
+				"request_mean":             75.0,
+				"request_p50":              75.0,
+				"request_p75":              75.0,
+				"request_p95":              75.0,
+				"response_count":           int64(1),
+				"response_mean":            10.0,
+				"response_p50":             10.0,
+				"response_p75":             10.0,
+				"response_p95":             10.0,
+				"dom_interactive_count":    int64(1),
+				"dom_interactive_mean":     350.0,
+				"dom_interactive_p50":      350.0,
+				"dom_interactive_p75":      350.0,
+				"dom_interactive_p95":      350.0,
+				"dom_content_loaded_count": int64(1),
+				"dom_content_loaded_mean":  410.0,
+				"dom_content_loaded_p50":   410.0,
+				"dom_content_loaded_p75":   410.0,
+				"dom_content_loaded_p95":   410.0,
+				"load_count":               int64(1),
+				"load_mean":                800.0,
+				"load_p50":                 800.0,
+				"load_p75":                 800.0,
+				"load_p95":                 800.0,
+				"resource_count":           int64(2),
+				"resource_mean":            50.0,
+				"resource_p50":             20.0,
+				"resource_p75":             80.0,
+				"resource_p95":             80.0,
+				"cls_count":                int64(1),
+				"cls_mean":                 0.1,
+				"cls_p50":                  0.1,
+				"cls_p75":                  0.1,
+				"cls_p95":                  0.1,
+				"lcp_count":                int64(2),
+				"lcp_mean":                 1400.0,
+				"lcp_p50":                  1200.0,
+				"lcp_p75":                  1600.0,
+				"lcp_p95":                  1600.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The statistics are reset after gathering
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestOrigins(t *testing.T) {
+	plugin := &WebsiteRUMCollector{
+		AllowedOrigins: []string{"https://www.example.com"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	beacon := `{"url": "https://www.example.org/", "vitals": [{"name": "LCP", "value": 1000}]}`
+	require.Equal(t, http.StatusForbidden, postBeacon(plugin, "https://www.example.org", beacon))
+
+	// Without origin header the origin is determined from the page url
+	require.Equal(t, http.StatusBadRequest, postBeacon(plugin, "", beacon))
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Preflight requests of allowed origins are answered
+	req := httptest.NewRequest(http.MethodOptions, "/rum", nil)
+	req.Header.Set("Origin", "https://www.example.com")
+	rec := httptest.NewRecorder()
+	plugin.serveBeacon(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "https://www.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSamplingAndLimits(t *testing.T) {
+	var sampled int
+	plugin := &WebsiteRUMCollector{
+		MaxPaths: 1,
+		Log:      testutil.Logger{},
+		sample: func() bool {
+			sampled++
+			return sampled%2 == 1
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		beacon := `{"url": "https://www.example.com` + path + `", "vitals": [{"name": "FCP", "value": 500}]}`
+		require.Equal(t, http.StatusNoContent, postBeacon(plugin, "", beacon))
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Only every other beacon is sampled and pages exceeding the limit are
+	// reported as "other"
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 2)
+	paths := make(map[string]interface{})
+	for _, m := range actual {
+		path, _ := m.GetTag("path")
+		paths[path], _ = m.GetField("beacons")
+	}
+	require.Equal(t, map[string]interface{}{"/a": int64(1), "other": int64(1)}, paths)
+}
+
+func TestInvalidBeacon(t *testing.T) {
+	plugin := &WebsiteRUMCollector{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	require.Equal(t, http.StatusBadRequest, postBeacon(plugin, "", `{"url": `))
+	require.Equal(t, http.StatusBadRequest, postBeacon(plugin, "", `{"vitals": []}`))
+
+	plugin.MaxBodySize = 10
+	require.Equal(t, http.StatusRequestEntityTooLarge, postBeacon(plugin, "", `{"url": "https://www.example.com/"}`))
+}
+
+func TestListener(t *testing.T) {
+	plugin := &WebsiteRUMCollector{
+		ServiceAddress: "127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	beacon := `{"url": "https://www.example.com/", "vitals": [{"name": "TTFB", "value": 100}]}`
+	resp, err := http.Post("http://"+plugin.listener.Addr().String()+"/rum", "text/plain", strings.NewReader(beacon))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func postBeacon(plugin *WebsiteRUMCollector, origin, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/rum", strings.NewReader(body))
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	plugin.serveBeacon(rec, req)
+	return rec.Code
+}
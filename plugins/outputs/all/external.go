@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.external
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/external" // register plugin
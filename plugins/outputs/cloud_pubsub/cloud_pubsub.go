@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,10 +38,18 @@ type PubSub struct {
 	Base64Data            bool            `toml:"base64_data"`
 	ContentEncoding       string          `toml:"content_encoding"`
 
+	OrderingKeyTags   []string          `toml:"ordering_key_tags"`
+	MeasurementTopics map[string]string `toml:"measurement_topics"`
+
+	FlowControlMaxMessages   int         `toml:"flow_control_max_messages"`
+	FlowControlMaxBytes      config.Size `toml:"flow_control_max_bytes"`
+	FlowControlLimitExceeded string      `toml:"flow_control_limit_exceeded"`
+
 	Log telegraf.Logger `toml:"-"`
 
-	t topic
-	c *pubsub.Client
+	topics        map[string]topic
+	c             *pubsub.Client
+	limitExceeded pubsub.LimitExceededBehavior
 
 	stubTopic func(id string) topic
 
@@ -66,32 +75,48 @@ func (ps *PubSub) Connect() error {
 }
 
 func (ps *PubSub) Close() error {
-	if ps.t != nil {
-		ps.t.Stop()
+	for _, t := range ps.topics {
+		t.Stop()
 	}
 	return nil
 }
 
 func (ps *PubSub) Write(metrics []telegraf.Metric) error {
-	ps.refreshTopic()
-
-	// Serialize metrics and package into appropriate PubSub messages
-	msgs, err := ps.toMessages(metrics)
-	if err != nil {
-		return err
+	// Route the metrics to their topics keeping the order of the metrics
+	var names []string
+	routed := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		name := ps.topicName(m)
+		if _, found := routed[name]; !found {
+			names = append(names, name)
+		}
+		routed[name] = append(routed[name], m)
 	}
+	ps.refreshTopics(names)
 
 	cctx, cancel := context.WithCancel(context.Background())
 
 	// Publish all messages - each call to Publish returns a future.
-	ps.publishResults = make([]publishResult, 0, len(msgs))
-	for _, m := range msgs {
-		ps.publishResults = append(ps.publishResults, ps.t.Publish(cctx, m))
+	ps.publishResults = make([]publishResult, 0, len(metrics))
+	for _, name := range names {
+		// Serialize metrics and package into appropriate PubSub messages
+		msgs, err := ps.toMessages(routed[name])
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		t := ps.topics[name]
+		for _, m := range msgs {
+			ps.publishResults = append(ps.publishResults, t.Publish(cctx, m))
+		}
 	}
 
 	// topic.Stop() forces all published messages to be sent, even
 	// if PubSub batch limits have not been reached.
-	go ps.t.Stop()
+	for _, t := range ps.topics {
+		go t.Stop()
+	}
 
 	return ps.waitForResults(cctx, cancel)
 }
@@ -123,14 +148,50 @@ func (ps *PubSub) initPubSubClient() error {
 	return nil
 }
 
-func (ps *PubSub) refreshTopic() {
-	if ps.stubTopic != nil {
-		ps.t = ps.stubTopic(ps.Topic)
-	} else {
-		t := ps.c.Topic(ps.Topic)
-		ps.t = &topicWrapper{t}
+// refreshTopics creates the topics with the given names for publishing. As
+// the topics are recreated for each write, publishing for an ordering key
+// paused due to an error is resumed with the next write.
+func (ps *PubSub) refreshTopics(names []string) {
+	ps.topics = make(map[string]topic, len(names))
+	for _, name := range names {
+		var t topic
+		if ps.stubTopic != nil {
+			t = ps.stubTopic(name)
+		} else {
+			t = &topicWrapper{ps.c.Topic(name)}
+		}
+		t.SetPublishSettings(ps.publishSettings())
+		t.SetMessageOrdering(len(ps.OrderingKeyTags) > 0)
+		ps.topics[name] = t
+	}
+}
+
+// topicName returns the topic to publish the given metric to
+func (ps *PubSub) topicName(m telegraf.Metric) string {
+	if name, found := ps.MeasurementTopics[m.Name()]; found {
+		return name
+	}
+	return ps.Topic
+}
+
+// orderingKey returns the values of the configured ordering key tags joined
+// by a slash or an empty string if none of the tags exist
+func (ps *PubSub) orderingKey(m telegraf.Metric) string {
+	if len(ps.OrderingKeyTags) == 0 {
+		return ""
+	}
+
+	values := make([]string, 0, len(ps.OrderingKeyTags))
+	var found bool
+	for _, key := range ps.OrderingKeyTags {
+		v, ok := m.GetTag(key)
+		found = found || ok
+		values = append(values, v)
+	}
+	if !found {
+		return ""
 	}
-	ps.t.SetPublishSettings(ps.publishSettings())
+	return strings.Join(values, "/")
 }
 
 func (ps *PubSub) publishSettings() pubsub.PublishSettings {
@@ -153,28 +214,50 @@ func (ps *PubSub) publishSettings() pubsub.PublishSettings {
 		settings.ByteThreshold = ps.PublishByteThreshold
 	}
 
+	settings.FlowControlSettings = pubsub.FlowControlSettings{
+		MaxOutstandingMessages: ps.FlowControlMaxMessages,
+		MaxOutstandingBytes:    int(ps.FlowControlMaxBytes),
+		LimitExceededBehavior:  ps.limitExceeded,
+	}
+
 	return settings
 }
 
 func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, error) {
 	if ps.SendBatched {
-		b, err := ps.serializer.SerializeBatch(metrics)
-		if err != nil {
-			return nil, err
+		// Send one message per ordering key as messages with different keys
+		// might be delivered in any order
+		var keys []string
+		batches := make(map[string][]telegraf.Metric)
+		for _, m := range metrics {
+			key := ps.orderingKey(m)
+			if _, found := batches[key]; !found {
+				keys = append(keys, key)
+			}
+			batches[key] = append(batches[key], m)
 		}
 
-		b = ps.encodeB64Data(b)
+		msgs := make([]*pubsub.Message, 0, len(keys))
+		for _, key := range keys {
+			b, err := ps.serializer.SerializeBatch(batches[key])
+			if err != nil {
+				return nil, err
+			}
 
-		b, err = ps.compressData(b)
-		if err != nil {
-			return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
-		}
+			b = ps.encodeB64Data(b)
 
-		msg := &pubsub.Message{Data: b}
-		if ps.Attributes != nil {
-			msg.Attributes = ps.Attributes
+			b, err = ps.compressData(b)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
+			}
+
+			msg := &pubsub.Message{Data: b, OrderingKey: key}
+			if ps.Attributes != nil {
+				msg.Attributes = ps.Attributes
+			}
+			msgs = append(msgs, msg)
 		}
-		return []*pubsub.Message{msg}, nil
+		return msgs, nil
 	}
 
 	msgs := make([]*pubsub.Message, 0, len(metrics))
@@ -194,7 +277,8 @@ func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, erro
 		}
 
 		msg := &pubsub.Message{
-			Data: b,
+			Data:        b,
+			OrderingKey: ps.orderingKey(m),
 		}
 		if ps.Attributes != nil {
 			msg.Attributes = ps.Attributes
@@ -278,6 +362,23 @@ func (ps *PubSub) Init() error {
 		return fmt.Errorf("invalid value %q for content_encoding", ps.ContentEncoding)
 	}
 
+	switch ps.FlowControlLimitExceeded {
+	case "", "ignore":
+		ps.limitExceeded = pubsub.FlowControlIgnore
+	case "block":
+		ps.limitExceeded = pubsub.FlowControlBlock
+	case "error":
+		ps.limitExceeded = pubsub.FlowControlSignalError
+	default:
+		return fmt.Errorf("invalid value %q for flow_control_limit_exceeded", ps.FlowControlLimitExceeded)
+	}
+
+	for measurement, name := range ps.MeasurementTopics {
+		if name == "" {
+			return fmt.Errorf("empty topic for measurement %q", measurement)
+		}
+	}
+
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -143,6 +144,110 @@ func TestPubSub_Error(t *testing.T) {
 	require.ErrorContains(t, err, errMockFail)
 }
 
+func TestPubSub_WriteOrderingKey(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.TestMetric("value_1", "test"), false},
+		{testutil.TestMetric("value_2", "test"), false},
+		{testutil.TestMetric("value_3", "test"), false},
+	}
+	testMetrics[0].m.AddTag("host", "server01")
+	testMetrics[1].m.AddTag("host", "server02")
+	testMetrics[2].m.RemoveTag("tag1")
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.OrderingKeyTags = []string{"tag1", "host"}
+
+	require.NoError(t, ps.Write(metrics))
+	require.True(t, topic.Ordering)
+
+	expected := []string{"value1/server01", "value1/server02", ""}
+	for i, testM := range testMetrics {
+		msg := verifyRawMetricPublished(t, testM.m, topic.published)
+		require.Equal(t, expected[i], msg.OrderingKey)
+	}
+}
+
+func TestPubSub_WriteBatchedOrderingKey(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.TestMetric("value_1", "test"), false},
+		{testutil.TestMetric("value_2", "test"), false},
+		{testutil.TestMetric("value_3", "test"), false},
+	}
+	testMetrics[0].m.AddTag("host", "server01")
+	testMetrics[1].m.AddTag("host", "server02")
+	testMetrics[2].m.AddTag("host", "server01")
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.SendBatched = true
+	ps.OrderingKeyTags = []string{"host"}
+
+	require.NoError(t, ps.Write(metrics))
+
+	// Metrics with the same key are sent in the same message
+	require.Same(t, topic.published["value_1"], topic.published["value_3"])
+	require.NotSame(t, topic.published["value_1"], topic.published["value_2"])
+	require.Equal(t, "server01", topic.published["value_1"].OrderingKey)
+	require.Equal(t, "server02", topic.published["value_2"].OrderingKey)
+}
+
+func TestPubSub_WriteMeasurementTopics(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.TestMetric("value_1", "cpu"), false},
+		{testutil.TestMetric("value_2", "mem"), false},
+		{testutil.TestMetric("value_3", "disk"), false},
+	}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, defaultTopic, metrics := getTestResources(t, settings, testMetrics)
+	ps.MeasurementTopics = map[string]string{
+		"cpu":  "cpu-topic",
+		"disk": "cpu-topic",
+	}
+
+	routed := &stubTopic{
+		T:               t,
+		ReturnErr:       make(map[string]bool),
+		published:       make(map[string]*pubsub.Message),
+		ContentEncoding: "identity",
+	}
+	ps.stubTopic = func(id string) topic {
+		if id == "cpu-topic" {
+			return routed
+		}
+		return defaultTopic
+	}
+
+	require.NoError(t, ps.Write(metrics))
+
+	require.Len(t, routed.published, 2)
+	verifyRawMetricPublished(t, testMetrics[0].m, routed.published)
+	verifyRawMetricPublished(t, testMetrics[2].m, routed.published)
+	require.Len(t, defaultTopic.published, 1)
+	verifyRawMetricPublished(t, testMetrics[1].m, defaultTopic.published)
+}
+
+func TestPubSub_FlowControl(t *testing.T) {
+	ps := &PubSub{
+		Project:                  "test-project",
+		Topic:                    "test-topic",
+		FlowControlMaxMessages:   100,
+		FlowControlMaxBytes:      config.Size(1024),
+		FlowControlLimitExceeded: "block",
+	}
+	require.NoError(t, ps.Init())
+
+	require.Equal(t, pubsub.FlowControlSettings{
+		MaxOutstandingMessages: 100,
+		MaxOutstandingBytes:    1024,
+		LimitExceededBehavior:  pubsub.FlowControlBlock,
+	}, ps.publishSettings().FlowControlSettings)
+
+	ps.FlowControlLimitExceeded = "foo"
+	require.ErrorContains(t, ps.Init(), `invalid value "foo" for flow_control_limit_exceeded`)
+}
+
 func TestPubSub_WriteGzipSingle(t *testing.T) {
 	testMetrics := []testMetric{
 		{testutil.TestMetric("value_1", "test"), false},
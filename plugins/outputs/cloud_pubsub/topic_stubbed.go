@@ -50,6 +50,7 @@ type (
 		*testing.T
 		Base64Data      bool
 		ContentEncoding string
+		Ordering        bool
 
 		stopped bool
 		pLock   sync.Mutex
@@ -151,6 +152,10 @@ func (t *stubTopic) SetPublishSettings(settings pubsub.PublishSettings) {
 	t.initBundler()
 }
 
+func (t *stubTopic) SetMessageOrdering(enabled bool) {
+	t.Ordering = enabled
+}
+
 func (t *stubTopic) initBundler() *stubTopic {
 	t.bundler = bundler.NewBundler(&bundledMsg{}, t.sendBundle())
 	t.bundler.DelayThreshold = 10 * time.Second
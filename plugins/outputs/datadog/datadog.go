@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/delta"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -25,14 +26,16 @@ import (
 var sampleConfig string
 
 type Datadog struct {
-	Apikey       string          `toml:"apikey"`
-	Timeout      config.Duration `toml:"timeout"`
-	URL          string          `toml:"url"`
-	Compression  string          `toml:"compression"`
-	RateInterval config.Duration `toml:"rate_interval"`
-	Log          telegraf.Logger `toml:"-"`
+	Apikey        string          `toml:"apikey"`
+	Timeout       config.Duration `toml:"timeout"`
+	URL           string          `toml:"url"`
+	Compression   string          `toml:"compression"`
+	RateInterval  config.Duration `toml:"rate_interval"`
+	DeltaCounters bool            `toml:"delta_counters"`
+	Log           telegraf.Logger `toml:"-"`
 
 	client *http.Client
+	deltas *delta.Tracker
 	proxy.HTTPProxy
 }
 
@@ -53,6 +56,9 @@ type Point [2]float64
 
 const datadogAPI = "https://app.datadoghq.com/api/v1/series"
 
+// Time after which the last value of a counter series is forgotten
+const deltaExpiry = time.Hour
+
 func (*Datadog) SampleConfig() string {
 	return sampleConfig
 }
@@ -77,6 +83,10 @@ func (d *Datadog) Connect() error {
 }
 
 func (d *Datadog) convertToDatadogMetric(metrics []telegraf.Metric) []*Metric {
+	if d.DeltaCounters && d.deltas == nil {
+		d.deltas = delta.NewTracker(deltaExpiry)
+	}
+
 	tempSeries := make([]*Metric, 0, len(metrics))
 	for _, m := range metrics {
 		if dogMs, err := buildMetrics(m); err == nil {
@@ -101,8 +111,17 @@ func (d *Datadog) convertToDatadogMetric(metrics []telegraf.Metric) []*Metric {
 				var tname string
 				var interval int64
 				interval = 1
-				switch m.Type() {
-				case telegraf.Counter, telegraf.Untyped:
+				switch {
+				case d.DeltaCounters && delta.IsCumulative(m, fieldName):
+					// Send the increase since the last write as count
+					diff, elapsed, ok := d.deltas.Delta(m, fieldName, dogM[1])
+					if !ok {
+						continue
+					}
+					dogM[1] = diff
+					interval = max(int64(elapsed.Seconds()), 1)
+					tname = "count"
+				case m.Type() == telegraf.Counter || m.Type() == telegraf.Untyped:
 					if d.RateInterval > 0 && isRateable(statsDMetricType, fieldName) {
 						// interval is expected to be in seconds
 						rateIntervalSeconds := time.Duration(d.RateInterval).Seconds()
@@ -114,7 +133,7 @@ func (d *Datadog) convertToDatadogMetric(metrics []telegraf.Metric) []*Metric {
 					} else {
 						tname = ""
 					}
-				case telegraf.Gauge:
+				case m.Type() == telegraf.Gauge:
 					tname = "gauge"
 				default:
 					tname = ""
@@ -133,6 +152,9 @@ func (d *Datadog) convertToDatadogMetric(metrics []telegraf.Metric) []*Metric {
 			d.Log.Infof("Unable to build Metric for %s due to error '%v', skipping", m.Name(), err)
 		}
 	}
+	if d.deltas != nil {
+		d.deltas.Expire()
+	}
 	return tempSeries
 }
 
@@ -899,3 +899,97 @@ func TestZeroRateIntervalConvertsRatesToCount(t *testing.T) {
 		})
 	}
 }
+
+func TestDeltaCounters(t *testing.T) {
+	d := &Datadog{
+		Apikey:        "123456",
+		RateInterval:  config.Duration(10 * time.Second),
+		DeltaCounters: true,
+	}
+
+	ts := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	input := func(offset time.Duration, requests, quantile, count int) []telegraf.Metric {
+		return []telegraf.Metric{
+			testutil.MustMetric(
+				"http",
+				map[string]string{"metric_type": "counter"},
+				map[string]interface{}{"requests": requests},
+				ts.Add(offset),
+				telegraf.Counter,
+			),
+			testutil.MustMetric(
+				"latency",
+				map[string]string{},
+				map[string]interface{}{"0.99": quantile, "count": count},
+				ts.Add(offset),
+				telegraf.Summary,
+			),
+			testutil.MustMetric(
+				"temperature",
+				map[string]string{},
+				map[string]interface{}{"value": 21.5},
+				ts.Add(offset),
+				telegraf.Gauge,
+			),
+		}
+	}
+
+	// The first write only records the counters
+	expected := []*Metric{
+		{
+			Metric:   "latency.0.99",
+			Points:   [1]Point{{float64(ts.Unix()), 12}},
+			Tags:     []string{},
+			Interval: 1,
+		},
+		{
+			Metric:   "temperature",
+			Points:   [1]Point{{float64(ts.Unix()), 21.5}},
+			Type:     "gauge",
+			Tags:     []string{},
+			Interval: 1,
+		},
+	}
+	require.ElementsMatch(t, expected, d.convertToDatadogMetric(input(0, 100, 12, 50)))
+
+	expected = []*Metric{
+		{
+			Metric:   "http.requests",
+			Points:   [1]Point{{float64(ts.Add(30 * time.Second).Unix()), 20}},
+			Type:     "count",
+			Tags:     []string{"metric_type:counter"},
+			Interval: 30,
+		},
+		{
+			Metric:   "latency.0.99",
+			Points:   [1]Point{{float64(ts.Add(30 * time.Second).Unix()), 15}},
+			Tags:     []string{},
+			Interval: 1,
+		},
+		{
+			Metric:   "latency.count",
+			Points:   [1]Point{{float64(ts.Add(30 * time.Second).Unix()), 10}},
+			Type:     "count",
+			Tags:     []string{},
+			Interval: 30,
+		},
+		{
+			Metric:   "temperature",
+			Points:   [1]Point{{float64(ts.Add(30 * time.Second).Unix()), 21.5}},
+			Type:     "gauge",
+			Tags:     []string{},
+			Interval: 1,
+		},
+	}
+	require.ElementsMatch(t, expected, d.convertToDatadogMetric(input(30*time.Second, 120, 15, 60)))
+
+	// A counter reset should send the new value
+	actual := d.convertToDatadogMetric(input(60*time.Second, 5, 15, 70))
+	require.Contains(t, actual, &Metric{
+		Metric:   "http.requests",
+		Points:   [1]Point{{float64(ts.Add(60 * time.Second).Unix()), 5}},
+		Type:     "count",
+		Tags:     []string{"metric_type:counter"},
+		Interval: 30,
+	})
+}
@@ -6,8 +6,11 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs"
 
 	"github.com/influxdata/telegraf"
@@ -20,15 +23,19 @@ import (
 var sampleConfig string
 
 type EventHubs struct {
-	ConnectionString string          `toml:"connection_string"`
-	PartitionKey     string          `toml:"partition_key"`
-	MaxMessageSize   config.Size     `toml:"max_message_size"`
-	Timeout          config.Duration `toml:"timeout"`
-	Log              telegraf.Logger `toml:"-"`
-
-	client     *azeventhubs.ProducerClient
-	options    azeventhubs.EventDataBatchOptions
-	serializer telegraf.Serializer
+	ConnectionString     string          `toml:"connection_string"`
+	Namespace            string          `toml:"namespace"`
+	EventHub             string          `toml:"event_hub"`
+	PartitionKey         string          `toml:"partition_key"`
+	PartitionKeyTemplate string          `toml:"partition_key_template"`
+	MaxMessageSize       config.Size     `toml:"max_message_size"`
+	Timeout              config.Duration `toml:"timeout"`
+	Log                  telegraf.Logger `toml:"-"`
+
+	client      *azeventhubs.ProducerClient
+	options     azeventhubs.EventDataBatchOptions
+	partitionTp *template.Template
+	serializer  telegraf.Serializer
 }
 
 func (*EventHubs) SampleConfig() string {
@@ -36,6 +43,24 @@ func (*EventHubs) SampleConfig() string {
 }
 
 func (e *EventHubs) Init() error {
+	switch {
+	case e.ConnectionString != "" && (e.Namespace != "" || e.EventHub != ""):
+		return errors.New("'connection_string' cannot be used together with 'namespace' and 'event_hub'")
+	case e.ConnectionString == "" && (e.Namespace == "" || e.EventHub == ""):
+		return errors.New("either 'connection_string' or 'namespace' and 'event_hub' must be set")
+	}
+
+	if e.PartitionKey != "" && e.PartitionKeyTemplate != "" {
+		return errors.New("'partition_key' and 'partition_key_template' are mutually exclusive")
+	}
+	if e.PartitionKeyTemplate != "" {
+		tp, err := template.New("partition_key").Parse(e.PartitionKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing partition key template failed: %w", err)
+		}
+		e.partitionTp = tp
+	}
+
 	if e.MaxMessageSize > 0 {
 		e.options.MaxBytes = uint64(e.MaxMessageSize)
 	}
@@ -49,9 +74,26 @@ func (e *EventHubs) Connect() error {
 		RetryOptions:  azeventhubs.RetryOptions{MaxRetries: -1},
 	}
 
-	client, err := azeventhubs.NewProducerClientFromConnectionString(e.ConnectionString, "", cfg)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+	// Use the connection string if given and authenticate via Azure Active
+	// Directory (Entra ID) otherwise, e.g. using environment variables,
+	// workload or managed identities.
+	var client *azeventhubs.ProducerClient
+	if e.ConnectionString != "" {
+		c, err := azeventhubs.NewProducerClientFromConnectionString(e.ConnectionString, "", cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client = c
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return fmt.Errorf("failed to get credentials: %w", err)
+		}
+		c, err := azeventhubs.NewProducerClient(e.Namespace, e.EventHub, cred, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client = c
 	}
 	e.client = client
 
@@ -88,16 +130,9 @@ func (e *EventHubs) Write(metrics []telegraf.Metric) error {
 		// Get the batcher for the chosen partition
 		partition := "<default>"
 		batchOptions.PartitionKey = nil
-		if e.PartitionKey != "" {
-			if key, ok := m.GetTag(e.PartitionKey); ok {
-				partition = key
-				batchOptions.PartitionKey = &partition
-			} else if key, ok := m.GetField(e.PartitionKey); ok {
-				if k, ok := key.(string); ok {
-					partition = k
-					batchOptions.PartitionKey = &partition
-				}
-			}
+		if key, ok := e.partitionKey(m); ok {
+			partition = key
+			batchOptions.PartitionKey = &key
 		}
 		if _, found := batches[partition]; !found {
 			batches[partition], err = e.client.NewEventDataBatch(ctx, &batchOptions)
@@ -128,9 +163,9 @@ func (e *EventHubs) Write(metrics []telegraf.Metric) error {
 			return fmt.Errorf("sending batch for partition %q failed: %w", partition, err)
 		}
 
-		// Create a new metric and reiterate over the current metric to be
-		// added in the next iteration of the for loop.
-		batches[partition], err = e.client.NewEventDataBatch(ctx, &e.options)
+		// Create a new batch for the same partition and reiterate over the
+		// current metric to be added in the next iteration of the for loop.
+		batches[partition], err = e.client.NewEventDataBatch(ctx, &batchOptions)
 		if err != nil {
 			return fmt.Errorf("creating batch for partition %q failed: %w", partition, err)
 		}
@@ -149,6 +184,33 @@ func (e *EventHubs) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// partitionKey returns the partition key for the given metric. The boolean is
+// false if no key could be determined and the event should be load-balanced
+// across the partitions.
+func (e *EventHubs) partitionKey(m telegraf.Metric) (string, bool) {
+	if e.partitionTp != nil {
+		var b strings.Builder
+		if err := e.partitionTp.Execute(&b, m.(telegraf.TemplateMetric)); err != nil {
+			e.Log.Errorf("Executing partition key template failed: %v", err)
+			return "", false
+		}
+		return b.String(), b.Len() > 0
+	}
+
+	if e.PartitionKey == "" {
+		return "", false
+	}
+	if key, ok := m.GetTag(e.PartitionKey); ok {
+		return key, true
+	}
+	if key, ok := m.GetField(e.PartitionKey); ok {
+		if k, ok := key.(string); ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
 func (e *EventHubs) send(batch *azeventhubs.EventDataBatch) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
 	defer cancel()
@@ -19,6 +19,121 @@ import (
 	"github.com/influxdata/telegraf/testutil"
 )
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *EventHubs
+		expected string
+	}{
+		{
+			name:     "no connection settings",
+			plugin:   &EventHubs{},
+			expected: "either 'connection_string' or 'namespace' and 'event_hub' must be set",
+		},
+		{
+			name:     "namespace without event hub",
+			plugin:   &EventHubs{Namespace: "namespace.servicebus.windows.net"},
+			expected: "either 'connection_string' or 'namespace' and 'event_hub' must be set",
+		},
+		{
+			name: "connection string and namespace",
+			plugin: &EventHubs{
+				ConnectionString: "Endpoint=sb://namespace.servicebus.windows.net/",
+				Namespace:        "namespace.servicebus.windows.net",
+			},
+			expected: "'connection_string' cannot be used together with 'namespace' and 'event_hub'",
+		},
+		{
+			name: "partition key and template",
+			plugin: &EventHubs{
+				ConnectionString:     "Endpoint=sb://namespace.servicebus.windows.net/",
+				PartitionKey:         "host",
+				PartitionKeyTemplate: `{{.Tag "host"}}`,
+			},
+			expected: "'partition_key' and 'partition_key_template' are mutually exclusive",
+		},
+		{
+			name: "invalid template",
+			plugin: &EventHubs{
+				Namespace:            "namespace.servicebus.windows.net",
+				EventHub:             "hub",
+				PartitionKeyTemplate: `{{.Tag "host"`,
+			},
+			expected: "parsing partition key template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	m := metric.New(
+		"test",
+		map[string]string{"host": "server01", "region": "eu"},
+		map[string]interface{}{"value": 42, "source": "sensor"},
+		time.Unix(0, 0),
+	)
+
+	tests := []struct {
+		name     string
+		key      string
+		template string
+		expected string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "tag",
+			key:      "host",
+			expected: "server01",
+		},
+		{
+			name:     "field",
+			key:      "source",
+			expected: "sensor",
+		},
+		{
+			name: "non-string field",
+			key:  "value",
+		},
+		{
+			name: "missing",
+			key:  "foo",
+		},
+		{
+			name:     "template",
+			template: `{{.Tag "region"}}-{{.Tag "host"}}`,
+			expected: "eu-server01",
+		},
+		{
+			name:     "template with empty result",
+			template: `{{.Tag "foo"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &EventHubs{
+				Namespace:            "namespace.servicebus.windows.net",
+				EventHub:             "hub",
+				PartitionKey:         tt.key,
+				PartitionKeyTemplate: tt.template,
+				Log:                  testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			key, ok := plugin.partitionKey(m)
+			require.Equal(t, tt.expected != "", ok)
+			require.Equal(t, tt.expected, key)
+		})
+	}
+}
+
 func TestEmulatorIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -23,9 +23,12 @@ type Execd struct {
 	Command                  []string        `toml:"command"`
 	Environment              []string        `toml:"environment"`
 	RestartDelay             config.Duration `toml:"restart_delay"`
+	MaxRestarts              int             `toml:"max_restarts"`
+	RestartWindow            config.Duration `toml:"restart_window"`
 	IgnoreSerializationError bool            `toml:"ignore_serialization_error"`
 	UseBatchFormat           bool            `toml:"use_batch_format"`
 	Log                      telegraf.Logger
+	process.Limits
 
 	process    *process.Process
 	serializer telegraf.Serializer
@@ -43,6 +46,9 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+	if err := e.Limits.Validate(); err != nil {
+		return err
+	}
 
 	var err error
 
@@ -52,6 +58,9 @@ func (e *Execd) Init() error {
 	}
 	e.process.Log = e.Log
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
+	e.process.MaxRestarts = e.MaxRestarts
+	e.process.RestartWindow = time.Duration(e.RestartWindow)
+	e.process.Limits = e.Limits
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
 
@@ -0,0 +1,136 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package external
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type External struct {
+	Log telegraf.Logger `toml:"-"`
+	extplugin.Config
+
+	client *extplugin.Client
+}
+
+func (*External) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *External) Init() error {
+	return e.Config.Validate()
+}
+
+func (e *External) Connect() error {
+	client, err := e.Config.Connect(pluginv1.PluginType_PLUGIN_TYPE_OUTPUT, e.Log)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	return nil
+}
+
+func (e *External) Close() error {
+	if e.client != nil {
+		e.client.Close()
+	}
+	return nil
+}
+
+func (e *External) Write(metrics []telegraf.Metric) error {
+	if _, err := e.client.Handshake(context.Background()); err != nil {
+		return err
+	}
+
+	writeErr := &internal.PartialWriteError{
+		MetricsAccept: make([]int, 0, len(metrics)),
+	}
+	var offset int
+	for _, batch := range e.client.Split(extplugin.ToProtoBatch(metrics)) {
+		rejected, err := e.write(batch)
+		if err != nil {
+			if !extplugin.IsRejected(err) {
+				// Keep the metrics not written yet for the next write cycle
+				if len(writeErr.MetricsAccept) == 0 && len(writeErr.MetricsReject) == 0 {
+					return err
+				}
+				writeErr.Err = err
+				return writeErr
+			}
+
+			// The plugin refused the whole batch so drop it
+			e.Log.Errorf("Plugin rejected %d metrics: %s", len(batch), status.Convert(err).Message())
+			writeErr.Err = err
+			for i := range batch {
+				writeErr.MetricsReject = append(writeErr.MetricsReject, offset+i)
+			}
+			offset += len(batch)
+			continue
+		}
+
+		for i := range batch {
+			if _, found := rejected[i]; found {
+				writeErr.MetricsReject = append(writeErr.MetricsReject, offset+i)
+			} else {
+				writeErr.MetricsAccept = append(writeErr.MetricsAccept, offset+i)
+			}
+		}
+		if len(rejected) > 0 && writeErr.Err == nil {
+			writeErr.Err = fmt.Errorf("plugin rejected %d metrics", len(rejected))
+		}
+		offset += len(batch)
+	}
+
+	if writeErr.Err != nil {
+		return writeErr
+	}
+	return nil
+}
+
+// write sends the batch to the plugin and returns the indices of the metrics
+// rejected by the plugin
+func (e *External) write(batch []*pluginv1.Metric) (map[int]struct{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.client.Timeout)
+	defer cancel()
+
+	response, err := pluginv1.NewOutputClient(e.client.Conn).Write(ctx, &pluginv1.WriteRequest{Metrics: batch})
+	if err != nil {
+		return nil, e.client.Check(err)
+	}
+
+	rejected := make(map[int]struct{}, len(response.GetRejected()))
+	for _, idx := range response.GetRejected() {
+		if int(idx) >= len(batch) {
+			e.Log.Warnf("Ignoring rejection of invalid index %d", idx)
+			continue
+		}
+		rejected[int(idx)] = struct{}{}
+	}
+	return rejected, nil
+}
+
+func init() {
+	outputs.Add("external", func() telegraf.Output {
+		return &External{
+			Config: extplugin.Config{
+				RestartDelay:   config.Duration(10 * time.Second),
+				StartupTimeout: config.Duration(30 * time.Second),
+				Timeout:        config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package external
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &External{
+		Config: extplugin.Config{
+			Command:        []string{"telegraf-plugin-example"},
+			StartupTimeout: config.Duration(time.Second),
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "'timeout' must be positive")
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  []string
+		accepted []int
+		rejected []int
+		expected string
+	}{
+		{
+			name:    "success",
+			metrics: []string{"ok", "ok", "ok"},
+		},
+		{
+			name:     "metric rejected",
+			metrics:  []string{"ok", "bad", "ok"},
+			accepted: []int{0, 2},
+			rejected: []int{1},
+			expected: "plugin rejected 1 metrics",
+		},
+		{
+			name:     "batch rejected",
+			metrics:  []string{"invalid", "ok", "ok"},
+			accepted: []int{2},
+			rejected: []int{0, 1},
+			expected: "invalid batch",
+		},
+		{
+			name:     "temporary failure",
+			metrics:  []string{"ok", "ok", "retry"},
+			accepted: []int{0, 1},
+			expected: "database unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin(t)
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			err := plugin.Write(newMetrics(tt.metrics...))
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var writeErr *internal.PartialWriteError
+			require.ErrorAs(t, err, &writeErr)
+			require.ErrorContains(t, err, tt.expected)
+			require.Equal(t, tt.accepted, writeErr.MetricsAccept)
+			require.Equal(t, tt.rejected, writeErr.MetricsReject)
+		})
+	}
+}
+
+func TestWriteRetry(t *testing.T) {
+	plugin := newPlugin(t)
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Metrics are kept for the next write if nothing was written
+	err := plugin.Write(newMetrics("retry", "ok"))
+	require.ErrorContains(t, err, "database unavailable")
+	require.True(t, extplugin.IsTemporary(err))
+
+	var writeErr *internal.PartialWriteError
+	require.NotErrorAs(t, err, &writeErr)
+}
+
+func newPlugin(t *testing.T) *External {
+	plugin := &External{
+		Log: testutil.Logger{},
+		Config: extplugin.Config{
+			Address:        newServer(t),
+			StartupTimeout: config.Duration(5 * time.Second),
+			Timeout:        config.Duration(5 * time.Second),
+		},
+	}
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+func newMetrics(names ...string) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, metric.New(name, map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)))
+	}
+	return metrics
+}
+
+// server accepts batches of at most two metrics and rejects metrics depending
+// on their name
+type server struct {
+	pluginv1.UnimplementedPluginServer
+	pluginv1.UnimplementedOutputServer
+}
+
+func (*server) Handshake(context.Context, *pluginv1.HandshakeRequest) (*pluginv1.HandshakeResponse, error) {
+	return &pluginv1.HandshakeResponse{
+		ProtocolVersion: pluginv1.ProtocolVersion_PROTOCOL_VERSION_1,
+		MaxBatchSize:    2,
+	}, nil
+}
+
+func (*server) Write(_ context.Context, req *pluginv1.WriteRequest) (*pluginv1.WriteResponse, error) {
+	if len(req.GetMetrics()) > 2 {
+		return nil, status.Error(codes.InvalidArgument, "batch too large")
+	}
+
+	response := &pluginv1.WriteResponse{}
+	for i, m := range req.GetMetrics() {
+		switch m.GetName() {
+		case "bad":
+			response.Rejected = append(response.Rejected, uint32(i))
+		case "invalid":
+			return nil, status.Error(codes.InvalidArgument, "invalid batch")
+		case "retry":
+			return nil, status.Error(codes.Unavailable, "database unavailable")
+		}
+	}
+	return response, nil
+}
+
+func newServer(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	pluginv1.RegisterPluginServer(s, &server{})
+	pluginv1.RegisterOutputServer(s, &server{})
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	return "unix://" + path
+}
@@ -32,10 +32,13 @@ type connection struct {
 }
 
 type Graphite struct {
-	GraphiteTagSupport      bool   `toml:"graphite_tag_support"`
-	GraphiteTagSanitizeMode string `toml:"graphite_tag_sanitize_mode"`
-	GraphiteSeparator       string `toml:"graphite_separator"`
-	GraphiteStrictRegex     string `toml:"graphite_strict_sanitize_regex"`
+	GraphiteTagSupport      bool              `toml:"graphite_tag_support"`
+	GraphiteTagSanitizeMode string            `toml:"graphite_tag_sanitize_mode"`
+	GraphiteSeparator       string            `toml:"graphite_separator"`
+	GraphiteStrictRegex     string            `toml:"graphite_strict_sanitize_regex"`
+	GraphiteTagAppend       bool              `toml:"graphite_tag_append"`
+	GraphiteReplacements    map[string]string `toml:"graphite_sanitize_replacements"`
+	GraphiteLowercase       bool              `toml:"graphite_sanitize_lowercase"`
 	// URL is only for backwards compatibility
 	Servers   []string        `toml:"servers"`
 	LocalAddr string          `toml:"local_address"`
@@ -62,6 +65,9 @@ func (g *Graphite) Init() error {
 		TagSupport:      g.GraphiteTagSupport,
 		TagSanitizeMode: g.GraphiteTagSanitizeMode,
 		Separator:       g.GraphiteSeparator,
+		TagAppend:       g.GraphiteTagAppend,
+		Replacements:    g.GraphiteReplacements,
+		Lowercase:       g.GraphiteLowercase,
 		Templates:       g.Templates,
 	}
 	if err := s.Init(); err != nil {
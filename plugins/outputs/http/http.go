@@ -8,9 +8,11 @@ import (
 	"crypto/sha256"
 	_ "embed"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -56,6 +58,7 @@ type HTTP struct {
 
 	client     *http.Client
 	serializer telegraf.Serializer
+	encoder    *common_http.ContentEncodingNegotiator
 
 	awsCfg *aws.Config
 	common_aws.CredentialConfig
@@ -89,6 +92,26 @@ func (h *HTTP) Connect() error {
 		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
 	}
 
+	// Negotiate the encoding with the server in auto mode, otherwise stick to
+	// the configured one
+	encodings := []string{h.ContentEncoding}
+	switch h.ContentEncoding {
+	case "auto":
+		encodings = common_http.DefaultNegotiatedEncodings
+	case "":
+		encodings = []string{"identity"}
+	}
+	tags := map[string]string{"url": h.URL}
+	if u, err := url.Parse(h.URL); err == nil {
+		u.User = nil
+		tags["url"] = u.String()
+	}
+	encoder, err := common_http.NewContentEncodingNegotiator(encodings, "http", tags, h.Log)
+	if err != nil {
+		return err
+	}
+	h.encoder = encoder
+
 	ctx := context.Background()
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
 	if err != nil {
@@ -112,46 +135,71 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 	if h.UseBatchFormat {
 		reqBody, err := h.serializer.SerializeBatch(metrics)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: %w", internal.ErrSerialization, err)
 		}
 
 		return h.writeMetric(reqBody)
 	}
 
-	for _, metric := range metrics {
+	// Metrics are sent one by one, so report the metrics already written as
+	// accepted and only reject the failing metric if it can never be written.
+	// The remaining metrics are kept for the next write.
+	for i, metric := range metrics {
 		reqBody, err := h.serializer.Serialize(metric)
 		if err != nil {
-			return err
+			err = fmt.Errorf("%w: %w", internal.ErrSerialization, err)
+		} else {
+			err = h.writeMetric(reqBody)
 		}
-
-		if err := h.writeMetric(reqBody); err != nil {
-			return err
+		if err != nil {
+			return partialWriteError(err, i)
 		}
 	}
 	return nil
 }
 
-func (h *HTTP) writeMetric(reqBody []byte) error {
-	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
+// partialWriteError returns the error for a write failing at the given index
+// of the batch, accepting the metrics written before
+func partialWriteError(err error, failed int) error {
+	writeErr := &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: make([]int, 0, failed),
+	}
+	for i := range failed {
+		writeErr.MetricsAccept = append(writeErr.MetricsAccept, i)
+	}
 
-	var err error
-	if h.ContentEncoding == "gzip" {
-		rc := internal.CompressWithGzip(reqBodyBuffer)
-		defer rc.Close()
-		reqBodyBuffer = rc
+	var rejectedErr *internal.RejectedError
+	if errors.As(err, &rejectedErr) || errors.Is(err, internal.ErrSerialization) {
+		writeErr.MetricsReject = []int{failed}
+		writeErr.MetricsRejectErrors = []error{err}
 	}
+	return writeErr
+}
 
-	var payloadHash *string
-	if h.awsCfg != nil {
-		// We need a local copy of the full buffer, the signature scheme requires a sha256 of the request body.
-		buf := new(bytes.Buffer)
-		_, err = io.Copy(buf, reqBodyBuffer)
+func (h *HTTP) writeMetric(reqBody []byte) error {
+	for {
+		encoding, body, err := h.encoder.Encode(reqBody)
 		if err != nil {
 			return err
 		}
 
-		sum := sha256.Sum256(buf.Bytes())
-		reqBodyBuffer = buf
+		retry, err := h.send(encoding, body)
+		if !retry {
+			return err
+		}
+	}
+}
+
+// send sends the encoded body to the server and returns true if the request
+// should be retried because the server rejected the encoding
+func (h *HTTP) send(encoding string, body []byte) (bool, error) {
+	var reqBodyBuffer io.Reader = bytes.NewReader(body)
+
+	var payloadHash *string
+	if h.awsCfg != nil {
+		// The signature scheme requires a sha256 of the request body
+		sum := sha256.Sum256(body)
 
 		// sha256 is hex encoded
 		hash := hex.EncodeToString(sum[:])
@@ -160,7 +208,7 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if h.awsCfg != nil {
@@ -169,24 +217,24 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 		credentials, err := h.awsCfg.Credentials.Retrieve(ctx)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		err = signer.SignHTTP(ctx, credentials, req, *payloadHash, h.AwsService, h.Region, time.Now().UTC())
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
 
 	if !h.Username.Empty() || !h.Password.Empty() {
 		username, err := h.Username.Get()
 		if err != nil {
-			return fmt.Errorf("getting username failed: %w", err)
+			return false, fmt.Errorf("getting username failed: %w", err)
 		}
 		password, err := h.Password.Get()
 		if err != nil {
 			username.Destroy()
-			return fmt.Errorf("getting password failed: %w", err)
+			return false, fmt.Errorf("getting password failed: %w", err)
 		}
 		req.SetBasicAuth(username.String(), password.String())
 		username.Destroy()
@@ -197,21 +245,21 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 	if h.CredentialsFile != "" {
 		token, err := h.getAccessToken(context.Background(), h.URL)
 		if err != nil {
-			return err
+			return false, err
 		}
 		token.SetAuthHeader(req)
 	}
 
 	req.Header.Set("User-Agent", internal.ProductToken())
 	req.Header.Set("Content-Type", defaultContentType)
-	if h.ContentEncoding == "gzip" {
-		req.Header.Set("Content-Encoding", "gzip")
+	if encoding != "identity" {
+		req.Header.Set("Content-Encoding", encoding)
 	}
 
 	for k, v := range h.Headers {
 		secret, err := v.Get()
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		headerVal := secret.String()
@@ -225,11 +273,15 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if h.encoder.Rejected(encoding, resp) {
+			return true, nil
+		}
+
 		errorLine := ""
 		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
 		if scanner.Scan() {
@@ -239,19 +291,32 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		for _, nonRetryableStatusCode := range h.NonRetryableStatusCodes {
 			if resp.StatusCode == nonRetryableStatusCode {
 				h.Log.Errorf("Received non-retryable status %v. Metrics are lost. body: %s", resp.StatusCode, errorLine)
-				return nil
+				return false, nil
 			}
 		}
 
-		return fmt.Errorf("when writing to [%s] received status code: %d. body: %s", h.URL, resp.StatusCode, errorLine)
+		err := fmt.Errorf("when writing to [%s] received status code: %d. body: %s", h.URL, resp.StatusCode, errorLine)
+		if isClientError(resp.StatusCode) {
+			return false, &internal.RejectedError{Err: err}
+		}
+		return false, err
 	}
 
 	_, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("when writing to [%s] received error: %w", h.URL, err)
+		return false, fmt.Errorf("when writing to [%s] received error: %w", h.URL, err)
 	}
 
-	return nil
+	return false, nil
+}
+
+// isClientError returns true for client error responses indicating a rejection
+// of the request, except for timeouts and rate limiting which can be retried
+func isClientError(code int) bool {
+	if code == http.StatusRequestTimeout || code == http.StatusTooManyRequests {
+		return false
+	}
+	return code >= 400 && code < 500
 }
 
 func init() {
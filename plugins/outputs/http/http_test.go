@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/common/oauth"
@@ -251,7 +253,20 @@ func TestStatusCode(t *testing.T) {
 			},
 			statusCode: http.StatusBadRequest,
 			errFunc: func(t *testing.T, err error) {
+				var rejectedErr *internal.RejectedError
+				require.ErrorAs(t, err, &rejectedErr)
+			},
+		},
+		{
+			name: "rate limiting is not a rejection",
+			plugin: &HTTP{
+				URL: u.String(),
+			},
+			statusCode: http.StatusTooManyRequests,
+			errFunc: func(t *testing.T, err error) {
+				var rejectedErr *internal.RejectedError
 				require.Error(t, err)
+				require.NotErrorAs(t, err, &rejectedErr)
 			},
 		},
 		{
@@ -287,6 +302,57 @@ func TestStatusCode(t *testing.T) {
 	}
 }
 
+func TestDeadLetterPerMetric(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+
+	filename := filepath.Join(t.TempDir(), "rejected.influx")
+	model := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "http", DeadLetterFile: filename}, 10, 100)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	metrics := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(i)}, time.Unix(0, 0))
+		metrics = append(metrics, m)
+		model.AddMetric(m)
+	}
+	require.Error(t, model.Write())
+
+	// Only the metric rejected by the server is passed to the dead-letter
+	// file, the first one is accepted and the last one is kept for retrying
+	require.Equal(t, 1, model.BufferLength())
+	expected := metrics[1].Copy()
+	expected.AddTag("rejection_reason", "rejected")
+	expected.AddTag("rejected_by", "outputs.http")
+	buf, err := serializer.Serialize(expected)
+	require.NoError(t, err)
+	actual, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, string(buf), string(actual))
+
+	require.NoError(t, model.Write())
+	require.Zero(t, model.BufferLength())
+	require.Equal(t, 3, requests)
+}
+
 func TestContentType(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -417,6 +483,86 @@ func TestContentEncodingGzip(t *testing.T) {
 	}
 }
 
+func TestContentEncodingNegotiation(t *testing.T) {
+	tests := []struct {
+		name           string
+		supported      string
+		acceptEncoding string
+		expected       []string
+	}{
+		{
+			name:           "server announces encoding",
+			supported:      "gzip",
+			acceptEncoding: "gzip, deflate",
+			expected:       []string{"zstd", "gzip", "gzip"},
+		},
+		{
+			name:      "fallback to identity",
+			supported: "",
+			expected:  []string{"zstd", "gzip", "snappy", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get("Content-Encoding")
+				requested = append(requested, encoding)
+				if encoding != tt.supported {
+					if tt.acceptEncoding != "" {
+						w.Header().Set("Accept-Encoding", tt.acceptEncoding)
+					}
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+
+				decoder, err := internal.NewContentDecoder(encoding)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				payload, err := decoder.Decode(body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				if !strings.Contains(string(payload), "cpu value=42") {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("'payload' should contain %q", "cpu value=42")
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			plugin := &HTTP{
+				URL:             ts.URL,
+				Method:          defaultMethod,
+				ContentEncoding: "auto",
+				Log:             testutil.Logger{},
+			}
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Connect())
+
+			// The negotiated encoding is used for subsequent writes
+			require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+			require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+			require.Equal(t, tt.expected, requested)
+		})
+	}
+}
+
 func TestBasicAuth(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"net"
 	"net/http"
@@ -52,6 +51,7 @@ type httpClient struct {
 	bucket           string
 	bucketTag        string
 	excludeBucketTag bool
+	routes           []*route
 	timeout          time.Duration
 	headers          map[string]*config.Secret
 	proxy            *url.URL
@@ -158,36 +158,29 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		return errors.New("retry time has not elapsed")
 	}
 
-	batches := make(map[string][]telegraf.Metric)
-	batchIndices := make(map[string][]int)
-	if c.bucketTag == "" {
-		batches[c.bucket] = metrics
-		batchIndices[c.bucket] = make([]int, len(metrics))
+	batches := make(map[destination][]telegraf.Metric)
+	batchIndices := make(map[destination][]int)
+	if c.bucketTag == "" && len(c.routes) == 0 {
+		dest := destination{organization: c.organization, bucket: c.bucket}
+		batches[dest] = metrics
+		batchIndices[dest] = make([]int, len(metrics))
 		for i := range metrics {
-			batchIndices[c.bucket][i] = i
+			batchIndices[dest][i] = i
 		}
 	} else {
 		for i, metric := range metrics {
-			bucket, ok := metric.GetTag(c.bucketTag)
-			if !ok {
-				bucket = c.bucket
-			} else if c.excludeBucketTag {
-				// Avoid modifying the metric if we do remove the tag
-				metric = metric.Copy()
-				metric.Accept()
-				metric.RemoveTag(c.bucketTag)
-			}
-
-			batches[bucket] = append(batches[bucket], metric)
-			batchIndices[bucket] = append(batchIndices[bucket], i)
+			var dest destination
+			dest, metric = c.destination(metric)
+			batches[dest] = append(batches[dest], metric)
+			batchIndices[dest] = append(batchIndices[dest], i)
 		}
 	}
 
 	var wErr internal.PartialWriteError
-	for bucket, batch := range batches {
-		err := c.writeBatch(ctx, bucket, batch)
+	for dest, batch := range batches {
+		err := c.writeBatch(ctx, dest, batch)
 		if err == nil {
-			wErr.MetricsAccept = append(wErr.MetricsAccept, batchIndices[bucket]...)
+			wErr.MetricsAccept = append(wErr.MetricsAccept, batchIndices[dest]...)
 			continue
 		}
 
@@ -196,11 +189,11 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		if errors.As(err, &apiErr) {
 			if apiErr.StatusCode == http.StatusRequestEntityTooLarge {
 				// TODO: Need a testcase to verify rejected metrics are not retried...
-				return c.splitAndWriteBatch(ctx, c.bucket, metrics)
+				return c.splitAndWriteBatch(ctx, dest, batch)
 			}
 			wErr.Err = err
 			if !apiErr.Retryable {
-				wErr.MetricsReject = append(wErr.MetricsReject, batchIndices[bucket]...)
+				wErr.MetricsReject = append(wErr.MetricsReject, batchIndices[dest]...)
 			}
 			// TODO: Clarify if we should continue here to try the remaining buckets?
 			return &wErr
@@ -212,10 +205,10 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		if errors.As(err, &writeErr) {
 			wErr.Err = writeErr.Err
 			for _, idx := range writeErr.MetricsAccept {
-				wErr.MetricsAccept = append(wErr.MetricsAccept, batchIndices[bucket][idx])
+				wErr.MetricsAccept = append(wErr.MetricsAccept, batchIndices[dest][idx])
 			}
 			for _, idx := range writeErr.MetricsReject {
-				wErr.MetricsReject = append(wErr.MetricsReject, batchIndices[bucket][idx])
+				wErr.MetricsReject = append(wErr.MetricsReject, batchIndices[dest][idx])
 			}
 			if !errors.Is(writeErr.Err, internal.ErrSizeLimitReached) {
 				continue
@@ -230,18 +223,49 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	return nil
 }
 
-func (c *httpClient) splitAndWriteBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
+// destination returns the bucket and organization to write the metric to
+// using the first matching route or the bucket tag. If the bucket tag is
+// used and should be excluded, a copy of the metric without the tag is
+// returned.
+func (c *httpClient) destination(metric telegraf.Metric) (destination, telegraf.Metric) {
+	for _, r := range c.routes {
+		if r.matches(metric) {
+			return destination{organization: r.Organization, bucket: r.Bucket}, metric
+		}
+	}
+
+	dest := destination{organization: c.organization, bucket: c.bucket}
+	if c.bucketTag == "" {
+		return dest, metric
+	}
+	bucket, ok := metric.GetTag(c.bucketTag)
+	if !ok {
+		return dest, metric
+	}
+	dest.bucket = bucket
+	if c.excludeBucketTag {
+		// Avoid modifying the metric if we do remove the tag
+		metric = metric.Copy()
+		metric.Accept()
+		metric.RemoveTag(c.bucketTag)
+	}
+	return dest, metric
+}
+
+func (c *httpClient) splitAndWriteBatch(ctx context.Context, dest destination, metrics []telegraf.Metric) error {
 	c.log.Warnf("Retrying write after splitting metric payload in half to reduce batch size")
 	midpoint := len(metrics) / 2
 
-	if err := c.writeBatch(ctx, bucket, metrics[:midpoint]); err != nil {
+	if err := c.writeBatch(ctx, dest, metrics[:midpoint]); err != nil {
 		return err
 	}
 
-	return c.writeBatch(ctx, bucket, metrics[midpoint:])
+	return c.writeBatch(ctx, dest, metrics[midpoint:])
 }
 
-func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
+func (c *httpClient) writeBatch(ctx context.Context, dest destination, metrics []telegraf.Metric) error {
+	bucket := dest.bucket
+
 	// Get the current limit for the outbound data
 	ratets := time.Now()
 	limit := c.rateLimiter.Remaining(ratets)
@@ -262,35 +286,34 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 	}
 
 	// Setup the request
+	c.params.Set("org", dest.organization)
 	address := makeWriteURL(*c.url, c.params, bucket)
-	req, err := http.NewRequest("POST", address, io.NopCloser(bytes.NewBuffer(body)))
-	if err != nil {
-		return fmt.Errorf("creating request failed: %w", err)
-	}
-	if c.encoder != nil {
-		req.Header.Set("Content-Encoding", c.contentEncoding)
-	}
-	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
-
-	// Set authorization
-	token, err := c.token.Get()
-	if err != nil {
-		return fmt.Errorf("getting token failed: %w", err)
-	}
-	req.Header.Set("Authorization", "Token "+token.String())
-	token.Destroy()
-
-	if err := c.addHeaders(req); err != nil {
-		return fmt.Errorf("adding headers failed: %w", err)
-	}
 
 	// Execute the request
 	c.rateLimiter.Accept(ratets, used)
-	resp, err := c.client.Do(req.WithContext(ctx))
+	resp, token, err := c.send(ctx, address, body)
 	if err != nil {
-		internal.OnClientError(c.client, err)
 		return err
 	}
+
+	// The token might have been rotated in the secret-store after we got it,
+	// so retry once with the current token instead of failing the write
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		current, err := c.token.Get()
+		if err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		rotated := current.String() != token
+		current.Destroy()
+		if rotated {
+			c.log.Debugf("Token changed, retrying write to %s", bucket)
+			resp.Body.Close()
+			if resp, _, err = c.send(ctx, address, body); err != nil {
+				return err
+			}
+		}
+	}
 	defer resp.Body.Close()
 
 	// Check for success
@@ -398,6 +421,39 @@ func (c *httpClient) getRetryDuration(headers http.Header) time.Duration {
 	return time.Duration(retry*1000) * time.Millisecond
 }
 
+// send posts the given body to the address and returns the response as well
+// as the token used for authorization
+func (c *httpClient) send(ctx context.Context, address string, body []byte) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", address, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fmt.Errorf("creating request failed: %w", err)
+	}
+	if c.encoder != nil {
+		req.Header.Set("Content-Encoding", c.contentEncoding)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	// Set authorization
+	token, err := c.token.Get()
+	if err != nil {
+		return nil, "", fmt.Errorf("getting token failed: %w", err)
+	}
+	tokenStr := token.String()
+	token.Destroy()
+	req.Header.Set("Authorization", "Token "+tokenStr)
+
+	if err := c.addHeaders(req); err != nil {
+		return nil, "", fmt.Errorf("adding headers failed: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		internal.OnClientError(c.client, err)
+		return nil, "", err
+	}
+	return resp, tokenStr, nil
+}
+
 func (c *httpClient) addHeaders(req *http.Request) error {
 	for header, value := range c.headers {
 		secret, err := value.Get()
@@ -2,15 +2,22 @@ package influxdb_v2
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"path"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestHTTPClientInit(t *testing.T) {
@@ -269,3 +276,71 @@ func oldMakeWriteURL(loc url.URL) (string, error) {
 	loc.RawQuery = params.Encode()
 	return loc.String(), nil
 }
+
+func TestWriteRoutes(t *testing.T) {
+	// Setup a test server recording the destination of the metrics
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		dest := r.URL.Query().Get("org") + "/" + r.URL.Query().Get("bucket")
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+			received[dest] = append(received[dest], strings.Fields(line)[0])
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &InfluxDB{
+		URLs:             []string{ts.URL},
+		Organization:     "main",
+		Bucket:           "telegraf",
+		BucketTag:        "bucket",
+		ExcludeBucketTag: true,
+		ContentEncoding:  "identity",
+		Routes: []*route{
+			{Measurements: []string{"cpu", "disk*"}, Bucket: "system"},
+			{Tag: "env", TagValues: []string{"prod*"}, Bucket: "production", Organization: "ops"},
+			{Tag: "team", Bucket: "teams"},
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"env": "prod-eu"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("diskio", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"env": "production"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"env": "dev", "team": "a"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"env": "dev", "bucket": "dev"}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+		metric.New("net", map[string]string{}, map[string]interface{}{"value": 6}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := map[string][]string{
+		"main/system":    {"cpu,env=prod-eu", "diskio"},
+		"ops/production": {"mem,env=production"},
+		"main/teams":     {"mem,env=dev,team=a"},
+		"main/dev":       {"mem,env=dev"},
+		"main/telegraf":  {"net"},
+	}
+	require.Equal(t, expected, received)
+}
+
+func TestInvalidRoutes(t *testing.T) {
+	plugin := &InfluxDB{Routes: []*route{{Measurements: []string{"cpu"}}}}
+	require.ErrorContains(t, plugin.Init(), "invalid route 1: bucket required")
+
+	plugin = &InfluxDB{Routes: []*route{{Bucket: "a"}, {TagValues: []string{"prod"}, Bucket: "b"}}}
+	require.ErrorContains(t, plugin.Init(), "invalid route 2: 'tag_values' requires 'tag' to be set")
+}
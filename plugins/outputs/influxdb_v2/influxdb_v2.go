@@ -34,6 +34,7 @@ type InfluxDB struct {
 	Bucket           string                    `toml:"bucket"`
 	BucketTag        string                    `toml:"bucket_tag"`
 	ExcludeBucketTag bool                      `toml:"exclude_bucket_tag"`
+	Routes           []*route                  `toml:"route"`
 	Timeout          config.Duration           `toml:"timeout"`
 	HTTPHeaders      map[string]*config.Secret `toml:"http_headers"`
 	HTTPProxy        string                    `toml:"http_proxy"`
@@ -81,6 +82,15 @@ func (i *InfluxDB) Init() error {
 		return fmt.Errorf("invalid content encoding %q", i.ContentEncoding)
 	}
 
+	for idx, r := range i.Routes {
+		if err := r.init(); err != nil {
+			return fmt.Errorf("invalid route %d: %w", idx+1, err)
+		}
+		if r.Organization == "" {
+			r.Organization = i.Organization
+		}
+	}
+
 	// Setup the limited serializer
 	serializer := &influx.Serializer{
 		UintSupport:   i.UintSupport,
@@ -157,6 +167,7 @@ func (i *InfluxDB) Connect() error {
 				bucket:           i.Bucket,
 				bucketTag:        i.BucketTag,
 				excludeBucketTag: i.ExcludeBucketTag,
+				routes:           i.Routes,
 				timeout:          time.Duration(i.Timeout),
 				headers:          i.HTTPHeaders,
 				proxy:            proxy,
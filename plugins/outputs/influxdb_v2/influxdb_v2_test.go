@@ -909,6 +909,52 @@ func TestUseDynamicSecret(t *testing.T) {
 	require.NoError(t, plugin.Write(metrics))
 }
 
+func TestTokenRotation(t *testing.T) {
+	secretToken := config.NewSecret([]byte("old"))
+	defer secretToken.Destroy()
+
+	// Setup a test server rotating the token on the first request
+	var requests atomic.Int32
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if r.Header.Get("Authorization") == "Token new" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if err := secretToken.Set([]byte("new")); err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}),
+	)
+	defer ts.Close()
+
+	// Setup plugin and connect
+	plugin := &influxdb.InfluxDB{
+		URLs:   []string{"http://" + ts.Listener.Addr().String()},
+		Log:    &testutil.Logger{},
+		Bucket: "my_bucket",
+		Token:  secretToken,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 0.0,
+			},
+			time.Unix(0, 3),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, int32(2), requests.Load())
+}
+
 func BenchmarkWrite1k(b *testing.B) {
 	batchsize := 1000
 
@@ -0,0 +1,63 @@
+package influxdb_v2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// route sends metrics matching the given measurement and tag patterns to a
+// dedicated bucket and organization
+type route struct {
+	Measurements []string `toml:"measurements"`
+	Tag          string   `toml:"tag"`
+	TagValues    []string `toml:"tag_values"`
+	Bucket       string   `toml:"bucket"`
+	Organization string   `toml:"organization"`
+
+	measurementFilter filter.Filter
+	tagValueFilter    filter.Filter
+}
+
+// destination identifies the bucket of an organization to write to
+type destination struct {
+	organization string
+	bucket       string
+}
+
+func (r *route) init() error {
+	if r.Bucket == "" {
+		return errors.New("bucket required")
+	}
+	if r.Tag == "" && len(r.TagValues) > 0 {
+		return errors.New("'tag_values' requires 'tag' to be set")
+	}
+
+	var err error
+	if r.measurementFilter, err = filter.Compile(r.Measurements); err != nil {
+		return fmt.Errorf("compiling measurement filter failed: %w", err)
+	}
+	if r.tagValueFilter, err = filter.Compile(r.TagValues); err != nil {
+		return fmt.Errorf("compiling tag-value filter failed: %w", err)
+	}
+	return nil
+}
+
+// matches returns true if the metric's name matches one of the measurement
+// patterns and if the tag is present with a value matching the tag-value
+// patterns. Empty criteria match all metrics.
+func (r *route) matches(m telegraf.Metric) bool {
+	if r.measurementFilter != nil && !r.measurementFilter.Match(m.Name()) {
+		return false
+	}
+	if r.Tag == "" {
+		return true
+	}
+	value, found := m.GetTag(r.Tag)
+	if !found {
+		return false
+	}
+	return r.tagValueFilter == nil || r.tagValueFilter.Match(value)
+}
@@ -6,15 +6,21 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/wavefronthq/wavefront-sdk-go/histogram"
 	wavefront "github.com/wavefronthq/wavefront-sdk-go/senders"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/delta"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	serializers_wavefront "github.com/influxdata/telegraf/plugins/serializers/wavefront"
@@ -25,6 +31,9 @@ var sampleConfig string
 
 const maxTagLength = 254
 
+// Time after which the last value of a counter series is forgotten
+const deltaExpiry = time.Hour
+
 type authCSPClientCredentials struct {
 	AppID     config.Secret `toml:"app_id"`
 	AppSecret config.Secret `toml:"app_secret"`
@@ -51,12 +60,17 @@ type Wavefront struct {
 	ImmediateFlush           bool                            `toml:"immediate_flush"`
 	SendInternalMetrics      bool                            `toml:"send_internal_metrics"`
 	SourceOverride           []string                        `toml:"source_override"`
+	SendDeltaCounters        bool                            `toml:"send_delta_counters"`
+	SendDistributions        bool                            `toml:"send_distributions"`
+	DistributionGranularity  []string                        `toml:"distribution_granularity"`
 	StringToNumber           map[string][]map[string]float64 `toml:"string_to_number" deprecated:"1.9.0;1.35.0;use the enum processor instead"`
 
 	common_http.HTTPClientConfig
 
-	sender wavefront.Sender
-	Log    telegraf.Logger `toml:"-"`
+	sender        wavefront.Sender
+	deltas        *delta.Tracker
+	granularities map[histogram.Granularity]bool
+	Log           telegraf.Logger `toml:"-"`
 }
 
 // instead of Sanitize which may miss some special characters we can use a regex pattern, but this is significantly slower than Sanitize
@@ -70,6 +84,31 @@ func (*Wavefront) SampleConfig() string {
 	return sampleConfig
 }
 
+func (w *Wavefront) Init() error {
+	if w.SendDeltaCounters || w.SendDistributions {
+		w.deltas = delta.NewTracker(deltaExpiry)
+	}
+
+	if len(w.DistributionGranularity) == 0 {
+		w.DistributionGranularity = []string{"minute"}
+	}
+	w.granularities = make(map[histogram.Granularity]bool, len(w.DistributionGranularity))
+	for _, g := range w.DistributionGranularity {
+		switch g {
+		case "minute":
+			w.granularities[histogram.MINUTE] = true
+		case "hour":
+			w.granularities[histogram.HOUR] = true
+		case "day":
+			w.granularities[histogram.DAY] = true
+		default:
+			return fmt.Errorf("invalid distribution granularity %q", g)
+		}
+	}
+
+	return nil
+}
+
 func (w *Wavefront) parseConnectionURL() (string, error) {
 	if w.URL == "" {
 		if w.Host == "" || w.Port <= 0 {
@@ -138,29 +177,40 @@ func (w *Wavefront) Connect() error {
 
 func (w *Wavefront) Write(metrics []telegraf.Metric) error {
 	for _, m := range metrics {
-		for _, point := range w.buildMetrics(m) {
-			err := w.sender.SendMetric(point.Metric, point.Value, point.Timestamp, point.Source, point.Tags)
-			if err != nil {
-				if isRetryable(err) {
-					// The internal buffer in the Wavefront SDK is full. To prevent data loss,
-					// we flush the buffer (which is a blocking operation) and try again.
-					w.Log.Debug("SDK buffer overrun, forcibly flushing the buffer")
-					if err = w.sender.Flush(); err != nil {
-						return fmt.Errorf("wavefront flushing error: %w", err)
-					}
-					// Try again.
-					err = w.sender.SendMetric(point.Metric, point.Value, point.Timestamp, point.Source, point.Tags)
-					if err != nil {
-						if isRetryable(err) {
-							return fmt.Errorf("wavefront sending error: %w", err)
-						}
-					}
+		// Send the buckets of histograms as distribution, the remaining fields
+		// such as sum and count are sent as usual
+		if w.SendDistributions && m.Type() == telegraf.Histogram {
+			var err error
+			if m, err = w.sendDistribution(m); err != nil {
+				return err
+			}
+		}
+
+		for _, field := range m.FieldList() {
+			point := w.buildPoint(m, field.Key, field.Value)
+			if point == nil {
+				continue
+			}
+			send := func() error {
+				return w.sender.SendMetric(point.Metric, point.Value, point.Timestamp, point.Source, point.Tags)
+			}
+			if w.SendDeltaCounters && delta.IsCumulative(m, field.Key) {
+				diff, _, ok := w.deltas.Delta(m, field.Key, point.Value)
+				if !ok {
+					continue
 				}
-				w.Log.Errorf("Non-retryable error during Wavefront.Write: %v", err)
-				w.Log.Debugf("Non-retryable metric data: %+v", point)
+				send = func() error {
+					return w.sender.SendDeltaCounter(point.Metric, diff, point.Source, point.Tags)
+				}
+			}
+			if err := w.send(send, point); err != nil {
+				return err
 			}
 		}
 	}
+	if w.deltas != nil {
+		w.deltas.Expire()
+	}
 	if w.ImmediateFlush {
 		w.Log.Debugf("Flushing batch of %d points", len(metrics))
 		return w.sender.Flush()
@@ -168,46 +218,155 @@ func (w *Wavefront) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// send calls the given send function and flushes the buffer and retries
+// sending once if the internal buffer is full
+func (w *Wavefront) send(send func() error, point interface{}) error {
+	err := send()
+	if err == nil {
+		return nil
+	}
+	if isRetryable(err) {
+		// The internal buffer in the Wavefront SDK is full. To prevent data loss,
+		// we flush the buffer (which is a blocking operation) and try again.
+		w.Log.Debug("SDK buffer overrun, forcibly flushing the buffer")
+		if err := w.sender.Flush(); err != nil {
+			return fmt.Errorf("wavefront flushing error: %w", err)
+		}
+		// Try again.
+		if err = send(); err == nil {
+			return nil
+		}
+		if isRetryable(err) {
+			return fmt.Errorf("wavefront sending error: %w", err)
+		}
+	}
+	w.Log.Errorf("Non-retryable error during Wavefront.Write: %v", err)
+	w.Log.Debugf("Non-retryable metric data: %+v", point)
+	return nil
+}
+
 func (w *Wavefront) buildMetrics(m telegraf.Metric) []*serializers_wavefront.MetricPoint {
 	ret := make([]*serializers_wavefront.MetricPoint, 0)
 
 	for fieldName, value := range m.Fields() {
-		var name string
-		if !w.SimpleFields && fieldName == "value" {
-			name = fmt.Sprintf("%s%s", w.Prefix, m.Name())
-		} else {
-			name = fmt.Sprintf("%s%s%s%s", w.Prefix, m.Name(), w.MetricSeparator, fieldName)
+		if metric := w.buildPoint(m, fieldName, value); metric != nil {
+			ret = append(ret, metric)
 		}
+	}
+	return ret
+}
 
-		if w.UseRegex {
-			name = sanitizedRegex.ReplaceAllLiteralString(name, "-")
-		} else {
-			name = serializers_wavefront.Sanitize(w.UseStrict, name)
-		}
+func (w *Wavefront) buildPoint(m telegraf.Metric, fieldName string, value interface{}) *serializers_wavefront.MetricPoint {
+	var name string
+	if !w.SimpleFields && fieldName == "value" {
+		name = fmt.Sprintf("%s%s", w.Prefix, m.Name())
+	} else {
+		name = fmt.Sprintf("%s%s%s%s", w.Prefix, m.Name(), w.MetricSeparator, fieldName)
+	}
 
-		if w.ConvertPaths {
-			name = pathReplacer.Replace(name)
-		}
+	point := &serializers_wavefront.MetricPoint{
+		Metric:    w.formatName(name),
+		Timestamp: m.Time().Unix(),
+	}
 
-		metric := &serializers_wavefront.MetricPoint{
-			Metric:    name,
-			Timestamp: m.Time().Unix(),
-		}
+	metricValue, buildError := buildValue(value, point.Metric, w)
+	if buildError != nil {
+		w.Log.Debugf("Error building tags: %s\n", buildError.Error())
+		return nil
+	}
+	point.Value = metricValue
+
+	source, tags := w.buildTags(m.Tags())
+	point.Source = source
+	point.Tags = tags
+
+	return point
+}
+
+func (w *Wavefront) formatName(name string) string {
+	if w.UseRegex {
+		name = sanitizedRegex.ReplaceAllLiteralString(name, "-")
+	} else {
+		name = serializers_wavefront.Sanitize(w.UseStrict, name)
+	}
+
+	if w.ConvertPaths {
+		name = pathReplacer.Replace(name)
+	}
+	return name
+}
+
+// sendDistribution sends the buckets of a histogram metric as distribution of
+// the observations since the last write. The metric is expected to contain a
+// field per bucket named by the upper bound of the bucket and holding the
+// cumulative count of observations, e.g. as produced by the prometheus input
+// with metric_version = 1. The buckets are converted to centroids located at
+// the upper bound of the bucket. A new metric without the bucket fields is
+// returned, which is not tracked so the delivery of the original metric is
+// unaffected.
+func (w *Wavefront) sendDistribution(m telegraf.Metric) (telegraf.Metric, error) {
+	type bucket struct {
+		bound float64
+		count float64
+	}
 
-		metricValue, buildError := buildValue(value, metric.Metric, w)
-		if buildError != nil {
-			w.Log.Debugf("Error building tags: %s\n", buildError.Error())
+	fields := make(map[string]interface{}, len(m.FieldList()))
+	buckets := make([]bucket, 0, len(m.FieldList()))
+	complete := true
+	for _, field := range m.FieldList() {
+		bound, err := strconv.ParseFloat(field.Key, 64)
+		if err != nil {
+			fields[field.Key] = field.Value
+			continue
+		}
+		value, err := buildValue(field.Value, field.Key, w)
+		if err != nil {
+			fields[field.Key] = field.Value
 			continue
 		}
-		metric.Value = metricValue
-
-		source, tags := w.buildTags(m.Tags())
-		metric.Source = source
-		metric.Tags = tags
 
-		ret = append(ret, metric)
+		diff, _, ok := w.deltas.Delta(m, field.Key, value)
+		if !ok {
+			complete = false
+			continue
+		}
+		buckets = append(buckets, bucket{bound: bound, count: diff})
 	}
-	return ret
+	rest := metric.New(m.Name(), m.Tags(), fields, m.Time(), m.Type())
+	if !complete || len(buckets) == 0 {
+		return rest, nil
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+
+	// Convert the cumulative counts into the counts per bucket
+	centroids := make([]histogram.Centroid, 0, len(buckets))
+	var previous float64
+	for i, b := range buckets {
+		count := b.count - previous
+		previous = b.count
+		value := b.bound
+		if math.IsInf(value, 1) {
+			// Observations above the largest bound are located at that bound
+			if i == 0 {
+				continue
+			}
+			value = buckets[i-1].bound
+		}
+		if count < 1 {
+			continue
+		}
+		centroids = append(centroids, histogram.Centroid{Value: value, Count: int(count)})
+	}
+	if len(centroids) == 0 {
+		return rest, nil
+	}
+
+	name := w.formatName(w.Prefix + m.Name())
+	source, tags := w.buildTags(m.Tags())
+	err := w.send(func() error {
+		return w.sender.SendDistribution(name, centroids, w.granularities, m.Time().Unix(), source, tags)
+	}, centroids)
+	return rest, err
 }
 
 func (w *Wavefront) buildTags(mTags map[string]string) (string, map[string]string) {
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wavefronthq/wavefront-sdk-go/histogram"
+	wavefront "github.com/wavefronthq/wavefront-sdk-go/senders"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -462,3 +464,129 @@ func BenchmarkReplacer(b *testing.B) {
 		serializers_wavefront.Sanitize(false, testString)
 	}
 }
+
+type distribution struct {
+	name      string
+	centroids []histogram.Centroid
+	tags      map[string]string
+}
+
+type mockSender struct {
+	wavefront.Sender
+	metrics       map[string]float64
+	deltas        map[string]float64
+	distributions []distribution
+}
+
+func (s *mockSender) SendMetric(name string, value float64, _ int64, _ string, _ map[string]string) error {
+	s.metrics[name] = value
+	return nil
+}
+
+func (s *mockSender) SendDeltaCounter(name string, value float64, _ string, _ map[string]string) error {
+	s.deltas[name] = value
+	return nil
+}
+
+func (s *mockSender) SendDistribution(name string, centroids []histogram.Centroid, _ map[histogram.Granularity]bool, _ int64, _ string, tags map[string]string) error {
+	s.distributions = append(s.distributions, distribution{name: name, centroids: centroids, tags: tags})
+	return nil
+}
+
+func (*mockSender) Flush() error {
+	return nil
+}
+
+func TestDeltaCountersAndDistributions(t *testing.T) {
+	w := defaultWavefront()
+	w.Prefix = ""
+	w.ImmediateFlush = true
+	w.SendDeltaCounters = true
+	w.SendDistributions = true
+	require.NoError(t, w.Init())
+
+	input := func(requests, le1, le5, inf int) []telegraf.Metric {
+		return []telegraf.Metric{
+			metric.New(
+				"http",
+				map[string]string{"host": "a"},
+				map[string]interface{}{"requests": requests},
+				time.Unix(0, 0),
+				telegraf.Counter,
+			),
+			metric.New(
+				"latency",
+				map[string]string{"host": "a", "path": "/"},
+				map[string]interface{}{"1": le1, "5": le5, "+Inf": inf, "count": inf, "sum": 42.0},
+				time.Unix(0, 0),
+				telegraf.Histogram,
+			),
+			metric.New(
+				"temperature",
+				map[string]string{"host": "a"},
+				map[string]interface{}{"value": 21.5},
+				time.Unix(0, 0),
+				telegraf.Gauge,
+			),
+		}
+	}
+
+	// The first write only records the cumulative values
+	sender := &mockSender{metrics: make(map[string]float64), deltas: make(map[string]float64)}
+	w.sender = sender
+	require.NoError(t, w.Write(input(100, 10, 15, 20)))
+	require.Equal(t, map[string]float64{"temperature": 21.5}, sender.metrics)
+	require.Empty(t, sender.deltas)
+	require.Empty(t, sender.distributions)
+
+	sender = &mockSender{metrics: make(map[string]float64), deltas: make(map[string]float64)}
+	w.sender = sender
+	require.NoError(t, w.Write(input(110, 13, 20, 30)))
+	require.Equal(t, map[string]float64{"temperature": 21.5}, sender.metrics)
+	require.Equal(t, map[string]float64{"http.requests": 10, "latency.count": 10, "latency.sum": 0}, sender.deltas)
+	expected := []distribution{
+		{
+			name:      "latency",
+			centroids: []histogram.Centroid{{Value: 1, Count: 3}, {Value: 5, Count: 2}, {Value: 5, Count: 5}},
+			tags:      map[string]string{"path": "/"},
+		},
+	}
+	require.Equal(t, expected, sender.distributions)
+}
+
+func TestDistributionTrackingMetrics(t *testing.T) {
+	w := defaultWavefront()
+	w.SendDistributions = true
+	require.NoError(t, w.Init())
+	w.sender = &mockSender{metrics: make(map[string]float64), deltas: make(map[string]float64)}
+
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	for _, count := range []int{10, 20} {
+		m := metric.New(
+			"latency",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"1": count, "+Inf": count, "sum": 42.0},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		)
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, w.Write([]telegraf.Metric{tm}))
+		tm.Accept()
+	}
+
+	// Each metric is delivered once accepted by the output
+	require.Len(t, delivered, 2)
+	for _, di := range delivered {
+		require.True(t, di.Delivered())
+	}
+}
+
+func TestInvalidDistributionGranularity(t *testing.T) {
+	w := defaultWavefront()
+	w.DistributionGranularity = []string{"minute", "week"}
+	require.ErrorContains(t, w.Init(), `invalid distribution granularity "week"`)
+}
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.wasm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/wasm" // register plugin
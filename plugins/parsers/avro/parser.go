@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
@@ -39,7 +40,7 @@ type Parser struct {
 	UnionMode        string            `toml:"avro_union_mode"`
 	DefaultTags      map[string]string `toml:"tags"`
 	Log              telegraf.Logger   `toml:"-"`
-	registryObj      *schemaRegistry
+	registryObj      *schemaregistry.Registry
 }
 
 func (p *Parser) Init() error {
@@ -72,7 +73,7 @@ func (p *Parser) Init() error {
 		return fmt.Errorf("invalid timestamp format '%v'", p.TimestampFormat)
 	}
 	if p.SchemaRegistry != "" {
-		registry, err := newSchemaRegistry(p.SchemaRegistry, p.CaCertPath)
+		registry, err := schemaregistry.New(p.SchemaRegistry, p.CaCertPath)
 		if err != nil {
 			return fmt.Errorf("error connecting to the schema registry %q: %w", p.SchemaRegistry, err)
 		}
@@ -95,7 +96,7 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 			return nil, errors.New("first byte is not 0: not Confluent Wire Protocol")
 		}
 		schemaID := int(binary.BigEndian.Uint32(buf[1:5]))
-		schemastruct, err := p.registryObj.getSchemaAndCodec(schemaID)
+		schemastruct, err := p.registryObj.GetSchemaAndCodec(schemaID)
 		if err != nil {
 			return nil, err
 		}
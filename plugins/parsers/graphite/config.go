@@ -2,7 +2,10 @@ package graphite
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/influxdata/telegraf/internal/templating"
 )
 
 const (
@@ -15,6 +18,9 @@ const (
 type Config struct {
 	Separator string
 	Templates []string
+	// AllowOverrides permits duplicate filters where later templates replace
+	// earlier ones with the same filter and priority
+	AllowOverrides bool
 }
 
 // Validate validates the config's templates and tags.
@@ -36,17 +42,29 @@ func (c *Config) validateTemplates() error {
 			return fmt.Errorf("missing template at position: %d", i)
 		}
 
+		// Strip the optional attributes of the template
+		parts, attrs := templating.SplitAttributes(parts)
+		if joiner, ok := attrs["separator"]; ok && joiner == "" {
+			return fmt.Errorf("empty separator in template %q", template)
+		}
+		priority, ok := attrs["priority"]
+		if ok {
+			if _, err := strconv.Atoi(priority); err != nil {
+				return fmt.Errorf("invalid priority in template %q", template)
+			}
+		}
+
 		if len(parts) > 3 {
 			return fmt.Errorf("invalid template format: %q", template)
 		}
 
 		filter := ""
 		tags := ""
+		template = parts[0]
 		if len(parts) >= 2 {
 			// We could have <filter> <template>  or <template> <tags>.  Equals is only allowed in
 			// tags section.
 			if strings.Contains(parts[1], "=") {
-				template = parts[0]
 				tags = parts[1]
 			} else {
 				filter = parts[0]
@@ -58,16 +76,18 @@ func (c *Config) validateTemplates() error {
 			tags = parts[2]
 		}
 
-		// Validate the template has one and only one measurement
-		if err := validateTemplate(template); err != nil {
+		// Validate the template using the same rules as the parser
+		if _, err := templating.NewTemplate(DefaultSeparator, template, nil); err != nil {
 			return err
 		}
 
-		// Prevent duplicate filters in the config
-		if _, ok := filters[filter]; ok {
+		// Prevent duplicate filters in the config unless their priorities
+		// resolve the overlap or later templates override earlier ones
+		key := filter + " " + priority
+		if _, ok := filters[key]; ok && !c.AllowOverrides {
 			return fmt.Errorf("duplicate filter %q found at position: %d", filter, i)
 		}
-		filters[filter] = struct{}{}
+		filters[key] = struct{}{}
 
 		if filter != "" {
 			// Validate filter expression is valid
@@ -88,22 +108,42 @@ func (c *Config) validateTemplates() error {
 	return nil
 }
 
-func validateTemplate(template string) error {
-	hasMeasurement := false
-	for _, p := range strings.Split(template, ".") {
-		if p == "measurement" || p == "measurement*" {
-			hasMeasurement = true
-		}
+// overrideKey returns the key of templates replacing each other in the
+// overrides mode, i.e. the filter together with the priority of the template
+func overrideKey(template string) string {
+	parts, attrs := templating.SplitAttributes(strings.Fields(template))
+	var filter string
+	if len(parts) >= 2 && !strings.Contains(parts[1], "=") {
+		filter = parts[0]
 	}
+	return filter + " " + attrs["priority"]
+}
 
-	if !hasMeasurement {
-		return fmt.Errorf("no measurement in template %q", template)
+// applyOverrides returns the given templates without those replaced by a later
+// template with the same filter and priority
+func applyOverrides(templates []string) (effective, overridden []string) {
+	keys := make([]string, 0, len(templates))
+	last := make(map[string]int, len(templates))
+	for i, template := range templates {
+		key := overrideKey(template)
+		keys = append(keys, key)
+		last[key] = i
 	}
 
-	return nil
+	effective = make([]string, 0, len(last))
+	for i, template := range templates {
+		if last[keys[i]] != i {
+			overridden = append(overridden, template)
+			continue
+		}
+		effective = append(effective, template)
+	}
+	return effective, overridden
 }
 
 func validateFilter(filter string) error {
+	// Filters can be negated by a leading exclamation mark
+	filter = strings.TrimPrefix(filter, "!")
 	for _, p := range strings.Split(filter, ".") {
 		if p == "" {
 			return fmt.Errorf("filter contains blank section: %s", filter)
@@ -2,14 +2,17 @@ package graphite
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
@@ -21,12 +24,29 @@ var (
 	MaxDate = time.Date(2038, 1, 19, 0, 0, 0, 0, time.UTC)
 )
 
+const defaultTemplatesCheckInterval = config.Duration(30 * time.Second)
+
 type Parser struct {
-	Separator   string            `toml:"separator"`
-	Templates   []string          `toml:"templates"`
-	DefaultTags map[string]string ` toml:"-"`
+	Separator              string            `toml:"separator"`
+	FieldSeparator         string            `toml:"field_separator"`
+	Templates              []string          `toml:"templates"`
+	TemplatesFiles         []string          `toml:"templates_files"`
+	TemplatesCheckInterval config.Duration   `toml:"templates_check_interval"`
+	AllowOverrides         bool              `toml:"allow_overrides"`
+	TagMode                string            `toml:"graphite_tag_mode"`
+	OnUnmatched            string            `toml:"on_unmatched"`
+	UnmatchedTag           string            `toml:"unmatched_tag"`
+	Protocol               string            `toml:"graphite_protocol"`
+	TimestampUnit          string            `toml:"graphite_timestamp_unit"`
+	MissingTimestamp       string            `toml:"graphite_missing_timestamp"`
+	MaxTimestampSkew       config.Duration   `toml:"graphite_max_timestamp_skew"`
+	DefaultTags            map[string]string ` toml:"-"`
+	Log                    telegraf.Logger   `toml:"-"`
 
-	templateEngine *templating.Engine
+	templateEngine atomic.Pointer[templating.Engine]
+	templatesState templatesState
+	nextCheck      atomic.Int64
+	reloading      atomic.Bool
 }
 
 func (p *Parser) Init() error {
@@ -35,20 +55,70 @@ func (p *Parser) Init() error {
 		p.Separator = DefaultSeparator
 	}
 
-	defaultTemplate, err := templating.NewDefaultTemplateWithPattern("measurement*")
-	if err != nil {
-		return fmt.Errorf("creating template failed: %w", err)
+	switch p.TagMode {
+	case "":
+		p.TagMode = "override"
+	case "override", "merge":
+	default:
+		return fmt.Errorf("invalid 'graphite_tag_mode' %q", p.TagMode)
+	}
+
+	switch p.OnUnmatched {
+	case "":
+		p.OnUnmatched = "keep"
+	case "keep", "drop", "error":
+	default:
+		return fmt.Errorf("invalid 'on_unmatched' %q", p.OnUnmatched)
 	}
 
-	p.templateEngine, err = templating.NewEngine(p.Separator, defaultTemplate, p.Templates)
+	switch p.Protocol {
+	case "":
+		p.Protocol = "line"
+	case "line", "pickle":
+	default:
+		return fmt.Errorf("invalid 'graphite_protocol' %q", p.Protocol)
+	}
+
+	switch p.TimestampUnit {
+	case "":
+		p.TimestampUnit = "s"
+	case "s", "ms":
+	default:
+		return fmt.Errorf("invalid 'graphite_timestamp_unit' %q", p.TimestampUnit)
+	}
+
+	switch p.MissingTimestamp {
+	case "":
+		p.MissingTimestamp = "now"
+	case "now", "drop":
+	default:
+		return fmt.Errorf("invalid 'graphite_missing_timestamp' %q", p.MissingTimestamp)
+	}
+
+	if p.MaxTimestampSkew < 0 {
+		return errors.New("'graphite_max_timestamp_skew' must not be negative")
+	}
+
+	files, err := p.initTemplatesFiles()
+	if err != nil {
+		return err
+	}
+	engine, err := p.newEngine(files)
 	if err != nil {
-		return fmt.Errorf("creating template engine failed: %w ", err)
+		return err
 	}
+	p.templateEngine.Store(engine)
 
 	return nil
 }
 
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	p.checkTemplatesFiles()
+
+	if p.Protocol == "pickle" {
+		return p.parsePickle(buf)
+	}
+
 	// parse even if the buffer begins with a newline
 	if len(buf) != 0 && buf[0] == '\n' {
 		buf = buf[1:]
@@ -56,6 +126,7 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 
 	var metrics []telegraf.Metric
 	var errs []string
+	var merger compositeMerger
 
 	for {
 		n := bytes.IndexByte(buf, '\n')
@@ -66,11 +137,11 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 			line = bytes.TrimSpace(buf) // last line
 		}
 		if len(line) != 0 {
-			m, err := p.ParseLine(string(line))
-			if err == nil {
-				metrics = append(metrics, m)
-			} else {
+			m, composite, err := p.parseLine(string(line))
+			if err != nil {
 				errs = append(errs, err.Error())
+			} else if m != nil {
+				metrics = merger.add(metrics, m, composite)
 			}
 		}
 		if n < 0 {
@@ -84,20 +155,171 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
-// ParseLine performs Graphite parsing of a single line.
+// compositeKey identifies a series at a point in time
+type compositeKey struct {
+	id        uint64
+	timestamp int64
+}
+
+// compositeMerger merges the fields of metrics created by composite templates
+// into the first metric of the same series and timestamp
+type compositeMerger struct {
+	metrics map[compositeKey]telegraf.Metric
+}
+
+// add appends the metric to the given metrics unless it was created by a
+// composite template and a metric of the same series and timestamp exists
+func (c *compositeMerger) add(metrics []telegraf.Metric, m telegraf.Metric, composite bool) []telegraf.Metric {
+	if !composite {
+		return append(metrics, m)
+	}
+	key := compositeKey{id: m.HashID(), timestamp: m.Time().UnixNano()}
+	if existing, found := c.metrics[key]; found {
+		for _, field := range m.FieldList() {
+			existing.AddField(field.Key, field.Value)
+		}
+		return metrics
+	}
+	if c.metrics == nil {
+		c.metrics = make(map[compositeKey]telegraf.Metric)
+	}
+	c.metrics[key] = m
+	return append(metrics, m)
+}
+
+// parsePickle parses a message of the Carbon pickle protocol, i.e. a pickled
+// list of (path, (timestamp, value)) tuples. The message might still contain
+// the four byte length header. The data points are handled like lines of the
+// plaintext protocol.
+func (p *Parser) parsePickle(buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) >= 4 && int(binary.BigEndian.Uint32(buf)) == len(buf)-4 {
+		buf = buf[4:]
+	}
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	data, err := unpickle(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding pickle failed: %w", err)
+	}
+	points, ok := pickleSequence(data)
+	if !ok {
+		return nil, fmt.Errorf("unexpected pickle content of type %T", data)
+	}
+
+	var metrics []telegraf.Metric
+	var errs []string
+	var merger compositeMerger
+	for _, point := range points {
+		line, err := pickleLine(point)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		m, composite, err := p.parseLine(line)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if m != nil {
+			metrics = merger.add(metrics, m, composite)
+		}
+	}
+	if len(errs) != 0 {
+		return metrics, errors.New(strings.Join(errs, "\n"))
+	}
+	return metrics, nil
+}
+
+// pickleLine converts a pickled (path, (timestamp, value)) tuple to a line of
+// the plaintext protocol
+func pickleLine(point interface{}) (string, error) {
+	parts, ok := pickleSequence(point)
+	if !ok || len(parts) != 2 {
+		return "", fmt.Errorf("invalid data point %v", point)
+	}
+	path, ok := parts[0].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("invalid metric path %v", parts[0])
+	}
+	datapoint, ok := pickleSequence(parts[1])
+	if !ok || len(datapoint) != 2 {
+		return "", fmt.Errorf("invalid data point for %q", path)
+	}
+	timestamp, ok := pickleNumber(datapoint[0])
+	if !ok {
+		return "", fmt.Errorf("invalid timestamp %v for %q", datapoint[0], path)
+	}
+	value, ok := pickleNumber(datapoint[1])
+	if !ok {
+		return "", fmt.Errorf("invalid value %v for %q", datapoint[1], path)
+	}
+	return path + " " + value + " " + timestamp, nil
+}
+
+// pickleSequence returns the items of a pickled list or tuple
+func pickleSequence(v interface{}) ([]interface{}, bool) {
+	switch s := v.(type) {
+	case *pyList:
+		return s.items, true
+	case []interface{}:
+		return s, true
+	}
+	return nil, false
+}
+
+// pickleNumber formats a pickled number, numbers might also be sent as strings
+func pickleNumber(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case string:
+		return n, n != "" && !strings.ContainsAny(n, " \t\n")
+	}
+	return "", false
+}
+
+// ParseLine performs Graphite parsing of a single line. The returned metric
+// is nil if the line is dropped because it does not match any template or
+// has no timestamp. The fields of composite templates are not merged as only
+// a single line is parsed.
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	p.checkTemplatesFiles()
+	m, _, err := p.parseLine(line)
+	return m, err
+}
+
+func (p *Parser) parseLine(line string) (telegraf.Metric, bool, error) {
+	engine := p.templateEngine.Load()
+
 	// Break into 3 fields (name, value, timestamp).
 	fields := strings.Fields(line)
 	if len(fields) != 2 && len(fields) != 3 {
-		return nil, fmt.Errorf("received %q which doesn't have required fields", line)
+		return nil, false, fmt.Errorf("received %q which doesn't have required fields", line)
 	}
 
 	parts := strings.Split(fields[0], ";")
 
+	// Handle lines not matching any template, only check if required as the
+	// lines are handled by the default template otherwise
+	var unmatched bool
+	if p.OnUnmatched != "keep" || p.UnmatchedTag != "" {
+		unmatched = !engine.Match(parts[0])
+	}
+	if unmatched {
+		switch p.OnUnmatched {
+		case "drop":
+			return nil, false, nil
+		case "error":
+			return nil, false, fmt.Errorf("no template matching %q", parts[0])
+		}
+	}
+
 	// decode the name and tags
-	measurement, tags, field, err := p.templateEngine.Apply(parts[0])
+	measurement, tags, field, err := engine.Apply(parts[0])
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Could not extract measurement, use the raw value
@@ -108,7 +330,7 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	// Parse value.
 	v, err := strconv.ParseFloat(fields[1], 64)
 	if err != nil {
-		return nil, fmt.Errorf(`field %q value: %w`, fields[0], err)
+		return nil, false, fmt.Errorf(`field %q value: %w`, fields[0], err)
 	}
 
 	fieldValues := make(map[string]interface{}, 1)
@@ -118,42 +340,33 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 		fieldValues["value"] = v
 	}
 
-	// If no 3rd field, use now as timestamp
-	timestamp := time.Now().UTC()
+	timestamp, err := p.parseTimestamp(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	if timestamp.IsZero() {
+		// Drop metrics without timestamp
+		return nil, false, nil
+	}
 
-	if len(fields) == 3 {
-		// Parse timestamp.
-		unixTime, err := strconv.ParseFloat(fields[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf(`field %q time: %w`, fields[0], err)
+	// Add the tags of the Graphite 1.1 tag syntax, i.e. "name;tag1=value1"
+	for _, tag := range parts[1:] {
+		key, value, found := strings.Cut(tag, "=")
+		if !found || key == "" || value == "" {
+			continue
 		}
-
-		// -1 is a special value that gets converted to current UTC time
-		// See https://github.com/graphite-project/carbon/issues/54
-		if unixTime != float64(-1) {
-			// Check if we have fractional seconds
-			timestamp = time.Unix(int64(unixTime), int64((unixTime-math.Floor(unixTime))*float64(time.Second)))
-			if timestamp.Before(MinDate) || timestamp.After(MaxDate) {
-				return nil, errors.New("timestamp out of range")
-			}
+		if strings.ContainsAny(key, "!^") || strings.HasPrefix(value, "~") {
+			continue
+		}
+		if _, exists := tags[key]; exists && p.TagMode == "merge" {
+			continue
 		}
+		tags[key] = value
 	}
 
-	// Split name and tags
-	if len(parts) >= 2 {
-		for _, tag := range parts[1:] {
-			tagValue := strings.Split(tag, "=")
-			if len(tagValue) != 2 || len(tagValue[0]) == 0 || len(tagValue[1]) == 0 {
-				continue
-			}
-			if strings.ContainsAny(tagValue[0], "!^") {
-				continue
-			}
-			if strings.Index(tagValue[1], "~") == 0 {
-				continue
-			}
-			tags[tagValue[0]] = tagValue[1]
-		}
+	// Keep the full metric path of unmatched lines for later triage
+	if unmatched && p.UnmatchedTag != "" {
+		tags[p.UnmatchedTag] = parts[0]
 	}
 
 	// Set the default tags on the point if they are not already set
@@ -163,7 +376,57 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 		}
 	}
 
-	return metric.New(measurement, tags, fieldValues, timestamp), nil
+	return metric.New(measurement, tags, fieldValues, timestamp), engine.Composite(parts[0]), nil
+}
+
+// parseTimestamp returns the timestamp given as third field of the line or,
+// for lines without timestamp, the current time. A zero time is returned if
+// lines without timestamp should be dropped.
+func (p *Parser) parseTimestamp(fields []string) (time.Time, error) {
+	now := time.Now().UTC()
+
+	unixTime := float64(-1)
+	if len(fields) == 3 {
+		var err error
+		unixTime, err = strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(`field %q time: %w`, fields[0], err)
+		}
+		if math.IsNaN(unixTime) || math.IsInf(unixTime, 0) {
+			return time.Time{}, fmt.Errorf(`field %q time: invalid timestamp %q`, fields[0], fields[2])
+		}
+	}
+
+	// -1 is a special value that gets converted to current UTC time
+	// See https://github.com/graphite-project/carbon/issues/54
+	if unixTime == -1 {
+		if p.MissingTimestamp == "drop" {
+			return time.Time{}, nil
+		}
+		return now, nil
+	}
+
+	unit := time.Second
+	if p.TimestampUnit == "ms" {
+		unit = time.Millisecond
+	}
+
+	// Check the range before converting to avoid overflows
+	if unixTime < float64(MinDate.UnixNano()/int64(unit)) || unixTime > float64(MaxDate.UnixNano()/int64(unit)) {
+		return time.Time{}, errors.New("timestamp out of range")
+	}
+	// Check if we have fractional seconds
+	whole, frac := math.Modf(unixTime)
+	timestamp := time.Unix(0, int64(whole)*int64(unit)+int64(frac*float64(unit)))
+
+	if skew := time.Duration(p.MaxTimestampSkew); skew > 0 {
+		if d := timestamp.Sub(now); d > skew || d < -skew {
+			return time.Time{}, fmt.Errorf("timestamp %s of %q exceeds the maximum skew of %s",
+				timestamp.UTC().Format(time.RFC3339), fields[0], skew)
+		}
+	}
+
+	return timestamp, nil
 }
 
 // ApplyTemplate extracts the template fields from the given line and
@@ -177,7 +440,7 @@ func (p *Parser) ApplyTemplate(line string) (string, map[string]string, string,
 		return "", make(map[string]string), "", nil
 	}
 	// decode the name and tags
-	name, tags, field, err := p.templateEngine.Apply(fields[0])
+	name, tags, field, err := p.templateEngine.Load().Apply(fields[0])
 
 	// Set the default tags on the point if they are not already set
 	for k, v := range p.DefaultTags {
@@ -189,6 +452,17 @@ func (p *Parser) ApplyTemplate(line string) (string, map[string]string, string,
 	return name, tags, field, err
 }
 
+// MatchedTemplate returns the template applied to the metric path of the
+// given line or an empty string if the built-in default template applies.
+func (p *Parser) MatchedTemplate(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	path, _, _ := strings.Cut(fields[0], ";")
+	return p.templateEngine.Load().MatchedTemplate(path)
+}
+
 func (p *Parser) SetDefaultTags(tags map[string]string) {
 	p.DefaultTags = tags
 }
@@ -1,13 +1,18 @@
 package graphite
 
 import (
+	"encoding/hex"
 	"math"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
@@ -35,6 +40,26 @@ func BenchmarkParse(b *testing.B) {
 	}
 }
 
+func BenchmarkParseManyTemplates(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			templates := make([]string, 0, n)
+			for i := range n {
+				templates = append(templates, "servers.dc"+strconv.Itoa(i)+".* .dc.host.measurement*")
+			}
+			p := Parser{Templates: templates}
+			require.NoError(b, p.Init())
+
+			line := []byte("servers.dc" + strconv.Itoa(n/2) + ".localhost.cpu.load 11 1435077219")
+			b.ResetTimer()
+			for range b.N {
+				_, err := p.Parse(line)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
 func TestTemplateApply(t *testing.T) {
 	var tests = []struct {
 		test        string
@@ -263,12 +288,6 @@ func TestParseLine(t *testing.T) {
 			template: "measurement",
 			err:      `field "cpu" time: strconv.ParseFloat: parsing "14199724z57825": invalid syntax`,
 		},
-		{
-			test:     "measurement* and field* (invalid)",
-			input:    `prod.us-west.server01.cpu.util.idle.percent 99.99 1419972457825`,
-			template: "env.zone.host.measurement*.field*",
-			err:      `either 'field*' or 'measurement*' can be used in each template (but not both together): "env.zone.host.measurement*.field*"`,
-		},
 	}
 
 	for _, test := range tests {
@@ -375,12 +394,6 @@ func TestParse(t *testing.T) {
 			template: "measurement",
 			err:      `field "cpu" time: strconv.ParseFloat: parsing "14199724z57825": invalid syntax`,
 		},
-		{
-			test:     "measurement* and field* (invalid)",
-			input:    []byte(`prod.us-west.server01.cpu.util.idle.percent 99.99 1419972457825`),
-			template: "env.zone.host.measurement*.field*",
-			err:      `either 'field*' or 'measurement*' can be used in each template (but not both together): "env.zone.host.measurement*.field*"`,
-		},
 	}
 
 	for _, test := range tests {
@@ -694,6 +707,164 @@ func TestParseDefaultTemplateTagsOverridGlobal(t *testing.T) {
 	require.Equal(t, "1c", value)
 }
 
+func TestParseGraphiteTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		expected map[string]string
+	}{
+		{
+			name: "default",
+			expected: map[string]string{
+				"host": "web01",
+				"dc":   "eu",
+				"zone": "1c",
+				"expr": "a=b",
+			},
+		},
+		{
+			name: "override",
+			mode: "override",
+			expected: map[string]string{
+				"host": "web01",
+				"dc":   "eu",
+				"zone": "1c",
+				"expr": "a=b",
+			},
+		},
+		{
+			name: "merge",
+			mode: "merge",
+			expected: map[string]string{
+				"host": "localhost",
+				"dc":   "eu",
+				"zone": "1a",
+				"expr": "a=b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parser{
+				Templates: []string{"servers.* .host.measurement* zone=1a"},
+				TagMode:   tt.mode,
+			}
+			require.NoError(t, p.Init())
+
+			m, err := p.ParseLine("servers.localhost.cpu.usage;host=web01;dc=eu;zone=1c;expr=a=b 42 1622000000")
+			require.NoError(t, err)
+			require.Equal(t, "cpu.usage", m.Name())
+			require.Equal(t, tt.expected, m.Tags())
+			require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+			require.Equal(t, time.Unix(1622000000, 0), m.Time())
+		})
+	}
+}
+
+func TestParseGraphiteTagsWithoutTemplate(t *testing.T) {
+	p := Parser{}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("cpu.usage;host=web01;dc=eu 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, "cpu.usage", m.Name())
+	require.Equal(t, map[string]string{"host": "web01", "dc": "eu"}, m.Tags())
+}
+
+func TestInvalidTagMode(t *testing.T) {
+	p := Parser{TagMode: "foo"}
+	require.ErrorContains(t, p.Init(), `invalid 'graphite_tag_mode' "foo"`)
+}
+
+func TestParseUnmatched(t *testing.T) {
+	input := []byte("servers.localhost.cpu 42 1622000000\nnet.eth0.rx 23 1622000000\n")
+
+	tests := []struct {
+		name      string
+		mode      string
+		tag       string
+		templates []string
+		expected  []telegraf.Metric
+		errmsg    string
+	}{
+		{
+			name:      "default",
+			templates: []string{"servers.* .host.measurement*"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": float64(42)}, time.Unix(1622000000, 0)),
+				metric.New("net.eth0.rx", map[string]string{}, map[string]interface{}{"value": float64(23)}, time.Unix(1622000000, 0)),
+			},
+		},
+		{
+			name:      "keep with tag",
+			mode:      "keep",
+			tag:       "graphite_path",
+			templates: []string{"servers.* .host.measurement*"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": float64(42)}, time.Unix(1622000000, 0)),
+				metric.New(
+					"net.eth0.rx",
+					map[string]string{"graphite_path": "net.eth0.rx"},
+					map[string]interface{}{"value": float64(23)},
+					time.Unix(1622000000, 0),
+				),
+			},
+		},
+		{
+			name:      "drop",
+			mode:      "drop",
+			templates: []string{"servers.* .host.measurement*"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": float64(42)}, time.Unix(1622000000, 0)),
+			},
+		},
+		{
+			name:      "error",
+			mode:      "error",
+			templates: []string{"servers.* .host.measurement*"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": float64(42)}, time.Unix(1622000000, 0)),
+			},
+			errmsg: `no template matching "net.eth0.rx"`,
+		},
+		{
+			name:      "custom default template",
+			mode:      "drop",
+			tag:       "graphite_path",
+			templates: []string{"servers.* .host.measurement*", "measurement.interface.field"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": float64(42)}, time.Unix(1622000000, 0)),
+				metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"rx": float64(23)}, time.Unix(1622000000, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parser{
+				Templates:    tt.templates,
+				OnUnmatched:  tt.mode,
+				UnmatchedTag: tt.tag,
+			}
+			require.NoError(t, p.Init())
+
+			actual, err := p.Parse(input)
+			if tt.errmsg != "" {
+				require.ErrorContains(t, err, tt.errmsg)
+			} else {
+				require.NoError(t, err)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInvalidOnUnmatched(t *testing.T) {
+	p := Parser{OnUnmatched: "foo"}
+	require.ErrorContains(t, p.Init(), `invalid 'on_unmatched' "foo"`)
+}
+
 func TestParseTemplateWhitespace(t *testing.T) {
 	p := Parser{
 		Templates: []string{
@@ -789,6 +960,37 @@ func TestApplyTemplateTags(t *testing.T) {
 	require.Equal(t, "us-west", region)
 }
 
+func TestApplyTemplateTransforms(t *testing.T) {
+	p := Parser{
+		Separator: "_",
+		Templates: []string{"servers.* measurement.host|lowercase.region|replace(-,_).field"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("servers.WEB01.eu-west.load 42 1622000000")
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"servers",
+		map[string]string{"host": "web01", "region": "eu_west"},
+		map[string]interface{}{"load": float64(42)},
+		time.Unix(1622000000, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
+func TestValidateTemplateTransforms(t *testing.T) {
+	valid := Config{Templates: []string{
+		"measurement|lowercase.host",
+		"servers.* measurement|replace(-,_).host|uppercase.field*",
+		"apps.* .app.measurement*|lowercase",
+	}}
+	require.NoError(t, valid.Validate())
+
+	invalid := Config{Templates: []string{"measurement|foo.host"}}
+	require.ErrorContains(t, invalid.Validate(), `unknown transform "foo"`)
+}
+
 func TestApplyTemplateField(t *testing.T) {
 	p := Parser{
 		Separator: "_",
@@ -857,6 +1059,312 @@ func TestApplyTemplateGreedyField(t *testing.T) {
 	}
 }
 
+func TestApplyTemplateGreedyFieldSeparator(t *testing.T) {
+	p := Parser{
+		FieldSeparator: "_",
+		Templates:      []string{"app.* measurement.measurement.field*"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("app.requests.api.v2.login.count 42 1622000000")
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"app.requests",
+		map[string]string{},
+		map[string]interface{}{"api_v2_login_count": float64(42)},
+		time.Unix(1622000000, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
+func TestApplyTemplateSeparatorOverride(t *testing.T) {
+	p := Parser{
+		Separator: ".",
+		Templates: []string{
+			"cpu.* measurement.measurement.host @separator=_",
+			"mem.* measurement.measurement.field.field region=eu @separator=-",
+			"measurement.measurement.region.field*",
+		},
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_load",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"mem-cached",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{"total-bytes": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"disk.used",
+			map[string]string{"region": "us"},
+			map[string]interface{}{"root.bytes": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, line := range []string{
+		"cpu.load.server01 42 1622000000",
+		"mem.cached.total.bytes 42 1622000000",
+		"disk.used.us.root.bytes 42 1622000000",
+	} {
+		m, err := p.ParseLine(line)
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestApplyTemplateTagReferences(t *testing.T) {
+	p := Parser{
+		Templates: []string{
+			"servers.* .dc.host.measurement* region={{dc}}-zone,env=prod",
+		},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("servers.eu1.web01.cpu 42 1622000000")
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"cpu",
+		map[string]string{"dc": "eu1", "host": "web01", "region": "eu1-zone", "env": "prod"},
+		map[string]interface{}{"value": float64(42)},
+		time.Unix(1622000000, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
+func TestValidateSeparatorAttribute(t *testing.T) {
+	valid := Config{Templates: []string{
+		"cpu.* measurement.measurement.host @separator=_",
+		"mem.* measurement.field region=eu @separator=-",
+		"measurement* @separator=_",
+	}}
+	require.NoError(t, valid.Validate())
+
+	invalid := Config{Templates: []string{"cpu.* measurement.host @separator="}}
+	require.ErrorContains(t, invalid.Validate(), "empty separator in template")
+}
+
+func TestApplyTemplateNegatedFilterAndPriority(t *testing.T) {
+	p := Parser{
+		Templates: []string{
+			"servers.* .host.measurement*",
+			"servers.db.* .role.host.measurement* @priority=10",
+			"!servers.* measurement* source=external",
+		},
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"role": "db", "host": "db01"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"app.requests",
+			map[string]string{"source": "external"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, line := range []string{
+		"servers.web01.cpu 42 1622000000",
+		"servers.db.db01.cpu 42 1622000000",
+		"app.requests 42 1622000000",
+	} {
+		m, err := p.ParseLine(line)
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseCompositeTemplate(t *testing.T) {
+	p := Parser{
+		Templates: []string{
+			"app.*.requests.{count,errors,p95} measurement.host.measurement.field",
+			"app.* measurement.host.measurement.field",
+		},
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"app.requests",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"count": float64(100), "errors": float64(2), "p95": float64(0.25)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"app.requests",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"p99": float64(0.5)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"app.requests",
+			map[string]string{"host": "web02"},
+			map[string]interface{}{"count": float64(50), "errors": float64(1)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"app.requests",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"count": float64(110)},
+			time.Unix(1622000060, 0),
+		),
+	}
+
+	actual, err := p.Parse([]byte(`app.web01.requests.count 100 1622000000
+app.web01.requests.errors 2 1622000000
+app.web01.requests.p99 0.5 1622000000
+app.web02.requests.count 50 1622000000
+app.web01.requests.p95 0.25 1622000000
+app.web02.requests.errors 1 1622000000
+app.web01.requests.count 110 1622000060
+`))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestValidateFilterPriorities(t *testing.T) {
+	valid := Config{Templates: []string{
+		"servers.* .host.measurement*",
+		"servers.* .role.host.measurement* @priority=10",
+		"!servers.* measurement*",
+	}}
+	require.NoError(t, valid.Validate())
+
+	duplicate := Config{Templates: []string{
+		"servers.* .host.measurement* @priority=10",
+		"servers.* .role.host.measurement* @priority=10",
+	}}
+	require.ErrorContains(t, duplicate.Validate(), `duplicate filter "servers.*" found at position: 1`)
+
+	invalid := Config{Templates: []string{"servers.* .host.measurement* @priority=high"}}
+	require.ErrorContains(t, invalid.Validate(), "invalid priority in template")
+}
+
+func TestValidateAllowOverrides(t *testing.T) {
+	c := Config{
+		Templates: []string{
+			"servers.* .host.measurement*",
+			"servers.* .role.host.measurement*",
+		},
+		AllowOverrides: true,
+	}
+	require.NoError(t, c.Validate())
+}
+
+func TestAllowOverrides(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	p := Parser{
+		Templates: []string{
+			"servers.* .host.measurement*",
+			"servers.db.* .role.host.measurement* @priority=10",
+			"measurement*",
+			"servers.* .role.host.measurement*",
+			"servers.db.* .host.measurement* @priority=10",
+			"servers.db.* .role.host.measurement* @priority=5",
+		},
+		AllowOverrides: true,
+		Log:            logger,
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"role": "web", "host": "web01"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"db01.cpu",
+			map[string]string{"host": "db"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(1622000000, 0),
+		),
+	}
+
+	actual, err := p.Parse([]byte("servers.web.web01.cpu 42 1622000000\nservers.db.db01.cpu 42 1622000000\n"))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	var messages []string
+	for _, entry := range logger.Messages() {
+		messages = append(messages, entry.Text)
+	}
+	require.Equal(t, []string{
+		`Template "servers.* .host.measurement*" overridden by a later template`,
+		`Template "servers.db.* .role.host.measurement* @priority=10" overridden by a later template`,
+		"Effective templates (4):",
+		"  0: measurement*",
+		"  1: servers.* .role.host.measurement*",
+		"  2: servers.db.* .host.measurement* @priority=10",
+		"  3: servers.db.* .role.host.measurement* @priority=5",
+	}, messages)
+}
+
+func TestInvalidGreedyTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "measurement* and field*",
+			template: "env.zone.host.measurement*.field*",
+			expected: `either 'field*' or 'measurement*' can be used in each template (but not both together): "env.zone.host.measurement*.field*"`,
+		},
+		{
+			name:     "field* not last",
+			template: "measurement.field*.host",
+			expected: `"field*" must be the last part of the template: "measurement.field*.host"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parser{Templates: []string{tt.template}}
+			require.ErrorContains(t, p.Init(), tt.expected)
+
+			c := Config{Templates: []string{tt.template}}
+			require.ErrorContains(t, c.Validate(), tt.expected)
+		})
+	}
+}
+
+func TestMisplacedGreedyMeasurement(t *testing.T) {
+	// Deprecated but still accepted for compatibility with existing templates
+	template := "env.measurement*.field"
+	p := Parser{Templates: []string{template}}
+	require.NoError(t, p.Init())
+
+	c := Config{Templates: []string{template}}
+	require.NoError(t, c.Validate())
+}
+
 func TestApplyTemplateOverSpecific(t *testing.T) {
 	p := Parser{
 		Separator: ".",
@@ -891,3 +1399,360 @@ func TestApplyTemplateMostSpecificTemplate(t *testing.T) {
 	require.Equal(t, "net", measurement)
 	require.Equal(t, map[string]string{"host": "server001", "metric": "a.b"}, tags)
 }
+
+func TestTemplatesFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "servers.conf"), []byte(`
+		# Templates for the servers
+		servers.* .host.measurement*
+	`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("invalid"), 0600))
+
+	p := Parser{
+		Templates:      []string{"apps.* .app.measurement*"},
+		TemplatesFiles: []string{filepath.Join(dir, "*.conf")},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+	require.Equal(t, defaultTemplatesCheckInterval, p.TemplatesCheckInterval)
+
+	m, err := p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, "cpu", m.Name())
+	require.Equal(t, map[string]string{"host": "localhost"}, m.Tags())
+
+	m, err = p.ParseLine("apps.web.requests 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, "requests", m.Name())
+	require.Equal(t, map[string]string{"app": "web"}, m.Tags())
+}
+
+func TestTemplatesFilesInvalid(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "templates.conf")
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .host.host"), 0600))
+
+	p := Parser{
+		TemplatesFiles: []string{fn},
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "no measurement specified for template")
+}
+
+func TestTemplatesFilesReload(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "templates.conf")
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .host.measurement*"), 0600))
+
+	p := Parser{
+		TemplatesFiles: []string{fn},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	// Force checking the files on each parse
+	forceCheck := func() {
+		require.Eventually(t, func() bool { return !p.reloading.Load() }, time.Second, 10*time.Millisecond)
+		p.nextCheck.Store(0)
+	}
+
+	m, err := p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, "cpu", m.Name())
+
+	// Invalid templates must not replace the current ones
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .host.host"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now(), time.Now().Add(time.Minute)))
+	forceCheck()
+	_, err = p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	forceCheck()
+	m, err = p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, "cpu", m.Name())
+	require.Equal(t, map[string]string{"host": "localhost"}, m.Tags())
+
+	// Valid changes are picked up in the background
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .dc.measurement*"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now(), time.Now().Add(2*time.Minute)))
+	forceCheck()
+	_, err = p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		m, err := p.ParseLine("servers.localhost.cpu 42 1622000000")
+		return err == nil && m.Tags()["dc"] == "localhost"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTemplatesFilesReloadWithoutLogger(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "templates.conf")
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .host.measurement*"), 0600))
+
+	p := Parser{TemplatesFiles: []string{fn}}
+	require.NoError(t, p.Init())
+
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .host.host"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now(), time.Now().Add(time.Minute)))
+	p.nextCheck.Store(0)
+	p.checkTemplatesFiles()
+	require.Eventually(t, func() bool { return !p.reloading.Load() }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(fn, []byte("servers.* .dc.measurement*"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now(), time.Now().Add(2*time.Minute)))
+	require.NoError(t, p.reloadTemplates())
+	m, err := p.ParseLine("servers.localhost.cpu 42 1622000000")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"dc": "localhost"}, m.Tags())
+}
+
+func TestParsePickle(t *testing.T) {
+	// Pickles of [("cpu.web01.usage", (1622000000, 42.5)),
+	// ("mem.web01.free", (1622000000, 1024)),
+	// ("disk.web01.used;dc=eu", (1622000000.5, 7))] created by Python
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "protocol 0",
+			data: "286c70300a28566370752e77656230312e75736167650a70310a2849313632323030303030300a4634322e350a7470320a7470330a6128566d65" +
+				"6d2e77656230312e667265650a70340a2849313632323030303030300a49313032340a7470350a7470360a6128566469736b2e7765623031" +
+				"2e757365643b64633d65750a70370a2846313632323030303030302e350a49370a7470380a7470390a612e",
+		},
+		{
+			name: "protocol 2",
+			data: "80025d710028580f0000006370752e77656230312e757361676571014a80c1ad60474045400000000000867102867103580e0000006d656d2e" +
+				"77656230312e6672656571044a80c1ad604d000486710586710658150000006469736b2e77656230312e757365643b64633d657571074741d8" +
+				"2b70602000004b07867108867109652e",
+		},
+		{
+			name: "protocol 4",
+			data: "8004956d000000000000005d94288c0f6370752e77656230312e7573616765944a80c1ad60474045400000000000869486948c0e6d656d2e" +
+				"77656230312e66726565944a80c1ad604d0004869486948c156469736b2e77656230312e757365643b64633d6575944741d82b7060200000" +
+				"4b0786948694652e",
+		},
+		{
+			name: "with length header",
+			data: "0000008280025d710028580f0000006370752e77656230312e757361676571014a80c1ad60474045400000000000867102867103580e000000" +
+				"6d656d2e77656230312e6672656571044a80c1ad604d000486710586710658150000006469736b2e77656230312e757365643b64633d6575" +
+				"71074741d82b70602000004b07867108867109652e",
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"usage": float64(42.5)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"free": float64(1024)},
+			time.Unix(1622000000, 0),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "web01", "dc": "eu"},
+			map[string]interface{}{"used": float64(7)},
+			time.Unix(1622000000, 500000000),
+		),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parser{
+				Protocol:  "pickle",
+				Templates: []string{"measurement.host.field"},
+			}
+			require.NoError(t, p.Init())
+
+			buf, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			metrics, err := p.Parse(buf)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, metrics)
+		})
+	}
+}
+
+func TestParsePickleInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			// pickle of os.system using the GLOBAL opcode
+			name:     "unsafe opcodes",
+			data:     "63706f7369780a73797374656d0a70300a2e",
+			expected: "unsupported pickle opcode 0x63",
+		},
+		{
+			name:     "truncated",
+			data:     "80025d710028580f000000637075",
+			expected: "unexpected end of pickle",
+		},
+		{
+			name:     "no list",
+			data:     "80024b2a2e",
+			expected: "unexpected pickle content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parser{Protocol: "pickle"}
+			require.NoError(t, p.Init())
+
+			buf, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			_, err = p.Parse(buf)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParsePickleInvalidDataPoint(t *testing.T) {
+	// [("cpu.usage", (1622000000, None)), ("mem.free", (1622000000, 7))]
+	buf, err := hex.DecodeString(
+		"80025d71002858090000006370752e757361676571014a80c1ad604e86710286710358080000006d656d2e6672656571044a80c1ad604b07" +
+			"867105867106652e",
+	)
+	require.NoError(t, err)
+
+	p := Parser{Protocol: "pickle"}
+	require.NoError(t, p.Init())
+
+	// Valid data points must still be parsed
+	metrics, err := p.Parse(buf)
+	require.ErrorContains(t, err, `invalid value <nil> for "cpu.usage"`)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "mem.free", metrics[0].Name())
+}
+
+func TestInvalidProtocol(t *testing.T) {
+	p := Parser{Protocol: "udp"}
+	require.ErrorContains(t, p.Init(), "invalid 'graphite_protocol'")
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	sec := strconv.FormatInt(now.Unix(), 10)
+	msec := strconv.FormatInt(now.UnixMilli(), 10)
+
+	tests := []struct {
+		name     string
+		parser   *Parser
+		input    string
+		expected time.Time
+		dropped  bool
+		err      string
+	}{
+		{
+			name:     "seconds",
+			input:    "cpu.load 1 " + sec,
+			expected: now.Truncate(time.Second),
+		},
+		{
+			name:     "fractional seconds",
+			input:    "cpu.load 1 1700000000.25",
+			expected: time.Unix(1700000000, 250*int64(time.Millisecond)),
+		},
+		{
+			name:     "milliseconds",
+			parser:   &Parser{TimestampUnit: "ms"},
+			input:    "cpu.load 1 " + msec,
+			expected: now,
+		},
+		{
+			name:  "milliseconds as seconds",
+			input: "cpu.load 1 " + msec,
+			err:   "timestamp out of range",
+		},
+		{
+			name:  "NaN",
+			input: "cpu.load 1 NaN",
+			err:   `field "cpu.load" time: invalid timestamp "NaN"`,
+		},
+		{
+			name:  "infinite",
+			input: "cpu.load 1 -Inf",
+			err:   `field "cpu.load" time: invalid timestamp "-Inf"`,
+		},
+		{
+			name:    "missing dropped",
+			parser:  &Parser{MissingTimestamp: "drop"},
+			input:   "cpu.load 1",
+			dropped: true,
+		},
+		{
+			name:    "minus one dropped",
+			parser:  &Parser{MissingTimestamp: "drop"},
+			input:   "cpu.load 1 -1",
+			dropped: true,
+		},
+		{
+			name:     "within skew",
+			parser:   &Parser{MaxTimestampSkew: config.Duration(time.Hour)},
+			input:    "cpu.load 1 " + strconv.FormatInt(now.Add(-30*time.Minute).Unix(), 10),
+			expected: now.Add(-30 * time.Minute).Truncate(time.Second),
+		},
+		{
+			name:   "ancient",
+			parser: &Parser{MaxTimestampSkew: config.Duration(time.Hour)},
+			input:  "cpu.load 1 0",
+			err:    `timestamp 1970-01-01T00:00:00Z of "cpu.load" exceeds the maximum skew of 1h0m0s`,
+		},
+		{
+			name:   "future",
+			parser: &Parser{MaxTimestampSkew: config.Duration(time.Hour)},
+			input:  "cpu.load 1 " + strconv.FormatInt(now.Add(2*time.Hour).Unix(), 10),
+			err:    "exceeds the maximum skew of 1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.parser
+			if p == nil {
+				p = &Parser{}
+			}
+			require.NoError(t, p.Init())
+
+			m, err := p.ParseLine(tt.input)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.dropped {
+				require.Nil(t, m)
+				return
+			}
+			require.NotNil(t, m)
+			require.True(t, tt.expected.Equal(m.Time()), "expected %v but got %v", tt.expected, m.Time())
+		})
+	}
+}
+
+func TestParseMissingTimestampNow(t *testing.T) {
+	p := Parser{}
+	require.NoError(t, p.Init())
+
+	before := time.Now()
+	m, err := p.ParseLine("cpu.load 1")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.False(t, m.Time().Before(before.Truncate(time.Second)))
+}
+
+func TestInvalidTimestampOptions(t *testing.T) {
+	p := Parser{TimestampUnit: "us"}
+	require.ErrorContains(t, p.Init(), "invalid 'graphite_timestamp_unit'")
+
+	p = Parser{MissingTimestamp: "error"}
+	require.ErrorContains(t, p.Init(), "invalid 'graphite_missing_timestamp'")
+
+	p = Parser{MaxTimestampSkew: config.Duration(-time.Second)}
+	require.ErrorContains(t, p.Init(), "'graphite_max_timestamp_skew' must not be negative")
+}
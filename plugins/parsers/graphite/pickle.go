@@ -0,0 +1,460 @@
+package graphite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Pickle opcodes required to decode the data sent by Carbon relays, see
+// https://github.com/python/cpython/blob/main/Lib/pickletools.py.
+// Opcodes creating arbitrary Python objects like GLOBAL or REDUCE are
+// intentionally not supported as the data is received from the network.
+const (
+	opMark            = '('
+	opStop            = '.'
+	opPop             = '0'
+	opPopMark         = '1'
+	opDup             = '2'
+	opFloat           = 'F'
+	opInt             = 'I'
+	opBinInt          = 'J'
+	opBinInt1         = 'K'
+	opLong            = 'L'
+	opBinInt2         = 'M'
+	opNone            = 'N'
+	opString          = 'S'
+	opBinString       = 'T'
+	opShortBinString  = 'U'
+	opUnicode         = 'V'
+	opBinUnicode      = 'X'
+	opAppend          = 'a'
+	opAppends         = 'e'
+	opGet             = 'g'
+	opBinGet          = 'h'
+	opLongBinGet      = 'j'
+	opList            = 'l'
+	opPut             = 'p'
+	opBinPut          = 'q'
+	opLongBinPut      = 'r'
+	opTuple           = 't'
+	opEmptyList       = ']'
+	opEmptyTuple      = ')'
+	opBinFloat        = 'G'
+	opBinBytes        = 'B'
+	opShortBinBytes   = 'C'
+	opProto           = 0x80
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opShortBinUnicode = 0x8c
+	opMemoize         = 0x94
+	opFrame           = 0x95
+)
+
+// mark is pushed to the stack by the MARK opcode
+type mark struct{}
+
+// pyList is a Python list, referenced to allow appending to memoized lists.
+// Tuples are decoded as slices.
+type pyList struct {
+	items []interface{}
+}
+
+// unpickler decodes the basic data types of the pickle format
+type unpickler struct {
+	buf   []byte
+	pos   int
+	stack []interface{}
+	memo  map[int]interface{}
+}
+
+// unpickle decodes the given pickle data
+func unpickle(buf []byte) (interface{}, error) {
+	u := &unpickler{buf: buf, memo: make(map[int]interface{})}
+	return u.load()
+}
+
+func (u *unpickler) load() (interface{}, error) {
+	for {
+		op, err := u.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case opStop:
+			if len(u.stack) != 1 {
+				return nil, errors.New("invalid stack at end of pickle")
+			}
+			return u.stack[0], nil
+		case opProto:
+			if _, err := u.read(1); err != nil {
+				return nil, err
+			}
+		case opFrame:
+			if _, err := u.read(8); err != nil {
+				return nil, err
+			}
+		case opMark:
+			u.push(mark{})
+		case opPop:
+			if _, err := u.pop(); err != nil {
+				return nil, err
+			}
+		case opPopMark:
+			if _, err := u.popMark(); err != nil {
+				return nil, err
+			}
+		case opDup:
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case opNone:
+			u.push(nil)
+		case opNewTrue:
+			u.push(true)
+		case opNewFalse:
+			u.push(false)
+		case opInt:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			// Protocol 0 encodes booleans as "I01" and "I00"
+			switch line {
+			case "01":
+				u.push(true)
+			case "00":
+				u.push(false)
+			default:
+				v, err := strconv.ParseInt(line, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid integer: %w", err)
+				}
+				u.push(v)
+			}
+		case opLong:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseInt(strings.TrimSuffix(line, "L"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid long: %w", err)
+			}
+			u.push(v)
+		case opBinInt:
+			b, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(int32(binary.LittleEndian.Uint32(b))))
+		case opBinInt1:
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(b[0]))
+		case opBinInt2:
+			b, err := u.read(2)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(binary.LittleEndian.Uint16(b)))
+		case opLong1:
+			n, err := u.readByte()
+			if err != nil {
+				return nil, err
+			}
+			b, err := u.read(int(n))
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeLong(b)
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case opFloat:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseFloat(line, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid float: %w", err)
+			}
+			u.push(v)
+		case opBinFloat:
+			b, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		case opString:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.Unquote(line)
+			if err != nil {
+				// Python uses single quotes for strings
+				if len(line) < 2 || line[0] != '\'' || line[len(line)-1] != '\'' {
+					return nil, fmt.Errorf("invalid string %q", line)
+				}
+				v = line[1 : len(line)-1]
+			}
+			u.push(v)
+		case opUnicode:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			u.push(line)
+		case opShortBinString, opShortBinBytes, opShortBinUnicode:
+			n, err := u.readByte()
+			if err != nil {
+				return nil, err
+			}
+			b, err := u.read(int(n))
+			if err != nil {
+				return nil, err
+			}
+			u.push(string(b))
+		case opBinString, opBinBytes, opBinUnicode:
+			b, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			b, err = u.read(int(binary.LittleEndian.Uint32(b)))
+			if err != nil {
+				return nil, err
+			}
+			u.push(string(b))
+		case opEmptyList:
+			u.push(&pyList{})
+		case opList:
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pyList{items: items})
+		case opAppend:
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.appendToList(v); err != nil {
+				return nil, err
+			}
+		case opAppends:
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.appendToList(items...); err != nil {
+				return nil, err
+			}
+		case opEmptyTuple:
+			u.push(make([]interface{}, 0))
+		case opTuple:
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(items)
+		case opTuple1, opTuple2, opTuple3:
+			n := int(op-opTuple1) + 1
+			if len(u.stack) < n {
+				return nil, errors.New("stack underflow")
+			}
+			items := make([]interface{}, n)
+			copy(items, u.stack[len(u.stack)-n:])
+			u.stack = u.stack[:len(u.stack)-n]
+			u.push(items)
+		case opPut:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid memo index: %w", err)
+			}
+			if err := u.memoize(idx); err != nil {
+				return nil, err
+			}
+		case opBinPut:
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := u.memoize(int(b[0])); err != nil {
+				return nil, err
+			}
+		case opLongBinPut:
+			b, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			if err := u.memoize(int(binary.LittleEndian.Uint32(b))); err != nil {
+				return nil, err
+			}
+		case opMemoize:
+			if err := u.memoize(len(u.memo)); err != nil {
+				return nil, err
+			}
+		case opGet:
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid memo index: %w", err)
+			}
+			if err := u.recall(idx); err != nil {
+				return nil, err
+			}
+		case opBinGet:
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := u.recall(int(b[0])); err != nil {
+				return nil, err
+			}
+		case opLongBinGet:
+			b, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			if err := u.recall(int(binary.LittleEndian.Uint32(b))); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported pickle opcode 0x%02x at position %d", op, u.pos-1)
+		}
+	}
+}
+
+func (u *unpickler) readByte() (byte, error) {
+	if u.pos >= len(u.buf) {
+		return 0, errors.New("unexpected end of pickle")
+	}
+	b := u.buf[u.pos]
+	u.pos++
+	return b, nil
+}
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || u.pos+n > len(u.buf) {
+		return nil, errors.New("unexpected end of pickle")
+	}
+	b := u.buf[u.pos : u.pos+n]
+	u.pos += n
+	return b, nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	for i := u.pos; i < len(u.buf); i++ {
+		if u.buf[i] == '\n' {
+			line := string(u.buf[u.pos:i])
+			u.pos = i + 1
+			return line, nil
+		}
+	}
+	return "", errors.New("unexpected end of pickle")
+}
+
+func (u *unpickler) push(v interface{}) {
+	u.stack = append(u.stack, v)
+}
+
+func (u *unpickler) top() (interface{}, error) {
+	if len(u.stack) == 0 {
+		return nil, errors.New("stack underflow")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+func (u *unpickler) pop() (interface{}, error) {
+	v, err := u.top()
+	if err != nil {
+		return nil, err
+	}
+	u.stack = u.stack[:len(u.stack)-1]
+	return v, nil
+}
+
+// popMark removes and returns the items up to the topmost mark
+func (u *unpickler) popMark() ([]interface{}, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(mark); ok {
+			items := make([]interface{}, len(u.stack)-i-1)
+			copy(items, u.stack[i+1:])
+			u.stack = u.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, errors.New("mark not found")
+}
+
+func (u *unpickler) appendToList(items ...interface{}) error {
+	if len(u.stack) == 0 {
+		return errors.New("stack underflow")
+	}
+	list, ok := u.stack[len(u.stack)-1].(*pyList)
+	if !ok {
+		return fmt.Errorf("cannot append to %T", u.stack[len(u.stack)-1])
+	}
+	list.items = append(list.items, items...)
+	return nil
+}
+
+func (u *unpickler) memoize(idx int) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	u.memo[idx] = v
+	return nil
+}
+
+func (u *unpickler) recall(idx int) error {
+	v, found := u.memo[idx]
+	if !found {
+		return fmt.Errorf("memo index %d not found", idx)
+	}
+	u.push(v)
+	return nil
+}
+
+// decodeLong decodes the two's complement little-endian integer of LONG1
+func decodeLong(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	if !v.IsInt64() {
+		return 0, errors.New("long exceeds 64 bit")
+	}
+	return v.Int64(), nil
+}
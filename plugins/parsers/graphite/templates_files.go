@@ -0,0 +1,178 @@
+package graphite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/templating"
+)
+
+// templatesState identifies the state of the templates files to detect
+// changes, i.e. the matched files together with their size and modification
+// time
+type templatesState string
+
+// newEngine creates a template engine for the configured templates and the
+// templates contained in the given files
+func (p *Parser) newEngine(files []string) (*templating.Engine, error) {
+	templates := p.Templates
+	for _, fn := range files {
+		t, err := readTemplatesFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates[:len(templates):len(templates)], t...)
+	}
+	if p.AllowOverrides {
+		templates = p.overrideTemplates(templates)
+	}
+
+	defaultTemplate, err := templating.NewDefaultTemplateWithPattern("measurement*")
+	if err != nil {
+		return nil, fmt.Errorf("creating template failed: %w", err)
+	}
+
+	engine, err := templating.NewEngine(p.Separator, defaultTemplate, templates)
+	if err != nil {
+		return nil, fmt.Errorf("creating template engine failed: %w ", err)
+	}
+	engine.SetFieldJoiner(p.FieldSeparator)
+
+	return engine, nil
+}
+
+// overrideTemplates removes the templates replaced by later templates with
+// the same filter and logs the resulting template table
+func (p *Parser) overrideTemplates(templates []string) []string {
+	effective, overridden := applyOverrides(templates)
+	if p.Log == nil {
+		return effective
+	}
+	for _, template := range overridden {
+		p.Log.Debugf("Template %q overridden by a later template", template)
+	}
+	p.Log.Debugf("Effective templates (%d):", len(effective))
+	for i, template := range effective {
+		p.Log.Debugf("  %d: %s", i, template)
+	}
+	return effective
+}
+
+// readTemplatesFile reads the templates from the given file with one template
+// per line. Empty lines and comments starting with '#' are ignored.
+func readTemplatesFile(fn string) ([]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("opening templates file failed: %w", err)
+	}
+	defer f.Close()
+
+	var templates []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		templates = append(templates, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading templates file %q failed: %w", fn, err)
+	}
+	return templates, nil
+}
+
+// templatesFiles returns the files matching the configured patterns in a
+// stable order together with their current state
+func (p *Parser) templatesFiles() ([]string, templatesState, error) {
+	var files []string
+	for _, pattern := range p.TemplatesFiles {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	var state strings.Builder
+	for _, fn := range files {
+		info, err := os.Stat(fn)
+		if err != nil {
+			return nil, "", err
+		}
+		fmt.Fprintf(&state, "%s:%d:%d\n", fn, info.Size(), info.ModTime().UnixNano())
+	}
+	return files, templatesState(state.String()), nil
+}
+
+// checkTemplatesFiles reloads the templates in the background if the
+// templates files changed since the last check. The current engine is kept
+// until the new templates were loaded successfully, so invalid templates do
+// not interrupt the parsing.
+func (p *Parser) checkTemplatesFiles() {
+	if len(p.TemplatesFiles) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.UnixNano() < p.nextCheck.Load() {
+		return
+	}
+	if !p.reloading.CompareAndSwap(false, true) {
+		return
+	}
+	p.nextCheck.Store(now.Add(time.Duration(p.TemplatesCheckInterval)).UnixNano())
+
+	go func() {
+		defer p.reloading.Store(false)
+		if err := p.reloadTemplates(); err != nil && p.Log != nil {
+			p.Log.Errorf("Reloading templates failed, keeping previous templates: %v", err)
+		}
+	}()
+}
+
+func (p *Parser) reloadTemplates() error {
+	files, state, err := p.templatesFiles()
+	if err != nil {
+		return err
+	}
+	if state == p.templatesState {
+		return nil
+	}
+
+	engine, err := p.newEngine(files)
+	if err != nil {
+		return err
+	}
+	p.templateEngine.Store(engine)
+	p.templatesState = state
+	if p.Log != nil {
+		p.Log.Debugf("Reloaded templates from %d files", len(files))
+	}
+
+	return nil
+}
+
+func (p *Parser) initTemplatesFiles() ([]string, error) {
+	if p.TemplatesCheckInterval < 0 {
+		return nil, errors.New("'templates_check_interval' must not be negative")
+	}
+	if p.TemplatesCheckInterval == 0 {
+		p.TemplatesCheckInterval = defaultTemplatesCheckInterval
+	}
+
+	files, state, err := p.templatesFiles()
+	if err != nil {
+		return nil, err
+	}
+	p.templatesState = state
+	p.nextCheck.Store(time.Now().Add(time.Duration(p.TemplatesCheckInterval)).UnixNano())
+	return files, nil
+}
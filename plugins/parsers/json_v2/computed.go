@@ -0,0 +1,107 @@
+package json_v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PaesslerAG/gval"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// ComputedField defines a field computed from an expression over the tags and
+// fields extracted from the JSON input
+type ComputedField struct {
+	Name       string `toml:"name"`       // REQUIRED
+	Expression string `toml:"expression"` // REQUIRED
+	Type       string `toml:"type"`       // OPTIONAL, can be "int", "uint", "float", "string", "bool"
+	Optional   bool   `toml:"optional"`   // Will suppress errors if the expression cannot be evaluated
+
+	evaluable gval.Evaluable
+}
+
+func (c *ComputedField) init() error {
+	if c.Name == "" {
+		return fmt.Errorf("missing name for computed field with expression %q", c.Expression)
+	}
+	if c.Expression == "" {
+		return fmt.Errorf("missing expression for computed field %q", c.Name)
+	}
+	switch c.Type {
+	case "", "int", "uint", "float", "string", "bool":
+	default:
+		return fmt.Errorf("invalid type %q for computed field %q", c.Type, c.Name)
+	}
+
+	evaluable, err := gval.Full().NewEvaluable(c.Expression)
+	if err != nil {
+		return fmt.Errorf("parsing expression of computed field %q failed: %w", c.Name, err)
+	}
+	c.evaluable = evaluable
+
+	return nil
+}
+
+// addComputedFields evaluates the computed fields in the configured order and
+// adds the results to the given metrics. Tags and fields of the metric are
+// available as variables in the expressions, including previously computed
+// fields.
+func addComputedFields(metrics []telegraf.Metric, computed []ComputedField) error {
+	if len(computed) == 0 {
+		return nil
+	}
+
+	for _, m := range metrics {
+		params := make(map[string]interface{}, len(m.TagList())+len(m.FieldList())+len(computed))
+		for _, tag := range m.TagList() {
+			params[tag.Key] = tag.Value
+		}
+		for _, field := range m.FieldList() {
+			params[field.Key] = field.Value
+		}
+
+		for _, c := range computed {
+			result, err := c.evaluable(context.Background(), params)
+			if err == nil {
+				result, err = convertComputed(result, c.Type)
+			}
+			if err != nil {
+				if c.Optional {
+					continue
+				}
+				return fmt.Errorf("evaluating computed field %q failed: %w", c.Name, err)
+			}
+			m.AddField(c.Name, result)
+			params[c.Name] = result
+		}
+	}
+
+	return nil
+}
+
+func convertComputed(value interface{}, desiredType string) (interface{}, error) {
+	switch desiredType {
+	case "int":
+		return internal.ToInt64(value)
+	case "uint":
+		return internal.ToUint64(value)
+	case "float":
+		return internal.ToFloat64(value)
+	case "string":
+		return internal.ToString(value)
+	case "bool":
+		return internal.ToBool(value)
+	}
+
+	// Use the type of the result, restricted to the types supported by metrics
+	switch v := value.(type) {
+	case float64, string, bool:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case nil:
+		return nil, fmt.Errorf("expression evaluated to nil")
+	}
+	return nil, fmt.Errorf("unsupported result type %T", value)
+}
@@ -52,6 +52,8 @@ type Config struct {
 	Tags        []DataSet `toml:"tag"`
 	JSONObjects []Object  `toml:"object"`
 
+	ComputedFields []ComputedField `toml:"computed_field"`
+
 	Location *time.Location
 }
 
@@ -116,6 +118,11 @@ func (p *Parser) Init() error {
 			}
 			p.Configs[i].Location = loc
 		}
+		for j := range cfg.ComputedFields {
+			if err := p.Configs[i].ComputedFields[j].init(); err != nil {
+				return fmt.Errorf("invalid computed field in config %d: %w", i+1, err)
+			}
+		}
 	}
 	return nil
 }
@@ -210,6 +217,10 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 			cmetrics = append(cmetrics, objects...)
 		}
 
+		if err := addComputedFields(cmetrics, c.ComputedFields); err != nil {
+			return nil, err
+		}
+
 		metrics = append(metrics, cmetrics...)
 	}
 
@@ -105,6 +105,59 @@ func TestParserEmptyConfig(t *testing.T) {
 	require.ErrorContains(t, plugin.Init(), "no configuration provided")
 }
 
+func TestParserInvalidComputedField(t *testing.T) {
+	tests := []struct {
+		name     string
+		computed json_v2.ComputedField
+		expected string
+	}{
+		{
+			name:     "missing name",
+			computed: json_v2.ComputedField{Expression: "a + b"},
+			expected: "missing name for computed field",
+		},
+		{
+			name:     "missing expression",
+			computed: json_v2.ComputedField{Name: "sum"},
+			expected: `missing expression for computed field "sum"`,
+		},
+		{
+			name:     "invalid type",
+			computed: json_v2.ComputedField{Name: "sum", Expression: "a + b", Type: "complex"},
+			expected: `invalid type "complex" for computed field "sum"`,
+		},
+		{
+			name:     "invalid expression",
+			computed: json_v2.ComputedField{Name: "sum", Expression: "a + * b"},
+			expected: `parsing expression of computed field "sum" failed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &json_v2.Parser{
+				Configs: []json_v2.Config{{ComputedFields: []json_v2.ComputedField{tt.computed}}},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParserComputedFieldError(t *testing.T) {
+	plugin := &json_v2.Parser{
+		Configs: []json_v2.Config{
+			{
+				MeasurementName: "test",
+				Fields:          []json_v2.DataSet{{Path: "a"}},
+				ComputedFields:  []json_v2.ComputedField{{Name: "sum", Expression: "a + b"}},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.Parse([]byte(`{"a": 1}`))
+	require.ErrorContains(t, err, `evaluating computed field "sum" failed`)
+}
+
 func BenchmarkParsingSequential(b *testing.B) {
 	inputFilename := filepath.Join("testdata", "benchmark", "input.json")
 
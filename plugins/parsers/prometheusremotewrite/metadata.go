@@ -0,0 +1,62 @@
+package prometheusremotewrite
+
+import (
+	"strings"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/influxdata/telegraf"
+)
+
+// metricTypes maps the metric family names of the write request's metadata
+// to their Prometheus type
+type metricTypes map[string]prompb.MetricMetadata_MetricType
+
+func newMetricTypes(metadata []prompb.MetricMetadata) metricTypes {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	types := make(metricTypes, len(metadata))
+	for _, m := range metadata {
+		types[m.MetricFamilyName] = m.Type
+	}
+	return types
+}
+
+// valueType returns the value type of the series with the given name. As
+// remote-write splits histograms and summaries into individual series, their
+// sum, count and bucket series are treated as counters.
+func (types metricTypes) valueType(name string) telegraf.ValueType {
+	if types == nil {
+		return telegraf.Untyped
+	}
+
+	if t, found := types[name]; found {
+		switch t {
+		case prompb.MetricMetadata_COUNTER:
+			return telegraf.Counter
+		case prompb.MetricMetadata_GAUGE:
+			return telegraf.Gauge
+		}
+		return telegraf.Untyped
+	}
+
+	// Counter families might be named without the "_total" suffix
+	if family, found := strings.CutSuffix(name, "_total"); found && types[family] == prompb.MetricMetadata_COUNTER {
+		return telegraf.Counter
+	}
+
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		family, found := strings.CutSuffix(name, suffix)
+		if !found {
+			continue
+		}
+		switch types[family] {
+		case prompb.MetricMetadata_HISTOGRAM, prompb.MetricMetadata_GAUGEHISTOGRAM, prompb.MetricMetadata_SUMMARY:
+			return telegraf.Counter
+		}
+	}
+
+	return telegraf.Untyped
+}
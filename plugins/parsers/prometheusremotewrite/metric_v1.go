@@ -12,7 +12,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 )
 
-func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, error) {
+func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries, types metricTypes) ([]telegraf.Metric, error) {
 	t := time.Now()
 
 	// Convert each prometheus metrics to the corresponding telegraf metrics.
@@ -40,6 +40,7 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		return nil, fmt.Errorf("metric name %q not found in tag-set or empty", model.MetricNameLabel)
 	}
 	delete(tags, model.MetricNameLabel)
+	vt := types.valueType(metricName)
 
 	for _, s := range ts.Samples {
 		if math.IsNaN(s.Value) {
@@ -51,7 +52,7 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		if s.Timestamp > 0 {
 			t = time.Unix(0, s.Timestamp*1000000)
 		}
-		m := metric.New(metricName, tags, fields, t)
+		m := metric.New(metricName, tags, fields, t, vt)
 		metrics = append(metrics, m)
 	}
 
@@ -24,13 +24,16 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		return nil, fmt.Errorf("unable to unmarshal request body: %w", err)
 	}
 
+	// Use the metadata sent along with the samples to determine the metric types
+	types := newMetricTypes(req.Metadata)
+
 	for _, ts := range req.Timeseries {
 		var metricsFromTS []telegraf.Metric
 		switch p.MetricVersion {
 		case 0, 2:
-			metricsFromTS, err = p.extractMetricsV2(&ts)
+			metricsFromTS, err = p.extractMetricsV2(&ts, types)
 		case 1:
-			metricsFromTS, err = p.extractMetricsV1(&ts)
+			metricsFromTS, err = p.extractMetricsV1(&ts, types)
 		default:
 			return nil, fmt.Errorf("unknown prometheus metric version %d", p.MetricVersion)
 		}
@@ -216,6 +216,60 @@ func generateTestFloatHistogram(i int) *histogram.FloatHistogram {
 	}
 }
 
+func TestMetadata(t *testing.T) {
+	ts := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	series := func(name string) prompb.TimeSeries {
+		return prompb.TimeSeries{
+			Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: ts}},
+		}
+	}
+
+	prompbInput := prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("http_requests_total"),
+			series("memory_bytes"),
+			series("request_duration_seconds_bucket"),
+			series("request_duration_seconds_sum"),
+			series("unknown"),
+		},
+		Metadata: []prompb.MetricMetadata{
+			{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "http_requests"},
+			{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "memory_bytes"},
+			{Type: prompb.MetricMetadata_HISTOGRAM, MetricFamilyName: "request_duration_seconds"},
+		},
+	}
+	inputBytes, err := prompbInput.Marshal()
+	require.NoError(t, err)
+
+	expected := map[string]telegraf.ValueType{
+		"http_requests_total":             telegraf.Counter,
+		"memory_bytes":                    telegraf.Gauge,
+		"request_duration_seconds_bucket": telegraf.Counter,
+		"request_duration_seconds_sum":    telegraf.Counter,
+		"unknown":                         telegraf.Untyped,
+	}
+
+	for _, version := range []int{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			parser := Parser{MetricVersion: version}
+			metrics, err := parser.Parse(inputBytes)
+			require.NoError(t, err)
+			require.Len(t, metrics, len(expected))
+
+			actual := make(map[string]telegraf.ValueType, len(metrics))
+			for _, m := range metrics {
+				name := m.Name()
+				if version == 2 {
+					name = m.FieldList()[0].Key
+				}
+				actual[name] = m.Type()
+			}
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
 func TestHistograms(t *testing.T) {
 	prompbInput := prompb.WriteRequest{
 		Timeseries: []prompb.TimeSeries{
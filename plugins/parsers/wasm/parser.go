@@ -0,0 +1,93 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common "github.com/influxdata/telegraf/plugins/common/wasm"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+var ErrNoMetric = errors.New("no metric in line")
+
+type Parser struct {
+	Module      string            `toml:"wasm_module"`
+	Settings    map[string]string `toml:"wasm_settings"`
+	Timeout     config.Duration   `toml:"wasm_timeout"`
+	MemoryLimit config.Size       `toml:"wasm_memory_limit"`
+	DefaultTags map[string]string `toml:"-"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	module *common.Module
+	parser *influx.Parser
+}
+
+func (p *Parser) Init() error {
+	p.parser = &influx.Parser{DefaultTags: p.DefaultTags}
+	if err := p.parser.Init(); err != nil {
+		return fmt.Errorf("initializing parser failed: %w", err)
+	}
+
+	cfg := &common.Config{
+		Module:      p.Module,
+		Settings:    p.Settings,
+		Timeout:     p.Timeout,
+		MemoryLimit: p.MemoryLimit,
+	}
+	module, err := cfg.Load(context.Background(), p.Log, "parse")
+	if err != nil {
+		return fmt.Errorf("loading module %q failed: %w", p.Module, err)
+	}
+	p.module = module
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	output, err := p.module.Call(context.Background(), "parse", buf)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := p.parser.Parse(output)
+	if err != nil {
+		return nil, fmt.Errorf("parsing output of module failed: %w", err)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+	if p.parser != nil {
+		p.parser.SetDefaultTags(tags)
+	}
+}
+
+func init() {
+	parsers.Add("wasm",
+		func(string) telegraf.Parser {
+			return &Parser{
+				Timeout:     config.Duration(time.Second),
+				MemoryLimit: config.Size(16 * 1024 * 1024),
+			}
+		},
+	)
+}
@@ -0,0 +1,91 @@
+package wasm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   *Parser
+		expected string
+	}{
+		{
+			name:     "missing module",
+			parser:   &Parser{Timeout: config.Duration(time.Second)},
+			expected: "no module configured",
+		},
+		{
+			name:     "missing timeout",
+			parser:   &Parser{Module: "testdata/length.wasm"},
+			expected: "timeout must be positive",
+		},
+		{
+			name:     "missing function",
+			parser:   &Parser{Module: "../../processors/wasm/testdata/tag.wasm", Timeout: config.Duration(time.Second)},
+			expected: `module does not export function "parse"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.parser.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.parser.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	parser := &Parser{
+		Module:      "testdata/length.wasm",
+		Timeout:     config.Duration(time.Second),
+		MemoryLimit: config.Size(1024 * 1024),
+		DefaultTags: map[string]string{"source": "test"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New("example", map[string]string{"source": "test"}, map[string]interface{}{"length": int64(12)}, time.Unix(0, 0)),
+	}
+	actual, err := parser.Parse([]byte("Hello World!"))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+
+	// Inputs exceeding the initial memory of the module
+	expected = []telegraf.Metric{
+		metric.New("example", map[string]string{"source": "test"}, map[string]interface{}{"length": int64(100000)}, time.Unix(0, 0)),
+	}
+	actual, err = parser.Parse(make([]byte, 100000))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+
+	m, err := parser.ParseLine("")
+	require.NoError(t, err)
+	testutil.RequireMetricEqual(t, metric.New("example", map[string]string{"source": "test"}, map[string]interface{}{"length": int64(0)}, time.Unix(0, 0)), m, testutil.IgnoreTime())
+}
+
+func TestParseMemoryLimit(t *testing.T) {
+	parser := &Parser{
+		Module:      "testdata/length.wasm",
+		Timeout:     config.Duration(time.Second),
+		MemoryLimit: config.Size(64 * 1024),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse(make([]byte, 100000))
+	require.ErrorContains(t, err, "out of range")
+
+	// The module must still work for inputs fitting into memory
+	actual, err := parser.Parse([]byte("test"))
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+}
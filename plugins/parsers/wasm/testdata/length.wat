@@ -0,0 +1,116 @@
+;; Parser returning the length of the input data as "example length=<n>i"
+(module
+  (memory (export "memory") 1)
+  (data (i32.const 16) "example length=")
+  (data (i32.const 32) "i\n")
+
+  ;; The input is always placed at offset 1024 and the output directly after
+  ;; the input
+  (func (export "alloc") (param $size i32) (result i32)
+    (local $required i32)
+    local.get $size
+    i32.const 1088
+    i32.add
+    local.set $required
+    block $done
+      loop $grow
+        memory.size
+        i32.const 16
+        i32.shl
+        local.get $required
+        i32.ge_u
+        br_if $done
+        i32.const 1
+        memory.grow
+        i32.const -1
+        i32.eq
+        br_if $done
+        br $grow
+      end
+    end
+    i32.const 1024)
+
+  (func (export "parse") (param $ptr i32) (param $len i32) (result i64)
+    (local $out i32)
+    (local $o i32)
+    (local $n i32)
+    (local $t i32)
+    local.get $ptr
+    local.get $len
+    i32.add
+    local.tee $out
+    i32.const 16
+    i32.const 15
+    memory.copy
+    local.get $out
+    i32.const 15
+    i32.add
+    local.set $o
+
+    ;; Write the digits in reverse order to a scratch area
+    local.get $len
+    local.set $n
+    i32.const 512
+    local.set $t
+    loop $digits
+      local.get $t
+      local.get $n
+      i32.const 10
+      i32.rem_u
+      i32.const 48
+      i32.add
+      i32.store8
+      local.get $t
+      i32.const 1
+      i32.add
+      local.set $t
+      local.get $n
+      i32.const 10
+      i32.div_u
+      local.tee $n
+      br_if $digits
+    end
+
+    ;; Copy the digits in the correct order to the output
+    block $done
+      loop $copy
+        local.get $t
+        i32.const 512
+        i32.le_u
+        br_if $done
+        local.get $t
+        i32.const 1
+        i32.sub
+        local.set $t
+        local.get $o
+        local.get $t
+        i32.load8_u
+        i32.store8
+        local.get $o
+        i32.const 1
+        i32.add
+        local.set $o
+        br $copy
+      end
+    end
+
+    local.get $o
+    i32.const 32
+    i32.const 2
+    memory.copy
+    local.get $o
+    i32.const 2
+    i32.add
+    local.set $o
+
+    ;; Return the pointer in the upper and the length in the lower 32 bits
+    local.get $out
+    i64.extend_i32_u
+    i64.const 32
+    i64.shl
+    local.get $o
+    local.get $out
+    i32.sub
+    i64.extend_i32_u
+    i64.or)
+)
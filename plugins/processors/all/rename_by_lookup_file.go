@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.rename_by_lookup_file
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/rename_by_lookup_file" // register plugin
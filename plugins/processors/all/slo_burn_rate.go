@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.slo_burn_rate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/slo_burn_rate" // register plugin
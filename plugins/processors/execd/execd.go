@@ -22,10 +22,13 @@ import (
 var sampleConfig string
 
 type Execd struct {
-	Command      []string        `toml:"command"`
-	Environment  []string        `toml:"environment"`
-	RestartDelay config.Duration `toml:"restart_delay"`
-	Log          telegraf.Logger
+	Command       []string        `toml:"command"`
+	Environment   []string        `toml:"environment"`
+	RestartDelay  config.Duration `toml:"restart_delay"`
+	MaxRestarts   int             `toml:"max_restarts"`
+	RestartWindow config.Duration `toml:"restart_window"`
+	Log           telegraf.Logger
+	process.Limits
 
 	parser     telegraf.Parser
 	serializer telegraf.Serializer
@@ -55,6 +58,9 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	}
 	e.process.Log = e.Log
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
+	e.process.MaxRestarts = e.MaxRestarts
+	e.process.RestartWindow = time.Duration(e.RestartWindow)
+	e.process.Limits = e.Limits
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
 
@@ -172,7 +178,7 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
-	return nil
+	return e.Limits.Validate()
 }
 
 func init() {
@@ -0,0 +1,242 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package external
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type External struct {
+	MaxInFlight int             `toml:"max_in_flight"`
+	Log         telegraf.Logger `toml:"-"`
+	extplugin.Config
+
+	client *extplugin.Client
+	acc    telegraf.Accumulator
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Semaphore limiting the number of unanswered requests
+	sem chan struct{}
+
+	// Stream currently open and the requests waiting for a response
+	stream  grpc.BidiStreamingClient[pluginv1.ProcessRequest, pluginv1.ProcessResponse]
+	id      uint64
+	pending map[uint64]telegraf.Metric
+	sync.Mutex
+}
+
+func (*External) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *External) Init() error {
+	if err := e.Config.Validate(); err != nil {
+		return err
+	}
+	if e.MaxInFlight < 1 {
+		return errors.New("'max_in_flight' must be positive")
+	}
+	e.sem = make(chan struct{}, e.MaxInFlight)
+	e.pending = make(map[uint64]telegraf.Metric, e.MaxInFlight)
+	return nil
+}
+
+func (e *External) Start(acc telegraf.Accumulator) error {
+	client, err := e.Config.Connect(pluginv1.PluginType_PLUGIN_TYPE_PROCESSOR, e.Log)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	e.acc = acc
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	// Open the first stream synchronously so metrics are not rejected
+	// directly after startup
+	stream, err := e.open(ctx)
+	if err != nil {
+		cancel()
+		e.client.Close()
+		return fmt.Errorf("opening stream failed: %w", err)
+	}
+	e.stream = stream
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.receive(ctx, stream)
+	}()
+
+	return nil
+}
+
+func (e *External) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	e.sem <- struct{}{}
+
+	e.Lock()
+	defer e.Unlock()
+
+	if e.stream == nil {
+		<-e.sem
+		return errors.New("plugin not available")
+	}
+
+	e.id++
+	request := &pluginv1.ProcessRequest{
+		Id:      e.id,
+		Metrics: []*pluginv1.Metric{extplugin.ToProto(m)},
+	}
+	if err := e.stream.Send(request); err != nil {
+		<-e.sem
+		return fmt.Errorf("sending metric failed: %w", e.client.Check(err))
+	}
+	e.pending[request.Id] = m
+
+	return nil
+}
+
+func (e *External) Stop() {
+	// Wait for the responses to the pending requests
+	timeout := time.After(e.client.Timeout)
+wait:
+	for range e.MaxInFlight {
+		select {
+		case e.sem <- struct{}{}:
+		case <-timeout:
+			e.Log.Warn("Timeout waiting for pending responses")
+			break wait
+		}
+	}
+
+	e.cancel()
+	e.wg.Wait()
+	e.client.Close()
+}
+
+// receive handles the responses of the plugin and reopens the stream after
+// failures until the context is cancelled
+func (e *External) receive(ctx context.Context, stream grpc.BidiStreamingClient[pluginv1.ProcessRequest, pluginv1.ProcessResponse]) {
+	for {
+		err := e.consume(stream)
+		e.reset()
+		if ctx.Err() != nil {
+			return
+		}
+		e.Log.Errorf("Processing failed: %v", err)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(e.RestartDelay)):
+			}
+
+			stream, err = e.open(ctx)
+			if err == nil {
+				e.Lock()
+				e.stream = stream
+				e.Unlock()
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			e.Log.Errorf("Reopening stream failed: %v", err)
+		}
+	}
+}
+
+func (e *External) open(ctx context.Context) (grpc.BidiStreamingClient[pluginv1.ProcessRequest, pluginv1.ProcessResponse], error) {
+	if _, err := e.client.Handshake(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := pluginv1.NewProcessorClient(e.client.Conn).Process(ctx)
+	if err != nil {
+		return nil, e.client.Check(err)
+	}
+	return stream, nil
+}
+
+func (e *External) consume(stream grpc.BidiStreamingClient[pluginv1.ProcessRequest, pluginv1.ProcessResponse]) error {
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("stream closed by plugin")
+		}
+		if err != nil {
+			return e.client.Check(err)
+		}
+
+		e.Lock()
+		original, found := e.pending[response.GetId()]
+		delete(e.pending, response.GetId())
+		e.Unlock()
+		if !found {
+			e.Log.Errorf("Received response for unknown request %d", response.GetId())
+			continue
+		}
+		<-e.sem
+
+		if perr := response.GetError(); perr != nil {
+			e.acc.AddError(errors.New(perr.GetMessage()))
+		}
+		metrics, errs := extplugin.FromProtoBatch(response.GetMetrics())
+		for _, err := range errs {
+			e.acc.AddError(err)
+		}
+		for _, m := range metrics {
+			e.acc.AddMetric(m)
+		}
+
+		// Tracking information cannot be transferred to the metrics returned
+		// by the plugin so consider the original metric as processed
+		original.Accept()
+	}
+}
+
+// reset drops the metrics of all unanswered requests of a failed stream
+func (e *External) reset() {
+	e.Lock()
+	defer e.Unlock()
+
+	e.stream = nil
+	if len(e.pending) > 0 {
+		e.Log.Errorf("Dropping %d metrics without response", len(e.pending))
+	}
+	for id, m := range e.pending {
+		m.Drop()
+		delete(e.pending, id)
+		<-e.sem
+	}
+}
+
+func init() {
+	processors.AddStreaming("external", func() telegraf.StreamingProcessor {
+		return &External{
+			MaxInFlight: 1000,
+			Config: extplugin.Config{
+				RestartDelay:   config.Duration(10 * time.Second),
+				StartupTimeout: config.Duration(30 * time.Second),
+				Timeout:        config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
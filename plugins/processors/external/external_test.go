@@ -0,0 +1,132 @@
+package external
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/extplugin"
+	"github.com/influxdata/telegraf/plugins/common/extplugin/pluginv1"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &External{
+		Config: extplugin.Config{
+			Address:        "localhost:5000",
+			StartupTimeout: config.Duration(time.Second),
+			Timeout:        config.Duration(time.Second),
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "'max_in_flight' must be positive")
+}
+
+func TestProcess(t *testing.T) {
+	plugin := &External{
+		MaxInFlight: 2,
+		Log:         testutil.Logger{},
+		Config: extplugin.Config{
+			Address:        newServer(t),
+			StartupTimeout: config.Duration(5 * time.Second),
+			Timeout:        config.Duration(5 * time.Second),
+			RestartDelay:   config.Duration(100 * time.Millisecond),
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	var delivered int
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.5}, time.Unix(1700000000, 0)),
+		metric.New("drop", map[string]string{}, map[string]interface{}{"value": 1.5}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": uint64(42)}, time.Unix(1700000000, 0), telegraf.Gauge),
+	}
+	for _, m := range input {
+		tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+
+	// Stopping the plugin waits for the pending responses
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"processed": "true"},
+			map[string]interface{}{"value": 1.5},
+			time.Unix(1700000000, 0),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{"processed": "true"},
+			map[string]interface{}{"used": uint64(42)},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+	require.Equal(t, 3, delivered)
+
+	require.Len(t, acc.Errors, 1)
+	require.EqualError(t, acc.Errors[0], `dropping metric "drop"`)
+}
+
+// server adds a tag to the metrics and drops metrics named "drop"
+type server struct {
+	pluginv1.UnimplementedPluginServer
+	pluginv1.UnimplementedProcessorServer
+}
+
+func (*server) Handshake(context.Context, *pluginv1.HandshakeRequest) (*pluginv1.HandshakeResponse, error) {
+	return &pluginv1.HandshakeResponse{ProtocolVersion: pluginv1.ProtocolVersion_PROTOCOL_VERSION_1}, nil
+}
+
+func (*server) Process(stream grpc.BidiStreamingServer[pluginv1.ProcessRequest, pluginv1.ProcessResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+
+		response := &pluginv1.ProcessResponse{Id: req.GetId()}
+		for _, m := range req.GetMetrics() {
+			if m.GetName() == "drop" {
+				response.Error = &pluginv1.PluginError{Message: `dropping metric "drop"`}
+				continue
+			}
+			m.Tags = append(m.Tags, &pluginv1.Tag{Key: "processed", Value: "true"})
+			response.Metrics = append(response.Metrics, m)
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+func newServer(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	pluginv1.RegisterPluginServer(s, &server{})
+	pluginv1.RegisterProcessorServer(s, &server{})
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	return "unix://" + path
+}
@@ -0,0 +1,219 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package flatten
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Flatten struct {
+	Fields       []string        `toml:"fields"`
+	Format       string          `toml:"format"`
+	Delimiter    string          `toml:"delimiter"`
+	Separator    string          `toml:"separator"`
+	ArrayMode    string          `toml:"array_mode"`
+	IndexTag     string          `toml:"index_tag"`
+	KeepOriginal bool            `toml:"keep_original"`
+	Log          telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+}
+
+func (*Flatten) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Flatten) Init() error {
+	if len(p.Fields) == 0 {
+		return errors.New("no fields specified")
+	}
+
+	f, err := filter.Compile(p.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	p.fieldFilter = f
+
+	switch p.Format {
+	case "":
+		p.Format = "json"
+	case "json", "delimited":
+	default:
+		return fmt.Errorf("invalid format %q", p.Format)
+	}
+
+	switch p.ArrayMode {
+	case "":
+		p.ArrayMode = "fields"
+	case "fields", "metrics":
+	default:
+		return fmt.Errorf("invalid array mode %q", p.ArrayMode)
+	}
+
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+	if p.Separator == "" {
+		p.Separator = "_"
+	}
+	if p.IndexTag == "" {
+		p.IndexTag = "index"
+	}
+
+	return nil
+}
+
+func (p *Flatten) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	results := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		results = append(results, p.flatten(m)...)
+	}
+	return results
+}
+
+func (p *Flatten) flatten(m telegraf.Metric) []telegraf.Metric {
+	// Collect the matching fields first as the field-list is modified below
+	var matching []*telegraf.Field
+	for _, field := range m.FieldList() {
+		if p.fieldFilter.Match(field.Key) {
+			matching = append(matching, &telegraf.Field{Key: field.Key, Value: field.Value})
+		}
+	}
+
+	var explode string
+	var elements []interface{}
+	for _, field := range matching {
+
+		value, err := p.decode(field.Value)
+		if err != nil {
+			p.Log.Errorf("Decoding field %q of metric %q failed: %v", field.Key, m.Name(), err)
+			continue
+		}
+
+		// Keep the first array for creating one metric per element
+		if array, ok := value.([]interface{}); ok && p.ArrayMode == "metrics" && explode == "" {
+			explode = field.Key
+			elements = array
+			continue
+		}
+
+		if !p.KeepOriginal {
+			m.RemoveField(field.Key)
+		}
+		for k, v := range p.flattenValue(field.Key, value) {
+			m.AddField(k, v)
+		}
+	}
+
+	if explode == "" {
+		return []telegraf.Metric{m}
+	}
+
+	// Keep the metric for empty arrays to not lose the remaining fields
+	if len(elements) == 0 {
+		if !p.KeepOriginal {
+			m.RemoveField(explode)
+		}
+		return []telegraf.Metric{m}
+	}
+
+	// Create a copy without tracking information as template
+	base := metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), m.Type())
+	if !p.KeepOriginal {
+		base.RemoveField(explode)
+	}
+
+	results := make([]telegraf.Metric, 0, len(elements))
+	for i, element := range elements {
+		em := base.Copy()
+		em.AddTag(p.IndexTag, strconv.Itoa(i))
+		for k, v := range p.flattenValue(explode, element) {
+			em.AddField(k, v)
+		}
+		results = append(results, em)
+	}
+	m.Accept()
+
+	return results
+}
+
+func (p *Flatten) decode(raw interface{}) (interface{}, error) {
+	s, ok := raw.(string)
+	if !ok {
+		return raw, nil
+	}
+
+	switch p.Format {
+	case "json":
+		var value interface{}
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "delimited":
+		parts := strings.Split(s, p.Delimiter)
+		values := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			values = append(values, parseValue(strings.TrimSpace(part)))
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("invalid format %q", p.Format)
+}
+
+// flattenValue recursively flattens the given value into fields prefixed
+// with the given key. Null values are skipped.
+func (p *Flatten) flattenValue(key string, value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	var walk func(string, interface{})
+	walk = func(prefix string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				walk(prefix+p.Separator+k, child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(prefix+p.Separator+strconv.Itoa(i), child)
+			}
+		case nil:
+		default:
+			fields[prefix] = v
+		}
+	}
+	walk(key, value)
+
+	return fields
+}
+
+// parseValue converts the given string to an integer or float if possible
+// and returns the string otherwise.
+func parseValue(s string) interface{} {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	return s
+}
+
+func init() {
+	processors.Add("flatten", func() telegraf.Processor {
+		return &Flatten{}
+	})
+}
@@ -0,0 +1,292 @@
+package flatten
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Flatten
+		expected string
+	}{
+		{
+			name:     "no fields",
+			plugin:   &Flatten{},
+			expected: "no fields specified",
+		},
+		{
+			name:     "invalid format",
+			plugin:   &Flatten{Fields: []string{"data"}, Format: "xml"},
+			expected: `invalid format "xml"`,
+		},
+		{
+			name:     "invalid array mode",
+			plugin:   &Flatten{Fields: []string{"data"}, ArrayMode: "tags"},
+			expected: `invalid array mode "tags"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCases(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		plugin   *Flatten
+		input    telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "json object",
+			plugin: &Flatten{Fields: []string{"data"}},
+			input: metric.New(
+				"test",
+				map[string]string{"source": "a"},
+				map[string]interface{}{
+					"data":  `{"temp": 21.5, "sensor": {"id": "x1", "ok": true}, "unset": null}`,
+					"other": int64(1),
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"source": "a"},
+					map[string]interface{}{
+						"data_temp":      21.5,
+						"data_sensor_id": "x1",
+						"data_sensor_ok": true,
+						"other":          int64(1),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "json array as fields",
+			plugin: &Flatten{Fields: []string{"data"}, Separator: "."},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]interface{}{"data": `[1, 2, {"x": 3}]`},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{},
+					map[string]interface{}{
+						"data.0":   float64(1),
+						"data.1":   float64(2),
+						"data.2.x": float64(3),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "json array as metrics",
+			plugin: &Flatten{Fields: []string{"disks"}, ArrayMode: "metrics"},
+			input: metric.New(
+				"test",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"disks": `[{"name": "sda", "used": 10}, {"name": "sdb", "used": 20}]`,
+					"total": int64(30),
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"host": "a", "index": "0"},
+					map[string]interface{}{
+						"disks_name": "sda",
+						"disks_used": float64(10),
+						"total":      int64(30),
+					},
+					now,
+				),
+				metric.New(
+					"test",
+					map[string]string{"host": "a", "index": "1"},
+					map[string]interface{}{
+						"disks_name": "sdb",
+						"disks_used": float64(20),
+						"total":      int64(30),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "empty json array as metrics",
+			plugin: &Flatten{Fields: []string{"disks"}, ArrayMode: "metrics"},
+			input: metric.New(
+				"test",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"disks": `[]`,
+					"total": int64(0),
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"total": int64(0)},
+					now,
+				),
+			},
+		},
+		{
+			name:   "empty json array as metrics keeping the original",
+			plugin: &Flatten{Fields: []string{"disks"}, ArrayMode: "metrics", KeepOriginal: true},
+			input: metric.New(
+				"test",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"disks": `[]`,
+					"total": int64(0),
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"host": "a"},
+					map[string]interface{}{
+						"disks": `[]`,
+						"total": int64(0),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "delimited as fields",
+			plugin: &Flatten{Fields: []string{"values"}, Format: "delimited", Delimiter: ";", KeepOriginal: true},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]interface{}{"values": "1; 2.5;abc"},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{},
+					map[string]interface{}{
+						"values":   "1; 2.5;abc",
+						"values_0": int64(1),
+						"values_1": 2.5,
+						"values_2": "abc",
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "delimited as metrics",
+			plugin: &Flatten{Fields: []string{"values"}, Format: "delimited", ArrayMode: "metrics", IndexTag: "core"},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]interface{}{"values": "10,20"},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"core": "0"},
+					map[string]interface{}{"values": int64(10)},
+					now,
+				),
+				metric.New(
+					"test",
+					map[string]string{"core": "1"},
+					map[string]interface{}{"values": int64(20)},
+					now,
+				),
+			},
+		},
+		{
+			name:   "invalid json",
+			plugin: &Flatten{Fields: []string{"data"}},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]interface{}{"data": "{invalid"},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{},
+					map[string]interface{}{"data": "{invalid"},
+					now,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"data": `{"a": 1}`}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"data": `[1, 2]`}, time.Unix(0, 0)),
+	}
+	tracked := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		tm, _ := metric.WithTracking(m, notify)
+		tracked = append(tracked, tm)
+	}
+
+	plugin := &Flatten{Fields: []string{"data"}, ArrayMode: "metrics", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(tracked...)
+	require.Len(t, actual, 3)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,198 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rename_by_lookup_file
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Processor struct {
+	Filename       string          `toml:"file"`
+	Fileformat     string          `toml:"format"`
+	ReloadInterval config.Duration `toml:"reload_interval"`
+	Tags           []string        `toml:"tags"`
+	Dest           string          `toml:"dest"`
+	Default        *string         `toml:"default"`
+	Log            telegraf.Logger `toml:"-"`
+
+	mappings  map[string]string
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+
+	hits         selfstat.Stat
+	misses       selfstat.Stat
+	reloads      selfstat.Stat
+	reloadErrors selfstat.Stat
+	entries      selfstat.Stat
+}
+
+func (*Processor) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Processor) Init() error {
+	if p.Filename == "" {
+		return errors.New("missing 'file'")
+	}
+
+	if len(p.Tags) == 0 {
+		return errors.New("missing 'tags'")
+	}
+
+	if p.Dest != "" && len(p.Tags) > 1 {
+		return errors.New("'dest' can only be used with a single tag")
+	}
+
+	switch strings.ToLower(p.Fileformat) {
+	case "":
+		p.Fileformat = "csv"
+	case "csv", "json":
+		p.Fileformat = strings.ToLower(p.Fileformat)
+	default:
+		return fmt.Errorf("invalid format %q", p.Fileformat)
+	}
+
+	tags := map[string]string{"file": p.Filename}
+	p.hits = selfstat.Register("rename_by_lookup_file", "hits", tags)
+	p.misses = selfstat.Register("rename_by_lookup_file", "misses", tags)
+	p.reloads = selfstat.Register("rename_by_lookup_file", "reloads", tags)
+	p.reloadErrors = selfstat.Register("rename_by_lookup_file", "reload_errors", tags)
+	p.entries = selfstat.Register("rename_by_lookup_file", "entries", tags)
+
+	// The file must be loadable on startup
+	p.lastCheck = time.Now()
+	info, err := os.Stat(p.Filename)
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", p.Filename, err)
+	}
+	return p.load(info)
+}
+
+func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	p.reload()
+
+	for _, m := range in {
+		for _, key := range p.Tags {
+			value, found := m.GetTag(key)
+			if !found {
+				continue
+			}
+
+			dest := key
+			if p.Dest != "" {
+				dest = p.Dest
+			}
+
+			if mapped, found := p.mappings[value]; found {
+				p.hits.Incr(1)
+				m.AddTag(dest, mapped)
+				continue
+			}
+
+			p.misses.Incr(1)
+			if p.Default != nil {
+				m.AddTag(dest, *p.Default)
+			}
+		}
+	}
+	return in
+}
+
+// reload checks the file for modifications once per reload interval and
+// replaces the lookup-table on change. The previous table is kept if the
+// modified file cannot be loaded.
+func (p *Processor) reload() {
+	if p.ReloadInterval <= 0 || time.Since(p.lastCheck) < time.Duration(p.ReloadInterval) {
+		return
+	}
+	p.lastCheck = time.Now()
+
+	info, err := os.Stat(p.Filename)
+	if err != nil {
+		p.reloadErrors.Incr(1)
+		p.Log.Errorf("Checking %q for modifications failed: %v", p.Filename, err)
+		return
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return
+	}
+
+	if err := p.load(info); err != nil {
+		p.reloadErrors.Incr(1)
+		p.Log.Errorf("Reloading failed, keeping previous lookup-table: %v", err)
+		return
+	}
+	p.reloads.Incr(1)
+	p.Log.Debugf("Reloaded %d entries from %q", len(p.mappings), p.Filename)
+}
+
+func (p *Processor) load(info os.FileInfo) error {
+	f, err := os.Open(p.Filename)
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", p.Filename, err)
+	}
+	defer f.Close()
+
+	var mappings map[string]string
+	switch p.Fileformat {
+	case "csv":
+		mappings, err = parseCSV(f)
+	case "json":
+		err = json.NewDecoder(f).Decode(&mappings)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %q failed: %w", p.Filename, err)
+	}
+
+	p.mappings = mappings
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	p.entries.Set(int64(len(mappings)))
+	return nil
+}
+
+func parseCSV(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	mappings := make(map[string]string)
+	line := 0
+	for {
+		line++
+		data, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("reading line %d failed: %w", line, err)
+		}
+		mappings[data[0]] = data[1]
+	}
+	return mappings, nil
+}
+
+func init() {
+	processors.Add("rename_by_lookup_file", func() telegraf.Processor {
+		return &Processor{
+			ReloadInterval: config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package rename_by_lookup_file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &Processor{}
+	require.ErrorContains(t, plugin.Init(), "missing 'file'")
+
+	plugin = &Processor{Filename: "lut.csv"}
+	require.ErrorContains(t, plugin.Init(), "missing 'tags'")
+
+	plugin = &Processor{
+		Filename: "lut.csv",
+		Tags:     []string{"a", "b"},
+		Dest:     "c",
+	}
+	require.ErrorContains(t, plugin.Init(), "'dest' can only be used with a single tag")
+
+	plugin = &Processor{
+		Filename:   "lut.csv",
+		Fileformat: "yaml",
+		Tags:       []string{"a"},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid format "yaml"`)
+
+	plugin = &Processor{
+		Filename: "non-existing.csv",
+		Tags:     []string{"a"},
+	}
+	require.ErrorIs(t, plugin.Init(), os.ErrNotExist)
+}
+
+func TestInitInvalidFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("1,a\n2,b,c\n"), 0600))
+
+	plugin := &Processor{
+		Filename: fn,
+		Tags:     []string{"a"},
+	}
+	require.ErrorContains(t, plugin.Init(), "reading line 2 failed")
+}
+
+func TestApply(t *testing.T) {
+	fallback := "unknown"
+	tests := []struct {
+		name     string
+		format   string
+		content  string
+		tags     []string
+		dest     string
+		fallback *string
+		expected []telegraf.Metric
+	}{
+		{
+			name:    "csv replace",
+			format:  "csv",
+			content: "# ifIndex,description\n1,uplink\n2, server\n",
+			tags:    []string{"ifIndex"},
+			expected: []telegraf.Metric{
+				metric.New("interface", map[string]string{"ifIndex": "uplink"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "server"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "3"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:     "json with dest and default",
+			format:   "json",
+			content:  `{"1": "uplink", "2": "server"}`,
+			tags:     []string{"ifIndex"},
+			dest:     "ifDescr",
+			fallback: &fallback,
+			expected: []telegraf.Metric{
+				metric.New("interface", map[string]string{"ifIndex": "1", "ifDescr": "uplink"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "2", "ifDescr": "server"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "3", "ifDescr": "unknown"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), "lut")
+			require.NoError(t, os.WriteFile(fn, []byte(tt.content), 0600))
+
+			plugin := &Processor{
+				Filename:   fn,
+				Fileformat: tt.format,
+				Tags:       tt.tags,
+				Dest:       tt.dest,
+				Default:    tt.fallback,
+				Log:        testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			input := []telegraf.Metric{
+				metric.New("interface", map[string]string{"ifIndex": "1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "2"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+				metric.New("interface", map[string]string{"ifIndex": "3"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+			}
+			actual := plugin.Apply(input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+
+			require.Equal(t, int64(2), plugin.hits.Get())
+			require.Equal(t, int64(1), plugin.misses.Get())
+			require.Equal(t, int64(2), plugin.entries.Get())
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("10.0.0.1,team-a\n"), 0600))
+
+	plugin := &Processor{
+		Filename:       fn,
+		Tags:           []string{"source"},
+		Dest:           "owner",
+		ReloadInterval: config.Duration(time.Minute),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("net", map[string]string{"source": "10.0.0.1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	actual := plugin.Apply(input.Copy())
+	require.Len(t, actual, 1)
+	owner, _ := actual[0].GetTag("owner")
+	require.Equal(t, "team-a", owner)
+
+	// Modifications are not picked up before the reload interval elapsed
+	require.NoError(t, os.WriteFile(fn, []byte("10.0.0.1,team-b\n"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+	actual = plugin.Apply(input.Copy())
+	owner, _ = actual[0].GetTag("owner")
+	require.Equal(t, "team-a", owner)
+
+	// Reload the modified file
+	plugin.lastCheck = time.Now().Add(-2 * time.Minute)
+	actual = plugin.Apply(input.Copy())
+	owner, _ = actual[0].GetTag("owner")
+	require.Equal(t, "team-b", owner)
+	require.Equal(t, int64(1), plugin.reloads.Get())
+
+	// Keep the previous table if the file cannot be parsed
+	require.NoError(t, os.WriteFile(fn, []byte("10.0.0.1,team-c,invalid\n"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now().Add(2*time.Hour), time.Now().Add(2*time.Hour)))
+	plugin.lastCheck = time.Now().Add(-2 * time.Minute)
+	actual = plugin.Apply(input.Copy())
+	owner, _ = actual[0].GetTag("owner")
+	require.Equal(t, "team-b", owner)
+	require.Equal(t, int64(1), plugin.reloads.Get())
+	require.Equal(t, int64(1), plugin.reloadErrors.Get())
+}
+
+func TestTracking(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("1,uplink\n"), 0600))
+
+	plugin := &Processor{
+		Filename: fn,
+		Tags:     []string{"ifIndex"},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var delivered bool
+	notify := func(telegraf.DeliveryInfo) {
+		delivered = true
+	}
+	m := metric.New("interface", map[string]string{"ifIndex": "1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	input, _ := metric.WithTracking(m, notify)
+
+	actual := plugin.Apply(input)
+	require.Len(t, actual, 1)
+	value, _ := actual[0].GetTag("ifIndex")
+	require.Equal(t, "uplink", value)
+
+	actual[0].Accept()
+	require.Eventually(t, func() bool { return delivered }, time.Second, 10*time.Millisecond)
+}
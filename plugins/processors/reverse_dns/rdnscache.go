@@ -1,6 +1,7 @@
 package reverse_dns
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"net"
@@ -27,7 +28,13 @@ type AnyResolver interface {
 // requests will trigger the lookup and the rest will wait for its response.
 type ReverseDNSCache struct {
 	Resolver AnyResolver
-	stats    RDNSCacheStats
+	// MaxSize limits the number of completed lookups in the cache by evicting
+	// the least recently used ones; zero disables the limit
+	MaxSize int
+	// NegativeTTL is the duration to cache failed lookups for; zero disables
+	// caching of failed lookups
+	NegativeTTL time.Duration
+	stats       RDNSCacheStats
 
 	// settings
 	ttl           time.Duration
@@ -41,6 +48,11 @@ type ReverseDNSCache struct {
 
 	cache map[string]*dnslookup
 
+	// lru orders the completed lookups by their last use if the size of the
+	// cache is limited. Must lock lruLock to get access to it.
+	lru     *list.List
+	lruLock sync.Mutex
+
 	// keep an ordered list of what needs to be worked on and what is due to expire.
 	// We can use this list for both with a job position marker, and by popping items
 	// off the list as they expire. This avoids iterating over the whole map to find
@@ -48,16 +60,21 @@ type ReverseDNSCache struct {
 	// As a bonus, we only have to read the first item to know if anything in the
 	// map has expired.
 	// must lock to get access to this.
-	expireList     []*dnslookup
-	expireListLock sync.Mutex
+	expireList []*dnslookup
+	// negativeExpireList is the same for failed lookups, kept separately as
+	// they expire after the negative TTL. must lock expireListLock as well.
+	negativeExpireList []*dnslookup
+	expireListLock     sync.Mutex
 }
 
 type RDNSCacheStats struct {
 	CacheHit          uint64
 	CacheMiss         uint64
 	CacheExpire       uint64
+	CacheEvict        uint64
 	RequestsAbandoned uint64
 	RequestsFilled    uint64
+	RequestsSkipped   uint64
 }
 
 func NewReverseDNSCache(ttl, lookupTimeout time.Duration, workerPoolSize int) *ReverseDNSCache {
@@ -69,6 +86,7 @@ func NewReverseDNSCache(ttl, lookupTimeout time.Duration, workerPoolSize int) *R
 		ttl:                 ttl,
 		lookupTimeout:       lookupTimeout,
 		cache:               make(map[string]*dnslookup),
+		lru:                 list.New(),
 		maxWorkers:          workerPoolSize,
 		sem:                 semaphore.NewWeighted(int64(workerPoolSize)),
 		cancelCleanupWorker: cancel,
@@ -87,6 +105,9 @@ type dnslookup struct {
 	expiresAt time.Time
 	completed bool
 	callbacks []callbackChannelType
+	// elem is the element of the lookup in the lru list, must lock lruLock
+	// to get access to it
+	elem *list.Element
 }
 
 type lookupResult struct {
@@ -110,6 +131,7 @@ func (d *ReverseDNSCache) Lookup(ip string) ([]string, error) {
 	if found && result.completed && !result.expiresAt.Before(time.Now()) {
 		defer d.rwLock.RUnlock()
 		atomic.AddUint64(&d.stats.CacheHit, 1)
+		d.touch(result)
 		// cache is valid
 		return result.domains, nil
 	}
@@ -130,6 +152,60 @@ func (d *ReverseDNSCache) Lookup(ip string) ([]string, error) {
 	}
 }
 
+// LookupAsync returns the cached result for the given IP without blocking.
+// On a cache miss, the lookup is started in the background if less than the
+// maximum number of lookups are in flight, so the result is available for
+// subsequent calls. The boolean is false if no result is cached.
+func (d *ReverseDNSCache) LookupAsync(ip string) ([]string, bool) {
+	if len(ip) == 0 {
+		return nil, false
+	}
+
+	d.rwLock.RLock()
+	result, found := d.lockedGetFromCache(ip)
+	if found && result.completed {
+		defer d.rwLock.RUnlock()
+		atomic.AddUint64(&d.stats.CacheHit, 1)
+		d.touch(result)
+		return result.domains, true
+	}
+	d.rwLock.RUnlock()
+
+	atomic.AddUint64(&d.stats.CacheMiss, 1)
+	if found {
+		// the lookup is in flight already
+		return nil, false
+	}
+
+	// skip the lookup instead of waiting for a free worker
+	if !d.sem.TryAcquire(1) {
+		atomic.AddUint64(&d.stats.RequestsSkipped, 1)
+		return nil, false
+	}
+
+	// register the request if no other caller did in the meantime
+	d.rwLock.Lock()
+	if _, found := d.lockedGetFromCache(ip); found {
+		d.rwLock.Unlock()
+		d.sem.Release(1)
+		return nil, false
+	}
+	d.lockedSaveToCache(&dnslookup{
+		ip:        ip,
+		expiresAt: time.Now().Add(d.ttl),
+	})
+	d.rwLock.Unlock()
+
+	go func() {
+		defer d.sem.Release(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), d.lookupTimeout)
+		defer cancel()
+		d.resolve(ctx, ip)
+	}()
+	return nil, false
+}
+
 func (d *ReverseDNSCache) subscribeTo(ip string) callbackChannelType {
 	callback := make(callbackChannelType, 1)
 
@@ -211,11 +287,27 @@ func (d *ReverseDNSCache) doLookup(ip string) {
 	}
 	defer d.sem.Release(1)
 
+	d.resolve(ctx, ip)
+}
+
+// resolve looks up the given IP and completes the pending lookup in the cache
+func (d *ReverseDNSCache) resolve(ctx context.Context, ip string) {
 	names, err := d.Resolver.LookupAddr(ctx, ip)
-	if err != nil {
+	if err != nil && d.NegativeTTL <= 0 {
 		d.abandonLookup(ip, err)
 		return
 	}
+	d.complete(ip, names, err)
+}
+
+// complete stores the result of a lookup in the cache and passes it to the
+// subscribers. Failed lookups are cached for the negative TTL to avoid
+// querying slow or failing DNS servers over and over again.
+func (d *ReverseDNSCache) complete(ip string, names []string, lookupErr error) {
+	ttl := d.ttl
+	if lookupErr != nil {
+		ttl = d.NegativeTTL
+	}
 
 	d.rwLock.Lock()
 	lookup, found := d.lockedGetFromCache(ip)
@@ -226,25 +318,75 @@ func (d *ReverseDNSCache) doLookup(ip string) {
 
 	lookup.domains = names
 	lookup.completed = true
-	lookup.expiresAt = time.Now().Add(d.ttl) // extend the ttl now that we have a reply.
+	lookup.expiresAt = time.Now().Add(ttl) // extend the ttl now that we have a reply.
 	callbacks := lookup.callbacks
 	lookup.callbacks = nil
 
 	d.lockedSaveToCache(lookup)
+	d.lockedAddToLRU(lookup)
 	d.rwLock.Unlock()
 
 	d.expireListLock.Lock()
 	// add it to the expireList.
-	d.expireList = append(d.expireList, lookup)
+	if lookupErr != nil {
+		d.negativeExpireList = append(d.negativeExpireList, lookup)
+	} else {
+		d.expireList = append(d.expireList, lookup)
+	}
 	d.expireListLock.Unlock()
 
-	atomic.AddUint64(&d.stats.RequestsFilled, uint64(len(callbacks)))
+	if lookupErr != nil {
+		atomic.AddUint64(&d.stats.RequestsAbandoned, uint64(len(callbacks)))
+	} else {
+		atomic.AddUint64(&d.stats.RequestsFilled, uint64(len(callbacks)))
+	}
 	for _, cb := range callbacks {
-		cb <- lookupResult{domains: names}
+		cb <- lookupResult{domains: names, err: lookupErr}
 		close(cb)
 	}
 }
 
+// lockedAddToLRU adds the completed lookup to the lru list and evicts the
+// least recently used lookups if the cache is full.
+// you MUST first do a write lock before calling it.
+func (d *ReverseDNSCache) lockedAddToLRU(lookup *dnslookup) {
+	if d.MaxSize <= 0 {
+		return
+	}
+
+	d.lruLock.Lock()
+	defer d.lruLock.Unlock()
+
+	if lookup.elem != nil {
+		d.lru.MoveToFront(lookup.elem)
+		return
+	}
+	lookup.elem = d.lru.PushFront(lookup)
+
+	for d.lru.Len() > d.MaxSize {
+		oldest := d.lru.Remove(d.lru.Back()).(*dnslookup)
+		oldest.elem = nil
+		if d.cache[oldest.ip] == oldest {
+			delete(d.cache, oldest.ip)
+		}
+		atomic.AddUint64(&d.stats.CacheEvict, 1)
+	}
+}
+
+// touch marks the lookup as recently used.
+// you MUST hold at least a read lock when calling it.
+func (d *ReverseDNSCache) touch(lookup *dnslookup) {
+	if d.MaxSize <= 0 {
+		return
+	}
+
+	d.lruLock.Lock()
+	if lookup.elem != nil {
+		d.lru.MoveToFront(lookup.elem)
+	}
+	d.lruLock.Unlock()
+}
+
 func (d *ReverseDNSCache) abandonLookup(ip string, err error) {
 	d.rwLock.Lock()
 	lookup, found := d.lockedGetFromCache(ip)
@@ -267,31 +409,79 @@ func (d *ReverseDNSCache) abandonLookup(ip string, err error) {
 func (d *ReverseDNSCache) cleanup() {
 	now := time.Now()
 	d.expireListLock.Lock()
-	if len(d.expireList) == 0 {
-		d.expireListLock.Unlock()
+	var toDelete []*dnslookup
+	toDelete, d.expireList = popExpired(d.expireList, now)
+	toDeleteNegative, remaining := popExpired(d.negativeExpireList, now)
+	d.negativeExpireList = remaining
+	toDelete = append(toDelete, toDeleteNegative...)
+	if d.MaxSize > 0 {
+		// drop evicted lookups to keep the lists bounded by the cache size
+		d.expireList = d.compact(d.expireList)
+		d.negativeExpireList = d.compact(d.negativeExpireList)
+	}
+	d.expireListLock.Unlock()
+
+	if len(toDelete) == 0 {
 		return
 	}
-	ipsToDelete := make([]string, 0, len(d.expireList))
-	for i := 0; i < len(d.expireList); i++ {
-		if !d.expireList[i].expiresAt.Before(now) {
-			break // done. Nothing after this point is expired.
+
+	d.rwLock.Lock()
+	defer d.rwLock.Unlock()
+
+	var expired uint64
+	for _, lookup := range toDelete {
+		// the lookup might have been evicted or replaced by a newer one
+		if d.cache[lookup.ip] != lookup {
+			continue
+		}
+		delete(d.cache, lookup.ip)
+		d.lruLock.Lock()
+		if lookup.elem != nil {
+			d.lru.Remove(lookup.elem)
+			lookup.elem = nil
 		}
-		ipsToDelete = append(ipsToDelete, d.expireList[i].ip)
+		d.lruLock.Unlock()
+		expired++
 	}
-	if len(ipsToDelete) == 0 {
-		d.expireListLock.Unlock()
-		return
+	atomic.AddUint64(&d.stats.CacheExpire, expired)
+}
+
+// popExpired splits the given list ordered by expiry into the expired and
+// the remaining lookups
+func popExpired(lookups []*dnslookup, now time.Time) (expired, remaining []*dnslookup) {
+	for i, lookup := range lookups {
+		if !lookup.expiresAt.Before(now) {
+			return lookups[:i], lookups[i:] // done. Nothing after this point is expired.
+		}
 	}
-	d.expireList = d.expireList[len(ipsToDelete):]
-	d.expireListLock.Unlock()
+	return lookups, nil
+}
 
-	atomic.AddUint64(&d.stats.CacheExpire, uint64(len(ipsToDelete)))
+// compact removes the lookups no longer in the cache from the given list if
+// it grew beyond twice the maximum cache size.
+// you MUST hold the expireListLock when calling it.
+func (d *ReverseDNSCache) compact(lookups []*dnslookup) []*dnslookup {
+	if len(lookups) <= 2*d.MaxSize {
+		return lookups
+	}
 
-	d.rwLock.Lock()
-	defer d.rwLock.Unlock()
-	for _, ip := range ipsToDelete {
-		delete(d.cache, ip)
+	d.rwLock.RLock()
+	defer d.rwLock.RUnlock()
+
+	remaining := make([]*dnslookup, 0, d.MaxSize)
+	for _, lookup := range lookups {
+		if d.cache[lookup.ip] == lookup {
+			remaining = append(remaining, lookup)
+		}
 	}
+	return remaining
+}
+
+// Size returns the number of entries in the cache including pending lookups
+func (d *ReverseDNSCache) Size() int {
+	d.rwLock.RLock()
+	defer d.rwLock.RUnlock()
+	return len(d.cache)
 }
 
 func (d *ReverseDNSCache) Stats() RDNSCacheStats {
@@ -299,8 +489,10 @@ func (d *ReverseDNSCache) Stats() RDNSCacheStats {
 	stats.CacheHit = atomic.LoadUint64(&d.stats.CacheHit)
 	stats.CacheMiss = atomic.LoadUint64(&d.stats.CacheMiss)
 	stats.CacheExpire = atomic.LoadUint64(&d.stats.CacheExpire)
+	stats.CacheEvict = atomic.LoadUint64(&d.stats.CacheEvict)
 	stats.RequestsAbandoned = atomic.LoadUint64(&d.stats.RequestsAbandoned)
 	stats.RequestsFilled = atomic.LoadUint64(&d.stats.RequestsFilled)
+	stats.RequestsSkipped = atomic.LoadUint64(&d.stats.RequestsSkipped)
 	return stats
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -123,6 +124,115 @@ func TestLookupTimeout(t *testing.T) {
 	require.EqualValues(t, 1, d.Stats().RequestsAbandoned)
 }
 
+func TestNegativeCache(t *testing.T) {
+	d := NewReverseDNSCache(10*time.Second, 10*time.Second, -1)
+	defer d.Stop()
+	d.NegativeTTL = 100 * time.Millisecond
+
+	resolver := &countingResolver{err: errors.New("timeout")}
+	d.Resolver = resolver
+	_, err := d.Lookup("127.0.0.1")
+	require.Error(t, err)
+
+	// the failure is cached and the IP is not looked up again
+	answer, err := d.Lookup("127.0.0.1")
+	require.NoError(t, err)
+	require.Empty(t, answer)
+	require.EqualValues(t, 1, resolver.calls.Load())
+	require.Len(t, d.negativeExpireList, 1)
+	require.Empty(t, d.expireList)
+
+	time.Sleep(d.NegativeTTL) // wait for the failure to expire.
+	d.cleanup()
+	require.Empty(t, d.cache)
+	require.Empty(t, d.negativeExpireList)
+
+	stats := d.Stats()
+	require.EqualValues(t, 1, stats.CacheHit)
+	require.EqualValues(t, 1, stats.CacheMiss)
+	require.EqualValues(t, 1, stats.CacheExpire)
+	require.EqualValues(t, 1, stats.RequestsAbandoned)
+}
+
+func TestCacheEviction(t *testing.T) {
+	d := NewReverseDNSCache(10*time.Second, 1*time.Second, -1)
+	defer d.Stop()
+	d.MaxSize = 2
+
+	d.Resolver = &localResolver{}
+	for _, ip := range []string{"127.0.0.1", "127.0.0.2", "127.0.0.1", "127.0.0.3"} {
+		_, err := d.Lookup(ip)
+		require.NoError(t, err)
+	}
+
+	// 127.0.0.2 is the least recently used entry
+	require.Len(t, d.cache, 2)
+	require.Contains(t, d.cache, "127.0.0.1")
+	require.Contains(t, d.cache, "127.0.0.3")
+	require.Equal(t, 2, d.lru.Len())
+	require.EqualValues(t, 1, d.Stats().CacheEvict)
+
+	// the evicted entry must not be removed again on expiry
+	d.expireListLock.Lock()
+	for _, lookup := range d.expireList {
+		lookup.expiresAt = time.Now().Add(-time.Second)
+	}
+	d.expireListLock.Unlock()
+	d.cleanup()
+	require.Empty(t, d.cache)
+	require.Zero(t, d.lru.Len())
+	require.EqualValues(t, 2, d.Stats().CacheExpire)
+}
+
+func TestLookupAsync(t *testing.T) {
+	d := NewReverseDNSCache(10*time.Second, 1*time.Second, 1)
+	defer d.Stop()
+
+	resolver := &countingResolver{
+		names:   []string{"localhost"},
+		release: make(chan bool),
+	}
+	d.Resolver = resolver
+
+	// the lookup is started in the background
+	answer, found := d.LookupAsync("127.0.0.1")
+	require.False(t, found)
+	require.Nil(t, answer)
+
+	// no worker is available for another IP so the lookup is skipped
+	_, found = d.LookupAsync("127.0.0.2")
+	require.False(t, found)
+	require.EqualValues(t, 1, d.Stats().RequestsSkipped)
+
+	close(resolver.release)
+	require.Eventually(t, func() bool {
+		answer, found = d.LookupAsync("127.0.0.1")
+		return found
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"localhost"}, answer)
+	require.EqualValues(t, 1, resolver.calls.Load())
+	require.EqualValues(t, 1, d.Stats().CacheHit)
+}
+
+type countingResolver struct {
+	names   []string
+	err     error
+	release chan bool
+	calls   atomic.Int64
+}
+
+func (r *countingResolver) LookupAddr(ctx context.Context, _ string) (names []string, err error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return r.names, r.err
+}
+
 type timeoutResolver struct{}
 
 func (*timeoutResolver) LookupAddr(context.Context, string) (names []string, err error) {
@@ -2,13 +2,16 @@
 package reverse_dns
 
 import (
+	"context"
 	_ "embed"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/parallel"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -27,10 +30,24 @@ type ReverseDNS struct {
 
 	Lookups            []lookupEntry   `toml:"lookup"`
 	CacheTTL           config.Duration `toml:"cache_ttl"`
+	CacheSize          int             `toml:"cache_size"`
+	NegativeCacheTTL   config.Duration `toml:"negative_cache_ttl"`
 	LookupTimeout      config.Duration `toml:"lookup_timeout"`
 	MaxParallelLookups int             `toml:"max_parallel_lookups"`
 	Ordered            bool            `toml:"ordered"`
+	Async              bool            `toml:"async"`
 	Log                telegraf.Logger `toml:"-"`
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	statHits    selfstat.Stat
+	statMisses  selfstat.Stat
+	statExpired selfstat.Stat
+	statEvicted selfstat.Stat
+	statFailed  selfstat.Stat
+	statSkipped selfstat.Stat
+	statEntries selfstat.Stat
 }
 
 func (*ReverseDNS) SampleConfig() string {
@@ -44,30 +61,103 @@ func (r *ReverseDNS) Start(acc telegraf.Accumulator) error {
 		time.Duration(r.LookupTimeout),
 		r.MaxParallelLookups, // max parallel reverse-dns lookups
 	)
-	if r.Ordered {
-		r.parallel = parallel.NewOrdered(acc, r.asyncAdd, 10000, r.MaxParallelLookups)
-	} else {
-		r.parallel = parallel.NewUnordered(acc, r.asyncAdd, r.MaxParallelLookups)
+	r.reverseDNSCache.MaxSize = r.CacheSize
+	r.reverseDNSCache.NegativeTTL = time.Duration(r.NegativeCacheTTL)
+
+	// In async mode metrics never wait for a lookup, so there is no need to
+	// process them in parallel
+	if !r.Async {
+		if r.Ordered {
+			r.parallel = parallel.NewOrdered(acc, r.asyncAdd, 10000, r.MaxParallelLookups)
+		} else {
+			r.parallel = parallel.NewUnordered(acc, r.asyncAdd, r.MaxParallelLookups)
+		}
 	}
+
+	tags := make(map[string]string)
+	r.statHits = selfstat.Register("reverse_dns", "cache_hits", tags)
+	r.statMisses = selfstat.Register("reverse_dns", "cache_misses", tags)
+	r.statExpired = selfstat.Register("reverse_dns", "cache_expired", tags)
+	r.statEvicted = selfstat.Register("reverse_dns", "cache_evicted", tags)
+	r.statEntries = selfstat.Register("reverse_dns", "cache_entries", tags)
+	r.statFailed = selfstat.Register("reverse_dns", "lookups_failed", tags)
+	r.statSkipped = selfstat.Register("reverse_dns", "lookups_skipped", tags)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.reportStats(ctx)
+	}()
+
 	return nil
 }
 
 func (r *ReverseDNS) Stop() {
-	r.parallel.Stop()
+	if r.parallel != nil {
+		r.parallel.Stop()
+	}
+	r.cancel()
+	r.wg.Wait()
 	r.reverseDNSCache.Stop()
 }
 
-func (r *ReverseDNS) Add(metric telegraf.Metric, _ telegraf.Accumulator) error {
+func (r *ReverseDNS) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	if r.Async {
+		for _, m := range r.asyncAdd(metric) {
+			acc.AddMetric(m)
+		}
+		return nil
+	}
 	r.parallel.Enqueue(metric)
 	return nil
 }
 
+// reportStats periodically updates the internal metrics from the cache
+// statistics until the context is cancelled
+func (r *ReverseDNS) reportStats(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		r.updateStats()
+		select {
+		case <-ctx.Done():
+			r.updateStats()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ReverseDNS) updateStats() {
+	stats := r.reverseDNSCache.Stats()
+	r.statHits.Set(int64(stats.CacheHit))
+	r.statMisses.Set(int64(stats.CacheMiss))
+	r.statExpired.Set(int64(stats.CacheExpire))
+	r.statEvicted.Set(int64(stats.CacheEvict))
+	r.statFailed.Set(int64(stats.RequestsAbandoned))
+	r.statSkipped.Set(int64(stats.RequestsSkipped))
+	r.statEntries.Set(int64(r.reverseDNSCache.Size()))
+}
+
+// lookup resolves the given IP using the cache. In async mode, only cached
+// results are returned without waiting for the lookup to finish.
+func (r *ReverseDNS) lookup(ip string) ([]string, error) {
+	if r.Async {
+		names, _ := r.reverseDNSCache.LookupAsync(ip)
+		return names, nil
+	}
+	return r.reverseDNSCache.Lookup(ip)
+}
+
 func (r *ReverseDNS) asyncAdd(metric telegraf.Metric) []telegraf.Metric {
 	for _, lookup := range r.Lookups {
 		if len(lookup.Field) > 0 {
 			if ipField, ok := metric.GetField(lookup.Field); ok {
 				if ip, ok := ipField.(string); ok {
-					result, err := r.reverseDNSCache.Lookup(ip)
+					result, err := r.lookup(ip)
 					if err != nil {
 						r.Log.Errorf("lookup error: %v", err)
 						continue
@@ -80,7 +170,7 @@ func (r *ReverseDNS) asyncAdd(metric telegraf.Metric) []telegraf.Metric {
 		}
 		if len(lookup.Tag) > 0 {
 			if ipTag, ok := metric.GetTag(lookup.Tag); ok {
-				result, err := r.reverseDNSCache.Lookup(ipTag)
+				result, err := r.lookup(ipTag)
 				if err != nil {
 					r.Log.Errorf("lookup error: %v", err)
 					continue
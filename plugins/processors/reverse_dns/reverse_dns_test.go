@@ -131,3 +131,43 @@ func TestTracking(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestAsync(t *testing.T) {
+	plugin := &ReverseDNS{
+		CacheTTL:           config.Duration(24 * time.Hour),
+		LookupTimeout:      config.Duration(1 * time.Minute),
+		MaxParallelLookups: 10,
+		Async:              true,
+		Log:                &testutil.Logger{},
+		Lookups: []lookupEntry{
+			{
+				Tag:  "ip",
+				Dest: "name",
+			},
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	defer plugin.Stop()
+	plugin.reverseDNSCache.Resolver = &localResolver{}
+
+	// The first metric is passed on unaltered without waiting for the lookup
+	m := metric.New("foo", map[string]string{"ip": "127.0.0.1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	require.Eventually(t, func() bool {
+		_, found := plugin.reverseDNSCache.LookupAsync("127.0.0.1")
+		return found
+	}, time.Second, 10*time.Millisecond)
+
+	m = metric.New("foo", map[string]string{"ip": "127.0.0.1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, acc))
+
+	expected := []telegraf.Metric{
+		metric.New("foo", map[string]string{"ip": "127.0.0.1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		metric.New("foo", map[string]string{"ip": "127.0.0.1", "name": "localhost"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,99 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sample
+
+import (
+	_ "embed"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Sample struct {
+	Rate       float64         `toml:"rate"`
+	StratifyBy []string        `toml:"stratify_by"`
+	Seed       string          `toml:"seed"`
+	Log        telegraf.Logger `toml:"-"`
+
+	threshold uint64
+}
+
+func (*Sample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sample) Init() error {
+	if s.Rate <= 0 || s.Rate > 1 {
+		return errors.New("rate must be in the range (0, 1]")
+	}
+
+	// Compute the hash threshold for keeping a series, a rate of one
+	// keeps everything
+	if s.Rate < 1 {
+		s.threshold = uint64(s.Rate * math.MaxUint64)
+	} else {
+		s.threshold = math.MaxUint64
+	}
+
+	sort.Strings(s.StratifyBy)
+
+	return nil
+}
+
+func (s *Sample) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if s.keep(m) {
+			out = append(out, m)
+		} else {
+			m.Drop()
+		}
+	}
+	return out
+}
+
+// keep decides if the given metric should be kept based on a hash of its
+// stratum. The decision only depends on the metric name, the tags and the
+// seed, so it is consistent across time and across agents.
+func (s *Sample) keep(m telegraf.Metric) bool {
+	if s.threshold == math.MaxUint64 {
+		return true
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(s.Seed))
+	h.Write([]byte{0})
+	h.Write([]byte(m.Name()))
+	h.Write([]byte{0})
+	if len(s.StratifyBy) == 0 {
+		// The tag-list is sorted by key
+		for _, tag := range m.TagList() {
+			h.Write([]byte(tag.Key))
+			h.Write([]byte{0})
+			h.Write([]byte(tag.Value))
+			h.Write([]byte{0})
+		}
+	} else {
+		for _, key := range s.StratifyBy {
+			value, _ := m.GetTag(key)
+			h.Write([]byte(key))
+			h.Write([]byte{0})
+			h.Write([]byte(value))
+			h.Write([]byte{0})
+		}
+	}
+
+	return h.Sum64() < s.threshold
+}
+
+func init() {
+	processors.Add("sample", func() telegraf.Processor {
+		return &Sample{}
+	})
+}
@@ -0,0 +1,126 @@
+package sample
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func generateMetrics(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		m := metric.New(
+			"test",
+			map[string]string{
+				"host":   fmt.Sprintf("host%d", i),
+				"region": fmt.Sprintf("region%d", i%4),
+			},
+			map[string]interface{}{"value": i},
+			time.Unix(0, 0),
+		)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestInitInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -0.5, 1.5} {
+		t.Run(fmt.Sprintf("%v", rate), func(t *testing.T) {
+			plugin := &Sample{Rate: rate}
+			require.ErrorContains(t, plugin.Init(), "rate must be in the range")
+		})
+	}
+}
+
+func TestKeepAll(t *testing.T) {
+	plugin := &Sample{Rate: 1}
+	require.NoError(t, plugin.Init())
+
+	input := generateMetrics(100)
+	expected := generateMetrics(100)
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestRate(t *testing.T) {
+	plugin := &Sample{Rate: 0.25}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(generateMetrics(10000)...)
+	require.InDelta(t, 2500, len(actual), 250)
+}
+
+func TestConsistency(t *testing.T) {
+	first := &Sample{Rate: 0.5, Seed: "telegraf"}
+	require.NoError(t, first.Init())
+	second := &Sample{Rate: 0.5, Seed: "telegraf"}
+	require.NoError(t, second.Init())
+
+	// The same series must be kept across instances and invocations
+	expected := first.Apply(generateMetrics(1000)...)
+	require.NotEmpty(t, expected)
+	testutil.RequireMetricsEqual(t, expected, second.Apply(generateMetrics(1000)...))
+	testutil.RequireMetricsEqual(t, expected, first.Apply(generateMetrics(1000)...))
+
+	// Using a different seed should result in a different selection
+	other := &Sample{Rate: 0.5, Seed: "other"}
+	require.NoError(t, other.Init())
+	require.NotEqual(t, expected, other.Apply(generateMetrics(1000)...))
+}
+
+func TestStratify(t *testing.T) {
+	plugin := &Sample{Rate: 0.5, StratifyBy: []string{"region"}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(generateMetrics(1000)...)
+	require.NotEmpty(t, actual)
+
+	// Series of a stratum are either all kept or all dropped
+	counts := make(map[string]int)
+	for _, m := range actual {
+		region, found := m.GetTag("region")
+		require.True(t, found)
+		counts[region]++
+	}
+	for region, count := range counts {
+		require.Equalf(t, 250, count, "incomplete stratum %q", region)
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 100)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, 100)
+	for _, m := range generateMetrics(100) {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Sample{Rate: 0.5}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// All metrics must be delivered either by dropping or accepting them
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,258 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package slo_burn_rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SLOBurnRate struct {
+	SuccessField string            `toml:"success_field"`
+	TotalField   string            `toml:"total_field"`
+	Targets      []float64         `toml:"targets"`
+	Windows      []config.Duration `toml:"windows"`
+	Log          telegraf.Logger   `toml:"-"`
+
+	fieldNames []string
+	targetTags []string
+	maxWindow  time.Duration
+	cache      map[uint64]*series
+}
+
+// sample is the state of the counters of a series at a point in time
+type sample struct {
+	timestamp time.Time
+	success   float64
+	total     float64
+}
+
+// series contains the samples of a series covering the longest window in
+// chronological order
+type series struct {
+	samples []sample
+}
+
+func (*SLOBurnRate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SLOBurnRate) Init() error {
+	if s.SuccessField == "" {
+		return errors.New("'success_field' required")
+	}
+	if s.TotalField == "" {
+		return errors.New("'total_field' required")
+	}
+	if len(s.Targets) == 0 {
+		return errors.New("no targets configured")
+	}
+	if len(s.Windows) == 0 {
+		return errors.New("no windows configured")
+	}
+
+	s.targetTags = make([]string, 0, len(s.Targets))
+	for _, target := range s.Targets {
+		if target <= 0 || target >= 1 {
+			return fmt.Errorf("invalid target %v, must be between zero and one", target)
+		}
+		s.targetTags = append(s.targetTags, strconv.FormatFloat(target, 'f', -1, 64))
+	}
+
+	s.fieldNames = make([]string, 0, len(s.Windows))
+	for _, window := range s.Windows {
+		if window <= 0 {
+			return fmt.Errorf("invalid window %s, must be positive", time.Duration(window))
+		}
+		s.fieldNames = append(s.fieldNames, "burn_rate_"+formatWindow(time.Duration(window)))
+		s.maxWindow = max(s.maxWindow, time.Duration(window))
+	}
+
+	s.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (s *SLOBurnRate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	var latest time.Time
+	for _, m := range in {
+		out = append(out, m)
+
+		success, total, ok := s.counters(m)
+		if !ok {
+			continue
+		}
+		current := sample{timestamp: m.Time(), success: success, total: total}
+		if current.timestamp.After(latest) {
+			latest = current.timestamp
+		}
+
+		id := m.HashID()
+		entry, found := s.cache[id]
+		if !found {
+			s.cache[id] = &series{samples: []sample{current}}
+			continue
+		}
+		if !entry.add(current, s.maxWindow) {
+			s.Log.Debugf("Resetting series of %q as the counters were reset or went back in time", m.Name())
+			continue
+		}
+
+		// Compute the error ratio for all windows covered by the samples
+		ratios := make([]float64, 0, len(s.Windows))
+		names := make([]string, 0, len(s.Windows))
+		for i, window := range s.Windows {
+			ratio, ok := entry.errorRatio(time.Duration(window))
+			if !ok {
+				continue
+			}
+			ratios = append(ratios, ratio)
+			names = append(names, s.fieldNames[i])
+		}
+		if len(ratios) == 0 {
+			continue
+		}
+
+		for i, target := range s.Targets {
+			tags := m.Tags()
+			tags["slo_target"] = s.targetTags[i]
+			fields := make(map[string]interface{}, len(ratios))
+			for j, ratio := range ratios {
+				fields[names[j]] = ratio / (1 - target)
+			}
+			out = append(out, metric.New(m.Name(), tags, fields, m.Time()))
+		}
+	}
+
+	// Remove the series not updated within the longest window as they cannot
+	// contribute to any burn rate anymore
+	if !latest.IsZero() {
+		threshold := latest.Add(-s.maxWindow)
+		maps.DeleteFunc(s.cache, func(_ uint64, e *series) bool {
+			return e.samples[len(e.samples)-1].timestamp.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// counters returns the success and total counter values of the metric
+func (s *SLOBurnRate) counters(m telegraf.Metric) (success, total float64, ok bool) {
+	sv, found := m.GetField(s.SuccessField)
+	if !found {
+		return 0, 0, false
+	}
+	tv, found := m.GetField(s.TotalField)
+	if !found {
+		return 0, 0, false
+	}
+
+	success, err := internal.ToFloat64(sv)
+	if err != nil {
+		s.Log.Tracef("Skipping metric %q as field %q is not numeric: %v", m.Name(), s.SuccessField, err)
+		return 0, 0, false
+	}
+	total, err = internal.ToFloat64(tv)
+	if err != nil {
+		s.Log.Tracef("Skipping metric %q as field %q is not numeric: %v", m.Name(), s.TotalField, err)
+		return 0, 0, false
+	}
+	return success, total, true
+}
+
+// add appends the sample and removes the samples not required to cover the
+// given window. A sample with the timestamp of the latest sample replaces the
+// latter. If the counters were reset or the sample is older than the latest
+// one, the series is restarted with the sample and false is returned.
+func (e *series) add(current sample, window time.Duration) bool {
+	last := e.samples[len(e.samples)-1]
+	switch {
+	case current.timestamp.Before(last.timestamp), current.success < last.success, current.total < last.total:
+		e.samples = append(e.samples[:0], current)
+		return false
+	case current.timestamp.Equal(last.timestamp):
+		e.samples[len(e.samples)-1] = current
+	default:
+		e.samples = append(e.samples, current)
+	}
+
+	// Keep the latest sample at or before the start of the window
+	start := current.timestamp.Add(-window)
+	idx, _ := slices.BinarySearchFunc(e.samples, start, func(s sample, t time.Time) int {
+		return s.timestamp.Compare(t)
+	})
+	if idx < len(e.samples) && e.samples[idx].timestamp.Equal(start) {
+		idx++
+	}
+	if idx > 1 {
+		e.samples = slices.Delete(e.samples, 0, idx-1)
+	}
+	return true
+}
+
+// errorRatio returns the ratio of failed events within the given window
+// ending with the latest sample. The boolean is false if the samples do not
+// cover the window.
+func (e *series) errorRatio(window time.Duration) (float64, bool) {
+	last := e.samples[len(e.samples)-1]
+	start := last.timestamp.Add(-window)
+
+	// Find the latest sample at or before the start of the window
+	idx, found := slices.BinarySearchFunc(e.samples, start, func(s sample, t time.Time) int {
+		return s.timestamp.Compare(t)
+	})
+	if !found {
+		idx--
+	}
+	if idx < 0 {
+		return 0, false
+	}
+	first := e.samples[idx]
+
+	total := last.total - first.total
+	if total <= 0 {
+		return 0, true
+	}
+	failed := total - (last.success - first.success)
+	return max(failed, 0) / total, true
+}
+
+// formatWindow returns the duration without trailing zero units, e.g. "1h"
+// instead of "1h0m0s"
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func init() {
+	processors.Add("slo_burn_rate", func() telegraf.Processor {
+		return &SLOBurnRate{
+			Windows: []config.Duration{
+				config.Duration(5 * time.Minute),
+				config.Duration(time.Hour),
+				config.Duration(6 * time.Hour),
+			},
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package slo_burn_rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SLOBurnRate
+		expected string
+	}{
+		{
+			name:     "missing success field",
+			plugin:   &SLOBurnRate{TotalField: "total", Targets: []float64{0.99}},
+			expected: "'success_field' required",
+		},
+		{
+			name:     "missing total field",
+			plugin:   &SLOBurnRate{SuccessField: "success", Targets: []float64{0.99}},
+			expected: "'total_field' required",
+		},
+		{
+			name:     "missing targets",
+			plugin:   &SLOBurnRate{SuccessField: "success", TotalField: "total"},
+			expected: "no targets configured",
+		},
+		{
+			name:     "invalid target",
+			plugin:   &SLOBurnRate{SuccessField: "success", TotalField: "total", Targets: []float64{99.9}},
+			expected: "invalid target 99.9",
+		},
+		{
+			name: "invalid window",
+			plugin: &SLOBurnRate{
+				SuccessField: "success",
+				TotalField:   "total",
+				Targets:      []float64{0.99},
+				Windows:      []config.Duration{0},
+			},
+			expected: "invalid window 0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.plugin.Windows == nil {
+				tt.plugin.Windows = []config.Duration{config.Duration(5 * time.Minute)}
+			}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFormatWindow(t *testing.T) {
+	require.Equal(t, "30s", formatWindow(30*time.Second))
+	require.Equal(t, "5m", formatWindow(5*time.Minute))
+	require.Equal(t, "5m30s", formatWindow(5*time.Minute+30*time.Second))
+	require.Equal(t, "1h", formatWindow(time.Hour))
+	require.Equal(t, "1h30m", formatWindow(90*time.Minute))
+	require.Equal(t, "6h", formatWindow(6*time.Hour))
+}
+
+func TestApply(t *testing.T) {
+	plugin := &SLOBurnRate{
+		SuccessField: "success",
+		TotalField:   "total",
+		Targets:      []float64{0.99, 0.9},
+		Windows:      []config.Duration{config.Duration(5 * time.Minute), config.Duration(10 * time.Minute)},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"service": "api"}, map[string]interface{}{"success": int64(0), "total": int64(0)}, start),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.0}, start),
+		metric.New("http", map[string]string{"service": "api"}, map[string]interface{}{"success": int64(90), "total": int64(100)}, start.Add(5*time.Minute)),
+		metric.New("http", map[string]string{"service": "api"}, map[string]interface{}{"success": int64(189), "total": int64(200)}, start.Add(10*time.Minute)),
+		metric.New("http", map[string]string{"service": "api"}, map[string]interface{}{"success": int64(189), "total": int64(200)}, start.Add(15*time.Minute)),
+	}
+
+	expected := []telegraf.Metric{
+		input[0],
+		input[1],
+		input[2],
+		metric.New("http", map[string]string{"service": "api", "slo_target": "0.99"}, map[string]interface{}{"burn_rate_5m": 10.0}, start.Add(5*time.Minute)),
+		metric.New("http", map[string]string{"service": "api", "slo_target": "0.9"}, map[string]interface{}{"burn_rate_5m": 1.0}, start.Add(5*time.Minute)),
+		input[3],
+		metric.New(
+			"http",
+			map[string]string{"service": "api", "slo_target": "0.99"},
+			map[string]interface{}{"burn_rate_5m": 1.0, "burn_rate_10m": 5.5},
+			start.Add(10*time.Minute),
+		),
+		metric.New(
+			"http",
+			map[string]string{"service": "api", "slo_target": "0.9"},
+			map[string]interface{}{"burn_rate_5m": 0.1, "burn_rate_10m": 0.55},
+			start.Add(10*time.Minute),
+		),
+		input[4],
+		metric.New(
+			"http",
+			map[string]string{"service": "api", "slo_target": "0.99"},
+			map[string]interface{}{"burn_rate_5m": 0.0, "burn_rate_10m": 1.0},
+			start.Add(15*time.Minute),
+		),
+		metric.New(
+			"http",
+			map[string]string{"service": "api", "slo_target": "0.9"},
+			map[string]interface{}{"burn_rate_5m": 0.0, "burn_rate_10m": 0.1},
+			start.Add(15*time.Minute),
+		),
+	}
+
+	// Process the metrics one by one like arriving in subsequent intervals
+	var actual []telegraf.Metric
+	for _, m := range input {
+		actual = append(actual, plugin.Apply(m)...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+
+	// Only the samples required for the longest window are kept
+	require.Len(t, plugin.cache, 1)
+	for _, e := range plugin.cache {
+		require.Len(t, e.samples, 3)
+	}
+}
+
+func TestApplyCounterReset(t *testing.T) {
+	plugin := &SLOBurnRate{
+		SuccessField: "success",
+		TotalField:   "total",
+		Targets:      []float64{0.99},
+		Windows:      []config.Duration{config.Duration(time.Minute)},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{}, map[string]interface{}{"success": 1000, "total": 1000}, start),
+		metric.New("http", map[string]string{}, map[string]interface{}{"success": 5, "total": 10}, start.Add(time.Minute)),
+		metric.New("http", map[string]string{}, map[string]interface{}{"success": 104, "total": 110}, start.Add(2*time.Minute)),
+	}
+
+	expected := []telegraf.Metric{
+		input[0],
+		input[1],
+		input[2],
+		metric.New("http", map[string]string{"slo_target": "0.99"}, map[string]interface{}{"burn_rate_1m": 1.0}, start.Add(2*time.Minute)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestApplyExpiry(t *testing.T) {
+	plugin := &SLOBurnRate{
+		SuccessField: "success",
+		TotalField:   "total",
+		Targets:      []float64{0.99},
+		Windows:      []config.Duration{config.Duration(time.Minute)},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	plugin.Apply(
+		metric.New("http", map[string]string{"service": "old"}, map[string]interface{}{"success": 1, "total": 1}, start),
+		metric.New("http", map[string]string{"service": "new"}, map[string]interface{}{"success": 1, "total": 1}, start),
+	)
+	require.Len(t, plugin.cache, 2)
+
+	plugin.Apply(
+		metric.New("http", map[string]string{"service": "new"}, map[string]interface{}{"success": 2, "total": 2}, start.Add(2*time.Minute)),
+	)
+	require.Len(t, plugin.cache, 1)
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -17,6 +18,7 @@ var sampleConfig string
 
 type Split struct {
 	Templates    []template `toml:"template"`
+	Patterns     []pattern  `toml:"pattern"`
 	DropOriginal bool       `toml:"drop_original"`
 }
 
@@ -29,13 +31,23 @@ type template struct {
 	tagFilters   filter.Filter
 }
 
+type pattern struct {
+	Field    string   `toml:"field"`
+	Name     string   `toml:"name"`
+	FieldKey string   `toml:"field_key"`
+	Tags     []string `toml:"tags"`
+
+	fieldRegex *regexp.Regexp
+	tagFilters filter.Filter
+}
+
 func (*Split) SampleConfig() string {
 	return sampleConfig
 }
 
 func (s *Split) Init() error {
-	if len(s.Templates) == 0 {
-		return errors.New("at least one template required")
+	if len(s.Templates) == 0 && len(s.Patterns) == 0 {
+		return errors.New("at least one template or pattern required")
 	}
 
 	for index, template := range s.Templates {
@@ -61,6 +73,29 @@ func (s *Split) Init() error {
 		}
 	}
 
+	for index, pattern := range s.Patterns {
+		if pattern.Field == "" {
+			return errors.New("field pattern cannot be empty")
+		}
+		if pattern.Name == "" {
+			return errors.New("metric name cannot be empty")
+		}
+
+		re, err := regexp.Compile(pattern.Field)
+		if err != nil {
+			return fmt.Errorf("failed to compile field pattern %q: %w", pattern.Field, err)
+		}
+		s.Patterns[index].fieldRegex = re
+
+		if len(pattern.Tags) != 0 {
+			f, err := filter.Compile(pattern.Tags)
+			if err != nil {
+				return fmt.Errorf("failed to create new tag filter: %w", err)
+			}
+			s.Patterns[index].tagFilters = f
+		}
+	}
+
 	return nil
 }
 
@@ -82,28 +117,81 @@ func (s *Split) Apply(in ...telegraf.Metric) []telegraf.Metric {
 				}
 			}
 
-			tags := make(map[string]string, len(point.TagList()))
-			if len(template.Tags) != 0 {
-				for _, tag := range point.TagList() {
-					if template.tagFilters.Match(tag.Key) {
-						tags[tag.Key] = tag.Value
-					}
-				}
-			}
-
 			// metric with no fields should be skipped
 			if len(fields) == 0 {
 				continue
 			}
 
+			tags := selectTags(point, template.tagFilters)
 			m := metric.New(template.Name, tags, fields, point.Time())
 			newMetrics = append(newMetrics, m)
 		}
+
+		for _, pattern := range s.Patterns {
+			newMetrics = append(newMetrics, pattern.apply(point)...)
+		}
 	}
 
 	return newMetrics
 }
 
+// apply groups the fields of the metric matching the pattern by the expanded
+// metric name and returns one metric per group in order of appearance.
+func (p *pattern) apply(point telegraf.Metric) []telegraf.Metric {
+	var names []string
+	groups := make(map[string]map[string]any)
+	for _, field := range point.FieldList() {
+		match := p.fieldRegex.FindStringSubmatchIndex(field.Key)
+		if match == nil {
+			continue
+		}
+
+		name := string(p.fieldRegex.ExpandString(nil, p.Name, field.Key, match))
+		key := field.Key
+		if p.FieldKey != "" {
+			key = string(p.fieldRegex.ExpandString(nil, p.FieldKey, field.Key, match))
+		}
+		// fields resulting in an empty metric name or field key are skipped
+		if name == "" || key == "" {
+			continue
+		}
+
+		fields, found := groups[name]
+		if !found {
+			fields = make(map[string]any)
+			groups[name] = fields
+			names = append(names, name)
+		}
+		fields[key] = field.Value
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := selectTags(point, p.tagFilters)
+	metrics := make([]telegraf.Metric, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, metric.New(name, tags, groups[name], point.Time()))
+	}
+	return metrics
+}
+
+// selectTags returns the tags of the metric matching the filter or no tags if
+// the filter is nil.
+func selectTags(point telegraf.Metric, f filter.Filter) map[string]string {
+	tags := make(map[string]string, len(point.TagList()))
+	if f == nil {
+		return tags
+	}
+	for _, tag := range point.TagList() {
+		if f.Match(tag.Key) {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	return tags
+}
+
 func init() {
 	processors.Add("split", func() telegraf.Processor {
 		return &Split{}
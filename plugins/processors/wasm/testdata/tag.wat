@@ -0,0 +1,184 @@
+;; Processor adding the "wasm=true" tag to all metrics. Metrics with names
+;; starting with "error", "trap", "loop" or "drop" trigger the respective
+;; behavior to test the error handling.
+(module
+  (import "telegraf" "log" (func $log (param i32 i32 i32)))
+  (import "telegraf" "set_error" (func $set_error (param i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 16) "error requested")
+  (data (i32.const 48) ",wasm=true")
+  (data (i32.const 64) "missing settings")
+
+  ;; The input is always placed at offset 1024 and the output directly after
+  ;; the input. Grow the memory to fit the input and the largest output.
+  (func (export "alloc") (param $size i32) (result i32)
+    (local $required i32)
+    local.get $size
+    i32.const 11
+    i32.mul
+    i32.const 1024
+    i32.add
+    local.set $required
+    block $done
+      loop $grow
+        memory.size
+        i32.const 16
+        i32.shl
+        local.get $required
+        i32.ge_u
+        br_if $done
+        i32.const 1
+        memory.grow
+        i32.const -1
+        i32.eq
+        br_if $done
+        br $grow
+      end
+    end
+    i32.const 1024)
+
+  ;; Log the settings and fail if there are none
+  (func (export "init") (param $ptr i32) (param $len i32) (result i32)
+    local.get $len
+    i32.const 2
+    i32.le_u
+    if
+      i32.const 64
+      i32.const 16
+      call $set_error
+      i32.const 1
+      return
+    end
+    i32.const 2
+    local.get $ptr
+    local.get $len
+    call $log
+    i32.const 0)
+
+  (func (export "process") (param $ptr i32) (param $len i32) (result i64)
+    (local $out i32)
+    (local $o i32)
+    (local $i i32)
+    (local $c i32)
+    (local $name i32)
+    local.get $ptr
+    i32.load8_u
+    local.set $c
+
+    ;; "error"
+    local.get $c
+    i32.const 101
+    i32.eq
+    if
+      i32.const 16
+      i32.const 15
+      call $set_error
+      i64.const 0
+      return
+    end
+
+    ;; "trap"
+    local.get $c
+    i32.const 116
+    i32.eq
+    if
+      unreachable
+    end
+
+    ;; "loop"
+    local.get $c
+    i32.const 108
+    i32.eq
+    if
+      loop $forever
+        br $forever
+      end
+    end
+
+    ;; "drop"
+    local.get $c
+    i32.const 100
+    i32.eq
+    if
+      i64.const 0
+      return
+    end
+
+    local.get $ptr
+    local.get $len
+    i32.add
+    local.tee $out
+    local.set $o
+    i32.const 1
+    local.set $name
+    block $done
+      loop $next
+        local.get $i
+        local.get $len
+        i32.ge_u
+        br_if $done
+        local.get $ptr
+        local.get $i
+        i32.add
+        i32.load8_u
+        local.set $c
+
+        ;; Insert the tag at the end of the measurement name
+        local.get $name
+        if
+          local.get $c
+          i32.const 44
+          i32.eq
+          local.get $c
+          i32.const 32
+          i32.eq
+          i32.or
+          if
+            local.get $o
+            i32.const 48
+            i32.const 10
+            memory.copy
+            local.get $o
+            i32.const 10
+            i32.add
+            local.set $o
+            i32.const 0
+            local.set $name
+          end
+        end
+
+        ;; A new line starts with the next measurement name
+        local.get $c
+        i32.const 10
+        i32.eq
+        if
+          i32.const 1
+          local.set $name
+        end
+
+        local.get $o
+        local.get $c
+        i32.store8
+        local.get $o
+        i32.const 1
+        i32.add
+        local.set $o
+        local.get $i
+        i32.const 1
+        i32.add
+        local.set $i
+        br $next
+      end
+    end
+
+    ;; Return the pointer in the upper and the length in the lower 32 bits
+    local.get $out
+    i64.extend_i32_u
+    i64.const 32
+    i64.shl
+    local.get $o
+    local.get $out
+    i32.sub
+    i64.extend_i32_u
+    i64.or)
+)
@@ -0,0 +1,103 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package wasm
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common "github.com/influxdata/telegraf/plugins/common/wasm"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/processors"
+	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WASM struct {
+	Log telegraf.Logger `toml:"-"`
+	common.Config
+
+	module     *common.Module
+	serializer *serializers_influx.Serializer
+	parser     *influx.Parser
+}
+
+func (*WASM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WASM) Init() error {
+	w.serializer = &serializers_influx.Serializer{UintSupport: true}
+	if err := w.serializer.Init(); err != nil {
+		return fmt.Errorf("initializing serializer failed: %w", err)
+	}
+
+	w.parser = &influx.Parser{}
+	if err := w.parser.Init(); err != nil {
+		return fmt.Errorf("initializing parser failed: %w", err)
+	}
+
+	module, err := w.Config.Load(context.Background(), w.Log, "process")
+	if err != nil {
+		return fmt.Errorf("loading module %q failed: %w", w.Module, err)
+	}
+	w.module = module
+
+	return nil
+}
+
+func (*WASM) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (w *WASM) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	input, err := w.serializer.Serialize(m)
+	if err != nil {
+		return fmt.Errorf("serializing metric failed: %w", err)
+	}
+
+	output, err := w.module.Call(context.Background(), "process", input)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := w.parser.Parse(output)
+	if err != nil {
+		return fmt.Errorf("parsing output of module failed: %w", err)
+	}
+
+	// The module returns new metrics so the original metric is either
+	// dropped or replaced by the returned metrics
+	if len(metrics) == 0 {
+		m.Drop()
+		return nil
+	}
+	m.Accept()
+	for _, pm := range metrics {
+		acc.AddMetric(pm)
+	}
+
+	return nil
+}
+
+func (w *WASM) Stop() {
+	if w.module != nil {
+		w.module.Close(context.Background())
+	}
+}
+
+func init() {
+	processors.AddStreaming("wasm", func() telegraf.StreamingProcessor {
+		return &WASM{
+			Config: common.Config{
+				Timeout:     config.Duration(time.Second),
+				MemoryLimit: config.Size(16 * 1024 * 1024),
+			},
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package wasm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common "github.com/influxdata/telegraf/plugins/common/wasm"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   common.Config
+		expected string
+	}{
+		{
+			name:     "missing module",
+			config:   common.Config{Timeout: config.Duration(time.Second)},
+			expected: "no module configured",
+		},
+		{
+			name:     "non-existing module",
+			config:   common.Config{Module: "testdata/missing.wasm", Timeout: config.Duration(time.Second)},
+			expected: "reading module failed",
+		},
+		{
+			name:     "invalid module",
+			config:   common.Config{Module: "testdata/tag.wat", Timeout: config.Duration(time.Second)},
+			expected: "compiling module failed",
+		},
+		{
+			name:     "failing init",
+			config:   common.Config{Module: "testdata/tag.wasm", Timeout: config.Duration(time.Second)},
+			expected: "initializing module failed: missing settings",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &WASM{Config: tt.config, Log: testutil.Logger{}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestProcess(t *testing.T) {
+	plugin := newPlugin(t, time.Second)
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": uint64(1024), "text": "some value"}, time.Unix(1700000000, 0)),
+		metric.New("drop", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "wasm": "true"}, map[string]interface{}{"usage": 42.5}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{"wasm": "true"}, map[string]interface{}{"free": uint64(1024), "text": "some value"}, time.Unix(1700000000, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestProcessFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "error",
+			expected: "error requested",
+		},
+		{
+			name:     "trap",
+			expected: `calling "process" failed`,
+		},
+		{
+			name:     "loop",
+			expected: `calling "process" failed`,
+		},
+	}
+
+	plugin := newPlugin(t, 100*time.Millisecond)
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New(tt.name, map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0))
+			require.ErrorContains(t, plugin.Add(m, &acc), tt.expected)
+
+			// The module must recover from the failure
+			m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0))
+			require.NoError(t, plugin.Add(m, &acc))
+		})
+	}
+	require.Len(t, acc.GetTelegrafMetrics(), len(tests))
+}
+
+func TestTracking(t *testing.T) {
+	plugin := newPlugin(t, time.Second)
+	defer plugin.Stop()
+
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, name := range []string{"cpu", "drop"} {
+		m := metric.New(name, map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0))
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+
+	require.Len(t, delivered, 2)
+	for _, di := range delivered {
+		require.True(t, di.Delivered())
+	}
+}
+
+func newPlugin(t *testing.T, timeout time.Duration) *WASM {
+	plugin := &WASM{
+		Config: common.Config{
+			Module:      "testdata/tag.wasm",
+			Settings:    map[string]string{"mode": "test"},
+			Timeout:     config.Duration(timeout),
+			MemoryLimit: config.Size(1024 * 1024),
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	return plugin
+}
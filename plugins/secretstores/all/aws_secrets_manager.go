@@ -0,0 +1,5 @@
+//go:build !custom || secretstores || secretstores.aws_secrets_manager
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/secretstores/aws_secrets_manager" // register plugin
@@ -0,0 +1,184 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package aws_secrets_manager
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Key of the secret value for secrets not containing a JSON object
+const valueKey = "value"
+
+type AWSSecretsManager struct {
+	SecretID        string          `toml:"secret_id"`
+	VersionStage    string          `toml:"version_stage"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+	common_aws.CredentialConfig
+
+	client *secretsmanager.Client
+
+	// Cached secret data
+	cache   map[string]string
+	fetched time.Time
+	sync.Mutex
+}
+
+func (*AWSSecretsManager) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AWSSecretsManager) Init() error {
+	if a.SecretID == "" {
+		return errors.New("'secret_id' required")
+	}
+
+	cfg, err := a.CredentialConfig.Credentials()
+	if err != nil {
+		return fmt.Errorf("getting credentials failed: %w", err)
+	}
+	a.client = secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if a.EndpointURL != "" {
+			o.BaseEndpoint = &a.EndpointURL
+		}
+	})
+
+	return nil
+}
+
+// Get searches for the given key and return the secret
+func (a *AWSSecretsManager) Get(key string) ([]byte, error) {
+	data, err := a.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	value, found := data[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+	return []byte(value), nil
+}
+
+// Set sets the given secret for the given key
+func (*AWSSecretsManager) Set(_, _ string) error {
+	return errors.New("setting secrets not supported")
+}
+
+// List lists all known secret keys
+func (a *AWSSecretsManager) List() ([]string, error) {
+	data, err := a.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// GetResolver returns a function to resolve the given key.
+func (a *AWSSecretsManager) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	// Fail early if the secret cannot be read
+	if _, err := a.secret(); err != nil {
+		return nil, err
+	}
+
+	// Secrets are re-read periodically to pick up rotated values
+	dynamic := a.RefreshInterval > 0
+	resolver := func() ([]byte, bool, error) {
+		s, err := a.Get(key)
+		return s, dynamic, err
+	}
+	return resolver, nil
+}
+
+// secret returns the data of the configured secret, reading it from AWS if
+// the cached data is outdated. If reading fails, the previously read data is
+// used to bridge temporary outages.
+func (a *AWSSecretsManager) secret() (map[string]string, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.cache != nil && (a.RefreshInterval <= 0 || time.Since(a.fetched) < time.Duration(a.RefreshInterval)) {
+		return a.cache, nil
+	}
+
+	data, err := a.read()
+	if err != nil {
+		if a.cache == nil {
+			return nil, err
+		}
+		a.Log.Warnf("Refreshing secret failed, using previous data: %v", err)
+		return a.cache, nil
+	}
+	a.cache = data
+	a.fetched = time.Now()
+
+	return a.cache, nil
+}
+
+func (a *AWSSecretsManager) read() (map[string]string, error) {
+	ctx := context.Background()
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(a.Timeout))
+		defer cancel()
+	}
+
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(a.SecretID)}
+	if a.VersionStage != "" {
+		input.VersionStage = aws.String(a.VersionStage)
+	}
+	output, err := a.client.GetSecretValue(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("reading secret %q failed: %w", a.SecretID, err)
+	}
+
+	if output.SecretString == nil {
+		return map[string]string{valueKey: string(output.SecretBinary)}, nil
+	}
+
+	// Secrets containing a JSON object provide each field as a key, values
+	// other than strings are kept in their JSON representation
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*output.SecretString), &raw); err != nil || raw == nil {
+		return map[string]string{valueKey: *output.SecretString}, nil
+	}
+	data := make(map[string]string, len(raw))
+	for k, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		data[k] = s
+	}
+	return data, nil
+}
+
+func init() {
+	secretstores.Add("aws_secrets_manager", func(string) telegraf.SecretStore {
+		return &AWSSecretsManager{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
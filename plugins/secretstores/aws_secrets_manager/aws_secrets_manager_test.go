@@ -0,0 +1,177 @@
+package aws_secrets_manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &AWSSecretsManager{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &AWSSecretsManager{}
+	require.ErrorContains(t, plugin.Init(), "'secret_id' required")
+}
+
+func TestSetNotSupported(t *testing.T) {
+	plugin := &AWSSecretsManager{}
+	require.ErrorContains(t, plugin.Set("key", "value"), "not supported")
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		expected map[string]string
+	}{
+		{
+			name:   "json object",
+			secret: `{"username": "telegraf", "password": "s3cr3t", "port": 5432}`,
+			expected: map[string]string{
+				"username": "telegraf",
+				"password": "s3cr3t",
+				"port":     "5432",
+			},
+		},
+		{
+			name:     "plain string",
+			secret:   "s3cr3t",
+			expected: map[string]string{"value": "s3cr3t"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, func() string { return tt.secret }, nil)
+			defer server.Close()
+
+			plugin := newPlugin(server.URL)
+			require.NoError(t, plugin.Init())
+
+			keys, err := plugin.List()
+			require.NoError(t, err)
+			require.Len(t, keys, len(tt.expected))
+
+			for k, v := range tt.expected {
+				secret, err := plugin.Get(k)
+				require.NoError(t, err)
+				require.Equal(t, v, string(secret))
+			}
+
+			_, err = plugin.Get("missing")
+			require.ErrorContains(t, err, "not found")
+		})
+	}
+}
+
+func TestGetError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte(`{"__type": "ResourceNotFoundException", "Message": "Secrets Manager can't find the specified secret."}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.GetResolver("password")
+	require.ErrorContains(t, err, "ResourceNotFoundException")
+}
+
+func TestResolverRotation(t *testing.T) {
+	var password atomic.Value
+	password.Store("first")
+	var fail atomic.Bool
+	server := newServer(t, func() string { return `{"password": "` + password.Load().(string) + `"}` }, &fail)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	plugin.RefreshInterval = config.Duration(50 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("password")
+	require.NoError(t, err)
+
+	secret, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.True(t, dynamic)
+	require.Equal(t, "first", string(secret))
+
+	// The rotated secret is picked up after the refresh interval
+	password.Store("second")
+	require.Eventually(t, func() bool {
+		secret, _, err := resolver()
+		return err == nil && string(secret) == "second"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Keep the previous value if the service is not available
+	fail.Store(true)
+	time.Sleep(100 * time.Millisecond)
+	secret, _, err = resolver()
+	require.NoError(t, err)
+	require.Equal(t, "second", string(secret))
+}
+
+func newPlugin(endpoint string) *AWSSecretsManager {
+	return &AWSSecretsManager{
+		SecretID:     "prod/telegraf",
+		VersionStage: "AWSCURRENT",
+		Timeout:      config.Duration(5 * time.Second),
+		Log:          testutil.Logger{},
+		CredentialConfig: common_aws.CredentialConfig{
+			Region:      "us-east-1",
+			AccessKey:   "dummy",
+			SecretKey:   "dummy",
+			EndpointURL: endpoint,
+		},
+	}
+}
+
+func newServer(t *testing.T, secret func() string, fail *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail != nil && fail.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var request map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if request["SecretId"] != "prod/telegraf" || request["VersionStage"] != "AWSCURRENT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]string{
+			"ARN":          "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/telegraf-AbCdEf",
+			"Name":         "prod/telegraf",
+			"SecretString": secret(),
+			"VersionId":    "a1b2c3d4",
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+}
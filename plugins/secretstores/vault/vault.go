@@ -0,0 +1,321 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package vault
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Renew the AppRole token if it expires within this margin
+const tokenExpiryMargin = 10 * time.Second
+
+type Vault struct {
+	Address         string          `toml:"address"`
+	Namespace       string          `toml:"namespace"`
+	Mount           string          `toml:"mount"`
+	Path            string          `toml:"path"`
+	KVVersion       int             `toml:"kv_version"`
+	Token           config.Secret   `toml:"token"`
+	AppRoleMount    string          `toml:"approle_mount"`
+	RoleID          config.Secret   `toml:"role_id"`
+	SecretID        config.Secret   `toml:"secret_id"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Log             telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+
+	// Cached secret data and the AppRole login token
+	cache       map[string]string
+	fetched     time.Time
+	token       string
+	tokenExpiry time.Time
+	sync.Mutex
+}
+
+func (*Vault) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *Vault) Init() error {
+	if v.Address == "" {
+		return errors.New("'address' required")
+	}
+	v.Address = strings.TrimSuffix(v.Address, "/")
+
+	if v.Path == "" {
+		return errors.New("'path' required")
+	}
+	v.Path = strings.Trim(v.Path, "/")
+	v.Mount = strings.Trim(v.Mount, "/")
+	v.AppRoleMount = strings.Trim(v.AppRoleMount, "/")
+
+	switch v.KVVersion {
+	case 1, 2:
+	default:
+		return fmt.Errorf("invalid 'kv_version' %d", v.KVVersion)
+	}
+
+	if v.Token.Empty() && v.RoleID.Empty() {
+		return errors.New("either 'token' or 'role_id' required")
+	}
+	if !v.Token.Empty() && !v.RoleID.Empty() {
+		return errors.New("'token' and 'role_id' are mutually exclusive")
+	}
+
+	client, err := v.HTTPClientConfig.CreateClient(context.Background(), v.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	v.client = client
+
+	return nil
+}
+
+// Get searches for the given key and return the secret
+func (v *Vault) Get(key string) ([]byte, error) {
+	data, err := v.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	value, found := data[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+	return []byte(value), nil
+}
+
+// Set sets the given secret for the given key
+func (*Vault) Set(_, _ string) error {
+	return errors.New("setting secrets not supported")
+}
+
+// List lists all known secret keys
+func (v *Vault) List() ([]string, error) {
+	data, err := v.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// GetResolver returns a function to resolve the given key.
+func (v *Vault) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	// Fail early if the secret cannot be read
+	if _, err := v.secret(); err != nil {
+		return nil, err
+	}
+
+	// Secrets are re-read periodically to pick up rotated values
+	dynamic := v.RefreshInterval > 0
+	resolver := func() ([]byte, bool, error) {
+		s, err := v.Get(key)
+		return s, dynamic, err
+	}
+	return resolver, nil
+}
+
+// secret returns the data of the configured secret, reading it from Vault if
+// the cached data is outdated. If reading fails, the previously read data is
+// used to bridge temporary outages of Vault.
+func (v *Vault) secret() (map[string]string, error) {
+	v.Lock()
+	defer v.Unlock()
+
+	if v.cache != nil && (v.RefreshInterval <= 0 || time.Since(v.fetched) < time.Duration(v.RefreshInterval)) {
+		return v.cache, nil
+	}
+
+	data, err := v.read()
+	if err != nil {
+		if v.cache == nil {
+			return nil, err
+		}
+		v.Log.Warnf("Refreshing secret failed, using previous data: %v", err)
+		return v.cache, nil
+	}
+	v.cache = data
+	v.fetched = time.Now()
+
+	return v.cache, nil
+}
+
+func (v *Vault) read() (map[string]string, error) {
+	token, err := v.authToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	switch v.KVVersion {
+	case 1:
+		var response struct {
+			Data map[string]json.RawMessage `json:"data"`
+		}
+		if err := v.request(http.MethodGet, v.Mount+"/"+v.Path, token, nil, &response); err != nil {
+			return nil, err
+		}
+		raw = response.Data
+	case 2:
+		var response struct {
+			Data struct {
+				Data map[string]json.RawMessage `json:"data"`
+			} `json:"data"`
+		}
+		if err := v.request(http.MethodGet, v.Mount+"/data/"+v.Path, token, nil, &response); err != nil {
+			return nil, err
+		}
+		raw = response.Data.Data
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("secret %q has no data", v.Path)
+	}
+
+	// Non-string values are kept in their JSON representation
+	data := make(map[string]string, len(raw))
+	for k, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		data[k] = s
+	}
+	return data, nil
+}
+
+func (v *Vault) request(method, path, token string, body io.Reader, out interface{}) error {
+	request, err := http.NewRequest(method, v.Address+"/v1/"+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	if token != "" {
+		request.Header.Set("X-Vault-Token", token)
+	}
+	if v.Namespace != "" {
+		request.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("executing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Force a new login in case the token was revoked
+		if resp.StatusCode == http.StatusForbidden && !v.RoleID.Empty() {
+			v.token = ""
+		}
+
+		var response struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && len(response.Errors) > 0 {
+			return fmt.Errorf("reading %q failed with status %s: %s", path, resp.Status, strings.Join(response.Errors, "; "))
+		}
+		return fmt.Errorf("reading %q failed with status %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+// authToken returns the token to use for the requests, logging in via AppRole
+// if required.
+func (v *Vault) authToken() (string, error) {
+	if !v.Token.Empty() {
+		token, err := v.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		defer token.Destroy()
+		return token.String(), nil
+	}
+
+	if v.token != "" && (v.tokenExpiry.IsZero() || time.Until(v.tokenExpiry) > tokenExpiryMargin) {
+		return v.token, nil
+	}
+
+	roleID, err := v.RoleID.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting role ID failed: %w", err)
+	}
+	defer roleID.Destroy()
+	login := map[string]string{"role_id": roleID.String()}
+
+	if !v.SecretID.Empty() {
+		secretID, err := v.SecretID.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting secret ID failed: %w", err)
+		}
+		defer secretID.Destroy()
+		login["secret_id"] = secretID.String()
+	}
+
+	body, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := v.request(http.MethodPost, "auth/"+v.AppRoleMount+"/login", "", bytes.NewReader(body), &response); err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", errors.New("login failed: no token received")
+	}
+
+	v.token = response.Auth.ClientToken
+	v.tokenExpiry = time.Time{}
+	if response.Auth.LeaseDuration > 0 {
+		v.tokenExpiry = time.Now().Add(time.Duration(response.Auth.LeaseDuration) * time.Second)
+	}
+
+	return v.token, nil
+}
+
+func init() {
+	secretstores.Add("vault", func(string) telegraf.SecretStore {
+		return &Vault{
+			Mount:        "secret",
+			KVVersion:    2,
+			AppRoleMount: "approle",
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
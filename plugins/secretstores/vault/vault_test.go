@@ -0,0 +1,298 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &Vault{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Vault
+		expected string
+	}{
+		{
+			name:     "missing address",
+			plugin:   &Vault{Path: "telegraf", KVVersion: 2, Token: config.NewSecret([]byte("token"))},
+			expected: "'address' required",
+		},
+		{
+			name:     "missing path",
+			plugin:   &Vault{Address: "http://localhost:8200", KVVersion: 2, Token: config.NewSecret([]byte("token"))},
+			expected: "'path' required",
+		},
+		{
+			name:     "invalid version",
+			plugin:   &Vault{Address: "http://localhost:8200", Path: "telegraf", KVVersion: 3, Token: config.NewSecret([]byte("token"))},
+			expected: "invalid 'kv_version' 3",
+		},
+		{
+			name:     "missing credentials",
+			plugin:   &Vault{Address: "http://localhost:8200", Path: "telegraf", KVVersion: 2},
+			expected: "either 'token' or 'role_id' required",
+		},
+		{
+			name: "token and approle",
+			plugin: &Vault{
+				Address:   "http://localhost:8200",
+				Path:      "telegraf",
+				KVVersion: 2,
+				Token:     config.NewSecret([]byte("token")),
+				RoleID:    config.NewSecret([]byte("role")),
+			},
+			expected: "'token' and 'role_id' are mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSetNotSupported(t *testing.T) {
+	plugin := &Vault{}
+	require.ErrorContains(t, plugin.Set("key", "value"), "not supported")
+}
+
+func TestGetKV(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		path     string
+		response string
+	}{
+		{
+			name:     "version 1",
+			version:  1,
+			path:     "/v1/kv/telegraf/db",
+			response: `{"data": {"username": "telegraf", "password": "s3cr3t", "port": 5432}}`,
+		},
+		{
+			name:     "version 2",
+			version:  2,
+			path:     "/v1/kv/data/telegraf/db",
+			response: `{"data": {"data": {"username": "telegraf", "password": "s3cr3t", "port": 5432}, "metadata": {"version": 3}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path || r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "ns1" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				if _, err := w.Write([]byte(tt.response)); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+				}
+			}))
+			defer server.Close()
+
+			plugin := &Vault{
+				Address:   server.URL,
+				Namespace: "ns1",
+				Mount:     "kv",
+				Path:      "/telegraf/db",
+				KVVersion: tt.version,
+				Token:     config.NewSecret([]byte("root")),
+				Log:       testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			keys, err := plugin.List()
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"username", "password", "port"}, keys)
+
+			secret, err := plugin.Get("password")
+			require.NoError(t, err)
+			require.Equal(t, "s3cr3t", string(secret))
+
+			secret, err = plugin.Get("port")
+			require.NoError(t, err)
+			require.Equal(t, "5432", string(secret))
+
+			_, err = plugin.Get("missing")
+			require.ErrorContains(t, err, "not found")
+		})
+	}
+}
+
+func TestGetError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		if _, err := w.Write([]byte(`{"errors": ["permission denied"]}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:   server.URL,
+		Mount:     "secret",
+		Path:      "telegraf",
+		KVVersion: 2,
+		Token:     config.NewSecret([]byte("root")),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.GetResolver("password")
+	require.ErrorContains(t, err, "403 Forbidden: permission denied")
+}
+
+func TestAppRole(t *testing.T) {
+	var logins atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var login map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if login["role_id"] != "telegraf" || login["secret_id"] != "d3adb33f" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			logins.Add(1)
+			response = `{"auth": {"client_token": "s.login", "lease_duration": 3600}}`
+		case "/v1/secret/data/telegraf":
+			if r.Header.Get("X-Vault-Token") != "s.login" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			response = `{"data": {"data": {"password": "s3cr3t"}}}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:         server.URL,
+		Mount:           "secret",
+		Path:            "telegraf",
+		KVVersion:       2,
+		AppRoleMount:    "approle",
+		RoleID:          config.NewSecret([]byte("telegraf")),
+		SecretID:        config.NewSecret([]byte("d3adb33f")),
+		RefreshInterval: config.Duration(time.Nanosecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for range 3 {
+		secret, err := plugin.Get("password")
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", string(secret))
+	}
+
+	// The login token is reused until it expires
+	require.Equal(t, int64(1), logins.Load())
+}
+
+func TestResolverRotation(t *testing.T) {
+	var password atomic.Value
+	password.Store("first")
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		response := map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]string{"password": password.Load().(string)},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:         server.URL,
+		Mount:           "secret",
+		Path:            "telegraf",
+		KVVersion:       2,
+		Token:           config.NewSecret([]byte("root")),
+		RefreshInterval: config.Duration(50 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("password")
+	require.NoError(t, err)
+
+	secret, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.True(t, dynamic)
+	require.Equal(t, "first", string(secret))
+
+	// The rotated secret is picked up after the refresh interval
+	password.Store("second")
+	secret, _, err = resolver()
+	require.NoError(t, err)
+	require.Equal(t, "first", string(secret))
+	require.Eventually(t, func() bool {
+		secret, _, err := resolver()
+		return err == nil && string(secret) == "second"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Keep the previous value if Vault is not available
+	fail.Store(true)
+	time.Sleep(100 * time.Millisecond)
+	secret, _, err = resolver()
+	require.NoError(t, err)
+	require.Equal(t, "second", string(secret))
+}
+
+func TestResolverStatic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write([]byte(`{"data": {"data": {"password": "s3cr3t"}}}`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:   server.URL,
+		Mount:     "secret",
+		Path:      "telegraf",
+		KVVersion: 2,
+		Token:     config.NewSecret([]byte("root")),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("password")
+	require.NoError(t, err)
+	secret, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.False(t, dynamic)
+	require.Equal(t, "s3cr3t", string(secret))
+}
@@ -0,0 +1,471 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// If SchemaRegistry is set, the schema is registered in the registry and the
+// output is written in the Confluent Wire Format
+// (https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format).
+
+// If Schema is set, the metrics are serialized using the given schema,
+// otherwise a schema is derived from the metric's tags and fields.
+
+type Serializer struct {
+	Schema           string `toml:"avro_schema"`
+	SchemaRegistry   string `toml:"avro_schema_registry"`
+	CaCertPath       string `toml:"avro_schema_registry_cert"`
+	Subject          string `toml:"avro_subject"`
+	Format           string `toml:"avro_format"`
+	Namespace        string `toml:"avro_namespace"`
+	MeasurementField string `toml:"avro_measurement_field"`
+	Timestamp        string `toml:"avro_timestamp"`
+	TimestampFormat  string `toml:"avro_timestamp_format"`
+
+	registry *schemaregistry.Registry
+	static   *schema
+	cache    map[string]*schema
+	mu       sync.Mutex
+}
+
+type schema struct {
+	text   string
+	codec  *goavro.Codec
+	fields []schemaField
+}
+
+type schemaField struct {
+	name string
+	typ  interface{}
+	kind string
+	key  string
+}
+
+func (s *Serializer) Init() error {
+	switch s.Format {
+	case "":
+		s.Format = "binary"
+	case "binary", "json":
+		// Do nothing as those are valid settings
+	default:
+		return fmt.Errorf("unknown 'avro_format' %q", s.Format)
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix"
+	case "unix", "unix_ns", "unix_us", "unix_ms":
+		// Valid values
+	default:
+		return fmt.Errorf("invalid timestamp format %q", s.TimestampFormat)
+	}
+
+	if s.MeasurementField == "" {
+		s.MeasurementField = "measurement"
+	}
+	if s.Timestamp == "" {
+		s.Timestamp = "timestamp"
+	}
+
+	if s.SchemaRegistry != "" {
+		if s.Format != "binary" {
+			return errors.New("the schema registry requires the 'binary' format")
+		}
+		registry, err := schemaregistry.New(s.SchemaRegistry, s.CaCertPath)
+		if err != nil {
+			return fmt.Errorf("error connecting to the schema registry %q: %w", s.SchemaRegistry, err)
+		}
+		s.registry = registry
+	}
+
+	if s.Schema != "" {
+		sc, err := parseSchema(s.Schema)
+		if err != nil {
+			return err
+		}
+		s.static = sc
+	}
+	s.cache = make(map[string]*schema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	sc, err := s.schemaFor(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	datum, err := s.datum(sc, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Format == "json" {
+		buf, err := sc.codec.TextualFromNative(nil, datum)
+		if err != nil {
+			return nil, err
+		}
+		return append(buf, '\n'), nil
+	}
+
+	// Prepend the Confluent wire format header if using a registry
+	var buf []byte
+	if s.registry != nil {
+		subject := s.Subject
+		if subject == "" {
+			subject = metric.Name() + "-value"
+		}
+		id, err := s.registry.Register(subject, sc.text)
+		if err != nil {
+			return nil, fmt.Errorf("registering schema failed: %w", err)
+		}
+		buf = make([]byte, 5)
+		binary.BigEndian.PutUint32(buf[1:], uint32(id))
+	}
+
+	return sc.codec.BinaryFromNative(buf, datum)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var batch []byte
+	for _, m := range metrics {
+		buf, err := s.Serialize(m)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, buf...)
+	}
+	return batch, nil
+}
+
+func (s *Serializer) schemaFor(metric telegraf.Metric) (*schema, error) {
+	if s.static != nil {
+		return s.static, nil
+	}
+
+	// Derive the schema from the metric's name, tags and field types
+	tags := metric.TagList()
+	// Sort a copy as the field list is shared with the metric
+	fields := append([]*telegraf.Field(nil), metric.FieldList()...)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	var signature strings.Builder
+	signature.WriteString(metric.Name())
+	for _, tag := range tags {
+		signature.WriteString("\x00t" + tag.Key)
+	}
+	for _, field := range fields {
+		fmt.Fprintf(&signature, "\x00f%s:%T", field.Key, field.Value)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sc, found := s.cache[signature.String()]; found {
+		return sc, nil
+	}
+
+	specFields := []map[string]interface{}{
+		{"name": sanitize(s.Timestamp), "type": "long"},
+		{"name": sanitize(s.MeasurementField), "type": "string"},
+	}
+	sfields := []schemaField{
+		{name: sanitize(s.Timestamp), typ: "long", kind: "timestamp"},
+		{name: sanitize(s.MeasurementField), typ: "string", kind: "measurement"},
+	}
+	for _, tag := range tags {
+		typ := []interface{}{"null", "string"}
+		specFields = append(specFields, map[string]interface{}{"name": sanitize(tag.Key), "type": typ, "default": nil})
+		sfields = append(sfields, schemaField{name: sanitize(tag.Key), typ: typ, kind: "tag", key: tag.Key})
+	}
+	for _, field := range fields {
+		var avroType string
+		switch field.Value.(type) {
+		case int64, uint64:
+			avroType = "long"
+		case float64:
+			avroType = "double"
+		case bool:
+			avroType = "boolean"
+		case string:
+			avroType = "string"
+		default:
+			return nil, fmt.Errorf("unsupported type %T of field %q", field.Value, field.Key)
+		}
+		typ := []interface{}{"null", avroType}
+		specFields = append(specFields, map[string]interface{}{"name": sanitize(field.Key), "type": typ, "default": nil})
+		sfields = append(sfields, schemaField{name: sanitize(field.Key), typ: typ, kind: "field", key: field.Key})
+	}
+
+	spec := map[string]interface{}{
+		"type":   "record",
+		"name":   sanitize(metric.Name()),
+		"fields": specFields,
+	}
+	if s.Namespace != "" {
+		spec["namespace"] = s.Namespace
+	}
+	text, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for derived schema failed: %w", err)
+	}
+
+	sc := &schema{text: string(text), codec: codec, fields: sfields}
+	s.cache[signature.String()] = sc
+	return sc, nil
+}
+
+func (s *Serializer) datum(sc *schema, metric telegraf.Metric) (map[string]interface{}, error) {
+	datum := make(map[string]interface{}, len(sc.fields))
+	for _, f := range sc.fields {
+		kind, key := f.kind, f.key
+		if kind == "" {
+			// Resolve the fields of user-provided schemas by name
+			switch f.name {
+			case s.Timestamp:
+				kind = "timestamp"
+			case s.MeasurementField:
+				kind = "measurement"
+			default:
+				kind, key = "field", f.name
+				if metric.HasTag(f.name) {
+					kind = "tag"
+				}
+			}
+		}
+
+		var value interface{}
+		switch kind {
+		case "timestamp":
+			if isTimestampType(f.typ) {
+				datum[f.name] = metric.Time()
+				continue
+			}
+			value = s.timestamp(metric.Time())
+		case "measurement":
+			value = metric.Name()
+		case "tag":
+			v, found := metric.GetTag(key)
+			if !found {
+				continue
+			}
+			value = v
+		case "field":
+			v, found := metric.GetField(key)
+			if !found {
+				continue
+			}
+			value = v
+		}
+
+		converted, err := convert(f.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("converting %q failed: %w", f.name, err)
+		}
+		datum[f.name] = converted
+	}
+	return datum, nil
+}
+
+func (s *Serializer) timestamp(t time.Time) int64 {
+	switch s.TimestampFormat {
+	case "unix_ms":
+		return t.UnixMilli()
+	case "unix_us":
+		return t.UnixMicro()
+	case "unix_ns":
+		return t.UnixNano()
+	}
+	return t.Unix()
+}
+
+func parseSchema(text string) (*schema, error) {
+	var spec struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name string      `json:"name"`
+			Type interface{} `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(text), &spec); err != nil {
+		return nil, fmt.Errorf("parsing schema failed: %w", err)
+	}
+	if spec.Type != "record" {
+		return nil, fmt.Errorf("schema must be of type 'record' but is %q", spec.Type)
+	}
+
+	codec, err := goavro.NewCodec(text)
+	if err != nil {
+		return nil, fmt.Errorf("creating codec failed: %w", err)
+	}
+
+	fields := make([]schemaField, 0, len(spec.Fields))
+	for _, f := range spec.Fields {
+		fields = append(fields, schemaField{name: f.Name, typ: f.Type})
+	}
+	return &schema{text: text, codec: codec, fields: fields}, nil
+}
+
+// convert transforms the given value to the native Go type expected by the
+// codec for the given Avro type
+func convert(typ, value interface{}) (interface{}, error) {
+	switch t := typ.(type) {
+	case string:
+		return convertPrimitive(t, value)
+	case map[string]interface{}:
+		name, ok := t["type"].(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported complex type %v", t["type"])
+		}
+		return convertPrimitive(name, value)
+	case []interface{}:
+		if value == nil {
+			for _, branch := range t {
+				if branch == "null" {
+					return nil, nil
+				}
+			}
+			return nil, errors.New("missing value for non-nullable union")
+		}
+		for _, branch := range t {
+			name := typeName(branch)
+			if name == "null" {
+				continue
+			}
+			if v, err := convert(branch, value); err == nil {
+				return goavro.Union(name, v), nil
+			}
+		}
+		return nil, fmt.Errorf("no union type matching %T", value)
+	}
+	return nil, fmt.Errorf("unsupported type %v", typ)
+}
+
+func convertPrimitive(typ string, value interface{}) (interface{}, error) {
+	switch typ {
+	case "null":
+		if value == nil {
+			return nil, nil
+		}
+	case "boolean":
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case "string":
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case "bytes":
+		if v, ok := value.(string); ok {
+			return []byte(v), nil
+		}
+	case "long":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case uint64:
+			// Clamp unsigned values exceeding the range of long
+			if v > math.MaxInt64 {
+				return int64(math.MaxInt64), nil
+			}
+			return int64(v), nil
+		}
+	case "int":
+		switch v := value.(type) {
+		case int64:
+			if v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int32(v), nil
+			}
+		case uint64:
+			if v <= math.MaxInt32 {
+				return int32(v), nil
+			}
+		}
+	case "double":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		}
+	case "float":
+		switch v := value.(type) {
+		case float64:
+			return float32(v), nil
+		case int64:
+			return float32(v), nil
+		case uint64:
+			return float32(v), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+	return nil, fmt.Errorf("cannot convert %T to %q", value, typ)
+}
+
+func typeName(typ interface{}) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if name, ok := t["type"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func isTimestampType(typ interface{}) bool {
+	if t, ok := typ.(map[string]interface{}); ok {
+		lt, _ := t["logicalType"].(string)
+		return strings.HasPrefix(lt, "timestamp-")
+	}
+	return false
+}
+
+// sanitize converts the given name to a valid Avro name
+func sanitize(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func init() {
+	serializers.Add("avro",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
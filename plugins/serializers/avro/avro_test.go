@@ -0,0 +1,262 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestSerializeDerivedSchema(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01", "cpu-id": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"count":      int64(42),
+			"online":     true,
+			"state":      "ok",
+		},
+		time.Unix(1700000000, 0),
+	)
+
+	s := &Serializer{Namespace: "telegraf"}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	sc, err := s.schemaFor(m)
+	require.NoError(t, err)
+	require.Contains(t, sc.text, `"namespace":"telegraf"`)
+	require.Contains(t, sc.text, `"name":"cpu_id"`)
+
+	native, remaining, err := sc.codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	expected := map[string]interface{}{
+		"timestamp":   int64(1700000000),
+		"measurement": "cpu",
+		"cpu_id":      map[string]interface{}{"string": "cpu0"},
+		"host":        map[string]interface{}{"string": "server01"},
+		"count":       map[string]interface{}{"long": int64(42)},
+		"online":      map[string]interface{}{"boolean": true},
+		"state":       map[string]interface{}{"string": "ok"},
+		"usage_idle":  map[string]interface{}{"double": 91.5},
+	}
+	require.Equal(t, expected, native)
+}
+
+func TestSerializeKeepsFieldOrder(t *testing.T) {
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_user": 1.5}, time.Unix(1700000000, 0))
+	m.AddField("usage_idle", 91.5)
+	m.AddField("count", int64(42))
+	before := make([]string, 0, len(m.FieldList()))
+	for _, f := range m.FieldList() {
+		before = append(before, f.Key)
+	}
+
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+	_, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	after := make([]string, 0, len(m.FieldList()))
+	for _, f := range m.FieldList() {
+		after = append(after, f.Key)
+	}
+	require.Equal(t, before, after)
+}
+
+func TestSerializeLargeUnsigned(t *testing.T) {
+	m := metric.New(
+		"disk",
+		map[string]string{},
+		map[string]interface{}{"small": uint64(42), "large": uint64(math.MaxUint64)},
+		time.Unix(1700000000, 0),
+	)
+
+	s := &Serializer{Format: "json"}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `"large":{"long":9223372036854775807}`)
+	require.Contains(t, string(buf), `"small":{"long":42}`)
+}
+
+func TestSerializeUserSchema(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "cpu",
+		"fields": [
+			{"name": "ts", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "host", "type": "string"},
+			{"name": "usage_idle", "type": "float"},
+			{"name": "count", "type": ["null", "int"], "default": null},
+			{"name": "missing", "type": ["null", "string"], "default": null}
+		]
+	}`
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{"usage_idle": 91.5, "count": int64(42)},
+		time.UnixMilli(1700000000123),
+	)
+
+	s := &Serializer{
+		Schema:    schema,
+		Timestamp: "ts",
+		Format:    "json",
+	}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf, &actual))
+	expected := map[string]interface{}{
+		"ts":         float64(1700000000123),
+		"host":       "server01",
+		"usage_idle": 91.5,
+		"count":      map[string]interface{}{"int": float64(42)},
+		"missing":    nil,
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestSerializeUserSchemaMismatch(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "cpu",
+		"fields": [
+			{"name": "timestamp", "type": "long"},
+			{"name": "usage_idle", "type": "boolean"}
+		]
+	}`
+	m := metric.New("cpu", nil, map[string]interface{}{"usage_idle": 91.5}, time.Unix(0, 0))
+
+	s := &Serializer{Schema: schema}
+	require.NoError(t, s.Init())
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, `converting "usage_idle" failed`)
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	var registrations atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/subjects/metrics-value/versions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["schema"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		registrations.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: strings.Replace(server.URL, "http://", "http://user:secret@", 1),
+		Subject:        "metrics-value",
+	}
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", nil, map[string]interface{}{"value": int64(1)}, time.Unix(1, 0)),
+		metric.New("cpu", nil, map[string]interface{}{"value": int64(2)}, time.Unix(2, 0)),
+	}
+	for _, m := range metrics {
+		buf, err := s.Serialize(m)
+		require.NoError(t, err)
+		require.Equal(t, byte(0), buf[0])
+		require.Equal(t, uint32(42), binary.BigEndian.Uint32(buf[1:5]))
+
+		sc, err := s.schemaFor(m)
+		require.NoError(t, err)
+		native, _, err := sc.codec.NativeFromBinary(buf[5:])
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"long": m.Fields()["value"]}, native.(map[string]interface{})["value"])
+	}
+
+	// The schema should only be registered once
+	require.Equal(t, int32(1), registrations.Load())
+}
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+		metric.New("cpu", nil, map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+	}
+
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	sc, err := s.schemaFor(metrics[0])
+	require.NoError(t, err)
+	for _, m := range metrics {
+		var native interface{}
+		native, buf, err = sc.codec.NativeFromBinary(buf)
+		require.NoError(t, err)
+		require.Equal(t, m.Time().Unix(), native.(map[string]interface{})["timestamp"])
+	}
+	require.Empty(t, buf)
+}
+
+func TestInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Serializer
+		expected string
+	}{
+		{
+			name:     "invalid format",
+			plugin:   &Serializer{Format: "xml"},
+			expected: `unknown 'avro_format' "xml"`,
+		},
+		{
+			name:     "invalid timestamp format",
+			plugin:   &Serializer{TimestampFormat: "rfc3339"},
+			expected: `invalid timestamp format "rfc3339"`,
+		},
+		{
+			name:     "registry with json",
+			plugin:   &Serializer{Format: "json", SchemaRegistry: "http://localhost:8081"},
+			expected: "the schema registry requires the 'binary' format",
+		},
+		{
+			name:     "non-record schema",
+			plugin:   &Serializer{Schema: `{"type": "string"}`},
+			expected: `schema must be of type 'record'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	require.Equal(t, "cpu_usage_idle", sanitize("cpu.usage-idle"))
+	require.Equal(t, "_1min", sanitize("1min"))
+}
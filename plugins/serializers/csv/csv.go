@@ -3,8 +3,10 @@ package csv
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,11 +22,17 @@ type Serializer struct {
 	TimestampFormat string   `toml:"csv_timestamp_format"`
 	Separator       string   `toml:"csv_separator"`
 	Header          bool     `toml:"csv_header"`
+	HeaderMode      string   `toml:"csv_header_mode"`
 	Prefix          bool     `toml:"csv_column_prefix"`
 	Columns         []string `toml:"csv_columns"`
+	NullValue       string   `toml:"csv_null_value"`
+	Quoting         string   `toml:"csv_quoting"`
+	LineEnding      string   `toml:"csv_line_ending"`
 
-	buffer bytes.Buffer
-	writer *csv.Writer
+	buffer     bytes.Buffer
+	writer     *csv.Writer
+	comma      rune
+	lastHeader []string
 }
 
 func (s *Serializer) Init() error {
@@ -47,6 +55,33 @@ func (s *Serializer) Init() error {
 		}
 	}
 
+	switch s.HeaderMode {
+	case "":
+		s.HeaderMode = "once"
+	case "once", "batch", "schema_change":
+	default:
+		return fmt.Errorf("invalid header mode %q", s.HeaderMode)
+	}
+
+	switch s.Quoting {
+	case "":
+		s.Quoting = "minimal"
+	case "minimal", "all", "non_numeric":
+	default:
+		return fmt.Errorf("invalid quoting %q", s.Quoting)
+	}
+
+	var useCRLF bool
+	switch s.LineEnding {
+	case "":
+		useCRLF = runtime.GOOS == "windows"
+	case "lf":
+	case "crlf":
+		useCRLF = true
+	default:
+		return fmt.Errorf("invalid line ending %q", s.LineEnding)
+	}
+
 	// Check columns if any
 	for _, name := range s.Columns {
 		switch {
@@ -59,9 +94,10 @@ func (s *Serializer) Init() error {
 	}
 
 	// Initialize the writer
+	s.comma, _ = utf8.DecodeRuneInString(s.Separator)
 	s.writer = csv.NewWriter(&s.buffer)
-	s.writer.Comma, _ = utf8.DecodeRuneInString(s.Separator)
-	s.writer.UseCRLF = runtime.GOOS == "windows"
+	s.writer.Comma = s.comma
+	s.writer.UseCRLF = useCRLF
 
 	return nil
 }
@@ -78,29 +114,39 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	// Clear the buffer
 	s.buffer.Truncate(0)
 
-	// Write the header if the user wants us to
-	if s.Header {
-		if len(s.Columns) > 0 {
-			if err := s.writeHeaderOrdered(); err != nil {
-				return nil, fmt.Errorf("writing header failed: %w", err)
+	// Write the header at the beginning of each batch if requested
+	if s.Header && s.HeaderMode == "batch" {
+		s.lastHeader = nil
+	}
+
+	for _, m := range metrics {
+		if s.Header {
+			var header []string
+			if len(s.Columns) > 0 {
+				header = s.headerOrdered()
+			} else {
+				header = s.header(m)
 			}
-		} else {
-			if err := s.writeHeader(metrics[0]); err != nil {
-				return nil, fmt.Errorf("writing header failed: %w", err)
+			if s.needsHeader(header) {
+				if err := s.write(header); err != nil {
+					return nil, fmt.Errorf("writing header failed: %w", err)
+				}
+				s.lastHeader = header
 			}
 		}
-		s.Header = false
-	}
 
-	for _, m := range metrics {
+		var record []string
+		var err error
 		if len(s.Columns) > 0 {
-			if err := s.writeDataOrdered(m); err != nil {
-				return nil, fmt.Errorf("writing data failed: %w", err)
-			}
+			record, err = s.dataOrdered(m)
 		} else {
-			if err := s.writeData(m); err != nil {
-				return nil, fmt.Errorf("writing data failed: %w", err)
-			}
+			record, err = s.data(m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("writing data failed: %w", err)
+		}
+		if err := s.write(record); err != nil {
+			return nil, fmt.Errorf("writing data failed: %w", err)
 		}
 	}
 
@@ -109,7 +155,19 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return s.buffer.Bytes(), nil
 }
 
-func (s *Serializer) writeHeader(metric telegraf.Metric) error {
+// needsHeader checks if the given header must be written according to the
+// header mode and the previously written header
+func (s *Serializer) needsHeader(header []string) bool {
+	if s.lastHeader == nil {
+		return true
+	}
+	if s.HeaderMode == "schema_change" {
+		return !slices.Equal(header, s.lastHeader)
+	}
+	return false
+}
+
+func (s *Serializer) header(metric telegraf.Metric) []string {
 	columns := []string{
 		"timestamp",
 		"measurement",
@@ -134,10 +192,10 @@ func (s *Serializer) writeHeader(metric telegraf.Metric) error {
 		}
 	}
 
-	return s.writer.Write(columns)
+	return columns
 }
 
-func (s *Serializer) writeHeaderOrdered() error {
+func (s *Serializer) headerOrdered() []string {
 	columns := make([]string, 0, len(s.Columns))
 	for _, name := range s.Columns {
 		if s.Prefix {
@@ -149,10 +207,10 @@ func (s *Serializer) writeHeaderOrdered() error {
 		columns = append(columns, name)
 	}
 
-	return s.writer.Write(columns)
+	return columns
 }
 
-func (s *Serializer) writeData(metric telegraf.Metric) error {
+func (s *Serializer) data(metric telegraf.Metric) ([]string, error) {
 	var timestamp string
 
 	// Format the time
@@ -184,15 +242,15 @@ func (s *Serializer) writeData(metric telegraf.Metric) error {
 	for _, field := range metric.FieldList() {
 		v, err := internal.ToString(field.Value)
 		if err != nil {
-			return fmt.Errorf("converting field %q to string failed: %w", field.Key, err)
+			return nil, fmt.Errorf("converting field %q to string failed: %w", field.Key, err)
 		}
 		columns = append(columns, v)
 	}
 
-	return s.writer.Write(columns)
+	return columns, nil
 }
 
-func (s *Serializer) writeDataOrdered(metric telegraf.Metric) error {
+func (s *Serializer) dataOrdered(metric telegraf.Metric) ([]string, error) {
 	var timestamp string
 
 	// Format the time
@@ -217,23 +275,70 @@ func (s *Serializer) writeDataOrdered(metric telegraf.Metric) error {
 		case name == "name":
 			columns = append(columns, metric.Name())
 		case strings.HasPrefix(name, "tag."):
-			v, _ := metric.GetTag(strings.TrimPrefix(name, "tag."))
+			v, ok := metric.GetTag(strings.TrimPrefix(name, "tag."))
+			if !ok {
+				v = s.NullValue
+			}
 			columns = append(columns, v)
 		case strings.HasPrefix(name, "field."):
-			var v string
+			v := s.NullValue
 			field := strings.TrimPrefix(name, "field.")
 			if raw, ok := metric.GetField(field); ok {
 				var err error
 				v, err = internal.ToString(raw)
 				if err != nil {
-					return fmt.Errorf("converting field %q to string failed: %w", field, err)
+					return nil, fmt.Errorf("converting field %q to string failed: %w", field, err)
 				}
 			}
 			columns = append(columns, v)
 		}
 	}
 
-	return s.writer.Write(columns)
+	return columns, nil
+}
+
+// write outputs the given record applying the configured quoting
+func (s *Serializer) write(record []string) error {
+	if s.Quoting == "minimal" {
+		return s.writer.Write(record)
+	}
+
+	// Use the CSV writer to validate the separator consistently
+	if !validDelimiter(s.comma) {
+		return errors.New("csv: invalid field or comment delimiter")
+	}
+
+	// Make sure all previously written data is in the buffer
+	s.writer.Flush()
+	for i, v := range record {
+		if i > 0 {
+			s.buffer.WriteRune(s.comma)
+		}
+		if s.Quoting == "non_numeric" && isNumeric(v) {
+			s.buffer.WriteString(v)
+			continue
+		}
+		s.buffer.WriteByte('"')
+		s.buffer.WriteString(strings.ReplaceAll(v, `"`, `""`))
+		s.buffer.WriteByte('"')
+	}
+	if s.writer.UseCRLF {
+		s.buffer.WriteString("\r\n")
+	} else {
+		s.buffer.WriteByte('\n')
+	}
+
+	return nil
+}
+
+func isNumeric(v string) bool {
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// validDelimiter follows the checks of the encoding/csv package
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
 }
 
 func init() {
@@ -6,10 +6,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
@@ -82,6 +85,22 @@ func TestSerializeTransformationNonBatch(t *testing.T) {
 			name:     "ordered non-existing fields and tags",
 			filename: "testcases/ordered_not_exist.conf",
 		},
+		{
+			name:     "ordered with null value",
+			filename: "testcases/ordered_null_value.conf",
+		},
+		{
+			name:     "header on schema change",
+			filename: "testcases/header_schema_change.conf",
+		},
+		{
+			name:     "quoting all",
+			filename: "testcases/quoting_all.conf",
+		},
+		{
+			name:     "quoting non-numeric",
+			filename: "testcases/quoting_non_numeric.conf",
+		},
 	}
 	parser := &influx.Parser{}
 	require.NoError(t, parser.Init())
@@ -108,8 +127,11 @@ func TestSerializeTransformationNonBatch(t *testing.T) {
 				TimestampFormat: cfg.TimestampFormat,
 				Separator:       cfg.Separator,
 				Header:          cfg.Header,
+				HeaderMode:      cfg.HeaderMode,
 				Prefix:          cfg.Prefix,
 				Columns:         cfg.Columns,
+				NullValue:       cfg.NullValue,
+				Quoting:         cfg.Quoting,
 			}
 			require.NoError(t, serializer.Init())
 			// expected results use LF endings
@@ -172,6 +194,22 @@ func TestSerializeTransformationBatch(t *testing.T) {
 			name:     "ordered non-existing fields and tags",
 			filename: "testcases/ordered_not_exist.conf",
 		},
+		{
+			name:     "ordered with null value",
+			filename: "testcases/ordered_null_value.conf",
+		},
+		{
+			name:     "header on schema change",
+			filename: "testcases/header_schema_change.conf",
+		},
+		{
+			name:     "quoting all",
+			filename: "testcases/quoting_all.conf",
+		},
+		{
+			name:     "quoting non-numeric",
+			filename: "testcases/quoting_non_numeric.conf",
+		},
 	}
 	parser := &influx.Parser{}
 	require.NoError(t, parser.Init())
@@ -198,8 +236,11 @@ func TestSerializeTransformationBatch(t *testing.T) {
 				TimestampFormat: cfg.TimestampFormat,
 				Separator:       cfg.Separator,
 				Header:          cfg.Header,
+				HeaderMode:      cfg.HeaderMode,
 				Prefix:          cfg.Prefix,
 				Columns:         cfg.Columns,
+				NullValue:       cfg.NullValue,
+				Quoting:         cfg.Quoting,
 			}
 			require.NoError(t, serializer.Init())
 			// expected results use LF endings
@@ -213,6 +254,38 @@ func TestSerializeTransformationBatch(t *testing.T) {
 	}
 }
 
+func TestInvalidOptions(t *testing.T) {
+	s := Serializer{HeaderMode: "garbage"}
+	require.EqualError(t, s.Init(), `invalid header mode "garbage"`)
+
+	s = Serializer{Quoting: "garbage"}
+	require.EqualError(t, s.Init(), `invalid quoting "garbage"`)
+
+	s = Serializer{LineEnding: "garbage"}
+	require.EqualError(t, s.Init(), `invalid line ending "garbage"`)
+}
+
+func TestHeaderModeBatch(t *testing.T) {
+	s := Serializer{
+		Header:     true,
+		HeaderMode: "batch",
+		LineEnding: "crlf",
+	}
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.5}, time.Unix(1653643420, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 2.5}, time.Unix(1653643420, 0)),
+	}
+
+	expected := "timestamp,measurement,host,usage\r\n1653643420,cpu,a,1.5\r\n1653643420,cpu,b,2.5\r\n"
+	for range 2 {
+		actual, err := s.SerializeBatch(metrics)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual))
+	}
+}
+
 type Config Serializer
 
 func loadTestConfiguration(filename string) (*Config, []string, error) {
@@ -2,6 +2,7 @@ package graphite
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
@@ -39,16 +40,20 @@ type GraphiteTemplate struct {
 }
 
 type GraphiteSerializer struct {
-	Prefix          string   `toml:"prefix"`
-	Template        string   `toml:"template"`
-	StrictRegex     string   `toml:"graphite_strict_sanitize_regex"`
-	TagSupport      bool     `toml:"graphite_tag_support"`
-	TagSanitizeMode string   `toml:"graphite_tag_sanitize_mode"`
-	Separator       string   `toml:"graphite_separator"`
-	Templates       []string `toml:"templates"`
+	Prefix          string            `toml:"prefix"`
+	Template        string            `toml:"template"`
+	StrictRegex     string            `toml:"graphite_strict_sanitize_regex"`
+	TagSupport      bool              `toml:"graphite_tag_support"`
+	TagSanitizeMode string            `toml:"graphite_tag_sanitize_mode"`
+	Separator       string            `toml:"graphite_separator"`
+	TagAppend       bool              `toml:"graphite_tag_append"`
+	Replacements    map[string]string `toml:"graphite_sanitize_replacements"`
+	Lowercase       bool              `toml:"graphite_sanitize_lowercase"`
+	Templates       []string          `toml:"templates"`
 
 	tmplts             []*GraphiteTemplate
 	strictAllowedChars *regexp.Regexp
+	replacer           *strings.Replacer
 }
 
 func (s *GraphiteSerializer) Init() error {
@@ -80,6 +85,29 @@ func (s *GraphiteSerializer) Init() error {
 		}
 	}
 
+	if len(s.Replacements) > 0 {
+		// Sort the replacements to get a deterministic result for overlapping
+		// patterns, longer patterns take precedence
+		keys := make([]string, 0, len(s.Replacements))
+		for k := range s.Replacements {
+			if k == "" {
+				return errors.New("empty pattern in sanitize replacements")
+			}
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) > len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		oldnew := make([]string, 0, 2*len(keys))
+		for _, k := range keys {
+			oldnew = append(oldnew, k, s.Replacements[k])
+		}
+		s.replacer = strings.NewReplacer(oldnew...)
+	}
+
 	return nil
 }
 
@@ -89,6 +117,15 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	// Convert UnixNano to Unix timestamps
 	timestamp := metric.Time().UnixNano() / 1000000000
 
+	name, tags := s.sanitize(metric.Name()), metric.Tags()
+	if s.replacer != nil || s.Lowercase {
+		sanitized := make(map[string]string, len(tags))
+		for k, v := range tags {
+			sanitized[s.sanitize(k)] = s.sanitize(v)
+		}
+		tags = sanitized
+	}
+
 	switch s.TagSupport {
 	case true:
 		for fieldName, value := range metric.Fields() {
@@ -96,7 +133,7 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 			if fieldValue == "" {
 				continue
 			}
-			bucket := s.SerializeBucketNameWithTags(metric.Name(), metric.Tags(), s.Prefix, s.Separator, fieldName, s.TagSanitizeMode)
+			bucket := s.SerializeBucketNameWithTags(name, tags, s.Prefix, s.Separator, s.sanitize(fieldName), s.TagSanitizeMode)
 			metricString := fmt.Sprintf("%s %s %d\n",
 				// insert "field" section of template
 				bucket,
@@ -115,19 +152,21 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 			}
 		}
 
-		bucket := SerializeBucketName(metric.Name(), metric.Tags(), template, s.Prefix)
+		bucket, remaining := serializeBucketName(name, tags, template, s.Prefix, s.TagAppend)
 		if bucket == "" {
 			return out, nil
 		}
+		tagSuffix := s.serializeTags(remaining, s.TagSanitizeMode)
 
 		for fieldName, value := range metric.Fields() {
 			fieldValue := formatValue(value)
 			if fieldValue == "" {
 				continue
 			}
-			metricString := fmt.Sprintf("%s %s %d\n",
+			metricString := fmt.Sprintf("%s%s %s %d\n",
 				// insert "field" section of template
-				s.strictSanitize(InsertField(bucket, fieldName)),
+				s.strictSanitize(InsertField(bucket, s.sanitize(fieldName))),
+				tagSuffix,
 				fieldValue,
 				timestamp)
 			point := []byte(metricString)
@@ -185,6 +224,14 @@ func formatValue(value interface{}) string {
 // SerializeBucketName can be called just once per measurement, rather than
 // once per field. See GraphiteSerializer.InsertField() function.
 func SerializeBucketName(measurement string, tags map[string]string, template, prefix string) string {
+	bucket, _ := serializeBucketName(measurement, tags, template, prefix, false)
+	return bucket
+}
+
+// serializeBucketName produces the graphite bucket like SerializeBucketName.
+// If appendTags is set, the "tags" portion of the template is dropped and the
+// tags not used in the template are returned instead.
+func serializeBucketName(measurement string, tags map[string]string, template, prefix string, appendTags bool) (string, map[string]string) {
 	if template == "" {
 		template = DefaultTemplate
 	}
@@ -200,6 +247,9 @@ func SerializeBucketName(measurement string, tags map[string]string, template, p
 		case "measurement":
 			out = append(out, measurement)
 		case "tags":
+			if appendTags {
+				continue
+			}
 			// we will replace this later
 			out = append(out, "TAGS")
 		case "field":
@@ -223,13 +273,16 @@ func SerializeBucketName(measurement string, tags map[string]string, template, p
 	}
 
 	if len(out) == 0 {
-		return ""
+		return "", nil
 	}
 
+	if !appendTags {
+		tagsCopy = nil
+	}
 	if prefix == "" {
-		return strings.Join(out, ".")
+		return strings.Join(out, "."), tagsCopy
 	}
-	return prefix + "." + strings.Join(out, ".")
+	return prefix + "." + strings.Join(out, "."), tagsCopy
 }
 
 func InitGraphiteTemplates(templates []string) ([]*GraphiteTemplate, string, error) {
@@ -275,19 +328,6 @@ func InitGraphiteTemplates(templates []string) ([]*GraphiteTemplate, string, err
 // http://graphite.readthedocs.io/en/latest/tags.html
 func (s *GraphiteSerializer) SerializeBucketNameWithTags(measurement string, tags map[string]string, prefix, separator, field, tagSanitizeMode string) string {
 	var out string
-	var tagsCopy []string
-	for k, v := range tags {
-		if k == "name" {
-			k = "_name"
-		}
-		if tagSanitizeMode == "compatible" {
-			tagsCopy = append(tagsCopy, compatibleSanitize(k, v))
-		} else {
-			tagsCopy = append(tagsCopy, s.strictSanitize(k+"="+v))
-		}
-	}
-	sort.Strings(tagsCopy)
-
 	if prefix != "" {
 		out = prefix + separator
 	}
@@ -298,13 +338,30 @@ func (s *GraphiteSerializer) SerializeBucketNameWithTags(measurement string, tag
 		out += separator + field
 	}
 
-	out = s.strictSanitize(out)
+	return s.strictSanitize(out) + s.serializeTags(tags, tagSanitizeMode)
+}
 
-	if len(tagsCopy) > 0 {
-		out += ";" + strings.Join(tagsCopy, ";")
+// serializeTags encodes the given tags using the Graphite 1.1 tag syntax,
+// i.e. ";tag1=value1;tag2=value2", sorted by tag name
+func (s *GraphiteSerializer) serializeTags(tags map[string]string, tagSanitizeMode string) string {
+	if len(tags) == 0 {
+		return ""
 	}
 
-	return out
+	tagsCopy := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k == "name" {
+			k = "_name"
+		}
+		if tagSanitizeMode == "compatible" {
+			tagsCopy = append(tagsCopy, compatibleSanitize(k, v))
+		} else {
+			tagsCopy = append(tagsCopy, s.strictSanitize(k+"="+v))
+		}
+	}
+	sort.Strings(tagsCopy)
+
+	return ";" + strings.Join(tagsCopy, ";")
 }
 
 // InsertField takes the bucket string from SerializeBucketName and replaces the
@@ -337,6 +394,18 @@ func buildTags(tags map[string]string) string {
 	return tagStr
 }
 
+// sanitize applies the user-defined replacements and lowercasing to the
+// given name or value
+func (s *GraphiteSerializer) sanitize(value string) string {
+	if s.replacer != nil {
+		value = s.replacer.Replace(value)
+	}
+	if s.Lowercase {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
 func (s *GraphiteSerializer) strictSanitize(value string) string {
 	// Apply special hyphenation rules to preserve backwards compatibility
 	value = hyphenChars.Replace(value)
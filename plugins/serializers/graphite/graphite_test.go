@@ -760,6 +760,112 @@ func TestSerializeCustomRegex(t *testing.T) {
 	require.Equal(t, expS, mS)
 }
 
+func TestSerializeTagAppend(t *testing.T) {
+	now := time.Now()
+	tags := map[string]string{
+		"host":       "localhost",
+		"cpu":        "cpu0",
+		"datacenter": "us-west-2",
+		"name":       "foo.bar",
+	}
+	fields := map[string]interface{}{
+		"usage_idle": float64(91.5),
+	}
+	m := metric.New("cpu", tags, fields, now)
+
+	s := GraphiteSerializer{
+		Template:  "host.tags.measurement.field",
+		TagAppend: true,
+	}
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	mS := strings.Split(strings.TrimSpace(string(buf)), "\n")
+
+	expS := []string{
+		fmt.Sprintf("localhost.cpu.usage_idle;_name=foo.bar;cpu=cpu0;datacenter=us-west-2 91.5 %d", now.Unix()),
+	}
+	require.Equal(t, expS, mS)
+}
+
+func TestSerializeTagAppendCompatible(t *testing.T) {
+	now := time.Now()
+	tags := map[string]string{
+		"host": "localhost",
+		"path": "/var/lib",
+	}
+	fields := map[string]interface{}{
+		"used": int64(42),
+	}
+	m := metric.New("disk", tags, fields, now)
+
+	s := GraphiteSerializer{
+		Template:        "measurement.host.field",
+		TagAppend:       true,
+		TagSanitizeMode: "compatible",
+	}
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	mS := strings.Split(strings.TrimSpace(string(buf)), "\n")
+
+	expS := []string{
+		fmt.Sprintf("disk.localhost.used;path=/var/lib 42 %d", now.Unix()),
+	}
+	require.Equal(t, expS, mS)
+}
+
+func TestSerializeReplacementsAndLowercase(t *testing.T) {
+	now := time.Now()
+	tags := map[string]string{
+		"host":   "Web01",
+		"device": "sda 1",
+		"Mount":  "C:",
+	}
+	fields := map[string]interface{}{
+		"Used Percent": float64(12.5),
+	}
+	m := metric.New("Disk", tags, fields, now)
+
+	tests := []struct {
+		name       string
+		tagSupport bool
+		expected   string
+	}{
+		{
+			name:     "template",
+			expected: "web01.sda-1.c.disk.used-percent",
+		},
+		{
+			name:       "tag support",
+			tagSupport: true,
+			expected:   "disk.used-percent;device=sda-1;host=web01;mount=c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GraphiteSerializer{
+				TagSupport:   tt.tagSupport,
+				Replacements: map[string]string{" ": "-", ":": ""},
+				Lowercase:    true,
+			}
+			require.NoError(t, s.Init())
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("%s 12.5 %d\n", tt.expected, now.Unix()), string(buf))
+		})
+	}
+}
+
+func TestInvalidReplacements(t *testing.T) {
+	s := GraphiteSerializer{Replacements: map[string]string{"": "_"}}
+	require.ErrorContains(t, s.Init(), "empty pattern in sanitize replacements")
+}
+
 func TestSerializeBucketNameNoHost(t *testing.T) {
 	now := time.Now()
 	tags := map[string]string{
@@ -0,0 +1,142 @@
+package selfstat
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// DurationBuckets are the default upper bounds, in seconds, of the buckets
+// of duration histograms.
+var DurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Histogram is a cumulative histogram of observed values. In contrast to
+// other stats, histograms are reported as multiple metrics of histogram type
+// with a "le" tag denoting the upper bound of the bucket, as produced by the
+// histogram aggregator.
+type Histogram struct {
+	measurement string
+	field       string
+	tags        map[string]string
+	bounds      []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// RegisterHistogram registers a histogram with the given measurement, field,
+// tags and bucket upper bounds in the selfstat registry. If given an
+// identical measurement, it will return the histogram that's already been
+// registered.
+//
+// The returned histogram will be returned as telegraf metrics when
+// Histograms() is called.
+func RegisterHistogram(measurement, field string, tags map[string]string, bounds []float64) *Histogram {
+	return registry.registerHistogram("internal_"+measurement, field, tags, bounds)
+}
+
+// Histograms returns all registered histograms as telegraf metrics.
+func Histograms() []telegraf.Metric {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	now := time.Now()
+	var metrics []telegraf.Metric
+	for _, histograms := range registry.histograms {
+		for _, h := range histograms {
+			metrics = append(metrics, h.metrics(now)...)
+		}
+	}
+	return metrics
+}
+
+func (r *Registry) registerHistogram(measurement, field string, tags map[string]string, bounds []float64) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.histograms == nil {
+		r.histograms = make(map[uint64]map[string]*Histogram)
+	}
+
+	key := key(measurement, tags)
+	if h, ok := r.histograms[key][field]; ok {
+		return h
+	}
+
+	t := make(map[string]string, len(tags))
+	for k, v := range tags {
+		t[k] = v
+	}
+
+	b := slices.Clone(bounds)
+	slices.Sort(b)
+
+	h := &Histogram{
+		measurement: measurement,
+		field:       field,
+		tags:        t,
+		bounds:      b,
+		counts:      make([]uint64, len(b)),
+	}
+	if _, ok := r.histograms[key]; !ok {
+		r.histograms[key] = make(map[string]*Histogram)
+	}
+	r.histograms[key][field] = h
+	return h
+}
+
+// Observe adds the given value to the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if idx, _ := slices.BinarySearch(h.bounds, v); idx < len(h.bounds) {
+		h.counts[idx]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration adds the given duration in seconds to the histogram.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+func (h *Histogram) metrics(now time.Time) []telegraf.Metric {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	metrics := make([]telegraf.Metric, 0, len(h.bounds)+2)
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		metrics = append(metrics, h.bucket(strconv.FormatFloat(bound, 'f', -1, 64), cumulative, now))
+	}
+	metrics = append(metrics, h.bucket(strconv.FormatFloat(math.Inf(1), 'f', -1, 64), h.count, now))
+
+	fields := map[string]interface{}{
+		h.field + "_count": h.count,
+		h.field + "_sum":   h.sum,
+	}
+	metrics = append(metrics, metric.New(h.measurement, h.tags, fields, now, telegraf.Histogram))
+
+	return metrics
+}
+
+func (h *Histogram) bucket(le string, count uint64, now time.Time) telegraf.Metric {
+	tags := make(map[string]string, len(h.tags)+1)
+	for k, v := range h.tags {
+		tags[k] = v
+	}
+	tags["le"] = le
+
+	fields := map[string]interface{}{h.field + "_bucket": count}
+	return metric.New(h.measurement, tags, fields, now, telegraf.Histogram)
+}
@@ -103,8 +103,9 @@ func Metrics() []telegraf.Metric {
 }
 
 type Registry struct {
-	stats map[uint64]map[string]Stat
-	mu    sync.Mutex
+	stats      map[uint64]map[string]Stat
+	histograms map[uint64]map[string]*Histogram
+	mu         sync.Mutex
 }
 
 func (r *Registry) register(measurement, field string, tags map[string]string) Stat {
@@ -3,9 +3,12 @@ package selfstat
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -211,3 +214,57 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestRegisterHistogram(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	tags := map[string]string{"input": "mem"}
+	h := RegisterHistogram("gather", "gather_duration_seconds", tags, []float64{1, 0.1})
+	require.Same(t, h, RegisterHistogram("gather", "gather_duration_seconds", tags, []float64{1, 0.1}))
+
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.ObserveDuration(500 * time.Millisecond)
+	h.Observe(2)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"internal_gather",
+			map[string]string{"input": "mem", "le": "0.1"},
+			map[string]interface{}{"gather_duration_seconds_bucket": uint64(2)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_gather",
+			map[string]string{"input": "mem", "le": "1"},
+			map[string]interface{}{"gather_duration_seconds_bucket": uint64(3)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_gather",
+			map[string]string{"input": "mem", "le": "+Inf"},
+			map[string]interface{}{"gather_duration_seconds_bucket": uint64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_gather",
+			map[string]string{"input": "mem"},
+			map[string]interface{}{
+				"gather_duration_seconds_count": uint64(4),
+				"gather_duration_seconds_sum":   2.65,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	// Histograms must not be reported as regular stats
+	for _, m := range Metrics() {
+		require.False(t, m.HasField("gather_duration_seconds_count"))
+	}
+	testutil.RequireMetricsEqual(t, expected, Histograms(), testutil.IgnoreTime(), testutil.SortMetrics())
+}